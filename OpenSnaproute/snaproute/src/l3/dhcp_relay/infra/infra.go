@@ -27,18 +27,22 @@ import (
 	//	"errors"
 	//	"fmt"
 	"net"
+	"ribd"
 	"sync"
 	"utils/asicdClient"
 	"utils/commonDefs"
+	"utils/ipcutils"
 	"utils/logging"
 )
 
 type IPv4IntfProperty struct {
-	IpAddr  string
-	Netmask net.IPMask
-	IfIndex int
-	IfRef   string
-	State   bool
+	IpAddr     string
+	Netmask    net.IPMask
+	IfIndex    int
+	IfRef      string
+	State      bool
+	L2IntfType string
+	L2IntfId   int32
 }
 
 type IPv6IntfProperty struct {
@@ -49,9 +53,17 @@ type IPv6IntfProperty struct {
 	State   bool
 }
 
+// Subset of the ribd client used for delegated prefix routes
+type RibdClientIntf interface {
+	CreateIPv6Route(cfg *ribd.IPv6Route) (bool, error)
+	DeleteIPv6Route(cfg *ribd.IPv6Route) (bool, error)
+}
+
 type InfraMgr struct {
 	Logger        logging.LoggerIntf
 	AsicdHdl      asicdClient.AsicdClientIntf
+	RibdHdl       RibdClientIntf
+	RibdAddr      string
 	InfraMgrMutex sync.Mutex
 
 	IPv4IntfProps      map[int]*IPv4IntfProperty
@@ -62,12 +74,17 @@ type InfraMgr struct {
 	IPv6LLIntfProps      map[int]*IPv6IntfProperty
 	IPv6LLIfRefToIfIndex map[string]int
 
-	DRAv4Global      *dhcprelayd.DHCPRelayGlobal
+	// Relay Agent instance per VRF keyed by Vrf
+	DRAv4Globals     map[string]*dhcprelayd.DHCPRelayGlobal
 	DRAv4Intfs       map[string]*dhcprelayd.DHCPRelayIntf
 	ActiveDRAv4Intfs map[int]*dhcprelayd.DHCPRelayIntf
-	DRAv6Global      *dhcprelayd.DHCPv6RelayGlobal
+	DRAv6Globals     map[string]*dhcprelayd.DHCPv6RelayGlobal
 	DRAv6Intfs       map[string]*dhcprelayd.DHCPv6RelayIntf
 	ActiveDRAv6Intfs map[int]*dhcprelayd.DHCPv6RelayIntf
+
+	SnoopingIntfs map[string]*dhcprelayd.DHCPRelaySnoopingIntf
+	// Used as the default Remote-ID of Relay Agent Information
+	SwitchMac net.HardwareAddr
 }
 
 func NewInfraMgr(logger logging.LoggerIntf,
@@ -83,10 +100,13 @@ func NewInfraMgr(logger logging.LoggerIntf,
 	iMgr.IPv6IfRefToIfIndex = make(map[string]int)
 	iMgr.IPv6LLIntfProps = make(map[int]*IPv6IntfProperty)
 	iMgr.IPv6LLIfRefToIfIndex = make(map[string]int)
+	iMgr.DRAv4Globals = make(map[string]*dhcprelayd.DHCPRelayGlobal)
 	iMgr.DRAv4Intfs = make(map[string]*dhcprelayd.DHCPRelayIntf)
 	iMgr.ActiveDRAv4Intfs = make(map[int]*dhcprelayd.DHCPRelayIntf)
+	iMgr.DRAv6Globals = make(map[string]*dhcprelayd.DHCPv6RelayGlobal)
 	iMgr.DRAv6Intfs = make(map[string]*dhcprelayd.DHCPv6RelayIntf)
 	iMgr.ActiveDRAv6Intfs = make(map[int]*dhcprelayd.DHCPv6RelayIntf)
+	iMgr.SnoopingIntfs = make(map[string]*dhcprelayd.DHCPRelaySnoopingIntf)
 	return iMgr
 }
 
@@ -119,6 +139,8 @@ func (iMgr *InfraMgr) constructIPv4Infra() {
 			IfIndex: ifIdx,
 			IfRef: ifRef,
 			State:   getBinaryState(intf.OperState),
+			L2IntfType: intf.L2IntfType,
+			L2IntfId:   intf.L2IntfId,
 		}
 		iMgr.IPv4IntfProps[ifIdx] = intfProp
 		iMgr.IPv4IfRefToIfIndex[ifRef] = ifIdx
@@ -246,11 +268,8 @@ func (iMgr *InfraMgr) ProcessIPv4IntfCreate(
 	if !intfProp.State {
 		return
 	}
-	if iMgr.DRAv4Global == nil || !iMgr.DRAv4Global.Enable {
-		return
-	}
 	draIntf, ok := iMgr.DRAv4Intfs[intfProp.IfRef]
-	if !ok || !draIntf.Enable {
+	if !ok || !draIntf.Enable || !iMgr.isDRAv4Enabled(GetDRAv4IntfVrf(draIntf)) {
 		return
 	}
 	iMgr.ActiveDRAv4Intfs[ifIdx] = draIntf
@@ -281,11 +300,8 @@ func (iMgr *InfraMgr) ProcessIPv6IntfCreate(
 	if !intfProp.State {
 		return
 	}
-	if iMgr.DRAv6Global == nil || !iMgr.DRAv6Global.Enable {
-		return
-	}
 	draIntf, ok := iMgr.DRAv6Intfs[intfProp.IfRef]
-	if !ok || !draIntf.Enable {
+	if !ok || !draIntf.Enable || !iMgr.isDRAv6Enabled(GetDRAv6IntfVrf(draIntf)) {
 		return
 	}
 	iMgr.ActiveDRAv6Intfs[ifIdx] = draIntf
@@ -353,11 +369,8 @@ func (iMgr *InfraMgr) ProcessIPv4StateChange(
 	} else {
 		ipv4Intf.State = true
 	}
-	if iMgr.DRAv4Global == nil || !iMgr.DRAv4Global.Enable {
-		return
-	}
 	draIntf, ok := iMgr.DRAv4Intfs[ipv4Intf.IfRef]
-	if !ok || !draIntf.Enable {
+	if !ok || !draIntf.Enable || !iMgr.isDRAv4Enabled(GetDRAv4IntfVrf(draIntf)) {
 		return
 	}
 	if msg.IfState == 0 { // State going DOWN
@@ -397,11 +410,9 @@ func (iMgr *InfraMgr) ProcessIPv6StateChange(
 		} else {
 			ipv6Intf.State = true
 		}
-		if iMgr.DRAv6Global == nil || !iMgr.DRAv6Global.Enable {
-			return
-		}
 		draIntf, ok := iMgr.DRAv6Intfs[ipv6Intf.IfRef]
-		if !ok || !draIntf.Enable {
+		if !ok || !draIntf.Enable ||
+			!iMgr.isDRAv6Enabled(GetDRAv6IntfVrf(draIntf)) {
 			return
 		}
 		if msg.IfState == 0 { // State going DOWN
@@ -446,6 +457,277 @@ func (iMgr *InfraMgr) GetActiveDRAv6Intf(
 	return draIntf, ok
 }
 
+func GetDRAv4IntfVrf(draIntf *dhcprelayd.DHCPRelayIntf) string {
+	if draIntf.Vrf == "" {
+		return DEFAULT_VRF
+	}
+	return draIntf.Vrf
+}
+
+func GetDRAv6IntfVrf(draIntf *dhcprelayd.DHCPv6RelayIntf) string {
+	if draIntf.Vrf == "" {
+		return DEFAULT_VRF
+	}
+	return draIntf.Vrf
+}
+
+// Caller needs to hold InfraMgrMutex
+func (iMgr *InfraMgr) isDRAv4Enabled(vrf string) bool {
+	draGbl, ok := iMgr.DRAv4Globals[vrf]
+	return ok && draGbl != nil && draGbl.Enable
+}
+
+// Caller needs to hold InfraMgrMutex
+func (iMgr *InfraMgr) isDRAv6Enabled(vrf string) bool {
+	draGbl, ok := iMgr.DRAv6Globals[vrf]
+	return ok && draGbl != nil && draGbl.Enable
+}
+
+func (iMgr *InfraMgr) GetDRAv4Global(
+	vrf string) (*dhcprelayd.DHCPRelayGlobal, bool) {
+
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	draGbl, ok := iMgr.DRAv4Globals[vrf]
+	return draGbl, ok && draGbl != nil
+}
+
+func (iMgr *InfraMgr) GetDRAv6Global(
+	vrf string) (*dhcprelayd.DHCPv6RelayGlobal, bool) {
+
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	draGbl, ok := iMgr.DRAv6Globals[vrf]
+	return draGbl, ok && draGbl != nil
+}
+
+// Interfaces without relay config are in the default VRF
+func (iMgr *InfraMgr) GetDRAv4IntfVrf(ifRef string) string {
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	if draIntf, ok := iMgr.DRAv4Intfs[ifRef]; ok {
+		return GetDRAv4IntfVrf(draIntf)
+	}
+	return DEFAULT_VRF
+}
+
+func (iMgr *InfraMgr) GetDRAv6IntfVrf(ifRef string) string {
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	if draIntf, ok := iMgr.DRAv6Intfs[ifRef]; ok {
+		return GetDRAv6IntfVrf(draIntf)
+	}
+	return DEFAULT_VRF
+}
+
+// VRF in which the servers of the relay VRF are reached
+func (iMgr *InfraMgr) GetDRAv4ServerVrf(vrf string) string {
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	draGbl, ok := iMgr.DRAv4Globals[vrf]
+	if !ok || draGbl == nil || draGbl.ServerVrf == "" {
+		return vrf
+	}
+	return draGbl.ServerVrf
+}
+
+func (iMgr *InfraMgr) GetDRAv6ServerVrf(vrf string) string {
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	draGbl, ok := iMgr.DRAv6Globals[vrf]
+	if !ok || draGbl == nil || draGbl.ServerVrf == "" {
+		return vrf
+	}
+	return draGbl.ServerVrf
+}
+
+// VRFs a socket is needed in, the VRFs with active interfaces and the
+// VRFs their servers are reached in
+func (iMgr *InfraMgr) GetActiveDRAv4Vrfs() []string {
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	vrfMap := make(map[string]bool)
+	for _, draIntf := range iMgr.ActiveDRAv4Intfs {
+		vrf := GetDRAv4IntfVrf(draIntf)
+		vrfMap[vrf] = true
+		if draGbl, ok := iMgr.DRAv4Globals[vrf]; ok && draGbl != nil &&
+			draGbl.ServerVrf != "" {
+			vrfMap[draGbl.ServerVrf] = true
+		}
+	}
+	vrfs := make([]string, 0, len(vrfMap))
+	for vrf := range vrfMap {
+		vrfs = append(vrfs, vrf)
+	}
+	return vrfs
+}
+
+func (iMgr *InfraMgr) GetActiveDRAv6Vrfs() []string {
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	vrfMap := make(map[string]bool)
+	for _, draIntf := range iMgr.ActiveDRAv6Intfs {
+		vrf := GetDRAv6IntfVrf(draIntf)
+		vrfMap[vrf] = true
+		if draGbl, ok := iMgr.DRAv6Globals[vrf]; ok && draGbl != nil &&
+			draGbl.ServerVrf != "" {
+			vrfMap[draGbl.ServerVrf] = true
+		}
+	}
+	vrfs := make([]string, 0, len(vrfMap))
+	for vrf := range vrfMap {
+		vrfs = append(vrfs, vrf)
+	}
+	return vrfs
+}
+
+func (iMgr *InfraMgr) GetDRAv4HopCountLimit(vrf string) (int32, bool) {
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	draGbl, ok := iMgr.DRAv4Globals[vrf]
+	if !ok || draGbl == nil {
+		return 0, false
+	}
+	return draGbl.HopCountLimit, true
+}
+
+func (iMgr *InfraMgr) GetDRAv6HopCountLimit(vrf string) (int32, bool) {
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	draGbl, ok := iMgr.DRAv6Globals[vrf]
+	if !ok || draGbl == nil {
+		return 0, false
+	}
+	return draGbl.HopCountLimit, true
+}
+
+func (iMgr *InfraMgr) GetDRAv4Intf(
+	ifRef string) (*dhcprelayd.DHCPRelayIntf, bool) {
+
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	draIntf, ok := iMgr.DRAv4Intfs[ifRef]
+	return draIntf, ok
+}
+
+func (iMgr *InfraMgr) SetRibdHdl(ribdHdl RibdClientIntf) {
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	iMgr.RibdHdl = ribdHdl
+}
+
+func (iMgr *InfraMgr) SetSwitchMac(switchMac string) {
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	mac, err := net.ParseMAC(switchMac)
+	if err != nil {
+		iMgr.Logger.Err("DRA: Invalid switch mac address", switchMac)
+		return
+	}
+	iMgr.SwitchMac = mac
+}
+
+func (iMgr *InfraMgr) GetSwitchMac() net.HardwareAddr {
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	return iMgr.SwitchMac
+}
+
+func (iMgr *InfraMgr) SetRibdAddr(ribdAddr string) {
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	iMgr.RibdAddr = ribdAddr
+}
+
+// Relaying does not depend on ribd, so the connection is made on first use
+// and retried by the next caller. Returns false until the connection is up
+func (iMgr *InfraMgr) GetRibdHdl() (RibdClientIntf, bool) {
+	iMgr.InfraMgrMutex.Lock()
+	ribdHdl, ribdAddr := iMgr.RibdHdl, iMgr.RibdAddr
+	iMgr.InfraMgrMutex.Unlock()
+	if ribdHdl != nil {
+		return ribdHdl, true
+	}
+	if ribdAddr == "" {
+		return nil, false
+	}
+
+	// Dialing can block, so it is done without holding InfraMgrMutex
+	transport, protocolFactory, err := ipcutils.CreateIPCHandles(ribdAddr)
+	if err != nil {
+		iMgr.Logger.Debug("DRA: Failed to connect to ribd at", ribdAddr, err)
+		return nil, false
+	}
+
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+	if iMgr.RibdHdl != nil {
+		// Another caller connected in the meantime
+		transport.Close()
+		return iMgr.RibdHdl, true
+	}
+	iMgr.Logger.Info("DRA: Connected to ribd at", ribdAddr)
+	iMgr.RibdHdl = ribd.NewRIBDServicesClientFactory(
+		transport, protocolFactory)
+	return iMgr.RibdHdl, true
+}
+
+func (iMgr *InfraMgr) GetDRAv4SnoopingEnabled(vrf string) bool {
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	draGbl, ok := iMgr.DRAv4Globals[vrf]
+	return ok && draGbl != nil && draGbl.Snooping
+}
+
+// Interfaces are untrusted unless configured otherwise
+func (iMgr *InfraMgr) GetSnoopingTrusted(ifRef string) bool {
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	snoopingIntf, ok := iMgr.SnoopingIntfs[ifRef]
+	return ok && snoopingIntf.Trusted
+}
+
+func (iMgr *InfraMgr) GetSnoopingIntf(
+	ifRef string) (*dhcprelayd.DHCPRelaySnoopingIntf, bool) {
+
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	snoopingIntf, ok := iMgr.SnoopingIntfs[ifRef]
+	return snoopingIntf, ok
+}
+
+func (iMgr *InfraMgr) UpdateSnoopingIntf(cfg *dhcprelayd.DHCPRelaySnoopingIntf) {
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	iMgr.SnoopingIntfs[cfg.IntfRef] = cfg
+}
+
+func (iMgr *InfraMgr) DeleteSnoopingIntf(ifRef string) {
+	defer iMgr.InfraMgrMutex.Unlock()
+	iMgr.InfraMgrMutex.Lock()
+
+	delete(iMgr.SnoopingIntfs, ifRef)
+}
+
 func (iMgr *InfraMgr) GetAllActiveDRAv4Intfs() []int {
 	defer iMgr.InfraMgrMutex.Unlock()
 	iMgr.InfraMgrMutex.Lock()
@@ -472,21 +754,20 @@ func (iMgr *InfraMgr) UpdateDRAv4Global(cfg *dhcprelayd.DHCPRelayGlobal) {
 	defer iMgr.InfraMgrMutex.Unlock()
 	iMgr.InfraMgrMutex.Lock()
 
-	iMgr.DRAv4Global = cfg
-	if !iMgr.DRAv4Global.Enable {
-		for ifIdx, _ := range iMgr.ActiveDRAv4Intfs {
-			delete(iMgr.ActiveDRAv4Intfs, ifIdx)
-		}
-		return
-	}
+	iMgr.DRAv4Globals[cfg.Vrf] = cfg
 	for ifRef, draIntf := range iMgr.DRAv4Intfs {
+		if GetDRAv4IntfVrf(draIntf) != cfg.Vrf {
+			continue
+		}
 		ifIdx, ok := iMgr.IPv4IfRefToIfIndex[ifRef]
 		if !ok {
 			continue
 		}
 		ipv4Intf, _ := iMgr.IPv4IntfProps[ifIdx]
-		if draIntf.Enable && ipv4Intf.State {
+		if cfg.Enable && draIntf.Enable && ipv4Intf.State {
 			iMgr.ActiveDRAv4Intfs[ifIdx] = draIntf
+		} else {
+			delete(iMgr.ActiveDRAv4Intfs, ifIdx)
 		}
 	}
 }
@@ -496,43 +777,46 @@ func (iMgr *InfraMgr) UpdateDRAv6Global(cfg *dhcprelayd.DHCPv6RelayGlobal) {
 	defer iMgr.InfraMgrMutex.Unlock()
 	iMgr.InfraMgrMutex.Lock()
 
-	iMgr.DRAv6Global = cfg
-	if !iMgr.DRAv6Global.Enable {
-		for ifIdx, _ := range iMgr.ActiveDRAv6Intfs {
-			delete(iMgr.ActiveDRAv6Intfs, ifIdx)
-		}
-		return
-	}
+	iMgr.DRAv6Globals[cfg.Vrf] = cfg
 	for ifRef, draIntf := range iMgr.DRAv6Intfs {
+		if GetDRAv6IntfVrf(draIntf) != cfg.Vrf {
+			continue
+		}
 		ifIdx, ok := iMgr.IPv6IfRefToIfIndex[ifRef]
 		if !ok {
 			continue
 		}
 		ipv6Intf, _ := iMgr.IPv6IntfProps[ifIdx]
-		if draIntf.Enable && ipv6Intf.State {
+		if cfg.Enable && draIntf.Enable && ipv6Intf.State {
 			iMgr.ActiveDRAv6Intfs[ifIdx] = draIntf
+		} else {
+			delete(iMgr.ActiveDRAv6Intfs, ifIdx)
 		}
 	}
 }
 
-func (iMgr *InfraMgr) DeleteDRAv4Global() {
+func (iMgr *InfraMgr) DeleteDRAv4Global(vrf string) {
 	defer iMgr.InfraMgrMutex.Unlock()
 	iMgr.InfraMgrMutex.Lock()
 
-	for ifIdx := range iMgr.ActiveDRAv4Intfs {
-		delete(iMgr.ActiveDRAv4Intfs, ifIdx)
+	for ifIdx, draIntf := range iMgr.ActiveDRAv4Intfs {
+		if GetDRAv4IntfVrf(draIntf) == vrf {
+			delete(iMgr.ActiveDRAv4Intfs, ifIdx)
+		}
 	}
-	iMgr.DRAv4Global = nil
+	delete(iMgr.DRAv4Globals, vrf)
 }
 
-func (iMgr *InfraMgr) DeleteDRAv6Global() {
+func (iMgr *InfraMgr) DeleteDRAv6Global(vrf string) {
 	defer iMgr.InfraMgrMutex.Unlock()
 	iMgr.InfraMgrMutex.Lock()
 
-	for ifIdx := range iMgr.ActiveDRAv6Intfs {
-		delete(iMgr.ActiveDRAv6Intfs, ifIdx)
+	for ifIdx, draIntf := range iMgr.ActiveDRAv6Intfs {
+		if GetDRAv6IntfVrf(draIntf) == vrf {
+			delete(iMgr.ActiveDRAv6Intfs, ifIdx)
+		}
 	}
-	iMgr.DRAv6Global = nil
+	delete(iMgr.DRAv6Globals, vrf)
 }
 
 func (iMgr *InfraMgr) UpdateDRAv4Intf(cfg *dhcprelayd.DHCPRelayIntf) {
@@ -542,16 +826,14 @@ func (iMgr *InfraMgr) UpdateDRAv4Intf(cfg *dhcprelayd.DHCPRelayIntf) {
 	ifRef := cfg.IntfRef
 	iMgr.DRAv4Intfs[ifRef] = cfg
 
-	if iMgr.DRAv4Global == nil || !iMgr.DRAv4Global.Enable {
-		return
-	}
 	ifIdx, ok := iMgr.IPv4IfRefToIfIndex[ifRef]
 	if !ok {
 		iMgr.Logger.Info("No IPv4 IfIndex found for IntfRef", ifRef)
 		return
 	}
 	ipv4Intf, _ := iMgr.IPv4IntfProps[ifIdx]
-	if cfg.Enable && ipv4Intf.State {
+	if cfg.Enable && ipv4Intf.State &&
+		iMgr.isDRAv4Enabled(GetDRAv4IntfVrf(cfg)) {
 		iMgr.ActiveDRAv4Intfs[ifIdx] = cfg
 	} else {
 		delete(iMgr.ActiveDRAv4Intfs, ifIdx)
@@ -565,16 +847,14 @@ func (iMgr *InfraMgr) UpdateDRAv6Intf(cfg *dhcprelayd.DHCPv6RelayIntf) {
 	ifRef := cfg.IntfRef
 	iMgr.DRAv6Intfs[ifRef] = cfg
 
-	if iMgr.DRAv6Global == nil || !iMgr.DRAv6Global.Enable {
-		return
-	}
 	ifIdx, ok := iMgr.IPv6IfRefToIfIndex[ifRef]
 	if !ok {
 		iMgr.Logger.Info("No IPv6 IfIndex found for IntfRef", ifRef)
 		return
 	}
 	ipv6Intf, _ := iMgr.IPv6IntfProps[ifIdx]
-	if cfg.Enable && ipv6Intf.State {
+	if cfg.Enable && ipv6Intf.State &&
+		iMgr.isDRAv6Enabled(GetDRAv6IntfVrf(cfg)) {
 		iMgr.ActiveDRAv6Intfs[ifIdx] = cfg
 	} else {
 		delete(iMgr.ActiveDRAv6Intfs, ifIdx)
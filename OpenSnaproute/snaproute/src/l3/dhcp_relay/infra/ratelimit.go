@@ -0,0 +1,191 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package infra
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	CLIENT_RATE_LIMIT_IDLE_TIMEOUT = 60 * time.Second
+	CLIENT_RATE_LIMIT_MAX_CLIENTS  = 4096
+)
+
+type RateLimitVerdict int
+
+const (
+	RATE_LIMIT_PASS RateLimitVerdict = iota
+	RATE_LIMIT_INTF_DROP
+	RATE_LIMIT_CLIENT_DROP
+)
+
+// Token bucket allowing rate packets per second with a burst of one second
+// worth of packets, callers need to serialize access
+type TokenBucket struct {
+	rate     float64
+	tokens   float64
+	lastFill time.Time
+}
+
+func NewTokenBucket(rate int32, now time.Time) *TokenBucket {
+	return &TokenBucket{
+		rate:     float64(rate),
+		tokens:   float64(rate),
+		lastFill: now,
+	}
+}
+
+func (tb *TokenBucket) fill(now time.Time) {
+	elapsed := now.Sub(tb.lastFill).Seconds()
+	if elapsed > 0 {
+		tb.tokens += elapsed * tb.rate
+		if tb.tokens > tb.rate {
+			tb.tokens = tb.rate
+		}
+	}
+	tb.lastFill = now
+}
+
+// Rate changes take effect without resetting the tokens already collected
+func (tb *TokenBucket) SetRate(rate int32, now time.Time) {
+	if float64(rate) == tb.rate {
+		return
+	}
+	tb.fill(now)
+	tb.rate = float64(rate)
+	if tb.tokens > tb.rate {
+		tb.tokens = tb.rate
+	}
+}
+
+// Takes a token if one is available
+func (tb *TokenBucket) Allow(now time.Time) bool {
+	tb.fill(now)
+	if tb.tokens < 1 {
+		return false
+	}
+	tb.tokens--
+	return true
+}
+
+type clientRateLimiter struct {
+	bucket   *TokenBucket
+	lastUsed time.Time
+	// Reported once until the client is within the limit again
+	limited bool
+}
+
+// Token buckets keyed by intf and by intf + client mac address. Client
+// buckets are bounded by maxClients, new clients beyond it are only limited
+// per intf until idle clients are aged out
+type RateLimiter struct {
+	mutex          sync.Mutex
+	intfBuckets    map[string]*TokenBucket
+	clientLimiters map[string]*clientRateLimiter
+	maxClients     int
+}
+
+func NewRateLimiter(maxClients int) *RateLimiter {
+	return &RateLimiter{
+		intfBuckets:    make(map[string]*TokenBucket),
+		clientLimiters: make(map[string]*clientRateLimiter),
+		maxClients:     maxClients,
+	}
+}
+
+func getClientRateLimitKey(ifName string, macAddr string) string {
+	return ifName + "_" + macAddr
+}
+
+// Client limit is applied first so that a flooding client does not use up
+// the tokens of the interface. Returns true along with a client drop the
+// first time the client exceeds its limit
+func (rl *RateLimiter) Apply(ifName string, macAddr string,
+	intfRate int32, clientRate int32, now time.Time) (RateLimitVerdict, bool) {
+
+	defer rl.mutex.Unlock()
+	rl.mutex.Lock()
+
+	clientKey := getClientRateLimitKey(ifName, macAddr)
+	if clientRate > 0 && macAddr != "" {
+		limiter, ok := rl.clientLimiters[clientKey]
+		if !ok && len(rl.clientLimiters) < rl.maxClients {
+			limiter = &clientRateLimiter{
+				bucket: NewTokenBucket(clientRate, now),
+			}
+			rl.clientLimiters[clientKey] = limiter
+		}
+		if limiter != nil {
+			limiter.lastUsed = now
+			limiter.bucket.SetRate(clientRate, now)
+			if !limiter.bucket.Allow(now) {
+				newlyLimited := !limiter.limited
+				limiter.limited = true
+				return RATE_LIMIT_CLIENT_DROP, newlyLimited
+			}
+			limiter.limited = false
+		}
+	} else {
+		delete(rl.clientLimiters, clientKey)
+	}
+	if intfRate > 0 {
+		bucket, ok := rl.intfBuckets[ifName]
+		if !ok {
+			bucket = NewTokenBucket(intfRate, now)
+			rl.intfBuckets[ifName] = bucket
+		}
+		bucket.SetRate(intfRate, now)
+		if !bucket.Allow(now) {
+			return RATE_LIMIT_INTF_DROP, false
+		}
+	} else {
+		delete(rl.intfBuckets, ifName)
+	}
+	return RATE_LIMIT_PASS, false
+}
+
+func (rl *RateLimiter) AgeClients(now time.Time) {
+	defer rl.mutex.Unlock()
+	rl.mutex.Lock()
+
+	for key, limiter := range rl.clientLimiters {
+		if now.Sub(limiter.lastUsed) > CLIENT_RATE_LIMIT_IDLE_TIMEOUT {
+			delete(rl.clientLimiters, key)
+		}
+	}
+}
+
+func (rl *RateLimiter) DeleteIntf(ifName string) {
+	defer rl.mutex.Unlock()
+	rl.mutex.Lock()
+
+	delete(rl.intfBuckets, ifName)
+	for key := range rl.clientLimiters {
+		if strings.HasPrefix(key, ifName+"_") {
+			delete(rl.clientLimiters, key)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package infra
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Now()
+	tb := NewTokenBucket(2, now)
+	// Burst of one second worth of packets
+	if !tb.Allow(now) || !tb.Allow(now) || tb.Allow(now) {
+		t.Fatal("Wrong burst")
+	}
+	now = now.Add(500 * time.Millisecond)
+	if !tb.Allow(now) || tb.Allow(now) {
+		t.Fatal("Wrong refill after half a second")
+	}
+	// Idle time does not accumulate more than the burst
+	now = now.Add(10 * time.Second)
+	if !tb.Allow(now) || !tb.Allow(now) || tb.Allow(now) {
+		t.Fatal("Burst exceeded after idle time")
+	}
+	now = now.Add(time.Second)
+	tb.SetRate(1, now)
+	if !tb.Allow(now) || tb.Allow(now) {
+		t.Fatal("Tokens not capped on rate decrease")
+	}
+	// Clock going backwards does not add tokens
+	if tb.Allow(now.Add(-time.Second)) {
+		t.Fatal("Token added for negative elapsed time")
+	}
+}
+
+func TestRateLimiterClient(t *testing.T) {
+	now := time.Now()
+	rl := NewRateLimiter(2)
+	verdict, newlyLimited := rl.Apply("eth0", "00:00:00:00:00:01", 0, 1, now)
+	if verdict != RATE_LIMIT_PASS || newlyLimited {
+		t.Fatal("First packet of client dropped")
+	}
+	verdict, newlyLimited = rl.Apply("eth0", "00:00:00:00:00:01", 0, 1, now)
+	if verdict != RATE_LIMIT_CLIENT_DROP || !newlyLimited {
+		t.Fatal("Client limit not applied", verdict, newlyLimited)
+	}
+	// Limited client is only reported once
+	verdict, newlyLimited = rl.Apply("eth0", "00:00:00:00:00:01", 0, 1, now)
+	if verdict != RATE_LIMIT_CLIENT_DROP || newlyLimited {
+		t.Fatal("Limited client reported again", verdict, newlyLimited)
+	}
+	// Dropped client packets don't use up the tokens of the interface
+	verdict, _ = rl.Apply("eth0", "00:00:00:00:00:02", 1, 1, now)
+	if verdict != RATE_LIMIT_PASS {
+		t.Fatal("Packet of other client dropped", verdict)
+	}
+	verdict, _ = rl.Apply("eth0", "", 1, 1, now)
+	if verdict != RATE_LIMIT_INTF_DROP {
+		t.Fatal("Interface limit not applied", verdict)
+	}
+	rl.DeleteIntf("eth0")
+	if len(rl.intfBuckets) != 0 || len(rl.clientLimiters) != 0 {
+		t.Error("Limiters of interface not deleted")
+	}
+}
+
+func TestRateLimiterMaxClients(t *testing.T) {
+	now := time.Now()
+	rl := NewRateLimiter(2)
+	rl.Apply("eth0", "00:00:00:00:00:01", 0, 1, now)
+	rl.Apply("eth0", "00:00:00:00:00:02", 0, 1, now.Add(time.Minute))
+	// Clients beyond the bound are not tracked
+	for i := 0; i < 3; i++ {
+		verdict, _ := rl.Apply("eth0", "00:00:00:00:00:03", 0, 1, now)
+		if verdict != RATE_LIMIT_PASS {
+			t.Fatal("Untracked client dropped", verdict)
+		}
+	}
+	if len(rl.clientLimiters) != 2 {
+		t.Fatal("Client limiters not bounded", len(rl.clientLimiters))
+	}
+	rl.AgeClients(now.Add(CLIENT_RATE_LIMIT_IDLE_TIMEOUT + time.Second))
+	if len(rl.clientLimiters) != 1 {
+		t.Fatal("Idle client not aged", len(rl.clientLimiters))
+	}
+	rl.Apply("eth0", "00:00:00:00:00:03", 0, 1, now)
+	verdict, _ := rl.Apply("eth0", "00:00:00:00:00:03", 0, 1, now)
+	if verdict != RATE_LIMIT_CLIENT_DROP {
+		t.Error("Client not tracked after aging", verdict)
+	}
+}
@@ -0,0 +1,250 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package infra
+
+import (
+	"errors"
+	"net"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+)
+
+const (
+	DEFAULT_VRF = "default"
+)
+
+// Caller closes the socket on error
+func setVrfSockOpts(fd int, family int, vrf string) error {
+	err := syscall.SetsockoptInt(fd, syscall.SOL_SOCKET,
+		syscall.SO_REUSEADDR, 1)
+	if err != nil {
+		return os.NewSyscallError("setsockopt", err)
+	}
+	if family == syscall.AF_INET6 {
+		err = syscall.SetsockoptInt(fd, syscall.IPPROTO_IPV6,
+			syscall.IPV6_V6ONLY, 1)
+		if err != nil {
+			return os.NewSyscallError("setsockopt", err)
+		}
+	}
+	if vrf == "" || vrf == DEFAULT_VRF {
+		return nil
+	}
+	err = syscall.SetsockoptString(fd, syscall.SOL_SOCKET,
+		syscall.SO_BINDTODEVICE, vrf)
+	if err != nil {
+		return os.NewSyscallError("setsockopt", err)
+	}
+	return nil
+}
+
+// Opens a UDP socket of network "udp4" or "udp6" in the VRF. Sockets of
+// every VRF share the DHCP port so SO_REUSEADDR is set on all of them,
+// sockets of a non default VRF are bound to the VRF device so that both rx
+// and tx are in the VRF's routing domain. The default VRF has no device,
+// its socket only receives on interfaces that are not enslaved to a VRF
+// device. The socket options need to be set before bind, so the socket is
+// created with syscalls and handed over to net afterwards
+func ListenUDPInVrf(network string, vrf string,
+	addr *net.UDPAddr) (*net.UDPConn, error) {
+
+	var family int
+	var sa syscall.Sockaddr
+	switch network {
+	case "udp4":
+		sa4 := &syscall.SockaddrInet4{Port: addr.Port}
+		if ip := addr.IP.To4(); ip != nil {
+			copy(sa4.Addr[:], ip)
+		}
+		family, sa = syscall.AF_INET, sa4
+	case "udp6":
+		sa6 := &syscall.SockaddrInet6{Port: addr.Port}
+		if ip := addr.IP.To16(); ip != nil {
+			copy(sa6.Addr[:], ip)
+		}
+		family, sa = syscall.AF_INET6, sa6
+	default:
+		return nil, errors.New("Unsupported network " + network)
+	}
+	fd, err := syscall.Socket(family, syscall.SOCK_DGRAM,
+		syscall.IPPROTO_UDP)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	syscall.CloseOnExec(fd)
+	if err = setVrfSockOpts(fd, family, vrf); err != nil {
+		syscall.Close(fd)
+		return nil, err
+	}
+	if err = syscall.Bind(fd, sa); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("bind", err)
+	}
+	// net works on a dup of the socket
+	file := os.NewFile(uintptr(fd), network+"-"+vrf)
+	conn, err := net.FilePacketConn(file)
+	file.Close()
+	if err != nil {
+		return nil, err
+	}
+	return conn.(*net.UDPConn), nil
+}
+
+// Address the VRF sends packets to dst from. Connecting a UDP socket of the
+// VRF makes the kernel look up the route and pick the source address,
+// nothing is sent
+func GetIPv4SrcAddrInVrf(vrf string, dst *net.UDPAddr) (net.IP, error) {
+	dstIp := dst.IP.To4()
+	if dstIp == nil {
+		return nil, errors.New("Not an IPv4 address " + dst.IP.String())
+	}
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM,
+		syscall.IPPROTO_UDP)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	defer syscall.Close(fd)
+	if vrf != "" && vrf != DEFAULT_VRF {
+		err = syscall.SetsockoptString(fd, syscall.SOL_SOCKET,
+			syscall.SO_BINDTODEVICE, vrf)
+		if err != nil {
+			return nil, os.NewSyscallError("setsockopt", err)
+		}
+	}
+	sa := &syscall.SockaddrInet4{Port: dst.Port}
+	copy(sa.Addr[:], dstIp)
+	if err = syscall.Connect(fd, sa); err != nil {
+		return nil, os.NewSyscallError("connect", err)
+	}
+	localSa, err := syscall.Getsockname(fd)
+	if err != nil {
+		return nil, os.NewSyscallError("getsockname", err)
+	}
+	localSa4, ok := localSa.(*syscall.SockaddrInet4)
+	if !ok {
+		return nil, errors.New("No IPv4 source address to " + dstIp.String())
+	}
+	srcIp := make(net.IP, net.IPv4len)
+	copy(srcIp, localSa4.Addr[:])
+	return srcIp, nil
+}
+
+// Socket of a relay VRF
+type VrfConn interface {
+	Close() error
+}
+
+// Sockets of the VRFs a relay processor is active in. Open creates the
+// socket of a VRF and Rx is started in its own go routine for every socket
+// opened
+type VrfConnMgr struct {
+	mutex sync.Mutex
+	conns map[string]VrfConn
+	Open  func(vrf string) (VrfConn, error)
+	Rx    func(vrf string, conn VrfConn)
+}
+
+func NewVrfConnMgr(open func(vrf string) (VrfConn, error),
+	rx func(vrf string, conn VrfConn)) *VrfConnMgr {
+	return &VrfConnMgr{
+		conns: make(map[string]VrfConn),
+		Open:  open,
+		Rx:    rx,
+	}
+}
+
+// Opens sockets for the VRFs not opened yet and closes the sockets of the
+// VRFs no longer needed. VRFs whose socket fails to open are reported in the
+// error and retried on the next Sync
+func (cMgr *VrfConnMgr) Sync(vrfs []string) error {
+	needed := make(map[string]bool)
+	for _, vrf := range vrfs {
+		needed[vrf] = true
+	}
+
+	defer cMgr.mutex.Unlock()
+	cMgr.mutex.Lock()
+
+	for vrf, conn := range cMgr.conns {
+		if !needed[vrf] {
+			cMgr.closeConn(vrf, conn)
+		}
+	}
+	var errMsgs []string
+	for _, vrf := range vrfs {
+		if _, ok := cMgr.conns[vrf]; ok {
+			continue
+		}
+		conn, err := cMgr.Open(vrf)
+		if err != nil {
+			errMsgs = append(errMsgs, "Vrf "+vrf+": "+err.Error())
+			continue
+		}
+		cMgr.conns[vrf] = conn
+		go cMgr.Rx(vrf, conn)
+	}
+	if len(errMsgs) > 0 {
+		sort.Strings(errMsgs)
+		return errors.New("Opening socket failed in " +
+			strings.Join(errMsgs, ", "))
+	}
+	return nil
+}
+
+// Caller needs to hold the mutex
+func (cMgr *VrfConnMgr) closeConn(vrf string, conn VrfConn) {
+	conn.Close()
+	delete(cMgr.conns, vrf)
+}
+
+func (cMgr *VrfConnMgr) CloseAll() {
+	defer cMgr.mutex.Unlock()
+	cMgr.mutex.Lock()
+
+	for vrf, conn := range cMgr.conns {
+		cMgr.closeConn(vrf, conn)
+	}
+}
+
+// Removes the socket of the Rx go routine once it fails, unless the socket
+// was already replaced
+func (cMgr *VrfConnMgr) Release(vrf string, conn VrfConn) {
+	defer cMgr.mutex.Unlock()
+	cMgr.mutex.Lock()
+
+	if cMgr.conns[vrf] == conn {
+		delete(cMgr.conns, vrf)
+	}
+}
+
+func (cMgr *VrfConnMgr) Get(vrf string) (VrfConn, bool) {
+	defer cMgr.mutex.Unlock()
+	cMgr.mutex.Lock()
+
+	conn, ok := cMgr.conns[vrf]
+	return conn, ok
+}
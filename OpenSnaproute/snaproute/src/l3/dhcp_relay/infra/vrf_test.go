@@ -0,0 +1,147 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package infra
+
+import (
+	"errors"
+	"net"
+	"strings"
+	"testing"
+)
+
+type testVrfConn struct {
+	closed bool
+}
+
+func (conn *testVrfConn) Close() error {
+	conn.closed = true
+	return nil
+}
+
+func newTestVrfConnMgr(rxCh chan string) *VrfConnMgr {
+	return NewVrfConnMgr(
+		func(vrf string) (VrfConn, error) {
+			if strings.HasPrefix(vrf, "down") {
+				return nil, errors.New("no such device")
+			}
+			return &testVrfConn{}, nil
+		},
+		func(vrf string, conn VrfConn) {
+			rxCh <- vrf
+		})
+}
+
+func TestVrfConnMgrSync(t *testing.T) {
+	rxCh := make(chan string, 8)
+	cMgr := newTestVrfConnMgr(rxCh)
+	err := cMgr.Sync([]string{"default", "down2", "tenant1", "down1"})
+	if err == nil {
+		t.Fatal("Failure to open sockets not reported")
+	}
+	// Failed VRFs are reported in a stable order
+	if !strings.Contains(err.Error(), "Vrf down1: no such device, Vrf down2") {
+		t.Error("Wrong error", err)
+	}
+	for i := 0; i < 2; i++ {
+		vrf := <-rxCh
+		if vrf != "default" && vrf != "tenant1" {
+			t.Error("Rx started for", vrf)
+		}
+	}
+	defaultConn, ok := cMgr.Get("default")
+	if !ok {
+		t.Fatal("Socket of default Vrf not opened")
+	}
+	if _, ok := cMgr.Get("down1"); ok {
+		t.Error("Socket of failed Vrf stored")
+	}
+	tenantConn, _ := cMgr.Get("tenant1")
+	if err := cMgr.Sync([]string{"default"}); err != nil {
+		t.Fatal("Sync failed", err)
+	}
+	if _, ok := cMgr.Get("tenant1"); ok || !tenantConn.(*testVrfConn).closed {
+		t.Error("Socket of unneeded Vrf not closed")
+	}
+	if conn, _ := cMgr.Get("default"); conn != defaultConn {
+		t.Error("Socket of needed Vrf reopened")
+	}
+	if len(rxCh) != 0 {
+		t.Error("Rx restarted for an open socket")
+	}
+}
+
+func TestVrfConnMgrRelease(t *testing.T) {
+	rxCh := make(chan string, 8)
+	cMgr := newTestVrfConnMgr(rxCh)
+	cMgr.Sync([]string{"default", "tenant1"})
+	oldConn, _ := cMgr.Get("default")
+	cMgr.Release("default", oldConn)
+	if _, ok := cMgr.Get("default"); ok {
+		t.Fatal("Socket not released")
+	}
+	// Released sockets are reopened on the next sync
+	cMgr.Sync([]string{"default", "tenant1"})
+	newConn, ok := cMgr.Get("default")
+	if !ok || newConn == oldConn {
+		t.Fatal("Released socket not reopened")
+	}
+	// Stale Rx of a replaced socket must not release the new one
+	cMgr.Release("default", oldConn)
+	if _, ok := cMgr.Get("default"); !ok {
+		t.Error("Replaced socket released")
+	}
+	cMgr.CloseAll()
+	if _, ok := cMgr.Get("tenant1"); ok || !newConn.(*testVrfConn).closed {
+		t.Error("Sockets not closed")
+	}
+}
+
+// Sockets of every VRF are opened on the same port
+func TestListenUDPInVrfReuseAddr(t *testing.T) {
+	addr := &net.UDPAddr{IP: net.ParseIP("127.0.0.1")}
+	conn1, err := ListenUDPInVrf("udp4", DEFAULT_VRF, addr)
+	if err != nil {
+		t.Fatal("Opening socket failed", err)
+	}
+	defer conn1.Close()
+	addr.Port = conn1.LocalAddr().(*net.UDPAddr).Port
+	conn2, err := ListenUDPInVrf("udp4", "", addr)
+	if err != nil {
+		t.Fatal("Opening second socket on port", addr.Port, "failed", err)
+	}
+	conn2.Close()
+}
+
+func TestGetIPv4SrcAddrInVrf(t *testing.T) {
+	srcIp, err := GetIPv4SrcAddrInVrf(DEFAULT_VRF,
+		&net.UDPAddr{IP: net.ParseIP("127.0.0.1"), Port: 67})
+	if err != nil || !srcIp.Equal(net.ParseIP("127.0.0.1")) {
+		t.Error("Wrong source address", srcIp, err)
+	}
+	_, err = GetIPv4SrcAddrInVrf(DEFAULT_VRF,
+		&net.UDPAddr{IP: net.ParseIP("::1"), Port: 67})
+	if err == nil {
+		t.Error("Source address of IPv6 destination")
+	}
+}
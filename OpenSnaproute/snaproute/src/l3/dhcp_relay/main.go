@@ -56,6 +56,8 @@ func main() {
 	serverInitParams := &server.ServerInitParams{
 		DmnName:     DMN_NAME,
 		CfgFileName: dmn.ParamsDir + "clients.json",
+		ParamsDir:   dmn.ParamsDir,
+		ClientsList: dmn.FSBaseDmn.ClientsList,
 		DbHdl:       dmn.DbHdl,
 		Logger:      dmn.FSBaseDmn.Logger,
 	}
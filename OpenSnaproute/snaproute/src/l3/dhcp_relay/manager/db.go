@@ -25,14 +25,32 @@ package manager
 
 import (
 	"dhcprelayd"
+	"l3/dhcp_relay/protocol/dhcp4"
+	"l3/dhcp_relay/protocol/dhcp6"
 	"models/objects"
+	"reflect"
+	"time"
+)
+
+const (
+	STATE_CHECKPOINT_INTERVAL = 30 * time.Second
 )
 
 func convertDRAv4IntfObjToThriftType(obj *objects.DHCPRelayIntf) *dhcprelayd.DHCPRelayIntf {
 	thriftObj := &dhcprelayd.DHCPRelayIntf{
-		IntfRef:  obj.IntfRef,
-		Enable:   obj.Enable,
-		ServerIp: obj.ServerIp,
+		IntfRef:               obj.IntfRef,
+		Enable:                obj.Enable,
+		ServerIp:              obj.ServerIp,
+		RelayAgentInfo:        obj.RelayAgentInfo,
+		CircuitIdType:         obj.CircuitIdType,
+		RemoteId:              obj.RemoteId,
+		RelayAgentInfoPolicy:  obj.RelayAgentInfoPolicy,
+		ServerSelection:       obj.ServerSelection,
+		ServerTimeout:         obj.ServerTimeout,
+		Vrf:                   obj.Vrf,
+		RateLimit:             obj.RateLimit,
+		ClientRateLimit:       obj.ClientRateLimit,
+		RelayAgentInfoTrusted: obj.RelayAgentInfoTrusted,
 	}
 	return thriftObj
 }
@@ -42,15 +60,31 @@ func convertDRAv4GlobalObjToThriftType(obj *objects.DHCPRelayGlobal) *dhcprelayd
 		Vrf:           obj.Vrf,
 		Enable:        obj.Enable,
 		HopCountLimit: obj.HopCountLimit,
+		Snooping:      obj.Snooping,
+		ServerVrf:     obj.ServerVrf,
+	}
+	return thriftObj
+}
+
+func convertSnoopingIntfObjToThriftType(obj *objects.DHCPRelaySnoopingIntf) *dhcprelayd.DHCPRelaySnoopingIntf {
+	thriftObj := &dhcprelayd.DHCPRelaySnoopingIntf{
+		IntfRef: obj.IntfRef,
+		Trusted: obj.Trusted,
 	}
 	return thriftObj
 }
 
 func convertDRAv6IntfObjToThriftType(obj *objects.DHCPv6RelayIntf) *dhcprelayd.DHCPv6RelayIntf {
 	thriftObj := &dhcprelayd.DHCPv6RelayIntf{
-		IntfRef:  obj.IntfRef,
-		Enable:   obj.Enable,
-		ServerIp: obj.ServerIp,
+		IntfRef:               obj.IntfRef,
+		Enable:                obj.Enable,
+		ServerIp:              obj.ServerIp,
+		InterfaceId:           obj.InterfaceId,
+		RemoteId:              obj.RemoteId,
+		RemoteIdEnterpriseNum: obj.RemoteIdEnterpriseNum,
+		Vrf:                   obj.Vrf,
+		RateLimit:             obj.RateLimit,
+		ClientRateLimit:       obj.ClientRateLimit,
 	}
 	return thriftObj
 }
@@ -60,24 +94,223 @@ func convertDRAv6GlobalObjToThriftType(obj *objects.DHCPv6RelayGlobal) *dhcprela
 		Vrf:           obj.Vrf,
 		Enable:        obj.Enable,
 		HopCountLimit: obj.HopCountLimit,
+		ServerVrf:     obj.ServerVrf,
+	}
+	return thriftObj
+}
+
+func convertDRAv4ClientStateToObj(state *dhcprelayd.DHCPRelayClientState) objects.DHCPRelayClientState {
+	return objects.DHCPRelayClientState{
+		MacAddr:         state.MacAddr,
+		ServerIp:        state.ServerIp,
+		OfferedIp:       state.OfferedIp,
+		GatewayIp:       state.GatewayIp,
+		AcceptedIp:      state.AcceptedIp,
+		RequestedIp:     state.RequestedIp,
+		ClientDiscover:  state.ClientDiscover,
+		ClientRequest:   state.ClientRequest,
+		ClientRequests:  state.ClientRequests,
+		ClientResponses: state.ClientResponses,
+		ServerOffer:     state.ServerOffer,
+		ServerAck:       state.ServerAck,
+		ServerRequests:  state.ServerRequests,
+		ServerResponses: state.ServerResponses,
+		Vrf:             state.Vrf,
+	}
+}
+
+func convertDRAv4ClientStateObjToThriftType(obj *objects.DHCPRelayClientState) *dhcprelayd.DHCPRelayClientState {
+	thriftObj := &dhcprelayd.DHCPRelayClientState{
+		MacAddr:         obj.MacAddr,
+		ServerIp:        obj.ServerIp,
+		OfferedIp:       obj.OfferedIp,
+		GatewayIp:       obj.GatewayIp,
+		AcceptedIp:      obj.AcceptedIp,
+		RequestedIp:     obj.RequestedIp,
+		ClientDiscover:  obj.ClientDiscover,
+		ClientRequest:   obj.ClientRequest,
+		ClientRequests:  obj.ClientRequests,
+		ClientResponses: obj.ClientResponses,
+		ServerOffer:     obj.ServerOffer,
+		ServerAck:       obj.ServerAck,
+		ServerRequests:  obj.ServerRequests,
+		ServerResponses: obj.ServerResponses,
+		Vrf:             obj.Vrf,
 	}
 	return thriftObj
 }
 
-func (draMgr *DRAMgr) readDRAv4GlobalConfig() (*dhcprelayd.DHCPRelayGlobal, error) {
+func convertDRAv4IntfStateToObj(state *dhcprelayd.DHCPRelayIntfState) objects.DHCPRelayIntfState {
+	return objects.DHCPRelayIntfState{
+		IntfRef:                state.IntfRef,
+		TotalDrops:             state.TotalDrops,
+		TotalDhcpClientRx:      state.TotalDhcpClientRx,
+		TotalDhcpClientTx:      state.TotalDhcpClientTx,
+		TotalDhcpServerRx:      state.TotalDhcpServerRx,
+		TotalDhcpServerTx:      state.TotalDhcpServerTx,
+		RelayAgentInfoInserted: state.RelayAgentInfoInserted,
+		RelayAgentInfoKept:     state.RelayAgentInfoKept,
+		RelayAgentInfoReplaced: state.RelayAgentInfoReplaced,
+		RelayAgentInfoDropped:  state.RelayAgentInfoDropped,
+		RelayAgentInfoStripped: state.RelayAgentInfoStripped,
+		HopCountDrops:          state.HopCountDrops,
+		SnoopingDrops:          state.SnoopingDrops,
+		ServerSelection:        state.ServerSelection,
+		Vrf:                    state.Vrf,
+		RateLimitDrops:         state.RateLimitDrops,
+		ClientRateLimitDrops:   state.ClientRateLimitDrops,
+		LoopDrops:              state.LoopDrops,
+	}
+}
+
+func convertDRAv4IntfStateObjToThriftType(obj *objects.DHCPRelayIntfState) *dhcprelayd.DHCPRelayIntfState {
+	thriftObj := &dhcprelayd.DHCPRelayIntfState{
+		IntfRef:                obj.IntfRef,
+		TotalDrops:             obj.TotalDrops,
+		TotalDhcpClientRx:      obj.TotalDhcpClientRx,
+		TotalDhcpClientTx:      obj.TotalDhcpClientTx,
+		TotalDhcpServerRx:      obj.TotalDhcpServerRx,
+		TotalDhcpServerTx:      obj.TotalDhcpServerTx,
+		RelayAgentInfoInserted: obj.RelayAgentInfoInserted,
+		RelayAgentInfoKept:     obj.RelayAgentInfoKept,
+		RelayAgentInfoReplaced: obj.RelayAgentInfoReplaced,
+		RelayAgentInfoDropped:  obj.RelayAgentInfoDropped,
+		RelayAgentInfoStripped: obj.RelayAgentInfoStripped,
+		HopCountDrops:          obj.HopCountDrops,
+		SnoopingDrops:          obj.SnoopingDrops,
+		ServerSelection:        obj.ServerSelection,
+		Vrf:                    obj.Vrf,
+		RateLimitDrops:         obj.RateLimitDrops,
+		ClientRateLimitDrops:   obj.ClientRateLimitDrops,
+		LoopDrops:              obj.LoopDrops,
+	}
+	return thriftObj
+}
+
+func convertDRAv4IntfServerStateToObj(state *dhcprelayd.DHCPRelayIntfServerState) objects.DHCPRelayIntfServerState {
+	return objects.DHCPRelayIntfServerState{
+		IntfRef:      state.IntfRef,
+		ServerIp:     state.ServerIp,
+		Request:      state.Request,
+		Responses:    state.Responses,
+		Health:       state.Health,
+		LastResponse: state.LastResponse,
+		Vrf:          state.Vrf,
+	}
+}
+
+func convertDRAv4IntfServerStateObjToThriftType(obj *objects.DHCPRelayIntfServerState) *dhcprelayd.DHCPRelayIntfServerState {
+	thriftObj := &dhcprelayd.DHCPRelayIntfServerState{
+		IntfRef:      obj.IntfRef,
+		ServerIp:     obj.ServerIp,
+		Request:      obj.Request,
+		Responses:    obj.Responses,
+		Health:       obj.Health,
+		LastResponse: obj.LastResponse,
+		Vrf:          obj.Vrf,
+	}
+	return thriftObj
+}
+
+func convertDRAv4BindingStateToObj(state *dhcprelayd.DHCPRelayBindingState) objects.DHCPRelayBindingState {
+	return objects.DHCPRelayBindingState{
+		MacAddr:     state.MacAddr,
+		XId:         state.XId,
+		IntfRef:     state.IntfRef,
+		Vlan:        state.Vlan,
+		OfferedIp:   state.OfferedIp,
+		AcceptedIp:  state.AcceptedIp,
+		LeaseTime:   state.LeaseTime,
+		LeaseExpiry: state.LeaseExpiry,
+		State:       state.State,
+		Vrf:         state.Vrf,
+	}
+}
+
+func convertDRAv4BindingStateObjToThriftType(obj *objects.DHCPRelayBindingState) *dhcprelayd.DHCPRelayBindingState {
+	thriftObj := &dhcprelayd.DHCPRelayBindingState{
+		MacAddr:     obj.MacAddr,
+		XId:         obj.XId,
+		IntfRef:     obj.IntfRef,
+		Vlan:        obj.Vlan,
+		OfferedIp:   obj.OfferedIp,
+		AcceptedIp:  obj.AcceptedIp,
+		LeaseTime:   obj.LeaseTime,
+		LeaseExpiry: obj.LeaseExpiry,
+		State:       obj.State,
+		Vrf:         obj.Vrf,
+	}
+	return thriftObj
+}
+
+func convertSnoopingBindingStateToObj(state *dhcprelayd.DHCPRelaySnoopingBindingState) objects.DHCPRelaySnoopingBindingState {
+	return objects.DHCPRelaySnoopingBindingState{
+		MacAddr:     state.MacAddr,
+		IpAddr:      state.IpAddr,
+		Vlan:        state.Vlan,
+		IntfRef:     state.IntfRef,
+		LeaseTime:   state.LeaseTime,
+		LeaseExpiry: state.LeaseExpiry,
+	}
+}
+
+func convertSnoopingBindingStateObjToThriftType(obj *objects.DHCPRelaySnoopingBindingState) *dhcprelayd.DHCPRelaySnoopingBindingState {
+	thriftObj := &dhcprelayd.DHCPRelaySnoopingBindingState{
+		MacAddr:     obj.MacAddr,
+		IpAddr:      obj.IpAddr,
+		Vlan:        obj.Vlan,
+		IntfRef:     obj.IntfRef,
+		LeaseTime:   obj.LeaseTime,
+		LeaseExpiry: obj.LeaseExpiry,
+	}
+	return thriftObj
+}
+
+func convertDRAv6PDStateToObj(state *dhcprelayd.DHCPv6RelayPDState) objects.DHCPv6RelayPDState {
+	return objects.DHCPv6RelayPDState{
+		Prefix:            state.Prefix,
+		IntfRef:           state.IntfRef,
+		NextHopIp:         state.NextHopIp,
+		ClientMacAddr:     state.ClientMacAddr,
+		PreferredLifetime: state.PreferredLifetime,
+		ValidLifetime:     state.ValidLifetime,
+		LeaseExpiry:       state.LeaseExpiry,
+		RouteInstalled:    state.RouteInstalled,
+		Vrf:               state.Vrf,
+	}
+}
+
+func convertDRAv6PDStateObjToThriftType(obj *objects.DHCPv6RelayPDState) *dhcprelayd.DHCPv6RelayPDState {
+	thriftObj := &dhcprelayd.DHCPv6RelayPDState{
+		Prefix:            obj.Prefix,
+		IntfRef:           obj.IntfRef,
+		NextHopIp:         obj.NextHopIp,
+		ClientMacAddr:     obj.ClientMacAddr,
+		PreferredLifetime: obj.PreferredLifetime,
+		ValidLifetime:     obj.ValidLifetime,
+		LeaseExpiry:       obj.LeaseExpiry,
+		RouteInstalled:    obj.RouteInstalled,
+		Vrf:               obj.Vrf,
+	}
+	return thriftObj
+}
+
+// One relay instance per Vrf
+func (draMgr *DRAMgr) readDRAv4GlobalConfig() ([]*dhcprelayd.DHCPRelayGlobal, error) {
 	draMgr.Logger.Info("Reading DRAv4Global from db")
 	var dbObj objects.DHCPRelayGlobal
+	result := []*dhcprelayd.DHCPRelayGlobal{}
 	objList, err := draMgr.DbHdl.GetAllObjFromDb(dbObj)
 	if err != nil {
 		return nil, err
 	}
-	if len(objList) == 0 {
-		return nil, nil
-	}
 	draMgr.Logger.Info("Objects from db are", objList)
-	dbEntry := objList[0].(objects.DHCPRelayGlobal)
-	thriftObj := convertDRAv4GlobalObjToThriftType(&dbEntry)
-	return thriftObj, nil
+	for _, obj := range objList {
+		dbEntry := obj.(objects.DHCPRelayGlobal)
+		thriftObj := convertDRAv4GlobalObjToThriftType(&dbEntry)
+		result = append(result, thriftObj)
+	}
+	return result, nil
 }
 
 func (draMgr *DRAMgr) readDRAv4IntfConfig() ([]*dhcprelayd.DHCPRelayIntf, error) {
@@ -99,20 +332,39 @@ func (draMgr *DRAMgr) readDRAv4IntfConfig() ([]*dhcprelayd.DHCPRelayIntf, error)
 	return result, nil
 }
 
-func (draMgr *DRAMgr) readDRAv6GlobalConfig() (*dhcprelayd.DHCPv6RelayGlobal, error) {
+func (draMgr *DRAMgr) readSnoopingIntfConfig() ([]*dhcprelayd.DHCPRelaySnoopingIntf, error) {
+	draMgr.Logger.Info("Reading DRAv4 SnoopingIntf from db")
+	var dbObj objects.DHCPRelaySnoopingIntf
+	result := []*dhcprelayd.DHCPRelaySnoopingIntf{}
+	objList, err := draMgr.DbHdl.GetAllObjFromDb(dbObj)
+	if err != nil {
+		return nil, err
+	}
+	draMgr.Logger.Info("Objects from db are", objList)
+	for _, obj := range objList {
+		dbEntry := obj.(objects.DHCPRelaySnoopingIntf)
+		thriftObj := convertSnoopingIntfObjToThriftType(&dbEntry)
+		result = append(result, thriftObj)
+	}
+	return result, nil
+}
+
+// One relay instance per Vrf
+func (draMgr *DRAMgr) readDRAv6GlobalConfig() ([]*dhcprelayd.DHCPv6RelayGlobal, error) {
 	draMgr.Logger.Info("Reading DRAv6Global from db")
 	var dbObj objects.DHCPv6RelayGlobal
+	result := []*dhcprelayd.DHCPv6RelayGlobal{}
 	objList, err := draMgr.DbHdl.GetAllObjFromDb(dbObj)
 	if err != nil {
 		return nil, err
 	}
-	if len(objList) == 0 {
-		return nil, nil
-	}
 	draMgr.Logger.Info("Objects from db are", objList)
-	dbEntry := objList[0].(objects.DHCPv6RelayGlobal)
-	thriftObj := convertDRAv6GlobalObjToThriftType(&dbEntry)
-	return thriftObj, nil
+	for _, obj := range objList {
+		dbEntry := obj.(objects.DHCPv6RelayGlobal)
+		thriftObj := convertDRAv6GlobalObjToThriftType(&dbEntry)
+		result = append(result, thriftObj)
+	}
+	return result, nil
 }
 
 func (draMgr *DRAMgr) readDRAv6IntfConfig() ([]*dhcprelayd.DHCPv6RelayIntf, error) {
@@ -133,3 +385,171 @@ func (draMgr *DRAMgr) readDRAv6IntfConfig() ([]*dhcprelayd.DHCPv6RelayIntf, erro
 	}
 	return result, nil
 }
+
+// Reads the relay state checkpointed before a restart, the objects read are
+// remembered so that stale entries get removed by the next checkpoint
+func (draMgr *DRAMgr) readDRAv4StateCheckpoint() (*dhcp4.StateCheckpoint, error) {
+	draMgr.Logger.Info("Reading DRAv4 state checkpoint from db")
+	checkpoint := &dhcp4.StateCheckpoint{}
+	objList, err := draMgr.DbHdl.GetAllObjFromDb(objects.DHCPRelayClientState{})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objList {
+		dbEntry := obj.(objects.DHCPRelayClientState)
+		draMgr.CheckpointObjs["ClientState_"+dbEntry.MacAddr] = dbEntry
+		checkpoint.ClientStates = append(checkpoint.ClientStates,
+			convertDRAv4ClientStateObjToThriftType(&dbEntry))
+	}
+	objList, err = draMgr.DbHdl.GetAllObjFromDb(objects.DHCPRelayIntfState{})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objList {
+		dbEntry := obj.(objects.DHCPRelayIntfState)
+		draMgr.CheckpointObjs["IntfState_"+dbEntry.IntfRef] = dbEntry
+		checkpoint.IntfStates = append(checkpoint.IntfStates,
+			convertDRAv4IntfStateObjToThriftType(&dbEntry))
+	}
+	objList, err = draMgr.DbHdl.GetAllObjFromDb(objects.DHCPRelayIntfServerState{})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objList {
+		dbEntry := obj.(objects.DHCPRelayIntfServerState)
+		draMgr.CheckpointObjs["IntfServerState_"+dbEntry.IntfRef+"_"+dbEntry.ServerIp] = dbEntry
+		checkpoint.IntfServerStates = append(checkpoint.IntfServerStates,
+			convertDRAv4IntfServerStateObjToThriftType(&dbEntry))
+	}
+	objList, err = draMgr.DbHdl.GetAllObjFromDb(objects.DHCPRelayBindingState{})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objList {
+		dbEntry := obj.(objects.DHCPRelayBindingState)
+		draMgr.CheckpointObjs["BindingState_"+dbEntry.MacAddr+"_"+dbEntry.XId] = dbEntry
+		checkpoint.BindingStates = append(checkpoint.BindingStates,
+			convertDRAv4BindingStateObjToThriftType(&dbEntry))
+	}
+	objList, err = draMgr.DbHdl.GetAllObjFromDb(objects.DHCPRelaySnoopingBindingState{})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objList {
+		dbEntry := obj.(objects.DHCPRelaySnoopingBindingState)
+		draMgr.CheckpointObjs["SnoopingBindingState_"+dbEntry.MacAddr] = dbEntry
+		checkpoint.SnoopingBindingStates = append(
+			checkpoint.SnoopingBindingStates,
+			convertSnoopingBindingStateObjToThriftType(&dbEntry))
+	}
+	return checkpoint, nil
+}
+
+// Only objects which changed since the last checkpoint are written, objects
+// whose write failed are retried by the next checkpoint. Returns the objects
+// now in DB
+func (draMgr *DRAMgr) writeCheckpointObjs(lastObjs,
+	checkpointObjs map[string]objects.ConfigObj) map[string]objects.ConfigObj {
+
+	dbObjs := make(map[string]objects.ConfigObj)
+	for key, obj := range lastObjs {
+		if _, ok := checkpointObjs[key]; ok {
+			continue
+		}
+		if err := draMgr.DbHdl.DeleteObjectFromDb(obj); err != nil {
+			draMgr.Logger.Err("DB Delete failed for", key, err)
+			dbObjs[key] = obj
+		}
+	}
+	for key, obj := range checkpointObjs {
+		if lastObj, ok := lastObjs[key]; ok && reflect.DeepEqual(lastObj, obj) {
+			dbObjs[key] = obj
+			continue
+		}
+		if err := draMgr.DbHdl.StoreObjectInDb(obj); err != nil {
+			draMgr.Logger.Err("DB Store failed for", key, err)
+			continue
+		}
+		dbObjs[key] = obj
+	}
+	return dbObjs
+}
+
+func (draMgr *DRAMgr) writeDRAv4StateCheckpoint(checkpoint *dhcp4.StateCheckpoint) {
+	checkpointObjs := make(map[string]objects.ConfigObj)
+	for _, state := range checkpoint.ClientStates {
+		checkpointObjs["ClientState_"+state.MacAddr] =
+			convertDRAv4ClientStateToObj(state)
+	}
+	for _, state := range checkpoint.IntfStates {
+		checkpointObjs["IntfState_"+state.IntfRef] =
+			convertDRAv4IntfStateToObj(state)
+	}
+	for _, state := range checkpoint.IntfServerStates {
+		checkpointObjs["IntfServerState_"+state.IntfRef+"_"+state.ServerIp] =
+			convertDRAv4IntfServerStateToObj(state)
+	}
+	for _, state := range checkpoint.BindingStates {
+		checkpointObjs["BindingState_"+state.MacAddr+"_"+state.XId] =
+			convertDRAv4BindingStateToObj(state)
+	}
+	for _, state := range checkpoint.SnoopingBindingStates {
+		checkpointObjs["SnoopingBindingState_"+state.MacAddr] =
+			convertSnoopingBindingStateToObj(state)
+	}
+	draMgr.CheckpointObjs = draMgr.writeCheckpointObjs(
+		draMgr.CheckpointObjs, checkpointObjs)
+}
+
+// Delegated prefixes are read back so that their routes are either kept or
+// removed from RIB after a restart
+func (draMgr *DRAMgr) readDRAv6StateCheckpoint() (*dhcp6.StateCheckpoint, error) {
+	draMgr.Logger.Info("Reading DRAv6 state checkpoint from db")
+	checkpoint := &dhcp6.StateCheckpoint{}
+	objList, err := draMgr.DbHdl.GetAllObjFromDb(objects.DHCPv6RelayPDState{})
+	if err != nil {
+		return nil, err
+	}
+	for _, obj := range objList {
+		dbEntry := obj.(objects.DHCPv6RelayPDState)
+		draMgr.PDCheckpointObjs["PDState_"+dbEntry.Vrf+"_"+dbEntry.Prefix] =
+			dbEntry
+		checkpoint.PDStates = append(checkpoint.PDStates,
+			convertDRAv6PDStateObjToThriftType(&dbEntry))
+	}
+	return checkpoint, nil
+}
+
+func (draMgr *DRAMgr) writeDRAv6StateCheckpoint(checkpoint *dhcp6.StateCheckpoint) {
+	checkpointObjs := make(map[string]objects.ConfigObj)
+	for _, state := range checkpoint.PDStates {
+		checkpointObjs["PDState_"+state.Vrf+"_"+state.Prefix] =
+			convertDRAv6PDStateToObj(state)
+	}
+	draMgr.PDCheckpointObjs = draMgr.writeCheckpointObjs(
+		draMgr.PDCheckpointObjs, checkpointObjs)
+}
+
+// Bindings and delegated prefixes are checkpointed as they change, counters
+// periodically. The current state is written once more before stopping
+func (draMgr *DRAMgr) checkpointDRAState(quit chan bool, done chan bool) {
+	ticker := time.NewTicker(STATE_CHECKPOINT_INTERVAL)
+	defer ticker.Stop()
+	stateChangeCh4 := draMgr.PProc4.GetStateChangeCh()
+	stateChangeCh6 := draMgr.PProc6.GetStateChangeCh()
+	for {
+		select {
+		case <-ticker.C:
+			draMgr.writeDRAv4StateCheckpoint(draMgr.PProc4.GetStateCheckpoint())
+		case <-stateChangeCh4:
+			draMgr.writeDRAv4StateCheckpoint(draMgr.PProc4.GetStateCheckpoint())
+		case <-stateChangeCh6:
+			draMgr.writeDRAv6StateCheckpoint(draMgr.PProc6.GetStateCheckpoint())
+		case <-quit:
+			draMgr.writeDRAv4StateCheckpoint(draMgr.PProc4.GetStateCheckpoint())
+			draMgr.writeDRAv6StateCheckpoint(draMgr.PProc6.GetStateCheckpoint())
+			done <- true
+			return
+		}
+	}
+}
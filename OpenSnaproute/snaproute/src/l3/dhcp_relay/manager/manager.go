@@ -30,6 +30,7 @@ import (
 	"l3/dhcp_relay/infra"
 	"l3/dhcp_relay/protocol/dhcp4"
 	"l3/dhcp_relay/protocol/dhcp6"
+	"models/objects"
 	"net"
 	"utils/commonDefs"
 	"utils/dbutils"
@@ -43,6 +44,12 @@ type DRAMgr struct {
 
 	PProc4 IPv4ProcessorIntf
 	PProc6 IPv6ProcessorIntf
+
+	// State objects last written to DB keyed by object type and key
+	CheckpointObjs   map[string]objects.ConfigObj
+	PDCheckpointObjs map[string]objects.ConfigObj
+	CheckpointQuitCh chan bool
+	CheckpointDoneCh chan bool
 }
 
 func NewDRAMgr(logger logging.LoggerIntf,
@@ -52,6 +59,8 @@ func NewDRAMgr(logger logging.LoggerIntf,
 	draMgr.Logger = logger
 	draMgr.DbHdl = dbHdl
 	draMgr.IMgr = infraMgr
+	draMgr.CheckpointObjs = make(map[string]objects.ConfigObj)
+	draMgr.PDCheckpointObjs = make(map[string]objects.ConfigObj)
 	draMgr.PProc4 = dhcp4.NewProcessor(
 		&dhcp4.ProcessorInitParams{
 			Logger:   draMgr.Logger,
@@ -69,11 +78,28 @@ func NewDRAMgr(logger logging.LoggerIntf,
 
 func (draMgr *DRAMgr) InitDRAMgr() bool {
 	draMgr.IMgr.BuildInfra()
-	return draMgr.initDRAv4() && draMgr.initDRAv6()
+	if !draMgr.initDRAv4() || !draMgr.initDRAv6() {
+		return false
+	}
+	draMgr.CheckpointQuitCh = make(chan bool)
+	draMgr.CheckpointDoneCh = make(chan bool)
+	go draMgr.checkpointDRAState(draMgr.CheckpointQuitCh,
+		draMgr.CheckpointDoneCh)
+	return true
+}
+
+// Stops checkpointing once the current state is written to DB
+func (draMgr *DRAMgr) DeinitDRAMgr() {
+	if draMgr.CheckpointQuitCh == nil {
+		return
+	}
+	draMgr.CheckpointQuitCh <- true
+	<-draMgr.CheckpointDoneCh
+	draMgr.CheckpointQuitCh = nil
 }
 
 func (draMgr *DRAMgr) initDRAv4() bool {
-	draGbl, err := draMgr.readDRAv4GlobalConfig()
+	draGbls, err := draMgr.readDRAv4GlobalConfig()
 	if err != nil {
 		draMgr.Logger.Err("DB Read failed:", err)
 		return false
@@ -83,18 +109,34 @@ func (draMgr *DRAMgr) initDRAv4() bool {
 		draMgr.Logger.Err("DB Read failed:", err)
 		return false
 	}
-	if draGbl != nil {
+	snoopingIntfs, err := draMgr.readSnoopingIntfConfig()
+	if err != nil {
+		draMgr.Logger.Err("DB Read failed:", err)
+		return false
+	}
+	for _, draGbl := range draGbls {
 		draMgr.IMgr.UpdateDRAv4Global(draGbl)
 	}
+	for _, snoopingIntf := range snoopingIntfs {
+		draMgr.IMgr.UpdateSnoopingIntf(snoopingIntf)
+		draMgr.PProc4.ProcessCreateSnoopingIntf(snoopingIntf.IntfRef)
+	}
 	for _, draIntf := range draIntfs {
 		draMgr.IMgr.UpdateDRAv4Intf(draIntf)
 		draMgr.PProc4.ProcessCreateDRAIntf(draIntf.IntfRef)
 	}
+	// Restored after the interfaces so that it is reconciled against them
+	checkpoint, err := draMgr.readDRAv4StateCheckpoint()
+	if err != nil {
+		draMgr.Logger.Err("DB Read failed:", err)
+		return false
+	}
+	draMgr.PProc4.RestoreStateCheckpoint(checkpoint)
 	if draMgr.IMgr.GetActiveDRAv4IntfCount() <= 0 {
 		draMgr.PProc4.StopRxTx()
 		return true
 	}
-	draMgr.PProc4.StartRxTx()
+	draMgr.startDRAv4RxTx()
 	for _, ifIdx := range draMgr.IMgr.GetAllActiveDRAv4Intfs() {
 		draMgr.PProc4.ProcessActiveDRAIntf(ifIdx)
 	}
@@ -102,7 +144,7 @@ func (draMgr *DRAMgr) initDRAv4() bool {
 }
 
 func (draMgr *DRAMgr) initDRAv6() bool {
-	draGbl, err := draMgr.readDRAv6GlobalConfig()
+	draGbls, err := draMgr.readDRAv6GlobalConfig()
 	if err != nil {
 		draMgr.Logger.Err("DB Read failed:", err)
 		return false
@@ -112,42 +154,97 @@ func (draMgr *DRAMgr) initDRAv6() bool {
 		draMgr.Logger.Err("DB Read failed:", err)
 		return false
 	}
-	if draGbl != nil {
+	for _, draGbl := range draGbls {
 		draMgr.IMgr.UpdateDRAv6Global(draGbl)
 	}
 	for _, draIntf := range draIntfs {
 		draMgr.IMgr.UpdateDRAv6Intf(draIntf)
 		draMgr.PProc6.ProcessCreateDRAIntf(draIntf.IntfRef)
 	}
+	checkpoint, err := draMgr.readDRAv6StateCheckpoint()
+	if err != nil {
+		draMgr.Logger.Err("DB Read failed:", err)
+		return false
+	}
+	draMgr.PProc6.RestoreStateCheckpoint(checkpoint)
 	if draMgr.IMgr.GetActiveDRAv6IntfCount() <= 0 {
 		draMgr.PProc6.StopRxTx()
 		return true
 	}
-	draMgr.PProc6.StartRxTx()
+	draMgr.startDRAv6RxTx()
 	for _, ifIdx := range draMgr.IMgr.GetAllActiveDRAv6Intfs() {
 		draMgr.PProc6.ProcessActiveDRAIntf(ifIdx)
 	}
 	return true
 }
 
+// Attributes beyond the length of attrSet are treated as not set
+func isAttrSet(attrSet []bool, idx int) bool {
+	return idx < len(attrSet) && attrSet[idx]
+}
+
+// Hop count is carried in a single octet for both DHCPv4 and DHCPv6
+func checkHopCountLimit(hopCountLimit int32) bool {
+	return hopCountLimit > 0 && hopCountLimit <= 255
+}
+
+// Relay keeps running in the VRFs whose socket could be opened, the others
+// are retried on the next start
+func (draMgr *DRAMgr) startDRAv4RxTx() {
+	if err := draMgr.PProc4.StartRxTx(); err != nil {
+		draMgr.Logger.Err("DRA:", err)
+	}
+}
+
+func (draMgr *DRAMgr) startDRAv6RxTx() {
+	if err := draMgr.PProc6.StartRxTx(); err != nil {
+		draMgr.Logger.Err("DRA:", err)
+	}
+}
+
+// Interfaces activated or deactivated by a change of the relay VRFs
+func (draMgr *DRAMgr) processDRAv4IntfsChange(preIfIdxs []int) {
+	preState := make(map[int]bool)
+	for _, ifIdx := range preIfIdxs {
+		preState[ifIdx] = true
+	}
+	postIfIdxs := draMgr.IMgr.GetAllActiveDRAv4Intfs()
+	if len(postIfIdxs) <= 0 {
+		draMgr.PProc4.StopRxTx()
+		return
+	}
+	draMgr.startDRAv4RxTx()
+	for _, ifIdx := range postIfIdxs {
+		if !preState[ifIdx] {
+			draMgr.PProc4.ProcessActiveDRAIntf(ifIdx)
+		}
+		delete(preState, ifIdx)
+	}
+	for ifIdx := range preState {
+		draMgr.PProc4.ProcessInactiveDRAIntf(ifIdx)
+	}
+}
+
 func (draMgr *DRAMgr) CreateDRAv4Global(
 	cfg *dhcprelayd.DHCPRelayGlobal) (bool, error) {
 
-	if cfg.Vrf != "default" {
-		errMsg := fmt.Sprintln("DRA: only \"default\" Vrf supported")
+	if cfg.Vrf == "" {
+		errMsg := fmt.Sprintln("DRA: Vrf is required")
 		draMgr.Logger.Err(errMsg)
 		return false, errors.New(errMsg)
 	}
+	if !checkHopCountLimit(cfg.HopCountLimit) {
+		errMsg := fmt.Sprintln(
+			"DRA: Invalid HopCountLimit:", cfg.HopCountLimit)
+		draMgr.Logger.Err(errMsg)
+		return false, errors.New(errMsg)
+	}
+	preIfIdxs := draMgr.IMgr.GetAllActiveDRAv4Intfs()
 	draMgr.IMgr.UpdateDRAv4Global(cfg)
 	if draMgr.IMgr.GetActiveDRAv4IntfCount() <= 0 {
 		return true, nil
 	}
-	draMgr.PProc4.StartRxTx()
-	for _, ifIdx := range draMgr.IMgr.GetAllActiveDRAv4Intfs() {
-		if _, ok := draMgr.IMgr.GetActiveDRAv4Intf(ifIdx); ok {
-			draMgr.PProc4.ProcessActiveDRAIntf(ifIdx)
-		}
-	}
+	draMgr.processDRAv4IntfsChange(preIfIdxs)
 	return true, nil
 }
 
@@ -156,39 +253,56 @@ func (draMgr *DRAMgr) UpdateDRAv4Global(oldCfg *dhcprelayd.DHCPRelayGlobal,
 	op []*dhcprelayd.PatchOpInfo) (bool, error) {
 
 	cfg := oldCfg
-	if attrSet[2] {
+	if isAttrSet(attrSet, 4) {
+		cfg.ServerVrf = newCfg.ServerVrf
+	}
+	if isAttrSet(attrSet, 3) {
+		cfg.Snooping = newCfg.Snooping
+	}
+	if isAttrSet(attrSet, 2) {
+		if !checkHopCountLimit(newCfg.HopCountLimit) {
+			errMsg := fmt.Sprintln(
+				"DRA: Invalid HopCountLimit:", newCfg.HopCountLimit)
+			draMgr.Logger.Err(errMsg)
+			return false, errors.New(errMsg)
+		}
 		cfg.HopCountLimit = newCfg.HopCountLimit
 	}
-	if attrSet[1] {
+	if isAttrSet(attrSet, 1) {
 		cfg.Enable = newCfg.Enable
 	}
-	draPreState := (draMgr.IMgr.GetActiveDRAv4IntfCount() > 0)
+	preIfIdxs := draMgr.IMgr.GetAllActiveDRAv4Intfs()
 	draMgr.IMgr.UpdateDRAv4Global(cfg)
-	draPostState := (draMgr.IMgr.GetActiveDRAv4IntfCount() > 0)
-	if !draPostState {
-		draMgr.PProc4.StopRxTx()
-		return true, nil
-	}
-	if !draPreState && draPostState {
-		draMgr.PProc4.StartRxTx()
-		for _, ifIdx := range draMgr.IMgr.GetAllActiveDRAv4Intfs() {
-			if _, ok := draMgr.IMgr.GetActiveDRAv4Intf(ifIdx); ok {
-				draMgr.PProc4.ProcessActiveDRAIntf(ifIdx)
-			}
-		}
-	}
+	draMgr.processDRAv4IntfsChange(preIfIdxs)
 	return true, nil
 }
 
 func (draMgr *DRAMgr) DeleteDRAv4Global(Vrf string) (bool, error) {
-	draMgr.IMgr.DeleteDRAv4Global()
-	draMgr.PProc4.StopRxTx()
+	preIfIdxs := draMgr.IMgr.GetAllActiveDRAv4Intfs()
+	draMgr.IMgr.DeleteDRAv4Global(Vrf)
+	draMgr.processDRAv4IntfsChange(preIfIdxs)
 	return true, nil
 }
 
+func (draMgr *DRAMgr) validateRateLimits(
+	rateLimit int32, clientRateLimit int32) error {
+
+	if rateLimit < 0 || clientRateLimit < 0 {
+		errMsg := fmt.Sprintln("DRA: Invalid rate limit", rateLimit,
+			"client rate limit", clientRateLimit)
+		draMgr.Logger.Err(errMsg)
+		return errors.New(errMsg)
+	}
+	return nil
+}
+
 func (draMgr *DRAMgr) CreateDRAv4Interface(
 	cfg *dhcprelayd.DHCPRelayIntf) (bool, error) {
 
+	if err := draMgr.validateRateLimits(
+		cfg.RateLimit, cfg.ClientRateLimit); err != nil {
+		return false, err
+	}
 	for _, val := range cfg.ServerIp {
 		ip := net.ParseIP(val)
 		if ip == nil || ip.To4() == nil {
@@ -210,7 +324,7 @@ func (draMgr *DRAMgr) CreateDRAv4Interface(
 	draMgr.IMgr.UpdateDRAv4Intf(cfg)
 	draMgr.PProc4.ProcessCreateDRAIntf(cfg.IntfRef)
 	if _, ok := draMgr.IMgr.GetActiveDRAv4Intf(ifIdx); ok {
-		draMgr.PProc4.StartRxTx()
+		draMgr.startDRAv4RxTx()
 		draMgr.PProc4.ProcessActiveDRAIntf(ifIdx)
 	}
 	return true, nil
@@ -220,8 +334,46 @@ func (draMgr *DRAMgr) UpdateDRAv4Interface(oldCfg *dhcprelayd.DHCPRelayIntf,
 	newCfg *dhcprelayd.DHCPRelayIntf, attrSet []bool,
 	op []*dhcprelayd.PatchOpInfo) (bool, error) {
 
+	if isAttrSet(attrSet, 10) || isAttrSet(attrSet, 11) {
+		if err := draMgr.validateRateLimits(
+			newCfg.RateLimit, newCfg.ClientRateLimit); err != nil {
+			return false, err
+		}
+	}
 	cfg := oldCfg
-	if attrSet[2] {
+	if isAttrSet(attrSet, 12) {
+		cfg.RelayAgentInfoTrusted = newCfg.RelayAgentInfoTrusted
+	}
+	if isAttrSet(attrSet, 11) {
+		cfg.ClientRateLimit = newCfg.ClientRateLimit
+	}
+	if isAttrSet(attrSet, 10) {
+		cfg.RateLimit = newCfg.RateLimit
+	}
+	vrfChanged := false
+	if isAttrSet(attrSet, 9) {
+		vrfChanged = infra.GetDRAv4IntfVrf(cfg) != infra.GetDRAv4IntfVrf(newCfg)
+		cfg.Vrf = newCfg.Vrf
+	}
+	if isAttrSet(attrSet, 8) {
+		cfg.ServerTimeout = newCfg.ServerTimeout
+	}
+	if isAttrSet(attrSet, 7) {
+		cfg.ServerSelection = newCfg.ServerSelection
+	}
+	if isAttrSet(attrSet, 6) {
+		cfg.RelayAgentInfoPolicy = newCfg.RelayAgentInfoPolicy
+	}
+	if isAttrSet(attrSet, 5) {
+		cfg.RemoteId = newCfg.RemoteId
+	}
+	if isAttrSet(attrSet, 4) {
+		cfg.CircuitIdType = newCfg.CircuitIdType
+	}
+	if isAttrSet(attrSet, 3) {
+		cfg.RelayAgentInfo = newCfg.RelayAgentInfo
+	}
+	if isAttrSet(attrSet, 2) {
 		for _, val := range newCfg.ServerIp {
 			ip := net.ParseIP(val)
 			if ip == nil || ip.To4() == nil {
@@ -233,23 +385,30 @@ func (draMgr *DRAMgr) UpdateDRAv4Interface(oldCfg *dhcprelayd.DHCPRelayIntf,
 		}
 		cfg.ServerIp = newCfg.ServerIp
 	}
-	if attrSet[1] {
+	if isAttrSet(attrSet, 1) {
 		cfg.Enable = newCfg.Enable
 	}
 	ifIdx, ok := draMgr.IMgr.GetIPv4IntfIndex(newCfg.IntfRef)
 	if !ok { // No valid interface
 		draMgr.IMgr.UpdateDRAv4Intf(cfg)
+		draMgr.PProc4.ProcessUpdateDRAIntf(cfg.IntfRef)
 		return true, nil
 	}
 
 	_, draPreState := draMgr.IMgr.GetActiveDRAv4Intf(ifIdx)
+	if draPreState && vrfChanged {
+		// Interface moves to the sockets of the new VRF
+		draMgr.PProc4.ProcessInactiveDRAIntf(ifIdx)
+		draPreState = false
+	}
 	draMgr.IMgr.UpdateDRAv4Intf(cfg)
+	draMgr.PProc4.ProcessUpdateDRAIntf(cfg.IntfRef)
 	_, draPostState := draMgr.IMgr.GetActiveDRAv4Intf(ifIdx)
 	if draMgr.IMgr.GetActiveDRAv4IntfCount() <= 0 {
 		draMgr.PProc4.StopRxTx()
 		return true, nil
 	}
-	draMgr.PProc4.StartRxTx()
+	draMgr.startDRAv4RxTx()
 	if !draPreState && draPostState {
 		draMgr.PProc4.ProcessActiveDRAIntf(ifIdx)
 	} else if draPreState && !draPostState {
@@ -277,24 +436,79 @@ func (draMgr *DRAMgr) DeleteDRAv4Interface(intfRef string) (bool, error) {
 	return true, nil
 }
 
+func (draMgr *DRAMgr) CreateDRAv4SnoopingIntf(
+	cfg *dhcprelayd.DHCPRelaySnoopingIntf) (bool, error) {
+
+	draMgr.IMgr.UpdateSnoopingIntf(cfg)
+	draMgr.PProc4.ProcessCreateSnoopingIntf(cfg.IntfRef)
+	return true, nil
+}
+
+func (draMgr *DRAMgr) UpdateDRAv4SnoopingIntf(
+	oldCfg *dhcprelayd.DHCPRelaySnoopingIntf,
+	newCfg *dhcprelayd.DHCPRelaySnoopingIntf, attrSet []bool,
+	op []*dhcprelayd.PatchOpInfo) (bool, error) {
+
+	cfg := oldCfg
+	if isAttrSet(attrSet, 1) {
+		cfg.Trusted = newCfg.Trusted
+	}
+	draMgr.IMgr.UpdateSnoopingIntf(cfg)
+	return true, nil
+}
+
+func (draMgr *DRAMgr) DeleteDRAv4SnoopingIntf(intfRef string) (bool, error) {
+	if _, ok := draMgr.IMgr.GetSnoopingIntf(intfRef); !ok {
+		draMgr.Logger.Info(
+			"DRA: Cannot find snooping interface with reference,", intfRef)
+	}
+	draMgr.IMgr.DeleteSnoopingIntf(intfRef)
+	draMgr.PProc4.ProcessDeleteSnoopingIntf(intfRef)
+	return true, nil
+}
+
+func (draMgr *DRAMgr) processDRAv6IntfsChange(preIfIdxs []int) {
+	preState := make(map[int]bool)
+	for _, ifIdx := range preIfIdxs {
+		preState[ifIdx] = true
+	}
+	postIfIdxs := draMgr.IMgr.GetAllActiveDRAv6Intfs()
+	if len(postIfIdxs) <= 0 {
+		draMgr.PProc6.StopRxTx()
+		return
+	}
+	draMgr.startDRAv6RxTx()
+	for _, ifIdx := range postIfIdxs {
+		if !preState[ifIdx] {
+			draMgr.PProc6.ProcessActiveDRAIntf(ifIdx)
+		}
+		delete(preState, ifIdx)
+	}
+	for ifIdx := range preState {
+		draMgr.PProc6.ProcessInactiveDRAIntf(ifIdx)
+	}
+}
+
 func (draMgr *DRAMgr) CreateDRAv6Global(
 	cfg *dhcprelayd.DHCPv6RelayGlobal) (bool, error) {
 
-	if cfg.Vrf != "default" {
-		errMsg := fmt.Sprintln("DRA: only \"default\" Vrf supported")
+	if cfg.Vrf == "" {
+		errMsg := fmt.Sprintln("DRA: Vrf is required")
+		draMgr.Logger.Err(errMsg)
+		return false, errors.New(errMsg)
+	}
+	if !checkHopCountLimit(cfg.HopCountLimit) {
+		errMsg := fmt.Sprintln(
+			"DRA: Invalid HopCountLimit:", cfg.HopCountLimit)
 		draMgr.Logger.Err(errMsg)
 		return false, errors.New(errMsg)
 	}
+	preIfIdxs := draMgr.IMgr.GetAllActiveDRAv6Intfs()
 	draMgr.IMgr.UpdateDRAv6Global(cfg)
 	if draMgr.IMgr.GetActiveDRAv6IntfCount() <= 0 {
 		return true, nil
 	}
-	draMgr.PProc6.StartRxTx()
-	for _, ifIdx := range draMgr.IMgr.GetAllActiveDRAv6Intfs() {
-		if _, ok := draMgr.IMgr.GetActiveDRAv6Intf(ifIdx); ok {
-			draMgr.PProc6.ProcessActiveDRAIntf(ifIdx)
-		}
-	}
+	draMgr.processDRAv6IntfsChange(preIfIdxs)
 	return true, nil
 }
 
@@ -303,33 +517,31 @@ func (draMgr *DRAMgr) UpdateDRAv6Global(oldCfg *dhcprelayd.DHCPv6RelayGlobal,
 	op []*dhcprelayd.PatchOpInfo) (bool, error) {
 
 	cfg := oldCfg
-	if attrSet[2] {
+	if isAttrSet(attrSet, 3) {
+		cfg.ServerVrf = newCfg.ServerVrf
+	}
+	if isAttrSet(attrSet, 2) {
+		if !checkHopCountLimit(newCfg.HopCountLimit) {
+			errMsg := fmt.Sprintln(
+				"DRA: Invalid HopCountLimit:", newCfg.HopCountLimit)
+			draMgr.Logger.Err(errMsg)
+			return false, errors.New(errMsg)
+		}
 		cfg.HopCountLimit = newCfg.HopCountLimit
 	}
-	if attrSet[1] {
+	if isAttrSet(attrSet, 1) {
 		cfg.Enable = newCfg.Enable
 	}
-	draPreState := (draMgr.IMgr.GetActiveDRAv6IntfCount() > 0)
+	preIfIdxs := draMgr.IMgr.GetAllActiveDRAv6Intfs()
 	draMgr.IMgr.UpdateDRAv6Global(cfg)
-	draPostState := (draMgr.IMgr.GetActiveDRAv6IntfCount() > 0)
-	if !draPostState {
-		draMgr.PProc6.StopRxTx()
-		return true, nil
-	}
-	if !draPreState && draPostState {
-		draMgr.PProc6.StartRxTx()
-		for _, ifIdx := range draMgr.IMgr.GetAllActiveDRAv6Intfs() {
-			if _, ok := draMgr.IMgr.GetActiveDRAv6Intf(ifIdx); ok {
-				draMgr.PProc6.ProcessActiveDRAIntf(ifIdx)
-			}
-		}
-	}
+	draMgr.processDRAv6IntfsChange(preIfIdxs)
 	return true, nil
 }
 
 func (draMgr *DRAMgr) DeleteDRAv6Global(Vrf string) (bool, error) {
-	draMgr.IMgr.DeleteDRAv6Global()
-	draMgr.PProc6.StopRxTx()
+	preIfIdxs := draMgr.IMgr.GetAllActiveDRAv6Intfs()
+	draMgr.IMgr.DeleteDRAv6Global(Vrf)
+	draMgr.processDRAv6IntfsChange(preIfIdxs)
 	return true, nil
 }
 
@@ -346,6 +558,10 @@ func checkStringSliceUnique(stringList []string) bool {
 func (draMgr *DRAMgr) CreateDRAv6Interface(
 	cfg *dhcprelayd.DHCPv6RelayIntf) (bool, error) {
 
+	if err := draMgr.validateRateLimits(
+		cfg.RateLimit, cfg.ClientRateLimit); err != nil {
+		return false, err
+	}
 	for _, ifRef := range cfg.UpstreamIntfs {
 		_, ok := draMgr.IMgr.GetIPv6LLIntfIndex(ifRef)
 		if !ok {
@@ -381,7 +597,7 @@ func (draMgr *DRAMgr) CreateDRAv6Interface(
 	draMgr.IMgr.UpdateDRAv6Intf(cfg)
 	draMgr.PProc6.ProcessCreateDRAIntf(cfg.IntfRef)
 	if _, ok := draMgr.IMgr.GetActiveDRAv6Intf(ifIdx); ok {
-		draMgr.PProc6.StartRxTx()
+		draMgr.startDRAv6RxTx()
 		draMgr.PProc6.ProcessActiveDRAIntf(ifIdx)
 	}
 	return true, nil
@@ -391,8 +607,34 @@ func (draMgr *DRAMgr) UpdateDRAv6Interface(oldCfg *dhcprelayd.DHCPv6RelayIntf,
 	newCfg *dhcprelayd.DHCPv6RelayIntf, attrSet []bool,
 	op []*dhcprelayd.PatchOpInfo) (bool, error) {
 
+	if isAttrSet(attrSet, 8) || isAttrSet(attrSet, 9) {
+		if err := draMgr.validateRateLimits(
+			newCfg.RateLimit, newCfg.ClientRateLimit); err != nil {
+			return false, err
+		}
+	}
 	cfg := oldCfg
-	if attrSet[3] {
+	if isAttrSet(attrSet, 9) {
+		cfg.ClientRateLimit = newCfg.ClientRateLimit
+	}
+	if isAttrSet(attrSet, 8) {
+		cfg.RateLimit = newCfg.RateLimit
+	}
+	vrfChanged := false
+	if isAttrSet(attrSet, 7) {
+		vrfChanged = infra.GetDRAv6IntfVrf(cfg) != infra.GetDRAv6IntfVrf(newCfg)
+		cfg.Vrf = newCfg.Vrf
+	}
+	if isAttrSet(attrSet, 6) {
+		cfg.RemoteIdEnterpriseNum = newCfg.RemoteIdEnterpriseNum
+	}
+	if isAttrSet(attrSet, 5) {
+		cfg.RemoteId = newCfg.RemoteId
+	}
+	if isAttrSet(attrSet, 4) {
+		cfg.InterfaceId = newCfg.InterfaceId
+	}
+	if isAttrSet(attrSet, 3) {
 		for _, ifRef := range cfg.UpstreamIntfs {
 			_, ok := draMgr.IMgr.GetIPv6LLIntfIndex(ifRef)
 			if !ok {
@@ -404,7 +646,7 @@ func (draMgr *DRAMgr) UpdateDRAv6Interface(oldCfg *dhcprelayd.DHCPv6RelayIntf,
 		}
 		cfg.UpstreamIntfs = newCfg.UpstreamIntfs
 	}
-	if attrSet[2] {
+	if isAttrSet(attrSet, 2) {
 		for _, val := range newCfg.ServerIp {
 			ip := net.ParseIP(val)
 			if ip == nil || ip.To4() != nil || ip.IsLinkLocalUnicast() {
@@ -416,23 +658,30 @@ func (draMgr *DRAMgr) UpdateDRAv6Interface(oldCfg *dhcprelayd.DHCPv6RelayIntf,
 		}
 		cfg.ServerIp = newCfg.ServerIp
 	}
-	if attrSet[1] {
+	if isAttrSet(attrSet, 1) {
 		cfg.Enable = newCfg.Enable
 	}
 	ifIdx, ok := draMgr.IMgr.GetIPv6IntfIndex(newCfg.IntfRef)
 	if !ok { // No valid interface
 		draMgr.IMgr.UpdateDRAv6Intf(cfg)
+		draMgr.PProc6.ProcessUpdateDRAIntf(cfg.IntfRef)
 		return true, nil
 	}
 
 	_, draPreState := draMgr.IMgr.GetActiveDRAv6Intf(ifIdx)
+	if draPreState && vrfChanged {
+		// Interface moves to the sockets of the new VRF
+		draMgr.PProc6.ProcessInactiveDRAIntf(ifIdx)
+		draPreState = false
+	}
 	draMgr.IMgr.UpdateDRAv6Intf(cfg)
+	draMgr.PProc6.ProcessUpdateDRAIntf(cfg.IntfRef)
 	_, draPostState := draMgr.IMgr.GetActiveDRAv6Intf(ifIdx)
 	if draMgr.IMgr.GetActiveDRAv6IntfCount() <= 0 {
 		draMgr.PProc6.StopRxTx()
 		return true, nil
 	}
-	draMgr.PProc6.StartRxTx()
+	draMgr.startDRAv6RxTx()
 	if !draPreState && draPostState {
 		draMgr.PProc6.ProcessActiveDRAIntf(ifIdx)
 	} else if draPreState && !draPostState {
@@ -495,7 +744,7 @@ func (draMgr *DRAMgr) processIPv4Notification(
 		draMgr.PProc4.StopRxTx()
 		return
 	}
-	draMgr.PProc4.StartRxTx()
+	draMgr.startDRAv4RxTx()
 	if !draPreState && draPostState {
 		draMgr.PProc4.ProcessActiveDRAIntf(ifIdx)
 	} else if draPreState && !draPostState {
@@ -515,7 +764,7 @@ func (draMgr *DRAMgr) processIPv4StateChange(
 		draMgr.PProc4.StopRxTx()
 		return
 	}
-	draMgr.PProc4.StartRxTx()
+	draMgr.startDRAv4RxTx()
 	if !draPreState && draPostState {
 		draMgr.PProc4.ProcessActiveDRAIntf(ifIdx)
 	} else if draPreState && !draPostState {
@@ -540,7 +789,7 @@ func (draMgr *DRAMgr) processIPv6Notification(
 		draMgr.PProc6.StopRxTx()
 		return
 	}
-	draMgr.PProc6.StartRxTx()
+	draMgr.startDRAv6RxTx()
 	if !draPreState && draPostState {
 		draMgr.PProc6.ProcessActiveDRAIntf(ifIdx)
 	} else if draPreState && !draPostState {
@@ -561,7 +810,7 @@ func (draMgr *DRAMgr) processIPv6StateChange(
 		draMgr.PProc6.StopRxTx()
 		return
 	}
-	draMgr.PProc6.StartRxTx()
+	draMgr.startDRAv6RxTx()
 	if !draPreState && draPostState {
 		draMgr.PProc6.ProcessActiveDRAIntf(ifIdx)
 	} else if draPreState && !draPostState {
@@ -707,6 +956,55 @@ func (draMgr *DRAMgr) GetBulkDRAv4IntfServerState(
 	return result
 }
 
+func (draMgr *DRAMgr) GetDRAv4BindingState(
+	macAddr string, xId string) (
+	*dhcprelayd.DHCPRelayBindingState, error) {
+
+	if val, ok := draMgr.PProc4.GetBindingState(macAddr, xId); ok {
+		return val, nil
+	}
+	return nil, errors.New("Could not find entry")
+}
+
+func (draMgr *DRAMgr) GetBulkDRAv4BindingState(
+	fromIdx, count int) *dhcprelayd.DHCPRelayBindingStateGetInfo {
+
+	result := &dhcprelayd.DHCPRelayBindingStateGetInfo{}
+	nextIdx, actualCount, more, bindingStateSlice :=
+		draMgr.PProc4.GetBindingStateSlice(fromIdx, count)
+
+	result.StartIdx = dhcprelayd.Int(fromIdx)
+	result.EndIdx = dhcprelayd.Int(nextIdx)
+	result.Count = dhcprelayd.Int(actualCount)
+	result.More = more
+	result.DHCPRelayBindingStateList = bindingStateSlice
+	return result
+}
+
+func (draMgr *DRAMgr) GetDRAv4SnoopingBindingState(macAddr string) (
+	*dhcprelayd.DHCPRelaySnoopingBindingState, error) {
+
+	if val, ok := draMgr.PProc4.GetSnoopingBindingState(macAddr); ok {
+		return val, nil
+	}
+	return nil, errors.New("Could not find entry")
+}
+
+func (draMgr *DRAMgr) GetBulkDRAv4SnoopingBindingState(
+	fromIdx, count int) *dhcprelayd.DHCPRelaySnoopingBindingStateGetInfo {
+
+	result := &dhcprelayd.DHCPRelaySnoopingBindingStateGetInfo{}
+	nextIdx, actualCount, more, bindingStateSlice :=
+		draMgr.PProc4.GetSnoopingBindingStateSlice(fromIdx, count)
+
+	result.StartIdx = dhcprelayd.Int(fromIdx)
+	result.EndIdx = dhcprelayd.Int(nextIdx)
+	result.Count = dhcprelayd.Int(actualCount)
+	result.More = more
+	result.DHCPRelaySnoopingBindingStateList = bindingStateSlice
+	return result
+}
+
 func (draMgr *DRAMgr) GetDRAv6IntfServerState(
 	intfRef string, serverIp string) (
 	*dhcprelayd.DHCPv6RelayIntfServerState, error) {
@@ -731,3 +1029,27 @@ func (draMgr *DRAMgr) GetBulkDRAv6IntfServerState(
 	result.DHCPv6RelayIntfServerStateList = intfServerStateSlice
 	return result
 }
+
+func (draMgr *DRAMgr) GetDRAv6PDState(prefix string, vrf string) (
+	*dhcprelayd.DHCPv6RelayPDState, error) {
+
+	if val, ok := draMgr.PProc6.GetPDState(prefix, vrf); ok {
+		return val, nil
+	}
+	return nil, errors.New("Could not find entry")
+}
+
+func (draMgr *DRAMgr) GetBulkDRAv6PDState(
+	fromIdx, count int) *dhcprelayd.DHCPv6RelayPDStateGetInfo {
+
+	result := &dhcprelayd.DHCPv6RelayPDStateGetInfo{}
+	nextIdx, actualCount, more, pdStateSlice :=
+		draMgr.PProc6.GetPDStateSlice(fromIdx, count)
+
+	result.StartIdx = dhcprelayd.Int(fromIdx)
+	result.EndIdx = dhcprelayd.Int(nextIdx)
+	result.Count = dhcprelayd.Int(actualCount)
+	result.More = more
+	result.DHCPv6RelayPDStateList = pdStateSlice
+	return result
+}
@@ -28,11 +28,14 @@ import (
 	"fmt"
 	"infra/sysd/sysdCommonDefs"
 	"l3/dhcp_relay/infra"
+	"l3/dhcp_relay/protocol/dhcp4"
+	"l3/dhcp_relay/protocol/dhcp6"
 	"log/syslog"
 	"models/events"
 	"models/objects"
 	"net"
 	"reflect"
+	"sort"
 	"testing"
 	"time"
 	asicdmock "utils/asicdClient/mock"
@@ -49,6 +52,13 @@ type Processor4Fake struct {
 	IntfStateMap         map[string]*dhcprelayd.DHCPRelayIntfState
 	IntfServerStateSlice []*dhcprelayd.DHCPRelayIntfServerState
 	IntfServerStateMap   map[string]*dhcprelayd.DHCPRelayIntfServerState
+	BindingStateSlice    []*dhcprelayd.DHCPRelayBindingState
+	BindingStateMap      map[string]*dhcprelayd.DHCPRelayBindingState
+
+	SnoopingBindingStateSlice []*dhcprelayd.DHCPRelaySnoopingBindingState
+	SnoopingBindingStateMap   map[string]*dhcprelayd.DHCPRelaySnoopingBindingState
+
+	Checkpoint *dhcp4.StateCheckpoint
 }
 
 type Processor6Fake struct {
@@ -60,6 +70,10 @@ type Processor6Fake struct {
 	IntfStateMap         map[string]*dhcprelayd.DHCPv6RelayIntfState
 	IntfServerStateSlice []*dhcprelayd.DHCPv6RelayIntfServerState
 	IntfServerStateMap   map[string]*dhcprelayd.DHCPv6RelayIntfServerState
+	PDStateSlice         []*dhcprelayd.DHCPv6RelayPDState
+	PDStateMap           map[string]*dhcprelayd.DHCPv6RelayPDState
+
+	Checkpoint *dhcp6.StateCheckpoint
 }
 
 func (pProc *Processor4Fake) GetEnabledFlag() bool {
@@ -70,8 +84,9 @@ func (pProc *Processor4Fake) SetEnabledFlag() {
 	pProc.EnabledFlag = true
 }
 
-func (pProc *Processor4Fake) StartRxTx() {
+func (pProc *Processor4Fake) StartRxTx() error {
 	pProc.EnabledFlag = true
+	return nil
 }
 
 func (pProc *Processor4Fake) StopRxTx() {
@@ -175,10 +190,100 @@ func (pProc *Processor4Fake) GetIntfServerState(
 	}
 }
 
+func (pProc *Processor4Fake) GetBindingStateSlice(
+	fromIdx, count int) (int, int, bool, []*dhcprelayd.DHCPRelayBindingState) {
+
+	var nextIdx int
+	var more bool
+	var actualCount int
+	length := len(pProc.BindingStateSlice)
+	if fromIdx+count >= length {
+		actualCount = length - fromIdx
+		nextIdx = 0
+		more = false
+	} else {
+		actualCount = count
+		nextIdx = fromIdx + count
+		more = true
+	}
+
+	result := make([]*dhcprelayd.DHCPRelayBindingState, actualCount)
+	copy(result, pProc.BindingStateSlice[fromIdx:fromIdx+actualCount])
+	return nextIdx, actualCount, more, result
+}
+
+func (pProc *Processor4Fake) GetBindingState(
+	macAddr string, xId string) (*dhcprelayd.DHCPRelayBindingState, bool) {
+
+	bindingStateKey := macAddr + "_" + xId
+	if bindingState, ok := pProc.BindingStateMap[bindingStateKey]; ok {
+		return bindingState, true
+	} else {
+		return nil, false
+	}
+}
+
+func (pProc *Processor4Fake) GetSnoopingBindingStateSlice(
+	fromIdx, count int) (int, int, bool, []*dhcprelayd.DHCPRelaySnoopingBindingState) {
+
+	var nextIdx int
+	var more bool
+	var actualCount int
+	length := len(pProc.SnoopingBindingStateSlice)
+	if fromIdx+count >= length {
+		actualCount = length - fromIdx
+		nextIdx = 0
+		more = false
+	} else {
+		actualCount = count
+		nextIdx = fromIdx + count
+		more = true
+	}
+
+	result := make([]*dhcprelayd.DHCPRelaySnoopingBindingState, actualCount)
+	copy(result, pProc.SnoopingBindingStateSlice[fromIdx:fromIdx+actualCount])
+	return nextIdx, actualCount, more, result
+}
+
+func (pProc *Processor4Fake) GetSnoopingBindingState(
+	macAddr string) (*dhcprelayd.DHCPRelaySnoopingBindingState, bool) {
+
+	if bindingState, ok := pProc.SnoopingBindingStateMap[macAddr]; ok {
+		return bindingState, true
+	} else {
+		return nil, false
+	}
+}
+
+func (pProc *Processor4Fake) GetStateChangeCh() chan bool {
+	return nil
+}
+
+func (pProc *Processor4Fake) GetStateCheckpoint() *dhcp4.StateCheckpoint {
+	return &dhcp4.StateCheckpoint{
+		ClientStates:     pProc.ClientStateSlice,
+		IntfStates:       pProc.IntfStateSlice,
+		IntfServerStates: pProc.IntfServerStateSlice,
+		BindingStates:    pProc.BindingStateSlice,
+
+		SnoopingBindingStates: pProc.SnoopingBindingStateSlice,
+	}
+}
+
+func (pProc *Processor4Fake) RestoreStateCheckpoint(
+	checkpoint *dhcp4.StateCheckpoint) {
+
+	pProc.Checkpoint = checkpoint
+}
+
 func (pProc *Processor4Fake) ProcessCreateDRAIntf(ifName string) {
 	return
 }
 
+func (pProc *Processor4Fake) ProcessUpdateDRAIntf(ifName string) {
+	return
+}
+
 func (pProc *Processor4Fake) ProcessDeleteDRAIntf(ifName string) {
 	return
 }
@@ -187,6 +292,14 @@ func (pProc *Processor4Fake) ProcessActiveDRAIntf(ifIdx int) {
 	return
 }
 
+func (pProc *Processor4Fake) ProcessCreateSnoopingIntf(ifName string) {
+	return
+}
+
+func (pProc *Processor4Fake) ProcessDeleteSnoopingIntf(ifName string) {
+	return
+}
+
 func (pProc *Processor4Fake) ProcessInactiveDRAIntf(ifIdx int) {
 	return
 }
@@ -199,8 +312,9 @@ func (pProc *Processor6Fake) SetEnabledFlag() {
 	pProc.EnabledFlag = true
 }
 
-func (pProc *Processor6Fake) StartRxTx() {
+func (pProc *Processor6Fake) StartRxTx() error {
 	pProc.EnabledFlag = true
+	return nil
 }
 
 func (pProc *Processor6Fake) StopRxTx() {
@@ -304,10 +418,62 @@ func (pProc *Processor6Fake) GetIntfServerState(
 	}
 }
 
+func (pProc *Processor6Fake) GetPDStateSlice(
+	fromIdx, count int) (int, int, bool, []*dhcprelayd.DHCPv6RelayPDState) {
+
+	var nextIdx int
+	var more bool
+	var actualCount int
+	length := len(pProc.PDStateSlice)
+	if fromIdx+count >= length {
+		actualCount = length - fromIdx
+		nextIdx = 0
+		more = false
+	} else {
+		actualCount = count
+		nextIdx = fromIdx + count
+		more = true
+	}
+
+	result := make([]*dhcprelayd.DHCPv6RelayPDState, actualCount)
+	copy(result, pProc.PDStateSlice[fromIdx:fromIdx+actualCount])
+	return nextIdx, actualCount, more, result
+}
+
+func (pProc *Processor6Fake) GetPDState(
+	prefix string, vrf string) (*dhcprelayd.DHCPv6RelayPDState, bool) {
+
+	if pdState, ok := pProc.PDStateMap[vrf+"_"+prefix]; ok {
+		return pdState, true
+	} else {
+		return nil, false
+	}
+}
+
+func (pProc *Processor6Fake) GetStateChangeCh() chan bool {
+	return nil
+}
+
+func (pProc *Processor6Fake) GetStateCheckpoint() *dhcp6.StateCheckpoint {
+	return &dhcp6.StateCheckpoint{
+		PDStates: pProc.PDStateSlice,
+	}
+}
+
+func (pProc *Processor6Fake) RestoreStateCheckpoint(
+	checkpoint *dhcp6.StateCheckpoint) {
+
+	pProc.Checkpoint = checkpoint
+}
+
 func (pProc *Processor6Fake) ProcessCreateDRAIntf(ifName string) {
 	return
 }
 
+func (pProc *Processor6Fake) ProcessUpdateDRAIntf(ifName string) {
+	return
+}
+
 func (pProc *Processor6Fake) ProcessDeleteDRAIntf(ifName string) {
 	return
 }
@@ -333,6 +499,24 @@ func (dbHdl DbFake) StoreObjectInDb(objects.ConfigObj) error {
 func (dbHdl DbFake) DeleteObjectFromDb(objects.ConfigObj) error {
 	return nil
 }
+
+// Counts the objects written to and removed from DB
+type DbCountFake struct {
+	DbFake
+	Stores  *int
+	Deletes *int
+}
+
+func (dbHdl DbCountFake) StoreObjectInDb(objects.ConfigObj) error {
+	*dbHdl.Stores++
+	return nil
+}
+
+func (dbHdl DbCountFake) DeleteObjectFromDb(objects.ConfigObj) error {
+	*dbHdl.Deletes++
+	return nil
+}
+
 func (dbHdl DbFake) GetObjectFromDb(objects.ConfigObj, string) (objects.ConfigObj, error) {
 	return nil, nil
 }
@@ -364,6 +548,15 @@ func (dbHdl DbFake) GetAllObjFromDb(obj objects.ConfigObj) ([]objects.ConfigObj,
 			Enable:        true,
 			HopCountLimit: 32,
 		})
+	case reflect.TypeOf(objects.DHCPRelayBindingState{}):
+		resultObjs = append(resultObjs, objects.DHCPRelayBindingState{
+			MacAddr:    "00:11:22:33:44:55",
+			XId:        "0x01020304",
+			IntfRef:    "eth0",
+			AcceptedIp: "10.0.0.10",
+			LeaseTime:  3600,
+			State:      "Bound",
+		})
 	case reflect.TypeOf(objects.DHCPv6RelayIntf{}):
 		resultObjs = append(resultObjs, objects.DHCPv6RelayIntf{
 			IntfRef:       "eth0",
@@ -389,6 +582,15 @@ func (dbHdl DbFake) GetAllObjFromDb(obj objects.ConfigObj) ([]objects.ConfigObj,
 			Enable:        true,
 			HopCountLimit: 32,
 		})
+	case reflect.TypeOf(objects.DHCPv6RelayPDState{}):
+		resultObjs = append(resultObjs, objects.DHCPv6RelayPDState{
+			Prefix:         "2001:db8:1::/48",
+			IntfRef:        "eth1",
+			NextHopIp:      "fe80::1",
+			ValidLifetime:  7200,
+			RouteInstalled: true,
+			Vrf:            "default",
+		})
 	}
 	return resultObjs, nil
 }
@@ -479,11 +681,13 @@ func InitTestDRAMgr() (*DRAMgr, error) {
 	pProc4F := &Processor4Fake{}
 	pProc6F := &Processor6Fake{}
 	draMgr := &DRAMgr{
-		DbHdl:  DbFake{},
-		Logger: lgr,
-		IMgr:   iMgr,
-		PProc4: pProc4F,
-		PProc6: pProc6F,
+		DbHdl:            DbFake{},
+		Logger:           lgr,
+		IMgr:             iMgr,
+		PProc4:           pProc4F,
+		PProc6:           pProc6F,
+		CheckpointObjs:   make(map[string]objects.ConfigObj),
+		PDCheckpointObjs: make(map[string]objects.ConfigObj),
 	}
 	return draMgr, nil
 }
@@ -504,7 +708,7 @@ func TestCreateDRAv4Intf1(t *testing.T) {
 	}
 	draMgr.IMgr.IPv4IntfProps[1] = ipv4Intf1
 	draMgr.IMgr.IPv4IfRefToIfIndex["eth0"] = 1
-	draMgr.IMgr.DRAv4Global = &dhcprelayd.DHCPRelayGlobal{
+	draMgr.IMgr.DRAv4Globals["default"] = &dhcprelayd.DHCPRelayGlobal{
 		Vrf:           "default",
 		Enable:        true,
 		HopCountLimit: 32,
@@ -639,7 +843,7 @@ func TestUpdateDRAv4Intf1(t *testing.T) {
 	}
 	draMgr.IMgr.DRAv4Intfs["eth0"] = draIntfCfgPre
 	draMgr.IMgr.ActiveDRAv4Intfs[1] = draIntfCfgPre
-	draMgr.IMgr.DRAv4Global = &dhcprelayd.DHCPRelayGlobal{
+	draMgr.IMgr.DRAv4Globals["default"] = &dhcprelayd.DHCPRelayGlobal{
 		Vrf:           "default",
 		Enable:        true,
 		HopCountLimit: 32,
@@ -704,7 +908,7 @@ func TestUpdateDRAv4Intf2(t *testing.T) {
 		ServerIp: []string{"10.0.0.1", "10.0.0.2"},
 	}
 	draMgr.IMgr.DRAv4Intfs["eth0"] = draIntfCfgPre
-	draMgr.IMgr.DRAv4Global = &dhcprelayd.DHCPRelayGlobal{
+	draMgr.IMgr.DRAv4Globals["default"] = &dhcprelayd.DHCPRelayGlobal{
 		Vrf:           "default",
 		Enable:        true,
 		HopCountLimit: 32,
@@ -748,6 +952,120 @@ func TestUpdateDRAv4Intf2(t *testing.T) {
 	t.Log("PASS: UpdateDRAv4Interface")
 }
 
+func TestUpdateDRAv4Intf3(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	ipv4Intf := &infra.IPv4IntfProperty{
+		IpAddr:  "10.0.0.1",
+		Netmask: net.CIDRMask(24, 32),
+		IfIndex: 1,
+		IfRef:   "eth0",
+		State:   true,
+	}
+	draMgr.IMgr.IPv4IntfProps[1] = ipv4Intf
+	draMgr.IMgr.IPv4IfRefToIfIndex["eth0"] = 1
+	draIntfCfgPre := &dhcprelayd.DHCPRelayIntf{
+		IntfRef:              "eth0",
+		Enable:               true,
+		ServerIp:             []string{"10.0.0.2"},
+		CircuitIdType:        "IntfName",
+		RelayAgentInfoPolicy: "Replace",
+	}
+	draMgr.IMgr.DRAv4Intfs["eth0"] = draIntfCfgPre
+	draMgr.IMgr.ActiveDRAv4Intfs[1] = draIntfCfgPre
+	draMgr.IMgr.DRAv4Globals["default"] = &dhcprelayd.DHCPRelayGlobal{
+		Vrf:           "default",
+		Enable:        true,
+		HopCountLimit: 32,
+	}
+	draMgr.PProc4.SetEnabledFlag()
+	drav4IntfCfg := &dhcprelayd.DHCPRelayIntf{
+		IntfRef:              "eth0",
+		Enable:               true,
+		ServerIp:             []string{"10.0.0.2"},
+		RelayAgentInfo:       true,
+		CircuitIdType:        "Vlan",
+		RemoteId:             "rack1-tor1",
+		RelayAgentInfoPolicy: "Drop",
+	}
+	_, err = draMgr.UpdateDRAv4Interface(
+		draIntfCfgPre, drav4IntfCfg,
+		[]bool{false, false, false, true, true, true, true, false, false, false, false, false}, nil)
+	if err != nil {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	if !draMgr.PProc4.GetEnabledFlag() {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	draIntfCfgPost := draMgr.IMgr.DRAv4Intfs["eth0"]
+	if !draIntfCfgPost.RelayAgentInfo ||
+		draIntfCfgPost.CircuitIdType != "Vlan" ||
+		draIntfCfgPost.RemoteId != "rack1-tor1" ||
+		draIntfCfgPost.RelayAgentInfoPolicy != "Drop" {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	t.Log("PASS: UpdateDRAv4Interface")
+}
+
+func TestUpdateDRAv4Intf4(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	ipv4Intf := &infra.IPv4IntfProperty{
+		IpAddr:  "10.0.0.1",
+		Netmask: net.CIDRMask(24, 32),
+		IfIndex: 1,
+		IfRef:   "eth0",
+		State:   true,
+	}
+	draMgr.IMgr.IPv4IntfProps[1] = ipv4Intf
+	draMgr.IMgr.IPv4IfRefToIfIndex["eth0"] = 1
+	draIntfCfgPre := &dhcprelayd.DHCPRelayIntf{
+		IntfRef:         "eth0",
+		Enable:          true,
+		ServerIp:        []string{"10.0.0.2", "10.0.0.3"},
+		ServerSelection: "BroadcastAll",
+		ServerTimeout:   5,
+	}
+	draMgr.IMgr.DRAv4Intfs["eth0"] = draIntfCfgPre
+	draMgr.IMgr.ActiveDRAv4Intfs[1] = draIntfCfgPre
+	draMgr.IMgr.DRAv4Globals["default"] = &dhcprelayd.DHCPRelayGlobal{
+		Vrf:           "default",
+		Enable:        true,
+		HopCountLimit: 32,
+	}
+	draMgr.PProc4.SetEnabledFlag()
+	drav4IntfCfg := &dhcprelayd.DHCPRelayIntf{
+		IntfRef:         "eth0",
+		Enable:          true,
+		ServerIp:        []string{"10.0.0.2", "10.0.0.3"},
+		ServerSelection: "PrimaryBackup",
+		ServerTimeout:   10,
+	}
+	_, err = draMgr.UpdateDRAv4Interface(
+		draIntfCfgPre, drav4IntfCfg,
+		[]bool{false, false, false, false, false, false, false, true, true, false, false, false}, nil)
+	if err != nil {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	draIntfCfgPost := draMgr.IMgr.DRAv4Intfs["eth0"]
+	if draIntfCfgPost.ServerSelection != "PrimaryBackup" ||
+		draIntfCfgPost.ServerTimeout != 10 {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	t.Log("PASS: UpdateDRAv4Interface")
+}
+
 func TestDeleteDRAv4Intf1(t *testing.T) {
 	draMgr, err := InitTestDRAMgr()
 	if err != nil {
@@ -770,7 +1088,7 @@ func TestDeleteDRAv4Intf1(t *testing.T) {
 	}
 	draMgr.IMgr.DRAv4Intfs["eth0"] = draIntfCfgPre
 	draMgr.IMgr.ActiveDRAv4Intfs[1] = draIntfCfgPre
-	draMgr.IMgr.DRAv4Global = &dhcprelayd.DHCPRelayGlobal{
+	draMgr.IMgr.DRAv4Globals["default"] = &dhcprelayd.DHCPRelayGlobal{
 		Vrf:           "default",
 		Enable:        true,
 		HopCountLimit: 32,
@@ -830,7 +1148,7 @@ func TestDeleteDRAv4Intf2(t *testing.T) {
 	draMgr.IMgr.DRAv4Intfs["eth1"] = draIntf2CfgPre
 	draMgr.IMgr.ActiveDRAv4Intfs[1] = draIntf1CfgPre
 	draMgr.IMgr.ActiveDRAv4Intfs[2] = draIntf2CfgPre
-	draMgr.IMgr.DRAv4Global = &dhcprelayd.DHCPRelayGlobal{
+	draMgr.IMgr.DRAv4Globals["default"] = &dhcprelayd.DHCPRelayGlobal{
 		Vrf:           "default",
 		Enable:        true,
 		HopCountLimit: 32,
@@ -887,7 +1205,7 @@ func TestCreateDRAv4Global1(t *testing.T) {
 		t.Errorf("FAIL: CreateDRAv4Global")
 		return
 	}
-	if draMgr.IMgr.DRAv4Global == nil {
+	if draMgr.IMgr.DRAv4Globals["default"] == nil {
 		t.Errorf("FAIL: CreateDRAv4Global")
 		return
 	}
@@ -923,17 +1241,63 @@ func TestCreateDRAv4Global2(t *testing.T) {
 		t.Errorf("FAIL: CreateDRAv4Global")
 		return
 	}
-	if draMgr.IMgr.DRAv4Global == nil {
+	if draMgr.IMgr.DRAv4Globals["default"] == nil {
 		t.Errorf("FAIL: CreateDRAv4Global")
 		return
 	}
 	t.Log("PASS: CreateDRAv4Global")
 }
 
-func TestUpdateDRAv4Global1(t *testing.T) {
+func TestCreateDRAv4Global3(t *testing.T) {
 	draMgr, err := InitTestDRAMgr()
 	if err != nil {
-		t.Errorf("FAIL: UpdateDRAv4Global")
+		t.Errorf("FAIL: CreateDRAv4Global")
+		return
+	}
+	drav4Global := &dhcprelayd.DHCPRelayGlobal{
+		Vrf:           "default",
+		Enable:        true,
+		HopCountLimit: 256,
+	}
+	_, err = draMgr.CreateDRAv4Global(drav4Global)
+	if err == nil {
+		t.Errorf("FAIL: CreateDRAv4Global")
+		return
+	}
+	if draMgr.IMgr.DRAv4Globals["default"] != nil {
+		t.Errorf("FAIL: CreateDRAv4Global")
+		return
+	}
+	t.Log("PASS: CreateDRAv4Global")
+}
+
+func TestCreateDRAv4Global4(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: CreateDRAv4Global")
+		return
+	}
+	drav4Global := &dhcprelayd.DHCPRelayGlobal{
+		Vrf:           "",
+		Enable:        true,
+		HopCountLimit: 32,
+	}
+	_, err = draMgr.CreateDRAv4Global(drav4Global)
+	if err == nil {
+		t.Errorf("FAIL: CreateDRAv4Global")
+		return
+	}
+	if len(draMgr.IMgr.DRAv4Globals) != 0 {
+		t.Errorf("FAIL: CreateDRAv4Global")
+		return
+	}
+	t.Log("PASS: CreateDRAv4Global")
+}
+
+func TestDRAv4GlobalVrf(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: DRAv4GlobalVrf")
 		return
 	}
 	ipv4Intf1 := &infra.IPv4IntfProperty{
@@ -944,37 +1308,268 @@ func TestUpdateDRAv4Global1(t *testing.T) {
 		State:   true,
 	}
 	ipv4Intf2 := &infra.IPv4IntfProperty{
-		IpAddr:  "10.0.0.2",
+		IpAddr:  "10.0.0.1",
 		Netmask: net.CIDRMask(24, 32),
 		IfIndex: 2,
 		IfRef:   "eth1",
 		State:   true,
 	}
 	draMgr.IMgr.IPv4IntfProps[1] = ipv4Intf1
-	draMgr.IMgr.IPv4IntfProps[2] = ipv4Intf2
 	draMgr.IMgr.IPv4IfRefToIfIndex["eth0"] = 1
+	draMgr.IMgr.IPv4IntfProps[2] = ipv4Intf2
 	draMgr.IMgr.IPv4IfRefToIfIndex["eth1"] = 2
-	draIntf1CfgPre := &dhcprelayd.DHCPRelayIntf{
+	draMgr.IMgr.DRAv4Intfs["eth0"] = &dhcprelayd.DHCPRelayIntf{
 		IntfRef:  "eth0",
 		Enable:   true,
-		ServerIp: []string{"10.0.0.1", "10.0.0.2"},
+		ServerIp: []string{"20.0.0.1"},
 	}
-	draIntf2CfgPre := &dhcprelayd.DHCPRelayIntf{
+	draMgr.IMgr.DRAv4Intfs["eth1"] = &dhcprelayd.DHCPRelayIntf{
 		IntfRef:  "eth1",
 		Enable:   true,
-		ServerIp: []string{"10.0.0.1", "10.0.0.2"},
+		ServerIp: []string{"20.0.0.1"},
+		Vrf:      "tenant1",
 	}
-	draMgr.IMgr.DRAv4Intfs["eth0"] = draIntf1CfgPre
-	draMgr.IMgr.DRAv4Intfs["eth1"] = draIntf2CfgPre
-	draMgr.IMgr.ActiveDRAv4Intfs[1] = draIntf1CfgPre
-	draMgr.IMgr.ActiveDRAv4Intfs[2] = draIntf2CfgPre
-	oldDraV4Global := &dhcprelayd.DHCPRelayGlobal{
-		Vrf:           "default",
+	_, err = draMgr.CreateDRAv4Global(&dhcprelayd.DHCPRelayGlobal{
+		Vrf:           "tenant1",
 		Enable:        true,
-		HopCountLimit: 32,
+		HopCountLimit: 8,
+		ServerVrf:     "tenant1",
+	})
+	if err != nil {
+		t.Errorf("FAIL: DRAv4GlobalVrf")
+		return
 	}
-	draMgr.IMgr.DRAv4Global = oldDraV4Global
-	draMgr.PProc4.SetEnabledFlag()
+	if _, ok := draMgr.IMgr.GetActiveDRAv4Intf(1); ok {
+		t.Errorf("FAIL: DRAv4GlobalVrf intf of default Vrf active")
+		return
+	}
+	if _, ok := draMgr.IMgr.GetActiveDRAv4Intf(2); !ok {
+		t.Errorf("FAIL: DRAv4GlobalVrf intf of tenant1 Vrf not active")
+		return
+	}
+	if !draMgr.PProc4.GetEnabledFlag() {
+		t.Errorf("FAIL: DRAv4GlobalVrf")
+		return
+	}
+	if draMgr.IMgr.GetDRAv4ServerVrf("tenant1") != "tenant1" ||
+		draMgr.IMgr.GetDRAv4ServerVrf("default") != "default" {
+		t.Errorf("FAIL: DRAv4GlobalVrf server Vrf")
+		return
+	}
+	vrfs := draMgr.IMgr.GetActiveDRAv4Vrfs()
+	sort.Strings(vrfs)
+	if !reflect.DeepEqual(vrfs, []string{"tenant1"}) {
+		t.Errorf("FAIL: DRAv4GlobalVrf active Vrfs %v", vrfs)
+		return
+	}
+	if hopCountLimit, ok := draMgr.IMgr.GetDRAv4HopCountLimit(
+		"tenant1"); !ok || hopCountLimit != 8 {
+		t.Errorf("FAIL: DRAv4GlobalVrf hop count limit")
+		return
+	}
+	// Servers may be reached through another VRF
+	_, err = draMgr.UpdateDRAv4Global(
+		draMgr.IMgr.DRAv4Globals["tenant1"],
+		&dhcprelayd.DHCPRelayGlobal{Vrf: "tenant1", ServerVrf: "mgmt"},
+		[]bool{false, false, false, false, true}, nil)
+	if err != nil || draMgr.IMgr.GetDRAv4ServerVrf("tenant1") != "mgmt" {
+		t.Errorf("FAIL: DRAv4GlobalVrf cross Vrf server update")
+		return
+	}
+	vrfs = draMgr.IMgr.GetActiveDRAv4Vrfs()
+	sort.Strings(vrfs)
+	if !reflect.DeepEqual(vrfs, []string{"mgmt", "tenant1"}) {
+		t.Errorf("FAIL: DRAv4GlobalVrf active Vrfs %v", vrfs)
+		return
+	}
+	_, err = draMgr.CreateDRAv4Global(&dhcprelayd.DHCPRelayGlobal{
+		Vrf:           "default",
+		Enable:        true,
+		HopCountLimit: 32,
+	})
+	if err != nil {
+		t.Errorf("FAIL: DRAv4GlobalVrf")
+		return
+	}
+	if draMgr.IMgr.GetActiveDRAv4IntfCount() != 2 {
+		t.Errorf("FAIL: DRAv4GlobalVrf")
+		return
+	}
+	_, err = draMgr.DeleteDRAv4Global("tenant1")
+	if err != nil {
+		t.Errorf("FAIL: DRAv4GlobalVrf")
+		return
+	}
+	if _, ok := draMgr.IMgr.GetActiveDRAv4Intf(2); ok {
+		t.Errorf("FAIL: DRAv4GlobalVrf intf of deleted Vrf active")
+		return
+	}
+	if _, ok := draMgr.IMgr.GetActiveDRAv4Intf(1); !ok {
+		t.Errorf("FAIL: DRAv4GlobalVrf intf of default Vrf not active")
+		return
+	}
+	if !draMgr.PProc4.GetEnabledFlag() {
+		t.Errorf("FAIL: DRAv4GlobalVrf")
+		return
+	}
+	t.Log("PASS: DRAv4GlobalVrf")
+}
+
+func TestUpdateDRAv4InterfaceVrf(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	ipv4Intf := &infra.IPv4IntfProperty{
+		IpAddr:  "10.0.0.1",
+		Netmask: net.CIDRMask(24, 32),
+		IfIndex: 1,
+		IfRef:   "eth0",
+		State:   true,
+	}
+	draMgr.IMgr.IPv4IntfProps[1] = ipv4Intf
+	draMgr.IMgr.IPv4IfRefToIfIndex["eth0"] = 1
+	draMgr.IMgr.DRAv4Globals["default"] = &dhcprelayd.DHCPRelayGlobal{
+		Vrf:           "default",
+		Enable:        true,
+		HopCountLimit: 32,
+	}
+	draIntfCfgPre := &dhcprelayd.DHCPRelayIntf{
+		IntfRef:  "eth0",
+		Enable:   true,
+		ServerIp: []string{"20.0.0.1"},
+	}
+	draMgr.IMgr.DRAv4Intfs["eth0"] = draIntfCfgPre
+	draMgr.IMgr.ActiveDRAv4Intfs[1] = draIntfCfgPre
+	draMgr.PProc4.SetEnabledFlag()
+	draIntfCfg := &dhcprelayd.DHCPRelayIntf{
+		IntfRef: "eth0",
+		Vrf:     "tenant1",
+	}
+	_, err = draMgr.UpdateDRAv4Interface(draIntfCfgPre, draIntfCfg,
+		[]bool{false, false, false, false, false, false, false, false, false, true, false, false}, nil)
+	if err != nil {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	if draMgr.IMgr.GetDRAv4IntfVrf("eth0") != "tenant1" {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	// No relay instance in the new Vrf
+	if _, ok := draMgr.IMgr.GetActiveDRAv4Intf(1); ok {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	if draMgr.PProc4.GetEnabledFlag() {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	t.Log("PASS: UpdateDRAv4Interface")
+}
+
+func TestUpdateDRAv4InterfaceRateLimit(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	drav4IntfCfg := &dhcprelayd.DHCPRelayIntf{
+		IntfRef:   "eth0",
+		Enable:    true,
+		ServerIp:  []string{"20.0.0.1"},
+		RateLimit: -1,
+	}
+	_, err = draMgr.CreateDRAv4Interface(drav4IntfCfg)
+	if err == nil {
+		t.Errorf("FAIL: CreateDRAv4Interface")
+		return
+	}
+	draIntfCfgPre := &dhcprelayd.DHCPRelayIntf{
+		IntfRef:  "eth0",
+		Enable:   true,
+		ServerIp: []string{"20.0.0.1"},
+	}
+	draMgr.IMgr.DRAv4Intfs["eth0"] = draIntfCfgPre
+	draIntfCfg := &dhcprelayd.DHCPRelayIntf{
+		IntfRef:         "eth0",
+		RateLimit:       100,
+		ClientRateLimit: 10,
+	}
+	_, err = draMgr.UpdateDRAv4Interface(draIntfCfgPre, draIntfCfg,
+		[]bool{false, false, false, false, false, false, false, false, false, false, true, true}, nil)
+	if err != nil {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	draIntfCfgPost := draMgr.IMgr.DRAv4Intfs["eth0"]
+	if draIntfCfgPost.RateLimit != 100 || draIntfCfgPost.ClientRateLimit != 10 {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	draIntfCfg = &dhcprelayd.DHCPRelayIntf{
+		IntfRef:         "eth0",
+		ClientRateLimit: -5,
+	}
+	_, err = draMgr.UpdateDRAv4Interface(draIntfCfgPost, draIntfCfg,
+		[]bool{false, false, false, false, false, false, false, false, false, false, false, true}, nil)
+	if err == nil {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	if draMgr.IMgr.DRAv4Intfs["eth0"].ClientRateLimit != 10 {
+		t.Errorf("FAIL: UpdateDRAv4Interface")
+		return
+	}
+	t.Log("PASS: UpdateDRAv4Interface")
+}
+
+func TestUpdateDRAv4Global1(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: UpdateDRAv4Global")
+		return
+	}
+	ipv4Intf1 := &infra.IPv4IntfProperty{
+		IpAddr:  "10.0.0.1",
+		Netmask: net.CIDRMask(24, 32),
+		IfIndex: 1,
+		IfRef:   "eth0",
+		State:   true,
+	}
+	ipv4Intf2 := &infra.IPv4IntfProperty{
+		IpAddr:  "10.0.0.2",
+		Netmask: net.CIDRMask(24, 32),
+		IfIndex: 2,
+		IfRef:   "eth1",
+		State:   true,
+	}
+	draMgr.IMgr.IPv4IntfProps[1] = ipv4Intf1
+	draMgr.IMgr.IPv4IntfProps[2] = ipv4Intf2
+	draMgr.IMgr.IPv4IfRefToIfIndex["eth0"] = 1
+	draMgr.IMgr.IPv4IfRefToIfIndex["eth1"] = 2
+	draIntf1CfgPre := &dhcprelayd.DHCPRelayIntf{
+		IntfRef:  "eth0",
+		Enable:   true,
+		ServerIp: []string{"10.0.0.1", "10.0.0.2"},
+	}
+	draIntf2CfgPre := &dhcprelayd.DHCPRelayIntf{
+		IntfRef:  "eth1",
+		Enable:   true,
+		ServerIp: []string{"10.0.0.1", "10.0.0.2"},
+	}
+	draMgr.IMgr.DRAv4Intfs["eth0"] = draIntf1CfgPre
+	draMgr.IMgr.DRAv4Intfs["eth1"] = draIntf2CfgPre
+	draMgr.IMgr.ActiveDRAv4Intfs[1] = draIntf1CfgPre
+	draMgr.IMgr.ActiveDRAv4Intfs[2] = draIntf2CfgPre
+	oldDraV4Global := &dhcprelayd.DHCPRelayGlobal{
+		Vrf:           "default",
+		Enable:        true,
+		HopCountLimit: 32,
+	}
+	draMgr.IMgr.DRAv4Globals["default"] = oldDraV4Global
+	draMgr.PProc4.SetEnabledFlag()
 	newDraV4Global := &dhcprelayd.DHCPRelayGlobal{
 		Vrf:           "default",
 		Enable:        false,
@@ -994,7 +1589,7 @@ func TestUpdateDRAv4Global1(t *testing.T) {
 		t.Errorf("FAIL: UpdateDRAv4Global")
 		return
 	}
-	curDraVgGlobal := draMgr.IMgr.DRAv4Global
+	curDraVgGlobal := draMgr.IMgr.DRAv4Globals["default"]
 	if curDraVgGlobal.Enable != newDraV4Global.Enable {
 		t.Errorf("FAIL: UpdateDRAv4Global")
 		return
@@ -1047,7 +1642,7 @@ func TestUpdateDRAv4Global2(t *testing.T) {
 		Enable:        false,
 		HopCountLimit: 32,
 	}
-	draMgr.IMgr.DRAv4Global = oldDraV4Global
+	draMgr.IMgr.DRAv4Globals["default"] = oldDraV4Global
 	draMgr.PProc4.SetEnabledFlag()
 	newDraV4Global := &dhcprelayd.DHCPRelayGlobal{
 		Vrf:           "default",
@@ -1068,7 +1663,7 @@ func TestUpdateDRAv4Global2(t *testing.T) {
 		t.Errorf("FAIL: UpdateDRAv4Global")
 		return
 	}
-	curDraVgGlobal := draMgr.IMgr.DRAv4Global
+	curDraVgGlobal := draMgr.IMgr.DRAv4Globals["default"]
 	if curDraVgGlobal.Enable != newDraV4Global.Enable {
 		t.Errorf("FAIL: UpdateDRAv4Global")
 		return
@@ -1102,7 +1697,7 @@ func TestDeleteDRAv4Global1(t *testing.T) {
 	}
 	draMgr.IMgr.DRAv4Intfs["eth0"] = draIntf1CfgPre
 	draMgr.IMgr.ActiveDRAv4Intfs[1] = draIntf1CfgPre
-	draMgr.IMgr.DRAv4Global = &dhcprelayd.DHCPRelayGlobal{
+	draMgr.IMgr.DRAv4Globals["default"] = &dhcprelayd.DHCPRelayGlobal{
 		Vrf:           "default",
 		Enable:        true,
 		HopCountLimit: 32,
@@ -1117,7 +1712,7 @@ func TestDeleteDRAv4Global1(t *testing.T) {
 		t.Errorf("FAIL: DeleteDRAv4Global")
 		return
 	}
-	if draMgr.IMgr.DRAv4Global != nil {
+	if draMgr.IMgr.DRAv4Globals["default"] != nil {
 		t.Errorf("FAIL: DeleteDRAv4Global")
 		return
 	}
@@ -1329,7 +1924,7 @@ func PopulateV4DummyInfra(iMgr *infra.InfraMgr) {
 		Enable:   true,
 		ServerIp: []string{"30.0.0.1"},
 	}
-	iMgr.DRAv4Global = drav4Global
+	iMgr.DRAv4Globals["default"] = drav4Global
 	iMgr.DRAv4Intfs["eth0"] = drav4IntfCfg1
 	iMgr.DRAv4Intfs["eth1"] = drav4IntfCfg2
 	iMgr.DRAv4Intfs["eth2"] = drav4IntfCfg3
@@ -1485,53 +2080,312 @@ func TestGetDRAv4IntfServerState(t *testing.T) {
 	PopulateV4DummyInfra(draMgr.IMgr)
 	_, err = draMgr.GetDRAv4IntfServerState("eth0", "10.0.0.1")
 	if err != nil {
-		t.Errorf("FAIL: GetDRAv4IntfServerState")
+		t.Errorf("FAIL: GetDRAv4IntfServerState")
+		return
+	}
+	_, err = draMgr.GetDRAv4IntfServerState("eth0", "20.0.0.1")
+	if err == nil {
+		t.Errorf("FAIL: GetDRAv4IntfServerState")
+		return
+	}
+	t.Log("PASS: GetDRAv4IntfServerState")
+}
+
+func TestGetDRAv4IntfServerStateSlice(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: GetDRAv4IntfServerStateSlice")
+		return
+	}
+	clientStates, clientStateMap, intfStates, intfStateMap, intfServerStates, intfServerStateMap :=
+		GetV4DummyStates()
+	pProcF := GetV4DummyStateProcessor(
+		clientStates,
+		clientStateMap,
+		intfStates,
+		intfStateMap,
+		intfServerStates,
+		intfServerStateMap,
+	)
+	draMgr.PProc4 = pProcF
+	PopulateV4DummyInfra(draMgr.IMgr)
+	resultIntfServerStates := []*dhcprelayd.DHCPRelayIntfServerState{}
+	curIdx := 0
+	count := 2
+	for {
+		bulkInfo := draMgr.GetBulkDRAv4IntfServerState(curIdx, count)
+		for i := 0; i < int(bulkInfo.Count); i++ {
+			resultIntfServerStates = append(resultIntfServerStates, bulkInfo.DHCPRelayIntfServerStateList[i])
+		}
+		if !bulkInfo.More {
+			break
+		}
+		curIdx = int(bulkInfo.EndIdx)
+	}
+	if len(resultIntfServerStates) != 3 {
+		t.Errorf("FAIL: GetDRAv4IntfServerStateSlice")
+		return
+	}
+	t.Log("PASS: GetDRAv4IntfServerStateSlice")
+}
+
+func GetV4DummyBindingStateProcessor() *Processor4Fake {
+	pProcF := &Processor4Fake{}
+	pProcF.BindingStateSlice = []*dhcprelayd.DHCPRelayBindingState{
+		&dhcprelayd.DHCPRelayBindingState{
+			MacAddr:    "00:11:22:33:44:55",
+			XId:        "0x12345678",
+			IntfRef:    "eth0",
+			AcceptedIp: "10.0.0.10",
+			LeaseTime:  3600,
+			State:      "Bound",
+		},
+		&dhcprelayd.DHCPRelayBindingState{
+			MacAddr:   "00:11:22:33:44:66",
+			XId:       "0x12345679",
+			IntfRef:   "eth1",
+			OfferedIp: "11.0.0.10",
+			State:     "Offered",
+		},
+		&dhcprelayd.DHCPRelayBindingState{
+			MacAddr: "00:11:22:33:44:77",
+			XId:     "0x1234567a",
+			IntfRef: "eth2",
+			State:   "Requesting",
+		},
+	}
+	pProcF.BindingStateMap = make(map[string]*dhcprelayd.DHCPRelayBindingState)
+	for _, bindingState := range pProcF.BindingStateSlice {
+		pProcF.BindingStateMap[bindingState.MacAddr+"_"+bindingState.XId] =
+			bindingState
+	}
+	return pProcF
+}
+
+func TestGetDRAv4BindingState(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: GetDRAv4BindingState")
+		return
+	}
+	draMgr.PProc4 = GetV4DummyBindingStateProcessor()
+	bindingState, err := draMgr.GetDRAv4BindingState(
+		"00:11:22:33:44:55", "0x12345678")
+	if err != nil || bindingState.IntfRef != "eth0" {
+		t.Errorf("FAIL: GetDRAv4BindingState")
+		return
+	}
+	_, err = draMgr.GetDRAv4BindingState("00:11:22:33:44:55", "0x12345679")
+	if err == nil {
+		t.Errorf("FAIL: GetDRAv4BindingState")
+		return
+	}
+	t.Log("PASS: GetDRAv4BindingState")
+}
+
+func TestGetDRAv4BindingStateSlice(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: GetDRAv4BindingStateSlice")
+		return
+	}
+	draMgr.PProc4 = GetV4DummyBindingStateProcessor()
+	resultBindingStates := []*dhcprelayd.DHCPRelayBindingState{}
+	curIdx := 0
+	count := 2
+	for {
+		bulkInfo := draMgr.GetBulkDRAv4BindingState(curIdx, count)
+		for i := 0; i < int(bulkInfo.Count); i++ {
+			resultBindingStates = append(resultBindingStates, bulkInfo.DHCPRelayBindingStateList[i])
+		}
+		if !bulkInfo.More {
+			break
+		}
+		curIdx = int(bulkInfo.EndIdx)
+	}
+	if len(resultBindingStates) != 3 {
+		t.Errorf("FAIL: GetDRAv4BindingStateSlice")
+		return
+	}
+	t.Log("PASS: GetDRAv4BindingStateSlice")
+}
+
+func TestCreateDRAv4SnoopingIntf(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: CreateDRAv4SnoopingIntf")
+		return
+	}
+	ipv4Intf := &infra.IPv4IntfProperty{
+		IpAddr:  "10.0.0.1",
+		Netmask: net.CIDRMask(24, 32),
+		IfIndex: 1,
+		IfRef:   "eth0",
+		State:   true,
+	}
+	draMgr.IMgr.IPv4IntfProps[1] = ipv4Intf
+	draMgr.IMgr.IPv4IfRefToIfIndex["eth0"] = 1
+	// Snooping interfaces need not be IPv4 interfaces
+	_, err = draMgr.CreateDRAv4SnoopingIntf(
+		&dhcprelayd.DHCPRelaySnoopingIntf{
+			IntfRef: "fpPort1",
+			Trusted: false,
+		})
+	if err != nil {
+		t.Errorf("FAIL: CreateDRAv4SnoopingIntf")
+		return
+	}
+	if _, ok := draMgr.IMgr.GetSnoopingIntf("fpPort1"); !ok {
+		t.Errorf("FAIL: CreateDRAv4SnoopingIntf")
+		return
+	}
+	_, err = draMgr.CreateDRAv4SnoopingIntf(
+		&dhcprelayd.DHCPRelaySnoopingIntf{
+			IntfRef: "eth0",
+			Trusted: true,
+		})
+	if err != nil {
+		t.Errorf("FAIL: CreateDRAv4SnoopingIntf")
+		return
+	}
+	if !draMgr.IMgr.GetSnoopingTrusted("eth0") {
+		t.Errorf("FAIL: CreateDRAv4SnoopingIntf")
+		return
+	}
+	if draMgr.IMgr.GetSnoopingTrusted("eth1") {
+		t.Errorf("FAIL: CreateDRAv4SnoopingIntf")
+		return
+	}
+	t.Log("PASS: CreateDRAv4SnoopingIntf")
+}
+
+func TestUpdateDRAv4SnoopingIntf(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: UpdateDRAv4SnoopingIntf")
+		return
+	}
+	oldCfg := &dhcprelayd.DHCPRelaySnoopingIntf{
+		IntfRef: "eth0",
+		Trusted: true,
+	}
+	draMgr.IMgr.SnoopingIntfs["eth0"] = oldCfg
+	newCfg := &dhcprelayd.DHCPRelaySnoopingIntf{
+		IntfRef: "eth0",
+		Trusted: false,
+	}
+	_, err = draMgr.UpdateDRAv4SnoopingIntf(
+		oldCfg, newCfg, []bool{false, true}, nil)
+	if err != nil {
+		t.Errorf("FAIL: UpdateDRAv4SnoopingIntf")
+		return
+	}
+	if draMgr.IMgr.GetSnoopingTrusted("eth0") {
+		t.Errorf("FAIL: UpdateDRAv4SnoopingIntf")
+		return
+	}
+	t.Log("PASS: UpdateDRAv4SnoopingIntf")
+}
+
+func TestDeleteDRAv4SnoopingIntf(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: DeleteDRAv4SnoopingIntf")
+		return
+	}
+	draMgr.IMgr.SnoopingIntfs["eth0"] = &dhcprelayd.DHCPRelaySnoopingIntf{
+		IntfRef: "eth0",
+		Trusted: true,
+	}
+	_, err = draMgr.DeleteDRAv4SnoopingIntf("eth0")
+	if err != nil {
+		t.Errorf("FAIL: DeleteDRAv4SnoopingIntf")
+		return
+	}
+	if _, ok := draMgr.IMgr.GetSnoopingIntf("eth0"); ok {
+		t.Errorf("FAIL: DeleteDRAv4SnoopingIntf")
+		return
+	}
+	t.Log("PASS: DeleteDRAv4SnoopingIntf")
+}
+
+func GetV4DummySnoopingBindingStateProcessor() *Processor4Fake {
+	pProcF := &Processor4Fake{}
+	pProcF.SnoopingBindingStateSlice = []*dhcprelayd.DHCPRelaySnoopingBindingState{
+		&dhcprelayd.DHCPRelaySnoopingBindingState{
+			MacAddr:   "00:11:22:33:44:55",
+			IpAddr:    "10.0.0.10",
+			IntfRef:   "eth0",
+			LeaseTime: 3600,
+		},
+		&dhcprelayd.DHCPRelaySnoopingBindingState{
+			MacAddr:   "00:11:22:33:44:66",
+			IpAddr:    "11.0.0.10",
+			Vlan:      100,
+			IntfRef:   "eth1",
+			LeaseTime: 3600,
+		},
+		&dhcprelayd.DHCPRelaySnoopingBindingState{
+			MacAddr:   "00:11:22:33:44:77",
+			IpAddr:    "12.0.0.10",
+			IntfRef:   "eth2",
+			LeaseTime: 7200,
+		},
+	}
+	pProcF.SnoopingBindingStateMap =
+		make(map[string]*dhcprelayd.DHCPRelaySnoopingBindingState)
+	for _, bindingState := range pProcF.SnoopingBindingStateSlice {
+		pProcF.SnoopingBindingStateMap[bindingState.MacAddr] = bindingState
+	}
+	return pProcF
+}
+
+func TestGetDRAv4SnoopingBindingState(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: GetDRAv4SnoopingBindingState")
 		return
 	}
-	_, err = draMgr.GetDRAv4IntfServerState("eth0", "20.0.0.1")
+	draMgr.PProc4 = GetV4DummySnoopingBindingStateProcessor()
+	bindingState, err := draMgr.GetDRAv4SnoopingBindingState(
+		"00:11:22:33:44:66")
+	if err != nil || bindingState.Vlan != 100 {
+		t.Errorf("FAIL: GetDRAv4SnoopingBindingState")
+		return
+	}
+	_, err = draMgr.GetDRAv4SnoopingBindingState("00:11:22:33:44:88")
 	if err == nil {
-		t.Errorf("FAIL: GetDRAv4IntfServerState")
+		t.Errorf("FAIL: GetDRAv4SnoopingBindingState")
 		return
 	}
-	t.Log("PASS: GetDRAv4IntfServerState")
+	t.Log("PASS: GetDRAv4SnoopingBindingState")
 }
 
-func TestGetDRAv4IntfServerStateSlice(t *testing.T) {
+func TestGetDRAv4SnoopingBindingStateSlice(t *testing.T) {
 	draMgr, err := InitTestDRAMgr()
 	if err != nil {
-		t.Errorf("FAIL: GetDRAv4IntfServerStateSlice")
+		t.Errorf("FAIL: GetDRAv4SnoopingBindingStateSlice")
 		return
 	}
-	clientStates, clientStateMap, intfStates, intfStateMap, intfServerStates, intfServerStateMap :=
-		GetV4DummyStates()
-	pProcF := GetV4DummyStateProcessor(
-		clientStates,
-		clientStateMap,
-		intfStates,
-		intfStateMap,
-		intfServerStates,
-		intfServerStateMap,
-	)
-	draMgr.PProc4 = pProcF
-	PopulateV4DummyInfra(draMgr.IMgr)
-	resultIntfServerStates := []*dhcprelayd.DHCPRelayIntfServerState{}
+	draMgr.PProc4 = GetV4DummySnoopingBindingStateProcessor()
+	resultBindingStates := []*dhcprelayd.DHCPRelaySnoopingBindingState{}
 	curIdx := 0
 	count := 2
 	for {
-		bulkInfo := draMgr.GetBulkDRAv4IntfServerState(curIdx, count)
+		bulkInfo := draMgr.GetBulkDRAv4SnoopingBindingState(curIdx, count)
 		for i := 0; i < int(bulkInfo.Count); i++ {
-			resultIntfServerStates = append(resultIntfServerStates, bulkInfo.DHCPRelayIntfServerStateList[i])
+			resultBindingStates = append(resultBindingStates, bulkInfo.DHCPRelaySnoopingBindingStateList[i])
 		}
 		if !bulkInfo.More {
 			break
 		}
 		curIdx = int(bulkInfo.EndIdx)
 	}
-	if len(resultIntfServerStates) != 3 {
-		t.Errorf("FAIL: GetDRAv4IntfServerStateSlice")
+	if len(resultBindingStates) != 3 {
+		t.Errorf("FAIL: GetDRAv4SnoopingBindingStateSlice")
 		return
 	}
-	t.Log("PASS: GetDRAv4IntfServerStateSlice")
+	t.Log("PASS: GetDRAv4SnoopingBindingStateSlice")
 }
 
 // IPv4 Create Notify
@@ -1664,7 +2518,7 @@ func PopulateDummyIPv4Infra(iMgr *infra.InfraMgr) {
 	//	}
 	//	iMgr.DRAv4Intfs["eth0"] = drav4IntfCfg1
 	//	iMgr.ActiveDRAv4Intfs[1] = drav4IntfCfg1
-	//	iMgr.DRAv4Global = &dhcprelayd.DHCPRelayGlobal{
+	//	iMgr.DRAv4Globals["default"] = &dhcprelayd.DHCPRelayGlobal{
 	//		Vrf:           "default",
 	//		Enable:        true,
 	//		HopCountLimit: 32,
@@ -1687,6 +2541,203 @@ func TestV4DaemonReload(t *testing.T) {
 	t.Log("PASS: DRAv4 Daemon Reload")
 }
 
+func TestV4DaemonReloadState(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: DRAv4 Daemon Reload State")
+		return
+	}
+	PopulateDummyIPv4Infra(draMgr.IMgr)
+	draMgr.InitDRAMgr()
+	checkpoint := draMgr.PProc4.(*Processor4Fake).Checkpoint
+	if checkpoint == nil || len(checkpoint.BindingStates) != 1 ||
+		checkpoint.BindingStates[0].IntfRef != "eth0" {
+		t.Errorf("FAIL: DRAv4 Daemon Reload State")
+		return
+	}
+	if _, ok := draMgr.CheckpointObjs["BindingState_00:11:22:33:44:55_0x01020304"]; !ok {
+		t.Errorf("FAIL: DRAv4 Daemon Reload State")
+		return
+	}
+	t.Log("PASS: DRAv4 Daemon Reload State")
+}
+
+func TestWriteDRAv4StateCheckpoint(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: WriteDRAv4StateCheckpoint")
+		return
+	}
+	draMgr.CheckpointObjs["BindingState_00:11:22:33:44:55_0x01020304"] =
+		objects.DHCPRelayBindingState{
+			MacAddr: "00:11:22:33:44:55",
+			XId:     "0x01020304",
+		}
+	pProcF := draMgr.PProc4.(*Processor4Fake)
+	pProcF.IntfStateSlice = []*dhcprelayd.DHCPRelayIntfState{
+		&dhcprelayd.DHCPRelayIntfState{
+			IntfRef:           "eth0",
+			TotalDhcpClientRx: 10,
+		},
+	}
+	pProcF.BindingStateSlice = []*dhcprelayd.DHCPRelayBindingState{
+		&dhcprelayd.DHCPRelayBindingState{
+			MacAddr: "00:11:22:33:44:66",
+			XId:     "0x01020305",
+			IntfRef: "eth0",
+		},
+	}
+	draMgr.writeDRAv4StateCheckpoint(pProcF.GetStateCheckpoint())
+	if len(draMgr.CheckpointObjs) != 2 {
+		t.Errorf("FAIL: WriteDRAv4StateCheckpoint")
+		return
+	}
+	if _, ok := draMgr.CheckpointObjs["BindingState_00:11:22:33:44:55_0x01020304"]; ok {
+		t.Errorf("FAIL: WriteDRAv4StateCheckpoint")
+		return
+	}
+	obj, ok := draMgr.CheckpointObjs["IntfState_eth0"]
+	if !ok || obj.(objects.DHCPRelayIntfState).TotalDhcpClientRx != 10 {
+		t.Errorf("FAIL: WriteDRAv4StateCheckpoint")
+		return
+	}
+	t.Log("PASS: WriteDRAv4StateCheckpoint")
+}
+
+func TestWriteDRAv4StateCheckpointDiff(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: WriteDRAv4StateCheckpointDiff")
+		return
+	}
+	var stores, deletes int
+	draMgr.DbHdl = DbCountFake{Stores: &stores, Deletes: &deletes}
+	pProcF := draMgr.PProc4.(*Processor4Fake)
+	pProcF.IntfStateSlice = []*dhcprelayd.DHCPRelayIntfState{
+		&dhcprelayd.DHCPRelayIntfState{
+			IntfRef:           "eth0",
+			TotalDhcpClientRx: 10,
+		},
+	}
+	pProcF.BindingStateSlice = []*dhcprelayd.DHCPRelayBindingState{
+		&dhcprelayd.DHCPRelayBindingState{
+			MacAddr: "00:11:22:33:44:66",
+			XId:     "0x01020305",
+			IntfRef: "eth0",
+		},
+	}
+	pProcF.SnoopingBindingStateSlice = []*dhcprelayd.DHCPRelaySnoopingBindingState{
+		&dhcprelayd.DHCPRelaySnoopingBindingState{
+			MacAddr: "00:11:22:33:44:66",
+			IntfRef: "fpPort1",
+		},
+	}
+	draMgr.writeDRAv4StateCheckpoint(pProcF.GetStateCheckpoint())
+	if stores != 3 || deletes != 0 {
+		t.Errorf("FAIL: WriteDRAv4StateCheckpointDiff")
+		return
+	}
+	if _, ok := draMgr.CheckpointObjs["SnoopingBindingState_00:11:22:33:44:66"]; !ok {
+		t.Errorf("FAIL: WriteDRAv4StateCheckpointDiff")
+		return
+	}
+	// Unchanged state is not written again
+	stores = 0
+	draMgr.writeDRAv4StateCheckpoint(pProcF.GetStateCheckpoint())
+	if stores != 0 || deletes != 0 {
+		t.Errorf("FAIL: WriteDRAv4StateCheckpointDiff")
+		return
+	}
+	pProcF.IntfStateSlice[0].TotalDhcpClientRx++
+	pProcF.SnoopingBindingStateSlice = nil
+	draMgr.writeDRAv4StateCheckpoint(pProcF.GetStateCheckpoint())
+	if stores != 1 || deletes != 1 || len(draMgr.CheckpointObjs) != 2 {
+		t.Errorf("FAIL: WriteDRAv4StateCheckpointDiff")
+		return
+	}
+	t.Log("PASS: WriteDRAv4StateCheckpointDiff")
+}
+
+func TestDeinitDRAMgr(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: DeinitDRAMgr")
+		return
+	}
+	var stores, deletes int
+	draMgr.DbHdl = DbCountFake{Stores: &stores, Deletes: &deletes}
+	draMgr.InitDRAMgr()
+	done := make(chan bool)
+	go func() {
+		draMgr.DeinitDRAMgr()
+		done <- true
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Errorf("FAIL: DeinitDRAMgr")
+		return
+	}
+	// State read back from DB is gone from the fake processors, so the
+	// last checkpoint removes it
+	if deletes != 2 || draMgr.CheckpointQuitCh != nil {
+		t.Errorf("FAIL: DeinitDRAMgr")
+		return
+	}
+	draMgr.DeinitDRAMgr()
+	t.Log("PASS: DeinitDRAMgr")
+}
+
+func TestV6DaemonReloadState(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: DRAv6 Daemon Reload State")
+		return
+	}
+	draMgr.InitDRAMgr()
+	checkpoint := draMgr.PProc6.(*Processor6Fake).Checkpoint
+	if checkpoint == nil || len(checkpoint.PDStates) != 1 ||
+		checkpoint.PDStates[0].Prefix != "2001:db8:1::/48" ||
+		!checkpoint.PDStates[0].RouteInstalled {
+		t.Errorf("FAIL: DRAv6 Daemon Reload State")
+		return
+	}
+	if _, ok := draMgr.PDCheckpointObjs["PDState_default_2001:db8:1::/48"]; !ok {
+		t.Errorf("FAIL: DRAv6 Daemon Reload State")
+		return
+	}
+	t.Log("PASS: DRAv6 Daemon Reload State")
+}
+
+func TestWriteDRAv6StateCheckpoint(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: WriteDRAv6StateCheckpoint")
+		return
+	}
+	draMgr.PDCheckpointObjs["PDState_default_2001:db8:9::/48"] =
+		objects.DHCPv6RelayPDState{
+			Prefix: "2001:db8:9::/48",
+			Vrf:    "default",
+		}
+	pProcF := GetV6DummyPDStateProcessor()
+	draMgr.writeDRAv6StateCheckpoint(pProcF.GetStateCheckpoint())
+	if len(draMgr.PDCheckpointObjs) != 3 {
+		t.Errorf("FAIL: WriteDRAv6StateCheckpoint")
+		return
+	}
+	if _, ok := draMgr.PDCheckpointObjs["PDState_default_2001:db8:9::/48"]; ok {
+		t.Errorf("FAIL: WriteDRAv6StateCheckpoint")
+		return
+	}
+	obj, ok := draMgr.PDCheckpointObjs["PDState_default_2001:db8:2::/56"]
+	if !ok || obj.(objects.DHCPv6RelayPDState).NextHopIp != "fe80::2" {
+		t.Errorf("FAIL: WriteDRAv6StateCheckpoint")
+		return
+	}
+	t.Log("PASS: WriteDRAv6StateCheckpoint")
+}
+
 // drav6
 func TestCreateDRAv6Intf1(t *testing.T) {
 	draMgr, err := InitTestDRAMgr()
@@ -1703,7 +2754,7 @@ func TestCreateDRAv6Intf1(t *testing.T) {
 	}
 	draMgr.IMgr.IPv6IntfProps[1] = ipv6Intf1
 	draMgr.IMgr.IPv6IfRefToIfIndex["eth0"] = 1
-	draMgr.IMgr.DRAv6Global = &dhcprelayd.DHCPv6RelayGlobal{
+	draMgr.IMgr.DRAv6Globals["default"] = &dhcprelayd.DHCPv6RelayGlobal{
 		Vrf:           "default",
 		Enable:        true,
 		HopCountLimit: 32,
@@ -1816,6 +2867,48 @@ func TestCreateDRAv6Intf4(t *testing.T) {
 	t.Log("PASS: CreateDRAv6Interface")
 }
 
+func TestUpdateDRAv6IntfRateLimit(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: UpdateDRAv6Interface")
+		return
+	}
+	drav6IntfCfg := &dhcprelayd.DHCPv6RelayIntf{
+		IntfRef:         "eth0",
+		Enable:          true,
+		ServerIp:        []string{"2456:db8::1"},
+		ClientRateLimit: -1,
+	}
+	_, err = draMgr.CreateDRAv6Interface(drav6IntfCfg)
+	if err == nil {
+		t.Errorf("FAIL: CreateDRAv6Interface")
+		return
+	}
+	draIntfCfgPre := &dhcprelayd.DHCPv6RelayIntf{
+		IntfRef:  "eth0",
+		Enable:   true,
+		ServerIp: []string{"2456:db8::1"},
+	}
+	draMgr.IMgr.DRAv6Intfs["eth0"] = draIntfCfgPre
+	draIntfCfg := &dhcprelayd.DHCPv6RelayIntf{
+		IntfRef:         "eth0",
+		RateLimit:       50,
+		ClientRateLimit: 5,
+	}
+	_, err = draMgr.UpdateDRAv6Interface(draIntfCfgPre, draIntfCfg,
+		[]bool{false, false, false, false, false, false, false, false, true, true}, nil)
+	if err != nil {
+		t.Errorf("FAIL: UpdateDRAv6Interface")
+		return
+	}
+	draIntfCfgPost := draMgr.IMgr.DRAv6Intfs["eth0"]
+	if draIntfCfgPost.RateLimit != 50 || draIntfCfgPost.ClientRateLimit != 5 {
+		t.Errorf("FAIL: UpdateDRAv6Interface")
+		return
+	}
+	t.Log("PASS: UpdateDRAv6Interface")
+}
+
 func TestUpdateDRAv6Intf1(t *testing.T) {
 	draMgr, err := InitTestDRAMgr()
 	if err != nil {
@@ -1838,7 +2931,7 @@ func TestUpdateDRAv6Intf1(t *testing.T) {
 	}
 	draMgr.IMgr.DRAv6Intfs["eth0"] = draIntfCfgPre
 	draMgr.IMgr.ActiveDRAv6Intfs[1] = draIntfCfgPre
-	draMgr.IMgr.DRAv6Global = &dhcprelayd.DHCPv6RelayGlobal{
+	draMgr.IMgr.DRAv6Globals["default"] = &dhcprelayd.DHCPv6RelayGlobal{
 		Vrf:           "default",
 		Enable:        true,
 		HopCountLimit: 32,
@@ -1903,7 +2996,7 @@ func TestUpdateDRAv6Intf2(t *testing.T) {
 		ServerIp: []string{"2456:db8::1", "2456:db8::2"},
 	}
 	draMgr.IMgr.DRAv6Intfs["eth0"] = draIntfCfgPre
-	draMgr.IMgr.DRAv6Global = &dhcprelayd.DHCPv6RelayGlobal{
+	draMgr.IMgr.DRAv6Globals["default"] = &dhcprelayd.DHCPv6RelayGlobal{
 		Vrf:           "default",
 		Enable:        true,
 		HopCountLimit: 32,
@@ -1947,6 +3040,58 @@ func TestUpdateDRAv6Intf2(t *testing.T) {
 	t.Log("PASS: UpdateDRAv6Interface")
 }
 
+func TestUpdateDRAv6Intf3(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: UpdateDRAv6Interface")
+		return
+	}
+	ipv6Intf := &infra.IPv6IntfProperty{
+		IpAddr:  "2031:db8::1",
+		Netmask: net.CIDRMask(64, 128),
+		IfIndex: 1,
+		IfRef:   "eth0",
+		State:   true,
+	}
+	draMgr.IMgr.IPv6IntfProps[1] = ipv6Intf
+	draMgr.IMgr.IPv6IfRefToIfIndex["eth0"] = 1
+	draIntfCfgPre := &dhcprelayd.DHCPv6RelayIntf{
+		IntfRef:  "eth0",
+		Enable:   true,
+		ServerIp: []string{"2456:db8::1"},
+	}
+	draMgr.IMgr.DRAv6Intfs["eth0"] = draIntfCfgPre
+	draMgr.IMgr.DRAv6Globals["default"] = &dhcprelayd.DHCPv6RelayGlobal{
+		Vrf:           "default",
+		Enable:        true,
+		HopCountLimit: 32,
+	}
+	drav6IntfCfg := &dhcprelayd.DHCPv6RelayIntf{
+		IntfRef:               "eth0",
+		Enable:                true,
+		ServerIp:              []string{"2456:db8::1"},
+		InterfaceId:           true,
+		RemoteId:              "rack1-tor1",
+		RemoteIdEnterpriseNum: 4413,
+	}
+	draMgr.PProc6.SetEnabledFlag()
+	_, err = draMgr.UpdateDRAv6Interface(
+		draIntfCfgPre, drav6IntfCfg,
+		[]bool{false, false, false, false, true, true, true, false, false, false}, nil)
+	if err != nil {
+		t.Errorf("FAIL: UpdateDRAv6Interface")
+		return
+	}
+	draIntfCfgPost := draMgr.IMgr.DRAv6Intfs["eth0"]
+	if !draIntfCfgPost.InterfaceId ||
+		draIntfCfgPost.RemoteId != "rack1-tor1" ||
+		draIntfCfgPost.RemoteIdEnterpriseNum != 4413 {
+		t.Errorf("FAIL: UpdateDRAv6Interface")
+		return
+	}
+	t.Log("PASS: UpdateDRAv6Interface")
+}
+
 func TestDeleteDRAv6Intf1(t *testing.T) {
 	draMgr, err := InitTestDRAMgr()
 	if err != nil {
@@ -1969,7 +3114,7 @@ func TestDeleteDRAv6Intf1(t *testing.T) {
 	}
 	draMgr.IMgr.DRAv6Intfs["eth0"] = draIntfCfgPre
 	draMgr.IMgr.ActiveDRAv6Intfs[1] = draIntfCfgPre
-	draMgr.IMgr.DRAv6Global = &dhcprelayd.DHCPv6RelayGlobal{
+	draMgr.IMgr.DRAv6Globals["default"] = &dhcprelayd.DHCPv6RelayGlobal{
 		Vrf:           "default",
 		Enable:        true,
 		HopCountLimit: 32,
@@ -2029,7 +3174,7 @@ func TestDeleteDRAv6Intf2(t *testing.T) {
 	draMgr.IMgr.DRAv6Intfs["eth1"] = draIntf2CfgPre
 	draMgr.IMgr.ActiveDRAv6Intfs[1] = draIntf1CfgPre
 	draMgr.IMgr.ActiveDRAv6Intfs[2] = draIntf2CfgPre
-	draMgr.IMgr.DRAv6Global = &dhcprelayd.DHCPv6RelayGlobal{
+	draMgr.IMgr.DRAv6Globals["default"] = &dhcprelayd.DHCPv6RelayGlobal{
 		Vrf:           "default",
 		Enable:        true,
 		HopCountLimit: 32,
@@ -2086,7 +3231,7 @@ func TestCreateDRAv6Global1(t *testing.T) {
 		t.Errorf("FAIL: CreateDRAv6Global")
 		return
 	}
-	if draMgr.IMgr.DRAv6Global == nil {
+	if draMgr.IMgr.DRAv6Globals["default"] == nil {
 		t.Errorf("FAIL: CreateDRAv6Global")
 		return
 	}
@@ -2122,7 +3267,7 @@ func TestCreateDRAv6Global2(t *testing.T) {
 		t.Errorf("FAIL: CreateDRAv6Global")
 		return
 	}
-	if draMgr.IMgr.DRAv6Global == nil {
+	if draMgr.IMgr.DRAv6Globals["default"] == nil {
 		t.Errorf("FAIL: CreateDRAv6Global")
 		return
 	}
@@ -2172,7 +3317,7 @@ func TestUpdateDRAv6Global1(t *testing.T) {
 		Enable:        true,
 		HopCountLimit: 32,
 	}
-	draMgr.IMgr.DRAv6Global = oldDraV6Global
+	draMgr.IMgr.DRAv6Globals["default"] = oldDraV6Global
 	draMgr.PProc6.SetEnabledFlag()
 	newDraV6Global := &dhcprelayd.DHCPv6RelayGlobal{
 		Vrf:           "default",
@@ -2193,7 +3338,7 @@ func TestUpdateDRAv6Global1(t *testing.T) {
 		t.Errorf("FAIL: UpdateDRAv6Global")
 		return
 	}
-	curDraVgGlobal := draMgr.IMgr.DRAv6Global
+	curDraVgGlobal := draMgr.IMgr.DRAv6Globals["default"]
 	if curDraVgGlobal.Enable != newDraV6Global.Enable {
 		t.Errorf("FAIL: UpdateDRAv6Global")
 		return
@@ -2246,7 +3391,7 @@ func TestUpdateDRAv6Global2(t *testing.T) {
 		Enable:        false,
 		HopCountLimit: 32,
 	}
-	draMgr.IMgr.DRAv6Global = oldDraV6Global
+	draMgr.IMgr.DRAv6Globals["default"] = oldDraV6Global
 	draMgr.PProc6.SetEnabledFlag()
 	newDraV6Global := &dhcprelayd.DHCPv6RelayGlobal{
 		Vrf:           "default",
@@ -2267,7 +3412,7 @@ func TestUpdateDRAv6Global2(t *testing.T) {
 		t.Errorf("FAIL: UpdateDRAv6Global")
 		return
 	}
-	curDraVgGlobal := draMgr.IMgr.DRAv6Global
+	curDraVgGlobal := draMgr.IMgr.DRAv6Globals["default"]
 	if curDraVgGlobal.Enable != newDraV6Global.Enable {
 		t.Errorf("FAIL: UpdateDRAv6Global")
 		return
@@ -2301,7 +3446,7 @@ func TestDeleteDRAv6Global1(t *testing.T) {
 	}
 	draMgr.IMgr.DRAv6Intfs["eth0"] = draIntf1CfgPre
 	draMgr.IMgr.ActiveDRAv6Intfs[1] = draIntf1CfgPre
-	draMgr.IMgr.DRAv6Global = &dhcprelayd.DHCPv6RelayGlobal{
+	draMgr.IMgr.DRAv6Globals["default"] = &dhcprelayd.DHCPv6RelayGlobal{
 		Vrf:           "default",
 		Enable:        true,
 		HopCountLimit: 32,
@@ -2316,13 +3461,86 @@ func TestDeleteDRAv6Global1(t *testing.T) {
 		t.Errorf("FAIL: DeleteDRAv6Global")
 		return
 	}
-	if draMgr.IMgr.DRAv6Global != nil {
+	if draMgr.IMgr.DRAv6Globals["default"] != nil {
 		t.Errorf("FAIL: DeleteDRAv6Global")
 		return
 	}
 	t.Log("PASS: DeleteDRAv6Global")
 }
 
+func TestDRAv6GlobalVrf(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: DRAv6GlobalVrf")
+		return
+	}
+	ipv6Intf1 := &infra.IPv6IntfProperty{
+		IpAddr:  "2031:db8::1",
+		Netmask: net.CIDRMask(64, 128),
+		IfIndex: 1,
+		IfRef:   "eth0",
+		State:   true,
+	}
+	draMgr.IMgr.IPv6IntfProps[1] = ipv6Intf1
+	draMgr.IMgr.IPv6IfRefToIfIndex["eth0"] = 1
+	draMgr.IMgr.DRAv6Intfs["eth0"] = &dhcprelayd.DHCPv6RelayIntf{
+		IntfRef:  "eth0",
+		Enable:   true,
+		ServerIp: []string{"2456:db8::1"},
+		Vrf:      "tenant1",
+	}
+	_, err = draMgr.CreateDRAv6Global(&dhcprelayd.DHCPv6RelayGlobal{
+		Vrf:           "default",
+		Enable:        true,
+		HopCountLimit: 32,
+	})
+	if err != nil {
+		t.Errorf("FAIL: DRAv6GlobalVrf")
+		return
+	}
+	if draMgr.IMgr.GetActiveDRAv6IntfCount() != 0 ||
+		draMgr.PProc6.GetEnabledFlag() {
+		t.Errorf("FAIL: DRAv6GlobalVrf intf of tenant1 Vrf active")
+		return
+	}
+	_, err = draMgr.CreateDRAv6Global(&dhcprelayd.DHCPv6RelayGlobal{
+		Vrf:           "tenant1",
+		Enable:        true,
+		HopCountLimit: 32,
+		ServerVrf:     "mgmt",
+	})
+	if err != nil {
+		t.Errorf("FAIL: DRAv6GlobalVrf")
+		return
+	}
+	if _, ok := draMgr.IMgr.GetActiveDRAv6Intf(1); !ok ||
+		!draMgr.PProc6.GetEnabledFlag() {
+		t.Errorf("FAIL: DRAv6GlobalVrf intf of tenant1 Vrf not active")
+		return
+	}
+	if draMgr.IMgr.GetDRAv6ServerVrf("tenant1") != "mgmt" {
+		t.Errorf("FAIL: DRAv6GlobalVrf server Vrf")
+		return
+	}
+	newCfg := &dhcprelayd.DHCPv6RelayGlobal{
+		Vrf:    "tenant1",
+		Enable: false,
+	}
+	oldCfg, _ := draMgr.IMgr.GetDRAv6Global("tenant1")
+	_, err = draMgr.UpdateDRAv6Global(oldCfg, newCfg,
+		[]bool{false, true, false, false}, nil)
+	if err != nil {
+		t.Errorf("FAIL: DRAv6GlobalVrf")
+		return
+	}
+	if draMgr.IMgr.GetActiveDRAv6IntfCount() != 0 ||
+		draMgr.PProc6.GetEnabledFlag() {
+		t.Errorf("FAIL: DRAv6GlobalVrf")
+		return
+	}
+	t.Log("PASS: DRAv6GlobalVrf")
+}
+
 //States
 func GetV6DummyStates() (
 	[]*dhcprelayd.DHCPv6RelayClientState,
@@ -2537,7 +3755,7 @@ func PopulateV6DummyInfra(iMgr *infra.InfraMgr) {
 		Enable:   true,
 		ServerIp: []string{"2001::3"},
 	}
-	iMgr.DRAv6Global = drav6Global
+	iMgr.DRAv6Globals["default"] = drav6Global
 	iMgr.DRAv6Intfs["eth0"] = drav6IntfCfg1
 	iMgr.DRAv6Intfs["eth1"] = drav6IntfCfg2
 	iMgr.DRAv6Intfs["eth2"] = drav6IntfCfg3
@@ -2742,6 +3960,92 @@ func TestGetDRAv6IntfServerStateSlice(t *testing.T) {
 	t.Log("PASS: GetDRAv6IntfServerStateSlice")
 }
 
+func GetV6DummyPDStateProcessor() *Processor6Fake {
+	pProcF := &Processor6Fake{}
+	pProcF.PDStateSlice = []*dhcprelayd.DHCPv6RelayPDState{
+		&dhcprelayd.DHCPv6RelayPDState{
+			Prefix:         "2001:db8:1::/48",
+			IntfRef:        "eth1",
+			NextHopIp:      "fe80::1",
+			ValidLifetime:  7200,
+			RouteInstalled: true,
+			Vrf:            "default",
+		},
+		&dhcprelayd.DHCPv6RelayPDState{
+			Prefix:         "2001:db8:2::/56",
+			IntfRef:        "eth1",
+			NextHopIp:      "fe80::2",
+			ValidLifetime:  7200,
+			RouteInstalled: true,
+			Vrf:            "default",
+		},
+		&dhcprelayd.DHCPv6RelayPDState{
+			Prefix:        "2001:db8:3::/64",
+			IntfRef:       "eth2",
+			NextHopIp:     "fe80::3",
+			ValidLifetime: 3600,
+			Vrf:           "default",
+		},
+	}
+	pProcF.PDStateMap = make(map[string]*dhcprelayd.DHCPv6RelayPDState)
+	for _, pdState := range pProcF.PDStateSlice {
+		pProcF.PDStateMap[pdState.Vrf+"_"+pdState.Prefix] = pdState
+	}
+	return pProcF
+}
+
+func TestGetDRAv6PDState(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: GetDRAv6PDState")
+		return
+	}
+	draMgr.PProc6 = GetV6DummyPDStateProcessor()
+	pdState, err := draMgr.GetDRAv6PDState("2001:db8:2::/56", "default")
+	if err != nil || pdState.NextHopIp != "fe80::2" {
+		t.Errorf("FAIL: GetDRAv6PDState")
+		return
+	}
+	_, err = draMgr.GetDRAv6PDState("2001:db8:2::/56", "tenant1")
+	if err == nil {
+		t.Errorf("FAIL: GetDRAv6PDState")
+		return
+	}
+	_, err = draMgr.GetDRAv6PDState("2001:db8:4::/64", "default")
+	if err == nil {
+		t.Errorf("FAIL: GetDRAv6PDState")
+		return
+	}
+	t.Log("PASS: GetDRAv6PDState")
+}
+
+func TestGetDRAv6PDStateSlice(t *testing.T) {
+	draMgr, err := InitTestDRAMgr()
+	if err != nil {
+		t.Errorf("FAIL: GetDRAv6PDStateSlice")
+		return
+	}
+	draMgr.PProc6 = GetV6DummyPDStateProcessor()
+	resultPDStates := []*dhcprelayd.DHCPv6RelayPDState{}
+	curIdx := 0
+	count := 2
+	for {
+		bulkInfo := draMgr.GetBulkDRAv6PDState(curIdx, count)
+		for i := 0; i < int(bulkInfo.Count); i++ {
+			resultPDStates = append(resultPDStates, bulkInfo.DHCPv6RelayPDStateList[i])
+		}
+		if !bulkInfo.More {
+			break
+		}
+		curIdx = int(bulkInfo.EndIdx)
+	}
+	if len(resultPDStates) != 3 {
+		t.Errorf("FAIL: GetDRAv6PDStateSlice")
+		return
+	}
+	t.Log("PASS: GetDRAv6PDStateSlice")
+}
+
 // IPv6 Create Notify
 func TestV6ProcessAsicdNotification1(t *testing.T) {
 	//	draMgr, err := InitTestDRAMgr()
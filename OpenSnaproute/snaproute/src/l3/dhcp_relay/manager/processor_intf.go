@@ -25,31 +25,44 @@ package manager
 
 import (
 	"dhcprelayd"
+	"l3/dhcp_relay/protocol/dhcp4"
+	"l3/dhcp_relay/protocol/dhcp6"
 )
 
 type IPv4ProcessorIntf interface {
-	StartRxTx()
+	StartRxTx() error
 	StopRxTx()
 	GetEnabledFlag() bool
 	SetEnabledFlag()
 	ProcessCreateDRAIntf(string)
+	ProcessUpdateDRAIntf(string)
 	ProcessDeleteDRAIntf(string)
 	ProcessActiveDRAIntf(int)
 	ProcessInactiveDRAIntf(int)
+	ProcessCreateSnoopingIntf(string)
+	ProcessDeleteSnoopingIntf(string)
 	GetClientState(string) (*dhcprelayd.DHCPRelayClientState, bool)
 	GetClientStateSlice(int, int) (int, int, bool, []*dhcprelayd.DHCPRelayClientState)
 	GetIntfState(string) (*dhcprelayd.DHCPRelayIntfState, bool)
 	GetIntfStateSlice(int, int) (int, int, bool, []*dhcprelayd.DHCPRelayIntfState)
 	GetIntfServerState(string, string) (*dhcprelayd.DHCPRelayIntfServerState, bool)
 	GetIntfServerStateSlice(int, int) (int, int, bool, []*dhcprelayd.DHCPRelayIntfServerState)
+	GetBindingState(string, string) (*dhcprelayd.DHCPRelayBindingState, bool)
+	GetBindingStateSlice(int, int) (int, int, bool, []*dhcprelayd.DHCPRelayBindingState)
+	GetSnoopingBindingState(string) (*dhcprelayd.DHCPRelaySnoopingBindingState, bool)
+	GetSnoopingBindingStateSlice(int, int) (int, int, bool, []*dhcprelayd.DHCPRelaySnoopingBindingState)
+	GetStateChangeCh() chan bool
+	GetStateCheckpoint() *dhcp4.StateCheckpoint
+	RestoreStateCheckpoint(*dhcp4.StateCheckpoint)
 }
 
 type IPv6ProcessorIntf interface {
-	StartRxTx()
+	StartRxTx() error
 	StopRxTx()
 	GetEnabledFlag() bool
 	SetEnabledFlag()
 	ProcessCreateDRAIntf(string)
+	ProcessUpdateDRAIntf(string)
 	ProcessDeleteDRAIntf(string)
 	ProcessActiveDRAIntf(int)
 	ProcessInactiveDRAIntf(int)
@@ -59,4 +72,9 @@ type IPv6ProcessorIntf interface {
 	GetIntfStateSlice(int, int) (int, int, bool, []*dhcprelayd.DHCPv6RelayIntfState)
 	GetIntfServerState(string, string) (*dhcprelayd.DHCPv6RelayIntfServerState, bool)
 	GetIntfServerStateSlice(int, int) (int, int, bool, []*dhcprelayd.DHCPv6RelayIntfServerState)
+	GetPDState(string, string) (*dhcprelayd.DHCPv6RelayPDState, bool)
+	GetPDStateSlice(int, int) (int, int, bool, []*dhcprelayd.DHCPv6RelayPDState)
+	GetStateChangeCh() chan bool
+	GetStateCheckpoint() *dhcp6.StateCheckpoint
+	RestoreStateCheckpoint(*dhcp6.StateCheckpoint)
 }
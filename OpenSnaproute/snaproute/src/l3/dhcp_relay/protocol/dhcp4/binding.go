@@ -0,0 +1,246 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package dhcp4
+
+import (
+	"dhcprelayd"
+	"encoding/binary"
+	"encoding/hex"
+	"l3/dhcp_relay/infra"
+	"math"
+	"strconv"
+	"time"
+)
+
+func getBindingKey(macAddr string, xId string) string {
+	return macAddr + "_" + xId
+}
+
+func getXIdString(xId []byte) string {
+	return "0x" + hex.EncodeToString(xId)
+}
+
+func (pProc *Processor) GetBindingStateSlice(
+	fromIdx, count int) (int, int, bool, []*dhcprelayd.DHCPRelayBindingState) {
+
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	var nextIdx int
+	var more bool
+	var actualCount int
+	length := len(pProc.BindingStateSlice)
+	if fromIdx < 0 || fromIdx >= length || count <= 0 {
+		return 0, 0, false, []*dhcprelayd.DHCPRelayBindingState{}
+	}
+	if fromIdx+count >= length {
+		actualCount = length - fromIdx
+		nextIdx = 0
+		more = false
+	} else {
+		actualCount = count
+		nextIdx = fromIdx + count
+		more = true
+	}
+
+	result := make([]*dhcprelayd.DHCPRelayBindingState, actualCount)
+	copy(result, pProc.BindingStateSlice[fromIdx:fromIdx+actualCount])
+	return nextIdx, actualCount, more, result
+}
+
+func (pProc *Processor) GetBindingState(
+	macAddr string, xId string) (*dhcprelayd.DHCPRelayBindingState, bool) {
+
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	if bindingState, ok := pProc.BindingStateMap[getBindingKey(macAddr, xId)]; ok {
+		return bindingState, true
+	} else {
+		return nil, false
+	}
+}
+
+// Caller needs to hold StateMutex
+func (pProc *Processor) addBindingState(bindingKey string,
+	bindingState *dhcprelayd.DHCPRelayBindingState) {
+
+	pProc.BindingStateIdx[bindingKey] = len(pProc.BindingStateSlice)
+	pProc.BindingStateSlice = append(pProc.BindingStateSlice, bindingState)
+	pProc.BindingStateMap[bindingKey] = bindingState
+}
+
+// Caller needs to hold StateMutex. The last binding of the slice takes the
+// place of the deleted one
+func (pProc *Processor) deleteBindingState(bindingKey string) {
+	idx, ok := pProc.BindingStateIdx[bindingKey]
+	if !ok {
+		return
+	}
+	lastIdx := len(pProc.BindingStateSlice) - 1
+	lastEntry := pProc.BindingStateSlice[lastIdx]
+	pProc.BindingStateSlice[idx] = lastEntry
+	pProc.BindingStateIdx[getBindingKey(lastEntry.MacAddr, lastEntry.XId)] = idx
+	pProc.BindingStateSlice[lastIdx] = nil
+	pProc.BindingStateSlice = pProc.BindingStateSlice[:lastIdx]
+	delete(pProc.BindingStateIdx, bindingKey)
+	delete(pProc.BindingStateMap, bindingKey)
+	delete(pProc.BindingExpiry, bindingKey)
+	pProc.notifyStateChange()
+}
+
+// Records the client facing interface for the transaction of a client
+// request, replies from the server are sent out on this interface
+func (pProc *Processor) learnBinding(inIntfProp *infra.IPv4IntfProperty,
+	inReq DhcpRelayAgentPacket, mType MessageType) {
+
+	vrf := pProc.InfraMgr.GetDRAv4IntfVrf(inIntfProp.IfRef)
+
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	macAddr := inReq.GetCHAddr().String()
+	switch mType {
+	case DhcpRelease, DhcpDecline:
+		// Client gave up its address, no more replies expected
+		for key, bindingState := range pProc.BindingStateMap {
+			if bindingState.MacAddr == macAddr {
+				pProc.deleteBindingState(key)
+			}
+		}
+		return
+	}
+	xId := getXIdString(inReq.GetXId())
+	bindingKey := getBindingKey(macAddr, xId)
+	bindingState, ok := pProc.BindingStateMap[bindingKey]
+	if !ok {
+		bindingState = &dhcprelayd.DHCPRelayBindingState{}
+		bindingState.MacAddr = macAddr
+		bindingState.XId = xId
+		pProc.addBindingState(bindingKey, bindingState)
+		pProc.notifyStateChange()
+	}
+	bindingState.IntfRef = inIntfProp.IfRef
+	bindingState.Vrf = vrf
+	if inIntfProp.L2IntfType == "Vlan" {
+		bindingState.Vlan = inIntfProp.L2IntfId
+	} else {
+		bindingState.Vlan = 0
+	}
+	if bindingState.State != BINDING_STATE_BOUND {
+		expiry := time.Now().Add(BINDING_PENDING_TIMEOUT)
+		bindingState.State = BINDING_STATE_REQUESTING
+		bindingState.LeaseExpiry = strconv.FormatInt(expiry.Unix(), 10)
+		pProc.BindingExpiry[bindingKey] = expiry
+	}
+}
+
+// Updates the binding for the server reply and returns the client facing
+// interface on which the reply needs to be sent out
+func (pProc *Processor) updateBinding(inPkt DhcpRelayAgentPacket,
+	reqOptions DhcpRelayAgentOptions, mType MessageType) (string, bool) {
+
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	macAddr := inPkt.GetCHAddr().String()
+	bindingKey := getBindingKey(macAddr, getXIdString(inPkt.GetXId()))
+	bindingState, ok := pProc.BindingStateMap[bindingKey]
+	if !ok {
+		return "", false
+	}
+	outIfName := bindingState.IntfRef
+	switch mType {
+	case DhcpOffer:
+		bindingState.OfferedIp = inPkt.GetYIAddr().String()
+		bindingState.State = BINDING_STATE_OFFERED
+		pProc.notifyStateChange()
+	case DhcpACK:
+		leaseVal, ok := reqOptions[OptionIPAddressLeaseTime]
+		if !ok || len(leaseVal) != 4 {
+			// Ack for inform carries no lease
+			break
+		}
+		leaseTime := binary.BigEndian.Uint32(leaseVal)
+		if leaseTime > math.MaxInt32 {
+			leaseTime = math.MaxInt32
+		}
+		expiry := time.Now().Add(time.Duration(leaseTime) * time.Second)
+		bindingState.AcceptedIp = inPkt.GetYIAddr().String()
+		bindingState.LeaseTime = int32(leaseTime)
+		bindingState.LeaseExpiry = strconv.FormatInt(expiry.Unix(), 10)
+		bindingState.State = BINDING_STATE_BOUND
+		pProc.BindingExpiry[bindingKey] = expiry
+		pProc.notifyStateChange()
+		// Older transactions of the client are superseded by this lease
+		for key, entry := range pProc.BindingStateMap {
+			if entry.MacAddr == macAddr && key != bindingKey {
+				pProc.deleteBindingState(key)
+			}
+		}
+	case DhcpNAK:
+		pProc.deleteBindingState(bindingKey)
+	}
+	return outIfName, true
+}
+
+func (pProc *Processor) deleteIntfBindings(ifName string) {
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	for key, bindingState := range pProc.BindingStateMap {
+		if bindingState.IntfRef == ifName {
+			pProc.deleteBindingState(key)
+		}
+	}
+}
+
+func (pProc *Processor) ageBindings() {
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	now := time.Now()
+	for key, expiry := range pProc.BindingExpiry {
+		if now.After(expiry) {
+			Logger.Debug("DRA: Binding expired for", key)
+			pProc.deleteBindingState(key)
+		}
+	}
+	pProc.ageSnoopingBindings(now)
+	pProc.RateLimiter.AgeClients(now)
+}
+
+func (pProc *Processor) bindingAging(quit chan bool) {
+	ticker := time.NewTicker(BINDING_AGING_INTERVAL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			pProc.ageBindings()
+			pProc.updateServerHealth()
+		case <-quit:
+			return
+		}
+	}
+}
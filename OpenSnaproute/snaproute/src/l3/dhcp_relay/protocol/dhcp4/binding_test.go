@@ -0,0 +1,177 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package dhcp4
+
+import (
+	"dhcprelayd"
+	"encoding/binary"
+	"net"
+	"testing"
+	"time"
+)
+
+func newTestClientPkt(macAddr string, xId uint32) DhcpRelayAgentPacket {
+	pkt := newTestRequest(DHCP_NO_IP, 0)
+	mac, _ := net.ParseMAC(macAddr)
+	pkt.SetCHAddr(mac)
+	xIdBytes := make([]byte, 4)
+	binary.BigEndian.PutUint32(xIdBytes, xId)
+	pkt.SetXId(xIdBytes)
+	return pkt
+}
+
+// Slice, map and index of the bindings need to agree with each other
+func checkBindingIndex(t *testing.T, pProc *Processor) {
+	if len(pProc.BindingStateSlice) != len(pProc.BindingStateMap) ||
+		len(pProc.BindingStateSlice) != len(pProc.BindingStateIdx) {
+		t.Fatal("Binding slice, map and index out of sync",
+			len(pProc.BindingStateSlice), len(pProc.BindingStateMap),
+			len(pProc.BindingStateIdx))
+	}
+	for key, idx := range pProc.BindingStateIdx {
+		if pProc.BindingStateSlice[idx] != pProc.BindingStateMap[key] {
+			t.Fatal("Binding index of", key, "is stale")
+		}
+	}
+}
+
+func TestLearnBinding(t *testing.T) {
+	pProc, ipv4Intf := initTestProcessor(t, &dhcprelayd.DHCPRelayIntf{
+		Enable: true,
+	})
+	pProc.learnBinding(ipv4Intf,
+		newTestClientPkt("00:00:00:00:00:01", 1), DhcpDiscover)
+	pProc.learnBinding(ipv4Intf,
+		newTestClientPkt("00:00:00:00:00:01", 1), DhcpRequest)
+	pProc.learnBinding(ipv4Intf,
+		newTestClientPkt("00:00:00:00:00:02", 2), DhcpDiscover)
+	checkBindingIndex(t, pProc)
+	if len(pProc.BindingStateSlice) != 2 {
+		t.Fatal("Wrong number of bindings", len(pProc.BindingStateSlice))
+	}
+	bindingState, ok := pProc.GetBindingState("00:00:00:00:00:01", "0x00000001")
+	if !ok {
+		t.Fatal("Binding not learnt")
+	}
+	if bindingState.IntfRef != "eth0" || bindingState.Vrf != "default" ||
+		bindingState.State != BINDING_STATE_REQUESTING {
+		t.Error("Wrong binding", bindingState)
+	}
+	// Release removes all transactions of the client
+	pProc.learnBinding(ipv4Intf,
+		newTestClientPkt("00:00:00:00:00:01", 3), DhcpRelease)
+	checkBindingIndex(t, pProc)
+	if _, ok := pProc.GetBindingState("00:00:00:00:00:01", "0x00000001"); ok {
+		t.Error("Binding not deleted on release")
+	}
+	if _, ok := pProc.GetBindingState("00:00:00:00:00:02", "0x00000002"); !ok {
+		t.Error("Binding of other client deleted on release")
+	}
+}
+
+func TestUpdateBinding(t *testing.T) {
+	pProc, ipv4Intf := initTestProcessor(t, &dhcprelayd.DHCPRelayIntf{
+		Enable: true,
+	})
+	macAddr := "00:00:00:00:00:01"
+	pProc.learnBinding(ipv4Intf, newTestClientPkt(macAddr, 1), DhcpDiscover)
+	pProc.learnBinding(ipv4Intf, newTestClientPkt(macAddr, 2), DhcpDiscover)
+	pProc.learnBinding(ipv4Intf,
+		newTestClientPkt("00:00:00:00:00:02", 3), DhcpDiscover)
+	if _, ok := pProc.updateBinding(newTestClientPkt(macAddr, 4),
+		make(DhcpRelayAgentOptions), DhcpOffer); ok {
+		t.Error("Reply for unknown transaction accepted")
+	}
+	offer := newTestClientPkt(macAddr, 2)
+	offer.SetYIAddr(net.ParseIP("10.0.0.10"))
+	outIfName, ok := pProc.updateBinding(offer,
+		make(DhcpRelayAgentOptions), DhcpOffer)
+	if !ok || outIfName != "eth0" {
+		t.Fatal("Offer not matched to binding", outIfName)
+	}
+	bindingState, _ := pProc.GetBindingState(macAddr, "0x00000002")
+	if bindingState.State != BINDING_STATE_OFFERED ||
+		bindingState.OfferedIp != "10.0.0.10" {
+		t.Error("Wrong binding after offer", bindingState)
+	}
+	ackOptions := make(DhcpRelayAgentOptions)
+	ackOptions[OptionIPAddressLeaseTime] = []byte{0, 0, 0x0e, 0x10}
+	if _, ok := pProc.updateBinding(offer, ackOptions, DhcpACK); !ok {
+		t.Fatal("Ack not matched to binding")
+	}
+	if bindingState.State != BINDING_STATE_BOUND ||
+		bindingState.AcceptedIp != "10.0.0.10" ||
+		bindingState.LeaseTime != 3600 {
+		t.Error("Wrong binding after ack", bindingState)
+	}
+	// Older transaction of the client is superseded by the lease
+	checkBindingIndex(t, pProc)
+	if _, ok := pProc.GetBindingState(macAddr, "0x00000001"); ok {
+		t.Error("Superseded binding not deleted")
+	}
+	if len(pProc.BindingStateSlice) != 2 {
+		t.Error("Wrong number of bindings", len(pProc.BindingStateSlice))
+	}
+	nak := newTestClientPkt("00:00:00:00:00:02", 3)
+	if _, ok := pProc.updateBinding(nak,
+		make(DhcpRelayAgentOptions), DhcpNAK); !ok {
+		t.Fatal("Nak not matched to binding")
+	}
+	checkBindingIndex(t, pProc)
+	if _, ok := pProc.GetBindingState("00:00:00:00:00:02", "0x00000003"); ok {
+		t.Error("Binding not deleted on nak")
+	}
+}
+
+func TestAgeBindings(t *testing.T) {
+	pProc, ipv4Intf := initTestProcessor(t, &dhcprelayd.DHCPRelayIntf{
+		Enable: true,
+	})
+	for xId := uint32(1); xId <= 10; xId++ {
+		pProc.learnBinding(ipv4Intf,
+			newTestClientPkt("00:00:00:00:00:01", xId), DhcpDiscover)
+	}
+	// Expire every other binding
+	for i, entry := range pProc.BindingStateSlice {
+		if i%2 == 0 {
+			pProc.BindingExpiry[getBindingKey(entry.MacAddr, entry.XId)] =
+				time.Now().Add(-time.Second)
+		}
+	}
+	pProc.ageBindings()
+	checkBindingIndex(t, pProc)
+	if len(pProc.BindingStateSlice) != 5 {
+		t.Error("Wrong number of bindings after aging",
+			len(pProc.BindingStateSlice))
+	}
+	nextIdx, count, more, result := pProc.GetBindingStateSlice(0, 3)
+	if nextIdx != 3 || count != 3 || !more || len(result) != 3 {
+		t.Error("Wrong binding slice", nextIdx, count, more)
+	}
+	pProc.deleteIntfBindings("eth0")
+	checkBindingIndex(t, pProc)
+	if len(pProc.BindingStateSlice) != 0 {
+		t.Error("Bindings of interface not deleted")
+	}
+}
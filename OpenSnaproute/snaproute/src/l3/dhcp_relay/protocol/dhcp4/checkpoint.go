@@ -0,0 +1,171 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package dhcp4
+
+import (
+	"dhcprelayd"
+	"strconv"
+	"time"
+)
+
+// Copy of the relay state written to and read back from the state DB
+type StateCheckpoint struct {
+	ClientStates     []*dhcprelayd.DHCPRelayClientState
+	IntfStates       []*dhcprelayd.DHCPRelayIntfState
+	IntfServerStates []*dhcprelayd.DHCPRelayIntfServerState
+	BindingStates    []*dhcprelayd.DHCPRelayBindingState
+
+	SnoopingBindingStates []*dhcprelayd.DHCPRelaySnoopingBindingState
+}
+
+// Lease expiry is kept in seconds since the Unix epoch
+func parseLeaseExpiry(leaseExpiry string) (time.Time, bool) {
+	secs, err := strconv.ParseInt(leaseExpiry, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return time.Unix(secs, 0), true
+}
+
+func (pProc *Processor) GetStateChangeCh() chan bool {
+	return pProc.StateChangeCh
+}
+
+// Caller needs to hold StateMutex, pending notification is enough as the
+// checkpoint picks up all changes
+func (pProc *Processor) notifyStateChange() {
+	select {
+	case pProc.StateChangeCh <- true:
+	default:
+	}
+}
+
+func (pProc *Processor) GetStateCheckpoint() *StateCheckpoint {
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	checkpoint := &StateCheckpoint{}
+	for _, entry := range pProc.ClientStateSlice {
+		clientState := *entry
+		checkpoint.ClientStates = append(
+			checkpoint.ClientStates, &clientState)
+	}
+	for _, entry := range pProc.IntfStateSlice {
+		intfState := *entry
+		checkpoint.IntfStates = append(checkpoint.IntfStates, &intfState)
+	}
+	for _, entry := range pProc.IntfServerStateSlice {
+		intfServerState := *entry
+		checkpoint.IntfServerStates = append(
+			checkpoint.IntfServerStates, &intfServerState)
+	}
+	for _, entry := range pProc.BindingStateSlice {
+		bindingState := *entry
+		checkpoint.BindingStates = append(
+			checkpoint.BindingStates, &bindingState)
+	}
+	for _, entry := range pProc.SnoopingBindingStateSlice {
+		bindingState := *entry
+		checkpoint.SnoopingBindingStates = append(
+			checkpoint.SnoopingBindingStates, &bindingState)
+	}
+	return checkpoint
+}
+
+// Restores state saved before a restart, interface specific state is only
+// kept for interfaces which are still configured for relay and expired
+// bindings are dropped
+func (pProc *Processor) RestoreStateCheckpoint(checkpoint *StateCheckpoint) {
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	for _, entry := range checkpoint.ClientStates {
+		if _, ok := pProc.ClientStateMap[entry.MacAddr]; ok {
+			continue
+		}
+		clientState := *entry
+		pProc.ClientStateSlice = append(pProc.ClientStateSlice, &clientState)
+		pProc.ClientStateMap[clientState.MacAddr] = &clientState
+	}
+	for _, entry := range checkpoint.IntfStates {
+		intfState, ok := pProc.IntfStateMap[entry.IntfRef]
+		if !ok {
+			Logger.Debug("DRA: Dropping state of unconfigured interface",
+				entry.IntfRef)
+			continue
+		}
+		// Policy and Vrf in use are derived from the current config
+		serverSelection := intfState.ServerSelection
+		vrf := intfState.Vrf
+		*intfState = *entry
+		intfState.ServerSelection = serverSelection
+		intfState.Vrf = vrf
+	}
+	for _, entry := range checkpoint.IntfServerStates {
+		if _, ok := pProc.IntfStateMap[entry.IntfRef]; !ok {
+			continue
+		}
+		intfServerStateKey := entry.IntfRef + "_" + entry.ServerIp
+		if _, ok := pProc.IntfServerStateMap[intfServerStateKey]; ok {
+			continue
+		}
+		intfServerState := *entry
+		pProc.IntfServerStateSlice = append(
+			pProc.IntfServerStateSlice, &intfServerState)
+		pProc.IntfServerStateMap[intfServerStateKey] = &intfServerState
+	}
+	now := time.Now()
+	for _, entry := range checkpoint.BindingStates {
+		if _, ok := pProc.IntfStateMap[entry.IntfRef]; !ok {
+			continue
+		}
+		expiry, ok := parseLeaseExpiry(entry.LeaseExpiry)
+		if !ok || now.After(expiry) {
+			continue
+		}
+		bindingKey := getBindingKey(entry.MacAddr, entry.XId)
+		if _, ok := pProc.BindingStateMap[bindingKey]; ok {
+			continue
+		}
+		bindingState := *entry
+		pProc.addBindingState(bindingKey, &bindingState)
+		pProc.BindingExpiry[bindingKey] = expiry
+	}
+	// Snooping bindings are learnt on L2 ports, they are only aged
+	for _, entry := range checkpoint.SnoopingBindingStates {
+		expiry, ok := parseLeaseExpiry(entry.LeaseExpiry)
+		if !ok || now.After(expiry) {
+			continue
+		}
+		if _, ok := pProc.SnoopingBindingStateMap[entry.MacAddr]; ok {
+			continue
+		}
+		bindingState := *entry
+		pProc.SnoopingBindingStateSlice = append(
+			pProc.SnoopingBindingStateSlice, &bindingState)
+		pProc.SnoopingBindingStateMap[bindingState.MacAddr] = &bindingState
+		pProc.SnoopingBindingExpiry[bindingState.MacAddr] = expiry
+	}
+	pProc.notifyStateChange()
+}
@@ -0,0 +1,187 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package dhcp4
+
+import (
+	"dhcprelayd"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestParseLeaseExpiry(t *testing.T) {
+	expiry, ok := parseLeaseExpiry("1476800000")
+	if !ok || !expiry.Equal(time.Unix(1476800000, 0)) {
+		t.Error("Wrong lease expiry", expiry)
+	}
+	if _, ok := parseLeaseExpiry("2016-10-18 14:13:20 +0000 UTC"); ok {
+		t.Error("Time stamp accepted as lease expiry")
+	}
+	if _, ok := parseLeaseExpiry(""); ok {
+		t.Error("Empty lease expiry accepted")
+	}
+	// Written by the binding table
+	pProc, ipv4Intf := initTestProcessor(t, &dhcprelayd.DHCPRelayIntf{
+		Enable: true,
+	})
+	pProc.learnBinding(ipv4Intf,
+		newTestClientPkt("00:00:00:00:00:01", 1), DhcpDiscover)
+	bindingState, _ := pProc.GetBindingState("00:00:00:00:00:01", "0x00000001")
+	expiry, ok = parseLeaseExpiry(bindingState.LeaseExpiry)
+	if !ok || expiry.Unix() !=
+		pProc.BindingExpiry[getBindingKey("00:00:00:00:00:01", "0x00000001")].Unix() {
+		t.Error("Binding lease expiry not parsed back", bindingState.LeaseExpiry)
+	}
+}
+
+func TestRestoreStateCheckpoint(t *testing.T) {
+	pProc, _ := initTestProcessor(t, &dhcprelayd.DHCPRelayIntf{
+		Enable:          true,
+		ServerSelection: SERVER_SELECTION_ROUND_ROBIN,
+	})
+	pProc.ProcessCreateDRAIntf("eth0")
+	now := time.Now().Unix()
+	validExpiry := strconv.FormatInt(now+3600, 10)
+	checkpoint := &StateCheckpoint{
+		ClientStates: []*dhcprelayd.DHCPRelayClientState{
+			&dhcprelayd.DHCPRelayClientState{
+				MacAddr:         "00:00:00:00:00:01",
+				ClientResponses: 3,
+			},
+		},
+		IntfStates: []*dhcprelayd.DHCPRelayIntfState{
+			&dhcprelayd.DHCPRelayIntfState{
+				IntfRef:           "eth0",
+				TotalDhcpClientRx: 10,
+				ServerSelection:   SERVER_SELECTION_PRIMARY_BACKUP,
+				Vrf:               "red",
+			},
+			&dhcprelayd.DHCPRelayIntfState{
+				IntfRef:           "eth9",
+				TotalDhcpClientRx: 20,
+			},
+		},
+		IntfServerStates: []*dhcprelayd.DHCPRelayIntfServerState{
+			&dhcprelayd.DHCPRelayIntfServerState{
+				IntfRef:  "eth0",
+				ServerIp: "20.0.0.1",
+				Request:  5,
+			},
+			&dhcprelayd.DHCPRelayIntfServerState{
+				IntfRef:  "eth9",
+				ServerIp: "20.0.0.1",
+			},
+		},
+		BindingStates: []*dhcprelayd.DHCPRelayBindingState{
+			&dhcprelayd.DHCPRelayBindingState{
+				MacAddr:     "00:00:00:00:00:01",
+				XId:         "0x00000001",
+				IntfRef:     "eth0",
+				LeaseExpiry: validExpiry,
+			},
+			&dhcprelayd.DHCPRelayBindingState{
+				MacAddr:     "00:00:00:00:00:02",
+				XId:         "0x00000002",
+				IntfRef:     "eth0",
+				LeaseExpiry: strconv.FormatInt(now-10, 10),
+			},
+			&dhcprelayd.DHCPRelayBindingState{
+				MacAddr:     "00:00:00:00:00:03",
+				XId:         "0x00000003",
+				IntfRef:     "eth9",
+				LeaseExpiry: validExpiry,
+			},
+		},
+		SnoopingBindingStates: []*dhcprelayd.DHCPRelaySnoopingBindingState{
+			&dhcprelayd.DHCPRelaySnoopingBindingState{
+				MacAddr:     "00:00:00:00:00:01",
+				IpAddr:      "10.0.0.10",
+				IntfRef:     "fpPort1",
+				LeaseExpiry: validExpiry,
+			},
+			&dhcprelayd.DHCPRelaySnoopingBindingState{
+				MacAddr:     "00:00:00:00:00:02",
+				IpAddr:      "10.0.0.11",
+				IntfRef:     "fpPort2",
+				LeaseExpiry: strconv.FormatInt(now-10, 10),
+			},
+		},
+	}
+	pProc.RestoreStateCheckpoint(checkpoint)
+	if clientState, ok := pProc.GetClientState("00:00:00:00:00:01"); !ok ||
+		clientState.ClientResponses != 3 {
+		t.Error("Client state not restored")
+	}
+	intfState, _ := pProc.GetIntfState("eth0")
+	if intfState.TotalDhcpClientRx != 10 {
+		t.Error("Interface counters not restored", intfState.TotalDhcpClientRx)
+	}
+	if intfState.ServerSelection != SERVER_SELECTION_ROUND_ROBIN ||
+		intfState.Vrf != "default" {
+		t.Error("Interface state not reconciled with config",
+			intfState.ServerSelection, intfState.Vrf)
+	}
+	if _, ok := pProc.GetIntfState("eth9"); ok {
+		t.Error("State of unconfigured interface restored")
+	}
+	if _, ok := pProc.GetIntfServerState("eth0", "20.0.0.1"); !ok {
+		t.Error("Interface server state not restored")
+	}
+	if _, ok := pProc.GetIntfServerState("eth9", "20.0.0.1"); ok {
+		t.Error("Server state of unconfigured interface restored")
+	}
+	checkBindingIndex(t, pProc)
+	if len(pProc.BindingStateSlice) != 1 {
+		t.Fatal("Wrong number of bindings restored", len(pProc.BindingStateSlice))
+	}
+	if _, ok := pProc.GetBindingState("00:00:00:00:00:01", "0x00000001"); !ok {
+		t.Error("Binding not restored")
+	}
+	expiry := pProc.BindingExpiry[getBindingKey("00:00:00:00:00:01", "0x00000001")]
+	if expiry.Unix() != now+3600 {
+		t.Error("Restored binding does not age", expiry)
+	}
+	if len(pProc.SnoopingBindingStateSlice) != 1 {
+		t.Fatal("Wrong number of snooping bindings restored",
+			len(pProc.SnoopingBindingStateSlice))
+	}
+	if _, ok := pProc.GetSnoopingBindingState("00:00:00:00:00:01"); !ok {
+		t.Error("Snooping binding not restored")
+	}
+	if _, ok := pProc.SnoopingBindingExpiry["00:00:00:00:00:01"]; !ok {
+		t.Error("Restored snooping binding does not age")
+	}
+	// Restored state is written back as is
+	saved := pProc.GetStateCheckpoint()
+	if len(saved.ClientStates) != 1 || len(saved.IntfStates) != 1 ||
+		len(saved.IntfServerStates) != 1 || len(saved.BindingStates) != 1 ||
+		len(saved.SnoopingBindingStates) != 1 {
+		t.Error("Wrong checkpoint of restored state")
+	}
+	select {
+	case <-pProc.GetStateChangeCh():
+	default:
+		t.Error("State change not signalled after restore")
+	}
+}
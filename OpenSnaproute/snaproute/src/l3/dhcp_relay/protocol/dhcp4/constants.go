@@ -37,6 +37,8 @@ const (
         DHCP_BROADCAST_IP       = "255.255.255.255"
         DHCP_NO_IP              = "0.0.0.0"
         DHCP_REDIS_DB_PORT      = ":6379"
+        DHCP_HOP_COUNT_LIMIT    = 32
+        DHCP_HOP_COUNT_MAX      = 255
 )
 
 const (
@@ -149,6 +151,51 @@ const (
         OptionClasslessRouteFormat DhcpOptionCode = 121
 )
 
+// Relay Agent Information (Option 82) sub-options
+const (
+        RelayAgentCircuitId        DhcpOptionCode = 1
+        RelayAgentRemoteId         DhcpOptionCode = 2
+        RelayAgentLinkSelection    DhcpOptionCode = 5  // RFC 3527
+        RelayAgentServerIdOverride DhcpOptionCode = 11 // RFC 5107
+)
+
+// Relay Agent Information config values
+const (
+        RELAY_AGENT_INFO_KEEP    = "Keep"
+        RELAY_AGENT_INFO_REPLACE = "Replace"
+        RELAY_AGENT_INFO_DROP    = "Drop"
+        CIRCUIT_ID_INTF_NAME     = "IntfName"
+        CIRCUIT_ID_VLAN          = "Vlan"
+)
+
+// Client binding states and timers
+const (
+        BINDING_STATE_REQUESTING = "Requesting"
+        BINDING_STATE_OFFERED    = "Offered"
+        BINDING_STATE_BOUND      = "Bound"
+        BINDING_PENDING_TIMEOUT  = 60 * time.Second
+        BINDING_AGING_INTERVAL   = 10 * time.Second
+)
+
+// DHCP messages seen at L2 ingress of snooping interfaces
+const (
+        SNOOPING_INGRESS_TIMEOUT = 10 * time.Second
+        SNOOPING_INGRESS_MAX     = 4096
+        SNOOPING_PCAP_FILTER     = "udp and (port 67 or port 68)"
+)
+
+// Server selection policies and liveness
+const (
+        SERVER_SELECTION_BROADCAST_ALL  = "BroadcastAll"
+        SERVER_SELECTION_PRIMARY_BACKUP = "PrimaryBackup"
+        SERVER_SELECTION_ROUND_ROBIN    = "RoundRobin"
+        SERVER_SELECTION_HASH_BY_MAC    = "HashByMac"
+        SERVER_HEALTH_UP                = "Up"
+        SERVER_HEALTH_DOWN              = "Down"
+        SERVER_RESPONSE_TIMEOUT         = 5 * time.Second
+        SERVER_RETRY_INTERVAL           = 30 * time.Second
+)
+
 // Dhcp OpCodes Types
 const (
         Request OpCode = 1 // From Client
@@ -270,6 +270,33 @@ func (p *DhcpRelayAgentPacket) AddDhcpOptions(op DhcpOptionCode, value []byte) {
 	*p = append(*p, byte(End)) // Add on new End
 }
 
+// Builds the value of Relay Agent Information option out of Circuit-ID and
+// Remote-ID sub-options
+func DhcpRelayAgentInfoOption(circuitId []byte, remoteId []byte) []byte {
+	value := make([]byte, 0, 4+len(circuitId)+len(remoteId))
+	if len(circuitId) > 0 {
+		value = append(value, byte(RelayAgentCircuitId), byte(len(circuitId)))
+		value = append(value, circuitId...)
+	}
+	if len(remoteId) > 0 {
+		value = append(value, byte(RelayAgentRemoteId), byte(len(remoteId)))
+		value = append(value, remoteId...)
+	}
+	return value
+}
+
+// Builds the Link Selection and Server Identifier Override sub-options of
+// Relay Agent Information, both carrying the address of the client facing
+// interface
+func DhcpRelayAgentLinkSubOptions(linkAddr net.IP) []byte {
+	value := make([]byte, 0, 4+2*net.IPv4len)
+	value = append(value, byte(RelayAgentLinkSelection), net.IPv4len)
+	value = append(value, linkAddr.To4()...)
+	value = append(value, byte(RelayAgentServerIdOverride), net.IPv4len)
+	value = append(value, linkAddr.To4()...)
+	return value
+}
+
 // SelectOrder returns a slice of options ordered and selected by a byte array
 // usually defined by OptionParameterRequestList.  This result is expected to be
 // used in ReplyPacket()'s []Option parameter.
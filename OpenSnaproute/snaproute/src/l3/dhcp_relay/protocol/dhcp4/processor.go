@@ -24,11 +24,11 @@
 package dhcp4
 
 import (
+	"bytes"
 	"dhcprelayd"
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
 	"github.com/google/gopacket/pcap"
-	"golang.org/x/net/ipv4"
 	"l3/dhcp_relay/infra"
 	"net"
 	"strconv"
@@ -40,13 +40,13 @@ import (
 var Logger logging.LoggerIntf
 
 type Processor struct {
-	Logger        logging.LoggerIntf
-	InfraMgr      *infra.InfraMgr
-	ClientHandler *net.UDPConn
-	ClientConn    *ipv4.PacketConn
+	Logger   logging.LoggerIntf
+	InfraMgr *infra.InfraMgr
+	// Sockets keyed by Vrf
+	VrfConns *infra.VrfConnMgr
 
-	PeerAddrIntfMap map[string]string
-	PcapHandles     map[int]*pcap.Handle
+	PcapHandles map[int]*pcap.Handle
+	AgingQuit   chan bool
 
 	// States
 	ClientStateSlice     []*dhcprelayd.DHCPRelayClientState
@@ -55,7 +55,26 @@ type Processor struct {
 	IntfStateMap         map[string]*dhcprelayd.DHCPRelayIntfState
 	IntfServerStateSlice []*dhcprelayd.DHCPRelayIntfServerState
 	IntfServerStateMap   map[string]*dhcprelayd.DHCPRelayIntfServerState
-	StateMutex           sync.Mutex
+	BindingStateSlice    []*dhcprelayd.DHCPRelayBindingState
+	BindingStateMap      map[string]*dhcprelayd.DHCPRelayBindingState
+	BindingStateIdx      map[string]int
+	BindingExpiry        map[string]time.Time
+	// Snooping bindings keyed by mac address
+	SnoopingBindingStateSlice []*dhcprelayd.DHCPRelaySnoopingBindingState
+	SnoopingBindingStateMap   map[string]*dhcprelayd.DHCPRelaySnoopingBindingState
+	SnoopingBindingExpiry     map[string]time.Time
+	SnoopingIngress           map[snoopingIngressKey]*snoopingIngress
+	SnoopingPcapHandles       map[string]*pcap.Handle
+	// Server selection keyed by intf + server ip and by intf
+	ServerLiveness map[string]*serverLiveness
+	RoundRobinIdx  map[string]int
+	// Per intf and per client rate limits, serialized by the limiter
+	RateLimiter *infra.RateLimiter
+	StateMutex  sync.Mutex
+	// Signalled when bindings change so that they are checkpointed
+	StateChangeCh chan bool
+	// Looks up the address the server VRF reaches a server from
+	ServerVrfAddr func(vrf string, dst *net.UDPAddr) (net.IP, error)
 
 	EnabledFlag  bool
 	EnabledMutex sync.Mutex
@@ -73,7 +92,10 @@ func NewProcessor(initParams *ProcessorInitParams) *Processor {
 		InfraMgr: initParams.InfraMgr,
 	}
 
-	pProc.PeerAddrIntfMap = make(map[string]string)
+	pProc.VrfConns = infra.NewVrfConnMgr(pProc.CreateConn,
+		func(vrf string, conn infra.VrfConn) {
+			pProc.RxTx(vrf, conn.(*vrfConn))
+		})
 	pProc.PcapHandles = make(map[int]*pcap.Handle)
 	pProc.ClientStateSlice = []*dhcprelayd.DHCPRelayClientState{}
 	pProc.ClientStateMap = make(map[string]*dhcprelayd.DHCPRelayClientState)
@@ -81,6 +103,21 @@ func NewProcessor(initParams *ProcessorInitParams) *Processor {
 	pProc.IntfStateMap = make(map[string]*dhcprelayd.DHCPRelayIntfState)
 	pProc.IntfServerStateSlice = []*dhcprelayd.DHCPRelayIntfServerState{}
 	pProc.IntfServerStateMap = make(map[string]*dhcprelayd.DHCPRelayIntfServerState)
+	pProc.BindingStateSlice = []*dhcprelayd.DHCPRelayBindingState{}
+	pProc.BindingStateMap = make(map[string]*dhcprelayd.DHCPRelayBindingState)
+	pProc.BindingStateIdx = make(map[string]int)
+	pProc.BindingExpiry = make(map[string]time.Time)
+	pProc.SnoopingBindingStateSlice = []*dhcprelayd.DHCPRelaySnoopingBindingState{}
+	pProc.SnoopingBindingStateMap = make(map[string]*dhcprelayd.DHCPRelaySnoopingBindingState)
+	pProc.SnoopingBindingExpiry = make(map[string]time.Time)
+	pProc.SnoopingIngress = make(map[snoopingIngressKey]*snoopingIngress)
+	pProc.SnoopingPcapHandles = make(map[string]*pcap.Handle)
+	pProc.ServerLiveness = make(map[string]*serverLiveness)
+	pProc.RoundRobinIdx = make(map[string]int)
+	pProc.RateLimiter = infra.NewRateLimiter(
+		infra.CLIENT_RATE_LIMIT_MAX_CLIENTS)
+	pProc.ServerVrfAddr = infra.GetIPv4SrcAddrInVrf
+	pProc.StateChangeCh = make(chan bool, 1)
 
 	pProc.EnabledMutex.Lock()
 	pProc.EnabledFlag = false
@@ -219,7 +256,7 @@ func (pProc *Processor) GetIntfServerState(
 }
 
 func (pProc *Processor) initClientState(
-	clientAddr string) *dhcprelayd.DHCPRelayClientState {
+	clientAddr string, vrf string) *dhcprelayd.DHCPRelayClientState {
 
 	defer pProc.StateMutex.Unlock()
 	pProc.StateMutex.Lock()
@@ -232,11 +269,12 @@ func (pProc *Processor) initClientState(
 		pProc.ClientStateSlice = append(pProc.ClientStateSlice, clientState)
 		pProc.ClientStateMap[clientStateKey] = clientState
 	}
+	clientState.Vrf = vrf
 	return clientState
 }
 
 func (pProc *Processor) initIntfState(
-	ifName string) *dhcprelayd.DHCPRelayIntfState {
+	ifName string, vrf string) *dhcprelayd.DHCPRelayIntfState {
 
 	defer pProc.StateMutex.Unlock()
 	pProc.StateMutex.Lock()
@@ -248,6 +286,7 @@ func (pProc *Processor) initIntfState(
 		pProc.IntfStateSlice = append(pProc.IntfStateSlice, intfState)
 		pProc.IntfStateMap[ifName] = intfState
 	}
+	intfState.Vrf = vrf
 	return intfState
 }
 
@@ -278,6 +317,9 @@ func (pProc *Processor) deleteIntfState(
 func (pProc *Processor) initIntfServerState(
 	ifName string, serverAddr string) *dhcprelayd.DHCPRelayIntfServerState {
 
+	serverVrf := pProc.InfraMgr.GetDRAv4ServerVrf(
+		pProc.InfraMgr.GetDRAv4IntfVrf(ifName))
+
 	defer pProc.StateMutex.Unlock()
 	pProc.StateMutex.Lock()
 
@@ -287,27 +329,32 @@ func (pProc *Processor) initIntfServerState(
 		intfServerState = &dhcprelayd.DHCPRelayIntfServerState{}
 		intfServerState.IntfRef = ifName
 		intfServerState.ServerIp = serverAddr
+		intfServerState.Health = SERVER_HEALTH_UP
 		pProc.IntfServerStateSlice = append(
 			pProc.IntfServerStateSlice, intfServerState)
 		pProc.IntfServerStateMap[intfServerStateKey] = intfServerState
 	}
+	intfServerState.Vrf = serverVrf
 	return intfServerState
 }
 
-func (pProc *Processor) StartRxTx() {
+// Sockets that fail to open are reported in the error and retried on the
+// next StartRxTx
+func (pProc *Processor) StartRxTx() error {
 	Logger.Debug("pProc: StartRxTx being called")
 
 	defer pProc.EnabledMutex.Unlock()
 	pProc.EnabledMutex.Lock()
 
-	if pProc.EnabledFlag {
-		return
-	}
-	if !pProc.CreateConn() {
-		return
+	if !pProc.EnabledFlag {
+		if pProc.AgingQuit == nil {
+			pProc.AgingQuit = make(chan bool)
+			go pProc.bindingAging(pProc.AgingQuit)
+		}
+		pProc.EnabledFlag = true
 	}
-	go pProc.RxTx()
-	pProc.EnabledFlag = true
+	// Relay VRFs may have changed while enabled
+	return pProc.VrfConns.Sync(pProc.InfraMgr.GetActiveDRAv4Vrfs())
 }
 
 func (pProc *Processor) StopRxTx() {
@@ -315,39 +362,15 @@ func (pProc *Processor) StopRxTx() {
 
 	defer pProc.EnabledMutex.Unlock()
 	pProc.EnabledMutex.Lock()
-	// Close UDP socket connection of Rx thread
-	if pProc.ClientConn != nil {
-		err := pProc.ClientConn.Close()
-		if err != nil {
-			Logger.Err("Error while closing socket")
-		}
-		pProc.ClientConn = nil
+	// Close UDP socket connections of Rx threads
+	pProc.VrfConns.CloseAll()
+	if pProc.AgingQuit != nil {
+		close(pProc.AgingQuit)
+		pProc.AgingQuit = nil
 	}
 	pProc.EnabledFlag = false
 }
 
-func (pProc *Processor) CreateConn() bool {
-	saddr := net.UDPAddr{
-		IP:   net.ParseIP(""),
-		Port: DHCP_SERVER_PORT,
-	}
-	var err error
-	pProc.ClientHandler, err = net.ListenUDP("udp4", &saddr)
-	if err != nil {
-		Logger.Err("Opening udp port for client --> server failed", err)
-		return false
-	}
-	pProc.ClientConn = ipv4.NewPacketConn(pProc.ClientHandler)
-	controlFlag := ipv4.FlagSrc | ipv4.FlagDst | ipv4.FlagInterface
-	err = pProc.ClientConn.SetControlMessage(controlFlag, true)
-	if err != nil {
-		Logger.Err("Setting control flag for client failed..", err)
-		return false
-	}
-	Logger.Debug("Client Connection opened successfully")
-	return true
-}
-
 func (pProc *Processor) setUpstreamInState(
 	mType MessageType,
 	inPkt DhcpRelayAgentPacket,
@@ -392,24 +415,34 @@ func (pProc *Processor) setDownstreamInState(
 	}
 }
 
-func (pProc *Processor) DhcpRelayAgentSendClientOptPacket(
-	inIntfProp *infra.IPv4IntfProperty,
-	inReq DhcpRelayAgentPacket, reqOptions DhcpRelayAgentOptions,
-	mt MessageType, intfState *dhcprelayd.DHCPRelayIntfState,
-	clientState *dhcprelayd.DHCPRelayClientState) {
+// Client request relayed to the server, the GIAddr set by a downstream relay
+// agent is kept and hops is incremented
+func createRelayedPacket(giAddr net.IP,
+	inReq DhcpRelayAgentPacket) DhcpRelayAgentPacket {
 
-	// Create Packet
 	outPacket := DhcpRelayAgentCreateNewPacket(Request, inReq)
+	outPacket.SetHops(inReq.GetHops() + 1)
 	if inReq.GetGIAddr().String() == DHCP_NO_IP {
-		outPacket.SetGIAddr(net.ParseIP(inIntfProp.IpAddr))
+		outPacket.SetGIAddr(giAddr)
 	} else {
 		Logger.Debug("DRA: Relay Agent " + inReq.GetGIAddr().String() +
 			" requested for DHCP for HOST " + inReq.GetCHAddr().String())
 		outPacket.SetGIAddr(inReq.GetGIAddr())
 	}
+	return outPacket
+}
 
-	requestedIp, serverIp := DhcpRelayAgentAddOptionsToPacket(reqOptions,
-		mt, &outPacket)
+func (pProc *Processor) DhcpRelayAgentSendClientOptPacket(
+	inIntfProp *infra.IPv4IntfProperty,
+	inReq DhcpRelayAgentPacket, reqOptions DhcpRelayAgentOptions,
+	mt MessageType, intfState *dhcprelayd.DHCPRelayIntfState,
+	clientState *dhcprelayd.DHCPRelayClientState) {
+
+	var serverIp string
+	if serverId, ok := reqOptions[OptionServerIdentifier]; ok &&
+		len(serverId) == net.IPv4len {
+		serverIp = net.IP(serverId).String()
+	}
 	if serverIp == "" {
 		Logger.Warning("DRA: no server ip.. dropping the request")
 		pProc.StateMutex.Lock() // State obj lock
@@ -417,6 +450,18 @@ func (pProc *Processor) DhcpRelayAgentSendClientOptPacket(
 		pProc.StateMutex.Unlock() // State obj unlock
 		return
 	}
+	giAddr, ok := pProc.getGIAddr(inIntfProp, serverIp)
+	if !ok {
+		pProc.StateMutex.Lock() // State obj lock
+		intfState.TotalDrops++
+		pProc.StateMutex.Unlock() // State obj unlock
+		return
+	}
+	// Create Packet
+	outPacket := createRelayedPacket(giAddr, inReq)
+
+	requestedIp, _ := DhcpRelayAgentAddOptionsToPacket(reqOptions,
+		mt, &outPacket)
 	// get host + server state entry for updating the state
 	// Create server ip address + port number
 	serverIpPort := serverIp + ":" + strconv.Itoa(DHCP_SERVER_PORT)
@@ -432,10 +477,21 @@ func (pProc *Processor) DhcpRelayAgentSendClientOptPacket(
 	}
 	// Pad to minimum size of dhcp packet
 	outPacket.PadToMinSize()
+	serverTimeout := SERVER_RESPONSE_TIMEOUT
+	if draIntf, ok := pProc.InfraMgr.GetActiveDRAv4Intf(inIntfProp.IfIndex); ok {
+		serverTimeout = getServerTimeout(draIntf)
+	}
 	// send out the packet...
 	pProc.setUpstreamInState(mt, inReq, requestedIp, intfState, clientState)
 	intfServerState := pProc.initIntfServerState(inIntfProp.IfRef, serverIp)
-	_, err = pProc.ClientHandler.WriteToUDP(outPacket, serverAddr)
+	vConn, ok := pProc.getServerConn(inIntfProp)
+	if !ok {
+		pProc.StateMutex.Lock() // State obj lock
+		intfState.TotalDrops++
+		pProc.StateMutex.Unlock() // State obj unlock
+		return
+	}
+	_, err = vConn.Handler.WriteToUDP(outPacket, serverAddr)
 	if err != nil {
 		Logger.Debug("DRA: WriteToUDP failed with error:", err)
 		pProc.StateMutex.Lock() // State obj lock
@@ -447,10 +503,121 @@ func (pProc *Processor) DhcpRelayAgentSendClientOptPacket(
 	intfState.TotalDhcpServerTx++
 	clientState.ServerRequests++
 	intfServerState.Request++
+	pProc.serverRequestSent(intfServerState, serverTimeout)
 	pProc.StateMutex.Unlock() // State obj unlock
 	Logger.Debug("DRA: Create & Send of PKT successfully to server", serverIp)
 }
 
+// Relay Agent Information is added if configured on the interface and on
+// requests relayed across VRFs, which need the Link Selection of this relay
+// agent
+func (pProc *Processor) isRelayAgentInfoAdded(
+	intfProp *infra.IPv4IntfProperty, draIntf *dhcprelayd.DHCPRelayIntf) bool {
+
+	return draIntf.RelayAgentInfo || pProc.isCrossVrf(intfProp.IfRef)
+}
+
+func (pProc *Processor) buildRelayAgentInfo(
+	inIntfProp *infra.IPv4IntfProperty,
+	draIntf *dhcprelayd.DHCPRelayIntf) []byte {
+
+	var relayAgentInfo []byte
+	if draIntf.RelayAgentInfo {
+		relayAgentInfo = pProc.buildCircuitRemoteId(inIntfProp, draIntf)
+	}
+	if pProc.isCrossVrf(inIntfProp.IfRef) {
+		relayAgentInfo = append(relayAgentInfo,
+			DhcpRelayAgentLinkSubOptions(net.ParseIP(inIntfProp.IpAddr))...)
+	}
+	return relayAgentInfo
+}
+
+func (pProc *Processor) buildCircuitRemoteId(
+	inIntfProp *infra.IPv4IntfProperty,
+	draIntf *dhcprelayd.DHCPRelayIntf) []byte {
+
+	var circuitId []byte
+	if draIntf.CircuitIdType == CIRCUIT_ID_VLAN && inIntfProp.L2IntfType == "Vlan" {
+		circuitId = []byte(strconv.Itoa(int(inIntfProp.L2IntfId)))
+	} else {
+		circuitId = []byte(inIntfProp.IfRef)
+	}
+	var remoteId []byte
+	if draIntf.RemoteId != "" {
+		remoteId = []byte(draIntf.RemoteId)
+	} else {
+		remoteId = []byte(pProc.InfraMgr.GetSwitchMac())
+	}
+	return DhcpRelayAgentInfoOption(circuitId, remoteId)
+}
+
+// Only Relay Agent Information inserted by this relay agent is stripped from
+// server replies, information kept from the client request is left as is
+func (pProc *Processor) isOwnRelayAgentInfo(
+	outIntfProp *infra.IPv4IntfProperty,
+	reqOptions DhcpRelayAgentOptions) bool {
+
+	relayAgentInfo, ok := reqOptions[OptionRelayAgentInformation]
+	if !ok {
+		return false
+	}
+	draIntf, ok := pProc.InfraMgr.GetActiveDRAv4Intf(outIntfProp.IfIndex)
+	if !ok || !pProc.isRelayAgentInfoAdded(outIntfProp, draIntf) {
+		return false
+	}
+	return bytes.Equal(relayAgentInfo,
+		pProc.buildRelayAgentInfo(outIntfProp, draIntf))
+}
+
+// Applies Relay Agent Information config of the incoming interface on the
+// client request. Returns false if the request needs to be dropped
+func (pProc *Processor) processRelayAgentInfo(
+	inIntfProp *infra.IPv4IntfProperty, inReq DhcpRelayAgentPacket,
+	reqOptions DhcpRelayAgentOptions,
+	intfState *dhcprelayd.DHCPRelayIntfState) bool {
+
+	draIntf, ok := pProc.InfraMgr.GetActiveDRAv4Intf(inIntfProp.IfIndex)
+	if !ok || !pProc.isRelayAgentInfoAdded(inIntfProp, draIntf) {
+		return true
+	}
+	// Requests already relayed by a downstream relay agent are forwarded
+	// unmodified unless the interface is trusted (RFC 3046, section 2.1)
+	if inReq.GetGIAddr().String() != DHCP_NO_IP && !draIntf.RelayAgentInfoTrusted {
+		return true
+	}
+	relayAgentInfo := pProc.buildRelayAgentInfo(inIntfProp, draIntf)
+	policy := draIntf.RelayAgentInfoPolicy
+	if pProc.isCrossVrf(inIntfProp.IfRef) &&
+		(policy == RELAY_AGENT_INFO_KEEP || !draIntf.RelayAgentInfo) {
+		// Servers of another VRF select the client subnet by the Link
+		// Selection of this relay agent
+		policy = RELAY_AGENT_INFO_REPLACE
+	}
+
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	if _, ok := reqOptions[OptionRelayAgentInformation]; ok {
+		switch policy {
+		case RELAY_AGENT_INFO_KEEP:
+			intfState.RelayAgentInfoKept++
+			return true
+		case RELAY_AGENT_INFO_DROP:
+			Logger.Debug("DRA: Dropping request with Relay Agent Information on",
+				inIntfProp.IfRef)
+			intfState.RelayAgentInfoDropped++
+			intfState.TotalDrops++
+			return false
+		default:
+			intfState.RelayAgentInfoReplaced++
+		}
+	} else {
+		intfState.RelayAgentInfoInserted++
+	}
+	reqOptions[OptionRelayAgentInformation] = relayAgentInfo
+	return true
+}
+
 func DhcpRelayAgentAddOptionsToPacket(reqOptions DhcpRelayAgentOptions, mt MessageType,
 	outPacket *DhcpRelayAgentPacket) (string, string) {
 	outPacket.AddDhcpOptions(OptionDHCPMessageType, []byte{byte(mt)})
@@ -474,24 +641,24 @@ func DhcpRelayAgentAddOptionsToPacket(reqOptions DhcpRelayAgentOptions, mt Messa
 				serverIp = net.IPv4(option.Value[0], option.Value[1],
 					option.Value[2], option.Value[3]).String()
 				break
+			case OptionRelayAgentInformation:
+				// Relay Agent Information is added as the last option
+				continue
 			}
 			outPacket.AddDhcpOptions(option.Code, option.Value)
 			dummyDup[option.Code] = 9999
 		}
 	}
+	if relayAgentInfo, ok := reqOptions[OptionRelayAgentInformation]; ok {
+		outPacket.AddDhcpOptions(OptionRelayAgentInformation, relayAgentInfo)
+	}
 	return reqIp, serverIp
 }
 
 func (pProc *Processor) DhcpRelayAgentSendPacketToDhcpClient(
 	inReq DhcpRelayAgentPacket, outIfName string,
 	reqOptions DhcpRelayAgentOptions, mt MessageType,
-	serverIp net.IP) {
-
-	var outPacket DhcpRelayAgentPacket
-	outPacket = DhcpRelayAgentCreateNewPacket(Reply, inReq)
-	DhcpRelayAgentAddOptionsToPacket(reqOptions, mt, &outPacket)
-	// Pad to minimum size of dhcp packet
-	outPacket.PadToMinSize()
+	serverIp string) {
 
 	netIntf, err := net.InterfaceByName(outIfName)
 	if err != nil {
@@ -508,6 +675,16 @@ func (pProc *Processor) DhcpRelayAgentSendPacketToDhcpClient(
 		Logger.Debug("Cannot get non IPv4Intf for IfIndex,", ifIdx)
 		return
 	}
+
+	var outPacket DhcpRelayAgentPacket
+	outPacket = DhcpRelayAgentCreateNewPacket(Reply, inReq)
+	stripRelayAgentInfo := pProc.isOwnRelayAgentInfo(ipv4Intf, reqOptions)
+	if stripRelayAgentInfo {
+		delete(reqOptions, OptionRelayAgentInformation)
+	}
+	DhcpRelayAgentAddOptionsToPacket(reqOptions, mt, &outPacket)
+	// Pad to minimum size of dhcp packet
+	outPacket.PadToMinSize()
 	eth := &layers.Ethernet{
 		SrcMAC:       netIntf.HardwareAddr,
 		DstMAC:       outPacket.GetCHAddr(),
@@ -534,9 +711,10 @@ func (pProc *Processor) DhcpRelayAgentSendPacketToDhcpClient(
 	gopacket.SerializeLayers(buffer, goOpts, eth, ipv4, udp,
 		gopacket.Payload(outPacket))
 
-	intfState := pProc.initIntfState(outIfName)
-	clientState := pProc.initClientState(outPacket.GetCHAddr().String())
-	intfServerState := pProc.initIntfServerState(outIfName, serverIp.String())
+	outVrf := pProc.InfraMgr.GetDRAv4IntfVrf(outIfName)
+	intfState := pProc.initIntfState(outIfName, outVrf)
+	clientState := pProc.initClientState(outPacket.GetCHAddr().String(), outVrf)
+	intfServerState := pProc.initIntfServerState(outIfName, serverIp)
 	pProc.StateMutex.Lock()
 	pcapHdl, ok := pProc.PcapHandles[ifIdx]
 	pProc.StateMutex.Unlock()
@@ -565,6 +743,9 @@ func (pProc *Processor) DhcpRelayAgentSendPacketToDhcpClient(
 	}
 	pProc.StateMutex.Lock()
 	intfState.TotalDhcpClientTx++
+	if stripRelayAgentInfo {
+		intfState.RelayAgentInfoStripped++
+	}
 	intfServerState.Responses++
 	clientState.ClientResponses++
 	pProc.StateMutex.Unlock()
@@ -589,9 +770,15 @@ func (pProc *Processor) DhcpRelayAgentSendDiscoverPacket(
 	}
 	pProc.setUpstreamInState(mt, inReq, "", intfState, clientState)
 
+	serverIps := pProc.selectServers(inIntfProp, draIntf, inReq, intfState)
+	serverTimeout := getServerTimeout(draIntf)
+	vConn, ok := pProc.getServerConn(inIntfProp)
+	if !ok {
+		serverIps = nil
+	}
 	txOk := false
-	for i := 0; i < len(draIntf.ServerIp); i++ {
-		serverIpPort := draIntf.ServerIp[i] + ":" +
+	for i := 0; i < len(serverIps); i++ {
+		serverIpPort := serverIps[i] + ":" +
 			strconv.Itoa(DHCP_SERVER_PORT)
 		Logger.Debug("DRA: Sending DHCP PACKET to server: " + serverIpPort)
 		serverAddr, err := net.ResolveUDPAddr("udp", serverIpPort)
@@ -600,21 +787,18 @@ func (pProc *Processor) DhcpRelayAgentSendDiscoverPacket(
 			continue
 		}
 
-		outPacket := DhcpRelayAgentCreateNewPacket(Request, inReq)
-		if inReq.GetGIAddr().String() == DHCP_NO_IP {
-			outPacket.SetGIAddr(net.ParseIP(inIntfProp.IpAddr))
-		} else {
-			Logger.Debug("DRA: Relay Agent " + inReq.GetGIAddr().String() +
-				" requested for DHCP for HOST " + inReq.GetCHAddr().String())
-			outPacket.SetGIAddr(inReq.GetGIAddr())
+		giAddr, ok := pProc.getGIAddr(inIntfProp, serverIps[i])
+		if !ok {
+			continue
 		}
+		outPacket := createRelayedPacket(giAddr, inReq)
 
 		DhcpRelayAgentAddOptionsToPacket(reqOptions, mt, &outPacket)
 		// Pad to minimum size of dhcp packet
 		outPacket.PadToMinSize()
 		// send out the packet...
-		intfServerState := pProc.initIntfServerState(inIntfProp.IfRef, draIntf.ServerIp[i])
-		_, err = pProc.ClientHandler.WriteToUDP(outPacket, serverAddr)
+		intfServerState := pProc.initIntfServerState(inIntfProp.IfRef, serverIps[i])
+		_, err = vConn.Handler.WriteToUDP(outPacket, serverAddr)
 		if err != nil {
 			Logger.Debug("DRA: WriteToUDP failed with error:", err)
 			continue
@@ -623,10 +807,11 @@ func (pProc *Processor) DhcpRelayAgentSendDiscoverPacket(
 		pProc.StateMutex.Lock()
 		intfState.TotalDhcpServerTx++
 		clientState.ServerRequests++
-		clientState.ServerIp = draIntf.ServerIp[i]
+		clientState.ServerIp = serverIps[i]
 		intfServerState.Request++
+		pProc.serverRequestSent(intfServerState, serverTimeout)
 		pProc.StateMutex.Unlock()
-		Logger.Debug("DRA: Create & Send of PKT successfully to server", draIntf.ServerIp[i])
+		Logger.Debug("DRA: Create & Send of PKT successfully to server", serverIps[i])
 	}
 	if !txOk {
 		pProc.StateMutex.Lock() // State obj lock
@@ -648,6 +833,14 @@ func (pProc *Processor) DhcpRelayAgentSendPacketToDhcpServer(
 			inIntfProp, inReq, reqOptions, mt, intfState, clientState)
 		break
 	case DhcpRequest, DhcpDecline, DhcpRelease, DhcpInform:
+		if pProc.isServerIdOverridden(inIntfProp, reqOptions) {
+			// Client addressed the relay agent given to it by Server
+			// Identifier Override, the request goes to the servers of the
+			// interface
+			pProc.DhcpRelayAgentSendDiscoverPacket(
+				inIntfProp, inReq, reqOptions, mt, intfState, clientState)
+			break
+		}
 		pProc.DhcpRelayAgentSendClientOptPacket(
 			inIntfProp, inReq, reqOptions, mt, intfState, clientState)
 		break
@@ -670,9 +863,47 @@ func (pProc *Processor) validateRxUpstream(
 	return ipv4Intf, true
 }
 
-func (pProc *Processor) DhcpRelayAgentSendPacket(inIfIdx int, inIfName string,
-	inReq DhcpRelayAgentPacket, srcAddr net.IP, reqOptions DhcpRelayAgentOptions,
-	mType MessageType) {
+func (pProc *Processor) getHopCountLimit(vrf string) byte {
+	hopCountLimit, ok := pProc.InfraMgr.GetDRAv4HopCountLimit(vrf)
+	if !ok || hopCountLimit <= 0 || hopCountLimit > DHCP_HOP_COUNT_MAX {
+		return DHCP_HOP_COUNT_LIMIT
+	}
+	return byte(hopCountLimit)
+}
+
+// Client requests relayed by another relay agent keep their GIAddr and carry
+// the hops incremented by every relay agent on the way
+func (pProc *Processor) validateRelayedPkt(
+	inIntfProp *infra.IPv4IntfProperty, inReq DhcpRelayAgentPacket,
+	intfState *dhcprelayd.DHCPRelayIntfState) bool {
+
+	hopCountLimit := pProc.getHopCountLimit(
+		pProc.InfraMgr.GetDRAv4IntfVrf(inIntfProp.IfRef))
+
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	if inReq.GetHops() >= hopCountLimit {
+		Logger.Debug("DRA: Dropping request for HOST",
+			inReq.GetCHAddr().String(), "hops", inReq.GetHops(),
+			"exceeded hop count limit")
+		intfState.HopCountDrops++
+		intfState.TotalDrops++
+		return false
+	}
+	if inReq.GetGIAddr().Equal(net.ParseIP(inIntfProp.IpAddr)) {
+		Logger.Debug("DRA: Dropping looped request for HOST",
+			inReq.GetCHAddr().String(), "on", inIntfProp.IfRef)
+		intfState.LoopDrops++
+		intfState.TotalDrops++
+		return false
+	}
+	return true
+}
+
+func (pProc *Processor) DhcpRelayAgentSendPacket(vrf string, inIfIdx int,
+	inIfName string, inReq DhcpRelayAgentPacket, srcAddr net.IP,
+	reqOptions DhcpRelayAgentOptions, mType MessageType) {
 
 	switch mType {
 	case DhcpDiscover, DhcpRequest, DhcpDecline, DhcpRelease, DhcpInform:
@@ -682,34 +913,53 @@ func (pProc *Processor) DhcpRelayAgentSendPacket(inIfIdx int, inIfName string,
 			return
 		}
 		clientMacAddr := inReq.GetCHAddr().String()
-		intfState := pProc.initIntfState(inIfName)
-		clientState := pProc.initClientState(clientMacAddr)
-		pProc.PeerAddrIntfMap[clientMacAddr] = inIfName
+		if !pProc.checkRateLimit(inIfProp, clientMacAddr, vrf) {
+			return
+		}
+		intfState := pProc.initIntfState(inIfName, vrf)
+		clientState := pProc.initClientState(clientMacAddr, vrf)
+		if !pProc.validateRelayedPkt(inIfProp, inReq, intfState) {
+			return
+		}
+		if !pProc.processRelayAgentInfo(inIfProp, inReq, reqOptions,
+			intfState) {
+			return
+		}
+		pProc.learnBinding(inIfProp, inReq, mType)
+		pProc.updateSnoopingBinding(inReq, reqOptions, mType, inIfName)
 		// Send Packet
 		pProc.DhcpRelayAgentSendPacketToDhcpServer(
 			inIfProp, inReq, reqOptions, mType, intfState, clientState)
 	case DhcpOffer, DhcpACK, DhcpNAK:
-		// Get the interface from reverse mapping to send the unicast
+		if !pProc.validateRxDownstream(vrf, inIfName, inReq, mType,
+			pProc.initIntfState(inIfName, vrf)) {
+			return
+		}
+		// Get the interface from client binding to send the unicast
 		// packet...
-		outIfName, ok := pProc.PeerAddrIntfMap[inReq.GetCHAddr().String()]
+		outIfName, ok := pProc.updateBinding(inReq, reqOptions, mType)
 		if !ok {
-			Logger.Err("DRA: cache for linux interface for " +
-				inReq.GetCHAddr().String() + " not present")
+			Logger.Err("DRA: binding for " + inReq.GetCHAddr().String() +
+				" xid " + getXIdString(inReq.GetXId()) + " not present")
 			return
 		}
 		clientMacAddr := inReq.GetCHAddr().String()
-		intfState := pProc.initIntfState(inIfName)
-		clientState := pProc.initClientState(clientMacAddr)
+		intfState := pProc.initIntfState(inIfName, vrf)
+		clientState := pProc.initClientState(clientMacAddr,
+			pProc.InfraMgr.GetDRAv4IntfVrf(outIfName))
 		pProc.setDownstreamInState(mType, inReq, clientState, intfState)
+		serverIp := pProc.getRespondingServer(outIfName, srcAddr, reqOptions)
+		pProc.serverResponseRcvd(outIfName, serverIp)
+		pProc.updateSnoopingBinding(inReq, reqOptions, mType, outIfName)
 		pProc.DhcpRelayAgentSendPacketToDhcpClient(inReq,
-			outIfName, reqOptions, mType, srcAddr)
+			outIfName, reqOptions, mType, serverIp)
 	default:
 		Logger.Debug("DRA: any new message type")
 	}
 	return
 }
 
-func (pProc *Processor) MakeSendTxPkt(
+func (pProc *Processor) MakeSendTxPkt(vrf string,
 	inIfIdx int, inIfName string, inPktBuf []byte, srcAddr net.IP) bool {
 
 	// var buf []byte = make([]byte, 1500)
@@ -721,16 +971,16 @@ func (pProc *Processor) MakeSendTxPkt(
 		return false
 	}
 	inReq, reqOptions, mType := DhcpRelayAgentDecodeInPkt(inPktBuf, bytesRead)
-	pProc.DhcpRelayAgentSendPacket(inIfIdx, inIfName, inReq, srcAddr, reqOptions,
-		mType)
+	pProc.DhcpRelayAgentSendPacket(vrf, inIfIdx, inIfName, inReq, srcAddr,
+		reqOptions, mType)
 	return true
 }
 
-func (pProc *Processor) RxTx() {
+func (pProc *Processor) RxTx(vrf string, vConn *vrfConn) {
 	buf := make([]byte, 1500)
 	for {
-		Logger.Debug("DRA: Calling ReadFrom")
-		bytesRead, cm, srcAddr, err := pProc.ClientConn.ReadFrom(buf)
+		Logger.Debug("DRA: Calling ReadFrom in Vrf", vrf)
+		bytesRead, cm, srcAddr, err := vConn.Conn.ReadFrom(buf)
 		if err != nil {
 			Logger.Err("DRA: reading buffer failed")
 			break
@@ -746,11 +996,11 @@ func (pProc *Processor) RxTx() {
 			continue
 		}
 		srcUdpAddr, _ := net.ResolveUDPAddr("udp4", srcAddr.String())
-		pProc.MakeSendTxPkt(ifIdx, inIf.Name, buf[:bytesRead], srcUdpAddr.IP)
+		pProc.MakeSendTxPkt(vrf, ifIdx, inIf.Name, buf[:bytesRead],
+			srcUdpAddr.IP)
 	}
-	pProc.EnabledMutex.Lock()
-	pProc.EnabledFlag = false
-	pProc.EnabledMutex.Unlock()
+	// Reopened on the next StartRxTx
+	pProc.VrfConns.Release(vrf, vConn)
 }
 
 func (pProc *Processor) openPcapHandler(ifIdx int) {
@@ -787,11 +1037,27 @@ func (pProc *Processor) closePcapHandler(ifIdx int) {
 }
 
 func (pProc *Processor) ProcessCreateDRAIntf(ifName string) {
-	pProc.initIntfState(ifName)
+	intfState := pProc.initIntfState(ifName,
+		pProc.InfraMgr.GetDRAv4IntfVrf(ifName))
+	if draIntf, ok := pProc.InfraMgr.GetDRAv4Intf(ifName); ok {
+		pProc.StateMutex.Lock()
+		intfState.ServerSelection = getServerSelection(draIntf)
+		pProc.StateMutex.Unlock()
+	}
+}
+
+func (pProc *Processor) ProcessUpdateDRAIntf(ifName string) {
+	pProc.ProcessCreateDRAIntf(ifName)
+	if draIntf, ok := pProc.InfraMgr.GetDRAv4Intf(ifName); ok {
+		pProc.updateIntfServerLiveness(ifName, draIntf)
+	}
 }
 
 func (pProc *Processor) ProcessDeleteDRAIntf(ifName string) {
 	pProc.deleteIntfState(ifName)
+	pProc.deleteIntfBindings(ifName)
+	pProc.deleteIntfServerLiveness(ifName)
+	pProc.RateLimiter.DeleteIntf(ifName)
 }
 
 func (pProc *Processor) ProcessActiveDRAIntf(ifIdx int) {
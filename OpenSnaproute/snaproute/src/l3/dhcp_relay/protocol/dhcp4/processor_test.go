@@ -0,0 +1,410 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package dhcp4
+
+import (
+	"bytes"
+	"dhcprelayd"
+	"errors"
+	"fmt"
+	"infra/sysd/sysdCommonDefs"
+	"l3/dhcp_relay/infra"
+	"log/syslog"
+	"net"
+	"testing"
+	asicdmock "utils/asicdClient/mock"
+	"utils/logging"
+)
+
+const (
+	testSwitchMac = "00:11:22:33:44:55"
+)
+
+func NewLogger(name string, tag string) (*logging.Writer, error) {
+	var err error
+	srLogger := new(logging.Writer)
+	srLogger.MyComponentName = name
+
+	srLogger.SysLogger, err = syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		fmt.Println("Failed to initialize syslog - ", err)
+		return srLogger, err
+	}
+	srLogger.MyLogLevel = sysdCommonDefs.INFO
+	return srLogger, err
+}
+
+// Processor with a single active relay interface eth0 (10.0.0.1/24) in the
+// default VRF
+func initTestProcessor(t *testing.T,
+	draIntf *dhcprelayd.DHCPRelayIntf) (*Processor, *infra.IPv4IntfProperty) {
+
+	lgr, err := NewLogger("dhcprelayd", "dhcprelayd")
+	if err != nil {
+		t.Fatal("Failed to initialize logger", err)
+	}
+	iMgr := infra.NewInfraMgr(lgr, &asicdmock.MockAsicdClientMgr{})
+	iMgr.SetSwitchMac(testSwitchMac)
+	ipv4Intf := &infra.IPv4IntfProperty{
+		IpAddr:  "10.0.0.1",
+		Netmask: net.CIDRMask(24, 32),
+		IfIndex: 1,
+		IfRef:   "eth0",
+		State:   true,
+	}
+	iMgr.IPv4IntfProps[1] = ipv4Intf
+	iMgr.IPv4IfRefToIfIndex["eth0"] = 1
+	iMgr.DRAv4Globals["default"] = &dhcprelayd.DHCPRelayGlobal{
+		Vrf:           "default",
+		Enable:        true,
+		HopCountLimit: 4,
+	}
+	draIntf.IntfRef = "eth0"
+	iMgr.DRAv4Intfs["eth0"] = draIntf
+	iMgr.ActiveDRAv4Intfs[1] = draIntf
+	pProc := NewProcessor(&ProcessorInitParams{
+		Logger:   lgr,
+		InfraMgr: iMgr,
+	})
+	return pProc, ipv4Intf
+}
+
+func newTestRequest(giAddr string, hops byte) DhcpRelayAgentPacket {
+	var inReq DhcpRelayAgentPacket = make([]byte, DHCP_PACKET_MIN_BYTES+1)
+	inReq.SetOpCode(Request)
+	inReq.SetHeaderType(1)
+	inReq.SetHops(hops)
+	inReq.SetXId([]byte{0x01, 0x02, 0x03, 0x04})
+	inReq.SetCookie([]byte{99, 130, 83, 99})
+	mac, _ := net.ParseMAC("00:aa:bb:cc:dd:ee")
+	inReq.SetCHAddr(mac)
+	inReq.SetGIAddr(net.ParseIP(giAddr))
+	inReq[DHCP_PACKET_MIN_BYTES] = byte(End)
+	return inReq
+}
+
+func TestDhcpRelayAgentInfoOption(t *testing.T) {
+	value := DhcpRelayAgentInfoOption([]byte("eth0"), []byte{0x00, 0x11})
+	expected := []byte{
+		byte(RelayAgentCircuitId), 4, 'e', 't', 'h', '0',
+		byte(RelayAgentRemoteId), 2, 0x00, 0x11,
+	}
+	if !bytes.Equal(value, expected) {
+		t.Error("Wrong Relay Agent Information encoding", value)
+	}
+	value = DhcpRelayAgentInfoOption([]byte("eth0"), nil)
+	if !bytes.Equal(value, expected[:6]) {
+		t.Error("Wrong Relay Agent Information encoding without Remote-ID", value)
+	}
+	// Relay Agent Information is always the last option of the packet
+	outPacket := newTestRequest(DHCP_NO_IP, 0)
+	reqOptions := make(DhcpRelayAgentOptions)
+	reqOptions[OptionRelayAgentInformation] = expected
+	reqOptions[OptionHostName] = []byte("host1")
+	DhcpRelayAgentAddOptionsToPacket(reqOptions, DhcpDiscover, &outPacket)
+	options := outPacket.ParseDhcpOptions()
+	if !bytes.Equal(options[OptionRelayAgentInformation], expected) {
+		t.Error("Relay Agent Information not carried in packet")
+	}
+	optionsEnd := len(outPacket) - len(expected) - 3
+	if outPacket[optionsEnd] != byte(OptionRelayAgentInformation) {
+		t.Error("Relay Agent Information is not the last option")
+	}
+}
+
+func TestRelayAgentInfoInsert(t *testing.T) {
+	pProc, ipv4Intf := initTestProcessor(t, &dhcprelayd.DHCPRelayIntf{
+		Enable:         true,
+		RelayAgentInfo: true,
+		CircuitIdType:  CIRCUIT_ID_INTF_NAME,
+	})
+	intfState := pProc.initIntfState("eth0", "default")
+	reqOptions := make(DhcpRelayAgentOptions)
+	if !pProc.processRelayAgentInfo(ipv4Intf, newTestRequest(DHCP_NO_IP, 0),
+		reqOptions, intfState) {
+		t.Fatal("Request without Relay Agent Information dropped")
+	}
+	mac, _ := net.ParseMAC(testSwitchMac)
+	expected := DhcpRelayAgentInfoOption([]byte("eth0"), []byte(mac))
+	if !bytes.Equal(reqOptions[OptionRelayAgentInformation], expected) {
+		t.Error("Remote-ID is not the switch mac address",
+			reqOptions[OptionRelayAgentInformation])
+	}
+	if intfState.RelayAgentInfoInserted != 1 {
+		t.Error("Wrong RelayAgentInfoInserted", intfState.RelayAgentInfoInserted)
+	}
+}
+
+func TestRelayAgentInfoPolicy(t *testing.T) {
+	clientInfo := DhcpRelayAgentInfoOption([]byte("port1"), []byte("client"))
+	ownInfo := DhcpRelayAgentInfoOption([]byte("eth0"), []byte("tor1"))
+	for _, tc := range []struct {
+		policy   string
+		accepted bool
+		expected []byte
+	}{
+		{RELAY_AGENT_INFO_KEEP, true, clientInfo},
+		{RELAY_AGENT_INFO_REPLACE, true, ownInfo},
+		{RELAY_AGENT_INFO_DROP, false, clientInfo},
+	} {
+		pProc, ipv4Intf := initTestProcessor(t, &dhcprelayd.DHCPRelayIntf{
+			Enable:               true,
+			RelayAgentInfo:       true,
+			RemoteId:             "tor1",
+			RelayAgentInfoPolicy: tc.policy,
+		})
+		intfState := pProc.initIntfState("eth0", "default")
+		reqOptions := make(DhcpRelayAgentOptions)
+		reqOptions[OptionRelayAgentInformation] = clientInfo
+		accepted := pProc.processRelayAgentInfo(ipv4Intf,
+			newTestRequest(DHCP_NO_IP, 0), reqOptions, intfState)
+		if accepted != tc.accepted {
+			t.Error("Policy", tc.policy, "accepted", accepted)
+		}
+		if !bytes.Equal(reqOptions[OptionRelayAgentInformation], tc.expected) {
+			t.Error("Policy", tc.policy, "wrong Relay Agent Information",
+				reqOptions[OptionRelayAgentInformation])
+		}
+		switch tc.policy {
+		case RELAY_AGENT_INFO_KEEP:
+			if intfState.RelayAgentInfoKept != 1 {
+				t.Error("Wrong RelayAgentInfoKept", intfState.RelayAgentInfoKept)
+			}
+		case RELAY_AGENT_INFO_REPLACE:
+			if intfState.RelayAgentInfoReplaced != 1 {
+				t.Error("Wrong RelayAgentInfoReplaced",
+					intfState.RelayAgentInfoReplaced)
+			}
+		case RELAY_AGENT_INFO_DROP:
+			if intfState.RelayAgentInfoDropped != 1 || intfState.TotalDrops != 1 {
+				t.Error("Wrong RelayAgentInfoDropped",
+					intfState.RelayAgentInfoDropped)
+			}
+		}
+	}
+}
+
+func TestRelayAgentInfoRelayedRequest(t *testing.T) {
+	draIntf := &dhcprelayd.DHCPRelayIntf{
+		Enable:               true,
+		RelayAgentInfo:       true,
+		RemoteId:             "tor1",
+		RelayAgentInfoPolicy: RELAY_AGENT_INFO_REPLACE,
+	}
+	pProc, ipv4Intf := initTestProcessor(t, draIntf)
+	intfState := pProc.initIntfState("eth0", "default")
+	relayInfo := DhcpRelayAgentInfoOption([]byte("relay1"), nil)
+	reqOptions := make(DhcpRelayAgentOptions)
+	reqOptions[OptionRelayAgentInformation] = relayInfo
+	// Requests from a downstream relay agent are forwarded unmodified
+	inReq := newTestRequest("10.0.0.2", 1)
+	if !pProc.processRelayAgentInfo(ipv4Intf, inReq, reqOptions, intfState) {
+		t.Fatal("Relayed request dropped")
+	}
+	if !bytes.Equal(reqOptions[OptionRelayAgentInformation], relayInfo) {
+		t.Error("Relay Agent Information of relayed request modified")
+	}
+	delete(reqOptions, OptionRelayAgentInformation)
+	pProc.processRelayAgentInfo(ipv4Intf, inReq, reqOptions, intfState)
+	if _, ok := reqOptions[OptionRelayAgentInformation]; ok {
+		t.Error("Relay Agent Information inserted in relayed request")
+	}
+	// Trusted interfaces apply the policy on relayed requests
+	draIntf.RelayAgentInfoTrusted = true
+	reqOptions[OptionRelayAgentInformation] = relayInfo
+	pProc.processRelayAgentInfo(ipv4Intf, inReq, reqOptions, intfState)
+	ownInfo := DhcpRelayAgentInfoOption([]byte("eth0"), []byte("tor1"))
+	if !bytes.Equal(reqOptions[OptionRelayAgentInformation], ownInfo) {
+		t.Error("Relay Agent Information not replaced on trusted interface")
+	}
+	if intfState.RelayAgentInfoReplaced != 1 {
+		t.Error("Wrong RelayAgentInfoReplaced", intfState.RelayAgentInfoReplaced)
+	}
+}
+
+func TestRelayAgentInfoStrip(t *testing.T) {
+	draIntf := &dhcprelayd.DHCPRelayIntf{
+		Enable:               true,
+		RelayAgentInfo:       true,
+		RemoteId:             "tor1",
+		RelayAgentInfoPolicy: RELAY_AGENT_INFO_KEEP,
+	}
+	pProc, ipv4Intf := initTestProcessor(t, draIntf)
+	ownInfo := DhcpRelayAgentInfoOption([]byte("eth0"), []byte("tor1"))
+	clientInfo := DhcpRelayAgentInfoOption([]byte("port1"), []byte("client"))
+	replyOptions := make(DhcpRelayAgentOptions)
+	replyOptions[OptionRelayAgentInformation] = ownInfo
+	if !pProc.isOwnRelayAgentInfo(ipv4Intf, replyOptions) {
+		t.Error("Inserted Relay Agent Information is not stripped")
+	}
+	// Kept Relay Agent Information is forwarded as received
+	replyOptions[OptionRelayAgentInformation] = clientInfo
+	if pProc.isOwnRelayAgentInfo(ipv4Intf, replyOptions) {
+		t.Error("Kept Relay Agent Information is stripped")
+	}
+	// Nothing is stripped with Relay Agent Information disabled
+	draIntf.RelayAgentInfo = false
+	replyOptions[OptionRelayAgentInformation] = ownInfo
+	if pProc.isOwnRelayAgentInfo(ipv4Intf, replyOptions) {
+		t.Error("Relay Agent Information stripped with the feature disabled")
+	}
+}
+
+func TestRelayedPacketHops(t *testing.T) {
+	_, ipv4Intf := initTestProcessor(t, &dhcprelayd.DHCPRelayIntf{
+		Enable: true,
+	})
+	giAddr := net.ParseIP(ipv4Intf.IpAddr)
+	outPacket := createRelayedPacket(giAddr, newTestRequest(DHCP_NO_IP, 0))
+	if outPacket.GetHops() != 1 {
+		t.Error("Wrong hops", outPacket.GetHops())
+	}
+	if !outPacket.GetGIAddr().Equal(net.ParseIP(ipv4Intf.IpAddr)) {
+		t.Error("GIAddr not set to the interface address", outPacket.GetGIAddr())
+	}
+	// GIAddr of a downstream relay agent is kept
+	outPacket = createRelayedPacket(giAddr, newTestRequest("10.0.0.2", 2))
+	if outPacket.GetHops() != 3 {
+		t.Error("Wrong hops", outPacket.GetHops())
+	}
+	if !outPacket.GetGIAddr().Equal(net.ParseIP("10.0.0.2")) {
+		t.Error("GIAddr of downstream relay agent not kept", outPacket.GetGIAddr())
+	}
+}
+
+// Requests relayed to the servers of another VRF
+func TestCrossVrfRelay(t *testing.T) {
+	pProc, ipv4Intf := initTestProcessor(t, &dhcprelayd.DHCPRelayIntf{
+		Enable:               true,
+		RelayAgentInfoPolicy: RELAY_AGENT_INFO_KEEP,
+	})
+	giAddr, ok := pProc.getGIAddr(ipv4Intf, "20.0.0.1")
+	if !ok || !giAddr.Equal(net.ParseIP(ipv4Intf.IpAddr)) {
+		t.Fatal("GIAddr is not the interface address", giAddr)
+	}
+	pProc.InfraMgr.DRAv4Globals["default"].ServerVrf = "mgmt"
+	pProc.ServerVrfAddr = func(vrf string,
+		dst *net.UDPAddr) (net.IP, error) {
+
+		if vrf != "mgmt" || !dst.IP.Equal(net.ParseIP("20.0.0.1")) ||
+			dst.Port != DHCP_SERVER_PORT {
+			return nil, errors.New("No route")
+		}
+		return net.ParseIP("192.168.0.1"), nil
+	}
+	giAddr, ok = pProc.getGIAddr(ipv4Intf, "20.0.0.1")
+	if !ok || !giAddr.Equal(net.ParseIP("192.168.0.1")) {
+		t.Error("GIAddr is not the address of the server VRF", giAddr)
+	}
+	if _, ok := pProc.getGIAddr(ipv4Intf, "20.0.0.2"); ok {
+		t.Error("GIAddr for server not reachable in server VRF")
+	}
+	// Link Selection replaces the client information even with Relay Agent
+	// Information disabled
+	intfState := pProc.initIntfState("eth0", "default")
+	reqOptions := make(DhcpRelayAgentOptions)
+	reqOptions[OptionRelayAgentInformation] =
+		DhcpRelayAgentInfoOption([]byte("port1"), nil)
+	if !pProc.processRelayAgentInfo(ipv4Intf, newTestRequest(DHCP_NO_IP, 0),
+		reqOptions, intfState) {
+		t.Fatal("Request relayed across VRFs dropped")
+	}
+	expected := []byte{
+		byte(RelayAgentLinkSelection), 4, 10, 0, 0, 1,
+		byte(RelayAgentServerIdOverride), 4, 10, 0, 0, 1,
+	}
+	if !bytes.Equal(reqOptions[OptionRelayAgentInformation], expected) {
+		t.Error("Wrong Link Selection sub-options",
+			reqOptions[OptionRelayAgentInformation])
+	}
+	if intfState.RelayAgentInfoReplaced != 1 {
+		t.Error("Wrong RelayAgentInfoReplaced", intfState.RelayAgentInfoReplaced)
+	}
+	if !pProc.isOwnRelayAgentInfo(ipv4Intf, reqOptions) {
+		t.Error("Link Selection sub-options not stripped from replies")
+	}
+	// Renewals addressed to the relay agent go to the servers
+	reqOptions[OptionServerIdentifier] = net.ParseIP("10.0.0.1").To4()
+	if !pProc.isServerIdOverridden(ipv4Intf, reqOptions) {
+		t.Error("Request to the overridden Server Identifier not detected")
+	}
+	reqOptions[OptionServerIdentifier] = net.ParseIP("20.0.0.1").To4()
+	if pProc.isServerIdOverridden(ipv4Intf, reqOptions) {
+		t.Error("Request to the server taken as overridden")
+	}
+}
+
+func TestValidateRelayedPkt(t *testing.T) {
+	pProc, ipv4Intf := initTestProcessor(t, &dhcprelayd.DHCPRelayIntf{
+		Enable: true,
+	})
+	intfState := pProc.initIntfState("eth0", "default")
+	if !pProc.validateRelayedPkt(ipv4Intf, newTestRequest("10.0.0.2", 3),
+		intfState) {
+		t.Error("Request below the hop count limit dropped")
+	}
+	if pProc.validateRelayedPkt(ipv4Intf, newTestRequest("10.0.0.2", 4),
+		intfState) {
+		t.Error("Request at the hop count limit relayed")
+	}
+	if intfState.HopCountDrops != 1 || intfState.LoopDrops != 0 {
+		t.Error("Wrong HopCountDrops", intfState.HopCountDrops)
+	}
+	if pProc.validateRelayedPkt(ipv4Intf, newTestRequest(ipv4Intf.IpAddr, 1),
+		intfState) {
+		t.Error("Looped request relayed")
+	}
+	if intfState.LoopDrops != 1 || intfState.HopCountDrops != 1 {
+		t.Error("Wrong LoopDrops", intfState.LoopDrops)
+	}
+	if intfState.TotalDrops != 2 {
+		t.Error("Wrong TotalDrops", intfState.TotalDrops)
+	}
+}
+
+// Rate limited requests don't create client state
+func TestCheckRateLimit(t *testing.T) {
+	pProc, ipv4Intf := initTestProcessor(t, &dhcprelayd.DHCPRelayIntf{
+		Enable:          true,
+		ClientRateLimit: 1,
+	})
+	macAddr := "00:aa:bb:cc:dd:ee"
+	if !pProc.checkRateLimit(ipv4Intf, macAddr, "default") {
+		t.Fatal("First request of client dropped")
+	}
+	if _, ok := pProc.GetIntfState("eth0"); ok {
+		t.Error("Intf state created for allowed request")
+	}
+	if pProc.checkRateLimit(ipv4Intf, macAddr, "default") {
+		t.Fatal("Client rate limit not applied")
+	}
+	intfState, ok := pProc.GetIntfState("eth0")
+	if !ok || intfState.ClientRateLimitDrops != 1 ||
+		intfState.TotalDrops != 1 {
+		t.Error("Wrong drop counters", intfState)
+	}
+	if _, ok := pProc.GetClientState(macAddr); ok {
+		t.Error("Client state created for rate limited request")
+	}
+}
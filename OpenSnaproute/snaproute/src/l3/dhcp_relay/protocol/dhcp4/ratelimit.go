@@ -0,0 +1,99 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package dhcp4
+
+import (
+	"l3/dhcp_relay/infra"
+	"models/events"
+	"time"
+	"utils/eventUtils"
+)
+
+type rateLimitEventData struct {
+	IntfRef         string
+	MacAddr         string
+	Vrf             string
+	ClientRateLimit int32
+}
+
+// Returns false if the client request exceeds the configured rate limits.
+// Limits are checked before any state is created for the request, the intf
+// state is only looked up to count the drop
+func (pProc *Processor) checkRateLimit(inIntfProp *infra.IPv4IntfProperty,
+	clientMacAddr string, vrf string) bool {
+
+	draIntf, ok := pProc.InfraMgr.GetActiveDRAv4Intf(inIntfProp.IfIndex)
+	if !ok {
+		return true
+	}
+	verdict, newlyLimited := pProc.RateLimiter.Apply(inIntfProp.IfRef,
+		clientMacAddr, draIntf.RateLimit, draIntf.ClientRateLimit,
+		time.Now())
+	if newlyLimited {
+		pProc.publishClientRateLimited(inIntfProp.IfRef, clientMacAddr,
+			infra.GetDRAv4IntfVrf(draIntf), draIntf.ClientRateLimit)
+	}
+	if verdict == infra.RATE_LIMIT_PASS {
+		return true
+	}
+	Logger.Debug("DRA: Rate limit exceeded, dropping packet from",
+		clientMacAddr, "on", inIntfProp.IfRef)
+	intfState := pProc.initIntfState(inIntfProp.IfRef, vrf)
+	pProc.StateMutex.Lock()
+	if verdict == infra.RATE_LIMIT_CLIENT_DROP {
+		intfState.ClientRateLimitDrops++
+	} else {
+		intfState.RateLimitDrops++
+	}
+	intfState.TotalDrops++
+	pProc.StateMutex.Unlock()
+	return false
+}
+
+func (pProc *Processor) publishClientRateLimited(ifName string,
+	macAddr string, vrf string, clientRate int32) {
+
+	Logger.Info("DRA: Client", macAddr, "on", ifName,
+		"exceeded rate limit of", clientRate, "packets per second")
+	evtKey := events.DHCPRelayClientKey{
+		IntfRef: ifName,
+		MacAddr: macAddr,
+	}
+	evtData := rateLimitEventData{
+		IntfRef:         ifName,
+		MacAddr:         macAddr,
+		Vrf:             vrf,
+		ClientRateLimit: clientRate,
+	}
+	txEvent := eventUtils.TxEvent{
+		EventId:        events.DHCPRelayClientRateLimited,
+		Key:            evtKey,
+		AdditionalInfo: "",
+		AdditionalData: evtData,
+	}
+	err := eventUtils.PublishEvents(&txEvent)
+	if err != nil {
+		Logger.Err("DRA: Error in publishing DHCPRelayClientRateLimited Event")
+	}
+}
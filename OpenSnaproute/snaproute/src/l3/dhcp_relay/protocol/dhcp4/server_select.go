@@ -0,0 +1,247 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package dhcp4
+
+import (
+	"dhcprelayd"
+	"hash/fnv"
+	"l3/dhcp_relay/infra"
+	"net"
+	"time"
+)
+
+// Liveness of a server on an interface, a server is down when its oldest
+// unanswered request is older than the configured response timeout
+type serverLiveness struct {
+	ifName       string
+	serverIp     string
+	pendingSince time.Time
+	downSince    time.Time
+	timeout      time.Duration
+}
+
+func getServerSelection(draIntf *dhcprelayd.DHCPRelayIntf) string {
+	switch draIntf.ServerSelection {
+	case SERVER_SELECTION_PRIMARY_BACKUP, SERVER_SELECTION_ROUND_ROBIN,
+		SERVER_SELECTION_HASH_BY_MAC:
+		return draIntf.ServerSelection
+	default:
+		return SERVER_SELECTION_BROADCAST_ALL
+	}
+}
+
+func getServerTimeout(draIntf *dhcprelayd.DHCPRelayIntf) time.Duration {
+	if draIntf.ServerTimeout <= 0 {
+		return SERVER_RESPONSE_TIMEOUT
+	}
+	return time.Duration(draIntf.ServerTimeout) * time.Second
+}
+
+// Caller needs to hold StateMutex
+func (pProc *Processor) isServerAlive(intfServerStateKey string,
+	now time.Time) bool {
+
+	liveness, ok := pProc.ServerLiveness[intfServerStateKey]
+	alive := true
+	if ok && !liveness.pendingSince.IsZero() &&
+		now.Sub(liveness.pendingSince) >= liveness.timeout {
+		if liveness.downSince.IsZero() {
+			Logger.Info("DRA: Server", intfServerStateKey, "is down")
+			liveness.downSince = now
+		}
+		alive = false
+		// Down servers are tried again once the retry interval is over
+		if now.Sub(liveness.downSince) >= SERVER_RETRY_INTERVAL {
+			liveness.pendingSince = time.Time{}
+			liveness.downSince = time.Time{}
+			alive = true
+		}
+	}
+	if intfServerState, ok := pProc.IntfServerStateMap[intfServerStateKey]; ok {
+		if alive {
+			intfServerState.Health = SERVER_HEALTH_UP
+		} else {
+			intfServerState.Health = SERVER_HEALTH_DOWN
+		}
+	}
+	return alive
+}
+
+// Returns the servers a client discover needs to be relayed to as per the
+// server selection policy of the interface
+func (pProc *Processor) selectServers(inIntfProp *infra.IPv4IntfProperty,
+	draIntf *dhcprelayd.DHCPRelayIntf, inReq DhcpRelayAgentPacket,
+	intfState *dhcprelayd.DHCPRelayIntfState) []string {
+
+	serverSelection := getServerSelection(draIntf)
+
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	intfState.ServerSelection = serverSelection
+	if serverSelection == SERVER_SELECTION_BROADCAST_ALL ||
+		len(draIntf.ServerIp) <= 1 {
+		return draIntf.ServerIp
+	}
+	now := time.Now()
+	alive := []int{}
+	for i, serverIp := range draIntf.ServerIp {
+		if pProc.isServerAlive(inIntfProp.IfRef+"_"+serverIp, now) {
+			alive = append(alive, i)
+		}
+	}
+	if len(alive) == 0 {
+		// Flood when no server is known to be alive
+		return draIntf.ServerIp
+	}
+	var selected int
+	switch serverSelection {
+	case SERVER_SELECTION_PRIMARY_BACKUP:
+		selected = alive[0]
+	case SERVER_SELECTION_ROUND_ROBIN:
+		idx := pProc.RoundRobinIdx[inIntfProp.IfRef] % len(alive)
+		pProc.RoundRobinIdx[inIntfProp.IfRef] = idx + 1
+		selected = alive[idx]
+	case SERVER_SELECTION_HASH_BY_MAC:
+		hash := fnv.New32a()
+		hash.Write(inReq.GetCHAddr())
+		start := int(hash.Sum32() % uint32(len(draIntf.ServerIp)))
+		// Move on to the next alive server if the hashed one is down
+		selected = alive[0]
+		for _, idx := range alive {
+			if idx >= start {
+				selected = idx
+				break
+			}
+		}
+	}
+	return []string{draIntf.ServerIp[selected]}
+}
+
+// Caller needs to hold StateMutex
+func (pProc *Processor) serverRequestSent(
+	intfServerState *dhcprelayd.DHCPRelayIntfServerState,
+	timeout time.Duration) {
+
+	intfServerStateKey := intfServerState.IntfRef + "_" +
+		intfServerState.ServerIp
+	liveness, ok := pProc.ServerLiveness[intfServerStateKey]
+	if !ok {
+		liveness = &serverLiveness{
+			ifName:   intfServerState.IntfRef,
+			serverIp: intfServerState.ServerIp,
+		}
+		pProc.ServerLiveness[intfServerStateKey] = liveness
+	}
+	liveness.timeout = timeout
+	if liveness.pendingSince.IsZero() {
+		liveness.pendingSince = time.Now()
+	}
+}
+
+// Replies of multi-homed servers may be sourced from an address other than
+// the configured one, hence the Server Identifier carried in the reply is
+// matched against the configured servers before the source address
+func (pProc *Processor) getRespondingServer(ifName string, srcAddr net.IP,
+	reqOptions DhcpRelayAgentOptions) string {
+
+	draIntf, ok := pProc.InfraMgr.GetDRAv4Intf(ifName)
+	if !ok {
+		return srcAddr.String()
+	}
+	candidates := []net.IP{}
+	if serverId, ok := reqOptions[OptionServerIdentifier]; ok &&
+		len(serverId) == net.IPv4len {
+		candidates = append(candidates, net.IP(serverId))
+	}
+	candidates = append(candidates, srcAddr)
+	for _, candidate := range candidates {
+		for _, serverIp := range draIntf.ServerIp {
+			if net.ParseIP(serverIp).Equal(candidate) {
+				return serverIp
+			}
+		}
+	}
+	return srcAddr.String()
+}
+
+func (pProc *Processor) serverResponseRcvd(ifName string, serverIp string) {
+	intfServerState := pProc.initIntfServerState(ifName, serverIp)
+
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	intfServerStateKey := ifName + "_" + serverIp
+	if liveness, ok := pProc.ServerLiveness[intfServerStateKey]; ok {
+		if !liveness.downSince.IsZero() {
+			Logger.Info("DRA: Server", intfServerStateKey, "is up")
+		}
+		liveness.pendingSince = time.Time{}
+		liveness.downSince = time.Time{}
+	}
+	intfServerState.Health = SERVER_HEALTH_UP
+	intfServerState.LastResponse = time.Now().String()
+}
+
+func (pProc *Processor) updateServerHealth() {
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	now := time.Now()
+	for intfServerStateKey := range pProc.ServerLiveness {
+		pProc.isServerAlive(intfServerStateKey, now)
+	}
+}
+
+// Liveness of servers which are no longer configured on the interface is
+// forgotten and round robin starts over
+func (pProc *Processor) updateIntfServerLiveness(ifName string,
+	draIntf *dhcprelayd.DHCPRelayIntf) {
+
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	configured := make(map[string]bool)
+	for _, serverIp := range draIntf.ServerIp {
+		configured[serverIp] = true
+	}
+	for intfServerStateKey, liveness := range pProc.ServerLiveness {
+		if liveness.ifName == ifName && !configured[liveness.serverIp] {
+			delete(pProc.ServerLiveness, intfServerStateKey)
+		}
+	}
+	delete(pProc.RoundRobinIdx, ifName)
+}
+
+func (pProc *Processor) deleteIntfServerLiveness(ifName string) {
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	for intfServerStateKey, liveness := range pProc.ServerLiveness {
+		if liveness.ifName == ifName {
+			delete(pProc.ServerLiveness, intfServerStateKey)
+		}
+	}
+	delete(pProc.RoundRobinIdx, ifName)
+}
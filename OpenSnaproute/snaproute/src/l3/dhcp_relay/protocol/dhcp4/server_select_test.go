@@ -0,0 +1,209 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package dhcp4
+
+import (
+	"dhcprelayd"
+	"l3/dhcp_relay/infra"
+	"net"
+	"testing"
+	"time"
+)
+
+var testServers = []string{"20.0.0.1", "20.0.0.2", "20.0.0.3"}
+
+func initTestServerSelection(t *testing.T, serverSelection string) (
+	*Processor, *infra.IPv4IntfProperty, *dhcprelayd.DHCPRelayIntf) {
+
+	draIntf := &dhcprelayd.DHCPRelayIntf{
+		Enable:          true,
+		ServerIp:        testServers,
+		ServerSelection: serverSelection,
+		ServerTimeout:   5,
+	}
+	pProc, ipv4Intf := initTestProcessor(t, draIntf)
+	pProc.ProcessCreateDRAIntf("eth0")
+	return pProc, ipv4Intf, draIntf
+}
+
+// Marks the server down as if its oldest request went unanswered for longer
+// than the response timeout
+func expireServer(pProc *Processor, serverIp string) {
+	intfServerState := pProc.initIntfServerState("eth0", serverIp)
+	pProc.StateMutex.Lock()
+	pProc.serverRequestSent(intfServerState, 5*time.Second)
+	pProc.ServerLiveness["eth0_"+serverIp].pendingSince =
+		time.Now().Add(-10 * time.Second)
+	pProc.StateMutex.Unlock()
+}
+
+func TestSelectServersBroadcastAll(t *testing.T) {
+	pProc, ipv4Intf, draIntf := initTestServerSelection(t,
+		SERVER_SELECTION_BROADCAST_ALL)
+	intfState := pProc.initIntfState("eth0", "default")
+	expireServer(pProc, "20.0.0.1")
+	selected := pProc.selectServers(ipv4Intf, draIntf,
+		newTestClientPkt("00:00:00:00:00:01", 1), intfState)
+	if len(selected) != len(testServers) {
+		t.Error("Discover not relayed to all servers", selected)
+	}
+}
+
+func TestSelectServersPrimaryBackup(t *testing.T) {
+	pProc, ipv4Intf, draIntf := initTestServerSelection(t,
+		SERVER_SELECTION_PRIMARY_BACKUP)
+	intfState := pProc.initIntfState("eth0", "default")
+	inReq := newTestClientPkt("00:00:00:00:00:01", 1)
+	selected := pProc.selectServers(ipv4Intf, draIntf, inReq, intfState)
+	if len(selected) != 1 || selected[0] != "20.0.0.1" {
+		t.Error("Primary server not selected", selected)
+	}
+	// Fail over to the backup once the primary is down
+	expireServer(pProc, "20.0.0.1")
+	selected = pProc.selectServers(ipv4Intf, draIntf, inReq, intfState)
+	if len(selected) != 1 || selected[0] != "20.0.0.2" {
+		t.Error("Backup server not selected", selected)
+	}
+	intfServerState, _ := pProc.GetIntfServerState("eth0", "20.0.0.1")
+	if intfServerState.Health != SERVER_HEALTH_DOWN {
+		t.Error("Primary server not reported down", intfServerState.Health)
+	}
+	// Primary is used again once it responds
+	pProc.serverResponseRcvd("eth0", "20.0.0.1")
+	selected = pProc.selectServers(ipv4Intf, draIntf, inReq, intfState)
+	if len(selected) != 1 || selected[0] != "20.0.0.1" {
+		t.Error("Primary server not selected after recovery", selected)
+	}
+	// Flood when every server is down
+	for _, serverIp := range testServers {
+		expireServer(pProc, serverIp)
+	}
+	selected = pProc.selectServers(ipv4Intf, draIntf, inReq, intfState)
+	if len(selected) != len(testServers) {
+		t.Error("Discover not flooded with all servers down", selected)
+	}
+}
+
+func TestSelectServersRoundRobin(t *testing.T) {
+	pProc, ipv4Intf, draIntf := initTestServerSelection(t,
+		SERVER_SELECTION_ROUND_ROBIN)
+	intfState := pProc.initIntfState("eth0", "default")
+	inReq := newTestClientPkt("00:00:00:00:00:01", 1)
+	for i := 0; i < 2*len(testServers); i++ {
+		selected := pProc.selectServers(ipv4Intf, draIntf, inReq, intfState)
+		if len(selected) != 1 || selected[0] != testServers[i%len(testServers)] {
+			t.Error("Wrong round robin server", i, selected)
+		}
+	}
+	expireServer(pProc, "20.0.0.2")
+	for i := 0; i < 4; i++ {
+		selected := pProc.selectServers(ipv4Intf, draIntf, inReq, intfState)
+		if len(selected) != 1 || selected[0] == "20.0.0.2" {
+			t.Error("Down server selected by round robin", selected)
+		}
+	}
+}
+
+func TestSelectServersHashByMac(t *testing.T) {
+	pProc, ipv4Intf, draIntf := initTestServerSelection(t,
+		SERVER_SELECTION_HASH_BY_MAC)
+	intfState := pProc.initIntfState("eth0", "default")
+	inReq := newTestClientPkt("00:00:00:00:00:01", 1)
+	selected := pProc.selectServers(ipv4Intf, draIntf, inReq, intfState)
+	if len(selected) != 1 {
+		t.Fatal("Wrong number of servers", selected)
+	}
+	// Same client always hashes to the same server
+	for xId := uint32(2); xId < 5; xId++ {
+		again := pProc.selectServers(ipv4Intf, draIntf,
+			newTestClientPkt("00:00:00:00:00:01", xId), intfState)
+		if len(again) != 1 || again[0] != selected[0] {
+			t.Error("Client hashed to another server", again)
+		}
+	}
+	expireServer(pProc, selected[0])
+	failover := pProc.selectServers(ipv4Intf, draIntf, inReq, intfState)
+	if len(failover) != 1 || failover[0] == selected[0] {
+		t.Error("Down server selected by hash", failover)
+	}
+}
+
+func TestRespondingServer(t *testing.T) {
+	pProc, _, _ := initTestServerSelection(t,
+		SERVER_SELECTION_PRIMARY_BACKUP)
+	reqOptions := make(DhcpRelayAgentOptions)
+	// Multi-homed server replying from another address
+	reqOptions[OptionServerIdentifier] = net.ParseIP("20.0.0.2").To4()
+	serverIp := pProc.getRespondingServer("eth0", net.ParseIP("30.0.0.2"),
+		reqOptions)
+	if serverIp != "20.0.0.2" {
+		t.Error("Reply not matched on Server Identifier", serverIp)
+	}
+	// Server Identifier other than the configured address
+	reqOptions[OptionServerIdentifier] = net.ParseIP("30.0.0.3").To4()
+	serverIp = pProc.getRespondingServer("eth0", net.ParseIP("20.0.0.3"),
+		reqOptions)
+	if serverIp != "20.0.0.3" {
+		t.Error("Reply not matched on source address", serverIp)
+	}
+	serverIp = pProc.getRespondingServer("eth0", net.ParseIP("30.0.0.4"),
+		make(DhcpRelayAgentOptions))
+	if serverIp != "30.0.0.4" {
+		t.Error("Unknown server not reported by source address", serverIp)
+	}
+	// Reply of the multi-homed server brings the configured server up
+	expireServer(pProc, "20.0.0.2")
+	reqOptions[OptionServerIdentifier] = net.ParseIP("20.0.0.2").To4()
+	pProc.serverResponseRcvd("eth0", pProc.getRespondingServer("eth0",
+		net.ParseIP("30.0.0.2"), reqOptions))
+	pProc.StateMutex.Lock()
+	alive := pProc.isServerAlive("eth0_20.0.0.2", time.Now())
+	pProc.StateMutex.Unlock()
+	if !alive {
+		t.Error("Multi-homed server still down after reply")
+	}
+}
+
+func TestUpdateServerSelection(t *testing.T) {
+	pProc, _, draIntf := initTestServerSelection(t,
+		SERVER_SELECTION_PRIMARY_BACKUP)
+	intfState, _ := pProc.GetIntfState("eth0")
+	if intfState.ServerSelection != SERVER_SELECTION_PRIMARY_BACKUP {
+		t.Error("Wrong server selection", intfState.ServerSelection)
+	}
+	expireServer(pProc, "20.0.0.1")
+	expireServer(pProc, "20.0.0.3")
+	draIntf.ServerSelection = SERVER_SELECTION_ROUND_ROBIN
+	draIntf.ServerIp = []string{"20.0.0.1", "20.0.0.2"}
+	pProc.ProcessUpdateDRAIntf("eth0")
+	if intfState.ServerSelection != SERVER_SELECTION_ROUND_ROBIN {
+		t.Error("Server selection not refreshed", intfState.ServerSelection)
+	}
+	if _, ok := pProc.ServerLiveness["eth0_20.0.0.3"]; ok {
+		t.Error("Liveness of removed server kept")
+	}
+	if _, ok := pProc.ServerLiveness["eth0_20.0.0.1"]; !ok {
+		t.Error("Liveness of configured server removed")
+	}
+}
@@ -0,0 +1,360 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package dhcp4
+
+import (
+	"dhcprelayd"
+	"encoding/binary"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"github.com/google/gopacket/pcap"
+	"math"
+	"strconv"
+	"time"
+)
+
+type snoopingIngressKey struct {
+	BindingKey string
+	OpCode     OpCode
+}
+
+// Snooping interface on which a client request or a server reply of a
+// transaction was received at L2 ingress
+type snoopingIngress struct {
+	IfName string
+	Expiry time.Time
+}
+
+func getSnoopingIngressKey(inPkt DhcpRelayAgentPacket,
+	opCode OpCode) snoopingIngressKey {
+
+	return snoopingIngressKey{
+		BindingKey: getBindingKey(inPkt.GetCHAddr().String(),
+			getXIdString(inPkt.GetXId())),
+		OpCode: opCode,
+	}
+}
+
+func (pProc *Processor) GetSnoopingBindingStateSlice(
+	fromIdx, count int) (int, int, bool, []*dhcprelayd.DHCPRelaySnoopingBindingState) {
+
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	var nextIdx int
+	var more bool
+	var actualCount int
+	length := len(pProc.SnoopingBindingStateSlice)
+	if fromIdx < 0 || fromIdx >= length || count <= 0 {
+		return 0, 0, false, []*dhcprelayd.DHCPRelaySnoopingBindingState{}
+	}
+	if fromIdx+count >= length {
+		actualCount = length - fromIdx
+		nextIdx = 0
+		more = false
+	} else {
+		actualCount = count
+		nextIdx = fromIdx + count
+		more = true
+	}
+
+	result := make([]*dhcprelayd.DHCPRelaySnoopingBindingState, actualCount)
+	copy(result, pProc.SnoopingBindingStateSlice[fromIdx:fromIdx+actualCount])
+	return nextIdx, actualCount, more, result
+}
+
+func (pProc *Processor) GetSnoopingBindingState(
+	macAddr string) (*dhcprelayd.DHCPRelaySnoopingBindingState, bool) {
+
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	if bindingState, ok := pProc.SnoopingBindingStateMap[macAddr]; ok {
+		return bindingState, true
+	} else {
+		return nil, false
+	}
+}
+
+// Caller needs to hold StateMutex
+func (pProc *Processor) deleteSnoopingBindingState(macAddr string) {
+	bindingState, ok := pProc.SnoopingBindingStateMap[macAddr]
+	if !ok {
+		return
+	}
+	for i, entry := range pProc.SnoopingBindingStateSlice {
+		if entry == bindingState {
+			pProc.SnoopingBindingStateSlice = append(
+				pProc.SnoopingBindingStateSlice[:i],
+				pProc.SnoopingBindingStateSlice[i+1:]...)
+			break
+		}
+	}
+	delete(pProc.SnoopingBindingStateMap, macAddr)
+	delete(pProc.SnoopingBindingExpiry, macAddr)
+	pProc.notifyStateChange()
+}
+
+// Caller needs to hold StateMutex
+func (pProc *Processor) getSnoopingIngress(inPkt DhcpRelayAgentPacket,
+	opCode OpCode) (string, bool) {
+
+	ingress, ok := pProc.SnoopingIngress[getSnoopingIngressKey(inPkt, opCode)]
+	if !ok {
+		return "", false
+	}
+	return ingress.IfName, true
+}
+
+// Records are capped at SNOOPING_INGRESS_MAX, expired ones are purged
+// first and then arbitrary ones are evicted to make room
+func (pProc *Processor) recordSnoopingIngress(ifName string,
+	inPkt DhcpRelayAgentPacket) {
+
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	key := getSnoopingIngressKey(inPkt, inPkt.GetOpCode())
+	now := time.Now()
+	if _, ok := pProc.SnoopingIngress[key]; !ok &&
+		len(pProc.SnoopingIngress) >= SNOOPING_INGRESS_MAX {
+		pProc.ageSnoopingIngress(now)
+		for oldKey := range pProc.SnoopingIngress {
+			if len(pProc.SnoopingIngress) < SNOOPING_INGRESS_MAX {
+				break
+			}
+			delete(pProc.SnoopingIngress, oldKey)
+		}
+	}
+	pProc.SnoopingIngress[key] = &snoopingIngress{
+		IfName: ifName,
+		Expiry: now.Add(SNOOPING_INGRESS_TIMEOUT),
+	}
+}
+
+// DHCP messages received on snooping interfaces are recorded against the
+// interface until the handle is closed
+func (pProc *Processor) snoopingRx(ifName string, pcapHdl *pcap.Handle) {
+	recv := gopacket.NewPacketSource(pcapHdl, layers.LayerTypeEthernet)
+	for packet := range recv.Packets() {
+		udpLayer := packet.Layer(layers.LayerTypeUDP)
+		if udpLayer == nil {
+			continue
+		}
+		payload := udpLayer.(*layers.UDP).Payload
+		if len(payload) < DHCP_PACKET_MIN_BYTES {
+			continue
+		}
+		pProc.recordSnoopingIngress(ifName, DhcpRelayAgentPacket(payload))
+	}
+	Logger.Debug("DRA: Snooping stopped on", ifName)
+}
+
+// Snooping interfaces are L2 ports, they need not carry an IPv4 address
+func (pProc *Processor) ProcessCreateSnoopingIntf(ifName string) {
+	pcapHdl, err := pcap.OpenLive(ifName, snapshot_len, promiscuous, timeout)
+	if err != nil {
+		Logger.Err("DRA: opening snooping pcap for", ifName,
+			"failed with Error:", err)
+		return
+	}
+	err = pcapHdl.SetDirection(pcap.DirectionIn)
+	if err == nil {
+		err = pcapHdl.SetBPFFilter(SNOOPING_PCAP_FILTER)
+	}
+	if err != nil {
+		Logger.Err("DRA: setting snooping filter for", ifName,
+			"failed with Error:", err)
+		pcapHdl.Close()
+		return
+	}
+	pProc.StateMutex.Lock()
+	if oldHdl, ok := pProc.SnoopingPcapHandles[ifName]; ok {
+		oldHdl.Close()
+	}
+	pProc.SnoopingPcapHandles[ifName] = pcapHdl
+	pProc.StateMutex.Unlock()
+	go pProc.snoopingRx(ifName, pcapHdl)
+}
+
+func (pProc *Processor) ProcessDeleteSnoopingIntf(ifName string) {
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	if pcapHdl, ok := pProc.SnoopingPcapHandles[ifName]; ok {
+		pcapHdl.Close()
+		delete(pProc.SnoopingPcapHandles, ifName)
+	}
+}
+
+// Vlan interfaces carry the traffic of the snooping ports, once any port
+// is snooped a server message relayed on a vlan is expected to have been
+// seen at L2 ingress
+func (pProc *Processor) isSnoopingVlan(ifName string) bool {
+	pProc.StateMutex.Lock()
+	snooping := len(pProc.SnoopingPcapHandles) > 0
+	pProc.StateMutex.Unlock()
+	if !snooping {
+		return false
+	}
+	ifIdx, ok := pProc.InfraMgr.GetIPv4IntfIndex(ifName)
+	if !ok {
+		return false
+	}
+	ipv4Intf, ok := pProc.InfraMgr.GetIPv4Intf(ifIdx)
+	return ok && ipv4Intf.L2IntfType == "Vlan"
+}
+
+// Server messages are attributed to the snooping interface they were
+// received on at L2 ingress, otherwise to the interface the relay received
+// them on. When snooping is enabled in the VRF, messages attributed to an
+// interface configured as untrusted are dropped, and so are messages
+// received on a snooping vlan which were not seen at L2 ingress
+func (pProc *Processor) validateRxDownstream(vrf string, inIfName string,
+	inPkt DhcpRelayAgentPacket, mType MessageType,
+	intfState *dhcprelayd.DHCPRelayIntfState) bool {
+
+	if !pProc.InfraMgr.GetDRAv4SnoopingEnabled(vrf) {
+		return true
+	}
+	pProc.StateMutex.Lock()
+	ingressIfName, ok := pProc.getSnoopingIngress(inPkt, Reply)
+	pProc.StateMutex.Unlock()
+	if !ok {
+		if pProc.isSnoopingVlan(inIfName) {
+			Logger.Debug("DRA: Dropping " + ParseMessageTypeToString(mType) +
+				" received on " + inIfName + " not seen on a snooping interface")
+			pProc.countSnoopingDrop(intfState)
+			return false
+		}
+		ingressIfName = inIfName
+	}
+	snoopingIntf, ok := pProc.InfraMgr.GetSnoopingIntf(ingressIfName)
+	if !ok || snoopingIntf.Trusted {
+		return true
+	}
+	Logger.Debug("DRA: Dropping " + ParseMessageTypeToString(mType) +
+		" received on untrusted interface " + ingressIfName)
+	pProc.countSnoopingDrop(intfState)
+	return false
+}
+
+func (pProc *Processor) countSnoopingDrop(
+	intfState *dhcprelayd.DHCPRelayIntfState) {
+
+	pProc.StateMutex.Lock()
+	intfState.SnoopingDrops++
+	intfState.TotalDrops++
+	pProc.StateMutex.Unlock()
+}
+
+// Snooping bindings are built from the acks relayed to the clients and are
+// bound to the port the client request was received on, if it was seen on a
+// snooping interface
+func (pProc *Processor) updateSnoopingBinding(inPkt DhcpRelayAgentPacket,
+	reqOptions DhcpRelayAgentOptions, mType MessageType, ifName string) {
+
+	vrf := pProc.InfraMgr.GetDRAv4IntfVrf(ifName)
+	if !pProc.InfraMgr.GetDRAv4SnoopingEnabled(vrf) {
+		return
+	}
+	var vlan int32
+	if ifIdx, ok := pProc.InfraMgr.GetIPv4IntfIndex(ifName); ok {
+		if ipv4Intf, ok := pProc.InfraMgr.GetIPv4Intf(ifIdx); ok &&
+			ipv4Intf.L2IntfType == "Vlan" {
+			vlan = ipv4Intf.L2IntfId
+		}
+	}
+
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	macAddr := inPkt.GetCHAddr().String()
+	clientIfName, ok := pProc.getSnoopingIngress(inPkt, Request)
+	if !ok {
+		clientIfName = ifName
+	}
+	switch mType {
+	case DhcpACK:
+		leaseVal, ok := reqOptions[OptionIPAddressLeaseTime]
+		if !ok || len(leaseVal) != 4 {
+			return
+		}
+		leaseTime := binary.BigEndian.Uint32(leaseVal)
+		if leaseTime > math.MaxInt32 {
+			leaseTime = math.MaxInt32
+		}
+		expiry := time.Now().Add(time.Duration(leaseTime) * time.Second)
+		bindingState, ok := pProc.SnoopingBindingStateMap[macAddr]
+		if !ok {
+			bindingState = &dhcprelayd.DHCPRelaySnoopingBindingState{}
+			bindingState.MacAddr = macAddr
+			pProc.SnoopingBindingStateSlice = append(
+				pProc.SnoopingBindingStateSlice, bindingState)
+			pProc.SnoopingBindingStateMap[macAddr] = bindingState
+		}
+		bindingState.IpAddr = inPkt.GetYIAddr().String()
+		bindingState.Vlan = vlan
+		bindingState.IntfRef = clientIfName
+		bindingState.LeaseTime = int32(leaseTime)
+		bindingState.LeaseExpiry = strconv.FormatInt(expiry.Unix(), 10)
+		pProc.SnoopingBindingExpiry[macAddr] = expiry
+		pProc.notifyStateChange()
+	case DhcpNAK:
+		pProc.deleteSnoopingBindingState(macAddr)
+	case DhcpRelease, DhcpDecline:
+		// Only the client port of the binding can give up the lease
+		bindingState, ok := pProc.SnoopingBindingStateMap[macAddr]
+		if !ok {
+			return
+		}
+		if bindingState.IntfRef != clientIfName {
+			Logger.Debug("DRA: Ignoring " + ParseMessageTypeToString(mType) +
+				" for " + macAddr + " received on " + clientIfName +
+				", binding is on " + bindingState.IntfRef)
+			return
+		}
+		pProc.deleteSnoopingBindingState(macAddr)
+	}
+}
+
+// Caller needs to hold StateMutex
+func (pProc *Processor) ageSnoopingBindings(now time.Time) {
+	for macAddr, expiry := range pProc.SnoopingBindingExpiry {
+		if now.After(expiry) {
+			Logger.Debug("DRA: Snooping binding expired for", macAddr)
+			pProc.deleteSnoopingBindingState(macAddr)
+		}
+	}
+	pProc.ageSnoopingIngress(now)
+}
+
+// Caller needs to hold StateMutex
+func (pProc *Processor) ageSnoopingIngress(now time.Time) {
+	for key, ingress := range pProc.SnoopingIngress {
+		if now.After(ingress.Expiry) {
+			delete(pProc.SnoopingIngress, key)
+		}
+	}
+}
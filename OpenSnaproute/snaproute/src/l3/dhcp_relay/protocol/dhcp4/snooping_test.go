@@ -0,0 +1,203 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package dhcp4
+
+import (
+	"dhcprelayd"
+	"l3/dhcp_relay/infra"
+	"net"
+	"testing"
+	"time"
+)
+
+func initTestSnoopingProcessor(t *testing.T) *Processor {
+	pProc, _ := initTestProcessor(t, &dhcprelayd.DHCPRelayIntf{
+		Enable: true,
+	})
+	pProc.InfraMgr.DRAv4Globals["default"].Snooping = true
+	pProc.InfraMgr.UpdateSnoopingIntf(&dhcprelayd.DHCPRelaySnoopingIntf{
+		IntfRef: "fpPort1",
+		Trusted: false,
+	})
+	pProc.InfraMgr.UpdateSnoopingIntf(&dhcprelayd.DHCPRelaySnoopingIntf{
+		IntfRef: "fpPort2",
+		Trusted: false,
+	})
+	pProc.InfraMgr.UpdateSnoopingIntf(&dhcprelayd.DHCPRelaySnoopingIntf{
+		IntfRef: "fpPort3",
+		Trusted: true,
+	})
+	return pProc
+}
+
+func newTestReply(macAddr string, xId uint32, yiAddr string) DhcpRelayAgentPacket {
+	reply := newTestClientPkt(macAddr, xId)
+	reply.SetOpCode(Reply)
+	reply.SetYIAddr(net.ParseIP(yiAddr))
+	return reply
+}
+
+func TestSnoopingRxDownstream(t *testing.T) {
+	pProc := initTestSnoopingProcessor(t)
+	intfState := pProc.initIntfState("eth0", "default")
+	// Uplink without snooping config is trusted
+	reply := newTestReply("00:00:00:00:00:01", 1, "10.0.0.10")
+	if !pProc.validateRxDownstream("default", "eth1", reply, DhcpOffer,
+		intfState) {
+		t.Error("Server message on uplink dropped")
+	}
+	// Server message seen at L2 ingress on an untrusted port
+	pProc.recordSnoopingIngress("fpPort1", reply)
+	if pProc.validateRxDownstream("default", "eth1", reply, DhcpOffer,
+		intfState) {
+		t.Error("Server message from untrusted port relayed")
+	}
+	if intfState.SnoopingDrops != 1 || intfState.TotalDrops != 1 {
+		t.Error("Wrong SnoopingDrops", intfState.SnoopingDrops)
+	}
+	// Server message seen at L2 ingress on a trusted port
+	reply = newTestReply("00:00:00:00:00:02", 2, "10.0.0.11")
+	pProc.recordSnoopingIngress("fpPort3", reply)
+	if !pProc.validateRxDownstream("default", "eth1", reply, DhcpOffer,
+		intfState) {
+		t.Error("Server message from trusted port dropped")
+	}
+	// Client request of the same transaction does not taint the reply
+	reply = newTestReply("00:00:00:00:00:03", 3, "10.0.0.12")
+	pProc.recordSnoopingIngress("fpPort1", newTestClientPkt(
+		"00:00:00:00:00:03", 3))
+	if !pProc.validateRxDownstream("default", "eth1", reply, DhcpOffer,
+		intfState) {
+		t.Error("Server message dropped for client request on untrusted port")
+	}
+	// Server message on a snooping vlan not seen at L2 ingress
+	pProc.InfraMgr.IPv4IntfProps[2] = &infra.IPv4IntfProperty{
+		IpAddr:     "20.0.0.1",
+		Netmask:    net.CIDRMask(24, 32),
+		IfIndex:    2,
+		IfRef:      "vlan10",
+		State:      true,
+		L2IntfType: "Vlan",
+		L2IntfId:   10,
+	}
+	pProc.InfraMgr.IPv4IfRefToIfIndex["vlan10"] = 2
+	reply = newTestReply("00:00:00:00:00:04", 4, "10.0.0.13")
+	if !pProc.validateRxDownstream("default", "vlan10", reply, DhcpOffer,
+		intfState) {
+		t.Error("Server message on vlan dropped without snooped ports")
+	}
+	pProc.SnoopingPcapHandles["fpPort1"] = nil
+	if pProc.validateRxDownstream("default", "vlan10", reply, DhcpOffer,
+		intfState) {
+		t.Error("Server message on snooping vlan relayed without L2 ingress")
+	}
+	if intfState.SnoopingDrops != 2 || intfState.TotalDrops != 2 {
+		t.Error("Wrong SnoopingDrops", intfState.SnoopingDrops)
+	}
+	pProc.recordSnoopingIngress("fpPort3", reply)
+	if !pProc.validateRxDownstream("default", "vlan10", reply, DhcpOffer,
+		intfState) {
+		t.Error("Server message on snooping vlan from trusted port dropped")
+	}
+	// Nothing is dropped with snooping disabled
+	pProc.InfraMgr.DRAv4Globals["default"].Snooping = false
+	reply = newTestReply("00:00:00:00:00:01", 1, "10.0.0.10")
+	if !pProc.validateRxDownstream("default", "eth1", reply, DhcpOffer,
+		intfState) {
+		t.Error("Server message dropped with snooping disabled")
+	}
+}
+
+func TestSnoopingIngressCap(t *testing.T) {
+	pProc := initTestSnoopingProcessor(t)
+	for i := 0; i < SNOOPING_INGRESS_MAX; i++ {
+		pProc.recordSnoopingIngress("fpPort1",
+			newTestClientPkt("00:00:00:00:00:01", uint32(i)))
+	}
+	// Expired records make room first
+	for _, ingress := range pProc.SnoopingIngress {
+		ingress.Expiry = time.Now().Add(-time.Second)
+		break
+	}
+	reply := newTestReply("00:00:00:00:00:02", 1, "10.0.0.10")
+	pProc.recordSnoopingIngress("fpPort3", reply)
+	if len(pProc.SnoopingIngress) != SNOOPING_INGRESS_MAX {
+		t.Error("Wrong number of ingress records", len(pProc.SnoopingIngress))
+	}
+	for i := 0; i < 10; i++ {
+		pProc.recordSnoopingIngress("fpPort1",
+			newTestClientPkt("00:00:00:00:00:03", uint32(i)))
+	}
+	if len(pProc.SnoopingIngress) > SNOOPING_INGRESS_MAX {
+		t.Error("Ingress records above the cap", len(pProc.SnoopingIngress))
+	}
+	ifName, ok := pProc.getSnoopingIngress(newTestClientPkt(
+		"00:00:00:00:00:03", 9), Request)
+	if !ok || ifName != "fpPort1" {
+		t.Error("Latest ingress record evicted")
+	}
+}
+
+func TestSnoopingBinding(t *testing.T) {
+	pProc := initTestSnoopingProcessor(t)
+	macAddr := "00:00:00:00:00:01"
+	pProc.recordSnoopingIngress("fpPort1", newTestClientPkt(macAddr, 1))
+	ackOptions := make(DhcpRelayAgentOptions)
+	ackOptions[OptionIPAddressLeaseTime] = []byte{0, 0, 0x0e, 0x10}
+	pProc.updateSnoopingBinding(newTestReply(macAddr, 1, "10.0.0.10"),
+		ackOptions, DhcpACK, "eth0")
+	bindingState, ok := pProc.GetSnoopingBindingState(macAddr)
+	if !ok {
+		t.Fatal("Snooping binding not learnt from ack")
+	}
+	if bindingState.IntfRef != "fpPort1" || bindingState.IpAddr != "10.0.0.10" ||
+		bindingState.LeaseTime != 3600 {
+		t.Error("Wrong snooping binding", bindingState)
+	}
+	// Release from another port is ignored
+	release := newTestClientPkt(macAddr, 2)
+	pProc.recordSnoopingIngress("fpPort2", release)
+	pProc.updateSnoopingBinding(release, make(DhcpRelayAgentOptions),
+		DhcpRelease, "eth0")
+	if _, ok := pProc.GetSnoopingBindingState(macAddr); !ok {
+		t.Error("Snooping binding deleted by release from another port")
+	}
+	// Decline without an observed port is attributed to the interface
+	decline := newTestClientPkt(macAddr, 3)
+	pProc.updateSnoopingBinding(decline, make(DhcpRelayAgentOptions),
+		DhcpDecline, "eth0")
+	if _, ok := pProc.GetSnoopingBindingState(macAddr); !ok {
+		t.Error("Snooping binding deleted by decline from another interface")
+	}
+	release = newTestClientPkt(macAddr, 4)
+	pProc.recordSnoopingIngress("fpPort1", release)
+	pProc.updateSnoopingBinding(release, make(DhcpRelayAgentOptions),
+		DhcpRelease, "eth0")
+	if _, ok := pProc.GetSnoopingBindingState(macAddr); ok {
+		t.Error("Snooping binding not deleted by release from its port")
+	}
+	if len(pProc.SnoopingBindingStateSlice) != 0 {
+		t.Error("Snooping binding slice not updated")
+	}
+}
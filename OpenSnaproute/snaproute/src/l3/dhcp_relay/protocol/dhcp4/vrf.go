@@ -0,0 +1,129 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package dhcp4
+
+import (
+	"golang.org/x/net/ipv4"
+	"l3/dhcp_relay/infra"
+	"net"
+)
+
+// UDP socket of a relay VRF, bound to the VRF device
+type vrfConn struct {
+	Handler *net.UDPConn
+	Conn    *ipv4.PacketConn
+}
+
+func (vConn *vrfConn) Close() error {
+	return vConn.Conn.Close()
+}
+
+func (pProc *Processor) CreateConn(vrf string) (infra.VrfConn, error) {
+	saddr := net.UDPAddr{
+		IP:   net.ParseIP(""),
+		Port: DHCP_SERVER_PORT,
+	}
+	handler, err := infra.ListenUDPInVrf("udp4", vrf, &saddr)
+	if err != nil {
+		Logger.Err("Opening udp port for client --> server failed in Vrf",
+			vrf, err)
+		return nil, err
+	}
+	conn := ipv4.NewPacketConn(handler)
+	controlFlag := ipv4.FlagSrc | ipv4.FlagDst | ipv4.FlagInterface
+	err = conn.SetControlMessage(controlFlag, true)
+	if err != nil {
+		Logger.Err("Setting control flag for client failed..", err)
+		conn.Close()
+		return nil, err
+	}
+	Logger.Debug("Client Connection opened successfully in Vrf", vrf)
+	return &vrfConn{Handler: handler, Conn: conn}, nil
+}
+
+func (pProc *Processor) getVrfConn(vrf string) (*vrfConn, bool) {
+	conn, ok := pProc.VrfConns.Get(vrf)
+	if !ok {
+		return nil, false
+	}
+	return conn.(*vrfConn), true
+}
+
+// Servers of another VRF can't reach the addresses of the relay VRF. Requests
+// relayed across VRFs carry a GIAddr of the server VRF, and the client facing
+// address in the Link Selection (RFC 3527) and Server Identifier Override
+// (RFC 5107) sub-options so that the server picks the client subnet and the
+// clients renew through the relay agent
+func (pProc *Processor) isCrossVrf(ifName string) bool {
+	vrf := pProc.InfraMgr.GetDRAv4IntfVrf(ifName)
+	return pProc.InfraMgr.GetDRAv4ServerVrf(vrf) != vrf
+}
+
+// GIAddr of requests to the server, the address the server VRF reaches the
+// server from when relaying across VRFs
+func (pProc *Processor) getGIAddr(inIntfProp *infra.IPv4IntfProperty,
+	serverIp string) (net.IP, bool) {
+
+	if !pProc.isCrossVrf(inIntfProp.IfRef) {
+		return net.ParseIP(inIntfProp.IpAddr), true
+	}
+	serverVrf := pProc.InfraMgr.GetDRAv4ServerVrf(
+		pProc.InfraMgr.GetDRAv4IntfVrf(inIntfProp.IfRef))
+	giAddr, err := pProc.ServerVrfAddr(serverVrf, &net.UDPAddr{
+		IP:   net.ParseIP(serverIp),
+		Port: DHCP_SERVER_PORT,
+	})
+	if err != nil {
+		Logger.Debug("DRA: No address to reach server", serverIp,
+			"in server Vrf", serverVrf, err)
+		return nil, false
+	}
+	return giAddr, true
+}
+
+// Requests of clients renewing through the relay agent carry the client
+// facing address handed out in Server Identifier Override
+func (pProc *Processor) isServerIdOverridden(
+	inIntfProp *infra.IPv4IntfProperty,
+	reqOptions DhcpRelayAgentOptions) bool {
+
+	serverId, ok := reqOptions[OptionServerIdentifier]
+	return ok && pProc.isCrossVrf(inIntfProp.IfRef) &&
+		net.IP(serverId).Equal(net.ParseIP(inIntfProp.IpAddr))
+}
+
+// Client requests received in the relay VRF of the interface are sent to
+// the servers over the socket of the server VRF
+func (pProc *Processor) getServerConn(
+	inIntfProp *infra.IPv4IntfProperty) (*vrfConn, bool) {
+
+	vrf := pProc.InfraMgr.GetDRAv4IntfVrf(inIntfProp.IfRef)
+	serverVrf := pProc.InfraMgr.GetDRAv4ServerVrf(vrf)
+	vConn, ok := pProc.getVrfConn(serverVrf)
+	if !ok {
+		Logger.Debug("DRA: No socket in server Vrf", serverVrf,
+			"for Vrf", vrf)
+	}
+	return vConn, ok
+}
@@ -0,0 +1,112 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package dhcp6
+
+import (
+	"dhcprelayd"
+	"net"
+	"ribd"
+	"strconv"
+	"time"
+)
+
+// Copy of the relay state written to and read back from the state DB
+type StateCheckpoint struct {
+	PDStates []*dhcprelayd.DHCPv6RelayPDState
+}
+
+func (pProc *Processor) GetStateChangeCh() chan bool {
+	return pProc.StateChangeCh
+}
+
+// Caller needs to hold StateMutex, pending notification is enough as the
+// checkpoint picks up all changes
+func (pProc *Processor) notifyStateChange() {
+	select {
+	case pProc.StateChangeCh <- true:
+	default:
+	}
+}
+
+func (pProc *Processor) GetStateCheckpoint() *StateCheckpoint {
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	checkpoint := &StateCheckpoint{}
+	for _, entry := range pProc.PDStateSlice {
+		pdState := *entry
+		checkpoint.PDStates = append(checkpoint.PDStates, &pdState)
+	}
+	return checkpoint
+}
+
+// Restores delegations learnt before a restart. Routes of delegations which
+// expired or whose interface is gone in the meantime are removed from RIB,
+// the others are reinstalled by the next aging run if needed
+func (pProc *Processor) RestoreStateCheckpoint(checkpoint *StateCheckpoint) {
+	var removeRoutes []*ribd.IPv6Route
+
+	pProc.StateMutex.Lock()
+	now := time.Now()
+	for _, entry := range checkpoint.PDStates {
+		pdKey := getPDKey(entry.Vrf, entry.Prefix)
+		if _, ok := pProc.PDStateMap[pdKey]; ok {
+			continue
+		}
+		_, prefix, err := net.ParseCIDR(entry.Prefix)
+		if err != nil {
+			continue
+		}
+		outIfIdx, ok := pProc.InfraMgr.GetIPv6IntfIndex(entry.IntfRef)
+		route := newPDRoute(prefix, entry.NextHopIp, outIfIdx, entry.Vrf)
+		if !ok {
+			Logger.Debug("DRA: Dropping delegated prefix of unknown interface",
+				entry.Prefix, entry.IntfRef)
+			if entry.RouteInstalled {
+				// ifIndex went with the interface, the route is removed
+				// by prefix and next hop
+				route.NextHop[0].NextHopIntRef = ""
+				removeRoutes = append(removeRoutes, route)
+			}
+			continue
+		}
+		leaseExpiry, err := strconv.ParseInt(entry.LeaseExpiry, 10, 64)
+		expiry := time.Unix(leaseExpiry, 0)
+		if err != nil || now.After(expiry) {
+			if entry.RouteInstalled {
+				removeRoutes = append(removeRoutes, route)
+			}
+			continue
+		}
+		pdState := *entry
+		pProc.PDStateSlice = append(pProc.PDStateSlice, &pdState)
+		pProc.PDStateMap[pdKey] = &pdState
+		pProc.PDExpiry[pdKey] = expiry
+		pProc.PDRoutes[pdKey] = route
+	}
+	pProc.notifyStateChange()
+	pProc.StateMutex.Unlock()
+
+	pProc.removePDRoutes(removeRoutes)
+}
@@ -23,6 +23,10 @@
 
 package dhcp6
 
+import (
+	"time"
+)
+
 // DHCP Packet global constants
 const (
 	DHCP_SERVER_PORT     = 547
@@ -31,6 +35,7 @@ const (
 	DHCP_NO_IP           = "0.0.0.0"
 	DHCP_REDIS_DB_PORT   = ":6379"
 	HOP_COUNT_LIMIT      = 32
+	HOP_COUNT_MAX        = 255
 	ALL_DRA_SERVERS_ADDR = "FF02::1:2"
 	ALL_SERVERS_ADDR     = "FF05::1:3"
 )
@@ -54,9 +59,21 @@ const (
 
 // DHCP Option Types
 const (
-	OPTION_CLIENTID  OptionType = 1
-	OPTION_IA_NA     OptionType = 3
-	OPTION_IA_TA     OptionType = 4
-	OPTION_IAADDR    OptionType = 5
-	OPTION_RELAY_MSG OptionType = 9
+	OPTION_CLIENTID     OptionType = 1
+	OPTION_IA_NA        OptionType = 3
+	OPTION_IA_TA        OptionType = 4
+	OPTION_IAADDR       OptionType = 5
+	OPTION_RELAY_MSG    OptionType = 9
+	OPTION_INTERFACE_ID OptionType = 18
+	OPTION_IA_PD        OptionType = 25
+	OPTION_IAPREFIX     OptionType = 26
+	OPTION_REMOTE_ID    OptionType = 37
+)
+
+// Delegated prefix aging
+const (
+	PD_AGING_INTERVAL = 10 * time.Second
+	// Distinct from operator configured static routes so that neither
+	// replaces nor removes the other
+	PD_ROUTE_PROTOCOL = "DHCPV6_PD"
 )
@@ -39,6 +39,8 @@ type DhcpOptionMap map[OptionType]DhcpOption
 type OptionIANA []byte
 type OptionIATA []byte
 type OptionIAAddr []byte
+type OptionIAPD []byte
+type OptionIAPrefix []byte
 type Duid []byte
 
 // CLIENT/SERVER Packet
@@ -90,6 +92,22 @@ func (p DhcpPacket) ParseOptions(filter []OptionType) DhcpOptionMap {
 	return optionMap
 }
 
+// A message can carry more than one IA_PD
+func (p DhcpPacket) GetIAPDOptions() []OptionIAPD {
+	result := []OptionIAPD{}
+	dOpt := DhcpOption(p.GetOptionsField())
+	for {
+		if !dOpt.Validate() {
+			break
+		}
+		if OptionType(dOpt.GetCode()) == OPTION_IA_PD && dOpt.GetLen() >= 12 {
+			result = append(result, OptionIAPD(dOpt[:4+int(dOpt.GetLen())]))
+		}
+		dOpt = DhcpOption(dOpt[4+int(dOpt.GetLen()):])
+	}
+	return result
+}
+
 func (p DhcpPacket) GetDuidField() []byte {
 	i := 4
 	var dOpt DhcpOption
@@ -152,6 +170,10 @@ func (p DhcpRelayPacket) GetOptionsField() []byte {
 }
 
 func (p DhcpRelayPacket) GetDRAOptionField() DhcpOption {
+	return p.GetOptionField(OPTION_RELAY_MSG)
+}
+
+func (p DhcpRelayPacket) GetOptionField(code OptionType) DhcpOption {
 	i := 34
 	var dOpt DhcpOption
 	for { // Iterate over Options
@@ -163,7 +185,7 @@ func (p DhcpRelayPacket) GetDRAOptionField() DhcpOption {
 			break
 		}
 		opCode := dOpt.GetCode()
-		if opCode == uint16(OPTION_RELAY_MSG) {
+		if opCode == uint16(code) {
 			return dOpt
 		}
 		i = i + 4 + int(dOpt.GetLen())
@@ -172,13 +194,25 @@ func (p DhcpRelayPacket) GetDRAOptionField() DhcpOption {
 }
 
 func (p DhcpRelayPacket) AddRelayMsgOption(payload []byte) DhcpRelayPacket {
-	newPkt := p[:len(p)+4+len(payload)]
+	return p.AddOption(OPTION_RELAY_MSG, payload)
+}
+
+func (p DhcpRelayPacket) AddOption(code OptionType, payload []byte) DhcpRelayPacket {
+	newPkt := append(p, make([]byte, 4+len(payload))...)
 	dhcpOp := DhcpOption(newPkt[len(p):])
-	dhcpOp.SetCodeField(uint16(OPTION_RELAY_MSG))
+	dhcpOp.SetCodeField(uint16(code))
 	dhcpOp.SetPayload(payload)
 	return newPkt
 }
 
+// Remote-ID payload is the vendor enterprise number followed by remote id
+func RemoteIdPayload(enterpriseNum uint32, remoteId []byte) []byte {
+	payload := make([]byte, 4+len(remoteId))
+	binary.BigEndian.PutUint32(payload[0:4], enterpriseNum)
+	copy(payload[4:], remoteId)
+	return payload
+}
+
 //func (p DhcpRelayPacket) GetDuidField() []byte {
 //	var draOpt DhcpOption
 //	var tmpBuf []byte = p
@@ -393,3 +427,42 @@ func (p OptionIATA) GetIpAddr() []byte {
 func (p OptionIAAddr) GetIpAddr() []byte {
 	return p[4:20]
 }
+
+func (p OptionIAPD) GetOptions() []byte {
+	return p[16:]
+}
+
+func (p OptionIAPD) GetPrefixes() []OptionIAPrefix {
+	result := []OptionIAPrefix{}
+	dOpt := DhcpOption(p.GetOptions())
+	for {
+		if !dOpt.Validate() {
+			break
+		}
+		if OptionType(dOpt.GetCode()) == OPTION_IAPREFIX && dOpt.GetLen() >= 25 {
+			result = append(result, OptionIAPrefix(dOpt))
+		}
+		dOpt = DhcpOption(dOpt[4+int(dOpt.GetLen()):])
+	}
+	return result
+}
+
+func (p OptionIAPrefix) GetPreferredLifetime() uint32 {
+	return binary.BigEndian.Uint32(p[4:8])
+}
+
+func (p OptionIAPrefix) GetValidLifetime() uint32 {
+	return binary.BigEndian.Uint32(p[8:12])
+}
+
+func (p OptionIAPrefix) GetPrefix() *net.IPNet {
+	prefixLen := int(p[12])
+	if prefixLen > 128 {
+		return nil
+	}
+	mask := net.CIDRMask(prefixLen, 128)
+	return &net.IPNet{
+		IP:   net.IP(p[13:29]).Mask(mask),
+		Mask: mask,
+	}
+}
@@ -0,0 +1,120 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package dhcp6
+
+import (
+	"bytes"
+	"net"
+	"testing"
+)
+
+func newTestRelayPkt() DhcpRelayPacket {
+	pkt := make(DhcpRelayPacket, 34)
+	pkt.SetMsgType(int8(RELAY_FORW))
+	pkt.SetHopCount(1)
+	pkt.SetLinkAddrField(net.ParseIP("2001:db8::1"))
+	pkt.SetPeerAddrField(net.ParseIP("fe80::1"))
+	return pkt
+}
+
+func TestAddOption(t *testing.T) {
+	pkt := newTestRelayPkt()
+	clientMsg := []byte{byte(SOLICIT), 0x01, 0x02, 0x03}
+	pkt = pkt.AddRelayMsgOption(clientMsg)
+	pkt = pkt.AddOption(OPTION_INTERFACE_ID, []byte("eth0"))
+	if !pkt.Validate() {
+		t.Fatal("Relay packet not valid after adding options")
+	}
+	if len(pkt) != 34+4+len(clientMsg)+4+len("eth0") {
+		t.Error("Wrong relay packet length", len(pkt))
+	}
+	expected := []byte{0, byte(OPTION_RELAY_MSG), 0, 4}
+	if !bytes.Equal(pkt[34:38], expected) {
+		t.Error("Wrong relay message option header", pkt[34:38])
+	}
+	// Fixed header is kept as is
+	if MsgType(pkt.GetMsgType()) != RELAY_FORW || pkt.GetHopCount() != 1 ||
+		!net.IP(pkt.GetLinkAddrField()).Equal(net.ParseIP("2001:db8::1")) ||
+		!net.IP(pkt.GetPeerAddrField()).Equal(net.ParseIP("fe80::1")) {
+		t.Error("Relay header changed by adding options")
+	}
+	// Option without payload
+	pkt = pkt.AddOption(OPTION_REMOTE_ID, nil)
+	dOpt := pkt.GetOptionField(OPTION_REMOTE_ID)
+	if dOpt == nil || dOpt.GetLen() != 0 || len(dOpt.GetPayload()) != 0 {
+		t.Error("Empty option not added", dOpt)
+	}
+}
+
+func TestGetOptionField(t *testing.T) {
+	pkt := newTestRelayPkt()
+	if pkt.GetOptionField(OPTION_RELAY_MSG) != nil {
+		t.Error("Option found in packet without options")
+	}
+	clientMsg := []byte{byte(SOLICIT), 0x01, 0x02, 0x03}
+	pkt = pkt.AddRelayMsgOption(clientMsg)
+	pkt = pkt.AddOption(OPTION_INTERFACE_ID, []byte("eth0"))
+	pkt = pkt.AddOption(OPTION_INTERFACE_ID, []byte("eth1"))
+	dOpt := pkt.GetDRAOptionField()
+	if dOpt == nil || !bytes.Equal(dOpt.GetPayload(), clientMsg) {
+		t.Error("Wrong relay message option", dOpt)
+	}
+	// First occurrence is returned
+	dOpt = pkt.GetOptionField(OPTION_INTERFACE_ID)
+	if dOpt == nil || string(dOpt.GetPayload()) != "eth0" {
+		t.Error("Wrong interface id option", dOpt)
+	}
+	if pkt.GetOptionField(OPTION_REMOTE_ID) != nil {
+		t.Error("Missing option found")
+	}
+	// Option length running past the end of the packet
+	truncated := append(DhcpRelayPacket{}, pkt[:len(pkt)-2]...)
+	if truncated.GetOptionField(OPTION_INTERFACE_ID) == nil {
+		t.Error("Option before truncation not found")
+	}
+	truncated[34+4+len(clientMsg)+3] = 0xff
+	if truncated.GetOptionField(OPTION_INTERFACE_ID) != nil {
+		t.Error("Option with bad length accepted")
+	}
+}
+
+func TestRemoteIdPayload(t *testing.T) {
+	mac, _ := net.ParseMAC("00:11:22:33:44:55")
+	payload := RemoteIdPayload(0x0000a1b2, mac)
+	expected := []byte{0x00, 0x00, 0xa1, 0xb2,
+		0x00, 0x11, 0x22, 0x33, 0x44, 0x55}
+	if !bytes.Equal(payload, expected) {
+		t.Error("Wrong remote id payload", payload)
+	}
+	payload = RemoteIdPayload(1, nil)
+	if !bytes.Equal(payload, []byte{0, 0, 0, 1}) {
+		t.Error("Wrong remote id payload without remote id", payload)
+	}
+	pkt := newTestRelayPkt().AddOption(OPTION_REMOTE_ID,
+		RemoteIdPayload(0x0000a1b2, mac))
+	dOpt := pkt.GetOptionField(OPTION_REMOTE_ID)
+	if dOpt == nil || !bytes.Equal(dOpt.GetPayload(), expected) {
+		t.Error("Wrong remote id option", dOpt)
+	}
+}
@@ -0,0 +1,377 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package dhcp6
+
+import (
+	"dhcprelayd"
+	"math"
+	"net"
+	"ribd"
+	"strconv"
+	"time"
+)
+
+func (pProc *Processor) GetPDStateSlice(
+	fromIdx, count int) (int, int, bool, []*dhcprelayd.DHCPv6RelayPDState) {
+
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	var nextIdx int
+	var more bool
+	var actualCount int
+	length := len(pProc.PDStateSlice)
+	if fromIdx < 0 || fromIdx >= length || count <= 0 {
+		return 0, 0, false, []*dhcprelayd.DHCPv6RelayPDState{}
+	}
+	if fromIdx+count >= length {
+		actualCount = length - fromIdx
+		nextIdx = 0
+		more = false
+	} else {
+		actualCount = count
+		nextIdx = fromIdx + count
+		more = true
+	}
+
+	result := make([]*dhcprelayd.DHCPv6RelayPDState, actualCount)
+	copy(result, pProc.PDStateSlice[fromIdx:fromIdx+actualCount])
+	return nextIdx, actualCount, more, result
+}
+
+// Prefixes are unique within a VRF only
+func getPDKey(vrf string, prefix string) string {
+	return vrf + "_" + prefix
+}
+
+func (pProc *Processor) GetPDState(
+	prefix string, vrf string) (*dhcprelayd.DHCPv6RelayPDState, bool) {
+
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	if pdState, ok := pProc.PDStateMap[getPDKey(vrf, prefix)]; ok {
+		return pdState, true
+	} else {
+		return nil, false
+	}
+}
+
+func newPDRoute(prefix *net.IPNet, nextHopIp string,
+	outIfIdx int, vrf string) *ribd.IPv6Route {
+
+	cfg := &ribd.IPv6Route{
+		DestinationNw: prefix.IP.String(),
+		Protocol:      PD_ROUTE_PROTOCOL,
+		Cost:          0,
+		NetworkMask:   net.IP(prefix.Mask).String(),
+		Vrf:           vrf,
+	}
+	nextHop := ribd.NextHopInfo{
+		NextHopIp:     nextHopIp,
+		NextHopIntRef: strconv.Itoa(outIfIdx),
+	}
+	cfg.NextHop = make([]*ribd.NextHopInfo, 0)
+	cfg.NextHop = append(cfg.NextHop, &nextHop)
+	return cfg
+}
+
+// Caller needs to hold StateMutex, returns the route to be removed from RIB
+func (pProc *Processor) deletePDState(pdKey string) *ribd.IPv6Route {
+	pdState, ok := pProc.PDStateMap[pdKey]
+	if !ok {
+		return nil
+	}
+	for i, entry := range pProc.PDStateSlice {
+		if entry == pdState {
+			pProc.PDStateSlice = append(pProc.PDStateSlice[:i],
+				pProc.PDStateSlice[i+1:]...)
+			break
+		}
+	}
+	route := pProc.PDRoutes[pdKey]
+	delete(pProc.PDStateMap, pdKey)
+	delete(pProc.PDExpiry, pdKey)
+	delete(pProc.PDRoutes, pdKey)
+	pProc.notifyStateChange()
+	if !pdState.RouteInstalled {
+		return nil
+	}
+	return route
+}
+
+// ribd is called without holding StateMutex
+func (pProc *Processor) installPDRoutes(pdKeys []string) {
+	ribdHdl, ok := pProc.InfraMgr.GetRibdHdl()
+	if !ok {
+		Logger.Debug("DRA: Not connected to ribd, delegated prefix routes pending")
+		return
+	}
+	for _, pdKey := range pdKeys {
+		pProc.StateMutex.Lock()
+		route, ok := pProc.PDRoutes[pdKey]
+		pProc.StateMutex.Unlock()
+		if !ok {
+			continue
+		}
+		_, err := ribdHdl.CreateIPv6Route(route)
+		if err != nil {
+			Logger.Err("DRA: Failed to install route for delegated prefix",
+				pdKey, err)
+			continue
+		}
+		pProc.StateMutex.Lock()
+		if pdState, ok := pProc.PDStateMap[pdKey]; ok &&
+			pProc.PDRoutes[pdKey] == route {
+			pdState.RouteInstalled = true
+			pProc.notifyStateChange()
+		}
+		pProc.StateMutex.Unlock()
+	}
+}
+
+// Same route as far as RIB is concerned, the ifIndex is not compared as it
+// is unknown for routes of interfaces gone during a restart
+func isSamePDRoute(a, b *ribd.IPv6Route) bool {
+	return a.DestinationNw == b.DestinationNw &&
+		a.NetworkMask == b.NetworkMask && a.Vrf == b.Vrf &&
+		a.NextHop[0].NextHopIp == b.NextHop[0].NextHopIp
+}
+
+// Caller needs to hold StateMutex, a removal still pending when the same
+// route was learnt again must not take the new route out of RIB
+func (pProc *Processor) isPDRouteInUse(route *ribd.IPv6Route) bool {
+	for _, pdRoute := range pProc.PDRoutes {
+		if isSamePDRoute(pdRoute, route) {
+			return true
+		}
+	}
+	return false
+}
+
+// ribd is called without holding StateMutex. Routes ribd did not remove are
+// kept and retried by the next aging run
+func (pProc *Processor) removePDRoutes(routes []*ribd.IPv6Route) {
+	if len(routes) == 0 {
+		return
+	}
+	var pendingRoutes []*ribd.IPv6Route
+	ribdHdl, ok := pProc.InfraMgr.GetRibdHdl()
+	if !ok {
+		Logger.Debug("DRA: Not connected to ribd, delegated prefix route",
+			"removals pending")
+		pendingRoutes = routes
+	} else {
+		for _, route := range routes {
+			pProc.StateMutex.Lock()
+			inUse := pProc.isPDRouteInUse(route)
+			pProc.StateMutex.Unlock()
+			if inUse {
+				continue
+			}
+			_, err := ribdHdl.DeleteIPv6Route(route)
+			if err != nil {
+				Logger.Err("DRA: Failed to remove route for delegated prefix",
+					route.DestinationNw, route.NetworkMask, err)
+				pendingRoutes = append(pendingRoutes, route)
+			}
+		}
+	}
+	if len(pendingRoutes) == 0 {
+		return
+	}
+	pProc.StateMutex.Lock()
+	pProc.PDPendingRemovals = append(pProc.PDPendingRemovals,
+		pendingRoutes...)
+	pProc.StateMutex.Unlock()
+}
+
+// Learns the prefixes delegated in a reply to the requesting router, which
+// is the peer the reply is relayed to. Routes are installed in the VRF of
+// the requesting router
+func (pProc *Processor) learnDelegatedPrefixes(outPkt DhcpPacket,
+	peerAddr net.IP, outIfName string, outIfIdx int, vrf string,
+	clientMacAddr string) {
+
+	var installPDKeys []string
+	var removeRoutes []*ribd.IPv6Route
+	nextHopIp := peerAddr.String()
+
+	pProc.StateMutex.Lock()
+	for _, iaPD := range outPkt.GetIAPDOptions() {
+		for _, iaPrefix := range iaPD.GetPrefixes() {
+			prefix := iaPrefix.GetPrefix()
+			if prefix == nil {
+				continue
+			}
+			prefixStr := prefix.String()
+			pdKey := getPDKey(vrf, prefixStr)
+			validLifetime := iaPrefix.GetValidLifetime()
+			if validLifetime == 0 {
+				// Server withdrew the delegation
+				if route := pProc.deletePDState(pdKey); route != nil {
+					removeRoutes = append(removeRoutes, route)
+				}
+				continue
+			}
+			pdState, ok := pProc.PDStateMap[pdKey]
+			if !ok {
+				pdState = &dhcprelayd.DHCPv6RelayPDState{}
+				pdState.Prefix = prefixStr
+				pdState.Vrf = vrf
+				pProc.PDStateSlice = append(pProc.PDStateSlice, pdState)
+				pProc.PDStateMap[pdKey] = pdState
+			}
+			if !ok || pdState.NextHopIp != nextHopIp ||
+				pdState.IntfRef != outIfName {
+				// Requesting router moved, route is replaced
+				if route, ok := pProc.PDRoutes[pdKey]; ok &&
+					pdState.RouteInstalled {
+					removeRoutes = append(removeRoutes, route)
+				}
+				pdState.RouteInstalled = false
+				pProc.PDRoutes[pdKey] = newPDRoute(
+					prefix, nextHopIp, outIfIdx, vrf)
+			}
+			if !pdState.RouteInstalled {
+				installPDKeys = append(installPDKeys, pdKey)
+			}
+			preferredLifetime := iaPrefix.GetPreferredLifetime()
+			if preferredLifetime > math.MaxInt32 {
+				preferredLifetime = math.MaxInt32
+			}
+			if validLifetime > math.MaxInt32 {
+				validLifetime = math.MaxInt32
+			}
+			expiry := time.Now().Add(time.Duration(validLifetime) * time.Second)
+			pdState.IntfRef = outIfName
+			pdState.NextHopIp = nextHopIp
+			pdState.ClientMacAddr = clientMacAddr
+			pdState.PreferredLifetime = int32(preferredLifetime)
+			pdState.ValidLifetime = int32(validLifetime)
+			pdState.LeaseExpiry = strconv.FormatInt(expiry.Unix(), 10)
+			pProc.PDExpiry[pdKey] = expiry
+			pProc.notifyStateChange()
+		}
+	}
+	pProc.StateMutex.Unlock()
+
+	pProc.removePDRoutes(removeRoutes)
+	pProc.installPDRoutes(installPDKeys)
+}
+
+// Requesting router gave up the prefixes listed in its release. Only
+// delegations learnt for the releasing router on the interface and in the
+// VRF the release came in on are removed
+func (pProc *Processor) releaseDelegatedPrefixes(inPkt DhcpPacket,
+	peerAddr net.IP, inIfName string, vrf string) {
+
+	var removeRoutes []*ribd.IPv6Route
+	nextHopIp := peerAddr.String()
+
+	pProc.StateMutex.Lock()
+	for _, iaPD := range inPkt.GetIAPDOptions() {
+		for _, iaPrefix := range iaPD.GetPrefixes() {
+			prefix := iaPrefix.GetPrefix()
+			if prefix == nil {
+				continue
+			}
+			pdKey := getPDKey(vrf, prefix.String())
+			pdState, ok := pProc.PDStateMap[pdKey]
+			if !ok || pdState.NextHopIp != nextHopIp ||
+				pdState.IntfRef != inIfName {
+				Logger.Debug("DRA: Ignoring release of prefix not delegated",
+					"to", nextHopIp, inIfName, pdKey)
+				continue
+			}
+			if route := pProc.deletePDState(pdKey); route != nil {
+				removeRoutes = append(removeRoutes, route)
+			}
+		}
+	}
+	pProc.StateMutex.Unlock()
+
+	pProc.removePDRoutes(removeRoutes)
+}
+
+// Removes the delegations learnt on the interface, except the ones of
+// keepVrf if set
+func (pProc *Processor) deleteIntfPDs(ifName string, keepVrf string) {
+	var removeRoutes []*ribd.IPv6Route
+
+	pProc.StateMutex.Lock()
+	for pdKey, pdState := range pProc.PDStateMap {
+		if pdState.IntfRef != ifName || pdState.Vrf == keepVrf {
+			continue
+		}
+		if route := pProc.deletePDState(pdKey); route != nil {
+			removeRoutes = append(removeRoutes, route)
+		}
+	}
+	pProc.StateMutex.Unlock()
+
+	pProc.removePDRoutes(removeRoutes)
+}
+
+// Removes expired delegations and retries routes ribd did not take or did
+// not remove
+func (pProc *Processor) agePDs() {
+	var installPDKeys []string
+
+	pProc.StateMutex.Lock()
+	removeRoutes := pProc.PDPendingRemovals
+	pProc.PDPendingRemovals = nil
+	now := time.Now()
+	for pdKey, expiry := range pProc.PDExpiry {
+		if now.After(expiry) {
+			Logger.Debug("DRA: Delegated prefix expired", pdKey)
+			if route := pProc.deletePDState(pdKey); route != nil {
+				removeRoutes = append(removeRoutes, route)
+			}
+		}
+	}
+	for pdKey, pdState := range pProc.PDStateMap {
+		if !pdState.RouteInstalled {
+			installPDKeys = append(installPDKeys, pdKey)
+		}
+	}
+	pProc.RateLimiter.AgeClients(now)
+	pProc.StateMutex.Unlock()
+
+	pProc.removePDRoutes(removeRoutes)
+	pProc.installPDRoutes(installPDKeys)
+}
+
+func (pProc *Processor) pdAging(quit chan bool) {
+	ticker := time.NewTicker(PD_AGING_INTERVAL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			pProc.agePDs()
+		case <-quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,335 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package dhcp6
+
+import (
+	"dhcprelayd"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"infra/sysd/sysdCommonDefs"
+	"l3/dhcp_relay/infra"
+	"log/syslog"
+	"net"
+	"ribd"
+	"strconv"
+	"testing"
+	"time"
+	asicdmock "utils/asicdClient/mock"
+	"utils/logging"
+)
+
+type ribdFake struct {
+	Routes map[string]*ribd.IPv6Route
+	// Returned by all calls when set
+	Err error
+}
+
+func (r *ribdFake) CreateIPv6Route(cfg *ribd.IPv6Route) (bool, error) {
+	if r.Err != nil {
+		return false, r.Err
+	}
+	r.Routes[cfg.DestinationNw] = cfg
+	return true, nil
+}
+
+func (r *ribdFake) DeleteIPv6Route(cfg *ribd.IPv6Route) (bool, error) {
+	if r.Err != nil {
+		return false, r.Err
+	}
+	delete(r.Routes, cfg.DestinationNw)
+	return true, nil
+}
+
+func NewLogger(name string, tag string) (*logging.Writer, error) {
+	var err error
+	srLogger := new(logging.Writer)
+	srLogger.MyComponentName = name
+
+	srLogger.SysLogger, err = syslog.New(syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+	if err != nil {
+		fmt.Println("Failed to initialize syslog - ", err)
+		return srLogger, err
+	}
+	srLogger.MyLogLevel = sysdCommonDefs.INFO
+	return srLogger, err
+}
+
+// Processor with interface eth1 (ifIndex 2) towards the requesting routers
+func initTestPDProcessor(t *testing.T) (*Processor, *ribdFake) {
+	lgr, err := NewLogger("dhcprelayd", "dhcprelayd")
+	if err != nil {
+		t.Fatal("Failed to initialize logger", err)
+	}
+	iMgr := infra.NewInfraMgr(lgr, &asicdmock.MockAsicdClientMgr{})
+	iMgr.IPv6IntfProps[2] = &infra.IPv6IntfProperty{
+		IpAddr:  "2001:db8:ffff::1",
+		Netmask: net.CIDRMask(64, 128),
+		IfIndex: 2,
+		IfRef:   "eth1",
+		State:   true,
+	}
+	iMgr.IPv6IfRefToIfIndex["eth1"] = 2
+	ribdHdl := &ribdFake{Routes: make(map[string]*ribd.IPv6Route)}
+	iMgr.SetRibdHdl(ribdHdl)
+	pProc := NewProcessor(&ProcessorInitParams{
+		Logger:   lgr,
+		InfraMgr: iMgr,
+	})
+	return pProc, ribdHdl
+}
+
+// Reply carrying a single IA_PD with the given prefix
+func newTestPDReply(prefix string, validLifetime uint32) DhcpPacket {
+	_, ipNet, _ := net.ParseCIDR(prefix)
+	prefixLen, _ := ipNet.Mask.Size()
+	iaPrefix := make([]byte, 4+25)
+	binary.BigEndian.PutUint16(iaPrefix[0:2], uint16(OPTION_IAPREFIX))
+	binary.BigEndian.PutUint16(iaPrefix[2:4], 25)
+	binary.BigEndian.PutUint32(iaPrefix[4:8], validLifetime/2)
+	binary.BigEndian.PutUint32(iaPrefix[8:12], validLifetime)
+	iaPrefix[12] = byte(prefixLen)
+	copy(iaPrefix[13:29], ipNet.IP.To16())
+	iaPD := make([]byte, 16, 16+len(iaPrefix))
+	binary.BigEndian.PutUint16(iaPD[0:2], uint16(OPTION_IA_PD))
+	binary.BigEndian.PutUint16(iaPD[2:4], uint16(12+len(iaPrefix)))
+	iaPD = append(iaPD, iaPrefix...)
+	pkt := DhcpPacket{byte(REPLY), 0x01, 0x02, 0x03}
+	return append(pkt, iaPD...)
+}
+
+func TestLearnDelegatedPrefixes(t *testing.T) {
+	pProc, ribdHdl := initTestPDProcessor(t)
+	pProc.learnDelegatedPrefixes(newTestPDReply("2001:db8:1::/48", 7200),
+		net.ParseIP("fe80::1"), "eth1", 2, "red", "00:00:00:00:00:01")
+	route, ok := ribdHdl.Routes["2001:db8:1::"]
+	if !ok {
+		t.Fatal("Route for delegated prefix not installed")
+	}
+	if route.Protocol != PD_ROUTE_PROTOCOL || route.Protocol == "STATIC" {
+		t.Error("Wrong route protocol", route.Protocol)
+	}
+	if route.Vrf != "red" {
+		t.Error("Route not installed in VRF of requesting router", route.Vrf)
+	}
+	if route.NextHop[0].NextHopIp != "fe80::1" ||
+		route.NextHop[0].NextHopIntRef != "2" {
+		t.Error("Wrong next hop", route.NextHop[0])
+	}
+	pdState, ok := pProc.GetPDState("2001:db8:1::/48", "red")
+	if !ok || !pdState.RouteInstalled || pdState.Vrf != "red" {
+		t.Fatal("Wrong delegated prefix state", pdState)
+	}
+	leaseExpiry, err := strconv.ParseInt(pdState.LeaseExpiry, 10, 64)
+	if err != nil || leaseExpiry < time.Now().Unix()+7100 {
+		t.Error("Wrong lease expiry", pdState.LeaseExpiry)
+	}
+	select {
+	case <-pProc.GetStateChangeCh():
+	default:
+		t.Error("State change not signalled")
+	}
+	// Zero valid lifetime withdraws the delegation
+	pProc.learnDelegatedPrefixes(newTestPDReply("2001:db8:1::/48", 0),
+		net.ParseIP("fe80::1"), "eth1", 2, "red", "00:00:00:00:00:01")
+	if _, ok := ribdHdl.Routes["2001:db8:1::"]; ok {
+		t.Error("Route of withdrawn delegation not removed")
+	}
+	if _, ok := pProc.GetPDState("2001:db8:1::/48", "red"); ok {
+		t.Error("Withdrawn delegation not removed")
+	}
+}
+
+func TestRestorePDState(t *testing.T) {
+	pProc, ribdHdl := initTestPDProcessor(t)
+	_, expiredNet, _ := net.ParseCIDR("2001:db8:2::/56")
+	ribdHdl.Routes["2001:db8:2::"] = newPDRoute(expiredNet, "fe80::2", 2,
+		infra.DEFAULT_VRF)
+	_, goneNet, _ := net.ParseCIDR("2001:db8:4::/64")
+	ribdHdl.Routes["2001:db8:4::"] = newPDRoute(goneNet, "fe80::4", 9,
+		infra.DEFAULT_VRF)
+	now := time.Now().Unix()
+	pdStates := []*dhcprelayd.DHCPv6RelayPDState{
+		&dhcprelayd.DHCPv6RelayPDState{
+			Prefix:         "2001:db8:1::/48",
+			IntfRef:        "eth1",
+			NextHopIp:      "fe80::1",
+			LeaseExpiry:    strconv.FormatInt(now+3600, 10),
+			RouteInstalled: true,
+			Vrf:            infra.DEFAULT_VRF,
+		},
+		&dhcprelayd.DHCPv6RelayPDState{
+			Prefix:         "2001:db8:2::/56",
+			IntfRef:        "eth1",
+			NextHopIp:      "fe80::2",
+			LeaseExpiry:    strconv.FormatInt(now-10, 10),
+			RouteInstalled: true,
+			Vrf:            infra.DEFAULT_VRF,
+		},
+		&dhcprelayd.DHCPv6RelayPDState{
+			Prefix:      "2001:db8:3::/64",
+			IntfRef:     "eth9",
+			NextHopIp:   "fe80::3",
+			LeaseExpiry: strconv.FormatInt(now+3600, 10),
+			Vrf:         infra.DEFAULT_VRF,
+		},
+		&dhcprelayd.DHCPv6RelayPDState{
+			Prefix:         "2001:db8:4::/64",
+			IntfRef:        "eth9",
+			NextHopIp:      "fe80::4",
+			LeaseExpiry:    strconv.FormatInt(now+3600, 10),
+			RouteInstalled: true,
+			Vrf:            infra.DEFAULT_VRF,
+		},
+	}
+	pProc.RestoreStateCheckpoint(&StateCheckpoint{PDStates: pdStates})
+	checkpoint := pProc.GetStateCheckpoint()
+	if len(checkpoint.PDStates) != 1 ||
+		checkpoint.PDStates[0].Prefix != "2001:db8:1::/48" {
+		t.Fatal("Wrong delegated prefixes restored", len(checkpoint.PDStates))
+	}
+	if _, ok := ribdHdl.Routes["2001:db8:2::"]; ok {
+		t.Error("Route of delegation expired during restart not removed")
+	}
+	if _, ok := ribdHdl.Routes["2001:db8:4::"]; ok {
+		t.Error("Route of delegation on interface gone not removed")
+	}
+	if _, ok := pProc.PDRoutes[getPDKey(infra.DEFAULT_VRF, "2001:db8:1::/48")]; !ok {
+		t.Error("Route of restored delegation not known")
+	}
+	if _, ok := pProc.PDExpiry[getPDKey(infra.DEFAULT_VRF, "2001:db8:1::/48")]; !ok {
+		t.Error("Restored delegation does not age")
+	}
+}
+
+func TestReleaseDelegatedPrefixes(t *testing.T) {
+	pProc, ribdHdl := initTestPDProcessor(t)
+	pProc.learnDelegatedPrefixes(newTestPDReply("2001:db8:1::/48", 7200),
+		net.ParseIP("fe80::1"), "eth1", 2, "red", "00:00:00:00:00:01")
+	release := newTestPDReply("2001:db8:1::/48", 7200)
+	release[0] = byte(RELEASE)
+	// Only the requesting router can release its delegation
+	pProc.releaseDelegatedPrefixes(release, net.ParseIP("fe80::2"),
+		"eth1", "red")
+	pProc.releaseDelegatedPrefixes(release, net.ParseIP("fe80::1"),
+		"eth2", "red")
+	pProc.releaseDelegatedPrefixes(release, net.ParseIP("fe80::1"),
+		"eth1", infra.DEFAULT_VRF)
+	if _, ok := pProc.GetPDState("2001:db8:1::/48", "red"); !ok {
+		t.Fatal("Delegation released by another router")
+	}
+	if _, ok := ribdHdl.Routes["2001:db8:1::"]; !ok {
+		t.Fatal("Route removed on release by another router")
+	}
+	pProc.releaseDelegatedPrefixes(release, net.ParseIP("fe80::1"),
+		"eth1", "red")
+	if _, ok := pProc.GetPDState("2001:db8:1::/48", "red"); ok {
+		t.Error("Released delegation not removed")
+	}
+	if _, ok := ribdHdl.Routes["2001:db8:1::"]; ok {
+		t.Error("Route of released delegation not removed")
+	}
+}
+
+func TestPDRouteRemovalRetry(t *testing.T) {
+	pProc, ribdHdl := initTestPDProcessor(t)
+	pProc.learnDelegatedPrefixes(newTestPDReply("2001:db8:1::/48", 7200),
+		net.ParseIP("fe80::1"), "eth1", 2, "red", "00:00:00:00:00:01")
+	pProc.learnDelegatedPrefixes(newTestPDReply("2001:db8:2::/48", 7200),
+		net.ParseIP("fe80::2"), "eth1", 2, "red", "00:00:00:00:00:02")
+	ribdHdl.Err = errors.New("ribd busy")
+	pProc.learnDelegatedPrefixes(newTestPDReply("2001:db8:1::/48", 0),
+		net.ParseIP("fe80::1"), "eth1", 2, "red", "00:00:00:00:00:01")
+	pProc.learnDelegatedPrefixes(newTestPDReply("2001:db8:2::/48", 0),
+		net.ParseIP("fe80::2"), "eth1", 2, "red", "00:00:00:00:00:02")
+	if _, ok := ribdHdl.Routes["2001:db8:1::"]; !ok {
+		t.Fatal("Route removed while ribd fails")
+	}
+	// Delegation learnt again before the retry keeps its route
+	ribdHdl.Err = nil
+	pProc.learnDelegatedPrefixes(newTestPDReply("2001:db8:2::/48", 7200),
+		net.ParseIP("fe80::2"), "eth1", 2, "red", "00:00:00:00:00:02")
+	pProc.agePDs()
+	if _, ok := ribdHdl.Routes["2001:db8:1::"]; ok {
+		t.Error("Pending route removal not retried")
+	}
+	if _, ok := ribdHdl.Routes["2001:db8:2::"]; !ok {
+		t.Error("Pending route removal took out route learnt again")
+	}
+	if len(pProc.PDPendingRemovals) != 0 {
+		t.Error("Route removals still pending", len(pProc.PDPendingRemovals))
+	}
+}
+
+// The same prefix is delegated independently in every VRF
+func TestDelegatedPrefixVrfs(t *testing.T) {
+	pProc, _ := initTestPDProcessor(t)
+	pProc.learnDelegatedPrefixes(newTestPDReply("2001:db8:1::/48", 7200),
+		net.ParseIP("fe80::1"), "eth1", 2, "red", "00:00:00:00:00:01")
+	pProc.learnDelegatedPrefixes(newTestPDReply("2001:db8:1::/48", 7200),
+		net.ParseIP("fe80::2"), "eth2", 3, "blue", "00:00:00:00:00:02")
+	redState, ok := pProc.GetPDState("2001:db8:1::/48", "red")
+	if !ok || redState.NextHopIp != "fe80::1" || redState.Vrf != "red" {
+		t.Fatal("Wrong delegated prefix state in red", redState)
+	}
+	blueState, ok := pProc.GetPDState("2001:db8:1::/48", "blue")
+	if !ok || blueState.NextHopIp != "fe80::2" || blueState.Vrf != "blue" {
+		t.Fatal("Wrong delegated prefix state in blue", blueState)
+	}
+	pProc.learnDelegatedPrefixes(newTestPDReply("2001:db8:1::/48", 0),
+		net.ParseIP("fe80::1"), "eth1", 2, "red", "00:00:00:00:00:01")
+	if _, ok := pProc.GetPDState("2001:db8:1::/48", "red"); ok {
+		t.Error("Withdrawn delegation not removed")
+	}
+	if _, ok := pProc.GetPDState("2001:db8:1::/48", "blue"); !ok {
+		t.Error("Delegation of another VRF withdrawn")
+	}
+}
+
+// Delegations of the VRF an interface left are removed
+func TestUpdateDRAIntfVrf(t *testing.T) {
+	pProc, ribdHdl := initTestPDProcessor(t)
+	pProc.InfraMgr.DRAv6Intfs["eth1"] = &dhcprelayd.DHCPv6RelayIntf{
+		IntfRef: "eth1",
+		Vrf:     "red",
+	}
+	pProc.learnDelegatedPrefixes(newTestPDReply("2001:db8:1::/48", 7200),
+		net.ParseIP("fe80::1"), "eth1", 2, "red", "00:00:00:00:00:01")
+	pProc.ProcessUpdateDRAIntf("eth1")
+	if _, ok := pProc.GetPDState("2001:db8:1::/48", "red"); !ok {
+		t.Fatal("Delegation removed without a VRF change")
+	}
+	pProc.InfraMgr.DRAv6Intfs["eth1"].Vrf = "blue"
+	pProc.ProcessUpdateDRAIntf("eth1")
+	if _, ok := pProc.GetPDState("2001:db8:1::/48", "red"); ok {
+		t.Error("Delegation of the VRF left not removed")
+	}
+	if _, ok := ribdHdl.Routes["2001:db8:1::"]; ok {
+		t.Error("Route of the VRF left not removed")
+	}
+	intfState, ok := pProc.GetIntfState("eth1")
+	if !ok || intfState.Vrf != "blue" {
+		t.Error("Interface state not moved to the new VRF", intfState)
+	}
+}
@@ -27,9 +27,9 @@ import (
 	"dhcprelayd"
 	//	"errors"
 	"fmt"
-	"golang.org/x/net/ipv6"
 	"l3/dhcp_relay/infra"
 	"net"
+	"ribd"
 	"strconv"
 	"sync"
 	"time"
@@ -39,10 +39,10 @@ import (
 var Logger logging.LoggerIntf
 
 type Processor struct {
-	Logger        logging.LoggerIntf
-	InfraMgr      *infra.InfraMgr
-	ClientHandler *net.UDPConn
-	ClientConn    *ipv6.PacketConn
+	Logger   logging.LoggerIntf
+	InfraMgr *infra.InfraMgr
+	// Sockets keyed by Vrf
+	VrfConns *infra.VrfConnMgr
 
 	PeerAddrIntfMap map[string]string
 
@@ -53,10 +53,22 @@ type Processor struct {
 	IntfStateMap         map[string]*dhcprelayd.DHCPv6RelayIntfState
 	IntfServerStateSlice []*dhcprelayd.DHCPv6RelayIntfServerState
 	IntfServerStateMap   map[string]*dhcprelayd.DHCPv6RelayIntfServerState
-	StateMutex           sync.Mutex
+	// Delegated prefixes keyed by Vrf + prefix
+	PDStateSlice []*dhcprelayd.DHCPv6RelayPDState
+	PDStateMap   map[string]*dhcprelayd.DHCPv6RelayPDState
+	PDExpiry     map[string]time.Time
+	PDRoutes     map[string]*ribd.IPv6Route
+	// Routes ribd has not removed yet, retried by aging
+	PDPendingRemovals []*ribd.IPv6Route
+	// Per intf and per client rate limits, serialized by the limiter
+	RateLimiter *infra.RateLimiter
+	StateMutex  sync.Mutex
+	// Signalled when state to be checkpointed changes
+	StateChangeCh chan bool
 
 	EnabledFlag  bool
 	EnabledMutex sync.Mutex
+	AgingQuit    chan bool
 }
 
 type ProcessorInitParams struct {
@@ -71,6 +83,10 @@ func NewProcessor(initParams *ProcessorInitParams) *Processor {
 		InfraMgr: initParams.InfraMgr,
 	}
 
+	pProc.VrfConns = infra.NewVrfConnMgr(pProc.CreateConn,
+		func(vrf string, conn infra.VrfConn) {
+			pProc.RxTx(vrf, conn.(*vrfConn))
+		})
 	pProc.PeerAddrIntfMap = make(map[string]string)
 	pProc.ClientStateSlice = []*dhcprelayd.DHCPv6RelayClientState{}
 	pProc.ClientStateMap = make(map[string]*dhcprelayd.DHCPv6RelayClientState)
@@ -78,6 +94,13 @@ func NewProcessor(initParams *ProcessorInitParams) *Processor {
 	pProc.IntfStateMap = make(map[string]*dhcprelayd.DHCPv6RelayIntfState)
 	pProc.IntfServerStateSlice = []*dhcprelayd.DHCPv6RelayIntfServerState{}
 	pProc.IntfServerStateMap = make(map[string]*dhcprelayd.DHCPv6RelayIntfServerState)
+	pProc.PDStateSlice = []*dhcprelayd.DHCPv6RelayPDState{}
+	pProc.PDStateMap = make(map[string]*dhcprelayd.DHCPv6RelayPDState)
+	pProc.PDExpiry = make(map[string]time.Time)
+	pProc.PDRoutes = make(map[string]*ribd.IPv6Route)
+	pProc.RateLimiter = infra.NewRateLimiter(
+		infra.CLIENT_RATE_LIMIT_MAX_CLIENTS)
+	pProc.StateChangeCh = make(chan bool, 1)
 
 	pProc.EnabledMutex.Lock()
 	pProc.EnabledFlag = false
@@ -223,20 +246,23 @@ func (pProc *Processor) GetIntfServerState(
 	}
 }
 
-func (pProc *Processor) StartRxTx() {
+// Sockets that fail to open are reported in the error and retried on the
+// next StartRxTx
+func (pProc *Processor) StartRxTx() error {
 	Logger.Debug("pProc: StartRxTx being called")
 
 	defer pProc.EnabledMutex.Unlock()
 	pProc.EnabledMutex.Lock()
 
-	if pProc.EnabledFlag {
-		return
-	}
-	if !pProc.CreateConn() {
-		return
+	if !pProc.EnabledFlag {
+		if pProc.AgingQuit == nil {
+			pProc.AgingQuit = make(chan bool)
+			go pProc.pdAging(pProc.AgingQuit)
+		}
+		pProc.EnabledFlag = true
 	}
-	go pProc.RxTx()
-	pProc.EnabledFlag = true
+	// Relay VRFs may have changed while enabled
+	return pProc.VrfConns.Sync(pProc.InfraMgr.GetActiveDRAv6Vrfs())
 }
 
 func (pProc *Processor) StopRxTx() {
@@ -244,13 +270,11 @@ func (pProc *Processor) StopRxTx() {
 
 	defer pProc.EnabledMutex.Unlock()
 	pProc.EnabledMutex.Lock()
-	// Close UDP socket connection of Rx thread
-	if pProc.ClientConn != nil {
-		err := pProc.ClientConn.Close()
-		if err != nil {
-			Logger.Err("Error while closing socket")
-		}
-		pProc.ClientConn = nil
+	// Close UDP socket connections of Rx threads
+	pProc.VrfConns.CloseAll()
+	if pProc.AgingQuit != nil {
+		close(pProc.AgingQuit)
+		pProc.AgingQuit = nil
 	}
 	pProc.EnabledFlag = false
 }
@@ -273,7 +297,7 @@ func (pProc *Processor) initClientState(
 }
 
 func (pProc *Processor) initIntfState(
-	ifName string) *dhcprelayd.DHCPv6RelayIntfState {
+	ifName string, vrf string) *dhcprelayd.DHCPv6RelayIntfState {
 
 	defer pProc.StateMutex.Unlock()
 	pProc.StateMutex.Lock()
@@ -285,6 +309,7 @@ func (pProc *Processor) initIntfState(
 		pProc.IntfStateSlice = append(pProc.IntfStateSlice, intfState)
 		pProc.IntfStateMap[ifName] = intfState
 	}
+	intfState.Vrf = vrf
 	return intfState
 }
 
@@ -315,6 +340,9 @@ func (pProc *Processor) deleteIntfState(
 func (pProc *Processor) initIntfServerState(
 	ifName string, serverAddr string) *dhcprelayd.DHCPv6RelayIntfServerState {
 
+	serverVrf := pProc.InfraMgr.GetDRAv6ServerVrf(
+		pProc.InfraMgr.GetDRAv6IntfVrf(ifName))
+
 	defer pProc.StateMutex.Unlock()
 	pProc.StateMutex.Lock()
 
@@ -328,6 +356,7 @@ func (pProc *Processor) initIntfServerState(
 			pProc.IntfServerStateSlice, intfServerState)
 		pProc.IntfServerStateMap[intfServerStateKey] = intfServerState
 	}
+	intfServerState.Vrf = serverVrf
 	return intfServerState
 }
 
@@ -344,6 +373,7 @@ func (pProc *Processor) setDownstreamInState(
 	if mType == RELAY_REPL {
 		return
 	}
+	clientState.Vrf = intfState.Vrf
 	clientState.ServerResponses++
 
 	switch mType {
@@ -382,10 +412,39 @@ func (pProc *Processor) setDownstreamInState(
 	}
 }
 
+// Interface-ID echoed by the server takes precedence over the interface
+// learnt for the peer address
+func (pProc *Processor) getDownstreamIntf(
+	peerAddr string, interfaceId []byte) (string, bool) {
+
+	peerIfName, peerOk := pProc.PeerAddrIntfMap[peerAddr]
+	if interfaceId == nil {
+		return peerIfName, peerOk
+	}
+	ifName := string(interfaceId)
+	_, ok := pProc.InfraMgr.GetIPv6IntfIndex(ifName)
+	if ok && (!peerOk || peerIfName == ifName) {
+		return ifName, true
+	}
+	Logger.Debug("DRA: Interface-ID", ifName,
+		"does not match interface", peerIfName, "of peer", peerAddr)
+	if ok {
+		peerIfName = ifName
+	} else if !peerOk {
+		return "", false
+	}
+	intfState := pProc.initIntfState(peerIfName,
+		pProc.InfraMgr.GetDRAv6IntfVrf(peerIfName))
+	pProc.StateMutex.Lock()
+	intfState.InterfaceIdMismatches++
+	pProc.StateMutex.Unlock()
+	return peerIfName, true
+}
+
 // Downstream: Server -> Client
 func (pProc *Processor) SendPktDownstream(
 	outPkt []byte, dhcpOptions DhcpOptionMap, peerAddr net.IP, srcAddr net.IP,
-	clientState *dhcprelayd.DHCPv6RelayClientState) {
+	interfaceId []byte, clientState *dhcprelayd.DHCPv6RelayClientState) {
 
 	mType := MsgType(outPkt[0])
 	peerAddrString := peerAddr.String()
@@ -396,7 +455,7 @@ func (pProc *Processor) SendPktDownstream(
 		peerPort = DHCP_CLIENT_PORT
 	}
 
-	outIfName, ok := pProc.PeerAddrIntfMap[peerAddrString]
+	outIfName, ok := pProc.getDownstreamIntf(peerAddrString, interfaceId)
 	if !ok {
 		Logger.Debug("DRA: No out interface found for peer Ip", peerAddrString)
 		return
@@ -407,7 +466,8 @@ func (pProc *Processor) SendPktDownstream(
 		return
 	}
 
-	intfState := pProc.initIntfState(outIfName)
+	outVrf := pProc.InfraMgr.GetDRAv6IntfVrf(outIfName)
+	intfState := pProc.initIntfState(outIfName, outVrf)
 	intfServerState := pProc.initIntfServerState(outIfName, srcAddr.String())
 	if mType != RELAY_REPL {
 		pProc.setDownstreamInState(mType, dhcpOptions, clientState, intfState)
@@ -428,7 +488,8 @@ func (pProc *Processor) SendPktDownstream(
 
 	Logger.Debug("DRA: destIpPortString", destIpPortString)
 	pProc.StateMutex.Lock()
-	if pProc.SendPkt(outPkt, destIpPortString) {
+	txOk := pProc.SendPkt(outVrf, outPkt, destIpPortString)
+	if txOk {
 		intfState.TotalDhcpClientTx++
 		intfServerState.Responses++
 		if mType != RELAY_REPL {
@@ -438,6 +499,11 @@ func (pProc *Processor) SendPktDownstream(
 		intfState.TotalDrops++
 	}
 	pProc.StateMutex.Unlock()
+	// Only the relay next to the requesting router routes its prefixes
+	if txOk && mType == REPLY {
+		pProc.learnDelegatedPrefixes(DhcpPacket(outPkt), peerAddr,
+			outIfName, outIfIdx, outVrf, clientState.MacAddr)
+	}
 }
 
 func (pProc *Processor) setUpstreamInState(
@@ -453,6 +519,7 @@ func (pProc *Processor) setUpstreamInState(
 	if mType == RELAY_FORW {
 		return
 	}
+	clientState.Vrf = intfState.Vrf
 	clientState.ClientRequests++
 
 	switch mType {
@@ -488,9 +555,15 @@ func (pProc *Processor) setUpstreamInState(
 	}
 }
 
-func (pProc *Processor) SendPkt(outPkt []byte, destIpPort string) bool {
+func (pProc *Processor) SendPkt(
+	vrf string, outPkt []byte, destIpPort string) bool {
+
+	vConn, ok := pProc.getVrfConn(vrf)
+	if !ok {
+		return false
+	}
 	destAddr, _ := net.ResolveUDPAddr("udp6", destIpPort)
-	_, err := pProc.ClientHandler.WriteToUDP(outPkt, destAddr)
+	_, err := vConn.Handler.WriteToUDP(outPkt, destAddr)
 	if err != nil {
 		Logger.Debug("DRA: WriteToUDP failed with error:", err)
 		return false
@@ -515,13 +588,16 @@ func (pProc *Processor) SendPktUpstream(
 		return
 	}
 
+	// Servers are reached in the server VRF of the relay VRF
+	serverVrf := pProc.InfraMgr.GetDRAv6ServerVrf(
+		infra.GetDRAv6IntfVrf(draIntf))
 	txOk := false
 	for _, destIpAddr := range draIntf.ServerIp {
 		destIpPortString := fmt.Sprintf(
 			"[%s]:%s", destIpAddr, strconv.Itoa(DHCP_SERVER_PORT))
 		intfServerState := pProc.initIntfServerState(inIfName, destIpAddr)
 		pProc.StateMutex.Lock() // State obj lock
-		if pProc.SendPkt(outPkt, destIpPortString) {
+		if pProc.SendPkt(serverVrf, outPkt, destIpPortString) {
 			txOk = true
 			intfState.TotalDhcpServerTx++
 			if mType != RELAY_FORW {
@@ -543,7 +619,7 @@ func (pProc *Processor) SendPktUpstream(
 			"[%s]:%s", destIpAddr, strconv.Itoa(DHCP_SERVER_PORT))
 		intfServerState := pProc.initIntfServerState(inIfName, destIpAddr)
 		pProc.StateMutex.Lock() // State obj lock
-		if pProc.SendPkt(outPkt, destIpPortString) {
+		if pProc.SendPkt(serverVrf, outPkt, destIpPortString) {
 			txOk = true
 			intfState.TotalDhcpServerTx++
 			if mType != RELAY_FORW {
@@ -560,6 +636,35 @@ func (pProc *Processor) SendPktUpstream(
 	}
 }
 
+// Adds the Interface-ID and Remote-ID options configured on the incoming
+// interface to the relay forward message
+func (pProc *Processor) addRelayOptions(outPkt DhcpRelayPacket, inIfIdx int,
+	inIfName string, intfState *dhcprelayd.DHCPv6RelayIntfState) DhcpRelayPacket {
+
+	draIntf, ok := pProc.InfraMgr.GetActiveDRAv6Intf(inIfIdx)
+	if !ok {
+		return outPkt
+	}
+	if draIntf.InterfaceId {
+		outPkt = outPkt.AddOption(OPTION_INTERFACE_ID, []byte(inIfName))
+	}
+	if draIntf.RemoteId != "" {
+		outPkt = outPkt.AddOption(OPTION_REMOTE_ID, RemoteIdPayload(
+			uint32(draIntf.RemoteIdEnterpriseNum), []byte(draIntf.RemoteId)))
+	}
+
+	defer pProc.StateMutex.Unlock()
+	pProc.StateMutex.Lock()
+
+	if draIntf.InterfaceId {
+		intfState.InterfaceIdInserted++
+	}
+	if draIntf.RemoteId != "" {
+		intfState.RemoteIdInserted++
+	}
+	return outPkt
+}
+
 func (pProc *Processor) validateRxUpstream(
 	inIfIdx int, inIfName string, srcAddr net.IP) (net.IP, bool) {
 
@@ -578,7 +683,15 @@ func (pProc *Processor) validateRxUpstream(
 	return intfAddr, true
 }
 
-func (pProc *Processor) MakeSendTxPkt(
+func (pProc *Processor) getHopCountLimit(vrf string) uint8 {
+	hopCountLimit, ok := pProc.InfraMgr.GetDRAv6HopCountLimit(vrf)
+	if !ok || hopCountLimit <= 0 || hopCountLimit > HOP_COUNT_MAX {
+		return HOP_COUNT_LIMIT
+	}
+	return uint8(hopCountLimit)
+}
+
+func (pProc *Processor) MakeSendTxPkt(vrf string,
 	inIfIdx int, inIfName string, inPktBuf []byte, srcAddr net.IP) bool {
 
 	outPkt := DhcpRelayPacket(make([]byte, 34, 1500))
@@ -591,22 +704,31 @@ func (pProc *Processor) MakeSendTxPkt(
 		if !ok {
 			return false
 		}
-		intfState := pProc.initIntfState(inIfName)
 		clientMacAddr := DhcpPacket(inPktBuf).GetClientHwAddr()
 		if clientMacAddr == nil {
 			Logger.Err("Cannot get client mac address ")
 			return false
 		}
+		if !pProc.checkRateLimit(inIfIdx, inIfName, clientMacAddr.String(),
+			vrf) {
+			return false
+		}
+		intfState := pProc.initIntfState(inIfName, vrf)
 		clientState := pProc.initClientState(clientMacAddr.String())
 		dhcpOptions := DhcpPacket(inPktBuf).ParseOptions([]OptionType{})
 		pProc.setUpstreamInState(mType, dhcpOptions, intfState, clientState)
 		pProc.PeerAddrIntfMap[srcAddr.String()] = inIfName
+		if mType == RELEASE {
+			pProc.releaseDelegatedPrefixes(DhcpPacket(inPktBuf), srcAddr,
+				inIfName, vrf)
+		}
 
 		outPkt.SetMsgType(int8(RELAY_FORW))
 		outPkt.SetHopCount(1)
 		outPkt.SetLinkAddrField(inIfAddr)
 		outPkt.SetPeerAddrField(srcAddr)
 		outPkt := outPkt.AddRelayMsgOption(inPktBuf)
+		outPkt = pProc.addRelayOptions(outPkt, inIfIdx, inIfName, intfState)
 		pProc.SendPktUpstream(
 			outPkt, mType, inIfIdx, inIfName,
 			srcAddr, intfState, clientState)
@@ -616,12 +738,21 @@ func (pProc *Processor) MakeSendTxPkt(
 		if !ok {
 			return false
 		}
-		intfState := pProc.initIntfState(inIfName)
+		if !pProc.checkRateLimit(inIfIdx, inIfName, "", vrf) {
+			return false
+		}
+		intfState := pProc.initIntfState(inIfName, vrf)
 		pProc.setUpstreamInState(mType, nil, intfState, nil)
 		pProc.PeerAddrIntfMap[srcAddr.String()] = inIfName
 
 		tmpPkt := DhcpRelayPacket(inPktBuf)
-		if tmpPkt.GetHopCount() >= HOP_COUNT_LIMIT {
+		if tmpPkt.GetHopCount() >= pProc.getHopCountLimit(vrf) {
+			Logger.Debug("DRA: Dropping relay forward from", srcAddr,
+				"hop count", tmpPkt.GetHopCount(), "exceeded limit")
+			pProc.StateMutex.Lock() // State obj lock
+			intfState.HopCountDrops++
+			intfState.TotalDrops++
+			pProc.StateMutex.Unlock() // State obj unlock
 			return false
 		}
 		outPkt.SetMsgType(int8(RELAY_FORW))
@@ -631,6 +762,7 @@ func (pProc *Processor) MakeSendTxPkt(
 		}
 		outPkt.SetPeerAddrField(srcAddr)
 		outPkt := outPkt.AddRelayMsgOption(inPktBuf)
+		outPkt = pProc.addRelayOptions(outPkt, inIfIdx, inIfName, intfState)
 		pProc.SendPktUpstream(
 			outPkt, mType, inIfIdx, inIfName,
 			srcAddr, intfState, nil)
@@ -639,6 +771,10 @@ func (pProc *Processor) MakeSendTxPkt(
 		inPkt := DhcpRelayPacket(inPktBuf)
 		peerAddr := net.IP(inPkt.GetPeerAddrField())
 		newOutPkt := inPkt.GetDRAOptionField().GetPayload()
+		var interfaceId []byte
+		if dOpt := inPkt.GetOptionField(OPTION_INTERFACE_ID); dOpt != nil {
+			interfaceId = dOpt.GetPayload()
+		}
 		outMsgType := MsgType(newOutPkt[0])
 		if outMsgType != RELAY_REPL {
 			clientMacAddr := DhcpPacket(inPktBuf).GetClientHwAddr()
@@ -648,21 +784,21 @@ func (pProc *Processor) MakeSendTxPkt(
 			}
 			clientState := pProc.initClientState(clientMacAddr.String())
 			dhcpOptions := DhcpPacket(newOutPkt).ParseOptions([]OptionType{})
-			pProc.SendPktDownstream(
-				newOutPkt, dhcpOptions, peerAddr, srcAddr, clientState)
+			pProc.SendPktDownstream(newOutPkt, dhcpOptions, peerAddr,
+				srcAddr, interfaceId, clientState)
 		} else {
 			pProc.SendPktDownstream(
-				newOutPkt, nil, peerAddr, srcAddr, nil)
+				newOutPkt, nil, peerAddr, srcAddr, interfaceId, nil)
 		}
 	}
 	return true
 }
 
-func (pProc *Processor) RxTx() {
+func (pProc *Processor) RxTx(vrf string, vConn *vrfConn) {
 	var buf DhcpPacket = make([]byte, 1500)
 	for {
-		Logger.Debug("DRA: Calling ReadFrom")
-		bytesRead, cm, srcAddr, err := pProc.ClientConn.ReadFrom(buf)
+		Logger.Debug("DRA: Calling ReadFrom in Vrf", vrf)
+		bytesRead, cm, srcAddr, err := vConn.Conn.ReadFrom(buf)
 		if err != nil {
 			Logger.Err("DRA: reading buffer failed")
 			break
@@ -684,33 +820,11 @@ func (pProc *Processor) RxTx() {
 			continue
 		}
 		srcUdpAddr, _ := net.ResolveUDPAddr("udp6", srcAddr.String())
-		pProc.MakeSendTxPkt(inIfIdx, inIf.Name, buf[:bytesRead], srcUdpAddr.IP)
-	}
-	pProc.EnabledMutex.Lock()
-	pProc.EnabledFlag = false
-	pProc.EnabledMutex.Unlock()
-}
-
-func (pProc *Processor) CreateConn() bool {
-	saddr := net.UDPAddr{
-		IP:   net.ParseIP(""),
-		Port: DHCP_SERVER_PORT,
-	}
-	var err error
-	pProc.ClientHandler, err = net.ListenUDP("udp6", &saddr)
-	if err != nil {
-		Logger.Err("Opening udp port for client --> server failed", err)
-		return false
+		pProc.MakeSendTxPkt(vrf, inIfIdx, inIf.Name, buf[:bytesRead],
+			srcUdpAddr.IP)
 	}
-	pProc.ClientConn = ipv6.NewPacketConn(pProc.ClientHandler)
-	controlFlag := ipv6.FlagSrc | ipv6.FlagDst | ipv6.FlagInterface
-	err = pProc.ClientConn.SetControlMessage(controlFlag, true)
-	if err != nil {
-		Logger.Err("Setting control flag for client failed..", err)
-		return false
-	}
-	Logger.Debug("Client Connection opened successfully")
-	return true
+	// Reopened on the next StartRxTx
+	pProc.VrfConns.Release(vrf, vConn)
 }
 
 func (pProc *Processor) RegisterMcast(ifRef string) {
@@ -719,8 +833,12 @@ func (pProc *Processor) RegisterMcast(ifRef string) {
 		Logger.Debug("Cannot find interface:", ifRef)
 		return
 	}
+	vConn, ok := pProc.getVrfConn(pProc.InfraMgr.GetDRAv6IntfVrf(ifRef))
+	if !ok {
+		return
+	}
 	Logger.Debug("Registering Mcast for intf", ifRef)
-	err = pProc.ClientConn.JoinGroup(
+	err = vConn.Conn.JoinGroup(
 		ifObj, &net.UDPAddr{IP: net.ParseIP(ALL_DRA_SERVERS_ADDR)},
 	)
 	if err != nil {
@@ -738,9 +856,13 @@ func (pProc *Processor) DeregisterMcast(ifRef string) {
 		Logger.Debug("Cannot find interface:", ifRef)
 		return
 	}
+	vConn, ok := pProc.getVrfConn(pProc.InfraMgr.GetDRAv6IntfVrf(ifRef))
+	if !ok {
+		return
+	}
 	Logger.Debug("Deregistering Mcast for intf", ifRef)
 
-	err = pProc.ClientConn.LeaveGroup(
+	err = vConn.Conn.LeaveGroup(
 		ifObj, &net.UDPAddr{IP: net.ParseIP(ALL_DRA_SERVERS_ADDR)},
 	)
 	if err != nil {
@@ -753,11 +875,20 @@ func (pProc *Processor) DeregisterMcast(ifRef string) {
 }
 
 func (pProc *Processor) ProcessCreateDRAIntf(ifName string) {
-	pProc.initIntfState(ifName)
+	pProc.initIntfState(ifName, pProc.InfraMgr.GetDRAv6IntfVrf(ifName))
+}
+
+// Delegations learnt on the interface in a VRF it left are removed
+func (pProc *Processor) ProcessUpdateDRAIntf(ifName string) {
+	vrf := pProc.InfraMgr.GetDRAv6IntfVrf(ifName)
+	pProc.initIntfState(ifName, vrf)
+	pProc.deleteIntfPDs(ifName, vrf)
 }
 
 func (pProc *Processor) ProcessDeleteDRAIntf(ifName string) {
 	pProc.deleteIntfState(ifName)
+	pProc.deleteIntfPDs(ifName, "")
+	pProc.RateLimiter.DeleteIntf(ifName)
 }
 
 func (pProc *Processor) ProcessActiveDRAIntf(ifIdx int) {
@@ -0,0 +1,72 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package dhcp6
+
+import (
+	"golang.org/x/net/ipv6"
+	"l3/dhcp_relay/infra"
+	"net"
+)
+
+// UDP socket of a relay VRF, bound to the VRF device
+type vrfConn struct {
+	Handler *net.UDPConn
+	Conn    *ipv6.PacketConn
+}
+
+func (vConn *vrfConn) Close() error {
+	return vConn.Conn.Close()
+}
+
+func (pProc *Processor) CreateConn(vrf string) (infra.VrfConn, error) {
+	saddr := net.UDPAddr{
+		IP:   net.ParseIP(""),
+		Port: DHCP_SERVER_PORT,
+	}
+	handler, err := infra.ListenUDPInVrf("udp6", vrf, &saddr)
+	if err != nil {
+		Logger.Err("Opening udp port for client --> server failed in Vrf",
+			vrf, err)
+		return nil, err
+	}
+	conn := ipv6.NewPacketConn(handler)
+	controlFlag := ipv6.FlagSrc | ipv6.FlagDst | ipv6.FlagInterface
+	err = conn.SetControlMessage(controlFlag, true)
+	if err != nil {
+		Logger.Err("Setting control flag for client failed..", err)
+		conn.Close()
+		return nil, err
+	}
+	Logger.Debug("Client Connection opened successfully in Vrf", vrf)
+	return &vrfConn{Handler: handler, Conn: conn}, nil
+}
+
+func (pProc *Processor) getVrfConn(vrf string) (*vrfConn, bool) {
+	conn, ok := pProc.VrfConns.Get(vrf)
+	if !ok {
+		Logger.Debug("DRA: No socket in Vrf", vrf)
+		return nil, false
+	}
+	return conn.(*vrfConn), true
+}
@@ -245,6 +245,116 @@ func (rpcHdl *rpcServiceHandler) GetBulkDHCPRelayIntfServerState(fromIdx, count
 	return result.Obj.(*server.GetBulkDHCPRelayIntfServerStateOutArgs).Obj, result.Error
 }
 
+func (rpcHdl *rpcServiceHandler) GetDHCPRelayBindingState(key1 string, key2 string) (obj *dhcprelayd.DHCPRelayBindingState, err error) {
+	rpcHdl.logger.Info("Calling GetDHCPRelayBindingState", key1, key2)
+
+	rpcHdl.dmnServer.ReqChan <- &server.ServerRequest{
+		Op: server.GET_DHCPRELAY_BINDING_STATE,
+		Data: interface{}(&server.GetDHCPRelayBindingStateInArgs{
+			MacAddr: key1,
+			XId:     key2,
+		}),
+	}
+
+	result := <-rpcHdl.dmnServer.ReplyChan
+	return result.Obj.(*server.GetDHCPRelayBindingStateOutArgs).Obj, result.Obj.(*server.GetDHCPRelayBindingStateOutArgs).Err
+}
+
+func (rpcHdl *rpcServiceHandler) GetBulkDHCPRelayBindingState(fromIdx, count dhcprelayd.Int) (*dhcprelayd.DHCPRelayBindingStateGetInfo, error) {
+	rpcHdl.dmnServer.ReqChan <- &server.ServerRequest{
+		Op: server.GETBLK_DHCPRELAY_BINDING_STATE,
+		Data: interface{}(&server.GetBulkInArgs{
+			FromIdx: int(fromIdx),
+			Count:   int(count),
+		}),
+	}
+
+	result := <-rpcHdl.dmnServer.ReplyChan
+	return result.Obj.(*server.GetBulkDHCPRelayBindingStateOutArgs).Obj, result.Error
+}
+
+func (rpcHdl *rpcServiceHandler) CreateDHCPRelaySnoopingIntf(
+	cfg *dhcprelayd.DHCPRelaySnoopingIntf) (bool, error) {
+
+	rpcHdl.logger.Info("Calling CreateDHCPRelaySnoopingIntf", cfg)
+
+	//Send message to server
+	rpcHdl.dmnServer.ReqChan <- &server.ServerRequest{
+		Op: server.CREATE_DHCPRELAY_SNOOPING_INTF,
+		Data: interface{}(&server.CreateDHCPRelaySnoopingIntfInArgs{
+			DHCPRelaySnoopingIntf: cfg,
+		}),
+	}
+
+	result := <-rpcHdl.dmnServer.ReplyChan
+	return result.Obj.(bool), result.Error
+}
+
+func (rpcHdl *rpcServiceHandler) UpdateDHCPRelaySnoopingIntf(
+	oldCfg, newCfg *dhcprelayd.DHCPRelaySnoopingIntf, attrset []bool,
+	op []*dhcprelayd.PatchOpInfo) (bool, error) {
+
+	rpcHdl.logger.Info("Calling UpdateDHCPRelaySnoopingIntf", oldCfg, newCfg,
+		attrset, op)
+
+	//Send message to server
+	rpcHdl.dmnServer.ReqChan <- &server.ServerRequest{
+		Op: server.UPDATE_DHCPRELAY_SNOOPING_INTF,
+		Data: interface{}(&server.UpdateDHCPRelaySnoopingIntfInArgs{
+			DHCPRelaySnoopingIntfOld: oldCfg,
+			DHCPRelaySnoopingIntfNew: newCfg,
+			AttrSet:                  attrset,
+		}),
+	}
+
+	result := <-rpcHdl.dmnServer.ReplyChan
+	return result.Obj.(bool), result.Error
+}
+
+func (rpcHdl *rpcServiceHandler) DeleteDHCPRelaySnoopingIntf(
+	cfg *dhcprelayd.DHCPRelaySnoopingIntf) (bool, error) {
+
+	rpcHdl.logger.Info("Calling DeleteDHCPRelaySnoopingIntf", cfg)
+
+	//Send message to server
+	rpcHdl.dmnServer.ReqChan <- &server.ServerRequest{
+		Op: server.DELETE_DHCPRELAY_SNOOPING_INTF,
+		Data: interface{}(&server.DeleteDHCPRelaySnoopingIntfInArgs{
+			IntfRef: cfg.IntfRef,
+		}),
+	}
+
+	result := <-rpcHdl.dmnServer.ReplyChan
+	return result.Obj.(bool), result.Error
+}
+
+func (rpcHdl *rpcServiceHandler) GetDHCPRelaySnoopingBindingState(key string) (obj *dhcprelayd.DHCPRelaySnoopingBindingState, err error) {
+	rpcHdl.logger.Info("Calling GetDHCPRelaySnoopingBindingState", key)
+
+	rpcHdl.dmnServer.ReqChan <- &server.ServerRequest{
+		Op: server.GET_DHCPRELAY_SNOOPING_BINDING_STATE,
+		Data: interface{}(&server.GetDHCPRelaySnoopingBindingStateInArgs{
+			MacAddr: key,
+		}),
+	}
+
+	result := <-rpcHdl.dmnServer.ReplyChan
+	return result.Obj.(*server.GetDHCPRelaySnoopingBindingStateOutArgs).Obj, result.Obj.(*server.GetDHCPRelaySnoopingBindingStateOutArgs).Err
+}
+
+func (rpcHdl *rpcServiceHandler) GetBulkDHCPRelaySnoopingBindingState(fromIdx, count dhcprelayd.Int) (*dhcprelayd.DHCPRelaySnoopingBindingStateGetInfo, error) {
+	rpcHdl.dmnServer.ReqChan <- &server.ServerRequest{
+		Op: server.GETBLK_DHCPRELAY_SNOOPING_BINDING_STATE,
+		Data: interface{}(&server.GetBulkInArgs{
+			FromIdx: int(fromIdx),
+			Count:   int(count),
+		}),
+	}
+
+	result := <-rpcHdl.dmnServer.ReplyChan
+	return result.Obj.(*server.GetBulkDHCPRelaySnoopingBindingStateOutArgs).Obj, result.Error
+}
+
 func (rpcHdl *rpcServiceHandler) CreateDHCPv6RelayGlobal(
 	cfg *dhcprelayd.DHCPv6RelayGlobal) (bool, error) {
 
@@ -461,3 +571,31 @@ func (rpcHdl *rpcServiceHandler) GetBulkDHCPv6RelayIntfServerState(fromIdx, coun
 	result := <-rpcHdl.dmnServer.ReplyChan
 	return result.Obj.(*server.GetBulkDHCPv6RelayIntfServerStateOutArgs).Obj, result.Error
 }
+
+func (rpcHdl *rpcServiceHandler) GetDHCPv6RelayPDState(key1 string, key2 string) (obj *dhcprelayd.DHCPv6RelayPDState, err error) {
+	rpcHdl.logger.Info("Calling GetDHCPv6RelayPDState", key1, key2)
+
+	rpcHdl.dmnServer.ReqChan <- &server.ServerRequest{
+		Op: server.GET_DHCPV6RELAY_PD_STATE,
+		Data: interface{}(&server.GetDHCPv6RelayPDStateInArgs{
+			Prefix: key1,
+			Vrf:    key2,
+		}),
+	}
+
+	result := <-rpcHdl.dmnServer.ReplyChan
+	return result.Obj.(*server.GetDHCPv6RelayPDStateOutArgs).Obj, result.Obj.(*server.GetDHCPv6RelayPDStateOutArgs).Err
+}
+
+func (rpcHdl *rpcServiceHandler) GetBulkDHCPv6RelayPDState(fromIdx, count dhcprelayd.Int) (*dhcprelayd.DHCPv6RelayPDStateGetInfo, error) {
+	rpcHdl.dmnServer.ReqChan <- &server.ServerRequest{
+		Op: server.GETBLK_DHCPV6RELAY_PD_STATE,
+		Data: interface{}(&server.GetBulkInArgs{
+			FromIdx: int(fromIdx),
+			Count:   int(count),
+		}),
+	}
+
+	result := <-rpcHdl.dmnServer.ReplyChan
+	return result.Obj.(*server.GetBulkDHCPv6RelayPDStateOutArgs).Obj, result.Error
+}
@@ -65,6 +65,19 @@ const ( // Request message codes
 
 	GET_DHCPV6RELAY_INTFSERVER_STATE
 	GETBLK_DHCPV6RELAY_INTFSERVER_STATE
+
+	GET_DHCPRELAY_BINDING_STATE
+	GETBLK_DHCPRELAY_BINDING_STATE
+
+	CREATE_DHCPRELAY_SNOOPING_INTF
+	UPDATE_DHCPRELAY_SNOOPING_INTF
+	DELETE_DHCPRELAY_SNOOPING_INTF
+
+	GET_DHCPRELAY_SNOOPING_BINDING_STATE
+	GETBLK_DHCPRELAY_SNOOPING_BINDING_STATE
+
+	GET_DHCPV6RELAY_PD_STATE
+	GETBLK_DHCPV6RELAY_PD_STATE
 )
 
 type ServerRequest struct {
@@ -158,6 +171,52 @@ type GetBulkDHCPRelayIntfServerStateOutArgs struct {
 	Err error
 }
 
+// Client Binding State
+type GetDHCPRelayBindingStateInArgs struct {
+	MacAddr string
+	XId     string
+}
+
+type GetDHCPRelayBindingStateOutArgs struct {
+	Obj *dhcprelayd.DHCPRelayBindingState
+	Err error
+}
+
+type GetBulkDHCPRelayBindingStateOutArgs struct {
+	Obj *dhcprelayd.DHCPRelayBindingStateGetInfo
+	Err error
+}
+
+// DHCP Snooping Interface Config
+type CreateDHCPRelaySnoopingIntfInArgs struct {
+	DHCPRelaySnoopingIntf *dhcprelayd.DHCPRelaySnoopingIntf
+}
+
+type UpdateDHCPRelaySnoopingIntfInArgs struct {
+	DHCPRelaySnoopingIntfOld *dhcprelayd.DHCPRelaySnoopingIntf
+	DHCPRelaySnoopingIntfNew *dhcprelayd.DHCPRelaySnoopingIntf
+	AttrSet                  []bool
+}
+
+type DeleteDHCPRelaySnoopingIntfInArgs struct {
+	IntfRef string
+}
+
+// DHCP Snooping Binding State
+type GetDHCPRelaySnoopingBindingStateInArgs struct {
+	MacAddr string
+}
+
+type GetDHCPRelaySnoopingBindingStateOutArgs struct {
+	Obj *dhcprelayd.DHCPRelaySnoopingBindingState
+	Err error
+}
+
+type GetBulkDHCPRelaySnoopingBindingStateOutArgs struct {
+	Obj *dhcprelayd.DHCPRelaySnoopingBindingStateGetInfo
+	Err error
+}
+
 // DHCPv6 Relay Global Config
 type CreateDHCPv6RelayGlobalInArgs struct {
 	DHCPv6RelayGlobal *dhcprelayd.DHCPv6RelayGlobal
@@ -233,3 +292,19 @@ type GetBulkDHCPv6RelayIntfServerStateOutArgs struct {
 	Obj *dhcprelayd.DHCPv6RelayIntfServerStateGetInfo
 	Err error
 }
+
+// Delegated Prefix State
+type GetDHCPv6RelayPDStateInArgs struct {
+	Prefix string
+	Vrf    string
+}
+
+type GetDHCPv6RelayPDStateOutArgs struct {
+	Obj *dhcprelayd.DHCPv6RelayPDState
+	Err error
+}
+
+type GetBulkDHCPv6RelayPDStateOutArgs struct {
+	Obj *dhcprelayd.DHCPv6RelayPDStateGetInfo
+	Err error
+}
@@ -28,9 +28,13 @@ import (
 	"fmt"
 	"l3/dhcp_relay/infra"
 	"l3/dhcp_relay/manager"
+	"os"
+	"os/signal"
+	"syscall"
 	"utils/asicdClient"
 	"utils/commonDefs"
 	"utils/dbutils"
+	"utils/dmnBase"
 	"utils/eventUtils"
 	"utils/keepalive"
 	"utils/logging"
@@ -41,6 +45,8 @@ type DmnServer struct {
 	DbHdl          dbutils.DBIntf
 	Logger         logging.LoggerIntf
 	CfgFileName    string
+	ParamsDir      string
+	ClientsList    []dmnBase.ClientJson
 	InitCompleteCh chan bool
 
 	AsicdHdl         asicdClient.AsicdClientIntf
@@ -59,6 +65,8 @@ type DmnServer struct {
 type ServerInitParams struct {
 	DmnName     string
 	CfgFileName string
+	ParamsDir   string
+	ClientsList []dmnBase.ClientJson
 	DbHdl       dbutils.DBIntf
 	Logger      logging.LoggerIntf
 	// AsicdHdl   asicdClient.AsicdClientIntf
@@ -69,6 +77,8 @@ func NewDHCPRELAYDServer(initParams *ServerInitParams) *DmnServer {
 	srvr.DbHdl = initParams.DbHdl
 	srvr.Logger = initParams.Logger
 	srvr.CfgFileName = initParams.CfgFileName
+	srvr.ParamsDir = initParams.ParamsDir
+	srvr.ClientsList = initParams.ClientsList
 	srvr.InitCompleteCh = make(chan bool)
 	//Parse dhcprelayd manager file
 	//	cfgFileInfo, err := parseCfgFile(initParams.CfgFileName)
@@ -95,13 +105,35 @@ func (srvr *DmnServer) initServer() error {
 	}
 
 	srvr.IMgr = infra.NewInfraMgr(srvr.Logger, srvr.AsicdHdl)
+	srvr.IMgr.SetSwitchMac(srvr.AsicdHdl.GetSwitchMAC(srvr.ParamsDir))
+	srvr.initRibdAddr()
 	srvr.DMgr = manager.NewDRAMgr(srvr.Logger, srvr.DbHdl, srvr.IMgr)
 	if !srvr.DMgr.InitDRAMgr() {
 		return errors.New("Unable to initialize DHCP Relay Manager")
 	}
+	sigChan := make(chan os.Signal, 1)
+	signal.Notify(sigChan, syscall.SIGHUP)
+	go srvr.sigHandler(sigChan)
 	return nil
 }
 
+// SIGHUP is sent before the daemon gets restarted. Relay state is written
+// out a last time and the daemon exits, as relaying would go on without
+// being checkpointed and the state read back after the restart would be
+// stale
+func (srvr *DmnServer) sigHandler(sigChan <-chan os.Signal) {
+	signal := <-sigChan
+	switch signal {
+	case syscall.SIGHUP:
+		srvr.Logger.Info("Received SIGHUP signal")
+		srvr.DMgr.DeinitDRAMgr()
+		srvr.Logger.Info("Relay state checkpointed, exiting")
+		os.Exit(0)
+	default:
+		srvr.Logger.Err("Unhandled signal : ", signal)
+	}
+}
+
 func (srvr *DmnServer) initAsicdHandler() asicdClient.AsicdClientIntf {
 	nHdl, nMap := srvr.NewNotificationHdl(srvr, srvr.Logger.(*logging.Writer))
 	tmpHdl := commonDefs.AsicdClientStruct{
@@ -280,6 +312,87 @@ func (srvr *DmnServer) Serve() {
 					},
 					Error: nil,
 				}
+			case GET_DHCPRELAY_BINDING_STATE:
+				state, err := srvr.DMgr.GetDRAv4BindingState(
+					req.Data.(*GetDHCPRelayBindingStateInArgs).
+						MacAddr,
+					req.Data.(*GetDHCPRelayBindingStateInArgs).
+						XId,
+				)
+				srvr.ReplyChan <- &ServerReply{
+					Obj: &GetDHCPRelayBindingStateOutArgs{
+						Obj: state,
+						Err: err,
+					},
+					Error: nil,
+				}
+			case GETBLK_DHCPRELAY_BINDING_STATE:
+				blkState := srvr.DMgr.GetBulkDRAv4BindingState(
+					req.Data.(*GetBulkInArgs).FromIdx,
+					req.Data.(*GetBulkInArgs).Count,
+				)
+				srvr.ReplyChan <- &ServerReply{
+					Obj: &GetBulkDHCPRelayBindingStateOutArgs{
+						Obj: blkState,
+						Err: nil,
+					},
+					Error: nil,
+				}
+			case CREATE_DHCPRELAY_SNOOPING_INTF:
+				success, err := srvr.DMgr.CreateDRAv4SnoopingIntf(
+					req.Data.(*CreateDHCPRelaySnoopingIntfInArgs).
+						DHCPRelaySnoopingIntf,
+				)
+				srvr.ReplyChan <- &ServerReply{
+					Obj:   success,
+					Error: err,
+				}
+			case UPDATE_DHCPRELAY_SNOOPING_INTF:
+				success, err := srvr.DMgr.UpdateDRAv4SnoopingIntf(
+					req.Data.(*UpdateDHCPRelaySnoopingIntfInArgs).
+						DHCPRelaySnoopingIntfOld,
+					req.Data.(*UpdateDHCPRelaySnoopingIntfInArgs).
+						DHCPRelaySnoopingIntfNew,
+					req.Data.(*UpdateDHCPRelaySnoopingIntfInArgs).
+						AttrSet,
+					nil,
+				)
+				srvr.ReplyChan <- &ServerReply{
+					Obj:   success,
+					Error: err,
+				}
+			case DELETE_DHCPRELAY_SNOOPING_INTF:
+				success, err := srvr.DMgr.DeleteDRAv4SnoopingIntf(
+					req.Data.(*DeleteDHCPRelaySnoopingIntfInArgs).
+						IntfRef)
+				srvr.ReplyChan <- &ServerReply{
+					Obj:   success,
+					Error: err,
+				}
+			case GET_DHCPRELAY_SNOOPING_BINDING_STATE:
+				state, err := srvr.DMgr.GetDRAv4SnoopingBindingState(
+					req.Data.(*GetDHCPRelaySnoopingBindingStateInArgs).
+						MacAddr,
+				)
+				srvr.ReplyChan <- &ServerReply{
+					Obj: &GetDHCPRelaySnoopingBindingStateOutArgs{
+						Obj: state,
+						Err: err,
+					},
+					Error: nil,
+				}
+			case GETBLK_DHCPRELAY_SNOOPING_BINDING_STATE:
+				blkState := srvr.DMgr.GetBulkDRAv4SnoopingBindingState(
+					req.Data.(*GetBulkInArgs).FromIdx,
+					req.Data.(*GetBulkInArgs).Count,
+				)
+				srvr.ReplyChan <- &ServerReply{
+					Obj: &GetBulkDHCPRelaySnoopingBindingStateOutArgs{
+						Obj: blkState,
+						Err: nil,
+					},
+					Error: nil,
+				}
 			case CREATE_DHCPV6RELAY_GLOBAL:
 				success, err := srvr.DMgr.CreateDRAv6Global(
 					req.Data.(*CreateDHCPv6RelayGlobalInArgs).
@@ -416,6 +529,32 @@ func (srvr *DmnServer) Serve() {
 					},
 					Error: nil,
 				}
+			case GET_DHCPV6RELAY_PD_STATE:
+				state, err := srvr.DMgr.GetDRAv6PDState(
+					req.Data.(*GetDHCPv6RelayPDStateInArgs).
+						Prefix,
+					req.Data.(*GetDHCPv6RelayPDStateInArgs).
+						Vrf,
+				)
+				srvr.ReplyChan <- &ServerReply{
+					Obj: &GetDHCPv6RelayPDStateOutArgs{
+						Obj: state,
+						Err: err,
+					},
+					Error: nil,
+				}
+			case GETBLK_DHCPV6RELAY_PD_STATE:
+				blkState := srvr.DMgr.GetBulkDRAv6PDState(
+					req.Data.(*GetBulkInArgs).FromIdx,
+					req.Data.(*GetBulkInArgs).Count,
+				)
+				srvr.ReplyChan <- &ServerReply{
+					Obj: &GetBulkDHCPv6RelayPDStateOutArgs{
+						Obj: blkState,
+						Err: nil,
+					},
+					Error: nil,
+				}
 			}
 		case msg := <-srvr.AsicdSubSocketCh:
 			srvr.Logger.Info("Notification", msg)
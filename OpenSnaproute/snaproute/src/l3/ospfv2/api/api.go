@@ -80,6 +80,256 @@ func DeleteOspfv2Area(cfg *objects.Ospfv2Area) (bool, error) {
 	return false, errors.New("Error: Invalid response received from server during DeleteArea")
 }
 
+func CreateOspfv2AreaRange(cfg *objects.Ospfv2AreaRange) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.CREATE_OSPFV2_AREA_RANGE,
+		Data: interface{}(&server.CreateOspfv2AreaRangeInArgs{
+			Cfg: cfg,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.CreateConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during CreateAreaRange")
+}
+
+func UpdateOspfv2AreaRange(oldCfg, newCfg *objects.Ospfv2AreaRange, attrset []bool) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.UPDATE_OSPFV2_AREA_RANGE,
+		Data: interface{}(&server.UpdateOspfv2AreaRangeInArgs{
+			OldCfg:  oldCfg,
+			NewCfg:  newCfg,
+			AttrSet: attrset,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.UpdateConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during UpdateAreaRange")
+}
+
+func DeleteOspfv2AreaRange(cfg *objects.Ospfv2AreaRange) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.DELETE_OSPFV2_AREA_RANGE,
+		Data: interface{}(&server.DeleteOspfv2AreaRangeInArgs{
+			Cfg: cfg,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.DeleteConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during DeleteAreaRange")
+}
+
+func CreateOspfv2IntfAuthKey(cfg *objects.Ospfv2IntfAuthKey) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.CREATE_OSPFV2_INTF_AUTH_KEY,
+		Data: interface{}(&server.CreateOspfv2IntfAuthKeyInArgs{
+			Cfg: cfg,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.CreateConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during CreateIntfAuthKey")
+}
+
+func UpdateOspfv2IntfAuthKey(oldCfg, newCfg *objects.Ospfv2IntfAuthKey, attrset []bool) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.UPDATE_OSPFV2_INTF_AUTH_KEY,
+		Data: interface{}(&server.UpdateOspfv2IntfAuthKeyInArgs{
+			OldCfg:  oldCfg,
+			NewCfg:  newCfg,
+			AttrSet: attrset,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.UpdateConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during UpdateIntfAuthKey")
+}
+
+func CreateOspfv2Nbr(cfg *objects.Ospfv2Nbr) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.CREATE_OSPFV2_NBR,
+		Data: interface{}(&server.CreateOspfv2NbrInArgs{
+			Cfg: cfg,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.CreateConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during CreateNbr")
+}
+
+func UpdateOspfv2Nbr(oldCfg, newCfg *objects.Ospfv2Nbr, attrset []bool) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.UPDATE_OSPFV2_NBR,
+		Data: interface{}(&server.UpdateOspfv2NbrInArgs{
+			OldCfg:  oldCfg,
+			NewCfg:  newCfg,
+			AttrSet: attrset,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.UpdateConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during UpdateNbr")
+}
+
+func DeleteOspfv2Nbr(cfg *objects.Ospfv2Nbr) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.DELETE_OSPFV2_NBR,
+		Data: interface{}(&server.DeleteOspfv2NbrInArgs{
+			Cfg: cfg,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.DeleteConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during DeleteNbr")
+}
+
+func CreateOspfv2VirtualLink(cfg *objects.Ospfv2VirtualLink) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.CREATE_OSPFV2_VIRTUAL_LINK,
+		Data: interface{}(&server.CreateOspfv2VirtualLinkInArgs{
+			Cfg: cfg,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.CreateConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during CreateVirtualLink")
+}
+
+func UpdateOspfv2VirtualLink(oldCfg, newCfg *objects.Ospfv2VirtualLink, attrset []bool) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.UPDATE_OSPFV2_VIRTUAL_LINK,
+		Data: interface{}(&server.UpdateOspfv2VirtualLinkInArgs{
+			OldCfg:  oldCfg,
+			NewCfg:  newCfg,
+			AttrSet: attrset,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.UpdateConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during UpdateVirtualLink")
+}
+
+func DeleteOspfv2VirtualLink(cfg *objects.Ospfv2VirtualLink) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.DELETE_OSPFV2_VIRTUAL_LINK,
+		Data: interface{}(&server.DeleteOspfv2VirtualLinkInArgs{
+			Cfg: cfg,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.DeleteConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during DeleteVirtualLink")
+}
+
+func CreateOspfv2Redistribution(cfg *objects.Ospfv2Redistribution) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.CREATE_OSPFV2_REDISTRIBUTION,
+		Data: interface{}(&server.CreateOspfv2RedistributionInArgs{
+			Cfg: cfg,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.CreateConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during CreateRedistribution")
+}
+
+func UpdateOspfv2Redistribution(oldCfg, newCfg *objects.Ospfv2Redistribution, attrset []bool) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.UPDATE_OSPFV2_REDISTRIBUTION,
+		Data: interface{}(&server.UpdateOspfv2RedistributionInArgs{
+			OldCfg:  oldCfg,
+			NewCfg:  newCfg,
+			AttrSet: attrset,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.UpdateConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during UpdateRedistribution")
+}
+
+func DeleteOspfv2Redistribution(cfg *objects.Ospfv2Redistribution) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.DELETE_OSPFV2_REDISTRIBUTION,
+		Data: interface{}(&server.DeleteOspfv2RedistributionInArgs{
+			Cfg: cfg,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.DeleteConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during DeleteRedistribution")
+}
+
+func CreateOspfv2RouteMap(cfg *objects.Ospfv2RouteMap) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.CREATE_OSPFV2_ROUTE_MAP,
+		Data: interface{}(&server.CreateOspfv2RouteMapInArgs{
+			Cfg: cfg,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.CreateConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during CreateRouteMap")
+}
+
+func UpdateOspfv2RouteMap(oldCfg, newCfg *objects.Ospfv2RouteMap, attrset []bool) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.UPDATE_OSPFV2_ROUTE_MAP,
+		Data: interface{}(&server.UpdateOspfv2RouteMapInArgs{
+			OldCfg:  oldCfg,
+			NewCfg:  newCfg,
+			AttrSet: attrset,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.UpdateConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during UpdateRouteMap")
+}
+
+func DeleteOspfv2RouteMap(cfg *objects.Ospfv2RouteMap) (bool, error) {
+	svr.ReqChan <- &server.ServerRequest{
+		Op: server.DELETE_OSPFV2_ROUTE_MAP,
+		Data: interface{}(&server.DeleteOspfv2RouteMapInArgs{
+			Cfg: cfg,
+		}),
+	}
+	ret := <-svr.ReplyChan
+	if retObj, ok := ret.(*server.DeleteConfigOutArgs); ok {
+		return retObj.RetVal, retObj.Err
+	}
+	return false, errors.New("Error: Invalid response received from server during DeleteRouteMap")
+}
+
 func GetOspfv2AreaState(areaId uint32) (*objects.Ospfv2AreaState, error) {
 	svr.ReqChan <- &server.ServerRequest{
 		Op: server.GET_OSPFV2_AREA_STATE,
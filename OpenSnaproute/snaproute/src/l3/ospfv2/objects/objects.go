@@ -46,21 +46,53 @@ const (
 )
 
 const (
-	OSPFV2_AREA_UPDATE_ADMIN_STATE      = 0x1
-	OSPFV2_AREA_UPDATE_AUTH_TYPE        = 0x2
-	OSPFV2_AREA_UPDATE_IMPORT_AS_EXTERN = 0x3
+	AREA_TYPE_NORMAL_STR       string = "normal"
+	AREA_TYPE_STUB_STR         string = "stub"
+	AREA_TYPE_TOTALLY_STUB_STR string = "totallystub"
+	AREA_TYPE_NSSA_STR         string = "nssa"
+)
+
+const (
+	NSSA_TRANSLATOR_ROLE_CANDIDATE_STR string = "candidate"
+	NSSA_TRANSLATOR_ROLE_ALWAYS_STR    string = "always"
+)
+
+const (
+	AREA_TYPE_NORMAL       uint8 = 0
+	AREA_TYPE_STUB         uint8 = 1
+	AREA_TYPE_TOTALLY_STUB uint8 = 2
+	AREA_TYPE_NSSA         uint8 = 3
+)
+
+const (
+	NSSA_TRANSLATOR_ROLE_CANDIDATE uint8 = 0
+	NSSA_TRANSLATOR_ROLE_ALWAYS    uint8 = 1
+)
+
+const (
+	OSPFV2_AREA_UPDATE_ADMIN_STATE       = 0x1
+	OSPFV2_AREA_UPDATE_AUTH_TYPE         = 0x2
+	OSPFV2_AREA_UPDATE_IMPORT_AS_EXTERN  = 0x4
+	OSPFV2_AREA_UPDATE_AREA_TYPE         = 0x8
+	OSPFV2_AREA_UPDATE_STUB_DEFAULT_COST = 0x10
+	OSPFV2_AREA_UPDATE_NSSA_TRANSLATOR   = 0x20
+	OSPFV2_AREA_UPDATE_NSSA_DEFAULT      = 0x40
 )
 
 type Ospfv2Area struct {
-	AreaId         uint32
-	AdminState     bool
-	AuthType       uint8
-	ImportASExtern bool
+	AreaId               uint32
+	AdminState           bool
+	AuthType             uint8
+	ImportASExtern       bool
+	AreaType             uint8
+	StubDefaultCost      uint32
+	NssaTranslatorRole   uint8
+	NssaDefaultOriginate bool
 }
 
 type Ospfv2AreaState struct {
-	AreaId uint32
-	//NumSpfRuns       uint32
+	AreaId     uint32
+	NumSpfRuns uint32
 	//NumBdrRtr        uint32
 	//NumAsBdrRtr      uint32
 	NumOfRouterLSA     uint32
@@ -72,6 +104,9 @@ type Ospfv2AreaState struct {
 	NumOfLSA           uint32
 	NumOfNbrs          uint32
 	NumOfRoutes        uint32
+	NumPartialSpfRuns  uint32
+	LastSpfDuration    uint32
+	LastSpfTrigger     uint8
 }
 
 type Ospfv2AreaStateGetInfo struct {
@@ -81,6 +116,19 @@ type Ospfv2AreaStateGetInfo struct {
 	List   []*Ospfv2AreaState
 }
 
+const (
+	OSPFV2_AREA_RANGE_UPDATE_ADVERTISE = 0x1
+	OSPFV2_AREA_RANGE_UPDATE_COST      = 0x2
+)
+
+type Ospfv2AreaRange struct {
+	AreaId    uint32
+	IpPrefix  uint32
+	Netmask   uint32
+	Advertise bool
+	Cost      uint32
+}
+
 const (
 	GLOBAL_ADMIN_STATE_UP   bool = true
 	GLOBAL_ADMIN_STATE_DOWN bool = false
@@ -96,14 +144,142 @@ const (
 	OSPFV2_GLOBAL_UPDATE_ADMIN_STATE         = 0x2
 	OSPFV2_GLOBAL_UPDATE_AS_BDR_RTR_STATUS   = 0x4
 	OSPFV2_GLOBAL_UPDATE_REFERENCE_BANDWIDTH = 0x8
+	OSPFV2_GLOBAL_UPDATE_RESTART_SUPPORT     = 0x10
+	OSPFV2_GLOBAL_UPDATE_RESTART_INTERVAL    = 0x20
+	OSPFV2_GLOBAL_UPDATE_RESTART_HELPER      = 0x40
+	OSPFV2_GLOBAL_UPDATE_RESTART_STRICT_LSA  = 0x80
+	OSPFV2_GLOBAL_UPDATE_MAXIMUM_PATHS       = 0x100
+	OSPFV2_GLOBAL_UPDATE_SPF_INITIAL_WAIT    = 0x200
+	OSPFV2_GLOBAL_UPDATE_SPF_HOLD_WAIT       = 0x400
+	OSPFV2_GLOBAL_UPDATE_SPF_MAX_WAIT        = 0x800
+	OSPFV2_GLOBAL_UPDATE_PASSIVE_DEFAULT     = 0x1000
+	OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_ADMIN   = 0x2000
+	OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_STARTUP = 0x4000
+	OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_BGP     = 0x8000
+	OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_SUMMARY = 0x10000
+	OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_EXT     = 0x20000
+)
+
+const (
+	RESTART_SUPPORT_NONE_STR         string = "none"
+	RESTART_SUPPORT_PLANNED_ONLY_STR string = "plannedonly"
+)
+
+const (
+	RESTART_SUPPORT_NONE         uint8 = 0
+	RESTART_SUPPORT_PLANNED_ONLY uint8 = 1
+)
+
+const (
+	RESTART_STATUS_NOT_RESTARTING_STR  string = "notrestarting"
+	RESTART_STATUS_PLANNED_RESTART_STR string = "plannedrestart"
+)
+
+const (
+	RESTART_STATUS_NOT_RESTARTING  uint8 = 0
+	RESTART_STATUS_PLANNED_RESTART uint8 = 1
+)
+
+const (
+	RESTART_EXIT_REASON_NONE_STR             string = "none"
+	RESTART_EXIT_REASON_IN_PROGRESS_STR      string = "inprogress"
+	RESTART_EXIT_REASON_COMPLETED_STR        string = "completed"
+	RESTART_EXIT_REASON_TIMED_OUT_STR        string = "timedout"
+	RESTART_EXIT_REASON_TOPOLOGY_CHANGED_STR string = "topologychanged"
+)
+
+const (
+	RESTART_EXIT_REASON_NONE             uint8 = 0
+	RESTART_EXIT_REASON_IN_PROGRESS      uint8 = 1
+	RESTART_EXIT_REASON_COMPLETED        uint8 = 2
+	RESTART_EXIT_REASON_TIMED_OUT        uint8 = 3
+	RESTART_EXIT_REASON_TOPOLOGY_CHANGED uint8 = 4
+)
+
+const (
+	STUB_ROUTER_STATUS_NONE_STR           string = "none"
+	STUB_ROUTER_STATUS_ADMINISTRATIVE_STR string = "administrative"
+	STUB_ROUTER_STATUS_ON_STARTUP_STR     string = "onstartup"
+)
+
+const (
+	STUB_ROUTER_STATUS_NONE           uint8 = 0
+	STUB_ROUTER_STATUS_ADMINISTRATIVE uint8 = 1
+	STUB_ROUTER_STATUS_ON_STARTUP     uint8 = 2
+)
+
+const (
+	SPF_TRIGGER_NONE_STR                  string = "none"
+	SPF_TRIGGER_ROUTER_LSA_STR            string = "routerlsa"
+	SPF_TRIGGER_NETWORK_LSA_STR           string = "networklsa"
+	SPF_TRIGGER_SUMMARY_LSA_STR           string = "summarylsa"
+	SPF_TRIGGER_AS_EXTERNAL_LSA_STR       string = "asexternallsa"
+	SPF_TRIGGER_NSSA_LSA_STR              string = "nssalsa"
+	SPF_TRIGGER_NBR_DOWN_STR              string = "nbrdown"
+	SPF_TRIGGER_AREA_CHANGE_STR           string = "areachange"
+	SPF_TRIGGER_AREA_RANGE_CHANGE_STR     string = "arearangechange"
+	SPF_TRIGGER_VIRTUAL_LINK_CHANGE_STR   string = "virtuallinkchange"
+	SPF_TRIGGER_MAXIMUM_PATHS_CHANGE_STR  string = "maximumpathschange"
+	SPF_TRIGGER_GRACEFUL_RESTART_EXIT_STR string = "gracefulrestartexit"
+)
+
+const (
+	SPF_TRIGGER_NONE                  uint8 = 0
+	SPF_TRIGGER_ROUTER_LSA            uint8 = 1
+	SPF_TRIGGER_NETWORK_LSA           uint8 = 2
+	SPF_TRIGGER_SUMMARY_LSA           uint8 = 3
+	SPF_TRIGGER_AS_EXTERNAL_LSA       uint8 = 4
+	SPF_TRIGGER_NSSA_LSA              uint8 = 5
+	SPF_TRIGGER_NBR_DOWN              uint8 = 6
+	SPF_TRIGGER_AREA_CHANGE           uint8 = 7
+	SPF_TRIGGER_AREA_RANGE_CHANGE     uint8 = 8
+	SPF_TRIGGER_VIRTUAL_LINK_CHANGE   uint8 = 9
+	SPF_TRIGGER_MAXIMUM_PATHS_CHANGE  uint8 = 10
+	SPF_TRIGGER_GRACEFUL_RESTART_EXIT uint8 = 11
+)
+
+const (
+	RESTART_HELPER_STATUS_NOT_HELPING_STR string = "nothelping"
+	RESTART_HELPER_STATUS_HELPING_STR     string = "helping"
+)
+
+const (
+	RESTART_HELPER_STATUS_NOT_HELPING uint8 = 0
+	RESTART_HELPER_STATUS_HELPING     uint8 = 1
+)
+
+const (
+	RESTART_RESYNC_NONE_STR        string = "none"
+	RESTART_RESYNC_IN_PROGRESS_STR string = "inprogress"
+	RESTART_RESYNC_COMPLETED_STR   string = "completed"
+)
+
+const (
+	RESTART_RESYNC_NONE        uint8 = 0
+	RESTART_RESYNC_IN_PROGRESS uint8 = 1
+	RESTART_RESYNC_COMPLETED   uint8 = 2
 )
 
 type Ospfv2Global struct {
-	Vrf                string
-	RouterId           uint32
-	AdminState         bool
-	ASBdrRtrStatus     bool
-	ReferenceBandwidth uint32
+	Vrf                      string
+	RouterId                 uint32
+	AdminState               bool
+	ASBdrRtrStatus           bool
+	ReferenceBandwidth       uint32
+	RestartSupport           uint8
+	RestartInterval          uint32
+	RestartHelperSupport     bool
+	RestartStrictLsaChecking bool
+	MaximumPaths             uint8
+	SpfInitialWait           uint32
+	SpfHoldWait              uint32
+	SpfMaxWait               uint32
+	PassiveIntfDefault       bool
+	StubRouterAdmin          bool
+	StubRouterOnStartup      uint32
+	StubRouterWaitForBgp     bool
+	StubRouterSummaryLsa     bool
+	StubRouterExternalLsa    bool
 }
 
 type Ospfv2GlobalState struct {
@@ -119,6 +295,11 @@ type Ospfv2GlobalState struct {
 	NumOfSummary4LSA   uint32
 	NumOfASExternalLSA uint32
 	NumOfRoutes        uint32
+	RestartStatus      uint8
+	RestartAge         uint32
+	RestartExitReason  uint8
+	StubRouterStatus   uint8
+	StubRouterTimeLeft uint32
 }
 
 type Ospfv2GlobalStateGetInfo struct {
@@ -139,13 +320,30 @@ const (
 )
 
 const (
-	INTF_TYPE_POINT2POINT_STR string = "pointtopoint"
-	INTF_TYPE_BROADCAST_STR   string = "broadcast"
+	INTF_PASSIVE_DEFAULT_STR string = "default"
+	INTF_PASSIVE_TRUE_STR    string = "true"
+	INTF_PASSIVE_FALSE_STR   string = "false"
 )
 
 const (
-	INTF_TYPE_POINT2POINT uint8 = 0
-	INTF_TYPE_BROADCAST   uint8 = 1
+	INTF_PASSIVE_DEFAULT uint8 = 0 // Follows the global PassiveIntfDefault
+	INTF_PASSIVE_TRUE    uint8 = 1
+	INTF_PASSIVE_FALSE   uint8 = 2
+)
+
+const (
+	INTF_TYPE_POINT2POINT_STR      string = "pointtopoint"
+	INTF_TYPE_BROADCAST_STR        string = "broadcast"
+	INTF_TYPE_NBMA_STR             string = "nbma"
+	INTF_TYPE_POINT2MULTIPOINT_STR string = "pointtomultipoint"
+)
+
+const (
+	INTF_TYPE_POINT2POINT      uint8 = 0
+	INTF_TYPE_BROADCAST        uint8 = 1
+	INTF_TYPE_VIRTUAL          uint8 = 2 // Internal, used for virtual links
+	INTF_TYPE_NBMA             uint8 = 3
+	INTF_TYPE_POINT2MULTIPOINT uint8 = 4
 )
 
 const (
@@ -180,6 +378,16 @@ const (
 	OSPFV2_INTF_UPDATE_HELLO_INTERVAL    = 0x80
 	OSPFV2_INTF_UPDATE_RTR_DEAD_INTERVAL = 0x100
 	OSPFV2_INTF_UPDATE_METRIC_VALUE      = 0x200
+	OSPFV2_INTF_UPDATE_AUTH_KEY          = 0x400
+	OSPFV2_INTF_UPDATE_AUTH_KEY_ID       = 0x800
+	OSPFV2_INTF_UPDATE_POLL_INTERVAL     = 0x1000
+	OSPFV2_INTF_UPDATE_BFD_ENABLE        = 0x2000
+	OSPFV2_INTF_UPDATE_PASSIVE           = 0x4000
+)
+
+const (
+	AUTH_SIMPLE_PASSWORD_MAX_LEN int = 8
+	AUTH_MD5_KEY_MAX_LEN         int = 16
 )
 
 type Ospfv2Intf struct {
@@ -194,6 +402,121 @@ type Ospfv2Intf struct {
 	HelloInterval    uint16
 	RtrDeadInterval  uint32
 	MetricValue      uint16
+	AuthKey          string
+	AuthKeyId        uint8
+	PollInterval     uint32
+	BfdEnable        bool
+	Passive          uint8
+}
+
+const (
+	OSPFV2_INTF_AUTH_KEY_UPDATE_KEY            = 0x1
+	OSPFV2_INTF_AUTH_KEY_UPDATE_GENERATE_DELAY = 0x2
+)
+
+type Ospfv2IntfAuthKey struct {
+	IpAddress        uint32
+	AddressLessIfIdx uint32
+	KeyId            uint8
+	Key              string
+	GenerateDelay    uint32
+}
+
+const (
+	OSPFV2_NBR_UPDATE_RTR_PRIORITY = 0x1
+)
+
+type Ospfv2Nbr struct {
+	IpAddr           uint32
+	AddressLessIfIdx uint32
+	RtrPriority      uint8
+}
+
+const (
+	OSPFV2_VIRTUAL_LINK_UPDATE_ADMIN_STATE       = 0x1
+	OSPFV2_VIRTUAL_LINK_UPDATE_TRANSIT_DELAY     = 0x2
+	OSPFV2_VIRTUAL_LINK_UPDATE_RETRANS_INTERVAL  = 0x4
+	OSPFV2_VIRTUAL_LINK_UPDATE_HELLO_INTERVAL    = 0x8
+	OSPFV2_VIRTUAL_LINK_UPDATE_RTR_DEAD_INTERVAL = 0x10
+	OSPFV2_VIRTUAL_LINK_UPDATE_AUTH_KEY          = 0x20
+	OSPFV2_VIRTUAL_LINK_UPDATE_AUTH_KEY_ID       = 0x40
+)
+
+type Ospfv2VirtualLink struct {
+	AreaId          uint32 // Transit Area
+	NbrRouterId     uint32
+	AdminState      bool
+	TransitDelay    uint16
+	RetransInterval uint16
+	HelloInterval   uint16
+	RtrDeadInterval uint32
+	AuthKey         string
+	AuthKeyId       uint8
+}
+
+const (
+	REDIST_PROTOCOL_CONNECTED string = "CONNECTED"
+	REDIST_PROTOCOL_STATIC    string = "STATIC"
+	REDIST_PROTOCOL_BGP       string = "BGP"
+)
+
+const (
+	METRIC_TYPE_NONE_STR string = ""
+	METRIC_TYPE_E1_STR   string = "e1"
+	METRIC_TYPE_E2_STR   string = "e2"
+)
+
+const (
+	METRIC_TYPE_NONE uint8 = 0
+	METRIC_TYPE_E1   uint8 = 1
+	METRIC_TYPE_E2   uint8 = 2
+)
+
+const (
+	OSPFV2_REDISTRIBUTION_UPDATE_METRIC      = 0x1
+	OSPFV2_REDISTRIBUTION_UPDATE_METRIC_TYPE = 0x2
+	OSPFV2_REDISTRIBUTION_UPDATE_TAG         = 0x4
+	OSPFV2_REDISTRIBUTION_UPDATE_ROUTE_MAP   = 0x8
+)
+
+type Ospfv2Redistribution struct {
+	Protocol   string
+	Metric     uint32 // 0: metric of the route
+	MetricType uint8
+	Tag        uint32
+	RouteMap   string
+}
+
+const (
+	ROUTE_MAP_ACTION_PERMIT_STR string = "permit"
+	ROUTE_MAP_ACTION_DENY_STR   string = "deny"
+)
+
+const (
+	OSPFV2_ROUTE_MAP_UPDATE_ACTION                = 0x1
+	OSPFV2_ROUTE_MAP_UPDATE_MATCH_IP_PREFIX       = 0x2
+	OSPFV2_ROUTE_MAP_UPDATE_MATCH_NETMASK         = 0x4
+	OSPFV2_ROUTE_MAP_UPDATE_MATCH_LONGER_PREFIXES = 0x8
+	OSPFV2_ROUTE_MAP_UPDATE_MATCH_TAG             = 0x10
+	OSPFV2_ROUTE_MAP_UPDATE_SET_METRIC            = 0x20
+	OSPFV2_ROUTE_MAP_UPDATE_SET_METRIC_TYPE       = 0x40
+	OSPFV2_ROUTE_MAP_UPDATE_SET_TAG               = 0x80
+	OSPFV2_ROUTE_MAP_UPDATE_SET_FWD_ADDR          = 0x100
+)
+
+type Ospfv2RouteMap struct {
+	Name                string
+	Sequence            uint32
+	Permit              bool
+	MatchPrefix         bool // false: prefix is not matched
+	MatchIpPrefix       uint32
+	MatchNetmask        uint32
+	MatchLongerPrefixes bool
+	MatchTag            uint32 // 0: tag is not matched
+	SetMetric           uint32 // 0: metric is not changed
+	SetMetricType       uint8
+	SetTag              uint32
+	SetFwdAddr          uint32
 }
 
 type Ospfv2IntfState struct {
@@ -216,6 +539,10 @@ type Ospfv2IntfState struct {
 	Cost                     uint32
 	NumOfStateChange         uint32
 	TimeOfStateChange        string
+	NumOfAuthTypeMismatch    uint32
+	NumOfAuthFailures        uint32
+	NumOfAuthReplayDrops     uint32
+	Ospfv2PktStats
 }
 type Ospfv2IntfStateGetInfo struct {
 	EndIdx int
@@ -284,12 +611,84 @@ const (
 	NBR_STATE_FULL     uint8 = 8
 )
 
+const (
+	BFD_STATE_NONE_STR string = "none"
+	BFD_STATE_INIT_STR string = "init"
+	BFD_STATE_UP_STR   string = "up"
+	BFD_STATE_DOWN_STR string = "down"
+)
+
+const (
+	BFD_STATE_NONE uint8 = 0
+	BFD_STATE_INIT uint8 = 1
+	BFD_STATE_UP   uint8 = 2
+	BFD_STATE_DOWN uint8 = 3
+)
+
+const (
+	NBR_EVENT_NONE_STR                string = "none"
+	NBR_EVENT_HELLO_RECEIVED_STR      string = "helloreceived"
+	NBR_EVENT_TWO_WAY_RECEIVED_STR    string = "twowayreceived"
+	NBR_EVENT_ONE_WAY_RECEIVED_STR    string = "onewayreceived"
+	NBR_EVENT_ADJ_OK_STR              string = "adjok"
+	NBR_EVENT_NEGOTIATION_DONE_STR    string = "negotiationdone"
+	NBR_EVENT_EXCHANGE_DONE_STR       string = "exchangedone"
+	NBR_EVENT_LOADING_DONE_STR        string = "loadingdone"
+	NBR_EVENT_SEQ_NUMBER_MISMATCH_STR string = "seqnumbermismatch"
+)
+
+// Neighbor events (RFC 2328, section 10.2) causing the last state change
+const (
+	NBR_EVENT_NONE                uint8 = 0
+	NBR_EVENT_HELLO_RECEIVED      uint8 = 1
+	NBR_EVENT_TWO_WAY_RECEIVED    uint8 = 2
+	NBR_EVENT_ONE_WAY_RECEIVED    uint8 = 3
+	NBR_EVENT_ADJ_OK              uint8 = 4
+	NBR_EVENT_NEGOTIATION_DONE    uint8 = 5
+	NBR_EVENT_EXCHANGE_DONE       uint8 = 6
+	NBR_EVENT_LOADING_DONE        uint8 = 7
+	NBR_EVENT_SEQ_NUMBER_MISMATCH uint8 = 8
+)
+
+// Ospf packet counters kept per interface and per neighbor
+type Ospfv2PktStats struct {
+	NumOfHelloRx            uint32
+	NumOfHelloTx            uint32
+	NumOfDbdRx              uint32
+	NumOfDbdTx              uint32
+	NumOfLsReqRx            uint32
+	NumOfLsReqTx            uint32
+	NumOfLsUpdRx            uint32
+	NumOfLsUpdTx            uint32
+	NumOfLsAckRx            uint32
+	NumOfLsAckTx            uint32
+	NumOfRetransmits        uint32
+	NumOfMtuMismatch        uint32
+	NumOfAreaMismatch       uint32
+	NumOfHelloIntvlMismatch uint32
+	NumOfDeadIntvlMismatch  uint32
+	NumOfBadChecksum        uint32
+}
+
 type Ospfv2NbrState struct {
-	IpAddr           uint32
-	AddressLessIfIdx uint32
-	RtrId            uint32
-	Options          int32
-	State            uint8
+	IpAddr                  uint32
+	AddressLessIfIdx        uint32
+	RtrId                   uint32
+	Options                 int32
+	State                   uint8
+	RestartHelperStatus     uint8
+	RestartHelperAge        uint32
+	RestartHelperExitReason uint8
+	RestartResync           uint8
+	BfdState                uint8
+	Uptime                  uint32
+	LastStateChangeReason   uint8
+	DesignatedRouter        uint32
+	BackupDesignatedRouter  uint32
+	RetransmitListLen       uint32
+	DeadTimerRemaining      uint32
+	NumOfAuthFailures       uint32
+	Ospfv2PktStats
 }
 
 type Ospfv2NbrStateGetInfo struct {
@@ -26,36 +26,61 @@ package rpc
 import (
 	"errors"
 	"l3/ospfv2/api"
+	"models/objects"
 	"ospfv2d"
 )
 
-func (rpcHdl *rpcServiceHandler) GetOspfv2NbrState(IpAddr string, AddressLessIfIdx int32) (*ospfv2d.Ospfv2NbrState, error) {
-	var convObj *ospfv2d.Ospfv2NbrState
-	ipAddr, err := convertDotNotationToUint32(IpAddr)
+func (rpcHdl *rpcServiceHandler) restoreOspfv2NbrConfFromDB() (bool, error) {
+	rpcHdl.logger.Info("Restoring Ospfv2 Nbr Config From DB")
+	var ospfv2Nbr objects.Ospfv2Nbr
+
+	ospfNbrList, err := rpcHdl.dbHdl.GetAllObjFromDb(ospfv2Nbr)
 	if err != nil {
-		return nil, errors.New("Invalid IP Address")
+		return false, errors.New("Failed to retireve Ospfv2Nbr object info from DB")
+	}
+	for idx := 0; idx < len(ospfNbrList); idx++ {
+		dbObj := ospfNbrList[idx].(objects.Ospfv2Nbr)
+		obj := new(ospfv2d.Ospfv2Nbr)
+		objects.Convertospfv2dOspfv2NbrObjToThrift(&dbObj, obj)
+		convObj, err := convertFromRPCFmtOspfv2Nbr(obj)
+		if err != nil {
+			return false, err
+		}
+		ok, err := api.CreateOspfv2Nbr(convObj)
+		if !ok {
+			return ok, err
+		}
 	}
-	addrLessIfIdx := uint32(AddressLessIfIdx)
-	obj, err := api.GetOspfv2NbrState(ipAddr, addrLessIfIdx)
-	if err == nil {
-		convObj = convertToRPCFmtOspfv2NbrState(obj)
+	return true, nil
+}
+
+func (rpcHdl *rpcServiceHandler) CreateOspfv2Nbr(config *ospfv2d.Ospfv2Nbr) (bool, error) {
+	cfg, err := convertFromRPCFmtOspfv2Nbr(config)
+	if err != nil {
+		return false, err
 	}
-	return convObj, err
+	rv, err := api.CreateOspfv2Nbr(cfg)
+	return rv, err
 }
 
-func (rpcHdl *rpcServiceHandler) GetBulkOspfv2NbrState(fromIdx, count ospfv2d.Int) (*ospfv2d.Ospfv2NbrStateGetInfo, error) {
-	var getBulkInfo ospfv2d.Ospfv2NbrStateGetInfo
-	info, err := api.GetBulkOspfv2NbrState(int(fromIdx), int(count))
-	if info == nil || err != nil {
-		return &getBulkInfo, err
+func (rpcHdl *rpcServiceHandler) UpdateOspfv2Nbr(oldConfig, newConfig *ospfv2d.Ospfv2Nbr, attrset []bool, op []*ospfv2d.PatchOpInfo) (bool, error) {
+	convOldCfg, err := convertFromRPCFmtOspfv2Nbr(oldConfig)
+	if err != nil {
+		return false, err
 	}
-	getBulkInfo.StartIdx = fromIdx
-	getBulkInfo.EndIdx = ospfv2d.Int(info.EndIdx)
-	getBulkInfo.More = info.More
-	getBulkInfo.Count = ospfv2d.Int(len(info.List))
-	for idx := 0; idx < len(info.List); idx++ {
-		getBulkInfo.Ospfv2NbrStateList = append(getBulkInfo.Ospfv2NbrStateList,
-			convertToRPCFmtOspfv2NbrState(info.List[idx]))
+	convNewCfg, err := convertFromRPCFmtOspfv2Nbr(newConfig)
+	if err != nil {
+		return false, err
+	}
+	rv, err := api.UpdateOspfv2Nbr(convOldCfg, convNewCfg, attrset)
+	return rv, err
+}
+
+func (rpcHdl *rpcServiceHandler) DeleteOspfv2Nbr(config *ospfv2d.Ospfv2Nbr) (bool, error) {
+	cfg, err := convertFromRPCFmtOspfv2Nbr(config)
+	if err != nil {
+		return false, err
 	}
-	return &getBulkInfo, err
+	rv, err := api.DeleteOspfv2Nbr(cfg)
+	return rv, err
 }
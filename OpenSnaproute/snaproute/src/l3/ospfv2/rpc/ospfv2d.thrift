@@ -30,6 +30,17 @@ struct Ospfv2Area {
 	2 : string AdminState
 	3 : string AuthType
 	4 : bool ImportASExtern
+	5 : string AreaType
+	6 : i32 StubDefaultCost
+	7 : string NssaTranslatorRole
+	8 : bool NssaDefaultOriginate
+}
+struct Ospfv2AreaRange {
+	1 : string AreaId
+	2 : string IpPrefix
+	3 : string Netmask
+	4 : bool Advertise
+	5 : i32 Cost
 }
 struct Ospfv2RouteState {
 	1 : string DestId
@@ -65,6 +76,54 @@ struct Ospfv2Intf {
 	9 : i16 HelloInterval
 	10 : i32 RtrDeadInterval
 	11 : i16 MetricValue
+	12 : string AuthKey
+	13 : byte AuthKeyId
+	14 : i32 PollInterval
+	15 : bool BfdEnable
+	16 : string Passive
+}
+struct Ospfv2IntfAuthKey {
+	1 : string IpAddress
+	2 : i32 AddressLessIfIdx
+	3 : byte KeyId
+	4 : string Key
+	5 : i32 GenerateDelay
+}
+struct Ospfv2Nbr {
+	1 : string IpAddr
+	2 : i32 AddressLessIfIdx
+	3 : byte RtrPriority
+}
+struct Ospfv2VirtualLink {
+	1 : string AreaId
+	2 : string NbrRouterId
+	3 : string AdminState
+	4 : i16 TransitDelay
+	5 : i16 RetransInterval
+	6 : i16 HelloInterval
+	7 : i32 RtrDeadInterval
+	8 : string AuthKey
+	9 : byte AuthKeyId
+}
+struct Ospfv2Redistribution {
+	1 : string Protocol
+	2 : i32 Metric
+	3 : string MetricType
+	4 : i32 Tag
+	5 : string RouteMap
+}
+struct Ospfv2RouteMap {
+	1 : string Name
+	2 : i32 Sequence
+	3 : string Action
+	4 : string MatchIpPrefix
+	5 : string MatchNetmask
+	6 : bool MatchLongerPrefixes
+	7 : i32 MatchTag
+	8 : i32 SetMetric
+	9 : string SetMetricType
+	10 : i32 SetTag
+	11 : string SetFwdAddr
 }
 struct Ospfv2NbrState {
 	1 : string IpAddr
@@ -72,6 +131,34 @@ struct Ospfv2NbrState {
 	3 : string RtrId
 	4 : i32 Options
 	5 : string State
+	6 : string RestartHelperStatus
+	7 : i32 RestartHelperAge
+	8 : string RestartHelperExitReason
+	9 : string RestartResync
+	10 : string BfdState
+	11 : i32 Uptime
+	12 : string LastStateChangeReason
+	13 : string DesignatedRouter
+	14 : string BackupDesignatedRouter
+	15 : i32 RetransmitListLen
+	16 : i32 DeadTimerRemaining
+	17 : i32 NumOfAuthFailures
+	18 : i32 NumOfHelloRx
+	19 : i32 NumOfHelloTx
+	20 : i32 NumOfDbdRx
+	21 : i32 NumOfDbdTx
+	22 : i32 NumOfLsReqRx
+	23 : i32 NumOfLsReqTx
+	24 : i32 NumOfLsUpdRx
+	25 : i32 NumOfLsUpdTx
+	26 : i32 NumOfLsAckRx
+	27 : i32 NumOfLsAckTx
+	28 : i32 NumOfRetransmits
+	29 : i32 NumOfMtuMismatch
+	30 : i32 NumOfAreaMismatch
+	31 : i32 NumOfHelloIntvlMismatch
+	32 : i32 NumOfDeadIntvlMismatch
+	33 : i32 NumOfBadChecksum
 }
 struct Ospfv2NbrStateGetInfo {
 	1: int StartIdx
@@ -91,6 +178,10 @@ struct Ospfv2AreaState {
 	8 : i32 NumOfLSA
 	9 : i32 NumOfNbrs
 	10 : i32 NumOfRoutes
+	11 : i32 NumSpfRuns
+	12 : i32 NumPartialSpfRuns
+	13 : i32 LastSpfDuration
+	14 : string LastSpfTrigger
 }
 struct Ospfv2AreaStateGetInfo {
 	1: int StartIdx
@@ -131,6 +222,11 @@ struct Ospfv2GlobalState {
 	10 : i32 NumOfSummary4LSA
 	11 : i32 NumOfASExternalLSA
 	12 : i32 NumOfRoutes
+	13 : string RestartStatus
+	14 : i32 RestartAge
+	15 : string RestartExitReason
+	16 : string StubRouterStatus
+	17 : i32 StubRouterTimeLeft
 }
 struct Ospfv2GlobalStateGetInfo {
 	1: int StartIdx
@@ -159,6 +255,25 @@ struct Ospfv2IntfState {
 	17 : i32 Cost
 	18 : i32 NumOfStateChange
 	19 : string TimeOfStateChange
+	20 : i32 NumOfAuthTypeMismatch
+	21 : i32 NumOfAuthFailures
+	22 : i32 NumOfAuthReplayDrops
+	23 : i32 NumOfHelloRx
+	24 : i32 NumOfHelloTx
+	25 : i32 NumOfDbdRx
+	26 : i32 NumOfDbdTx
+	27 : i32 NumOfLsReqRx
+	28 : i32 NumOfLsReqTx
+	29 : i32 NumOfLsUpdRx
+	30 : i32 NumOfLsUpdTx
+	31 : i32 NumOfLsAckRx
+	32 : i32 NumOfLsAckTx
+	33 : i32 NumOfRetransmits
+	34 : i32 NumOfMtuMismatch
+	35 : i32 NumOfAreaMismatch
+	36 : i32 NumOfHelloIntvlMismatch
+	37 : i32 NumOfDeadIntvlMismatch
+	38 : i32 NumOfBadChecksum
 }
 struct Ospfv2IntfStateGetInfo {
 	1: int StartIdx
@@ -173,6 +288,20 @@ struct Ospfv2Global {
 	3 : string AdminState
 	4 : bool ASBdrRtrStatus
 	5 : i32 ReferenceBandwidth
+	6 : string RestartSupport
+	7 : i32 RestartInterval
+	8 : bool RestartHelperSupport
+	9 : bool RestartStrictLsaChecking
+	10 : byte MaximumPaths
+	11 : i32 SpfInitialWait
+	12 : i32 SpfHoldWait
+	13 : i32 SpfMaxWait
+	14 : bool PassiveIntfDefault
+	15 : bool StubRouterAdmin
+	16 : i32 StubRouterOnStartup
+	17 : bool StubRouterWaitForBgp
+	18 : bool StubRouterSummaryLsa
+	19 : bool StubRouterExternalLsa
 }
 struct Ospfv2NextHop {
 	1 : string IntfIPAddr
@@ -191,12 +320,30 @@ service OSPFV2DServices {
 	bool CreateOspfv2Area(1: Ospfv2Area config);
 	bool UpdateOspfv2Area(1: Ospfv2Area origconfig, 2: Ospfv2Area newconfig, 3: list<bool> attrset, 4: list<PatchOpInfo> op);
 	bool DeleteOspfv2Area(1: Ospfv2Area config);
+	bool CreateOspfv2AreaRange(1: Ospfv2AreaRange config);
+	bool UpdateOspfv2AreaRange(1: Ospfv2AreaRange origconfig, 2: Ospfv2AreaRange newconfig, 3: list<bool> attrset, 4: list<PatchOpInfo> op);
+	bool DeleteOspfv2AreaRange(1: Ospfv2AreaRange config);
 
 	Ospfv2RouteStateGetInfo GetBulkOspfv2RouteState(1: int fromIndex, 2: int count);
 	Ospfv2RouteState GetOspfv2RouteState(1: string DestId, 2: string AddrMask, 3: string DestType);
 	bool CreateOspfv2Intf(1: Ospfv2Intf config);
 	bool UpdateOspfv2Intf(1: Ospfv2Intf origconfig, 2: Ospfv2Intf newconfig, 3: list<bool> attrset, 4: list<PatchOpInfo> op);
 	bool DeleteOspfv2Intf(1: Ospfv2Intf config);
+	bool CreateOspfv2IntfAuthKey(1: Ospfv2IntfAuthKey config);
+	bool UpdateOspfv2IntfAuthKey(1: Ospfv2IntfAuthKey origconfig, 2: Ospfv2IntfAuthKey newconfig, 3: list<bool> attrset, 4: list<PatchOpInfo> op);
+	bool DeleteOspfv2IntfAuthKey(1: Ospfv2IntfAuthKey config);
+	bool CreateOspfv2Nbr(1: Ospfv2Nbr config);
+	bool UpdateOspfv2Nbr(1: Ospfv2Nbr origconfig, 2: Ospfv2Nbr newconfig, 3: list<bool> attrset, 4: list<PatchOpInfo> op);
+	bool DeleteOspfv2Nbr(1: Ospfv2Nbr config);
+	bool CreateOspfv2VirtualLink(1: Ospfv2VirtualLink config);
+	bool UpdateOspfv2VirtualLink(1: Ospfv2VirtualLink origconfig, 2: Ospfv2VirtualLink newconfig, 3: list<bool> attrset, 4: list<PatchOpInfo> op);
+	bool DeleteOspfv2VirtualLink(1: Ospfv2VirtualLink config);
+	bool CreateOspfv2Redistribution(1: Ospfv2Redistribution config);
+	bool UpdateOspfv2Redistribution(1: Ospfv2Redistribution origconfig, 2: Ospfv2Redistribution newconfig, 3: list<bool> attrset, 4: list<PatchOpInfo> op);
+	bool DeleteOspfv2Redistribution(1: Ospfv2Redistribution config);
+	bool CreateOspfv2RouteMap(1: Ospfv2RouteMap config);
+	bool UpdateOspfv2RouteMap(1: Ospfv2RouteMap origconfig, 2: Ospfv2RouteMap newconfig, 3: list<bool> attrset, 4: list<PatchOpInfo> op);
+	bool DeleteOspfv2RouteMap(1: Ospfv2RouteMap config);
 
 	Ospfv2NbrStateGetInfo GetBulkOspfv2NbrState(1: int fromIndex, 2: int count);
 	Ospfv2NbrState GetOspfv2NbrState(1: string IpAddr, 2: i32 AddressLessIfIdx);
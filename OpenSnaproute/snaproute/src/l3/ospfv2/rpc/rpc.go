@@ -75,6 +75,30 @@ func (rpcHdl *rpcServiceHandler) restoreConfigFromDB() (bool, error) {
 	if !ok {
 		return ok, err
 	}
+	ok, err = rpcHdl.restoreOspfv2AreaRangeConfFromDB()
+	if !ok {
+		return ok, err
+	}
 	ok, err = rpcHdl.restoreOspfv2IntfConfFromDB()
+	if !ok {
+		return ok, err
+	}
+	ok, err = rpcHdl.restoreOspfv2IntfAuthKeyConfFromDB()
+	if !ok {
+		return ok, err
+	}
+	ok, err = rpcHdl.restoreOspfv2NbrConfFromDB()
+	if !ok {
+		return ok, err
+	}
+	ok, err = rpcHdl.restoreOspfv2VirtualLinkConfFromDB()
+	if !ok {
+		return ok, err
+	}
+	ok, err = rpcHdl.restoreOspfv2RouteMapConfFromDB()
+	if !ok {
+		return ok, err
+	}
+	ok, err = rpcHdl.restoreOspfv2RedistributionConfFromDB()
 	return ok, err
 }
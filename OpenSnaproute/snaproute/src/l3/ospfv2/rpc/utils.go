@@ -83,19 +83,82 @@ func convertFromRPCFmtOspfv2Area(config *ospfv2d.Ospfv2Area) (*objects.Ospfv2Are
 	default:
 		return nil, errors.New("Invalid Auth Type")
 	}
+	var areaType uint8
+	switch strings.ToLower(config.AreaType) {
+	case objects.AREA_TYPE_NORMAL_STR:
+		areaType = objects.AREA_TYPE_NORMAL
+	case objects.AREA_TYPE_STUB_STR:
+		areaType = objects.AREA_TYPE_STUB
+	case objects.AREA_TYPE_TOTALLY_STUB_STR:
+		areaType = objects.AREA_TYPE_TOTALLY_STUB
+	case objects.AREA_TYPE_NSSA_STR:
+		areaType = objects.AREA_TYPE_NSSA
+	default:
+		return nil, errors.New("Invalid Area Type")
+	}
+	if areaType != objects.AREA_TYPE_NORMAL && areaId == 0 {
+		return nil, errors.New("Invalid Area Type, backbone area can only be normal")
+	}
+	if config.StubDefaultCost < 0 || config.StubDefaultCost > 0xffffff {
+		return nil, errors.New("Invalid StubDefaultCost")
+	}
+	var translatorRole uint8
+	switch strings.ToLower(config.NssaTranslatorRole) {
+	case objects.NSSA_TRANSLATOR_ROLE_CANDIDATE_STR:
+		translatorRole = objects.NSSA_TRANSLATOR_ROLE_CANDIDATE
+	case objects.NSSA_TRANSLATOR_ROLE_ALWAYS_STR:
+		translatorRole = objects.NSSA_TRANSLATOR_ROLE_ALWAYS
+	default:
+		return nil, errors.New("Invalid NssaTranslatorRole")
+	}
 	return &objects.Ospfv2Area{
-		AreaId:         areaId,
-		AdminState:     adminState,
-		AuthType:       authType,
-		ImportASExtern: config.ImportASExtern,
+		AreaId:               areaId,
+		AdminState:           adminState,
+		AuthType:             authType,
+		ImportASExtern:       config.ImportASExtern,
+		AreaType:             areaType,
+		StubDefaultCost:      uint32(config.StubDefaultCost),
+		NssaTranslatorRole:   translatorRole,
+		NssaDefaultOriginate: config.NssaDefaultOriginate,
+	}, nil
+}
+
+func convertFromRPCFmtOspfv2AreaRange(config *ospfv2d.Ospfv2AreaRange) (*objects.Ospfv2AreaRange, error) {
+	areaId, err := convertDotNotationToUint32(config.AreaId)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintln("Invalid AreaId", err))
+	}
+	ipPrefix, err := convertDotNotationToUint32(config.IpPrefix)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintln("Invalid IpPrefix", err))
+	}
+	netmask, err := convertDotNotationToUint32(config.Netmask)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintln("Invalid Netmask", err))
+	}
+	if (^netmask)&(^netmask+1) != 0 {
+		return nil, errors.New("Invalid Netmask, non contiguous mask")
+	}
+	if ipPrefix&netmask != ipPrefix {
+		return nil, errors.New("Invalid IpPrefix, host bits are set")
+	}
+	if config.Cost < 0 || config.Cost > 0xffffff {
+		return nil, errors.New("Invalid Cost")
+	}
+	return &objects.Ospfv2AreaRange{
+		AreaId:    areaId,
+		IpPrefix:  ipPrefix,
+		Netmask:   netmask,
+		Advertise: config.Advertise,
+		Cost:      uint32(config.Cost),
 	}, nil
 }
 
 func convertToRPCFmtOspfv2AreaState(obj *objects.Ospfv2AreaState) *ospfv2d.Ospfv2AreaState {
 	areaId := convertUint32ToDotNotation(obj.AreaId)
 	return &ospfv2d.Ospfv2AreaState{
-		AreaId: areaId,
-		//NumSpfRuns:       int32(obj.NumSpfRuns),
+		AreaId:     areaId,
+		NumSpfRuns: int32(obj.NumSpfRuns),
 		//NumBdrRtr:        int32(obj.NumBdrRtr),
 		//NumAsBdrRtr:      int32(obj.NumAsBdrRtr),
 		NumOfRouterLSA:     int32(obj.NumOfRouterLSA),
@@ -107,6 +170,9 @@ func convertToRPCFmtOspfv2AreaState(obj *objects.Ospfv2AreaState) *ospfv2d.Ospfv
 		NumOfNbrs:          int32(obj.NumOfNbrs),
 		NumOfLSA:           int32(obj.NumOfLSA),
 		NumOfRoutes:        int32(obj.NumOfRoutes),
+		NumPartialSpfRuns:  int32(obj.NumPartialSpfRuns),
+		LastSpfDuration:    int32(obj.LastSpfDuration),
+		LastSpfTrigger:     convertToRPCFmtSpfTrigger(obj.LastSpfTrigger),
 	}
 }
 
@@ -128,16 +194,125 @@ func convertFromRPCFmtOspfv2Global(config *ospfv2d.Ospfv2Global) (*objects.Ospfv
 	if config.Vrf != "default" {
 		return nil, errors.New("Invalid Vrf")
 	}
+	var restartSupport uint8
+	switch strings.ToLower(config.RestartSupport) {
+	case objects.RESTART_SUPPORT_NONE_STR:
+		restartSupport = objects.RESTART_SUPPORT_NONE
+	case objects.RESTART_SUPPORT_PLANNED_ONLY_STR:
+		restartSupport = objects.RESTART_SUPPORT_PLANNED_ONLY
+	default:
+		return nil, errors.New("Invalid RestartSupport")
+	}
+	if config.RestartInterval < 1 || config.RestartInterval > 1800 {
+		return nil, errors.New("Invalid RestartInterval")
+	}
+	if config.MaximumPaths < 1 || config.MaximumPaths > 32 {
+		return nil, errors.New("Invalid MaximumPaths")
+	}
+	if config.SpfInitialWait < 0 || config.SpfInitialWait > 60000 {
+		return nil, errors.New("Invalid SpfInitialWait")
+	}
+	if config.SpfHoldWait < 0 || config.SpfHoldWait > 60000 {
+		return nil, errors.New("Invalid SpfHoldWait")
+	}
+	if config.SpfMaxWait < 0 || config.SpfMaxWait > 60000 {
+		return nil, errors.New("Invalid SpfMaxWait")
+	}
+	if config.SpfMaxWait < config.SpfInitialWait ||
+		config.SpfMaxWait < config.SpfHoldWait {
+		return nil, errors.New("SpfMaxWait should not be less than SpfInitialWait and SpfHoldWait")
+	}
+	if config.StubRouterOnStartup < 0 || config.StubRouterOnStartup > 86400 {
+		return nil, errors.New("Invalid StubRouterOnStartup")
+	}
 	return &objects.Ospfv2Global{
-		Vrf:                "default",
-		RouterId:           routerId,
-		AdminState:         adminState,
-		ASBdrRtrStatus:     config.ASBdrRtrStatus,
-		ReferenceBandwidth: uint32(config.ReferenceBandwidth),
+		Vrf:                      "default",
+		RouterId:                 routerId,
+		AdminState:               adminState,
+		ASBdrRtrStatus:           config.ASBdrRtrStatus,
+		ReferenceBandwidth:       uint32(config.ReferenceBandwidth),
+		RestartSupport:           restartSupport,
+		RestartInterval:          uint32(config.RestartInterval),
+		RestartHelperSupport:     config.RestartHelperSupport,
+		RestartStrictLsaChecking: config.RestartStrictLsaChecking,
+		MaximumPaths:             uint8(config.MaximumPaths),
+		SpfInitialWait:           uint32(config.SpfInitialWait),
+		SpfHoldWait:              uint32(config.SpfHoldWait),
+		SpfMaxWait:               uint32(config.SpfMaxWait),
+		PassiveIntfDefault:       config.PassiveIntfDefault,
+		StubRouterAdmin:          config.StubRouterAdmin,
+		StubRouterOnStartup:      uint32(config.StubRouterOnStartup),
+		StubRouterWaitForBgp:     config.StubRouterWaitForBgp,
+		StubRouterSummaryLsa:     config.StubRouterSummaryLsa,
+		StubRouterExternalLsa:    config.StubRouterExternalLsa,
 	}, nil
 }
 
+func convertToRPCFmtSpfTrigger(trigger uint8) string {
+	var reason string
+	switch trigger {
+	case objects.SPF_TRIGGER_NONE:
+		reason = objects.SPF_TRIGGER_NONE_STR
+	case objects.SPF_TRIGGER_ROUTER_LSA:
+		reason = objects.SPF_TRIGGER_ROUTER_LSA_STR
+	case objects.SPF_TRIGGER_NETWORK_LSA:
+		reason = objects.SPF_TRIGGER_NETWORK_LSA_STR
+	case objects.SPF_TRIGGER_SUMMARY_LSA:
+		reason = objects.SPF_TRIGGER_SUMMARY_LSA_STR
+	case objects.SPF_TRIGGER_AS_EXTERNAL_LSA:
+		reason = objects.SPF_TRIGGER_AS_EXTERNAL_LSA_STR
+	case objects.SPF_TRIGGER_NSSA_LSA:
+		reason = objects.SPF_TRIGGER_NSSA_LSA_STR
+	case objects.SPF_TRIGGER_NBR_DOWN:
+		reason = objects.SPF_TRIGGER_NBR_DOWN_STR
+	case objects.SPF_TRIGGER_AREA_CHANGE:
+		reason = objects.SPF_TRIGGER_AREA_CHANGE_STR
+	case objects.SPF_TRIGGER_AREA_RANGE_CHANGE:
+		reason = objects.SPF_TRIGGER_AREA_RANGE_CHANGE_STR
+	case objects.SPF_TRIGGER_VIRTUAL_LINK_CHANGE:
+		reason = objects.SPF_TRIGGER_VIRTUAL_LINK_CHANGE_STR
+	case objects.SPF_TRIGGER_MAXIMUM_PATHS_CHANGE:
+		reason = objects.SPF_TRIGGER_MAXIMUM_PATHS_CHANGE_STR
+	case objects.SPF_TRIGGER_GRACEFUL_RESTART_EXIT:
+		reason = objects.SPF_TRIGGER_GRACEFUL_RESTART_EXIT_STR
+	}
+	return reason
+}
+
+func convertToRPCFmtRestartExitReason(exitReason uint8) string {
+	var reason string
+	switch exitReason {
+	case objects.RESTART_EXIT_REASON_NONE:
+		reason = objects.RESTART_EXIT_REASON_NONE_STR
+	case objects.RESTART_EXIT_REASON_IN_PROGRESS:
+		reason = objects.RESTART_EXIT_REASON_IN_PROGRESS_STR
+	case objects.RESTART_EXIT_REASON_COMPLETED:
+		reason = objects.RESTART_EXIT_REASON_COMPLETED_STR
+	case objects.RESTART_EXIT_REASON_TIMED_OUT:
+		reason = objects.RESTART_EXIT_REASON_TIMED_OUT_STR
+	case objects.RESTART_EXIT_REASON_TOPOLOGY_CHANGED:
+		reason = objects.RESTART_EXIT_REASON_TOPOLOGY_CHANGED_STR
+	}
+	return reason
+}
+
 func convertToRPCFmtOspfv2GlobalState(obj *objects.Ospfv2GlobalState) *ospfv2d.Ospfv2GlobalState {
+	var restartStatus string
+	switch obj.RestartStatus {
+	case objects.RESTART_STATUS_NOT_RESTARTING:
+		restartStatus = objects.RESTART_STATUS_NOT_RESTARTING_STR
+	case objects.RESTART_STATUS_PLANNED_RESTART:
+		restartStatus = objects.RESTART_STATUS_PLANNED_RESTART_STR
+	}
+	var stubRouterStatus string
+	switch obj.StubRouterStatus {
+	case objects.STUB_ROUTER_STATUS_NONE:
+		stubRouterStatus = objects.STUB_ROUTER_STATUS_NONE_STR
+	case objects.STUB_ROUTER_STATUS_ADMINISTRATIVE:
+		stubRouterStatus = objects.STUB_ROUTER_STATUS_ADMINISTRATIVE_STR
+	case objects.STUB_ROUTER_STATUS_ON_STARTUP:
+		stubRouterStatus = objects.STUB_ROUTER_STATUS_ON_STARTUP_STR
+	}
 	return &ospfv2d.Ospfv2GlobalState{
 		Vrf:                "default",
 		AreaBdrRtrStatus:   obj.AreaBdrRtrStatus,
@@ -151,6 +326,11 @@ func convertToRPCFmtOspfv2GlobalState(obj *objects.Ospfv2GlobalState) *ospfv2d.O
 		NumOfSummary4LSA:   int32(obj.NumOfSummary4LSA),
 		NumOfASExternalLSA: int32(obj.NumOfASExternalLSA),
 		NumOfRoutes:        int32(obj.NumOfRoutes),
+		RestartStatus:      restartStatus,
+		RestartAge:         int32(obj.RestartAge),
+		RestartExitReason:  convertToRPCFmtRestartExitReason(obj.RestartExitReason),
+		StubRouterStatus:   stubRouterStatus,
+		StubRouterTimeLeft: int32(obj.StubRouterTimeLeft),
 	}
 }
 
@@ -178,9 +358,27 @@ func convertFromRPCFmtOspfv2Intf(config *ospfv2d.Ospfv2Intf) (*objects.Ospfv2Int
 		intfType = objects.INTF_TYPE_POINT2POINT
 	case objects.INTF_TYPE_BROADCAST_STR:
 		intfType = objects.INTF_TYPE_BROADCAST
+	case objects.INTF_TYPE_NBMA_STR:
+		intfType = objects.INTF_TYPE_NBMA
+	case objects.INTF_TYPE_POINT2MULTIPOINT_STR:
+		intfType = objects.INTF_TYPE_POINT2MULTIPOINT
 	default:
 		return nil, errors.New("Invalid Interface Type")
 	}
+	if len(config.AuthKey) > objects.AUTH_MD5_KEY_MAX_LEN {
+		return nil, errors.New("Invalid AuthKey length")
+	}
+	var passive uint8
+	switch strings.ToLower(config.Passive) {
+	case objects.INTF_PASSIVE_DEFAULT_STR:
+		passive = objects.INTF_PASSIVE_DEFAULT
+	case objects.INTF_PASSIVE_TRUE_STR:
+		passive = objects.INTF_PASSIVE_TRUE
+	case objects.INTF_PASSIVE_FALSE_STR:
+		passive = objects.INTF_PASSIVE_FALSE
+	default:
+		return nil, errors.New("Invalid Passive")
+	}
 	return &objects.Ospfv2Intf{
 		IpAddress:        ipAddr,
 		AddressLessIfIdx: uint32(config.AddressLessIfIdx),
@@ -193,6 +391,200 @@ func convertFromRPCFmtOspfv2Intf(config *ospfv2d.Ospfv2Intf) (*objects.Ospfv2Int
 		HelloInterval:    uint16(config.HelloInterval),
 		RtrDeadInterval:  uint32(config.RtrDeadInterval),
 		MetricValue:      uint16(config.MetricValue),
+		AuthKey:          config.AuthKey,
+		AuthKeyId:        uint8(config.AuthKeyId),
+		PollInterval:     uint32(config.PollInterval),
+		BfdEnable:        config.BfdEnable,
+		Passive:          passive,
+	}, nil
+}
+
+func convertFromRPCFmtOspfv2IntfAuthKey(config *ospfv2d.Ospfv2IntfAuthKey) (*objects.Ospfv2IntfAuthKey, error) {
+	ipAddr, err := convertDotNotationToUint32(config.IpAddress)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintln("Invalid IpAddress", err))
+	}
+	if len(config.Key) > objects.AUTH_MD5_KEY_MAX_LEN {
+		return nil, errors.New("MD5 key cannot be longer than 16 characters")
+	}
+	if config.GenerateDelay < 0 {
+		return nil, errors.New("Invalid GenerateDelay")
+	}
+	return &objects.Ospfv2IntfAuthKey{
+		IpAddress:        ipAddr,
+		AddressLessIfIdx: uint32(config.AddressLessIfIdx),
+		KeyId:            uint8(config.KeyId),
+		Key:              config.Key,
+		GenerateDelay:    uint32(config.GenerateDelay),
+	}, nil
+}
+
+func convertFromRPCFmtOspfv2Nbr(config *ospfv2d.Ospfv2Nbr) (*objects.Ospfv2Nbr, error) {
+	ipAddr, err := convertDotNotationToUint32(config.IpAddr)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintln("Invalid IpAddr", err))
+	}
+	if ipAddr == 0 {
+		return nil, errors.New("Invalid IpAddr")
+	}
+	return &objects.Ospfv2Nbr{
+		IpAddr:           ipAddr,
+		AddressLessIfIdx: uint32(config.AddressLessIfIdx),
+		RtrPriority:      uint8(config.RtrPriority),
+	}, nil
+}
+
+func convertFromRPCFmtOspfv2VirtualLink(config *ospfv2d.Ospfv2VirtualLink) (*objects.Ospfv2VirtualLink, error) {
+	areaId, err := convertDotNotationToUint32(config.AreaId)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintln("Invalid AreaId", err))
+	}
+	if areaId == 0 {
+		return nil, errors.New("Invalid AreaId, backbone cannot be a transit area")
+	}
+	nbrRouterId, err := convertDotNotationToUint32(config.NbrRouterId)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintln("Invalid NbrRouterId", err))
+	}
+	if nbrRouterId == 0 {
+		return nil, errors.New("Invalid NbrRouterId")
+	}
+	var adminState bool
+	switch strings.ToLower(config.AdminState) {
+	case objects.INTF_ADMIN_STATE_UP_STR:
+		adminState = objects.INTF_ADMIN_STATE_UP
+	case objects.INTF_ADMIN_STATE_DOWN_STR:
+		adminState = objects.INTF_ADMIN_STATE_DOWN
+	default:
+		return nil, errors.New("Invalid AdminState")
+	}
+	if len(config.AuthKey) > objects.AUTH_MD5_KEY_MAX_LEN {
+		return nil, errors.New("Invalid AuthKey length")
+	}
+	return &objects.Ospfv2VirtualLink{
+		AreaId:          areaId,
+		NbrRouterId:     nbrRouterId,
+		AdminState:      adminState,
+		TransitDelay:    uint16(config.TransitDelay),
+		RetransInterval: uint16(config.RetransInterval),
+		HelloInterval:   uint16(config.HelloInterval),
+		RtrDeadInterval: uint32(config.RtrDeadInterval),
+		AuthKey:         config.AuthKey,
+		AuthKeyId:       uint8(config.AuthKeyId),
+	}, nil
+}
+
+func convertFromRPCFmtMetricType(metricType string) (uint8, error) {
+	switch strings.ToLower(metricType) {
+	case objects.METRIC_TYPE_NONE_STR:
+		return objects.METRIC_TYPE_NONE, nil
+	case objects.METRIC_TYPE_E1_STR:
+		return objects.METRIC_TYPE_E1, nil
+	case objects.METRIC_TYPE_E2_STR:
+		return objects.METRIC_TYPE_E2, nil
+	}
+	return objects.METRIC_TYPE_NONE, errors.New("Invalid MetricType")
+}
+
+func convertFromRPCFmtOspfv2Redistribution(config *ospfv2d.Ospfv2Redistribution) (*objects.Ospfv2Redistribution, error) {
+	protocol := strings.ToUpper(config.Protocol)
+	switch protocol {
+	case objects.REDIST_PROTOCOL_CONNECTED,
+		objects.REDIST_PROTOCOL_STATIC,
+		objects.REDIST_PROTOCOL_BGP:
+	default:
+		return nil, errors.New("Invalid Protocol")
+	}
+	if config.Metric < 0 || config.Metric > 0xffffff {
+		return nil, errors.New("Invalid Metric")
+	}
+	metricType, err := convertFromRPCFmtMetricType(config.MetricType)
+	if err != nil {
+		return nil, err
+	}
+	if metricType == objects.METRIC_TYPE_NONE {
+		metricType = objects.METRIC_TYPE_E2
+	}
+	if config.Tag < 0 {
+		return nil, errors.New("Invalid Tag")
+	}
+	return &objects.Ospfv2Redistribution{
+		Protocol:   protocol,
+		Metric:     uint32(config.Metric),
+		MetricType: metricType,
+		Tag:        uint32(config.Tag),
+		RouteMap:   config.RouteMap,
+	}, nil
+}
+
+func convertFromRPCFmtOspfv2RouteMap(config *ospfv2d.Ospfv2RouteMap) (*objects.Ospfv2RouteMap, error) {
+	if config.Name == "" {
+		return nil, errors.New("Invalid Name")
+	}
+	if config.Sequence < 1 || config.Sequence > 65535 {
+		return nil, errors.New("Invalid Sequence")
+	}
+	var permit bool
+	switch strings.ToLower(config.Action) {
+	case objects.ROUTE_MAP_ACTION_PERMIT_STR:
+		permit = true
+	case objects.ROUTE_MAP_ACTION_DENY_STR:
+		permit = false
+	default:
+		return nil, errors.New("Invalid Action")
+	}
+	var matchIpPrefix, matchNetmask uint32
+	matchPrefix := config.MatchIpPrefix != ""
+	if matchPrefix {
+		var err error
+		matchIpPrefix, err = convertDotNotationToUint32(config.MatchIpPrefix)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintln("Invalid MatchIpPrefix", err))
+		}
+		matchNetmask, err = convertDotNotationToUint32(config.MatchNetmask)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintln("Invalid MatchNetmask", err))
+		}
+		if (^matchNetmask)&(^matchNetmask+1) != 0 {
+			return nil, errors.New("Invalid MatchNetmask, non contiguous mask")
+		}
+		if matchIpPrefix&matchNetmask != matchIpPrefix {
+			return nil, errors.New("Invalid MatchIpPrefix, host bits are set")
+		}
+	}
+	if config.MatchTag < 0 {
+		return nil, errors.New("Invalid MatchTag")
+	}
+	if config.SetMetric < 0 || config.SetMetric > 0xffffff {
+		return nil, errors.New("Invalid SetMetric")
+	}
+	setMetricType, err := convertFromRPCFmtMetricType(config.SetMetricType)
+	if err != nil {
+		return nil, errors.New("Invalid SetMetricType")
+	}
+	if config.SetTag < 0 {
+		return nil, errors.New("Invalid SetTag")
+	}
+	var setFwdAddr uint32
+	if config.SetFwdAddr != "" {
+		setFwdAddr, err = convertDotNotationToUint32(config.SetFwdAddr)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintln("Invalid SetFwdAddr", err))
+		}
+	}
+	return &objects.Ospfv2RouteMap{
+		Name:                config.Name,
+		Sequence:            uint32(config.Sequence),
+		Permit:              permit,
+		MatchPrefix:         matchPrefix,
+		MatchIpPrefix:       matchIpPrefix,
+		MatchNetmask:        matchNetmask,
+		MatchLongerPrefixes: config.MatchLongerPrefixes,
+		MatchTag:            uint32(config.MatchTag),
+		SetMetric:           uint32(config.SetMetric),
+		SetMetricType:       setMetricType,
+		SetTag:              uint32(config.SetTag),
+		SetFwdAddr:          setFwdAddr,
 	}, nil
 }
 
@@ -239,6 +631,25 @@ func convertToRPCFmtOspfv2IntfState(obj *objects.Ospfv2IntfState) *ospfv2d.Ospfv
 		Cost:                     int32(obj.Cost),
 		NumOfStateChange:         int32(obj.NumOfStateChange),
 		TimeOfStateChange:        obj.TimeOfStateChange,
+		NumOfAuthTypeMismatch:    int32(obj.NumOfAuthTypeMismatch),
+		NumOfAuthFailures:        int32(obj.NumOfAuthFailures),
+		NumOfAuthReplayDrops:     int32(obj.NumOfAuthReplayDrops),
+		NumOfHelloRx:             int32(obj.NumOfHelloRx),
+		NumOfHelloTx:             int32(obj.NumOfHelloTx),
+		NumOfDbdRx:               int32(obj.NumOfDbdRx),
+		NumOfDbdTx:               int32(obj.NumOfDbdTx),
+		NumOfLsReqRx:             int32(obj.NumOfLsReqRx),
+		NumOfLsReqTx:             int32(obj.NumOfLsReqTx),
+		NumOfLsUpdRx:             int32(obj.NumOfLsUpdRx),
+		NumOfLsUpdTx:             int32(obj.NumOfLsUpdTx),
+		NumOfLsAckRx:             int32(obj.NumOfLsAckRx),
+		NumOfLsAckTx:             int32(obj.NumOfLsAckTx),
+		NumOfRetransmits:         int32(obj.NumOfRetransmits),
+		NumOfMtuMismatch:         int32(obj.NumOfMtuMismatch),
+		NumOfAreaMismatch:        int32(obj.NumOfAreaMismatch),
+		NumOfHelloIntvlMismatch:  int32(obj.NumOfHelloIntvlMismatch),
+		NumOfDeadIntvlMismatch:   int32(obj.NumOfDeadIntvlMismatch),
+		NumOfBadChecksum:         int32(obj.NumOfBadChecksum),
 	}
 }
 
@@ -294,6 +705,31 @@ func convertToRPCFmtOspfv2LsdbState(obj *objects.Ospfv2LsdbState) *ospfv2d.Ospfv
 	}
 }
 
+func convertToRPCFmtNbrEvent(event uint8) string {
+	var reason string
+	switch event {
+	case objects.NBR_EVENT_NONE:
+		reason = objects.NBR_EVENT_NONE_STR
+	case objects.NBR_EVENT_HELLO_RECEIVED:
+		reason = objects.NBR_EVENT_HELLO_RECEIVED_STR
+	case objects.NBR_EVENT_TWO_WAY_RECEIVED:
+		reason = objects.NBR_EVENT_TWO_WAY_RECEIVED_STR
+	case objects.NBR_EVENT_ONE_WAY_RECEIVED:
+		reason = objects.NBR_EVENT_ONE_WAY_RECEIVED_STR
+	case objects.NBR_EVENT_ADJ_OK:
+		reason = objects.NBR_EVENT_ADJ_OK_STR
+	case objects.NBR_EVENT_NEGOTIATION_DONE:
+		reason = objects.NBR_EVENT_NEGOTIATION_DONE_STR
+	case objects.NBR_EVENT_EXCHANGE_DONE:
+		reason = objects.NBR_EVENT_EXCHANGE_DONE_STR
+	case objects.NBR_EVENT_LOADING_DONE:
+		reason = objects.NBR_EVENT_LOADING_DONE_STR
+	case objects.NBR_EVENT_SEQ_NUMBER_MISMATCH:
+		reason = objects.NBR_EVENT_SEQ_NUMBER_MISMATCH_STR
+	}
+	return reason
+}
+
 func convertToRPCFmtOspfv2NbrState(obj *objects.Ospfv2NbrState) *ospfv2d.Ospfv2NbrState {
 	ipAddr := convertUint32ToDotNotation(obj.IpAddr)
 	rtrId := convertUint32ToDotNotation(obj.RtrId)
@@ -318,12 +754,67 @@ func convertToRPCFmtOspfv2NbrState(obj *objects.Ospfv2NbrState) *ospfv2d.Ospfv2N
 	case objects.NBR_STATE_FULL:
 		state = strings.ToUpper(objects.NBR_STATE_FULL_STR)
 	}
+	var helperStatus string
+	switch obj.RestartHelperStatus {
+	case objects.RESTART_HELPER_STATUS_NOT_HELPING:
+		helperStatus = objects.RESTART_HELPER_STATUS_NOT_HELPING_STR
+	case objects.RESTART_HELPER_STATUS_HELPING:
+		helperStatus = objects.RESTART_HELPER_STATUS_HELPING_STR
+	}
+	var resync string
+	switch obj.RestartResync {
+	case objects.RESTART_RESYNC_NONE:
+		resync = objects.RESTART_RESYNC_NONE_STR
+	case objects.RESTART_RESYNC_IN_PROGRESS:
+		resync = objects.RESTART_RESYNC_IN_PROGRESS_STR
+	case objects.RESTART_RESYNC_COMPLETED:
+		resync = objects.RESTART_RESYNC_COMPLETED_STR
+	}
+	var bfdState string
+	switch obj.BfdState {
+	case objects.BFD_STATE_NONE:
+		bfdState = objects.BFD_STATE_NONE_STR
+	case objects.BFD_STATE_INIT:
+		bfdState = objects.BFD_STATE_INIT_STR
+	case objects.BFD_STATE_UP:
+		bfdState = objects.BFD_STATE_UP_STR
+	case objects.BFD_STATE_DOWN:
+		bfdState = objects.BFD_STATE_DOWN_STR
+	}
 	return &ospfv2d.Ospfv2NbrState{
-		IpAddr:           ipAddr,
-		AddressLessIfIdx: int32(obj.AddressLessIfIdx),
-		RtrId:            rtrId,
-		Options:          obj.Options,
-		State:            state,
+		IpAddr:                  ipAddr,
+		AddressLessIfIdx:        int32(obj.AddressLessIfIdx),
+		RtrId:                   rtrId,
+		Options:                 obj.Options,
+		State:                   state,
+		RestartHelperStatus:     helperStatus,
+		RestartHelperAge:        int32(obj.RestartHelperAge),
+		RestartHelperExitReason: convertToRPCFmtRestartExitReason(obj.RestartHelperExitReason),
+		RestartResync:           resync,
+		BfdState:                bfdState,
+		Uptime:                  int32(obj.Uptime),
+		LastStateChangeReason:   convertToRPCFmtNbrEvent(obj.LastStateChangeReason),
+		DesignatedRouter:        convertUint32ToDotNotation(obj.DesignatedRouter),
+		BackupDesignatedRouter:  convertUint32ToDotNotation(obj.BackupDesignatedRouter),
+		RetransmitListLen:       int32(obj.RetransmitListLen),
+		DeadTimerRemaining:      int32(obj.DeadTimerRemaining),
+		NumOfAuthFailures:       int32(obj.NumOfAuthFailures),
+		NumOfHelloRx:            int32(obj.NumOfHelloRx),
+		NumOfHelloTx:            int32(obj.NumOfHelloTx),
+		NumOfDbdRx:              int32(obj.NumOfDbdRx),
+		NumOfDbdTx:              int32(obj.NumOfDbdTx),
+		NumOfLsReqRx:            int32(obj.NumOfLsReqRx),
+		NumOfLsReqTx:            int32(obj.NumOfLsReqTx),
+		NumOfLsUpdRx:            int32(obj.NumOfLsUpdRx),
+		NumOfLsUpdTx:            int32(obj.NumOfLsUpdTx),
+		NumOfLsAckRx:            int32(obj.NumOfLsAckRx),
+		NumOfLsAckTx:            int32(obj.NumOfLsAckTx),
+		NumOfRetransmits:        int32(obj.NumOfRetransmits),
+		NumOfMtuMismatch:        int32(obj.NumOfMtuMismatch),
+		NumOfAreaMismatch:       int32(obj.NumOfAreaMismatch),
+		NumOfHelloIntvlMismatch: int32(obj.NumOfHelloIntvlMismatch),
+		NumOfDeadIntvlMismatch:  int32(obj.NumOfDeadIntvlMismatch),
+		NumOfBadChecksum:        int32(obj.NumOfBadChecksum),
 	}
 }
 
@@ -24,9 +24,7 @@
 package server
 
 import (
-	"github.com/google/gopacket/pcap"
 	"net"
-	"sync"
 	"time"
 )
 
@@ -87,12 +85,11 @@ type NbrConfKey struct {
 }
 
 type IntfTxHandle struct {
-	SendPcapHdl *pcap.Handle
-	SendMutex   sync.Mutex
+	SendHdl PktTxHandle
 }
 
 type IntfRxHandle struct {
-	RecvPcapHdl        *pcap.Handle
+	RecvHdl            PktRxHandle
 	PktRecvCtrlCh      chan bool
 	PktRecvCtrlReplyCh chan bool
 }
@@ -132,6 +129,11 @@ type GenerateRouterLSAMsg struct {
 type NbrDownMsg struct {
 	NbrKey NbrConfKey
 }
+
+// Nbrs are killed from the nbr FSM as well, which must not
+// block on an intf FSM that is sending to it
+const NBR_DOWN_MSG_QUEUE_LEN = 10
+
 type RecvdLsaMsgType uint8
 
 const (
@@ -168,6 +170,16 @@ type UpdateSelfNetworkLSAMsg struct {
 	NbrList []uint32
 }
 
+type OpaqueLsaMsg struct {
+	Op         LsaOp
+	LSType     uint8
+	AreaId     uint32      // Area local scope
+	IntfKey    IntfConfKey // Link local scope
+	OpaqueType uint8
+	OpaqueId   uint32
+	Data       []byte
+}
+
 type LsdbToFloodLSAMsg struct {
 	AreaId  uint32
 	LsaKey  LsaKey
@@ -234,7 +246,7 @@ type LsdbToFloodChStruct struct {
 }
 
 type LsdbToSPFChStruct struct {
-	StartSPF chan bool
+	StartSPF chan SpfCalcMsg
 }
 
 type SPFToLsdbChStruct struct {
@@ -245,11 +257,19 @@ type ServerToLsdbChStruct struct {
 	RefreshLsdbSliceCh    chan bool
 	RouteInfoDataUpdateCh chan RouteInfoDataUpdateMsg
 	InitAreaLsdbCh        chan uint32
+	AreaRangeUpdateCh     chan bool
+	VirtualLinkUpdateCh   chan bool
+	GracefulRestartExitCh chan bool
+	OpaqueLsaUpdateCh     chan OpaqueLsaMsg
+	MaximumPathsUpdateCh  chan bool
+	SpfThrottleUpdateCh   chan SpfThrottleMsg
+	StubRouterUpdateCh    chan StubRouterMsg
 }
 
 type LsdbToServerChStruct struct {
 	RefreshLsdbSliceDoneCh chan bool
 	InitAreaLsdbDoneCh     chan bool
+	VirtualLinkChangeCh    chan VirtualLinkChangeMsg
 }
 
 type RouteTblToDBClntChStruct struct {
@@ -49,19 +49,24 @@ const (
 	//LSSequenceNumber      int           = InitialSequenceNumber
 	LSInfinity                 uint32 = 0x00ffffff
 	FLETCHER_CHECKSUM_VALIDATE uint16 = 0xffff
+	OSPF_DEFAULT_STUB_COST     uint32 = 1
+	VIRTUAL_LINK_TTL           uint8  = 64
 )
 
 const (
-	OSPF_HELLO_MIN_SIZE        = 20
-	OSPF_DBD_MIN_SIZE          = 8
-	OSPF_LSA_HEADER_SIZE       = 20
-	OSPF_LSA_REQ_SIZE          = 12
-	OSPF_LSA_ACK_SIZE          = 20
-	OSPF_HEADER_SIZE           = 24
-	IP_HEADER_MIN_LEN          = 20
-	OSPF_PROTO_ID        uint8 = 89
-	OSPF_VERSION_2       uint8 = 2
-	OSPF_NO_OF_LSA_FIELD       = 4
+	OSPF_HELLO_MIN_SIZE             = 20
+	OSPF_DBD_MIN_SIZE               = 8
+	OSPF_LSA_HEADER_SIZE            = 20
+	OSPF_LSA_REQ_SIZE               = 12
+	OSPF_LSA_ACK_SIZE               = 20
+	OSPF_HEADER_SIZE                = 24
+	IP_HEADER_MIN_LEN               = 20
+	OSPF_PROTO_ID             uint8 = 89
+	OSPF_VERSION_2            uint8 = 2
+	OSPF_NO_OF_LSA_FIELD            = 4
+	OSPF_AUTH_SIMPLE_PASS_LEN       = 8
+	OSPF_AUTH_MD5_KEY_LEN           = 16
+	OSPF_AUTH_MD5_DIGEST_LEN        = 16
 )
 
 const (
@@ -91,8 +96,9 @@ const (
 	EOption  = 0x02
 	MCOption = 0x04
 	NPOption = 0x08
-	EAOption = 0x20
-	DCOption = 0x40
+	EAOption = 0x10
+	DCOption = 0x20
+	OOption  = 0x40
 )
 
 const (
@@ -118,6 +124,12 @@ const (
 	LSAROUTERFLOOD          = 6 //flood only router LSA
 )
 
+const (
+	// Area range discard routes are installed under their own protocol
+	// so that they neither replace nor remove OSPF routes to the range
+	OSPF_DISCARD_ROUTE_PROTOCOL = "OSPF_DISCARD"
+)
+
 const (
 	AllSPFRouters = "224.0.0.5"
 	AllDRouters   = "224.0.0.6"
@@ -42,7 +42,37 @@ func (server *OSPFV2Server) HandleASExternalLsa(areaId uint32) {
 		return
 	}
 
-	for lsaKey, lsaEnt := range lsDbEnt.ASExternalLsaMap {
+	server.calcASExternalRoutes(areaId, lsDbEnt.ASExternalLsaMap)
+	// Rfc 3101 2.5: Type-7 LSAs are used for routing within NSSA
+	server.calcASExternalRoutes(areaId, lsDbEnt.NssaLsaMap)
+}
+
+// getFwdAddrRoutingTblEnt returns the intra or inter area route
+// towards the forwarding address of an external LSA
+func getFwdAddrRoutingTblEnt(tbl AreaRoutingTbl, fwdAddr uint32) (RoutingTblEntry, bool) {
+	var bestEnt RoutingTblEntry
+	var bestMask uint32
+	found := false
+	for rKey, rEnt := range tbl.RoutingTblMap {
+		if rKey.DestType != Network ||
+			(rEnt.PathType != IntraArea &&
+				rEnt.PathType != InterArea) {
+			continue
+		}
+		if fwdAddr&rKey.AddrMask != rKey.DestId {
+			continue
+		}
+		if !found || rKey.AddrMask > bestMask {
+			bestEnt = rEnt
+			bestMask = rKey.AddrMask
+			found = true
+		}
+	}
+	return bestEnt, found
+}
+
+func (server *OSPFV2Server) calcASExternalRoutes(areaId uint32, lsaMap map[LsaKey]ASExternalLsa) {
+	for lsaKey, lsaEnt := range lsaMap {
 		server.logger.Info("AS External LSAKey:", lsaKey, "lsaENt:", lsaEnt)
 		if lsaEnt.Metric == LSInfinity ||
 			lsaEnt.LsaMd.LSAge == MAX_AGE {
@@ -86,12 +116,15 @@ func (server *OSPFV2Server) HandleASExternalLsa(areaId uint32) {
 			}
 		} else {
 			// Packet should be sent to forwarding address
-			rKey = RoutingTblEntryKey{
-				DestId:   lsaEnt.FwdAddr,
-				AddrMask: 0,
-				DestType: ASBdrRouter,
+			rEnt, exist = getFwdAddrRoutingTblEnt(tempAreaRoutingTbl, lsaEnt.FwdAddr)
+			if !exist {
+				rKey = RoutingTblEntryKey{
+					DestId:   lsaEnt.FwdAddr,
+					AddrMask: 0,
+					DestType: ASBdrRouter,
+				}
+				rEnt, exist = tempAreaRoutingTbl.RoutingTblMap[rKey]
 			}
-			rEnt, exist = tempAreaRoutingTbl.RoutingTblMap[rKey]
 			if !exist {
 				server.logger.Info("AS Border Router routing table entry doesnot exists for AS External Lsa Advertising Router")
 				rKey = RoutingTblEntryKey{
@@ -110,9 +143,8 @@ func (server *OSPFV2Server) HandleASExternalLsa(areaId uint32) {
 			continue
 		}
 
-		cost := rEnt.Cost + uint16(lsaEnt.Metric)
+		cost := rEnt.Cost + lsaEnt.Metric
 		nextHopMap := rEnt.NextHops
-		numOfNextHops := rEnt.NumOfPaths
 		rKey = RoutingTblEntryKey{
 			DestId:   lsaKey.LSId & lsaEnt.Netmask,
 			AddrMask: lsaEnt.Netmask,
@@ -151,25 +183,12 @@ func (server *OSPFV2Server) HandleASExternalLsa(areaId uint32) {
 				//rEnt.PathType = InterArea
 				rEnt.PathType = pathType
 				rEnt.Cost = cost
-				rEnt.Type2Cost = uint16(lsaEnt.Metric)
+				rEnt.Type2Cost = lsaEnt.Metric
 				//rEnt.LSOrigin = lsaKey
-				rEnt.NumOfPaths = numOfNextHops
-				rEnt.NextHops = make(map[NextHop]bool)
-				for key, _ := range nextHopMap {
-					key.AdvRtr = lsaKey.AdvRouter
-					rEnt.NextHops[key] = true
-				}
+				setNextHops(&rEnt, nextHopMap, lsaKey.AdvRouter)
 			} else {
-				cnt := 0
-				for key, _ := range nextHopMap {
-					_, exist = rEnt.NextHops[key]
-					if !exist {
-						key.AdvRtr = lsaKey.AdvRouter
-						rEnt.NextHops[key] = true
-						cnt++
-					}
-				}
-				rEnt.NumOfPaths = numOfNextHops + cnt
+				// Equal cost path, add its next hops
+				mergeNextHops(&rEnt, nextHopMap, lsaKey.AdvRouter)
 			}
 		} else {
 			rEnt.OptCapabilities = 0 //TODO
@@ -179,14 +198,9 @@ func (server *OSPFV2Server) HandleASExternalLsa(areaId uint32) {
 				rEnt.PathType = Type1Ext
 			}
 			rEnt.Cost = cost
-			rEnt.Type2Cost = uint16(lsaEnt.Metric)
+			rEnt.Type2Cost = lsaEnt.Metric
 			//rEnt.LSOrigin = lsaKey
-			rEnt.NumOfPaths = numOfNextHops
-			rEnt.NextHops = make(map[NextHop]bool)
-			for key, _ := range nextHopMap {
-				key.AdvRtr = lsaKey.AdvRouter
-				rEnt.NextHops[key] = true
-			}
+			setNextHops(&rEnt, nextHopMap, lsaKey.AdvRouter)
 		}
 		tempAreaRoutingTbl.RoutingTblMap[rKey] = rEnt
 		server.RoutingTblData.TempAreaRoutingTbl[areaIdKey] = tempAreaRoutingTbl
@@ -247,9 +261,8 @@ func (server *OSPFV2Server) CalcASBorderRoutes(areaId uint32) {
 		if rEnt.NumOfPaths == 0 {
 			continue
 		}
-		cost := rEnt.Cost + uint16(lsaEnt.Metric)
+		cost := rEnt.Cost + lsaEnt.Metric
 		nextHopMap := rEnt.NextHops
-		numOfNextHops := rEnt.NumOfPaths
 		rKey = RoutingTblEntryKey{
 			DestId:   lsaKey.LSId,
 			AddrMask: 0,
@@ -271,23 +284,10 @@ func (server *OSPFV2Server) CalcASBorderRoutes(areaId uint32) {
 				rEnt.Cost = cost
 				//rEnt.Type2Cost = 0
 				//rEnt.LSOrigin = lsaKey
-				rEnt.NumOfPaths = numOfNextHops
-				rEnt.NextHops = make(map[NextHop]bool)
-				for key, _ := range nextHopMap {
-					key.AdvRtr = 0
-					rEnt.NextHops[key] = true
-				}
+				setNextHops(&rEnt, nextHopMap, 0)
 			} else {
-				cnt := 0
-				for key, _ := range nextHopMap {
-					_, exist = rEnt.NextHops[key]
-					if !exist {
-						key.AdvRtr = 0
-						rEnt.NextHops[key] = true
-						cnt++
-					}
-				}
-				rEnt.NumOfPaths = numOfNextHops + cnt
+				// Equal cost path, add its next hops
+				mergeNextHops(&rEnt, nextHopMap, 0)
 			}
 		} else {
 			rEnt.OptCapabilities = 0 //TODO
@@ -295,12 +295,7 @@ func (server *OSPFV2Server) CalcASBorderRoutes(areaId uint32) {
 			rEnt.Cost = cost
 			rEnt.Type2Cost = 0
 			//rEnt.LSOrigin = lsaKey
-			rEnt.NumOfPaths = numOfNextHops
-			rEnt.NextHops = make(map[NextHop]bool)
-			for key, _ := range nextHopMap {
-				key.AdvRtr = 0
-				rEnt.NextHops[key] = true
-			}
+			setNextHops(&rEnt, nextHopMap, 0)
 		}
 		tempAreaRoutingTbl.RoutingTblMap[rKey] = rEnt
 		server.RoutingTblData.TempAreaRoutingTbl[areaIdKey] = tempAreaRoutingTbl
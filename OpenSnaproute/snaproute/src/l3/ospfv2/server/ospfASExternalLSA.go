@@ -137,9 +137,9 @@ func (server *OSPFV2Server) generateASExternalLSA(routeInfo RouteInfo) {
 	var asLsaEnt ASExternalLsa
 	asLsaEnt.LsaMd.LSAge = 0
 	asLsaEnt.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + 16)
-	asLsaEnt.BitE = true
-	asLsaEnt.ExtRouteTag = 0
-	asLsaEnt.FwdAddr = 0
+	asLsaEnt.BitE = routeInfo.BitE
+	asLsaEnt.ExtRouteTag = routeInfo.Tag
+	asLsaEnt.FwdAddr = routeInfo.FwdAddr
 	asLsaEnt.Metric = routeInfo.Metric
 	asLsaEnt.Netmask = routeInfo.Netmask
 	asLsaEnt.LsaMd.Options = EOption
@@ -152,13 +152,14 @@ func (server *OSPFV2Server) generateASExternalLSA(routeInfo RouteInfo) {
 			server.logger.Err("No Lsdb Exist for:", lsdbKey)
 			continue
 		}
-		lsaEnt, exist := lsdbEnt.ASExternalLsaMap[lsaKey]
-		lsaEnt = asLsaEnt
+		oldLsaEnt, exist := lsdbEnt.ASExternalLsaMap[lsaKey]
+		lsaEnt := asLsaEnt
 		lsaEnt.LsaMd.LSChecksum = 0
 		if exist {
-			lsaEnt.LsaMd.LSSequenceNum = int(InitialSequenceNum)
+			// Policy change, replace the LSA with a newer instance
+			lsaEnt.LsaMd.LSSequenceNum = oldLsaEnt.LsaMd.LSSequenceNum + 1
 		} else {
-			lsaEnt.LsaMd.LSSequenceNum = lsaEnt.LsaMd.LSSequenceNum + 1
+			lsaEnt.LsaMd.LSSequenceNum = int(InitialSequenceNum)
 		}
 		lsaEnc := encodeASExternalLsa(lsaEnt, lsaKey)
 		lsaEnt.LsaMd.LSChecksum = computeFletcherChecksum(lsaEnc[2:], checksumOffset)
@@ -261,9 +262,9 @@ func (server *OSPFV2Server) GenerateAllASExternalLSA(areaId uint32) {
 		lsaEnt.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + 16)
 		lsaEnt.LsaMd.LSSequenceNum = int(InitialSequenceNum)
 		lsaEnt.LsaMd.Options = EOption
-		lsaEnt.BitE = true
-		lsaEnt.ExtRouteTag = 0
-		lsaEnt.FwdAddr = 0
+		lsaEnt.BitE = route.BitE
+		lsaEnt.ExtRouteTag = route.Tag
+		lsaEnt.FwdAddr = route.FwdAddr
 		lsaEnt.Metric = route.Metric
 		lsaEnt.Netmask = route.Netmask
 		checksumOffset := uint16(14)
@@ -320,9 +321,9 @@ func (server *OSPFV2Server) reGenerateASExternalLSAForGivenArea(routeInfo RouteI
 	}
 	lsaEnt.LsaMd.LSAge = 0
 	lsaEnt.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + 16)
-	lsaEnt.BitE = true
-	lsaEnt.ExtRouteTag = 0
-	lsaEnt.FwdAddr = 0
+	lsaEnt.BitE = routeInfo.BitE
+	lsaEnt.ExtRouteTag = routeInfo.Tag
+	lsaEnt.FwdAddr = routeInfo.FwdAddr
 	lsaEnt.Metric = routeInfo.Metric
 	lsaEnt.Netmask = routeInfo.Netmask
 	lsaEnt.LsaMd.Options = EOption
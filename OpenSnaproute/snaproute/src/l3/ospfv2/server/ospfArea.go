@@ -29,9 +29,16 @@ import (
 )
 
 type AreaConf struct {
-	AdminState     bool
-	AuthType       uint8
-	ImportASExtern bool
+	AdminState bool
+	AuthType   uint8
+	// ImportASExtern is the effective external routing capability,
+	// it is always false for stub, totally stub and NSSA areas
+	ImportASExtern  bool
+	AreaType        uint8
+	StubDefaultCost uint32
+	// Rfc 3101 NSSATranslatorRole and Type-7 default origination
+	NssaTranslatorRole   uint8
+	NssaDefaultOriginate bool
 	//NumSpfRuns       uint32
 	//NumBdrRtr        uint32
 	//NumAsBdrRtr      uint32
@@ -50,7 +57,11 @@ func genOspfv2AreaUpdateMask(attrset []bool) uint32 {
 
 	if attrset == nil {
 		mask = objects.OSPFV2_AREA_UPDATE_AUTH_TYPE |
-			objects.OSPFV2_AREA_UPDATE_IMPORT_AS_EXTERN
+			objects.OSPFV2_AREA_UPDATE_IMPORT_AS_EXTERN |
+			objects.OSPFV2_AREA_UPDATE_AREA_TYPE |
+			objects.OSPFV2_AREA_UPDATE_STUB_DEFAULT_COST |
+			objects.OSPFV2_AREA_UPDATE_NSSA_TRANSLATOR |
+			objects.OSPFV2_AREA_UPDATE_NSSA_DEFAULT
 	} else {
 		for idx, val := range attrset {
 			if val == true {
@@ -63,6 +74,14 @@ func genOspfv2AreaUpdateMask(attrset []bool) uint32 {
 					mask |= objects.OSPFV2_AREA_UPDATE_AUTH_TYPE
 				case 3:
 					mask |= objects.OSPFV2_AREA_UPDATE_IMPORT_AS_EXTERN
+				case 4:
+					mask |= objects.OSPFV2_AREA_UPDATE_AREA_TYPE
+				case 5:
+					mask |= objects.OSPFV2_AREA_UPDATE_STUB_DEFAULT_COST
+				case 6:
+					mask |= objects.OSPFV2_AREA_UPDATE_NSSA_TRANSLATOR
+				case 7:
+					mask |= objects.OSPFV2_AREA_UPDATE_NSSA_DEFAULT
 				}
 			}
 		}
@@ -70,6 +89,13 @@ func genOspfv2AreaUpdateMask(attrset []bool) uint32 {
 	return mask
 }
 
+func getAreaStubDefaultCost(cost uint32) uint32 {
+	if cost == 0 {
+		return OSPF_DEFAULT_STUB_COST
+	}
+	return cost
+}
+
 func (server *OSPFV2Server) isAreaBDR() bool {
 	cnt := 0
 	for _, areaEnt := range server.AreaConfMap {
@@ -95,6 +121,24 @@ func (server *OSPFV2Server) updateArea(newCfg, oldCfg *objects.Ospfv2Area, attrs
 		return false, errors.New("Cannot update, area doesnot exist")
 	}
 
+	mask := genOspfv2AreaUpdateMask(attrset)
+	if mask&objects.OSPFV2_AREA_UPDATE_AREA_TYPE == objects.OSPFV2_AREA_UPDATE_AREA_TYPE &&
+		newCfg.AreaType != objects.AREA_TYPE_NORMAL &&
+		server.areaHasVirtualLink(newCfg.AreaId) {
+		server.logger.Err("Cannot update area type, area is the transit area of virtual links")
+		return false, errors.New("Cannot update area type, area is the transit area of virtual links")
+	}
+	if mask&objects.OSPFV2_AREA_UPDATE_AUTH_TYPE == objects.OSPFV2_AREA_UPDATE_AUTH_TYPE {
+		for intfKey, _ := range oldAreaEnt.IntfMap {
+			intfEnt, _ := server.IntfConfMap[intfKey]
+			err := validateIntfAuthKey(newCfg.AuthType, decodeIntfAuthKey(intfEnt.AuthKey))
+			if err != nil {
+				server.logger.Err("Cannot update area AuthType, Intf", intfKey, err)
+				return false, err
+			}
+		}
+	}
+
 	if oldAreaEnt.AdminState == true &&
 		server.globalData.AdminState == true {
 		//This will cause Nbrs to be deleted from NbrFSM
@@ -105,15 +149,31 @@ func (server *OSPFV2Server) updateArea(newCfg, oldCfg *objects.Ospfv2Area, attrs
 
 	oldAreaEnt, _ = server.AreaConfMap[newCfg.AreaId]
 	newAreaEnt := oldAreaEnt
-	mask := genOspfv2AreaUpdateMask(attrset)
 	if mask&objects.OSPFV2_AREA_UPDATE_ADMIN_STATE == objects.OSPFV2_AREA_UPDATE_ADMIN_STATE {
 		newAreaEnt.AdminState = newCfg.AdminState
 	}
 	if mask&objects.OSPFV2_AREA_UPDATE_AUTH_TYPE == objects.OSPFV2_AREA_UPDATE_AUTH_TYPE {
 		newAreaEnt.AuthType = newCfg.AuthType
+		for intfKey, _ := range newAreaEnt.IntfMap {
+			intfEnt, _ := server.IntfConfMap[intfKey]
+			intfEnt.AuthType = uint16(newAreaEnt.AuthType)
+			server.IntfConfMap[intfKey] = intfEnt
+		}
 	}
-	if mask&objects.OSPFV2_AREA_UPDATE_IMPORT_AS_EXTERN == objects.OSPFV2_AREA_UPDATE_IMPORT_AS_EXTERN {
-		newAreaEnt.ImportASExtern = newCfg.ImportASExtern
+	if mask&objects.OSPFV2_AREA_UPDATE_IMPORT_AS_EXTERN == objects.OSPFV2_AREA_UPDATE_IMPORT_AS_EXTERN ||
+		mask&objects.OSPFV2_AREA_UPDATE_AREA_TYPE == objects.OSPFV2_AREA_UPDATE_AREA_TYPE {
+		newAreaEnt.AreaType = newCfg.AreaType
+		newAreaEnt.ImportASExtern = newCfg.ImportASExtern &&
+			newCfg.AreaType == objects.AREA_TYPE_NORMAL
+	}
+	if mask&objects.OSPFV2_AREA_UPDATE_STUB_DEFAULT_COST == objects.OSPFV2_AREA_UPDATE_STUB_DEFAULT_COST {
+		newAreaEnt.StubDefaultCost = getAreaStubDefaultCost(newCfg.StubDefaultCost)
+	}
+	if mask&objects.OSPFV2_AREA_UPDATE_NSSA_TRANSLATOR == objects.OSPFV2_AREA_UPDATE_NSSA_TRANSLATOR {
+		newAreaEnt.NssaTranslatorRole = newCfg.NssaTranslatorRole
+	}
+	if mask&objects.OSPFV2_AREA_UPDATE_NSSA_DEFAULT == objects.OSPFV2_AREA_UPDATE_NSSA_DEFAULT {
+		newAreaEnt.NssaDefaultOriginate = newCfg.NssaDefaultOriginate
 	}
 
 	server.AreaConfMap[newCfg.AreaId] = newAreaEnt
@@ -135,13 +195,13 @@ func (server *OSPFV2Server) createArea(cfg *objects.Ospfv2Area) (bool, error) {
 		server.logger.Err("Unable to Create Area already exist")
 		return false, errors.New("Unable to create area already exist")
 	}
-	//TODO: Only AuthType none is supported
-	if cfg.AuthType != objects.AUTH_TYPE_NONE {
-		server.logger.Err("Only AuthType None is supported")
-		return false, errors.New("AuthType not supported")
-	}
 	areaEnt.AuthType = cfg.AuthType
-	areaEnt.ImportASExtern = cfg.ImportASExtern
+	areaEnt.AreaType = cfg.AreaType
+	areaEnt.ImportASExtern = cfg.ImportASExtern &&
+		cfg.AreaType == objects.AREA_TYPE_NORMAL
+	areaEnt.StubDefaultCost = getAreaStubDefaultCost(cfg.StubDefaultCost)
+	areaEnt.NssaTranslatorRole = cfg.NssaTranslatorRole
+	areaEnt.NssaDefaultOriginate = cfg.NssaDefaultOriginate
 	areaEnt.IntfMap = make(map[IntfConfKey]bool)
 	areaEnt.AdminState = cfg.AdminState
 	server.AreaConfMap[cfg.AreaId] = areaEnt
@@ -173,6 +233,14 @@ func (server *OSPFV2Server) deleteArea(cfg *objects.Ospfv2Area) (bool, error) {
 		server.logger.Err("Unable to delete Area as there are interface configured in this area")
 		return false, errors.New("Unable to delete Area as there are interface configured in this area")
 	}
+	if server.areaHasVirtualLink(cfg.AreaId) {
+		server.logger.Err("Unable to delete Area as it is the transit area of virtual links")
+		return false, errors.New("Unable to delete Area as it is the transit area of virtual links")
+	}
+	if server.areaHasRange(cfg.AreaId) {
+		server.logger.Err("Unable to delete Area as there are address ranges configured in this area")
+		return false, errors.New("Unable to delete Area as there are address ranges configured in this area")
+	}
 	if areaEnt.AdminState == true {
 		//This will cause Nbrs to be deleted from NbrFSM
 		//server.StopAreaIntfFSM(cfg.AreaId)
@@ -193,7 +261,11 @@ func (server *OSPFV2Server) getAreaState(areaId uint32) (*objects.Ospfv2AreaStat
 		return nil, errors.New("Area doesnot exist")
 	}
 	retObj.AreaId = areaId
-	//TODO	retObj.NumSpfRuns = 0
+	spfStats := server.getAreaSpfStats(areaId)
+	retObj.NumSpfRuns = spfStats.NumSpfRuns
+	retObj.NumPartialSpfRuns = spfStats.NumPartialSpfRuns
+	retObj.LastSpfDuration = spfStats.LastSpfDuration
+	retObj.LastSpfTrigger = spfStats.LastSpfTrigger
 	lsdbKey := LsdbKey{
 		AreaId: areaId,
 	}
@@ -241,7 +313,11 @@ func (server *OSPFV2Server) getBulkAreaState(fromIdx, cnt int) (*objects.Ospfv2A
 		}
 		var obj objects.Ospfv2AreaState
 		obj.AreaId = areaId
-		//TODO	retObj.NumSpfRuns = 0
+		spfStats := server.getAreaSpfStats(areaId)
+		obj.NumSpfRuns = spfStats.NumSpfRuns
+		obj.NumPartialSpfRuns = spfStats.NumPartialSpfRuns
+		obj.LastSpfDuration = spfStats.LastSpfDuration
+		obj.LastSpfTrigger = spfStats.LastSpfTrigger
 		lsdbKey := LsdbKey{
 			AreaId: areaId,
 		}
@@ -284,6 +360,33 @@ func (server *OSPFV2Server) isStubArea(areaId uint32) (bool, error) {
 	return false, nil
 }
 
+func (server *OSPFV2Server) isNssaArea(areaId uint32) bool {
+	conf, exist := server.AreaConfMap[areaId]
+	if !exist {
+		return false
+	}
+	return conf.AreaType == objects.AREA_TYPE_NSSA
+}
+
+func (server *OSPFV2Server) isTotallyStubArea(areaId uint32) bool {
+	conf, exist := server.AreaConfMap[areaId]
+	if !exist {
+		return false
+	}
+	return conf.AreaType == objects.AREA_TYPE_TOTALLY_STUB
+}
+
+// getAreaLsaOptions returns the options to be advertised in self
+// originated LSAs of the given area, E bit is only set in areas
+// which are capable of carrying AS External LSAs
+func (server *OSPFV2Server) getAreaLsaOptions(areaId uint32) uint8 {
+	isStub, _ := server.isStubArea(areaId)
+	if isStub {
+		return 0
+	}
+	return EOption
+}
+
 func (server *OSPFV2Server) GetListOfIntfKeyInGivenArea(areaId uint32) ([]IntfConfKey, error) {
 	var intfConKeyList []IntfConfKey
 
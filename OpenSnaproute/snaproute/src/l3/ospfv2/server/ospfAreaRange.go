@@ -0,0 +1,357 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"errors"
+	"l3/ospfv2/objects"
+	"ribd"
+	"sort"
+)
+
+type AreaRangeKey struct {
+	AreaId   uint32
+	IpPrefix uint32
+	Netmask  uint32
+}
+
+type AreaRangeConf struct {
+	Advertise bool
+	Cost      uint32 // 0: use highest cost of the component routes
+}
+
+func genOspfv2AreaRangeUpdateMask(attrset []bool) uint32 {
+	var mask uint32 = 0
+
+	if attrset == nil {
+		mask = objects.OSPFV2_AREA_RANGE_UPDATE_ADVERTISE |
+			objects.OSPFV2_AREA_RANGE_UPDATE_COST
+	} else {
+		for idx, val := range attrset {
+			if val == true {
+				switch idx {
+				case 0:
+					//AreaId
+				case 1:
+					//IpPrefix
+				case 2:
+					//Netmask
+				case 3:
+					mask |= objects.OSPFV2_AREA_RANGE_UPDATE_ADVERTISE
+				case 4:
+					mask |= objects.OSPFV2_AREA_RANGE_UPDATE_COST
+				}
+			}
+		}
+	}
+	return mask
+}
+
+func (server *OSPFV2Server) createAreaRange(cfg *objects.Ospfv2AreaRange) (bool, error) {
+	server.logger.Info("Area range configuration create")
+	_, exist := server.AreaConfMap[cfg.AreaId]
+	if !exist {
+		server.logger.Err("Unable to create area range, area doesnot exist")
+		return false, errors.New("Unable to create area range, area doesnot exist")
+	}
+	rangeKey := AreaRangeKey{
+		AreaId:   cfg.AreaId,
+		IpPrefix: cfg.IpPrefix,
+		Netmask:  cfg.Netmask,
+	}
+	_, exist = server.AreaRangeConfMap[rangeKey]
+	if exist {
+		server.logger.Err("Unable to create area range already exist")
+		return false, errors.New("Unable to create area range already exist")
+	}
+	server.AreaRangeConfMap[rangeKey] = AreaRangeConf{
+		Advertise: cfg.Advertise,
+		Cost:      cfg.Cost,
+	}
+	server.updateAreaRangeMasks(cfg.AreaId)
+	server.SendMsgToLsdbForAreaRangeUpdate()
+	server.logger.Info("Successfully created ospfv2AreaRange config")
+	return true, nil
+}
+
+func (server *OSPFV2Server) updateAreaRange(newCfg, oldCfg *objects.Ospfv2AreaRange, attrset []bool) (bool, error) {
+	server.logger.Info("Area range configuration update")
+	rangeKey := AreaRangeKey{
+		AreaId:   newCfg.AreaId,
+		IpPrefix: newCfg.IpPrefix,
+		Netmask:  newCfg.Netmask,
+	}
+	rangeEnt, exist := server.AreaRangeConfMap[rangeKey]
+	if !exist {
+		server.logger.Err("Cannot update, area range doesnot exist")
+		return false, errors.New("Cannot update, area range doesnot exist")
+	}
+	mask := genOspfv2AreaRangeUpdateMask(attrset)
+	if mask&objects.OSPFV2_AREA_RANGE_UPDATE_ADVERTISE == objects.OSPFV2_AREA_RANGE_UPDATE_ADVERTISE {
+		rangeEnt.Advertise = newCfg.Advertise
+	}
+	if mask&objects.OSPFV2_AREA_RANGE_UPDATE_COST == objects.OSPFV2_AREA_RANGE_UPDATE_COST {
+		rangeEnt.Cost = newCfg.Cost
+	}
+	server.AreaRangeConfMap[rangeKey] = rangeEnt
+	server.SendMsgToLsdbForAreaRangeUpdate()
+	return true, nil
+}
+
+func (server *OSPFV2Server) deleteAreaRange(cfg *objects.Ospfv2AreaRange) (bool, error) {
+	server.logger.Info("Area range configuration delete")
+	rangeKey := AreaRangeKey{
+		AreaId:   cfg.AreaId,
+		IpPrefix: cfg.IpPrefix,
+		Netmask:  cfg.Netmask,
+	}
+	_, exist := server.AreaRangeConfMap[rangeKey]
+	if !exist {
+		server.logger.Err("Unable to delete area range doesnot exist")
+		return false, errors.New("Unable to delete area range doesnot exist")
+	}
+	delete(server.AreaRangeConfMap, rangeKey)
+	server.updateAreaRangeMasks(cfg.AreaId)
+	server.SendMsgToLsdbForAreaRangeUpdate()
+	return true, nil
+}
+
+func (server *OSPFV2Server) areaHasRange(areaId uint32) bool {
+	for rangeKey, _ := range server.AreaRangeConfMap {
+		if rangeKey.AreaId == areaId {
+			return true
+		}
+	}
+	return false
+}
+
+// Netmasks sorted longest first
+type areaRangeMasks []uint32
+
+func (n areaRangeMasks) Len() int {
+	return len(n)
+}
+
+func (n areaRangeMasks) Less(i, j int) bool {
+	return n[i] > n[j]
+}
+
+func (n areaRangeMasks) Swap(i, j int) {
+	n[i], n[j] = n[j], n[i]
+}
+
+/*
+@fn updateAreaRangeMasks
+Keeps the distinct netmasks of the ranges of areaId sorted
+longest first, a route is then matched against its ranges with
+at most one lookup per netmask.
+*/
+func (server *OSPFV2Server) updateAreaRangeMasks(areaId uint32) {
+	maskMap := make(map[uint32]bool)
+	for rangeKey, _ := range server.AreaRangeConfMap {
+		if rangeKey.AreaId == areaId {
+			maskMap[rangeKey.Netmask] = true
+		}
+	}
+	if len(maskMap) == 0 {
+		delete(server.AreaRangeMaskMap, areaId)
+		return
+	}
+	var masks areaRangeMasks
+	for netmask, _ := range maskMap {
+		masks = append(masks, netmask)
+	}
+	sort.Sort(masks)
+	server.AreaRangeMaskMap[areaId] = masks
+}
+
+/*
+@fn getAreaRangeForRoute
+Returns the most specific range configured on areaId which
+contains the given network (RFC 2328 12.4.3).
+*/
+func (server *OSPFV2Server) getAreaRangeForRoute(areaId uint32, rKey RoutingTblEntryKey) (AreaRangeKey, bool) {
+	for _, netmask := range server.AreaRangeMaskMap[areaId] {
+		if rKey.AddrMask < netmask {
+			continue
+		}
+		rangeKey := AreaRangeKey{
+			AreaId:   areaId,
+			IpPrefix: rKey.DestId & netmask,
+			Netmask:  netmask,
+		}
+		if _, exist := server.AreaRangeConfMap[rangeKey]; exist {
+			return rangeKey, true
+		}
+	}
+	return AreaRangeKey{}, false
+}
+
+/*
+@fn getActiveAreaRanges
+A range is active when at least one intra-area network route
+of its area falls in it. Returns the highest component cost
+of each active range.
+*/
+func (server *OSPFV2Server) getActiveAreaRanges() map[AreaRangeKey]uint32 {
+	activeRanges := make(map[AreaRangeKey]uint32)
+	if len(server.AreaRangeConfMap) == 0 {
+		return activeRanges
+	}
+	for rKey, rEnt := range server.RoutingTblData.GlobalRoutingTbl {
+		if rKey.DestType != Network ||
+			rEnt.RoutingTblEnt.PathType != IntraArea ||
+			uint32(rEnt.RoutingTblEnt.Cost) >= LSInfinity {
+			continue
+		}
+		rangeKey, found := server.getAreaRangeForRoute(rEnt.AreaId, rKey)
+		if !found {
+			continue
+		}
+		cost := uint32(rEnt.RoutingTblEnt.Cost)
+		if maxCost, exist := activeRanges[rangeKey]; !exist || cost > maxCost {
+			activeRanges[rangeKey] = cost
+		}
+	}
+	return activeRanges
+}
+
+/*
+@fn generateAreaRangeSummaryLsa
+Adds one Type 3 summary LSA per active, advertised range
+of the other areas to the summary LSAs of areaId.
+*/
+func (server *OSPFV2Server) generateAreaRangeSummaryLsa(areaId uint32, activeRanges map[AreaRangeKey]uint32, sEnt map[LsaKey]SummaryLsa) {
+	lsDbKey := LsdbKey{
+		AreaId: areaId,
+	}
+	for rangeKey, maxCost := range activeRanges {
+		rangeEnt, _ := server.AreaRangeConfMap[rangeKey]
+		if rangeKey.AreaId == areaId ||
+			rangeEnt.Advertise == false {
+			continue
+		}
+		rKey := RoutingTblEntryKey{
+			DestId:   rangeKey.IpPrefix,
+			AddrMask: rangeKey.Netmask,
+			DestType: Network,
+		}
+		var rEnt GlobalRoutingTblEntry
+		lsaKey, summaryLsa := server.GenerateType3SummaryLSA(rKey, rEnt, lsDbKey)
+		summaryLsa.Metric = maxCost
+		if rangeEnt.Cost != 0 {
+			summaryLsa.Metric = rangeEnt.Cost
+		}
+		server.logger.Debug("Summary : generated area range summary 3 lsa ", rangeKey)
+		sEnt[lsaKey] = summaryLsa
+	}
+}
+
+/*
+@fn updateAreaRangeDiscardRoutes
+Installs a discard route for every active, advertised range
+so traffic to unreachable parts of the aggregate is dropped
+instead of looping, and withdraws the ones no longer needed.
+*/
+func (server *OSPFV2Server) updateAreaRangeDiscardRoutes() {
+	activeRanges := make(map[AreaRangeKey]uint32)
+	if server.globalData.AreaBdrRtrStatus == true {
+		activeRanges = server.getActiveAreaRanges()
+	}
+	if server.RoutingTblData.DiscardRouteMap == nil {
+		server.RoutingTblData.DiscardRouteMap = make(map[AreaRangeKey]bool)
+	}
+	for rangeKey, _ := range server.RoutingTblData.DiscardRouteMap {
+		rangeEnt, exist := server.AreaRangeConfMap[rangeKey]
+		_, active := activeRanges[rangeKey]
+		if exist && active && rangeEnt.Advertise {
+			continue
+		}
+		server.deleteDiscardRoute(rangeKey)
+		delete(server.RoutingTblData.DiscardRouteMap, rangeKey)
+	}
+	for rangeKey, _ := range activeRanges {
+		rangeEnt, _ := server.AreaRangeConfMap[rangeKey]
+		if rangeEnt.Advertise == false {
+			continue
+		}
+		if _, exist := server.RoutingTblData.DiscardRouteMap[rangeKey]; exist {
+			continue
+		}
+		if server.installDiscardRoute(rangeKey) {
+			server.RoutingTblData.DiscardRouteMap[rangeKey] = true
+		}
+	}
+}
+
+func (server *OSPFV2Server) flushAreaRangeDiscardRoutes() {
+	for rangeKey, _ := range server.RoutingTblData.DiscardRouteMap {
+		server.deleteDiscardRoute(rangeKey)
+	}
+	server.RoutingTblData.DiscardRouteMap = nil
+}
+
+func newDiscardRoute(rangeKey AreaRangeKey) *ribd.IPv4Route {
+	cfg := &ribd.IPv4Route{
+		DestinationNw: convertUint32ToDotNotation(rangeKey.IpPrefix),
+		Protocol:      OSPF_DISCARD_ROUTE_PROTOCOL,
+		Cost:          0,
+		NetworkMask:   convertUint32ToDotNotation(rangeKey.Netmask),
+		NullRoute:     true,
+	}
+	nextHopInfo := ribd.NextHopInfo{
+		NextHopIp: "0.0.0.0",
+	}
+	cfg.NextHop = make([]*ribd.NextHopInfo, 0)
+	cfg.NextHop = append(cfg.NextHop, &nextHopInfo)
+	return cfg
+}
+
+func (server *OSPFV2Server) installDiscardRoute(rangeKey AreaRangeKey) bool {
+	if server.ribdComm.ribdClient.ClientHdl == nil {
+		server.logger.Err("Nil ribd handle. Can not install discard route.")
+		return false
+	}
+	cfg := newDiscardRoute(rangeKey)
+	server.logger.Info("Installing discard route for area range:", cfg.DestinationNw, cfg.NetworkMask)
+	ret, err := server.ribdComm.ribdClient.ClientHdl.CreateIPv4Route(cfg)
+	if err != nil {
+		server.logger.Err("Error Installing discard route:", err, ret)
+		return false
+	}
+	return true
+}
+
+func (server *OSPFV2Server) deleteDiscardRoute(rangeKey AreaRangeKey) {
+	if server.ribdComm.ribdClient.ClientHdl == nil {
+		server.logger.Err("Nil ribd handle. Can not delete discard route.")
+		return
+	}
+	cfg := newDiscardRoute(rangeKey)
+	server.logger.Info("Deleting discard route for area range:", cfg.DestinationNw, cfg.NetworkMask)
+	ret, err := server.ribdComm.ribdClient.ClientHdl.DeleteIPv4Route(cfg)
+	if err != nil {
+		server.logger.Err("Error Deleting discard route:", err, ret)
+	}
+}
@@ -0,0 +1,167 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"testing"
+)
+
+func newTestRangeKey(areaId uint32, prefix string, maskLen int) AreaRangeKey {
+	ipPrefix, _ := convertDotNotationToUint32(prefix)
+	return AreaRangeKey{
+		AreaId:   areaId,
+		IpPrefix: ipPrefix,
+		Netmask:  uint32(0xffffffff) << uint(32-maskLen),
+	}
+}
+
+func newTestNwKey(network string, maskLen int) RoutingTblEntryKey {
+	destId, _ := convertDotNotationToUint32(network)
+	return RoutingTblEntryKey{
+		DestId:   destId,
+		AddrMask: uint32(0xffffffff) << uint(32-maskLen),
+		DestType: Network,
+	}
+}
+
+func addTestAreaRange(server *OSPFV2Server, rangeKey AreaRangeKey) {
+	server.AreaRangeConfMap[rangeKey] = AreaRangeConf{
+		Advertise: true,
+	}
+	server.updateAreaRangeMasks(rangeKey.AreaId)
+}
+
+func TestGetAreaRangeForRoute(t *testing.T) {
+	server := newTestServer("1.1.1.1")
+	addTestArea(server, 0, AreaConf{})
+	addTestArea(server, 1, AreaConf{})
+	range8 := newTestRangeKey(1, "10.0.0.0", 8)
+	range16 := newTestRangeKey(1, "10.1.0.0", 16)
+	addTestAreaRange(server, range8)
+	addTestAreaRange(server, range16)
+	addTestAreaRange(server, newTestRangeKey(0, "10.1.1.0", 24))
+
+	testCases := []struct {
+		areaId    uint32
+		rKey      RoutingTblEntryKey
+		rangeKey  AreaRangeKey
+		withRange bool
+	}{
+		{1, newTestNwKey("10.1.1.0", 24), range16, true},
+		{1, newTestNwKey("10.2.1.0", 24), range8, true},
+		{1, newTestNwKey("10.1.0.0", 16), range16, true},
+		// Network shorter than the most specific range
+		{1, newTestNwKey("10.0.0.0", 15), range8, true},
+		{1, newTestNwKey("11.1.1.0", 24), AreaRangeKey{}, false},
+		// Ranges of the other areas do not apply
+		{2, newTestNwKey("10.1.1.0", 24), AreaRangeKey{}, false},
+	}
+	for _, tc := range testCases {
+		rangeKey, found := server.getAreaRangeForRoute(tc.areaId, tc.rKey)
+		if found != tc.withRange || rangeKey != tc.rangeKey {
+			t.Error("Unexpected range for", tc.areaId, tc.rKey, rangeKey, found)
+		}
+	}
+
+	delete(server.AreaRangeConfMap, range16)
+	server.updateAreaRangeMasks(1)
+	if rangeKey, _ := server.getAreaRangeForRoute(1, newTestNwKey("10.1.1.0", 24)); rangeKey != range8 {
+		t.Error("Deleted range still matched", rangeKey)
+	}
+	delete(server.AreaRangeConfMap, range8)
+	server.updateAreaRangeMasks(1)
+	if _, exist := server.AreaRangeMaskMap[1]; exist {
+		t.Error("Netmasks kept for area without ranges", server.AreaRangeMaskMap[1])
+	}
+}
+
+func TestGetActiveAreaRanges(t *testing.T) {
+	server := newTestServer("1.1.1.1")
+	addTestArea(server, 0, AreaConf{})
+	addTestArea(server, 1, AreaConf{})
+	range16 := newTestRangeKey(1, "10.1.0.0", 16)
+	unusedRange := newTestRangeKey(1, "10.2.0.0", 16)
+	addTestAreaRange(server, range16)
+	addTestAreaRange(server, unusedRange)
+	routes := []struct {
+		areaId   uint32
+		rKey     RoutingTblEntryKey
+		pathType PathType
+		cost     uint32
+	}{
+		{1, newTestNwKey("10.1.1.0", 24), IntraArea, 10},
+		{1, newTestNwKey("10.1.2.0", 24), IntraArea, 30},
+		// Only intra-area routes of the range's area make it active
+		{1, newTestNwKey("10.1.3.0", 24), InterArea, 50},
+		{0, newTestNwKey("10.2.1.0", 24), IntraArea, 50},
+	}
+	for _, route := range routes {
+		var rEnt GlobalRoutingTblEntry
+		rEnt.AreaId = route.areaId
+		rEnt.RoutingTblEnt.PathType = route.pathType
+		rEnt.RoutingTblEnt.Cost = route.cost
+		server.RoutingTblData.GlobalRoutingTbl[route.rKey] = rEnt
+	}
+
+	activeRanges := server.getActiveAreaRanges()
+	if len(activeRanges) != 1 || activeRanges[range16] != 30 {
+		t.Error("Unexpected active ranges", activeRanges)
+	}
+
+	// Range summary carries the highest component cost unless configured
+	sEnt := make(map[LsaKey]SummaryLsa)
+	server.generateAreaRangeSummaryLsa(0, activeRanges, sEnt)
+	lsaKey := LsaKey{
+		LSType:    Summary3LSA,
+		LSId:      range16.IpPrefix,
+		AdvRouter: server.globalData.RouterId,
+	}
+	if lsa, exist := sEnt[lsaKey]; !exist || lsa.Metric != 30 || lsa.Netmask != range16.Netmask {
+		t.Error("Unexpected range summary", sEnt)
+	}
+	server.AreaRangeConfMap[range16] = AreaRangeConf{
+		Advertise: true,
+		Cost:      100,
+	}
+	server.generateAreaRangeSummaryLsa(0, activeRanges, sEnt)
+	if sEnt[lsaKey].Metric != 100 {
+		t.Error("Configured range cost not used", sEnt[lsaKey].Metric)
+	}
+	// No summary of a range into its own area
+	sEnt = make(map[LsaKey]SummaryLsa)
+	server.generateAreaRangeSummaryLsa(1, activeRanges, sEnt)
+	if len(sEnt) != 0 {
+		t.Error("Range summarized into its own area", sEnt)
+	}
+}
+
+func TestAreaRangeDiscardRoute(t *testing.T) {
+	cfg := newDiscardRoute(newTestRangeKey(1, "10.1.0.0", 16))
+	if cfg.Protocol == "OSPF" || !cfg.NullRoute {
+		t.Error("Discard route collides with OSPF routes", cfg.Protocol, cfg.NullRoute)
+	}
+	if cfg.DestinationNw != "10.1.0.0" || cfg.NetworkMask != "255.255.0.0" {
+		t.Error("Unexpected discard route", cfg.DestinationNw, cfg.NetworkMask)
+	}
+}
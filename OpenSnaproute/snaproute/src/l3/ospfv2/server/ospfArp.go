@@ -0,0 +1,217 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"errors"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	ARP_HW_ADDR_LEN   = 6
+	ARP_REQ_TIMEOUT   = 5 * time.Second
+	ARP_CACHE_TIMEOUT = 300 * time.Second
+)
+
+type ArpCacheEnt struct {
+	IntfKey   IntfConfKey
+	Mac       net.HardwareAddr
+	LearnTime time.Time
+}
+
+// Outstanding request, sent from SrcIp on the interface
+type ArpReqEnt struct {
+	IntfKey IntfConfKey
+	SrcIp   uint32
+	ReqTime time.Time
+}
+
+/*
+NBMA and point-to-multipoint nbrs are only reached by unicast,
+their MAC address is resolved with ARP before the first hello
+is sent to them. Only replies to outstanding requests are
+learnt, and entries age out so the nbrs are resolved again.
+Replies are received by the per interface rx routines, hence
+the cache is kept behind a lock.
+*/
+type ArpCacheStruct struct {
+	sync.RWMutex
+	MacMap map[uint32]ArpCacheEnt
+	ReqMap map[uint32]ArpReqEnt
+}
+
+func (server *OSPFV2Server) initArpCacheData() {
+	server.ArpCacheData.MacMap = make(map[uint32]ArpCacheEnt)
+	server.ArpCacheData.ReqMap = make(map[uint32]ArpReqEnt)
+}
+
+func (server *OSPFV2Server) getArpCacheMac(key IntfConfKey, ipAddr uint32) (net.HardwareAddr, bool) {
+	server.ArpCacheData.RLock()
+	defer server.ArpCacheData.RUnlock()
+	arpEnt, exist := server.ArpCacheData.MacMap[ipAddr]
+	if !exist || arpEnt.IntfKey != key ||
+		time.Since(arpEnt.LearnTime) >= ARP_CACHE_TIMEOUT {
+		return nil, false
+	}
+	return arpEnt.Mac, true
+}
+
+func (server *OSPFV2Server) delArpCacheEntry(ipAddr uint32) {
+	server.ArpCacheData.Lock()
+	delete(server.ArpCacheData.MacMap, ipAddr)
+	delete(server.ArpCacheData.ReqMap, ipAddr)
+	server.ArpCacheData.Unlock()
+}
+
+// Entries and requests of the interface are flushed when it goes down
+func (server *OSPFV2Server) flushArpCache(key IntfConfKey) {
+	server.ArpCacheData.Lock()
+	defer server.ArpCacheData.Unlock()
+	for ipAddr, arpEnt := range server.ArpCacheData.MacMap {
+		if arpEnt.IntfKey == key {
+			delete(server.ArpCacheData.MacMap, ipAddr)
+		}
+	}
+	for ipAddr, reqEnt := range server.ArpCacheData.ReqMap {
+		if reqEnt.IntfKey == key {
+			delete(server.ArpCacheData.ReqMap, ipAddr)
+		}
+	}
+}
+
+/*
+@fn processRxArpPkt
+A reply received on the interface is only learnt if it answers
+a request sent there which has not timed out. Requests are only
+sent for the static nbrs of the interface.
+*/
+func (server *OSPFV2Server) processRxArpPkt(key IntfConfKey, arp *layers.ARP) {
+	if arp.Operation != layers.ARPReply ||
+		len(arp.SourceProtAddress) != net.IPv4len ||
+		len(arp.DstProtAddress) != net.IPv4len ||
+		len(arp.SourceHwAddress) != ARP_HW_ADDR_LEN {
+		return
+	}
+	ipAddr, _ := convertDotNotationToUint32(net.IP(arp.SourceProtAddress).String())
+	dstIp, _ := convertDotNotationToUint32(net.IP(arp.DstProtAddress).String())
+	server.ArpCacheData.Lock()
+	defer server.ArpCacheData.Unlock()
+	reqEnt, exist := server.ArpCacheData.ReqMap[ipAddr]
+	if !exist || reqEnt.IntfKey != key || reqEnt.SrcIp != dstIp {
+		return
+	}
+	delete(server.ArpCacheData.ReqMap, ipAddr)
+	if time.Since(reqEnt.ReqTime) >= ARP_REQ_TIMEOUT {
+		return
+	}
+	mac := make(net.HardwareAddr, len(arp.SourceHwAddress))
+	copy(mac, arp.SourceHwAddress)
+	server.ArpCacheData.MacMap[ipAddr] = ArpCacheEnt{
+		IntfKey:   key,
+		Mac:       mac,
+		LearnTime: time.Now(),
+	}
+}
+
+func (server *OSPFV2Server) buildArpRequestPkt(ent IntfConf, dstIp uint32) []byte {
+	dstMac, _ := net.ParseMAC(MASKMAC)
+	ethLayer := layers.Ethernet{
+		SrcMAC:       ent.IfMacAddr,
+		DstMAC:       dstMac,
+		EthernetType: layers.EthernetTypeARP,
+	}
+	arpLayer := layers.ARP{
+		AddrType:          layers.LinkTypeEthernet,
+		Protocol:          layers.EthernetTypeIPv4,
+		HwAddressSize:     ARP_HW_ADDR_LEN,
+		ProtAddressSize:   uint8(net.IPv4len),
+		Operation:         layers.ARPRequest,
+		SourceHwAddress:   []byte(ent.IfMacAddr),
+		SourceProtAddress: net.ParseIP(convertUint32ToDotNotation(ent.IpAddr)).To4(),
+		DstHwAddress:      make([]byte, ARP_HW_ADDR_LEN),
+		DstProtAddress:    net.ParseIP(convertUint32ToDotNotation(dstIp)).To4(),
+	}
+	buffer := gopacket.NewSerializeBuffer()
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+	err := gopacket.SerializeLayers(buffer, options, &ethLayer, &arpLayer)
+	if err != nil {
+		return nil
+	}
+	return buffer.Bytes()
+}
+
+func (server *OSPFV2Server) sendArpRequest(key IntfConfKey, dstIp uint32) error {
+	ent, _ := server.IntfConfMap[key]
+	handle := ent.txHdl.SendHdl
+	if handle == nil {
+		return errors.New("Invalid tx handle")
+	}
+	arpPkt := server.buildArpRequestPkt(ent, dstIp)
+	if arpPkt == nil {
+		return errors.New("Unable to build arp request")
+	}
+	server.ArpCacheData.Lock()
+	server.ArpCacheData.ReqMap[dstIp] = ArpReqEnt{
+		IntfKey: key,
+		SrcIp:   ent.IpAddr,
+		ReqTime: time.Now(),
+	}
+	server.ArpCacheData.Unlock()
+	return handle.WritePacketData(arpPkt)
+}
+
+/*
+@fn resolveNbrMac
+The MAC learnt from the nbr packets is used first, then the
+ARP cache. An ARP request is sent when both are unknown and
+nbrKey is a static nbr of the interface, the caller retries on
+its next hello.
+*/
+func (server *OSPFV2Server) resolveNbrMac(key IntfConfKey, nbrKey NbrConfKey) (net.HardwareAddr, bool) {
+	nbrConf, exist := server.NbrConfMap[nbrKey]
+	if exist && nbrConf.NbrMac != nil {
+		return nbrConf.NbrMac, true
+	}
+	mac, exist := server.getArpCacheMac(key, nbrKey.NbrIdentity)
+	if exist {
+		return mac, true
+	}
+	_, isStaticNbr := server.StaticNbrConfMap[nbrKey]
+	if !isStaticNbr || !isIntfStaticNbr(key, server.IntfConfMap[key], nbrKey) {
+		return nil, false
+	}
+	err := server.sendArpRequest(key, nbrKey.NbrIdentity)
+	if err != nil {
+		server.logger.Err("Unable to send arp request for",
+			convertUint32ToDotNotation(nbrKey.NbrIdentity), err)
+	}
+	return nil, false
+}
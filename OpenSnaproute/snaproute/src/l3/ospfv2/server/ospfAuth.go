@@ -0,0 +1,366 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"crypto/md5"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"l3/ospfv2/objects"
+	"strings"
+	"sync"
+	"time"
+)
+
+/*
+   Cryptographic authentication trailer (RFC 2328 Appendix D.3)
+        0                   1                   2                   3
+        0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+       +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+       |              0                |    Key ID     | Auth Data Len |
+       +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+       |                 Cryptographic sequence number                 |
+       +-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+*/
+
+// Additional MD5 key of an interface (RFC 2328 Appendix D.3), it is
+// accepted on receipt as soon as it is configured and used to generate
+// message digests from GenerateStart onwards
+type IntfAuthKey struct {
+	Key           []byte
+	GenerateStart time.Time
+}
+
+type IntfAuthStruct struct {
+	AuthMutex      sync.Mutex
+	TxCryptoSeqNum uint32
+	//Last Cryptographic sequence number received, keyed by Nbr IP Address
+	RxCryptoSeqNum map[uint32]uint32
+	//Keys configured in addition to the AuthKeyId of the interface
+	Keys                  map[uint8]IntfAuthKey
+	NumOfAuthTypeMismatch uint32
+	NumOfAuthFailures     uint32
+	NumOfAuthReplayDrops  uint32
+}
+
+func NewIntfAuthStruct() *IntfAuthStruct {
+	return &IntfAuthStruct{
+		//Seeding with time so that the sequence number
+		//keeps increasing across restarts
+		TxCryptoSeqNum: uint32(time.Now().Unix()),
+		RxCryptoSeqNum: make(map[uint32]uint32),
+		Keys:           make(map[uint8]IntfAuthKey),
+	}
+}
+
+func validateIntfAuthKey(authType uint8, authKey string) error {
+	switch authType {
+	case objects.AUTH_TYPE_SIMPLE_PASSWORD:
+		if len(authKey) > objects.AUTH_SIMPLE_PASSWORD_MAX_LEN {
+			return errors.New("Simple password cannot be longer than 8 characters")
+		}
+	case objects.AUTH_TYPE_MD5:
+		if len(authKey) > objects.AUTH_MD5_KEY_MAX_LEN {
+			return errors.New("MD5 key cannot be longer than 16 characters")
+		}
+	}
+	return nil
+}
+
+// Auth key is always stored null padded to 16 bytes,
+// simple password uses the first 8 bytes of it
+func encodeIntfAuthKey(authKey string) []byte {
+	key := make([]byte, OSPF_AUTH_MD5_KEY_LEN)
+	copy(key, authKey)
+	return key
+}
+
+func decodeIntfAuthKey(authKey []byte) string {
+	return strings.TrimRight(string(authKey), "\x00")
+}
+
+/*
+@fn getTxAuthKey
+Out of the keys whose GenerateStart has been reached the most
+recently activated one is used (ties go to the higher Key ID),
+the key of the interface config is used when there is none.
+AuthMutex should be held by the caller.
+*/
+func (authData *IntfAuthStruct) getTxAuthKey(ent IntfConf, now time.Time) (uint8, []byte) {
+	keyId, authKey := ent.AuthKeyId, ent.AuthKey
+	var start time.Time
+	for id, key := range authData.Keys {
+		if key.GenerateStart.After(now) {
+			continue
+		}
+		if key.GenerateStart.After(start) ||
+			(key.GenerateStart.Equal(start) && id > keyId) {
+			keyId, authKey, start = id, key.Key, key.GenerateStart
+		}
+	}
+	return keyId, authKey
+}
+
+// AuthMutex should be held by the caller
+func (authData *IntfAuthStruct) getRxAuthKey(ent IntfConf, keyId uint8) ([]byte, bool) {
+	if key, exist := authData.Keys[keyId]; exist {
+		return key.Key, true
+	}
+	if keyId == ent.AuthKeyId {
+		return ent.AuthKey, true
+	}
+	return nil, false
+}
+
+func computeOspfMd5Digest(ospfPkt []byte, authKey []byte) []byte {
+	data := make([]byte, len(ospfPkt)+OSPF_AUTH_MD5_KEY_LEN)
+	copy(data, ospfPkt)
+	copy(data[len(ospfPkt):], authKey)
+	digest := md5.Sum(data)
+	return digest[:]
+}
+
+// Fills in the checksum and authentication fields of an encoded
+// ospf packet. In case of MD5 the message digest is appended after
+// the ospf packet, hence the returned slice should be used.
+func (server *OSPFV2Server) encodeOspfAuth(ent IntfConf, ospf []byte) []byte {
+	switch uint8(ent.AuthType) {
+	case objects.AUTH_TYPE_SIMPLE_PASSWORD:
+		copy(ospf[16:OSPF_HEADER_SIZE], make([]byte, OSPF_AUTH_SIMPLE_PASS_LEN))
+		csum := computeCheckSum(ospf)
+		binary.BigEndian.PutUint16(ospf[12:14], csum)
+		copy(ospf[16:OSPF_HEADER_SIZE], ent.AuthKey[:OSPF_AUTH_SIMPLE_PASS_LEN])
+	case objects.AUTH_TYPE_MD5:
+		//Checksum is not calculated in case of cryptographic authentication
+		binary.BigEndian.PutUint16(ospf[12:14], 0)
+		binary.BigEndian.PutUint16(ospf[16:18], 0)
+		ent.authData.AuthMutex.Lock()
+		keyId, authKey := ent.authData.getTxAuthKey(ent, time.Now())
+		ent.authData.TxCryptoSeqNum++
+		seqNum := ent.authData.TxCryptoSeqNum
+		ent.authData.AuthMutex.Unlock()
+		ospf[18] = keyId
+		ospf[19] = uint8(OSPF_AUTH_MD5_DIGEST_LEN)
+		binary.BigEndian.PutUint32(ospf[20:24], seqNum)
+		ospf = append(ospf, computeOspfMd5Digest(ospf, authKey)...)
+	default:
+		copy(ospf[16:OSPF_HEADER_SIZE], make([]byte, OSPF_AUTH_SIMPLE_PASS_LEN))
+		csum := computeCheckSum(ospf)
+		binary.BigEndian.PutUint16(ospf[12:14], csum)
+	}
+	return ospf
+}
+
+// RFC 2328 Appendix D.2 and D.4.3
+// Verifies the authentication fields of received ospf packet,
+// ospfPkt contains ospf header and its payload including the
+// message digest (if any)
+func (server *OSPFV2Server) verifyOspfAuth(ent IntfConf, ospfPkt []byte, ospfHdr *OSPFHeader, srcIp uint32) error {
+	authData := ent.authData
+	if ent.AuthType != ospfHdr.AuthType {
+		authData.AuthMutex.Lock()
+		authData.NumOfAuthTypeMismatch++
+		authData.AuthMutex.Unlock()
+		return errors.New("Dropped because of Auth Type not matching")
+	}
+
+	switch uint8(ent.AuthType) {
+	case objects.AUTH_TYPE_SIMPLE_PASSWORD:
+		if subtle.ConstantTimeCompare(ospfHdr.AuthKey, ent.AuthKey[:OSPF_AUTH_SIMPLE_PASS_LEN]) != 1 {
+			authData.AuthMutex.Lock()
+			authData.NumOfAuthFailures++
+			authData.AuthMutex.Unlock()
+			return errors.New("Dropped because of Simple Password not matching")
+		}
+	case objects.AUTH_TYPE_MD5:
+		keyId := ospfPkt[18]
+		authLen := int(ospfPkt[19])
+		seqNum := binary.BigEndian.Uint32(ospfPkt[20:24])
+		pktLen := int(ospfHdr.Pktlen)
+		authData.AuthMutex.Lock()
+		defer authData.AuthMutex.Unlock()
+		authKey, keyExist := authData.getRxAuthKey(ent, keyId)
+		if !keyExist ||
+			authLen != OSPF_AUTH_MD5_DIGEST_LEN ||
+			len(ospfPkt) < pktLen+authLen {
+			authData.NumOfAuthFailures++
+			return errors.New("Dropped because of invalid Key ID or Auth Data length")
+		}
+		digest := computeOspfMd5Digest(ospfPkt[:pktLen], authKey)
+		if subtle.ConstantTimeCompare(digest, ospfPkt[pktLen:pktLen+authLen]) != 1 {
+			authData.NumOfAuthFailures++
+			return errors.New("Dropped because of Message Digest not matching")
+		}
+		lastSeqNum, exist := authData.RxCryptoSeqNum[srcIp]
+		if exist && seqNum < lastSeqNum {
+			authData.NumOfAuthReplayDrops++
+			return errors.New("Dropped because of decreasing Cryptographic sequence number")
+		}
+		authData.RxCryptoSeqNum[srcIp] = seqNum
+	}
+	return nil
+}
+
+func (server *OSPFV2Server) resetNbrCryptoSeqNum(ent IntfConf, nbrIpAddr uint32) {
+	if ent.authData == nil {
+		return
+	}
+	ent.authData.AuthMutex.Lock()
+	delete(ent.authData.RxCryptoSeqNum, nbrIpAddr)
+	ent.authData.AuthMutex.Unlock()
+}
+
+func (server *OSPFV2Server) resetIntfCryptoSeqNum(ent IntfConf) {
+	if ent.authData == nil {
+		return
+	}
+	ent.authData.AuthMutex.Lock()
+	ent.authData.RxCryptoSeqNum = make(map[uint32]uint32)
+	ent.authData.AuthMutex.Unlock()
+}
+
+func (server *OSPFV2Server) getIntfAuthStats(ent IntfConf) (typeMismatch, failures, replayDrops uint32) {
+	if ent.authData == nil {
+		return 0, 0, 0
+	}
+	ent.authData.AuthMutex.Lock()
+	typeMismatch = ent.authData.NumOfAuthTypeMismatch
+	failures = ent.authData.NumOfAuthFailures
+	replayDrops = ent.authData.NumOfAuthReplayDrops
+	ent.authData.AuthMutex.Unlock()
+	return typeMismatch, failures, replayDrops
+}
+
+func genOspfv2IntfAuthKeyUpdateMask(attrset []bool) uint32 {
+	var mask uint32 = 0
+
+	if attrset == nil {
+		mask = objects.OSPFV2_INTF_AUTH_KEY_UPDATE_KEY |
+			objects.OSPFV2_INTF_AUTH_KEY_UPDATE_GENERATE_DELAY
+	} else {
+		for idx, val := range attrset {
+			if val == true {
+				switch idx {
+				case 0:
+					//IpAddress
+				case 1:
+					//AddressLessIfIdx
+				case 2:
+					//KeyId
+				case 3:
+					mask |= objects.OSPFV2_INTF_AUTH_KEY_UPDATE_KEY
+				case 4:
+					mask |= objects.OSPFV2_INTF_AUTH_KEY_UPDATE_GENERATE_DELAY
+				}
+			}
+		}
+	}
+	return mask
+}
+
+func (server *OSPFV2Server) createIntfAuthKey(cfg *objects.Ospfv2IntfAuthKey) (bool, error) {
+	server.logger.Info("Intf auth key configuration create")
+	intfConfKey := IntfConfKey{
+		IpAddr:  cfg.IpAddress,
+		IntfIdx: cfg.AddressLessIfIdx,
+	}
+	intfConfEnt, exist := server.IntfConfMap[intfConfKey]
+	if !exist {
+		server.logger.Err("Ospf Interface configuration doesnot exist")
+		return false, errors.New("Ospf Interface configuration doesnot exist")
+	}
+	err := validateIntfAuthKey(objects.AUTH_TYPE_MD5, cfg.Key)
+	if err != nil {
+		return false, err
+	}
+	authData := intfConfEnt.authData
+	authData.AuthMutex.Lock()
+	defer authData.AuthMutex.Unlock()
+	if _, exist := authData.Keys[cfg.KeyId]; exist {
+		server.logger.Err("Unable to create intf auth key already exist")
+		return false, errors.New("Unable to create intf auth key already exist")
+	}
+	authData.Keys[cfg.KeyId] = IntfAuthKey{
+		Key:           encodeIntfAuthKey(cfg.Key),
+		GenerateStart: time.Now().Add(time.Duration(cfg.GenerateDelay) * time.Second),
+	}
+	return true, nil
+}
+
+func (server *OSPFV2Server) updateIntfAuthKey(newCfg, oldCfg *objects.Ospfv2IntfAuthKey, attrset []bool) (bool, error) {
+	server.logger.Info("Intf auth key configuration update")
+	intfConfKey := IntfConfKey{
+		IpAddr:  newCfg.IpAddress,
+		IntfIdx: newCfg.AddressLessIfIdx,
+	}
+	intfConfEnt, exist := server.IntfConfMap[intfConfKey]
+	if !exist {
+		server.logger.Err("Ospf Interface configuration doesnot exist")
+		return false, errors.New("Ospf Interface configuration doesnot exist")
+	}
+	authData := intfConfEnt.authData
+	authData.AuthMutex.Lock()
+	defer authData.AuthMutex.Unlock()
+	keyEnt, exist := authData.Keys[newCfg.KeyId]
+	if !exist {
+		server.logger.Err("Cannot update, intf auth key doesnot exist")
+		return false, errors.New("Cannot update, intf auth key doesnot exist")
+	}
+	mask := genOspfv2IntfAuthKeyUpdateMask(attrset)
+	if mask&objects.OSPFV2_INTF_AUTH_KEY_UPDATE_KEY == objects.OSPFV2_INTF_AUTH_KEY_UPDATE_KEY {
+		err := validateIntfAuthKey(objects.AUTH_TYPE_MD5, newCfg.Key)
+		if err != nil {
+			return false, err
+		}
+		keyEnt.Key = encodeIntfAuthKey(newCfg.Key)
+	}
+	if mask&objects.OSPFV2_INTF_AUTH_KEY_UPDATE_GENERATE_DELAY == objects.OSPFV2_INTF_AUTH_KEY_UPDATE_GENERATE_DELAY {
+		keyEnt.GenerateStart = time.Now().Add(time.Duration(newCfg.GenerateDelay) * time.Second)
+	}
+	authData.Keys[newCfg.KeyId] = keyEnt
+	return true, nil
+}
+
+func (server *OSPFV2Server) deleteIntfAuthKey(cfg *objects.Ospfv2IntfAuthKey) (bool, error) {
+	server.logger.Info("Intf auth key configuration delete")
+	intfConfKey := IntfConfKey{
+		IpAddr:  cfg.IpAddress,
+		IntfIdx: cfg.AddressLessIfIdx,
+	}
+	intfConfEnt, exist := server.IntfConfMap[intfConfKey]
+	if !exist {
+		server.logger.Err("Ospf Interface configuration doesnot exist")
+		return false, errors.New("Ospf Interface configuration doesnot exist")
+	}
+	authData := intfConfEnt.authData
+	authData.AuthMutex.Lock()
+	defer authData.AuthMutex.Unlock()
+	if _, exist := authData.Keys[cfg.KeyId]; !exist {
+		server.logger.Err("Unable to delete intf auth key doesnot exist")
+		return false, errors.New("Unable to delete intf auth key doesnot exist")
+	}
+	delete(authData.Keys, cfg.KeyId)
+	return true, nil
+}
@@ -0,0 +1,255 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"bytes"
+	"encoding/hex"
+	"l3/ospfv2/objects"
+	"testing"
+	"time"
+)
+
+// newTestAuthPkt returns an encoded hello header followed by 4 bytes of payload
+func newTestAuthPkt(authType uint8) []byte {
+	hdr := OSPFHeader{
+		Ver:      OSPF_VERSION_2,
+		PktType:  HelloType,
+		Pktlen:   OSPF_HEADER_SIZE + 4,
+		RouterId: 0x01010101,
+		AuthType: uint16(authType),
+	}
+	return append(encodeOspfHdr(hdr), 0xde, 0xad, 0xbe, 0xef)
+}
+
+func newTestAuthIntf(authType uint8, authKey string, authKeyId uint8) IntfConf {
+	return IntfConf{
+		AuthType:  uint16(authType),
+		AuthKey:   encodeIntfAuthKey(authKey),
+		AuthKeyId: authKeyId,
+		authData:  NewIntfAuthStruct(),
+	}
+}
+
+func verifyTestAuthPkt(server *OSPFV2Server, ent IntfConf, pkt []byte, srcIp uint32) error {
+	ospfHdr := NewOSPFHeader()
+	decodeOspfHdr(pkt, ospfHdr)
+	return server.verifyOspfAuth(ent, pkt, ospfHdr, srcIp)
+}
+
+func TestComputeOspfMd5Digest(t *testing.T) {
+	pkt := newTestAuthPkt(objects.AUTH_TYPE_MD5)
+	pkt[18] = 1
+	pkt[19] = OSPF_AUTH_MD5_DIGEST_LEN
+	pkt[23] = 1
+	digest := computeOspfMd5Digest(pkt, encodeIntfAuthKey("secret"))
+	// MD5 of the packet followed by the key null padded to 16 bytes
+	if hex.EncodeToString(digest) != "525e4234f3a4f3daa4605565f96df8ac" {
+		t.Error("Unexpected message digest", hex.EncodeToString(digest))
+	}
+}
+
+func TestOspfSimplePasswordAuth(t *testing.T) {
+	server := &OSPFV2Server{}
+	ent := newTestAuthIntf(objects.AUTH_TYPE_SIMPLE_PASSWORD, "passwd", 0)
+	pkt := server.encodeOspfAuth(ent, newTestAuthPkt(objects.AUTH_TYPE_SIMPLE_PASSWORD))
+	if !bytes.Equal(pkt[16:OSPF_HEADER_SIZE], ent.AuthKey[:OSPF_AUTH_SIMPLE_PASS_LEN]) {
+		t.Error("Password not encoded", pkt[16:OSPF_HEADER_SIZE])
+	}
+	if err := verifyTestAuthPkt(server, ent, pkt, 1); err != nil {
+		t.Error("Valid password dropped", err)
+	}
+	other := newTestAuthIntf(objects.AUTH_TYPE_SIMPLE_PASSWORD, "other", 0)
+	if err := verifyTestAuthPkt(server, other, pkt, 1); err == nil {
+		t.Error("Wrong password accepted")
+	}
+	none := newTestAuthIntf(objects.AUTH_TYPE_NONE, "", 0)
+	if err := verifyTestAuthPkt(server, none, pkt, 1); err == nil {
+		t.Error("Auth type mismatch accepted")
+	}
+	typeMismatch, failures, _ := server.getIntfAuthStats(none)
+	if typeMismatch != 1 || failures != 0 {
+		t.Error("Unexpected auth stats", typeMismatch, failures)
+	}
+}
+
+func TestOspfMd5Auth(t *testing.T) {
+	server := &OSPFV2Server{}
+	ent := newTestAuthIntf(objects.AUTH_TYPE_MD5, "secret", 5)
+	pkt1 := server.encodeOspfAuth(ent, newTestAuthPkt(objects.AUTH_TYPE_MD5))
+	pkt2 := server.encodeOspfAuth(ent, newTestAuthPkt(objects.AUTH_TYPE_MD5))
+	if len(pkt1) != OSPF_HEADER_SIZE+4+OSPF_AUTH_MD5_DIGEST_LEN {
+		t.Fatal("Message digest not appended", len(pkt1))
+	}
+	if pkt1[18] != 5 || pkt1[19] != OSPF_AUTH_MD5_DIGEST_LEN {
+		t.Error("Unexpected Key ID or Auth Data length", pkt1[18], pkt1[19])
+	}
+
+	rxEnt := newTestAuthIntf(objects.AUTH_TYPE_MD5, "secret", 5)
+	if err := verifyTestAuthPkt(server, rxEnt, pkt2, 1); err != nil {
+		t.Error("Valid packet dropped", err)
+	}
+	// Same sequence number is accepted, a lower one is a replay
+	if err := verifyTestAuthPkt(server, rxEnt, pkt2, 1); err != nil {
+		t.Error("Packet with same sequence number dropped", err)
+	}
+	if err := verifyTestAuthPkt(server, rxEnt, pkt1, 1); err == nil {
+		t.Error("Replayed packet accepted")
+	}
+	// Sequence numbers are tracked per nbr
+	if err := verifyTestAuthPkt(server, rxEnt, pkt1, 2); err != nil {
+		t.Error("Packet from other nbr dropped", err)
+	}
+	server.resetNbrCryptoSeqNum(rxEnt, 1)
+	if err := verifyTestAuthPkt(server, rxEnt, pkt1, 1); err != nil {
+		t.Error("Packet dropped after sequence number reset", err)
+	}
+	_, failures, replayDrops := server.getIntfAuthStats(rxEnt)
+	if failures != 0 || replayDrops != 1 {
+		t.Error("Unexpected auth stats", failures, replayDrops)
+	}
+
+	tampered := append([]byte(nil), pkt2...)
+	tampered[OSPF_HEADER_SIZE] ^= 0xff
+	if err := verifyTestAuthPkt(server, rxEnt, tampered, 3); err == nil {
+		t.Error("Tampered packet accepted")
+	}
+	otherKey := newTestAuthIntf(objects.AUTH_TYPE_MD5, "other", 5)
+	if err := verifyTestAuthPkt(server, otherKey, pkt2, 1); err == nil {
+		t.Error("Packet with wrong key accepted")
+	}
+	otherKeyId := newTestAuthIntf(objects.AUTH_TYPE_MD5, "secret", 6)
+	if err := verifyTestAuthPkt(server, otherKeyId, pkt2, 1); err == nil {
+		t.Error("Packet with unknown Key ID accepted")
+	}
+}
+
+func TestOspfMd5AuthLength(t *testing.T) {
+	server := &OSPFV2Server{}
+	ent := newTestAuthIntf(objects.AUTH_TYPE_MD5, "secret", 1)
+	pkt := server.encodeOspfAuth(ent, newTestAuthPkt(objects.AUTH_TYPE_MD5))
+
+	if err := verifyTestAuthPkt(server, ent, pkt[:len(pkt)-1], 1); err == nil {
+		t.Error("Truncated message digest accepted")
+	}
+	badLen := append([]byte(nil), pkt...)
+	badLen[19] = OSPF_AUTH_MD5_DIGEST_LEN - 1
+	if err := verifyTestAuthPkt(server, ent, badLen, 1); err == nil {
+		t.Error("Invalid Auth Data length accepted")
+	}
+	_, failures, _ := server.getIntfAuthStats(ent)
+	if failures != 2 {
+		t.Error("Unexpected auth failures", failures)
+	}
+	if err := verifyTestAuthPkt(server, ent, pkt, 1); err != nil {
+		t.Error("Valid packet dropped", err)
+	}
+}
+
+// RFC 2328 Appendix D.3 key rollover
+func TestOspfMd5AuthKeyRollover(t *testing.T) {
+	server := &OSPFV2Server{}
+	ent := newTestAuthIntf(objects.AUTH_TYPE_MD5, "old", 1)
+	rxEnt := newTestAuthIntf(objects.AUTH_TYPE_MD5, "old", 1)
+	now := time.Now()
+	ent.authData.Keys[2] = IntfAuthKey{
+		Key:           encodeIntfAuthKey("new"),
+		GenerateStart: now.Add(time.Hour),
+	}
+	rxEnt.authData.Keys[2] = IntfAuthKey{
+		Key:           encodeIntfAuthKey("new"),
+		GenerateStart: now.Add(time.Hour),
+	}
+
+	// New key is accepted before it is used for sending
+	pkt := server.encodeOspfAuth(ent, newTestAuthPkt(objects.AUTH_TYPE_MD5))
+	if pkt[18] != 1 {
+		t.Error("Key not yet activated used for sending", pkt[18])
+	}
+	if err := verifyTestAuthPkt(server, rxEnt, pkt, 1); err != nil {
+		t.Error("Packet with old key dropped", err)
+	}
+
+	ent.authData.Keys[2] = IntfAuthKey{
+		Key:           encodeIntfAuthKey("new"),
+		GenerateStart: now,
+	}
+	pkt = server.encodeOspfAuth(ent, newTestAuthPkt(objects.AUTH_TYPE_MD5))
+	if pkt[18] != 2 {
+		t.Error("Activated key not used for sending", pkt[18])
+	}
+	if err := verifyTestAuthPkt(server, rxEnt, pkt, 1); err != nil {
+		t.Error("Packet with new key dropped", err)
+	}
+
+	// Most recently activated key wins, ties go to the higher Key ID
+	ent.authData.Keys[3] = IntfAuthKey{
+		Key:           encodeIntfAuthKey("newer"),
+		GenerateStart: now,
+	}
+	ent.authData.Keys[4] = IntfAuthKey{
+		Key:           encodeIntfAuthKey("older"),
+		GenerateStart: now.Add(-time.Hour),
+	}
+	if keyId, _ := ent.authData.getTxAuthKey(ent, now); keyId != 3 {
+		t.Error("Unexpected key used for sending", keyId)
+	}
+}
+
+func TestIntfAuthKeyConfig(t *testing.T) {
+	rtr := newTestRouter(t, "1.1.1.1")
+	defer rtr.close(t)
+	wire := NewVirtualWire()
+	rtr.addIntf(t, "eth0", "10.0.0.1", 24, 1, wire)
+	ip, _ := convertDotNotationToUint32("10.0.0.1")
+	keyCfg := &objects.Ospfv2IntfAuthKey{
+		IpAddress:     ip,
+		KeyId:         2,
+		Key:           "new",
+		GenerateDelay: 3600,
+	}
+	rtr.createConfig(t, CREATE_OSPFV2_INTF_AUTH_KEY, &CreateOspfv2IntfAuthKeyInArgs{Cfg: keyCfg})
+	ret := rtr.request(CREATE_OSPFV2_INTF_AUTH_KEY, &CreateOspfv2IntfAuthKeyInArgs{Cfg: keyCfg})
+	if retObj, ok := ret.(*CreateConfigOutArgs); !ok || retObj.RetVal {
+		t.Error("Duplicate auth key created")
+	}
+	ret = rtr.request(DELETE_OSPFV2_INTF, &DeleteOspfv2IntfInArgs{Cfg: newTestIntfCfg(ip, 1, objects.INTF_PASSIVE_DEFAULT)})
+	if retObj, ok := ret.(*DeleteConfigOutArgs); !ok || retObj.RetVal {
+		t.Error("Interface deleted while auth keys are configured")
+	}
+
+	keyCfg.GenerateDelay = 0
+	ret = rtr.request(UPDATE_OSPFV2_INTF_AUTH_KEY, &UpdateOspfv2IntfAuthKeyInArgs{
+		OldCfg:  keyCfg,
+		NewCfg:  keyCfg,
+		AttrSet: []bool{false, false, false, false, true},
+	})
+	if retObj, ok := ret.(*UpdateConfigOutArgs); !ok || !retObj.RetVal {
+		t.Error("Auth key update failed")
+	}
+	ret = rtr.request(DELETE_OSPFV2_INTF_AUTH_KEY, &DeleteOspfv2IntfAuthKeyInArgs{Cfg: keyCfg})
+	if retObj, ok := ret.(*DeleteConfigOutArgs); !ok || !retObj.RetVal {
+		t.Error("Auth key delete failed")
+	}
+}
@@ -0,0 +1,344 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"bfdd"
+	"encoding/json"
+	nanomsg "github.com/op/go-nanomsg"
+	"l3/bfd/bfddCommonDefs"
+	"l3/ospfv2/objects"
+	"strconv"
+	"time"
+	"utils/ipcutils"
+)
+
+const (
+	BFD_SESSION_OWNER = "ospf"
+	BFD_SESSION_PARAM = "default"
+
+	BFD_SESSION_QUEUE_LEN = 100
+)
+
+type BfddClient struct {
+	OspfClientBase
+	ClientHdl *bfdd.BFDDServicesClient
+}
+
+type BfdCommStruct struct {
+	bfdSubSocketCh    chan []byte
+	bfdClient         BfddClient
+	bfdSessionCh      chan BfdSessionMsg // nil when bfdd is not in use
+	bfdSubSocket      *nanomsg.SubSocket
+	bfdSubSocketErrCh chan error
+}
+
+type NbrBfdStateMsg struct {
+	NbrIP     uint32
+	SessionUp bool
+}
+
+type BfdSessionMsg struct {
+	NbrIP  uint32
+	IfName string
+	Create bool
+}
+
+func (server *OSPFV2Server) initBfdComm() error {
+	server.bfdComm.bfdSubSocketCh = make(chan []byte)
+	server.bfdComm.bfdSubSocketErrCh = make(chan error)
+	return nil
+}
+
+/*
+bfdd is connected to and the sessions are created from the
+bfd client routine, so neither ospf startup nor the nbr FSM
+waits on bfdd.
+*/
+func (server *OSPFV2Server) StartBfdClient(port int) {
+	server.bfdComm.bfdSessionCh = make(chan BfdSessionMsg, BFD_SESSION_QUEUE_LEN)
+	go server.processBfdSessions(port)
+}
+
+func (server *OSPFV2Server) processBfdSessions(port int) {
+	server.ConnectToBfdServer(port)
+	for msg := range server.bfdComm.bfdSessionCh {
+		if msg.Create {
+			server.createBfdSession(msg.NbrIP, msg.IfName)
+		} else {
+			server.deleteBfdSession(msg.NbrIP)
+		}
+	}
+}
+
+func (server *OSPFV2Server) ConnectToBfdServer(port int) {
+	var err error
+	server.logger.Info("found bfdd at port", port)
+	server.bfdComm.bfdClient.Address = "localhost:" + strconv.Itoa(port)
+	server.bfdComm.bfdClient.Transport, server.bfdComm.bfdClient.PtrProtocolFactory, err = ipcutils.CreateIPCHandles(server.bfdComm.bfdClient.Address)
+	if err != nil {
+		server.logger.Info("Failed to connect to bfdd, retrying until connection is successful")
+		count := 0
+		ticker := time.NewTicker(time.Duration(1000) * time.Millisecond)
+		for _ = range ticker.C {
+			server.bfdComm.bfdClient.Transport, server.bfdComm.bfdClient.PtrProtocolFactory, err = ipcutils.CreateIPCHandles(server.bfdComm.bfdClient.Address)
+			if err == nil {
+				ticker.Stop()
+				break
+			}
+			count++
+			if (count % 10) == 0 {
+				server.logger.Info("Still can't connect to bfdd, retrying..")
+			}
+		}
+	}
+	server.logger.Info("Ospfd is connected to bfdd")
+	server.bfdComm.bfdClient.ClientHdl = bfdd.NewBFDDServicesClientFactory(server.bfdComm.bfdClient.Transport, server.bfdComm.bfdClient.PtrProtocolFactory)
+	server.bfdComm.bfdClient.IsConnected = true
+}
+
+func (server *OSPFV2Server) StartBfdSubscriber() {
+	server.logger.Info("Listen for bfdd updates")
+	err := server.listenForBfdUpdates(bfddCommonDefs.PUB_SOCKET_ADDR)
+	if err != nil {
+		return
+	}
+	go server.createBfdSubscriber()
+}
+
+func (server *OSPFV2Server) listenForBfdUpdates(address string) error {
+	var err error
+	if server.bfdComm.bfdSubSocket, err = nanomsg.NewSubSocket(); err != nil {
+		server.logger.Err("ERR: Failed to create BFD subscribe socket, error:", err)
+		return err
+	}
+
+	if err = server.bfdComm.bfdSubSocket.Subscribe(""); err != nil {
+		server.logger.Err("ERR: Failed to subscribe to \"\" on BFD subscribe socket, error:", err)
+		return err
+	}
+
+	if _, err = server.bfdComm.bfdSubSocket.Connect(address); err != nil {
+		server.logger.Err("ERR: Failed to connect to BFD publisher socket, address:", address, "error:", err)
+		return err
+	}
+
+	server.logger.Info("Connected to BFD publisher at address:", address)
+	if err = server.bfdComm.bfdSubSocket.SetRecvBuffer(1024 * 1024); err != nil {
+		server.logger.Err("ERR: Failed to set the buffer size for BFD publisher socket, error:", err)
+		return err
+	}
+	return nil
+}
+
+func (server *OSPFV2Server) createBfdSubscriber() {
+	for {
+		server.logger.Info("Read on Bfdd subscriber socket...")
+		bfdRxBuf, err := server.bfdComm.bfdSubSocket.Recv(0)
+		if err != nil {
+			server.logger.Err("ERR: Recv on Bfdd subscriber socket failed with error:", err)
+			server.bfdComm.bfdSubSocketErrCh <- err
+			continue
+		}
+		server.bfdComm.bfdSubSocketCh <- bfdRxBuf
+	}
+}
+
+func (server *OSPFV2Server) processBfdNotification(bfdRxBuf []byte) {
+	if server.globalData.AdminState == false {
+		return
+	}
+	var bfdMsg bfddCommonDefs.BfddNotifyMsg
+	err := json.Unmarshal(bfdRxBuf, &bfdMsg)
+	if err != nil {
+		server.logger.Err("Unable to unmarshal bfdRxBuf:", bfdRxBuf)
+		return
+	}
+	nbrIP, err := convertDotNotationToUint32(bfdMsg.DestIp)
+	if err != nil {
+		server.logger.Err("Invalid nbr address in BFD notification:", bfdMsg.DestIp)
+		return
+	}
+	server.NbrConfData.nbrBfdStateCh <- NbrBfdStateMsg{
+		NbrIP:     nbrIP,
+		SessionUp: bfdMsg.State,
+	}
+}
+
+func (server *OSPFV2Server) createBfdSession(nbrIP uint32, ifName string) {
+	session := bfdd.NewBfdSession()
+	session.IpAddr = convertUint32ToDotNotation(nbrIP)
+	session.ParamName = BFD_SESSION_PARAM
+	session.Interface = ifName
+	session.PerLink = false
+	session.Owner = BFD_SESSION_OWNER
+	_, err := server.bfdComm.bfdClient.ClientHdl.CreateBfdSession(session)
+	if err != nil {
+		server.logger.Err("Unable to create BFD session for", session.IpAddr, err)
+		return
+	}
+	server.logger.Info("Created BFD session for", session.IpAddr)
+}
+
+func (server *OSPFV2Server) deleteBfdSession(nbrIP uint32) {
+	session := bfdd.NewBfdSession()
+	session.IpAddr = convertUint32ToDotNotation(nbrIP)
+	session.Owner = BFD_SESSION_OWNER
+	_, err := server.bfdComm.bfdClient.ClientHdl.DeleteBfdSession(session)
+	if err != nil {
+		server.logger.Err("Unable to delete BFD session for", session.IpAddr, err)
+		return
+	}
+	server.logger.Info("Deleted BFD session for", session.IpAddr)
+}
+
+/*
+Queues a session request for the bfd client routine, the
+caller is never blocked.
+*/
+func (server *OSPFV2Server) sendBfdSessionMsg(msg BfdSessionMsg) bool {
+	if server.bfdComm.bfdSessionCh == nil {
+		server.logger.Debug("bfdd is not in use. Dropping BFD session request", msg)
+		return false
+	}
+	select {
+	case server.bfdComm.bfdSessionCh <- msg:
+		return true
+	default:
+		server.logger.Err("BFD session queue is full. Dropping BFD session request", msg)
+		return false
+	}
+}
+
+/*
+Nbrs in 2-Way state or above are registered with bfdd
+when BFD is enabled on the interface.
+*/
+func (server *OSPFV2Server) updateNbrBfdSession(nbrConf *NbrConf) {
+	if nbrConf.State < NbrTwoWay {
+		if nbrConf.BfdState != objects.BFD_STATE_NONE {
+			server.sendBfdSessionMsg(BfdSessionMsg{NbrIP: nbrConf.NbrIP})
+			nbrConf.BfdState = objects.BFD_STATE_NONE
+		}
+		return
+	}
+	if nbrConf.BfdState != objects.BFD_STATE_NONE {
+		return
+	}
+	intfConfEnt, exist := server.IntfConfMap[nbrConf.IntfKey]
+	if !exist || !intfConfEnt.BfdEnable {
+		return
+	}
+	msg := BfdSessionMsg{
+		NbrIP:  nbrConf.NbrIP,
+		IfName: intfConfEnt.IfName,
+		Create: true,
+	}
+	if server.sendBfdSessionMsg(msg) {
+		nbrConf.BfdState = objects.BFD_STATE_INIT
+	}
+}
+
+func (server *OSPFV2Server) deleteNbrBfdSession(nbrConf NbrConf) {
+	if nbrConf.BfdState == objects.BFD_STATE_NONE {
+		return
+	}
+	server.sendBfdSessionMsg(BfdSessionMsg{NbrIP: nbrConf.NbrIP})
+}
+
+/*
+Enabling or disabling BFD on the interface only changes the
+sessions of its nbrs, the interface FSM keeps running.
+*/
+func (server *OSPFV2Server) updateIntfBfdEnable(intfKey IntfConfKey, bfdEnable bool) {
+	intfConfEnt, _ := server.IntfConfMap[intfKey]
+	intfConfEnt.BfdEnable = bfdEnable
+	server.IntfConfMap[intfKey] = intfConfEnt
+	areaEnt, _ := server.AreaConfMap[intfConfEnt.AreaId]
+	if intfConfEnt.AdminState == true &&
+		server.globalData.AdminState == true &&
+		areaEnt.AdminState == true &&
+		intfConfEnt.OperState == true {
+		server.NbrConfData.nbrBfdEnableCh <- intfKey
+	}
+}
+
+func (server *OSPFV2Server) ProcessIntfBfdEnable(intfKey IntfConfKey) {
+	intfConfEnt, exist := server.IntfConfMap[intfKey]
+	if !exist {
+		return
+	}
+	for nbrKey, nbrConf := range server.NbrConfMap {
+		if nbrConf.IntfKey != intfKey {
+			continue
+		}
+		if intfConfEnt.BfdEnable {
+			server.updateNbrBfdSession(&nbrConf)
+		} else {
+			server.deleteNbrBfdSession(nbrConf)
+			nbrConf.BfdState = objects.BFD_STATE_NONE
+		}
+		server.NbrConfMap[nbrKey] = nbrConf
+	}
+}
+
+/*
+A BFD session going down is a KillNbr event. Only sessions
+which have come up are acted upon.
+*/
+func (server *OSPFV2Server) ProcessNbrBfdState(msg NbrBfdStateMsg) {
+	for nbrKey, nbrConf := range server.NbrConfMap {
+		if nbrConf.NbrIP != msg.NbrIP ||
+			nbrConf.BfdState == objects.BFD_STATE_NONE {
+			continue
+		}
+		oldState := nbrConf.BfdState
+		if msg.SessionUp {
+			nbrConf.BfdState = objects.BFD_STATE_UP
+		} else {
+			nbrConf.BfdState = objects.BFD_STATE_DOWN
+		}
+		server.NbrConfMap[nbrKey] = nbrConf
+		if msg.SessionUp || oldState != objects.BFD_STATE_UP {
+			continue
+		}
+		// Adjacency with a restarting nbr is kept up for the grace period
+		if nbrConf.GrHelperStatus == objects.RESTART_HELPER_STATUS_HELPING {
+			server.logger.Info("BFD session down for restarting nbr, keeping adjacency", nbrKey)
+			continue
+		}
+		server.logger.Info("BFD session down, killing nbr", nbrKey)
+		select {
+		case server.NbrConfData.nbrKillCh <- nbrKey:
+			if nbrConf.NbrDeadTimer != nil {
+				nbrConf.NbrDeadTimer.Stop()
+			}
+		default:
+			// Kill event queue is full, expire the inactivity timer instead
+			if nbrConf.NbrDeadTimer != nil {
+				nbrConf.NbrDeadTimer.Reset(0)
+			}
+		}
+	}
+}
@@ -0,0 +1,175 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"encoding/json"
+	"l3/bfd/bfddCommonDefs"
+	"l3/ospfv2/objects"
+	"testing"
+	"time"
+)
+
+// notifyBfdState publishes a BFD session state change as bfdd does
+func (rtr *testRouter) notifyBfdState(t *testing.T, nbrIpAddr string, up bool) {
+	buf, err := json.Marshal(bfddCommonDefs.BfddNotifyMsg{
+		DestIp: nbrIpAddr,
+		State:  up,
+	})
+	if err != nil {
+		t.Fatal("Unable to marshal bfd notification", err)
+	}
+	rtr.server.bfdComm.bfdSubSocketCh <- buf
+}
+
+func readTestBfdSessionMsg(t *testing.T, sessionCh chan BfdSessionMsg) BfdSessionMsg {
+	select {
+	case msg := <-sessionCh:
+		return msg
+	case <-time.After(testConvergeTimeout):
+		t.Fatal("Timed out waiting for a BFD session request")
+	}
+	return BfdSessionMsg{}
+}
+
+/*
+A BFD session going down kills the nbr without waiting for
+the inactivity timer. The test stands in for the bfd client
+routine and bfdd.
+*/
+func TestBfdSessionDown(t *testing.T) {
+	lan := NewVirtualWire()
+	defer lan.SetLinkUp(false)
+	rtrA := newTestRouter(t, "1.1.1.1")
+	defer rtrA.close(t)
+	rtrB := newTestRouter(t, "2.2.2.2")
+	defer rtrB.close(t)
+	sessionCh := make(chan BfdSessionMsg, BFD_SESSION_QUEUE_LEN)
+	rtrA.server.bfdComm.bfdSessionCh = sessionCh
+
+	ip := rtrA.addL3Intf("eth0", "10.0.0.1", 24, lan)
+	cfg := newTestIntfCfg(ip, 1, objects.INTF_PASSIVE_DEFAULT)
+	cfg.BfdEnable = true
+	rtrA.createConfig(t, CREATE_OSPFV2_INTF, &CreateOspfv2IntfInArgs{
+		Cfg: cfg,
+	})
+	waitFor(t, "A to become DR", func() bool {
+		return rtrA.isIntfState("10.0.0.1", objects.INTF_FSM_STATE_DR)
+	})
+	rtrB.addIntf(t, "eth0", "10.0.0.2", 24, 1, lan)
+	waitForAdjacency(t, rtrA, "10.0.0.1", rtrB, "10.0.0.2")
+
+	nbrIp, _ := convertDotNotationToUint32("10.0.0.2")
+	msg := readTestBfdSessionMsg(t, sessionCh)
+	if !msg.Create || msg.NbrIP != nbrIp || msg.IfName != "eth0" {
+		t.Fatal("Unexpected BFD session request", msg)
+	}
+	if nbr := rtrA.getNbrState("10.0.0.2"); nbr == nil || nbr.BfdState != objects.BFD_STATE_INIT {
+		t.Error("BFD session not pending for nbr", nbr)
+	}
+
+	// Down before the session came up is ignored
+	rtrA.notifyBfdState(t, "10.0.0.2", false)
+	waitFor(t, "BFD session down on A", func() bool {
+		nbr := rtrA.getNbrState("10.0.0.2")
+		return nbr != nil && nbr.BfdState == objects.BFD_STATE_DOWN
+	})
+	if !rtrA.isNbrFull("10.0.0.2") {
+		t.Error("Nbr killed before its BFD session came up")
+	}
+
+	rtrA.notifyBfdState(t, "10.0.0.2", true)
+	waitFor(t, "BFD session up on A", func() bool {
+		nbr := rtrA.getNbrState("10.0.0.2")
+		return nbr != nil && nbr.BfdState == objects.BFD_STATE_UP
+	})
+	rtrA.notifyBfdState(t, "10.0.0.2", false)
+	msg = readTestBfdSessionMsg(t, sessionCh)
+	if msg.Create || msg.NbrIP != nbrIp {
+		t.Fatal("BFD session not deleted with the nbr", msg)
+	}
+
+	// The adjacency comes back up with a new session
+	msg = readTestBfdSessionMsg(t, sessionCh)
+	if !msg.Create || msg.NbrIP != nbrIp {
+		t.Error("BFD session not created for the new nbr", msg)
+	}
+	waitForAdjacency(t, rtrA, "10.0.0.1", rtrB, "10.0.0.2")
+}
+
+// Enabling and disabling BFD on an intf does not restart the intf FSM
+func TestBfdEnableUpdate(t *testing.T) {
+	lan := NewVirtualWire()
+	defer lan.SetLinkUp(false)
+	rtrA := newTestRouter(t, "1.1.1.1")
+	defer rtrA.close(t)
+	rtrB := newTestRouter(t, "2.2.2.2")
+	defer rtrB.close(t)
+	sessionCh := make(chan BfdSessionMsg, BFD_SESSION_QUEUE_LEN)
+	rtrA.server.bfdComm.bfdSessionCh = sessionCh
+
+	rtrA.addIntf(t, "eth0", "10.0.0.1", 24, 1, lan)
+	waitFor(t, "A to become DR", func() bool {
+		return rtrA.isIntfState("10.0.0.1", objects.INTF_FSM_STATE_DR)
+	})
+	rtrB.addIntf(t, "eth0", "10.0.0.2", 24, 1, lan)
+	waitForAdjacency(t, rtrA, "10.0.0.1", rtrB, "10.0.0.2")
+	intf := rtrA.getIntfState("10.0.0.1")
+	if intf == nil {
+		t.Fatal("No intf state for 10.0.0.1")
+	}
+	stateChanges := intf.NumOfStateChange
+
+	ip, _ := convertDotNotationToUint32("10.0.0.1")
+	nbrIp, _ := convertDotNotationToUint32("10.0.0.2")
+	cfg := newTestIntfCfg(ip, 1, objects.INTF_PASSIVE_DEFAULT)
+	cfg.BfdEnable = true
+	rtrA.updateIntf(t, cfg, 14)
+	msg := readTestBfdSessionMsg(t, sessionCh)
+	if !msg.Create || msg.NbrIP != nbrIp || msg.IfName != "eth0" {
+		t.Fatal("BFD session not created for the existing nbr", msg)
+	}
+	waitFor(t, "BFD session pending for nbr", func() bool {
+		nbr := rtrA.getNbrState("10.0.0.2")
+		return nbr != nil && nbr.BfdState == objects.BFD_STATE_INIT
+	})
+
+	cfg.BfdEnable = false
+	rtrA.updateIntf(t, cfg, 14)
+	msg = readTestBfdSessionMsg(t, sessionCh)
+	if msg.Create || msg.NbrIP != nbrIp {
+		t.Fatal("BFD session not deleted for the existing nbr", msg)
+	}
+	waitFor(t, "BFD session unregistered for nbr", func() bool {
+		nbr := rtrA.getNbrState("10.0.0.2")
+		return nbr != nil && nbr.BfdState == objects.BFD_STATE_NONE
+	})
+
+	if !rtrA.isNbrFull("10.0.0.2") {
+		t.Error("Adjacency lost on BFD update")
+	}
+	if intf := rtrA.getIntfState("10.0.0.1"); intf == nil || intf.NumOfStateChange != stateChanges {
+		t.Error("Intf FSM restarted on BFD update", stateChanges, intf)
+	}
+}
@@ -33,6 +33,8 @@ import (
 func (server *OSPFV2Server) StartDBClient() {
 	for {
 		select {
+		case <-server.stopCh:
+			return
 		case msg := <-server.MessagingChData.RouteTblToDBClntChData.RouteAddMsgCh:
 			server.RouteAddToDB(msg)
 		case msg := <-server.MessagingChData.RouteTblToDBClntChData.RouteDelMsgCh:
@@ -163,3 +165,65 @@ func (server *OSPFV2Server) RouteDelFromDB(msg RouteDelMsg) {
 	}
 	return
 }
+
+/*
+Network routes installed by the previous instance, used as the
+old routing table after a graceful restart so only the routes
+which changed meanwhile are updated in ribd.
+*/
+func (server *OSPFV2Server) GetAllRoutesFromDB() map[RoutingTblEntryKey]GlobalRoutingTblEntry {
+	routeTbl := make(map[RoutingTblEntryKey]GlobalRoutingTblEntry)
+	if server.dbHdl == nil {
+		server.logger.Err("Db Handler is nil")
+		return routeTbl
+	}
+	var dbObj objects.Ospfv2RouteState
+	objList, err := server.dbHdl.GetAllObjFromDb(dbObj)
+	if err != nil {
+		server.logger.Err(fmt.Sprintln("Failed to get routes from db:", err))
+		return routeTbl
+	}
+	for idx := 0; idx < len(objList); idx++ {
+		obj, ok := objList[idx].(objects.Ospfv2RouteState)
+		if !ok || obj.DestType != "Network" {
+			continue
+		}
+		var rKey RoutingTblEntryKey
+		var rEnt GlobalRoutingTblEntry
+		rKey.DestType = Network
+		rKey.DestId, err = convertDotNotationToUint32(obj.DestId)
+		if err != nil {
+			continue
+		}
+		rKey.AddrMask, err = convertDotNotationToUint32(obj.AddrMask)
+		if err != nil {
+			continue
+		}
+		rEnt.AreaId, _ = convertDotNotationToUint32(obj.AreaId)
+		rEnt.RoutingTblEnt.OptCapabilities = uint8(obj.OptCapabilities)
+		switch obj.PathType {
+		case "Intra Area":
+			rEnt.RoutingTblEnt.PathType = IntraArea
+		case "Inter Area":
+			rEnt.RoutingTblEnt.PathType = InterArea
+		case "Type1 External":
+			rEnt.RoutingTblEnt.PathType = Type1Ext
+		case "Type2 External":
+			rEnt.RoutingTblEnt.PathType = Type2Ext
+		}
+		rEnt.RoutingTblEnt.Cost = uint32(obj.Cost)
+		rEnt.RoutingTblEnt.Type2Cost = uint32(obj.Type2Cost)
+		rEnt.RoutingTblEnt.NumOfPaths = int(obj.NumOfPaths)
+		rEnt.RoutingTblEnt.NextHops = make(map[NextHop]bool)
+		for _, nh := range obj.NextHops {
+			var nextHop NextHop
+			nextHop.IfIPAddr, _ = convertDotNotationToUint32(nh.IntfIPAddr)
+			nextHop.IfIdx = nh.IntfIdx
+			nextHop.NextHopIP, _ = convertDotNotationToUint32(nh.NextHopIPAddr)
+			nextHop.AdvRtr, _ = convertDotNotationToUint32(nh.AdvRtrId)
+			rEnt.RoutingTblEnt.NextHops[nextHop] = true
+		}
+		routeTbl[rKey] = rEnt
+	}
+	return routeTbl
+}
@@ -25,6 +25,7 @@ package server
 
 import (
 	"encoding/binary"
+	"errors"
 	"fmt"
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
@@ -210,7 +211,7 @@ func encodeLSAHeader(dd_data NbrDbdData) []byte {
 func encodeDatabaseDescriptionData(dd_data NbrDbdData) []byte {
 	pkt := make([]byte, OSPF_DBD_MIN_SIZE)
 	binary.BigEndian.PutUint16(pkt[0:2], dd_data.interface_mtu)
-	pkt[2] = 0x2
+	pkt[2] = dd_data.options
 	imms := 0
 	if dd_data.ibit {
 		imms = imms | 0x4
@@ -252,6 +253,10 @@ func (server *OSPFV2Server) BuildAndSendDdBDPkt(nbrConf NbrConf, dbdData NbrDbdD
 		AuthType: ent.AuthType,
 	}
 
+	// Rfc 2328 10.8: the options supported in the attached area,
+	// no E-bit in stub and NSSA areas
+	dbdData.options = server.getAreaLsaOptions(ent.AreaId) | OOption
+
 	ospfPktlen := OSPF_HEADER_SIZE
 	lsa_header_size := OSPF_LSA_HEADER_SIZE * len(dbdData.lsa_headers)
 	ospfPktlen = ospfPktlen + OSPF_DBD_MIN_SIZE + lsa_header_size
@@ -265,13 +270,11 @@ func (server *OSPFV2Server) BuildAndSendDdBDPkt(nbrConf NbrConf, dbdData NbrDbdD
 
 	ospf := append(ospfEncHdr, dbdDataEnc...)
 	server.logger.Debug("OSPF DBD:", ospf)
-	csum := computeCheckSum(ospf)
-	binary.BigEndian.PutUint16(ospf[12:14], csum)
-	binary.BigEndian.PutUint64(ospf[16:24], ent.AuthKey)
+	ospf = server.encodeOspfAuth(ent, ospf)
 
 	var DstIP net.IP
 
-	ipPktlen := IP_HEADER_MIN_LEN + ospfHdr.Pktlen
+	ipPktlen := IP_HEADER_MIN_LEN + len(ospf)
 	if ent.FSMState == objects.INTF_FSM_STATE_P2P {
 		DstIP = net.ParseIP(config.AllSPFRouters)
 		dstMAC, _ = net.ParseMAC(ALLSPFROUTERMAC)
@@ -311,7 +314,7 @@ func (server *OSPFV2Server) BuildAndSendDdBDPkt(nbrConf NbrConf, dbdData NbrDbdD
 }
 
 func (server *OSPFV2Server) ProcessRxDbdPkt(data []byte, ospfHdrMd *OspfHdrMetadata,
-	ipHdrMd *IpHdrMetadata, nbrKey NbrConfKey) error {
+	ipHdrMd *IpHdrMetadata, nbrKey NbrConfKey, intfKey IntfConfKey) error {
 	ospfdbd_data := NewOspfDatabaseDescriptionData()
 	ospfdbd_data.lsa_headers = []ospfLSAHeader{}
 	//routerId := convertIPv4ToUint32(ospfHdrMd.routerId)
@@ -325,6 +328,18 @@ func (server *OSPFV2Server) ProcessRxDbdPkt(data []byte, ospfHdrMd *OspfHdrMetad
 	DecodeDatabaseDescriptionData(data, ospfdbd_data, Pktlen)
 	//ipaddr := convertIPInByteToString(ipHdrMd.srcIP)
 
+	/* Reject DBD when nbr MTU is larger than ours. The MTU is
+	   set to 0 on virtual links. */
+	intfConf, _ := server.IntfConfMap[intfKey]
+	if intfConf.Type != objects.INTF_TYPE_VIRTUAL &&
+		uint32(ospfdbd_data.interface_mtu) > intfConf.Mtu {
+		server.updatePktStats(intfKey, nbrKey, func(stats *objects.Ospfv2PktStats) {
+			stats.NumOfMtuMismatch++
+		})
+		return errors.New(fmt.Sprintln("DBD: Interface MTU mismatch. nbr mtu ",
+			ospfdbd_data.interface_mtu, " intf mtu ", intfConf.Mtu))
+	}
+
 	dbdNbrMsg := NbrDbdMsg{
 		nbrConfKey: nbrKey,
 		nbrDbdData: *ospfdbd_data,
@@ -0,0 +1,126 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"github.com/google/gopacket/layers"
+	"l3/ospfv2/objects"
+	"testing"
+)
+
+func TestDbdEncodeDecode(t *testing.T) {
+	dbdData := NbrDbdData{
+		options:            EOption | OOption,
+		interface_mtu:      1500,
+		dd_sequence_number: 0x1234,
+		mbit:               true,
+		msbit:              true,
+		lsa_headers: []ospfLSAHeader{
+			ospfLSAHeader{
+				ls_age:          10,
+				options:         EOption,
+				ls_type:         RouterLSA,
+				link_state_id:   testIp("2.2.2.2"),
+				adv_router_id:   testIp("2.2.2.2"),
+				ls_sequence_num: InitialSequenceNum,
+				ls_checksum:     0xabcd,
+				ls_len:          36,
+			},
+		},
+	}
+	data := encodeDatabaseDescriptionData(dbdData)
+	var rxDbdData NbrDbdData
+	DecodeDatabaseDescriptionData(data, &rxDbdData,
+		uint16(OSPF_HEADER_SIZE+len(data)))
+	if rxDbdData.options != dbdData.options ||
+		rxDbdData.interface_mtu != dbdData.interface_mtu ||
+		rxDbdData.dd_sequence_number != dbdData.dd_sequence_number ||
+		rxDbdData.ibit || !rxDbdData.mbit || !rxDbdData.msbit {
+		t.Error("DBD mismatch", rxDbdData)
+	}
+	if len(rxDbdData.lsa_headers) != 1 ||
+		rxDbdData.lsa_headers[0] != dbdData.lsa_headers[0] {
+		t.Error("DBD LSA headers mismatch", rxDbdData.lsa_headers)
+	}
+}
+
+func TestDbdAreaOptions(t *testing.T) {
+	server := newTestServer("1.1.1.1")
+	addTestArea(server, 1, AreaConf{ImportASExtern: true})
+	intfKey := addTestIntf(server, 1, "10.0.0.1", objects.INTF_TYPE_POINT2POINT, objects.INTF_FSM_STATE_P2P)
+	nbrKey := addTestP2MPNbr(server, intfKey, "10.0.0.2", "2.2.2.2", NbrExchangeStart)
+	_, nbrRxHdl := openTestNbrWire(t, server, intfKey, PktIOParams{
+		IpAddr:   testIp("10.0.0.2"),
+		IntfType: objects.INTF_TYPE_POINT2POINT,
+		AreaId:   1,
+	})
+	defer nbrRxHdl.Close()
+
+	tests := []struct {
+		areaType       uint8
+		importASExtern bool
+		options        uint8
+	}{
+		{objects.AREA_TYPE_NORMAL, true, EOption | OOption},
+		{objects.AREA_TYPE_STUB, false, OOption},
+		{objects.AREA_TYPE_TOTALLY_STUB, false, OOption},
+		{objects.AREA_TYPE_NSSA, false, OOption},
+	}
+	for _, test := range tests {
+		areaEnt := server.AreaConfMap[1]
+		areaEnt.AreaType = test.areaType
+		areaEnt.ImportASExtern = test.importASExtern
+		server.AreaConfMap[1] = areaEnt
+		// The nbr options are not echoed back
+		dbdData := NbrDbdData{
+			options:            EOption | NPOption,
+			interface_mtu:      1500,
+			dd_sequence_number: 1,
+			ibit:               true,
+			mbit:               true,
+			msbit:              true,
+		}
+		server.BuildAndSendDdBDPkt(server.NbrConfMap[nbrKey], dbdData)
+		pkt, ok := readTestPacket(t, nbrRxHdl)
+		if !ok {
+			t.Fatal("DBD not sent for area type", test.areaType)
+		}
+		ipLayer := pkt.Layer(layers.LayerTypeIPv4)
+		if ipLayer == nil {
+			t.Fatal("Not an IP packet", pkt)
+		}
+		ospfPkt := ipLayer.(*layers.IPv4).Payload
+		if len(ospfPkt) < OSPF_HEADER_SIZE+OSPF_DBD_MIN_SIZE ||
+			ospfPkt[1] != DBDescriptionType {
+			t.Fatal("Not a DBD", ospfPkt)
+		}
+		var rxDbdData NbrDbdData
+		DecodeDatabaseDescriptionData(ospfPkt[OSPF_HEADER_SIZE:], &rxDbdData,
+			uint16(len(ospfPkt)))
+		if rxDbdData.options != test.options {
+			t.Error("DBD options mismatch for area type", test.areaType,
+				"expected", test.options, "got", rxDbdData.options)
+		}
+	}
+}
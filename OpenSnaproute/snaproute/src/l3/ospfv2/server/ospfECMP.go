@@ -0,0 +1,144 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"ribd"
+	"sort"
+	"strconv"
+)
+
+type NextHopSlice []NextHop
+
+func (n NextHopSlice) Len() int {
+	return len(n)
+}
+
+func (n NextHopSlice) Less(i, j int) bool {
+	if n[i].NextHopIP == n[j].NextHopIP {
+		return n[i].IfIPAddr < n[j].IfIPAddr
+	}
+	return n[i].NextHopIP < n[j].NextHopIP
+}
+
+func (n NextHopSlice) Swap(i, j int) {
+	n[i], n[j] = n[j], n[i]
+}
+
+func (server *OSPFV2Server) updateGlobalMaximumPaths(maxPaths uint8) {
+	if server.globalData.MaximumPaths == maxPaths {
+		return
+	}
+	server.globalData.MaximumPaths = maxPaths
+	server.SendMsgToLsdbForMaximumPathsUpdate()
+}
+
+/*
+Set the next hops of the route entry to the given next hop
+set, advRtr is the ABR/ASBR through which the route is reached
+*/
+func setNextHops(rEnt *RoutingTblEntry, nextHopMap map[NextHop]bool, advRtr uint32) {
+	rEnt.NextHops = make(map[NextHop]bool)
+	mergeNextHops(rEnt, nextHopMap, advRtr)
+}
+
+// Add the next hops of an equal cost path to the route entry
+func mergeNextHops(rEnt *RoutingTblEntry, nextHopMap map[NextHop]bool, advRtr uint32) {
+	if rEnt.NextHops == nil {
+		rEnt.NextHops = make(map[NextHop]bool)
+	}
+	for key, _ := range nextHopMap {
+		if advRtr != 0 {
+			key.AdvRtr = advRtr
+		}
+		rEnt.NextHops[key] = true
+	}
+	rEnt.NumOfPaths = len(rEnt.NextHops)
+}
+
+/*
+Limit the next hops of the route entry to MaximumPaths. The next
+hops with the lowest (NextHopIP, IfIPAddr) are kept so that the
+same set is selected on every SPF run. Next hops which only differ
+in the advertising router are the same path and count once.
+*/
+func (server *OSPFV2Server) limitNextHops(rEnt *RoutingTblEntry) {
+	maxPaths := int(server.globalData.MaximumPaths)
+	nextHops := make(NextHopSlice, 0, len(rEnt.NextHops))
+	for key, _ := range rEnt.NextHops {
+		nextHops = append(nextHops, key)
+	}
+	sort.Sort(nextHops)
+	paths := make(map[NextHop]bool)
+	limited := make(map[NextHop]bool, len(nextHops))
+	for _, key := range nextHops {
+		path := NextHop{
+			IfIPAddr:  key.IfIPAddr,
+			NextHopIP: key.NextHopIP,
+		}
+		if !paths[path] {
+			if maxPaths != 0 && len(paths) == maxPaths {
+				break
+			}
+			paths[path] = true
+		}
+		limited[key] = true
+	}
+	rEnt.NextHops = limited
+	rEnt.NumOfPaths = len(rEnt.NextHops)
+}
+
+/*
+Build the ribd next hop list of the route entry. Next hops which only
+differ in the advertising router are installed once.
+*/
+func (server *OSPFV2Server) getRibdNextHopList(rEnt RoutingTblEntry, withIntf bool) []*ribd.NextHopInfo {
+	nextHops := make(NextHopSlice, 0, len(rEnt.NextHops))
+	for key, _ := range rEnt.NextHops {
+		nextHops = append(nextHops, key)
+	}
+	sort.Sort(nextHops)
+	nextHopList := make([]*ribd.NextHopInfo, 0)
+	installed := make(map[NextHop]bool)
+	for _, key := range nextHops {
+		key.AdvRtr = 0
+		if installed[key] {
+			continue
+		}
+		installed[key] = true
+		nextHopInfo := ribd.NextHopInfo{
+			NextHopIp: convertUint32ToDotNotation(key.NextHopIP),
+		}
+		if withIntf {
+			ifIdx, exist := server.infraData.ipToIfIdxMap[key.IfIPAddr]
+			if !exist {
+				server.logger.Err("Unable to find entry for ip:", key.IfIPAddr, "in ipToIfIdxMap")
+				continue
+			}
+			nextHopInfo.NextHopIntRef = strconv.Itoa(int(ifIdx))
+		}
+		nextHopList = append(nextHopList, &nextHopInfo)
+	}
+	return nextHopList
+}
@@ -0,0 +1,96 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"testing"
+	"utils/logging"
+)
+
+func newTestNextHops() map[NextHop]bool {
+	nextHops := make(map[NextHop]bool)
+	for _, nh := range []struct {
+		nextHopIp string
+		ifIpAddr  string
+		advRtr    string
+	}{
+		{"10.0.0.2", "10.0.0.1", "2.2.2.2"},
+		{"10.0.0.2", "10.0.0.1", "3.3.3.3"},
+		{"10.0.1.2", "10.0.1.1", "2.2.2.2"},
+		{"10.0.2.2", "10.0.2.1", "2.2.2.2"},
+	} {
+		key := NextHop{
+			IfIPAddr:  testIp(nh.ifIpAddr),
+			NextHopIP: testIp(nh.nextHopIp),
+			AdvRtr:    testIp(nh.advRtr),
+		}
+		nextHops[key] = true
+	}
+	return nextHops
+}
+
+func TestLimitNextHops(t *testing.T) {
+	server := &OSPFV2Server{
+		logger: new(logging.Writer),
+	}
+	server.globalData.MaximumPaths = 2
+	rEnt := RoutingTblEntry{
+		NextHops: newTestNextHops(),
+	}
+	server.limitNextHops(&rEnt)
+	// The two next hops via 10.0.0.2 are one path
+	if len(rEnt.NextHops) != 3 || rEnt.NumOfPaths != 3 {
+		t.Fatal("Next hops not limited to two paths", rEnt.NextHops)
+	}
+	for key, _ := range rEnt.NextHops {
+		if key.NextHopIP == testIp("10.0.2.2") {
+			t.Error("Highest next hop not removed", rEnt.NextHops)
+		}
+	}
+	nextHopList := server.getRibdNextHopList(rEnt, false)
+	if len(nextHopList) != 2 ||
+		nextHopList[0].NextHopIp != "10.0.0.2" ||
+		nextHopList[1].NextHopIp != "10.0.1.2" {
+		t.Error("Ribd next hops mismatch", nextHopList)
+	}
+
+	server.globalData.MaximumPaths = 1
+	server.limitNextHops(&rEnt)
+	if len(rEnt.NextHops) != 2 {
+		t.Error("Next hops not limited to one path", rEnt.NextHops)
+	}
+	for key, _ := range rEnt.NextHops {
+		if key.NextHopIP != testIp("10.0.0.2") {
+			t.Error("Lowest next hop not kept", rEnt.NextHops)
+		}
+	}
+
+	// No limit
+	server.globalData.MaximumPaths = 0
+	rEnt.NextHops = newTestNextHops()
+	server.limitNextHops(&rEnt)
+	if len(rEnt.NextHops) != 4 || rEnt.NumOfPaths != 4 {
+		t.Error("Next hops limited without maximum paths", rEnt.NextHops)
+	}
+}
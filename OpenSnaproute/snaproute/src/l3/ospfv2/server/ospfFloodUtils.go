@@ -46,6 +46,10 @@ func (server *OSPFV2Server) lsaUpdDiscardCheck(nbrConf NbrConf, data []byte) boo
 		server.logger.Info(fmt.Sprintln("LSAUPD: Discard .. Nbrstate (expected less than exchange)", nbrConf.State))
 		return true
 	}
+	if len(data) < 4 {
+		server.logger.Info(fmt.Sprintln("LSAUPD: Discard .. No LSA count in pkt, len ", len(data)))
+		return true
+	}
 
 	return false
 }
@@ -158,7 +162,10 @@ func (server *OSPFV2Server) sanityCheckSummaryLsa(slsa SummaryLsa, dslsa Summary
 func (server *OSPFV2Server) sanityCheckASExternalLsa(alsa ASExternalLsa, dalsa ASExternalLsa, nbr NbrConf, intf IntfConf, exist bool, lsa_max_age bool) (discard bool, op uint8) {
 	discard = false
 	op = LsdbAdd
-	// TODO Reject this lsa if area is configured as stub area.
+	if !server.isExternalLsaAllowed(intf.AreaId, ASExternalLSA) {
+		server.logger.Info(fmt.Sprintln("LSAUPD: As external LSA Discard. Area is stub ", intf.AreaId, " nbr ", nbr))
+		return true, LsdbNoAction
+	}
 	send_ack := server.lsAgeCheck(nbr.IntfKey, lsa_max_age, exist)
 	if send_ack {
 		op = LsdbNoAction
@@ -178,6 +185,32 @@ func (server *OSPFV2Server) sanityCheckASExternalLsa(alsa ASExternalLsa, dalsa A
 	return discard, op
 }
 
+func (server *OSPFV2Server) sanityCheckNssaLsa(alsa ASExternalLsa, dalsa ASExternalLsa, nbr NbrConf, intf IntfConf, exist bool, lsa_max_age bool) (discard bool, op uint8) {
+	discard = false
+	op = LsdbAdd
+	if !server.isExternalLsaAllowed(intf.AreaId, NSSALSA) {
+		server.logger.Info(fmt.Sprintln("LSAUPD: NSSA LSA Discard. Area is not NSSA ", intf.AreaId, " nbr ", nbr))
+		return true, LsdbNoAction
+	}
+	send_ack := server.lsAgeCheck(nbr.IntfKey, lsa_max_age, exist)
+	if send_ack {
+		op = LsdbNoAction
+		discard = true
+		server.logger.Info(fmt.Sprintln("LSAUPD: NSSA LSA Discard.", " nbr ", nbr))
+		return discard, op
+	} else {
+		isNew := server.validateLsaIsNew(alsa.LsaMd, dalsa.LsaMd)
+		if isNew {
+			op = FloodLsa
+			discard = false
+		} else {
+			discard = true
+			op = LsdbNoAction
+		}
+	}
+	return discard, op
+}
+
 func validateChecksum(data []byte) bool {
 	csum := computeFletcherChecksum(data[2:], FLETCHER_CHECKSUM_VALIDATE)
 	if csum != 0 {
@@ -242,6 +275,23 @@ func (server *OSPFV2Server) GetLsaByteFromLsaKey(areaId uint32, lsaKey LsaKey) [
 		lsaByte = encodeASExternalLsa(alsa, lsaKey)
 		break
 
+	case NSSALSA:
+		alsa, valid := server.getNssaLsaFromLsdb(areaId, lsaKey)
+		if valid == LsdbEntryNotFound {
+			return nil
+		}
+		lsaByte = encodeASExternalLsa(alsa, lsaKey)
+		break
+
+	case OpaqueAreaLSA, OpaqueASLSA:
+		// Link local opaque LSAs can not be looked up without the link
+		olsa, valid := server.getOpaqueLsaFromLsdb(areaId, IntfConfKey{}, lsaKey)
+		if valid == LsdbEntryNotFound {
+			return nil
+		}
+		lsaByte = encodeOpaqueLsa(olsa, lsaKey)
+		break
+
 	default:
 		server.logger.Debug("Flood: Invalid lsa type . ", lsaKey)
 		return nil
@@ -274,6 +324,16 @@ func (server *OSPFV2Server) getLsaByteFromLsa(msg LsdbToFloodLSAMsg) []byte {
 			server.logger.Debug("Flood: Retrieved as external  lsa  from lsdb")
 			lsaByte = encodeASExternalLsa(lsa, msg.LsaKey)
 		}
+	case NSSALSA:
+		if lsa, ok := msg.LsaData.(ASExternalLsa); ok {
+			server.logger.Debug("Flood: Retrieved nssa lsa from lsdb")
+			lsaByte = encodeASExternalLsa(lsa, msg.LsaKey)
+		}
+	case OpaqueLinkLSA, OpaqueAreaLSA, OpaqueASLSA:
+		if lsa, ok := msg.LsaData.(OpaqueLsa); ok {
+			server.logger.Debug("Flood: Retrieved opaque lsa from lsdb")
+			lsaByte = encodeOpaqueLsa(lsa, msg.LsaKey)
+		}
 	default:
 		server.logger.Err("Flood: Invalid LSA type . Not able to decode message from lsdb ", msg.LsaKey)
 	}
@@ -310,6 +370,14 @@ func (server *OSPFV2Server) GetDestIpForFlood(intfKey IntfConfKey, nbrIP uint32,
 	} else if intf.Type == objects.INTF_TYPE_POINT2POINT {
 		destIp = net.ParseIP(AllSPFRouters)
 		destMac, _ = net.ParseMAC(ALLSPFROUTERMAC)
+	} else if intf.Type == objects.INTF_TYPE_NBMA ||
+		intf.Type == objects.INTF_TYPE_POINT2MULTIPOINT {
+		// No multicast, LSAs are unicast to each adjacent nbr
+		destIp = net.ParseIP(convertUint32ToDotNotation(nbrIP))
+		destMac = nbrMac
+	} else if intf.Type == objects.INTF_TYPE_VIRTUAL {
+		destIp = net.ParseIP(convertUint32ToDotNotation(intf.VirtNbrIpAddr))
+		destMac = nbrMac
 	}
 
 	return destIp, destMac, nil
@@ -26,6 +26,7 @@ package server
 import (
 	"encoding/binary"
 	"fmt"
+	"l3/ospfv2/objects"
 	"net"
 	"time"
 )
@@ -124,7 +125,22 @@ func (server *OSPFV2Server) ProcessNbrFullFlood(nbrKey NbrConfKey) {
 		server.logger.Debug("Flood: Self orig lsa do not exist . ", lsdbKey)
 		return
 	}
+	var opaqueLinkLsaList [][]byte
+	if isNbrOpaqueCapable(nbrConf) {
+		lsdbEnt, _ := server.LsdbData.AreaLsdb[lsdbKey]
+		for linkLsaKey, lsaEnt := range lsdbEnt.OpaqueLinkLsaMap {
+			if linkLsaKey.IntfKey == nbrConf.IntfKey &&
+				linkLsaKey.LsaKey.AdvRouter == server.globalData.RouterId {
+				opaqueLinkLsaList = append(opaqueLinkLsaList, encodeOpaqueLsa(lsaEnt, linkLsaKey.LsaKey))
+			}
+		}
+	}
 	for lsaKey, _ := range selfOrigEnt {
+		if isOpaqueLsa(lsaKey.LSType) &&
+			(!isNbrOpaqueCapable(nbrConf) ||
+				!server.isOpaqueLsaFloodAllowed(lsaKey.LSType, IntfConfKey{}, nbrConf.IntfKey, intf)) {
+			continue
+		}
 		lsa_data := server.GetLsaByteFromLsaKey(lsdbKey.AreaId, lsaKey)
 		if lsa_data != nil {
 			var lsaEncPkt []byte
@@ -145,22 +161,49 @@ func (server *OSPFV2Server) ProcessNbrFullFlood(nbrKey NbrConfKey) {
 			pkt := server.BuildLsaUpdPkt(nbrConf.IntfKey, intf,
 				destMac, destIp, lsa_pkt_len, lsaEncPkt)
 			server.SendOspfPkt(nbrConf.IntfKey, pkt)
+			server.addLsaToRetxList(nbrKey, lsa_data)
 
 		}
 	}
+	for _, lsa_data := range opaqueLinkLsaList {
+		lsaEncPkt := make([]byte, 4)
+		binary.BigEndian.PutUint32(lsaEncPkt, 1)
+		lsaEncPkt = append(lsaEncPkt, lsa_data...)
+		destIp, destMac, err := server.GetDestIpForFlood(nbrConf.IntfKey, nbrConf.NbrIP, nbrConf.NbrMac)
+		if err != nil {
+			server.logger.Err("Flood: Failed to get dest ip and dest mac ", nbrConf.NbrIP)
+			return
+		}
+		pkt := server.BuildLsaUpdPkt(nbrConf.IntfKey, intf,
+			destMac, destIp, len(lsaEncPkt), lsaEncPkt)
+		server.SendOspfPkt(nbrConf.IntfKey, pkt)
+		server.addLsaToRetxList(nbrKey, lsa_data)
+	}
 }
 
 /* Flood incoming LSA to the appropriate interfaces. */
-func (server OSPFV2Server) ProcessLsaFloodAll(nbrKey NbrConfKey, lsaType uint8, lsa_pkt []byte) {
+func (server *OSPFV2Server) ProcessLsaFloodAll(nbrKey NbrConfKey, lsaType uint8, lsa_pkt []byte) {
 	nbrConf := server.NbrConfMap[nbrKey]
 	rxIntf := server.IntfConfMap[nbrConf.IntfKey]
 	var lsaEncPkt []byte
+	if lsaType == OpaqueLinkLSA {
+		return // link local scope, never flooded beyond the rx link
+	}
 	for key, intf := range server.IntfConfMap {
 		areaid := intf.AreaId
 		if intf.IpAddr == rxIntf.IpAddr || areaid != rxIntf.AreaId {
 			server.logger.Info("LSA_FLOOD_ALL:Dont flood on rx intf ", rxIntf.IpAddr)
 			continue // dont flood the LSA on the interface it is received.
 		}
+		if lsaType == ASExternalLSA &&
+			intf.Type == objects.INTF_TYPE_VIRTUAL {
+			continue // AS external LSAs are not flooded over virtual links
+		}
+		if isOpaqueLsa(lsaType) &&
+			(!server.isOpaqueLsaFloodAllowed(lsaType, IntfConfKey{}, key, intf) ||
+				!server.isIntfOpaqueCapable(key)) {
+			continue // Rfc 5250: only flooded to opaque capable nbrs
+		}
 		send := server.nbrFloodCheck(nbrKey, key, intf, lsaType)
 		if send {
 			if lsa_pkt != nil {
@@ -182,6 +225,7 @@ func (server OSPFV2Server) ProcessLsaFloodAll(nbrKey NbrConfKey, lsaType uint8,
 				pkt := server.BuildLsaUpdPkt(key, intf,
 					destMac, destIp, lsa_pkt_len, lsaEncPkt)
 				server.SendOspfPkt(key, pkt)
+				server.addLsaToIntfRetxLists(key, lsa_pkt)
 			}
 		}
 	}
@@ -237,6 +281,12 @@ func (server *OSPFV2Server) ProcessLsdbToFloodMsg(msg LsdbToFloodLSAMsg) {
 			server.logger.Info(fmt.Sprintln("LSA_FLOOD_SELF:Area id diff so not flooding on  ", intf.IpAddr))
 			continue // dont flood the LSA on the interface it is received.
 		}
+		if isOpaqueLsa(msg.LsaKey.LSType) {
+			olsa, _ := msg.LsaData.(OpaqueLsa)
+			if !server.isOpaqueLsaFloodAllowed(msg.LsaKey.LSType, olsa.IntfKey, key, intf) {
+				continue
+			}
+		}
 		nbrs, valid := server.NbrConfData.IntfToNbrMap[key]
 		if !valid {
 			server.logger.Debug("Flood: No nbrs exist for intf . No flood ", key)
@@ -264,6 +314,7 @@ func (server *OSPFV2Server) ProcessLsdbToFloodMsg(msg LsdbToFloodLSAMsg) {
 					pkt := server.BuildLsaUpdPkt(key, intf,
 						dstMac, dstIp, lsa_pkt_len, lsaEncPkt)
 					server.SendOspfPkt(key, pkt)
+					server.addLsaToRetxList(nbrKey, lsa_data)
 				}
 			} // end of send packet
 		} // end of nbrs / intf
@@ -336,6 +387,9 @@ func (server *OSPFV2Server) selfGenLsaFloodCheck(nbrKey NbrConfKey, key IntfConf
 		server.logger.Info("Flood : no nbr exist. Dont flood on this interface ", key)
 		return false
 	}
+	if isOpaqueLsa(lsType) && !isNbrOpaqueCapable(nbrConf) {
+		return false
+	}
 	if nbrConf.State >= NbrExchange && nbrConf.IntfKey == key {
 		flood_check = true
 	}
@@ -437,6 +491,7 @@ func (server *OSPFV2Server) floodSummaryLsa(pkt []byte, areaid uint32) {
 				send_pkt := server.BuildLsaUpdPkt(key, intf, dstMac, dstIp, len(pkt), pkt)
 				server.logger.Info(fmt.Sprintln("SUMMARY: Send  LSA to interface ", intf.IpAddr, " area ", intf.AreaId))
 				server.SendOspfPkt(key, send_pkt)
+				server.addLsaToIntfRetxLists(key, pkt[OSPF_NO_OF_LSA_FIELD:])
 			}
 
 		}
@@ -494,11 +549,15 @@ func (server *OSPFV2Server) floodASExternalLsa(pkt []byte) {
 			server.logger.Info(fmt.Sprintln("ASBR: Dont flood AS external as area is stub ", intf.AreaId))
 			continue
 		}
+		if intf.Type == objects.INTF_TYPE_VIRTUAL {
+			continue
+		}
 		nbrMdata, ok := server.NbrConfData.IntfToNbrMap[key]
 		if ok && len(nbrMdata) > 0 {
 			send_pkt := server.BuildLsaUpdPkt(key, intf, dstMac, dstIp, len(pkt), pkt)
 			server.logger.Info(fmt.Sprintln("ASBR: Send  LSA to interface ", intf.IpAddr))
 			server.SendOspfPkt(key, send_pkt)
+			server.addLsaToIntfRetxLists(key, pkt[OSPF_NO_OF_LSA_FIELD:])
 		}
 	}
 }
@@ -29,12 +29,26 @@ import (
 )
 
 type GlobalStruct struct {
-	Vrf                string
-	RouterId           uint32
-	AdminState         bool
-	ASBdrRtrStatus     bool
-	ReferenceBandwidth uint32
-	AreaBdrRtrStatus   bool
+	Vrf                      string
+	RouterId                 uint32
+	AdminState               bool
+	ASBdrRtrStatus           bool
+	ReferenceBandwidth       uint32
+	AreaBdrRtrStatus         bool
+	RestartSupport           uint8
+	RestartInterval          uint32
+	RestartHelperSupport     bool
+	RestartStrictLsaChecking bool
+	MaximumPaths             uint8
+	SpfInitialWait           uint32
+	SpfHoldWait              uint32
+	SpfMaxWait               uint32
+	PassiveIntfDefault       bool
+	StubRouterAdmin          bool
+	StubRouterOnStartup      uint32
+	StubRouterWaitForBgp     bool
+	StubRouterSummaryLsa     bool
+	StubRouterExternalLsa    bool
 	//isABR             bool
 }
 
@@ -45,7 +59,17 @@ func genOspfv2GlobalUpdateMask(attrset []bool) uint32 {
 		mask = objects.OSPFV2_GLOBAL_UPDATE_ROUTER_ID |
 			objects.OSPFV2_GLOBAL_UPDATE_ADMIN_STATE |
 			objects.OSPFV2_GLOBAL_UPDATE_AS_BDR_RTR_STATUS |
-			objects.OSPFV2_GLOBAL_UPDATE_REFERENCE_BANDWIDTH
+			objects.OSPFV2_GLOBAL_UPDATE_REFERENCE_BANDWIDTH |
+			objects.OSPFV2_GLOBAL_UPDATE_RESTART_SUPPORT |
+			objects.OSPFV2_GLOBAL_UPDATE_RESTART_INTERVAL |
+			objects.OSPFV2_GLOBAL_UPDATE_RESTART_HELPER |
+			objects.OSPFV2_GLOBAL_UPDATE_RESTART_STRICT_LSA |
+			objects.OSPFV2_GLOBAL_UPDATE_MAXIMUM_PATHS |
+			objects.OSPFV2_GLOBAL_UPDATE_SPF_INITIAL_WAIT |
+			objects.OSPFV2_GLOBAL_UPDATE_SPF_HOLD_WAIT |
+			objects.OSPFV2_GLOBAL_UPDATE_SPF_MAX_WAIT |
+			objects.OSPFV2_GLOBAL_UPDATE_PASSIVE_DEFAULT |
+			ospfv2GlobalStubRouterMask
 	} else {
 		for idx, val := range attrset {
 			if true == val {
@@ -60,6 +84,34 @@ func genOspfv2GlobalUpdateMask(attrset []bool) uint32 {
 					mask |= objects.OSPFV2_GLOBAL_UPDATE_AS_BDR_RTR_STATUS
 				case 4:
 					mask |= objects.OSPFV2_GLOBAL_UPDATE_REFERENCE_BANDWIDTH
+				case 5:
+					mask |= objects.OSPFV2_GLOBAL_UPDATE_RESTART_SUPPORT
+				case 6:
+					mask |= objects.OSPFV2_GLOBAL_UPDATE_RESTART_INTERVAL
+				case 7:
+					mask |= objects.OSPFV2_GLOBAL_UPDATE_RESTART_HELPER
+				case 8:
+					mask |= objects.OSPFV2_GLOBAL_UPDATE_RESTART_STRICT_LSA
+				case 9:
+					mask |= objects.OSPFV2_GLOBAL_UPDATE_MAXIMUM_PATHS
+				case 10:
+					mask |= objects.OSPFV2_GLOBAL_UPDATE_SPF_INITIAL_WAIT
+				case 11:
+					mask |= objects.OSPFV2_GLOBAL_UPDATE_SPF_HOLD_WAIT
+				case 12:
+					mask |= objects.OSPFV2_GLOBAL_UPDATE_SPF_MAX_WAIT
+				case 13:
+					mask |= objects.OSPFV2_GLOBAL_UPDATE_PASSIVE_DEFAULT
+				case 14:
+					mask |= objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_ADMIN
+				case 15:
+					mask |= objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_STARTUP
+				case 16:
+					mask |= objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_BGP
+				case 17:
+					mask |= objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_SUMMARY
+				case 18:
+					mask |= objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_EXT
 				}
 			}
 		}
@@ -69,6 +121,29 @@ func genOspfv2GlobalUpdateMask(attrset []bool) uint32 {
 
 func (server *OSPFV2Server) updateGlobal(newCfg, oldCfg *objects.Ospfv2Global, attrset []bool) (bool, error) {
 	server.logger.Info("Global configuration update")
+	mask := genOspfv2GlobalUpdateMask(attrset)
+	server.updateGlobalGracefulRestart(newCfg, mask)
+	if mask&objects.OSPFV2_GLOBAL_UPDATE_MAXIMUM_PATHS == objects.OSPFV2_GLOBAL_UPDATE_MAXIMUM_PATHS {
+		server.updateGlobalMaximumPaths(newCfg.MaximumPaths)
+	}
+	if mask&ospfv2GlobalSpfThrottleMask != 0 {
+		server.updateGlobalSpfThrottle(newCfg, mask)
+	}
+	if mask&objects.OSPFV2_GLOBAL_UPDATE_PASSIVE_DEFAULT == objects.OSPFV2_GLOBAL_UPDATE_PASSIVE_DEFAULT {
+		server.updateGlobalPassiveIntfDefault(newCfg.PassiveIntfDefault)
+	}
+	if mask&ospfv2GlobalStubRouterMask != 0 {
+		server.updateGlobalStubRouter(newCfg, mask)
+		server.updateStubRouterState()
+	}
+	if mask&^(ospfv2GlobalGracefulRestartMask|objects.OSPFV2_GLOBAL_UPDATE_MAXIMUM_PATHS|
+		ospfv2GlobalSpfThrottleMask|objects.OSPFV2_GLOBAL_UPDATE_PASSIVE_DEFAULT|
+		ospfv2GlobalStubRouterMask) == 0 {
+		// Graceful restart parameters, maximum paths, SPF throttle
+		// timers, the passive interface default and the stub router
+		// parameters take effect without restarting the protocol
+		return true, nil
+	}
 	if server.globalData.AdminState == true {
 		server.StopAllIntfFSM()
 		//Stop Rx Pkt
@@ -98,8 +173,12 @@ func (server *OSPFV2Server) updateGlobal(newCfg, oldCfg *objects.Ospfv2Global, a
 		}
 	}
 
-	mask := genOspfv2GlobalUpdateMask(attrset)
 	if mask&objects.OSPFV2_GLOBAL_UPDATE_ADMIN_STATE == objects.OSPFV2_GLOBAL_UPDATE_ADMIN_STATE {
+		if newCfg.AdminState == false {
+			server.stopStubRouterOnStartup()
+		} else if server.globalData.AdminState == false {
+			server.startStubRouterOnStartup()
+		}
 		server.globalData.AdminState = newCfg.AdminState
 	}
 	if mask&objects.OSPFV2_GLOBAL_UPDATE_ROUTER_ID == objects.OSPFV2_GLOBAL_UPDATE_ROUTER_ID {
@@ -156,7 +235,15 @@ func (server *OSPFV2Server) createGlobal(cfg *objects.Ospfv2Global) (bool, error
 	server.globalData.RouterId = cfg.RouterId
 	server.globalData.ASBdrRtrStatus = cfg.ASBdrRtrStatus
 	server.globalData.ReferenceBandwidth = cfg.ReferenceBandwidth
+	server.globalData.MaximumPaths = cfg.MaximumPaths
+	server.globalData.SpfInitialWait = cfg.SpfInitialWait
+	server.globalData.SpfHoldWait = cfg.SpfHoldWait
+	server.globalData.SpfMaxWait = cfg.SpfMaxWait
+	server.globalData.PassiveIntfDefault = cfg.PassiveIntfDefault
+	server.updateGlobalGracefulRestart(cfg, genOspfv2GlobalUpdateMask(nil))
+	server.updateGlobalStubRouter(cfg, genOspfv2GlobalUpdateMask(nil))
 	if server.globalData.AdminState == true {
+		server.startStubRouterOnStartup()
 		err := server.initAsicdForRxMulticastPkt()
 		if err != nil {
 			server.logger.Err("Unable to initialize ASIC for recving Multicast Packets", err)
@@ -203,6 +290,8 @@ func (server *OSPFV2Server) getGlobalState(vrf string) (*objects.Ospfv2GlobalSta
 		retObj.NumOfSummary3LSA + retObj.NumOfSummary4LSA +
 		retObj.NumOfASExternalLSA
 	//TODO: num of routes
+	retObj.RestartStatus, retObj.RestartAge, retObj.RestartExitReason = server.getGracefulRestartState()
+	retObj.StubRouterStatus, retObj.StubRouterTimeLeft = server.getStubRouterState()
 	return &retObj, nil
 }
 
@@ -0,0 +1,694 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"l3/ospfv2/objects"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	GRACE_LSA_OPAQUE_TYPE        uint8  = 3
+	GRACE_LSA_TLV_GRACE_PERIOD   uint16 = 1
+	GRACE_LSA_TLV_RESTART_REASON uint16 = 2
+	GRACE_LSA_TLV_IP_INTF_ADDR   uint16 = 3
+	GRACE_LSA_TLV_HDR_SIZE              = 4
+)
+
+const (
+	GRACE_LSA_REASON_UNKNOWN             uint8 = 0
+	GRACE_LSA_REASON_SW_RESTART          uint8 = 1
+	GRACE_LSA_REASON_SW_RELOAD           uint8 = 2
+	GRACE_LSA_REASON_SWITCH_TO_REDUNDANT uint8 = 3
+)
+
+const (
+	GRACE_LSA_TX_COUNT                     = 3
+	GRACE_LSA_TX_INTERVAL    time.Duration = 500 * time.Millisecond
+	GRACEFUL_RESTART_DB_KEY  string        = "Ospfv2GracefulRestart"
+	GRACEFUL_RESTART_EXPIRY  string        = "GraceExpiry"
+	GRACEFUL_RESTART_NBR_IDS string        = "NbrRtrIds"
+)
+
+const ospfv2GlobalGracefulRestartMask = objects.OSPFV2_GLOBAL_UPDATE_RESTART_SUPPORT |
+	objects.OSPFV2_GLOBAL_UPDATE_RESTART_INTERVAL |
+	objects.OSPFV2_GLOBAL_UPDATE_RESTART_HELPER |
+	objects.OSPFV2_GLOBAL_UPDATE_RESTART_STRICT_LSA
+
+/*
+Grace-LSA (RFC 3623 Appendix A), a link local opaque LSA
+with opaque type 3 carrying the following TLVs
+1 - Grace Period (seconds)
+2 - Graceful restart reason
+3 - IP interface address (broadcast, NBMA and P2MP only)
+*/
+type GraceLsa struct {
+	LsaMd         LsaMetadata
+	GracePeriod   uint32
+	RestartReason uint8
+	IpIntfAddr    uint32
+}
+
+type GracefulRestartStruct struct {
+	sync.RWMutex
+	RestartStatus uint8
+	ExitReason    uint8
+	GraceExpiry   time.Time
+	GraceTimer    *time.Timer
+	// Nbrs which were full before the restart and whether
+	// the adjacency with them has been re-established
+	ResyncNbrMap map[uint32]bool
+
+	PrepareRestartCh     chan bool
+	PrepareRestartDoneCh chan bool
+	RestartExitCh        chan uint8
+
+	// Grace-LSA retransmissions before a planned restart
+	GraceLsaTxCount int
+	GraceLsaTxTimer *time.Timer
+	GraceLsaTxCh    chan bool
+}
+
+func encodeGraceLsa(lsa GraceLsa, lsakey LsaKey) []byte {
+	lsaEnc := make([]byte, lsa.LsaMd.LSLen)
+	lsaHdr := encodeLsaHeader(lsa.LsaMd, lsakey)
+	copy(lsaEnc[0:20], lsaHdr)
+	binary.BigEndian.PutUint16(lsaEnc[20:22], GRACE_LSA_TLV_GRACE_PERIOD)
+	binary.BigEndian.PutUint16(lsaEnc[22:24], 4)
+	binary.BigEndian.PutUint32(lsaEnc[24:28], lsa.GracePeriod)
+	binary.BigEndian.PutUint16(lsaEnc[28:30], GRACE_LSA_TLV_RESTART_REASON)
+	binary.BigEndian.PutUint16(lsaEnc[30:32], 1)
+	lsaEnc[32] = lsa.RestartReason
+	if lsa.IpIntfAddr != 0 {
+		binary.BigEndian.PutUint16(lsaEnc[36:38], GRACE_LSA_TLV_IP_INTF_ADDR)
+		binary.BigEndian.PutUint16(lsaEnc[38:40], 4)
+		binary.BigEndian.PutUint32(lsaEnc[40:44], lsa.IpIntfAddr)
+	}
+	return lsaEnc
+}
+
+func decodeGraceLsa(data []byte, lsa *GraceLsa, lsakey *LsaKey) error {
+	if len(data) < OSPF_LSA_HEADER_SIZE {
+		return errors.New("Invalid Grace LSA length")
+	}
+	lsa.LsaMd.LSAge = binary.BigEndian.Uint16(data[0:2])
+	lsa.LsaMd.Options = uint8(data[2])
+	lsakey.LSType = uint8(data[3])
+	lsakey.LSId = binary.BigEndian.Uint32(data[4:8])
+	lsakey.AdvRouter = binary.BigEndian.Uint32(data[8:12])
+	lsa.LsaMd.LSSequenceNum = int(binary.BigEndian.Uint32(data[12:16]))
+	lsa.LsaMd.LSChecksum = binary.BigEndian.Uint16(data[16:18])
+	lsa.LsaMd.LSLen = binary.BigEndian.Uint16(data[18:20])
+	end := int(lsa.LsaMd.LSLen)
+	if end > len(data) {
+		return errors.New("Truncated Grace LSA")
+	}
+	gracePeriodFound := false
+	idx := OSPF_LSA_HEADER_SIZE
+	for idx+GRACE_LSA_TLV_HDR_SIZE <= end {
+		tlvType := binary.BigEndian.Uint16(data[idx : idx+2])
+		tlvLen := int(binary.BigEndian.Uint16(data[idx+2 : idx+4]))
+		valStart := idx + GRACE_LSA_TLV_HDR_SIZE
+		if valStart+tlvLen > end {
+			return errors.New("Invalid Grace LSA TLV length")
+		}
+		switch tlvType {
+		case GRACE_LSA_TLV_GRACE_PERIOD:
+			if tlvLen == 4 {
+				lsa.GracePeriod = binary.BigEndian.Uint32(data[valStart : valStart+4])
+				gracePeriodFound = true
+			}
+		case GRACE_LSA_TLV_RESTART_REASON:
+			if tlvLen == 1 {
+				lsa.RestartReason = data[valStart]
+			}
+		case GRACE_LSA_TLV_IP_INTF_ADDR:
+			if tlvLen == 4 {
+				lsa.IpIntfAddr = binary.BigEndian.Uint32(data[valStart : valStart+4])
+			}
+		}
+		// TLVs are padded to 4 octet alignment
+		idx = valStart + ((tlvLen+3)/4)*4
+	}
+	if !gracePeriodFound {
+		return errors.New("Grace LSA without Grace Period TLV")
+	}
+	return nil
+}
+
+func (server *OSPFV2Server) initGracefulRestartData() {
+	server.GrData.PrepareRestartCh = make(chan bool)
+	server.GrData.PrepareRestartDoneCh = make(chan bool, 1)
+	server.GrData.RestartExitCh = make(chan uint8, 2)
+	server.GrData.GraceLsaTxCh = make(chan bool, 1)
+}
+
+func (server *OSPFV2Server) updateGlobalGracefulRestart(cfg *objects.Ospfv2Global, mask uint32) {
+	if mask&objects.OSPFV2_GLOBAL_UPDATE_RESTART_SUPPORT == objects.OSPFV2_GLOBAL_UPDATE_RESTART_SUPPORT {
+		server.globalData.RestartSupport = cfg.RestartSupport
+	}
+	if mask&objects.OSPFV2_GLOBAL_UPDATE_RESTART_INTERVAL == objects.OSPFV2_GLOBAL_UPDATE_RESTART_INTERVAL {
+		server.globalData.RestartInterval = cfg.RestartInterval
+	}
+	if mask&objects.OSPFV2_GLOBAL_UPDATE_RESTART_HELPER == objects.OSPFV2_GLOBAL_UPDATE_RESTART_HELPER {
+		server.globalData.RestartHelperSupport = cfg.RestartHelperSupport
+	}
+	if mask&objects.OSPFV2_GLOBAL_UPDATE_RESTART_STRICT_LSA == objects.OSPFV2_GLOBAL_UPDATE_RESTART_STRICT_LSA {
+		server.globalData.RestartStrictLsaChecking = cfg.RestartStrictLsaChecking
+	}
+}
+
+func (server *OSPFV2Server) isGracefulRestartInProgress() bool {
+	server.GrData.RLock()
+	defer server.GrData.RUnlock()
+	return server.GrData.RestartStatus == objects.RESTART_STATUS_PLANNED_RESTART
+}
+
+func (server *OSPFV2Server) getGracefulRestartState() (uint8, uint32, uint8) {
+	server.GrData.RLock()
+	defer server.GrData.RUnlock()
+	var age uint32
+	if server.GrData.RestartStatus == objects.RESTART_STATUS_PLANNED_RESTART {
+		remaining := server.GrData.GraceExpiry.Sub(time.Now())
+		if remaining > 0 {
+			age = uint32(remaining / time.Second)
+		}
+	}
+	return server.GrData.RestartStatus, age, server.GrData.ExitReason
+}
+
+func (server *OSPFV2Server) getNbrRestartResync(nbrRtrId uint32) uint8 {
+	server.GrData.RLock()
+	defer server.GrData.RUnlock()
+	if server.GrData.RestartStatus != objects.RESTART_STATUS_PLANNED_RESTART {
+		return objects.RESTART_RESYNC_NONE
+	}
+	resynced, exist := server.GrData.ResyncNbrMap[nbrRtrId]
+	if !exist {
+		return objects.RESTART_RESYNC_NONE
+	}
+	if resynced {
+		return objects.RESTART_RESYNC_COMPLETED
+	}
+	return objects.RESTART_RESYNC_IN_PROGRESS
+}
+
+func convertDBValToString(val interface{}) (string, error) {
+	switch v := val.(type) {
+	case []byte:
+		return string(v), nil
+	case string:
+		return v, nil
+	}
+	return "", errors.New("Unexpected value type in DB")
+}
+
+func (server *OSPFV2Server) storeGracefulRestartStateInDB(expiry time.Time, nbrList []uint32) error {
+	if server.dbHdl == nil {
+		return errors.New("DB Handle is nil")
+	}
+	var nbrIds []string
+	for _, nbrRtrId := range nbrList {
+		nbrIds = append(nbrIds, strconv.FormatUint(uint64(nbrRtrId), 10))
+	}
+	err := server.dbHdl.StoreValInDb(GRACEFUL_RESTART_DB_KEY, GRACEFUL_RESTART_EXPIRY,
+		strconv.FormatInt(expiry.Unix(), 10))
+	if err != nil {
+		return err
+	}
+	return server.dbHdl.StoreValInDb(GRACEFUL_RESTART_DB_KEY, GRACEFUL_RESTART_NBR_IDS,
+		strings.Join(nbrIds, ","))
+}
+
+func (server *OSPFV2Server) getGracefulRestartStateFromDB() (time.Time, []uint32, error) {
+	var expiry time.Time
+	var nbrList []uint32
+	if server.dbHdl == nil {
+		return expiry, nil, errors.New("DB Handle is nil")
+	}
+	val, err := server.dbHdl.GetValFromDB(GRACEFUL_RESTART_DB_KEY, GRACEFUL_RESTART_EXPIRY)
+	if err != nil || val == nil {
+		return expiry, nil, errors.New("No graceful restart state in DB")
+	}
+	str, err := convertDBValToString(val)
+	if err != nil {
+		return expiry, nil, err
+	}
+	expirySec, err := strconv.ParseInt(str, 10, 64)
+	if err != nil {
+		return expiry, nil, err
+	}
+	expiry = time.Unix(expirySec, 0)
+	val, err = server.dbHdl.GetValFromDB(GRACEFUL_RESTART_DB_KEY, GRACEFUL_RESTART_NBR_IDS)
+	if err != nil || val == nil {
+		return expiry, nil, errors.New("No graceful restart nbrs in DB")
+	}
+	str, err = convertDBValToString(val)
+	if err != nil {
+		return expiry, nil, err
+	}
+	for _, nbrId := range strings.Split(str, ",") {
+		nbrRtrId, err := strconv.ParseUint(nbrId, 10, 32)
+		if err != nil {
+			continue
+		}
+		nbrList = append(nbrList, uint32(nbrRtrId))
+	}
+	return expiry, nbrList, nil
+}
+
+/*
+Called at startup. If the previous instance went down for a planned
+restart, re-enter graceful restart for the remainder of the grace period
+and hold on to the routes it had installed.
+*/
+func (server *OSPFV2Server) initGracefulRestart() {
+	expiry, nbrList, err := server.getGracefulRestartStateFromDB()
+	if err != nil {
+		return
+	}
+	// The state is only valid for this restart
+	server.dbHdl.DeleteValFromDb(GRACEFUL_RESTART_DB_KEY)
+	remaining := expiry.Sub(time.Now())
+	if remaining <= 0 || len(nbrList) == 0 {
+		server.logger.Info("Grace period already expired, not entering graceful restart")
+		server.GrData.ExitReason = objects.RESTART_EXIT_REASON_TIMED_OUT
+		return
+	}
+	server.GrData.Lock()
+	server.GrData.RestartStatus = objects.RESTART_STATUS_PLANNED_RESTART
+	server.GrData.ExitReason = objects.RESTART_EXIT_REASON_IN_PROGRESS
+	server.GrData.GraceExpiry = expiry
+	server.GrData.ResyncNbrMap = make(map[uint32]bool)
+	for _, nbrRtrId := range nbrList {
+		server.GrData.ResyncNbrMap[nbrRtrId] = false
+	}
+	server.GrData.GraceTimer = time.AfterFunc(remaining, func() {
+		server.GrData.RestartExitCh <- objects.RESTART_EXIT_REASON_TIMED_OUT
+	})
+	server.GrData.Unlock()
+	server.RoutingTblData.PreservedRoutingTbl = server.GetAllRoutesFromDB()
+	server.logger.Info("Entered graceful restart, grace period remaining:", remaining,
+		"preserved routes:", len(server.RoutingTblData.PreservedRoutingTbl))
+}
+
+/*
+Called before a planned restart. Returns false if the restart
+can not be graceful, in which case the caller flushes the routes.
+Otherwise true is sent on PrepareRestartDoneCh once the last
+Grace-LSA is out.
+*/
+func (server *OSPFV2Server) prepareGracefulRestart() bool {
+	if server.GrData.GraceLsaTxTimer != nil {
+		server.logger.Err("Already preparing for graceful restart")
+		return false
+	}
+	if server.globalData.AdminState == false ||
+		server.globalData.RestartSupport != objects.RESTART_SUPPORT_PLANNED_ONLY {
+		return false
+	}
+	if server.isGracefulRestartInProgress() {
+		server.logger.Err("Graceful restart already in progress, restart will not be graceful")
+		return false
+	}
+	var nbrList []uint32
+	for _, nbrConf := range server.NbrConfMap {
+		if nbrConf.State == NbrFull {
+			nbrList = append(nbrList, nbrConf.NbrRtrId)
+		}
+	}
+	if len(nbrList) == 0 {
+		server.logger.Info("No full adjacencies, restart will not be graceful")
+		return false
+	}
+	expiry := time.Now().Add(time.Duration(server.globalData.RestartInterval) * time.Second)
+	err := server.storeGracefulRestartStateInDB(expiry, nbrList)
+	if err != nil {
+		server.logger.Err("Unable to store graceful restart state in DB", err)
+		return false
+	}
+	// Grace-LSAs are not retransmitted on missing acks as the
+	// process is going away, send them a few times instead
+	server.sendGraceLsa(0)
+	server.GrData.GraceLsaTxCount = 1
+	server.GrData.GraceLsaTxTimer = time.AfterFunc(GRACE_LSA_TX_INTERVAL, func() {
+		server.GrData.GraceLsaTxCh <- true
+	})
+	return true
+}
+
+func (server *OSPFV2Server) processGraceLsaTxTimer() {
+	if server.GrData.GraceLsaTxTimer == nil {
+		return
+	}
+	server.sendGraceLsa(0)
+	server.GrData.GraceLsaTxCount++
+	if server.GrData.GraceLsaTxCount < GRACE_LSA_TX_COUNT {
+		server.GrData.GraceLsaTxTimer.Reset(GRACE_LSA_TX_INTERVAL)
+		return
+	}
+	server.GrData.GraceLsaTxTimer = nil
+	server.logger.Info("Prepared for graceful restart, grace period:", server.globalData.RestartInterval)
+	server.GrData.PrepareRestartDoneCh <- true
+}
+
+func (server *OSPFV2Server) buildGraceLsa(intf IntfConf, lsAge uint16) []byte {
+	lsaKey := LsaKey{
+		LSType:    OpaqueLinkLSA,
+		LSId:      getOpaqueLSId(GRACE_LSA_OPAQUE_TYPE, 0),
+		AdvRouter: server.globalData.RouterId,
+	}
+	lsa := GraceLsa{
+		GracePeriod:   server.globalData.RestartInterval,
+		RestartReason: GRACE_LSA_REASON_SW_RESTART,
+	}
+	lsa.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + 16)
+	if intf.Type != objects.INTF_TYPE_POINT2POINT &&
+		intf.Type != objects.INTF_TYPE_VIRTUAL {
+		lsa.IpIntfAddr = intf.IpAddr
+		lsa.LsaMd.LSLen = lsa.LsaMd.LSLen + 8
+	}
+	lsa.LsaMd.LSAge = lsAge
+	lsa.LsaMd.Options = server.getAreaLsaOptions(intf.AreaId)
+	lsa.LsaMd.LSSequenceNum = int(InitialSequenceNum)
+	lsaEnc := encodeGraceLsa(lsa, lsaKey)
+	checksumOffset := uint16(14)
+	lsa.LsaMd.LSChecksum = computeFletcherChecksum(lsaEnc[2:], checksumOffset)
+	return encodeGraceLsa(lsa, lsaKey)
+}
+
+/*
+Send the Grace-LSA to every adjacent nbr. An LSAge of MAX_AGE
+flushes the Grace-LSA, ending helper mode on the nbrs.
+*/
+func (server *OSPFV2Server) sendGraceLsa(lsAge uint16) {
+	sentMap := make(map[IntfConfKey]map[string]bool)
+	for _, nbrConf := range server.NbrConfMap {
+		if nbrConf.State < NbrExchange {
+			continue
+		}
+		intf, exist := server.IntfConfMap[nbrConf.IntfKey]
+		if !exist {
+			continue
+		}
+		dstIp, dstMac, err := server.GetDestIpForFlood(nbrConf.IntfKey, nbrConf.NbrIP, nbrConf.NbrMac)
+		if err != nil {
+			server.logger.Err("Unable to get destination for Grace LSA", nbrConf.NbrIP, err)
+			continue
+		}
+		sent, exist := sentMap[nbrConf.IntfKey]
+		if !exist {
+			sent = make(map[string]bool)
+			sentMap[nbrConf.IntfKey] = sent
+		}
+		if sent[dstIp.String()] {
+			continue
+		}
+		sent[dstIp.String()] = true
+		lsaUpdEnc := make([]byte, 4)
+		binary.BigEndian.PutUint32(lsaUpdEnc, 1)
+		lsaUpdEnc = append(lsaUpdEnc, server.buildGraceLsa(intf, lsAge)...)
+		pkt := server.BuildLsaUpdPkt(nbrConf.IntfKey, intf, dstMac, dstIp, len(lsaUpdEnc), lsaUpdEnc)
+		server.SendOspfPkt(nbrConf.IntfKey, pkt)
+	}
+}
+
+func (server *OSPFV2Server) processGracefulRestartNbrFull(nbrRtrId uint32) {
+	server.GrData.Lock()
+	if server.GrData.RestartStatus != objects.RESTART_STATUS_PLANNED_RESTART {
+		server.GrData.Unlock()
+		return
+	}
+	_, exist := server.GrData.ResyncNbrMap[nbrRtrId]
+	if !exist {
+		server.GrData.Unlock()
+		return
+	}
+	server.GrData.ResyncNbrMap[nbrRtrId] = true
+	done := true
+	for _, resynced := range server.GrData.ResyncNbrMap {
+		if !resynced {
+			done = false
+			break
+		}
+	}
+	server.GrData.Unlock()
+	if done {
+		server.GrData.RestartExitCh <- objects.RESTART_EXIT_REASON_COMPLETED
+	}
+}
+
+func (server *OSPFV2Server) exitGracefulRestart(reason uint8) {
+	server.GrData.Lock()
+	if server.GrData.RestartStatus != objects.RESTART_STATUS_PLANNED_RESTART {
+		server.GrData.Unlock()
+		return
+	}
+	server.GrData.RestartStatus = objects.RESTART_STATUS_NOT_RESTARTING
+	server.GrData.ExitReason = reason
+	if server.GrData.GraceTimer != nil {
+		server.GrData.GraceTimer.Stop()
+		server.GrData.GraceTimer = nil
+	}
+	server.GrData.ResyncNbrMap = nil
+	server.GrData.Unlock()
+	server.logger.Info("Exiting graceful restart, reason:", reason)
+	server.sendGraceLsa(MAX_AGE)
+	if server.globalData.AdminState == true {
+		server.MessagingChData.ServerToLsdbChData.GracefulRestartExitCh <- true
+	}
+}
+
+/*
+Graceful restart done, flood the self originated LSAs which
+were held back during the grace period
+*/
+func (server *OSPFV2Server) reoriginateSelfLsa() {
+	for areaId, _ := range server.AreaConfMap {
+		lsdbKey := LsdbKey{
+			AreaId: areaId,
+		}
+		lsdbEnt, exist := server.LsdbData.AreaLsdb[lsdbKey]
+		if !exist {
+			continue
+		}
+		msg := GenerateRouterLSAMsg{
+			AreaId: areaId,
+		}
+		err := server.GenerateRouterLSA(msg)
+		if err != nil {
+			server.logger.Err("Unable to reoriginate router LSA for area", areaId, err)
+		}
+		for lsaKey, _ := range server.LsdbData.AreaSelfOrigLsa[lsdbKey] {
+			var lsa interface{}
+			switch lsaKey.LSType {
+			case NetworkLSA:
+				lsa, exist = lsdbEnt.NetworkLsaMap[lsaKey]
+			case Summary3LSA:
+				lsa, exist = lsdbEnt.Summary3LsaMap[lsaKey]
+			case Summary4LSA:
+				lsa, exist = lsdbEnt.Summary4LsaMap[lsaKey]
+			case ASExternalLSA:
+				lsa, exist = lsdbEnt.ASExternalLsaMap[lsaKey]
+			case NSSALSA:
+				lsa, exist = lsdbEnt.NssaLsaMap[lsaKey]
+			case OpaqueAreaLSA, OpaqueASLSA:
+				lsa, exist = getOpaqueLsaMap(lsdbEnt, lsaKey.LSType)[lsaKey]
+			default:
+				continue
+			}
+			if exist {
+				server.CreateAndSendMsgFromLsdbToFloodLsa(areaId, lsaKey, lsa)
+			}
+		}
+		for linkLsaKey, lsa := range lsdbEnt.OpaqueLinkLsaMap {
+			if linkLsaKey.LsaKey.AdvRouter == server.globalData.RouterId {
+				server.CreateAndSendMsgFromLsdbToFloodLsa(areaId, linkLsaKey.LsaKey, lsa)
+			}
+		}
+	}
+}
+
+func (server *OSPFV2Server) isNbrGracefulRestartHelping(nbrKey NbrConfKey) bool {
+	nbrConf, exist := server.NbrConfMap[nbrKey]
+	if !exist {
+		return false
+	}
+	return nbrConf.GrHelperStatus == objects.RESTART_HELPER_STATUS_HELPING
+}
+
+/*
+Grace-LSA received from nbrKey. Enter helper mode for the
+restarting router, or leave it if the Grace-LSA is flushed.
+*/
+func (server *OSPFV2Server) processRxGraceLsa(nbrKey NbrConfKey, data []byte) {
+	var lsa GraceLsa
+	var lsaKey LsaKey
+	err := decodeGraceLsa(data, &lsa, &lsaKey)
+	if err != nil {
+		server.logger.Err("Invalid Grace LSA from nbr", nbrKey, err)
+		return
+	}
+	if getOpaqueType(lsaKey.LSId) != GRACE_LSA_OPAQUE_TYPE {
+		server.logger.Debug("Ignoring opaque LSA from nbr", nbrKey, lsaKey)
+		return
+	}
+	if lsaKey.AdvRouter == server.globalData.RouterId {
+		return
+	}
+	rxNbrConf, exist := server.NbrConfMap[nbrKey]
+	if !exist {
+		return
+	}
+	var rstNbrKey NbrConfKey
+	found := false
+	for key, nbrConf := range server.NbrConfMap {
+		if nbrConf.IntfKey == rxNbrConf.IntfKey &&
+			nbrConf.NbrRtrId == lsaKey.AdvRouter {
+			rstNbrKey = key
+			found = true
+			break
+		}
+	}
+	if !found {
+		server.logger.Info("Grace LSA from unknown restarting router", convertUint32ToDotNotation(lsaKey.AdvRouter))
+		return
+	}
+	nbrConf := server.NbrConfMap[rstNbrKey]
+	helping := nbrConf.GrHelperStatus == objects.RESTART_HELPER_STATUS_HELPING
+	if lsa.LsaMd.LSAge >= MAX_AGE {
+		if helping {
+			server.exitGracefulRestartHelper(rstNbrKey, objects.RESTART_EXIT_REASON_COMPLETED)
+		}
+		return
+	}
+	// RFC 3623 3.1: Entering helper mode
+	if server.globalData.RestartHelperSupport == false {
+		server.logger.Info("Graceful restart helper disabled, ignoring Grace LSA from", nbrConf.NbrIP)
+		return
+	}
+	if server.isGracefulRestartInProgress() {
+		server.logger.Info("Restarting, can not help nbr", nbrConf.NbrIP)
+		return
+	}
+	if !helping && nbrConf.State != NbrFull {
+		server.logger.Info("Nbr is not full, can not help nbr", nbrConf.NbrIP)
+		return
+	}
+	if uint32(lsa.LsaMd.LSAge) >= lsa.GracePeriod {
+		server.logger.Info("Grace period already expired for nbr", nbrConf.NbrIP)
+		return
+	}
+	if !helping && server.globalData.RestartStrictLsaChecking &&
+		server.getNbrRetxListLen(rstNbrKey) > 0 {
+		server.logger.Info("Pending LSA changes for nbr, can not help nbr", nbrConf.NbrIP)
+		return
+	}
+	remaining := time.Duration(lsa.GracePeriod-uint32(lsa.LsaMd.LSAge)) * time.Second
+	if nbrConf.GrHelperTimer != nil {
+		nbrConf.GrHelperTimer.Stop()
+	}
+	nbrConf.GrHelperStatus = objects.RESTART_HELPER_STATUS_HELPING
+	nbrConf.GrHelperExitReason = objects.RESTART_EXIT_REASON_IN_PROGRESS
+	nbrConf.GrHelperExpiry = time.Now().Add(remaining)
+	nbrConf.GrHelperTimer = time.AfterFunc(remaining, func() {
+		server.NbrConfData.nbrGraceExpiryCh <- rstNbrKey
+	})
+	server.NbrConfMap[rstNbrKey] = nbrConf
+	server.logger.Info("Entered graceful restart helper mode for nbr", nbrConf.NbrIP, "grace period:", remaining)
+}
+
+func (server *OSPFV2Server) exitGracefulRestartHelper(nbrKey NbrConfKey, reason uint8) {
+	nbrConf, exist := server.NbrConfMap[nbrKey]
+	if !exist || nbrConf.GrHelperStatus != objects.RESTART_HELPER_STATUS_HELPING {
+		return
+	}
+	if nbrConf.GrHelperTimer != nil {
+		nbrConf.GrHelperTimer.Stop()
+		nbrConf.GrHelperTimer = nil
+	}
+	nbrConf.GrHelperStatus = objects.RESTART_HELPER_STATUS_NOT_HELPING
+	nbrConf.GrHelperExitReason = reason
+	server.NbrConfMap[nbrKey] = nbrConf
+	server.logger.Info("Exiting graceful restart helper mode for nbr", nbrConf.NbrIP, "reason:", reason)
+}
+
+/*
+RFC 3623 3.2: With strict LSA checking, a change in the content of
+an LSA that would be flooded to a restarting router ends helper mode.
+Refreshes of an LSA and LSAs of the restarting router itself are not
+topology changes.
+*/
+func (server *OSPFV2Server) checkGracefulRestartHelperTopologyChange(areaId uint32, lsaKey LsaKey, lsa []byte) {
+	if server.globalData.RestartStrictLsaChecking == false ||
+		isOpaqueLsa(lsaKey.LSType) {
+		return
+	}
+	helping := false
+	for _, nbrConf := range server.NbrConfMap {
+		if nbrConf.GrHelperStatus == objects.RESTART_HELPER_STATUS_HELPING {
+			helping = true
+			break
+		}
+	}
+	if !helping {
+		return
+	}
+	oldLsa := server.GetLsaByteFromLsaKey(areaId, lsaKey)
+	if oldLsa != nil && len(oldLsa) == len(lsa) &&
+		binary.BigEndian.Uint16(lsa[0:2]) < MAX_AGE &&
+		bytes.Equal(oldLsa[OSPF_LSA_HEADER_SIZE:], lsa[OSPF_LSA_HEADER_SIZE:]) {
+		return
+	}
+	for nbrKey, nbrConf := range server.NbrConfMap {
+		if nbrConf.GrHelperStatus != objects.RESTART_HELPER_STATUS_HELPING ||
+			nbrConf.NbrRtrId == lsaKey.AdvRouter {
+			continue
+		}
+		if lsaKey.LSType != ASExternalLSA {
+			intf, exist := server.IntfConfMap[nbrConf.IntfKey]
+			if !exist || intf.AreaId != areaId {
+				continue
+			}
+		}
+		server.exitGracefulRestartHelper(nbrKey, objects.RESTART_EXIT_REASON_TOPOLOGY_CHANGED)
+	}
+}
+
+func (server *OSPFV2Server) getNbrGracefulRestartHelperAge(nbrConf NbrConf) uint32 {
+	if nbrConf.GrHelperStatus != objects.RESTART_HELPER_STATUS_HELPING {
+		return 0
+	}
+	remaining := nbrConf.GrHelperExpiry.Sub(time.Now())
+	if remaining <= 0 {
+		return 0
+	}
+	return uint32(remaining / time.Second)
+}
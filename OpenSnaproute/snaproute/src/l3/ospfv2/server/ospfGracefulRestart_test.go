@@ -0,0 +1,376 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/google/gopacket/layers"
+	"l3/ospfv2/objects"
+	modelObjs "models/objects"
+	"strconv"
+	"testing"
+	"time"
+	"utils/dbutils"
+)
+
+type testGrDB struct {
+	dbutils.DBIntf
+	vals map[string]interface{}
+}
+
+func newTestGrDB() *testGrDB {
+	return &testGrDB{
+		vals: make(map[string]interface{}),
+	}
+}
+
+func (db *testGrDB) StoreValInDb(key, field, val interface{}) error {
+	db.vals[key.(string)+":"+field.(string)] = val
+	return nil
+}
+
+func (db *testGrDB) GetValFromDB(key, field interface{}) (interface{}, error) {
+	val, exist := db.vals[key.(string)+":"+field.(string)]
+	if !exist {
+		return nil, errors.New("No such key")
+	}
+	return val, nil
+}
+
+func (db *testGrDB) DeleteValFromDb(key interface{}) error {
+	for k, _ := range db.vals {
+		if len(k) > len(key.(string)) && k[:len(key.(string))+1] == key.(string)+":" {
+			delete(db.vals, k)
+		}
+	}
+	return nil
+}
+
+func (db *testGrDB) GetAllObjFromDb(obj modelObjs.ConfigObj) ([]modelObjs.ConfigObj, error) {
+	return nil, nil
+}
+
+func TestGraceLsaEncodeDecode(t *testing.T) {
+	lsaKey := LsaKey{
+		LSType:    OpaqueLinkLSA,
+		LSId:      getOpaqueLSId(GRACE_LSA_OPAQUE_TYPE, 0),
+		AdvRouter: testIp("1.1.1.1"),
+	}
+	lsa := GraceLsa{
+		GracePeriod:   120,
+		RestartReason: GRACE_LSA_REASON_SW_RESTART,
+		IpIntfAddr:    testIp("10.0.0.1"),
+	}
+	lsa.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + 24)
+	lsa.LsaMd.LSSequenceNum = int(InitialSequenceNum)
+
+	var rxLsa GraceLsa
+	var rxKey LsaKey
+	err := decodeGraceLsa(encodeGraceLsa(lsa, lsaKey), &rxLsa, &rxKey)
+	if err != nil {
+		t.Fatal("Unable to decode Grace LSA", err)
+	}
+	if rxKey != lsaKey || rxLsa != lsa {
+		t.Error("Grace LSA mismatch", rxKey, rxLsa)
+	}
+
+	// Point-to-point, no IP interface address TLV
+	lsa.IpIntfAddr = 0
+	lsa.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + 16)
+	rxLsa = GraceLsa{}
+	err = decodeGraceLsa(encodeGraceLsa(lsa, lsaKey), &rxLsa, &rxKey)
+	if err != nil || rxLsa != lsa {
+		t.Error("Grace LSA mismatch without IP interface address", rxLsa, err)
+	}
+
+	data := encodeGraceLsa(lsa, lsaKey)
+	if decodeGraceLsa(data[:OSPF_LSA_HEADER_SIZE+8], &rxLsa, &rxKey) == nil {
+		t.Error("Truncated Grace LSA decoded")
+	}
+	binary.BigEndian.PutUint16(data[20:22], GRACE_LSA_TLV_RESTART_REASON+10)
+	if decodeGraceLsa(data, &rxLsa, &rxKey) == nil {
+		t.Error("Grace LSA without Grace Period TLV decoded")
+	}
+}
+
+func TestGracefulRestartDBState(t *testing.T) {
+	server := &OSPFV2Server{}
+	expiry := time.Unix(time.Now().Unix()+120, 0)
+	nbrList := []uint32{testIp("2.2.2.2"), testIp("3.3.3.3")}
+	if server.storeGracefulRestartStateInDB(expiry, nbrList) == nil {
+		t.Error("Graceful restart state stored without a DB")
+	}
+	server.dbHdl = newTestGrDB()
+	err := server.storeGracefulRestartStateInDB(expiry, nbrList)
+	if err != nil {
+		t.Fatal("Unable to store graceful restart state", err)
+	}
+	rxExpiry, rxNbrList, err := server.getGracefulRestartStateFromDB()
+	if err != nil {
+		t.Fatal("Unable to get graceful restart state", err)
+	}
+	if !rxExpiry.Equal(expiry) || len(rxNbrList) != 2 ||
+		rxNbrList[0] != nbrList[0] || rxNbrList[1] != nbrList[1] {
+		t.Error("Graceful restart state mismatch", rxExpiry, rxNbrList)
+	}
+
+	// Values read back from redis are byte slices
+	server.dbHdl.StoreValInDb(GRACEFUL_RESTART_DB_KEY, GRACEFUL_RESTART_EXPIRY,
+		[]byte(strconv.FormatInt(expiry.Unix(), 10)))
+	rxExpiry, _, err = server.getGracefulRestartStateFromDB()
+	if err != nil || !rxExpiry.Equal(expiry) {
+		t.Error("Unable to get graceful restart state stored as bytes", rxExpiry, err)
+	}
+
+	server.dbHdl.DeleteValFromDb(GRACEFUL_RESTART_DB_KEY)
+	_, _, err = server.getGracefulRestartStateFromDB()
+	if err == nil {
+		t.Error("Graceful restart state found after delete")
+	}
+}
+
+func newTestGracefulRestartServer(t *testing.T) (*OSPFV2Server, PktRxHandle) {
+	server := newTestServer("1.1.1.1")
+	addTestArea(server, 1, AreaConf{ImportASExtern: true})
+	intfKey := addTestIntf(server, 1, "10.0.0.1", objects.INTF_TYPE_POINT2POINT, objects.INTF_FSM_STATE_P2P)
+	server.globalData.AdminState = true
+	server.globalData.RestartSupport = objects.RESTART_SUPPORT_PLANNED_ONLY
+	server.globalData.RestartInterval = 120
+	server.dbHdl = newTestGrDB()
+	server.initGracefulRestartData()
+	server.MessagingChData.ServerToLsdbChData.GracefulRestartExitCh = make(chan bool, 1)
+	addTestP2MPNbr(server, intfKey, "10.0.0.2", "2.2.2.2", NbrFull)
+
+	_, nbrRxHdl := openTestNbrWire(t, server, intfKey, PktIOParams{
+		IpAddr:   testIp("10.0.0.2"),
+		IntfType: objects.INTF_TYPE_POINT2POINT,
+		AreaId:   1,
+	})
+	return server, nbrRxHdl
+}
+
+/*
+Returns the LS age of the Grace-LSA in the next LS Update
+received by the nbr
+*/
+func readTestGraceLsa(t *testing.T, rxHdl PktRxHandle) (uint16, bool) {
+	pkt, ok := readTestPacket(t, rxHdl)
+	if !ok {
+		return 0, false
+	}
+	ipLayer := pkt.Layer(layers.LayerTypeIPv4)
+	if ipLayer == nil {
+		t.Error("Not an IP packet", pkt)
+		return 0, false
+	}
+	ospfPkt := ipLayer.(*layers.IPv4).Payload
+	if len(ospfPkt) < OSPF_HEADER_SIZE+4+OSPF_LSA_HEADER_SIZE ||
+		ospfPkt[1] != LSUpdateType {
+		t.Error("Not an LS Update", ospfPkt)
+		return 0, false
+	}
+	var lsa GraceLsa
+	var lsaKey LsaKey
+	err := decodeGraceLsa(ospfPkt[OSPF_HEADER_SIZE+4:], &lsa, &lsaKey)
+	if err != nil || lsaKey.LSType != OpaqueLinkLSA ||
+		getOpaqueType(lsaKey.LSId) != GRACE_LSA_OPAQUE_TYPE {
+		t.Error("Not a Grace LSA", lsaKey, err)
+		return 0, false
+	}
+	if lsa.GracePeriod != 120 || lsa.RestartReason != GRACE_LSA_REASON_SW_RESTART {
+		t.Error("Grace LSA content mismatch", lsa)
+	}
+	return lsa.LsaMd.LSAge, true
+}
+
+func TestPrepareGracefulRestart(t *testing.T) {
+	server, nbrRxHdl := newTestGracefulRestartServer(t)
+	defer nbrRxHdl.Close()
+
+	server.globalData.RestartSupport = objects.RESTART_SUPPORT_NONE
+	if server.prepareGracefulRestart() {
+		t.Error("Prepared for graceful restart without restart support")
+	}
+	server.globalData.RestartSupport = objects.RESTART_SUPPORT_PLANNED_ONLY
+	for key, nbrConf := range server.NbrConfMap {
+		nbrConf.State = NbrExchange
+		server.NbrConfMap[key] = nbrConf
+	}
+	if server.prepareGracefulRestart() {
+		t.Error("Prepared for graceful restart without full nbrs")
+	}
+	for key, nbrConf := range server.NbrConfMap {
+		nbrConf.State = NbrFull
+		server.NbrConfMap[key] = nbrConf
+	}
+	if _, ok := readTestFrame(nbrRxHdl); ok {
+		t.Error("Grace LSA sent for a non graceful restart")
+	}
+
+	if !server.prepareGracefulRestart() {
+		t.Fatal("Unable to prepare for graceful restart")
+	}
+	if server.prepareGracefulRestart() {
+		t.Error("Prepared for graceful restart twice")
+	}
+	_, nbrList, err := server.getGracefulRestartStateFromDB()
+	if err != nil || len(nbrList) != 1 || nbrList[0] != testIp("2.2.2.2") {
+		t.Error("Graceful restart state not stored", nbrList, err)
+	}
+	for count := 1; count <= GRACE_LSA_TX_COUNT; count++ {
+		lsAge, ok := readTestGraceLsa(t, nbrRxHdl)
+		if !ok {
+			t.Fatal("Grace LSA", count, "not sent")
+		}
+		if lsAge != 0 {
+			t.Error("Grace LSA sent with age", lsAge)
+		}
+		if count == GRACE_LSA_TX_COUNT {
+			break
+		}
+		if len(server.GrData.PrepareRestartDoneCh) != 0 {
+			t.Fatal("Restart prepared before the last Grace LSA")
+		}
+		select {
+		case <-server.GrData.GraceLsaTxCh:
+			server.processGraceLsaTxTimer()
+		case <-time.After(10 * GRACE_LSA_TX_INTERVAL):
+			t.Fatal("Grace LSA retransmission timer did not fire")
+		}
+	}
+	if len(server.GrData.PrepareRestartDoneCh) != 1 || !<-server.GrData.PrepareRestartDoneCh {
+		t.Error("Restart not prepared after the last Grace LSA")
+	}
+	if server.GrData.GraceLsaTxTimer != nil {
+		t.Error("Grace LSA retransmission timer still running")
+	}
+}
+
+func TestGracefulRestartResyncExit(t *testing.T) {
+	server, nbrRxHdl := newTestGracefulRestartServer(t)
+	defer nbrRxHdl.Close()
+	expiry := time.Now().Add(time.Minute)
+	server.storeGracefulRestartStateInDB(expiry,
+		[]uint32{testIp("2.2.2.2"), testIp("3.3.3.3")})
+
+	server.initGracefulRestart()
+	if !server.isGracefulRestartInProgress() {
+		t.Fatal("Graceful restart not entered from the DB state")
+	}
+	if _, _, err := server.getGracefulRestartStateFromDB(); err == nil {
+		t.Error("Graceful restart state left in DB")
+	}
+	if server.getNbrRestartResync(testIp("2.2.2.2")) != objects.RESTART_RESYNC_IN_PROGRESS {
+		t.Error("Nbr resync not in progress")
+	}
+
+	server.processGracefulRestartNbrFull(testIp("2.2.2.2"))
+	if server.getNbrRestartResync(testIp("2.2.2.2")) != objects.RESTART_RESYNC_COMPLETED {
+		t.Error("Nbr resync not completed")
+	}
+	if len(server.GrData.RestartExitCh) != 0 {
+		t.Error("Graceful restart exited before all nbrs resynced")
+	}
+	server.processGracefulRestartNbrFull(testIp("3.3.3.3"))
+	if len(server.GrData.RestartExitCh) != 1 {
+		t.Fatal("Graceful restart not exited after all nbrs resynced")
+	}
+
+	server.exitGracefulRestart(<-server.GrData.RestartExitCh)
+	status, _, reason := server.getGracefulRestartState()
+	if status != objects.RESTART_STATUS_NOT_RESTARTING ||
+		reason != objects.RESTART_EXIT_REASON_COMPLETED {
+		t.Error("Graceful restart state mismatch after exit", status, reason)
+	}
+	if len(server.MessagingChData.ServerToLsdbChData.GracefulRestartExitCh) != 1 {
+		t.Error("Lsdb not told to reoriginate self LSAs")
+	}
+	lsAge, ok := readTestGraceLsa(t, nbrRxHdl)
+	if !ok || lsAge != MAX_AGE {
+		t.Error("Grace LSA not flushed on exit", lsAge)
+	}
+}
+
+func TestGracefulRestartExpiredDBState(t *testing.T) {
+	server, nbrRxHdl := newTestGracefulRestartServer(t)
+	defer nbrRxHdl.Close()
+	server.storeGracefulRestartStateInDB(time.Now().Add(-time.Minute),
+		[]uint32{testIp("2.2.2.2")})
+
+	server.initGracefulRestart()
+	if server.isGracefulRestartInProgress() {
+		t.Error("Graceful restart entered after the grace period")
+	}
+	_, _, reason := server.getGracefulRestartState()
+	if reason != objects.RESTART_EXIT_REASON_TIMED_OUT {
+		t.Error("Exit reason mismatch", reason)
+	}
+}
+
+func TestGracefulRestartHelper(t *testing.T) {
+	server, nbrRxHdl := newTestGracefulRestartServer(t)
+	nbrRxHdl.Close()
+	server.globalData.RestartHelperSupport = true
+	nbrKey := NbrConfKey{
+		NbrIdentity: testIp("10.0.0.2"),
+	}
+	lsaKey := LsaKey{
+		LSType:    OpaqueLinkLSA,
+		LSId:      getOpaqueLSId(GRACE_LSA_OPAQUE_TYPE, 0),
+		AdvRouter: testIp("2.2.2.2"),
+	}
+	lsa := GraceLsa{
+		GracePeriod:   120,
+		RestartReason: GRACE_LSA_REASON_SW_RESTART,
+	}
+	lsa.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + 16)
+
+	server.processRxGraceLsa(nbrKey, encodeGraceLsa(lsa, lsaKey))
+	nbrConf := server.NbrConfMap[nbrKey]
+	if !server.isNbrGracefulRestartHelping(nbrKey) {
+		t.Fatal("Helper mode not entered for nbr")
+	}
+	if nbrConf.GrHelperExpiry.Before(time.Now().Add(110 * time.Second)) {
+		t.Error("Helper grace period mismatch", nbrConf.GrHelperExpiry)
+	}
+
+	lsa.LsaMd.LSAge = MAX_AGE
+	server.processRxGraceLsa(nbrKey, encodeGraceLsa(lsa, lsaKey))
+	nbrConf = server.NbrConfMap[nbrKey]
+	if server.isNbrGracefulRestartHelping(nbrKey) ||
+		nbrConf.GrHelperExitReason != objects.RESTART_EXIT_REASON_COMPLETED {
+		t.Error("Helper mode not exited on flushed Grace LSA", nbrConf.GrHelperStatus)
+	}
+	if nbrConf.GrHelperTimer != nil {
+		t.Error("Helper timer still running")
+	}
+
+	server.globalData.RestartHelperSupport = false
+	lsa.LsaMd.LSAge = 0
+	server.processRxGraceLsa(nbrKey, encodeGraceLsa(lsa, lsaKey))
+	if server.isNbrGracefulRestartHelping(nbrKey) {
+		t.Error("Helper mode entered with helper support disabled")
+	}
+}
@@ -26,7 +26,6 @@ package server
 import (
 	//"fmt"
 	//    "bytes"
-	"encoding/binary"
 	"errors"
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
@@ -36,7 +35,15 @@ import (
 )
 
 func (server *OSPFV2Server) SendHelloPkt(key IntfConfKey) {
-	ospfHelloPkt := server.BuildHelloPkt(key)
+	ent, _ := server.IntfConfMap[key]
+	if ent.Type == objects.INTF_TYPE_NBMA ||
+		ent.Type == objects.INTF_TYPE_POINT2MULTIPOINT {
+		server.sendStaticNbrHelloPkts(key)
+		return
+	}
+	dstIp := net.IP{224, 0, 0, 5} //ALLSPFROUTER
+	dstMac := net.HardwareAddr{0x01, 0x00, 0x5e, 0x00, 0x00, 0x05}
+	ospfHelloPkt := server.BuildHelloPkt(key, dstIp, dstMac)
 	if ospfHelloPkt == nil {
 		server.logger.Err("Unable to send the ospf Hello pkt")
 		return
@@ -48,7 +55,7 @@ func (server *OSPFV2Server) SendHelloPkt(key IntfConfKey) {
 	return
 }
 
-func (server *OSPFV2Server) BuildHelloPkt(key IntfConfKey) []byte {
+func (server *OSPFV2Server) BuildHelloPkt(key IntfConfKey, dstIp net.IP, dstMac net.HardwareAddr) []byte {
 	ent, exist := server.IntfConfMap[key]
 	if !exist {
 		server.logger.Err("Interface doesnot exist", key)
@@ -77,6 +84,10 @@ func (server *OSPFV2Server) BuildHelloPkt(key IntfConfKey) []byte {
 	} else {
 		option = EOption
 	}
+	//Rfc 3101 3.1
+	if server.isNssaArea(ent.AreaId) {
+		option |= NPOption
+	}
 	helloData := OSPFHelloData{
 		HelloInterval:   ent.HelloInterval,
 		Options:         option,
@@ -106,11 +117,9 @@ func (server *OSPFV2Server) BuildHelloPkt(key IntfConfKey) []byte {
 	ospfEncHdr := encodeOspfHdr(ospfHdr)
 	helloDataEnc := encodeOspfHelloData(helloData, nbrList)
 	ospf := append(ospfEncHdr, helloDataEnc...)
-	csum := computeCheckSum(ospf)
-	binary.BigEndian.PutUint16(ospf[12:14], csum)
-	binary.BigEndian.PutUint64(ospf[16:24], ent.AuthKey)
+	ospf = server.encodeOspfAuth(ent, ospf)
 
-	ipPktlen := IP_HEADER_MIN_LEN + ospfHdr.Pktlen
+	ipPktlen := IP_HEADER_MIN_LEN + len(ospf)
 	srcIp := net.ParseIP(convertUint32ToDotNotation(ent.IpAddr))
 	ipLayer := layers.IPv4{
 		Version:  uint8(4),
@@ -120,12 +129,12 @@ func (server *OSPFV2Server) BuildHelloPkt(key IntfConfKey) []byte {
 		TTL:      uint8(1),
 		Protocol: layers.IPProtocol(OSPF_PROTO_ID),
 		SrcIP:    srcIp,
-		DstIP:    net.IP{224, 0, 0, 5}, //ALLSPFROUTER
+		DstIP:    dstIp,
 	}
 
 	ethLayer := layers.Ethernet{
 		SrcMAC:       ent.IfMacAddr,
-		DstMAC:       net.HardwareAddr{0x01, 0x00, 0x5e, 0x00, 0x00, 0x05},
+		DstMAC:       dstMac,
 		EthernetType: layers.EthernetTypeIPv4,
 	}
 
@@ -161,12 +170,22 @@ func (server *OSPFV2Server) processRxHelloPkt(data []byte,
 		}
 	}
 
+	nbrKey := NbrConfKey{
+		NbrIdentity:         ipHdrMd.SrcIP,
+		NbrAddressLessIfIdx: key.IntfIdx,
+	}
 	if ent.HelloInterval != ospfHelloData.HelloInterval {
+		server.updatePktStats(key, nbrKey, func(stats *objects.Ospfv2PktStats) {
+			stats.NumOfHelloIntvlMismatch++
+		})
 		err := errors.New("Hello Interval mismatch")
 		return err
 	}
 
 	if ent.RtrDeadInterval != ospfHelloData.RtrDeadInterval {
+		server.updatePktStats(key, nbrKey, func(stats *objects.Ospfv2PktStats) {
+			stats.NumOfDeadIntvlMismatch++
+		})
 		err := errors.New("Router Dead Interval mismatch")
 		return err
 	}
@@ -187,6 +206,16 @@ func (server *OSPFV2Server) processRxHelloPkt(data []byte,
 
 	}
 
+	if areaEnt.AreaType == objects.AREA_TYPE_NSSA {
+		if (ospfHelloData.Options & NPOption) == 0 {
+			return errors.New("NSSA Capability mismatch")
+		}
+	} else {
+		if (ospfHelloData.Options & NPOption) != 0 {
+			return errors.New("NSSA Capability mismatch")
+		}
+	}
+
 	TwoWayStatus := false
 	for _, nbr := range ospfHelloData.NbrList {
 		if nbr == server.globalData.RouterId {
@@ -243,7 +272,9 @@ func (server *OSPFV2Server) processOspfHelloNbr(ethHdrMd *EthHdrMetadata, ipHdrM
 		nbrCreateMsg.NbrKey = nbrKey
 		ent.NbrCreateCh <- nbrCreateMsg
 		server.logger.Info("Nbr Entry Created", nbrEntry)
-	} else {
+	} else if !server.isNbrGracefulRestartHelping(nbrKey) {
+		// Hellos of a restarting nbr are not allowed to change the
+		// interface state while helping it
 		if nbrEntry.TwoWayStatus != TwoWayStatus ||
 			nbrEntry.DRtrIpAddr != ospfHelloData.DRtrIpAddr ||
 			nbrEntry.BDRtrIpAddr != ospfHelloData.BDRtrIpAddr ||
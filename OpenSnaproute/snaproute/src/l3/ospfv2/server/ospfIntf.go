@@ -48,7 +48,12 @@ type IntfConf struct {
 	Cost            uint32
 	Mtu             uint32
 	AuthType        uint16
-	AuthKey         uint64
+	AuthKey         []byte
+	AuthKeyId       uint8
+	PollInterval    uint32 // NBMA only
+	NbrPollTime     map[uint32]time.Time
+	BfdEnable       bool
+	Passive         uint8
 
 	DRIpAddr  uint32
 	DRtrId    uint32
@@ -79,6 +84,24 @@ type IntfConf struct {
 	Netmask   uint32
 	txHdl     IntfTxHandle
 	rxHdl     IntfRxHandle
+	authData  *IntfAuthStruct
+
+	// Virtual link only
+	VirtNbrIpAddr uint32
+	VirtNextHopIp uint32
+	VirtRxIpAddrs []uint32
+}
+
+// No multicast on NBMA and point-to-multipoint networks, nbrs are configured
+func isStaticNbrIntf(intfType uint8) bool {
+	return intfType == objects.INTF_TYPE_NBMA ||
+		intfType == objects.INTF_TYPE_POINT2MULTIPOINT
+}
+
+// DR is elected on broadcast and NBMA networks only
+func isMultiAccessIntf(intfType uint8) bool {
+	return intfType == objects.INTF_TYPE_BROADCAST ||
+		intfType == objects.INTF_TYPE_NBMA
 }
 
 func getOspfv2IntfUpdateMask(attrset []bool) uint32 {
@@ -93,7 +116,12 @@ func getOspfv2IntfUpdateMask(attrset []bool) uint32 {
 			objects.OSPFV2_INTF_UPDATE_RETRANS_INTERVAL |
 			objects.OSPFV2_INTF_UPDATE_HELLO_INTERVAL |
 			objects.OSPFV2_INTF_UPDATE_RTR_DEAD_INTERVAL |
-			objects.OSPFV2_INTF_UPDATE_METRIC_VALUE
+			objects.OSPFV2_INTF_UPDATE_METRIC_VALUE |
+			objects.OSPFV2_INTF_UPDATE_AUTH_KEY |
+			objects.OSPFV2_INTF_UPDATE_AUTH_KEY_ID |
+			objects.OSPFV2_INTF_UPDATE_POLL_INTERVAL |
+			objects.OSPFV2_INTF_UPDATE_BFD_ENABLE |
+			objects.OSPFV2_INTF_UPDATE_PASSIVE
 	} else {
 		for idx, val := range attrset {
 			if true == val {
@@ -120,6 +148,16 @@ func getOspfv2IntfUpdateMask(attrset []bool) uint32 {
 					mask |= objects.OSPFV2_INTF_UPDATE_RTR_DEAD_INTERVAL
 				case 10:
 					mask |= objects.OSPFV2_INTF_UPDATE_METRIC_VALUE
+				case 11:
+					mask |= objects.OSPFV2_INTF_UPDATE_AUTH_KEY
+				case 12:
+					mask |= objects.OSPFV2_INTF_UPDATE_AUTH_KEY_ID
+				case 13:
+					mask |= objects.OSPFV2_INTF_UPDATE_POLL_INTERVAL
+				case 14:
+					mask |= objects.OSPFV2_INTF_UPDATE_BFD_ENABLE
+				case 15:
+					mask |= objects.OSPFV2_INTF_UPDATE_PASSIVE
 				}
 			}
 		}
@@ -139,6 +177,21 @@ func (server *OSPFV2Server) updateIntf(newCfg, oldCfg *objects.Ospfv2Intf, attrs
 		return false, errors.New("Ospf Interface configuration doesnot exist")
 	}
 	areaEnt, _ := server.AreaConfMap[intfConfEnt.AreaId]
+	mask := getOspfv2IntfUpdateMask(attrset)
+	if mask&(objects.OSPFV2_INTF_UPDATE_AUTH_KEY|objects.OSPFV2_INTF_UPDATE_AREA_ID) != 0 {
+		newAreaEnt, exist := server.AreaConfMap[newCfg.AreaId]
+		if exist {
+			err := validateIntfAuthKey(newAreaEnt.AuthType, newCfg.AuthKey)
+			if err != nil {
+				server.logger.Err("Intf configuration update:", err)
+				return false, err
+			}
+		}
+	}
+	if mask == objects.OSPFV2_INTF_UPDATE_BFD_ENABLE {
+		server.updateIntfBfdEnable(intfConfKey, newCfg.BfdEnable)
+		return true, nil
+	}
 	if intfConfEnt.AdminState == true &&
 		server.globalData.AdminState == true &&
 		areaEnt.AdminState == true &&
@@ -147,17 +200,17 @@ func (server *OSPFV2Server) updateIntf(newCfg, oldCfg *objects.Ospfv2Intf, attrs
 	}
 	intfConfEnt, _ = server.IntfConfMap[intfConfKey]
 	oldIntfConfEnt := intfConfEnt
-	mask := getOspfv2IntfUpdateMask(attrset)
 	if mask&objects.OSPFV2_INTF_UPDATE_ADMIN_STATE == objects.OSPFV2_INTF_UPDATE_ADMIN_STATE {
 		intfConfEnt.AdminState = newCfg.AdminState
 	}
 	if mask&objects.OSPFV2_INTF_UPDATE_AREA_ID == objects.OSPFV2_INTF_UPDATE_AREA_ID {
-		_, exist := server.AreaConfMap[newCfg.AreaId]
+		newAreaEnt, exist := server.AreaConfMap[newCfg.AreaId]
 		if !exist {
 			server.logger.Err("Area doesnot exist")
 			return false, errors.New("Area doesnot exist")
 		}
 		intfConfEnt.AreaId = newCfg.AreaId
+		intfConfEnt.AuthType = uint16(newAreaEnt.AuthType)
 	}
 	if mask&objects.OSPFV2_INTF_UPDATE_TYPE == objects.OSPFV2_INTF_UPDATE_TYPE {
 		intfConfEnt.Type = newCfg.Type
@@ -180,6 +233,21 @@ func (server *OSPFV2Server) updateIntf(newCfg, oldCfg *objects.Ospfv2Intf, attrs
 	if mask&objects.OSPFV2_INTF_UPDATE_METRIC_VALUE == objects.OSPFV2_INTF_UPDATE_METRIC_VALUE {
 		intfConfEnt.Cost = uint32(newCfg.MetricValue)
 	}
+	if mask&objects.OSPFV2_INTF_UPDATE_AUTH_KEY == objects.OSPFV2_INTF_UPDATE_AUTH_KEY {
+		intfConfEnt.AuthKey = encodeIntfAuthKey(newCfg.AuthKey)
+	}
+	if mask&objects.OSPFV2_INTF_UPDATE_AUTH_KEY_ID == objects.OSPFV2_INTF_UPDATE_AUTH_KEY_ID {
+		intfConfEnt.AuthKeyId = newCfg.AuthKeyId
+	}
+	if mask&objects.OSPFV2_INTF_UPDATE_POLL_INTERVAL == objects.OSPFV2_INTF_UPDATE_POLL_INTERVAL {
+		intfConfEnt.PollInterval = newCfg.PollInterval
+	}
+	if mask&objects.OSPFV2_INTF_UPDATE_BFD_ENABLE == objects.OSPFV2_INTF_UPDATE_BFD_ENABLE {
+		intfConfEnt.BfdEnable = newCfg.BfdEnable
+	}
+	if mask&objects.OSPFV2_INTF_UPDATE_PASSIVE == objects.OSPFV2_INTF_UPDATE_PASSIVE {
+		intfConfEnt.Passive = newCfg.Passive
+	}
 	areaEnt, _ = server.AreaConfMap[oldIntfConfEnt.AreaId]
 	delete(areaEnt.IntfMap, intfConfKey)
 	server.AreaConfMap[oldIntfConfEnt.AreaId] = areaEnt
@@ -241,6 +309,11 @@ func (server *OSPFV2Server) createIntf(cfg *objects.Ospfv2Intf) (bool, error) {
 		server.logger.Err("Area doesnot exist")
 		return false, errors.New("Area doesnot exist")
 	}
+	err := validateIntfAuthKey(areaEnt.AuthType, cfg.AuthKey)
+	if err != nil {
+		server.logger.Err("Intf configuration create:", err)
+		return false, err
+	}
 	intfConfEnt.AreaId = cfg.AreaId
 	intfConfEnt.Type = cfg.Type
 	intfConfEnt.RtrPriority = cfg.RtrPriority
@@ -254,7 +327,13 @@ func (server *OSPFV2Server) createIntf(cfg *objects.Ospfv2Intf) (bool, error) {
 	//intfConfEnt.DRtrId = 0
 	//intfConfEnt.BDRIpAddr = 0
 	//intfConfEnt.BDRtrId = 0
-	intfConfEnt.AuthKey = 0
+	intfConfEnt.AuthType = uint16(areaEnt.AuthType)
+	intfConfEnt.AuthKey = encodeIntfAuthKey(cfg.AuthKey)
+	intfConfEnt.AuthKeyId = cfg.AuthKeyId
+	intfConfEnt.PollInterval = cfg.PollInterval
+	intfConfEnt.BfdEnable = cfg.BfdEnable
+	intfConfEnt.Passive = cfg.Passive
+	intfConfEnt.authData = NewIntfAuthStruct()
 
 	intfConfEnt.FSMState = objects.INTF_FSM_STATE_DOWN
 
@@ -271,7 +350,7 @@ func (server *OSPFV2Server) createIntf(cfg *objects.Ospfv2Intf) (bool, error) {
 	server.IntfConfMap[intfConfKey] = intfConfEnt
 
 	areaEnt.IntfMap[intfConfKey] = true
-	server.MessagingChData.NbrToIntfFSMChData.NbrDownMsgChMap[intfConfKey] = make(chan NbrDownMsg)
+	server.MessagingChData.NbrToIntfFSMChData.NbrDownMsgChMap[intfConfKey] = make(chan NbrDownMsg, NBR_DOWN_MSG_QUEUE_LEN)
 	server.AreaConfMap[cfg.AreaId] = areaEnt
 	if intfConfEnt.AdminState == true &&
 		server.globalData.AdminState == true &&
@@ -295,6 +374,13 @@ func (server *OSPFV2Server) deleteIntf(cfg *objects.Ospfv2Intf) (bool, error) {
 		server.logger.Err("Ospf Interface configuration doesnot exist")
 		return false, errors.New("Ospf Interface configuration doesnot exist")
 	}
+	intfConfEnt.authData.AuthMutex.Lock()
+	numOfKeys := len(intfConfEnt.authData.Keys)
+	intfConfEnt.authData.AuthMutex.Unlock()
+	if numOfKeys != 0 {
+		server.logger.Err("Ospf Interface has auth keys configured")
+		return false, errors.New("Unable to delete Ospf Interface, delete its auth keys first")
+	}
 
 	server.logger.Info("Intf Conf Ent", intfConfEnt)
 	areaEnt, _ := server.AreaConfMap[intfConfEnt.AreaId]
@@ -309,6 +395,7 @@ func (server *OSPFV2Server) deleteIntf(cfg *objects.Ospfv2Intf) (bool, error) {
 	server.AreaConfMap[intfConfEnt.AreaId] = areaEnt
 	delete(server.MessagingChData.NbrToIntfFSMChData.NbrDownMsgChMap, intfConfKey)
 	delete(server.IntfConfMap, intfConfKey)
+	server.delIntfPktStats(intfConfKey)
 	return true, nil
 }
 
@@ -351,6 +438,9 @@ func (server *OSPFV2Server) getIntfState(ipAddr, addressLessIfIdx uint32) (*obje
 	//TODO: NumOfRoutes
 	retObj.NumOfStateChange = intfEnt.NumOfStateChange
 	retObj.TimeOfStateChange = intfEnt.TimeOfStateChange
+	retObj.NumOfAuthTypeMismatch, retObj.NumOfAuthFailures,
+		retObj.NumOfAuthReplayDrops = server.getIntfAuthStats(intfEnt)
+	retObj.Ospfv2PktStats = server.getIntfPktStats(intfKey)
 	return &retObj, nil
 }
 
@@ -402,6 +492,9 @@ func (server *OSPFV2Server) getBulkIntfState(fromIdx, cnt int) (*objects.Ospfv2I
 		//TODO: NumOfRoutes
 		obj.NumOfStateChange = intfEnt.NumOfStateChange
 		obj.TimeOfStateChange = intfEnt.TimeOfStateChange
+		obj.NumOfAuthTypeMismatch, obj.NumOfAuthFailures,
+			obj.NumOfAuthReplayDrops = server.getIntfAuthStats(intfEnt)
+		obj.Ospfv2PktStats = server.getIntfPktStats(intfKey)
 		retObj.List = append(retObj.List, &obj)
 		count++
 		idx++
@@ -442,7 +535,10 @@ func (server *OSPFV2Server) RefreshIntfConfSlice() {
 	}
 	server.GetBulkData.IntfConfSlice = server.GetBulkData.IntfConfSlice[:len(server.GetBulkData.AreaConfSlice)-1]
 	server.GetBulkData.IntfConfSlice = nil
-	for intfKey, _ := range server.IntfConfMap {
+	for intfKey, intfEnt := range server.IntfConfMap {
+		if intfEnt.Type == objects.INTF_TYPE_VIRTUAL {
+			continue
+		}
 		server.GetBulkData.IntfConfSlice = append(server.GetBulkData.IntfConfSlice, intfKey)
 	}
 }
@@ -33,18 +33,19 @@ func (server *OSPFV2Server) InitOspfIntfFSM(intfConfKey IntfConfKey) {
 	ent, _ := server.IntfConfMap[intfConfKey]
 	helloInterval := time.Duration(ent.HelloInterval) * time.Second
 	ent.HelloIntervalTicker = time.NewTicker(helloInterval)
-	if ent.Type == objects.INTF_TYPE_BROADCAST {
+	if isMultiAccessIntf(ent.Type) {
 		waitTime := time.Duration(ent.RtrDeadInterval) * time.Second
 		ent.WaitTimer = time.NewTimer(waitTime)
 	}
-	if ent.Type == objects.INTF_TYPE_BROADCAST {
+	if isMultiAccessIntf(ent.Type) {
 		ent.FSMState = objects.INTF_FSM_STATE_WAITING
-	} else if ent.Type == objects.INTF_TYPE_POINT2POINT {
+	} else {
 		ent.FSMState = objects.INTF_FSM_STATE_P2P
 	}
 	ent.NumOfStateChange++
 	ent.TimeOfStateChange = time.Now().String()
 	ent.NbrMap = make(map[NbrConfKey]NbrData)
+	ent.NbrPollTime = make(map[uint32]time.Time)
 	ent.BDRIpAddr = 0
 	ent.DRIpAddr = 0
 	ent.BDRtrId = 0
@@ -55,10 +56,12 @@ func (server *OSPFV2Server) InitOspfIntfFSM(intfConfKey IntfConfKey) {
 func (server *OSPFV2Server) DeinitOspfIntfFSM(intfConfKey IntfConfKey) {
 	ent, _ := server.IntfConfMap[intfConfKey]
 	ent.NbrMap = nil
+	ent.NbrPollTime = nil
+	server.resetIntfCryptoSeqNum(ent)
 	ent.FSMState = objects.INTF_FSM_STATE_DOWN
 	ent.NumOfStateChange++
 	ent.TimeOfStateChange = time.Now().String()
-	if ent.Type == objects.INTF_TYPE_BROADCAST {
+	if isMultiAccessIntf(ent.Type) {
 		ent.WaitTimer.Stop()
 		ent.WaitTimer = nil
 	}
@@ -111,6 +114,10 @@ func (server *OSPFV2Server) StopIntfFSM(key IntfConfKey) {
 			areaConf.AdminState == true &&
 			ent.OperState == true &&
 			ent.FSMState != objects.INTF_FSM_STATE_DOWN {
+			if server.isIntfPassive(ent) {
+				server.StopPassiveIntf(key)
+				return
+			}
 			ent.FSMCtrlCh <- false
 			cnt := 0
 			for {
@@ -157,6 +164,10 @@ func (server *OSPFV2Server) StartIntfFSM(key IntfConfKey) {
 			areaConf.AdminState == true &&
 			ent.OperState == true &&
 			ent.FSMState == objects.INTF_FSM_STATE_DOWN {
+			if server.isIntfPassive(ent) {
+				server.StartPassiveIntf(key)
+				return
+			}
 			ent.FSMCtrlCh = make(chan bool)
 			ent.FSMCtrlReplyCh = make(chan bool)
 			ent.BackupSeenCh = make(chan BackupSeenMsg)
@@ -164,10 +175,10 @@ func (server *OSPFV2Server) StartIntfFSM(key IntfConfKey) {
 			ent.NbrChangeCh = make(chan NbrChangeMsg)
 			server.IntfConfMap[key] = ent
 			server.StartIntfRxTxPkt(key)
-			if ent.Type == objects.INTF_TYPE_POINT2POINT {
-				go server.StartOspfP2PIntfFSM(key)
-			} else if ent.Type == objects.INTF_TYPE_BROADCAST {
+			if isMultiAccessIntf(ent.Type) {
 				go server.StartOspfBroadcastIntfFSM(key)
+			} else {
+				go server.StartOspfP2PIntfFSM(key)
 			}
 		}
 	} else {
@@ -347,6 +358,7 @@ func (server *OSPFV2Server) processNbrDownEvent(msg NbrDownMsg,
 	if exist {
 		delete(ent.NbrMap, msg.NbrKey)
 		server.logger.Info("Deleting", msg.NbrKey)
+		server.resetNbrCryptoSeqNum(ent, msg.NbrKey.NbrIdentity)
 		server.IntfConfMap[key] = ent
 		if p2p == false {
 			if ent.FSMState > objects.INTF_FSM_STATE_WAITING {
@@ -405,7 +417,7 @@ func (server *OSPFV2Server) ElectBDR(key IntfConfKey) (uint32, uint32) {
 
 	if ent.RtrPriority != 0 && ent.IpAddr != 0 {
 		if ent.IpAddr != ent.DRIpAddr {
-			if ent.IpAddr != ent.BDRIpAddr {
+			if ent.IpAddr == ent.BDRIpAddr {
 				rtrId := server.globalData.RouterId
 				if ent.RtrPriority > electedRtrPrio {
 					electedRtrPrio = ent.RtrPriority
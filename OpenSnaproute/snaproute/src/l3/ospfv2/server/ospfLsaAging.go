@@ -23,7 +23,9 @@
 
 package server
 
-import ()
+import (
+	"l3/ospfv2/objects"
+)
 
 func (server *OSPFV2Server) processLsdbAgeSelfOrigRouterLsa(lsdbKey LsdbKey, lsaKey LsaKey, lsa *RouterLsa) {
 	//Increment LSA age
@@ -126,6 +128,9 @@ func (server *OSPFV2Server) processLsdbAgeSelfOrigASExternalLsa(lsdbKey LsdbKey,
 			NwAddr:  lsaKey.LSId,
 			Netmask: lsa.Netmask,
 			Metric:  lsa.Metric,
+			BitE:    lsa.BitE,
+			Tag:     lsa.ExtRouteTag,
+			FwdAddr: lsa.FwdAddr,
 		}
 		_, exist := server.LsdbData.ExtRouteInfoMap[routeInfo]
 		if exist {
@@ -136,6 +141,23 @@ func (server *OSPFV2Server) processLsdbAgeSelfOrigASExternalLsa(lsdbKey LsdbKey,
 	return
 }
 
+func (server *OSPFV2Server) processLsdbAgeSelfOrigNssaLsa(lsdbKey LsdbKey, lsaKey LsaKey, lsa *ASExternalLsa) {
+	//Increment LSA age
+	if lsa.LsaMd.LSAge < MAX_AGE {
+		lsa.LsaMd.LSAge++
+	}
+	//If Age = multiples of CheckAge compute checksum and verify if error raise an alarm
+	if (lsa.LsaMd.LSAge % CHECK_AGE) == 0 {
+		lsaEnc := encodeASExternalLsa(*lsa, lsaKey)
+		cSum := computeFletcherChecksum(lsaEnc[2:], FLETCHER_CHECKSUM_VALIDATE)
+		if cSum != 0 {
+			server.logger.Err("Some serious problem, may be memory corruption")
+			return
+		}
+	}
+	return
+}
+
 func (server *OSPFV2Server) processLsdbAgeSelfOrigLsa(lsdbKey LsdbKey, lsaKey LsaKey, lsaEnt interface{}) {
 	switch lsaKey.LSType {
 	case RouterLSA:
@@ -173,6 +195,13 @@ func (server *OSPFV2Server) processLsdbAgeSelfOrigLsa(lsdbKey LsdbKey, lsaKey Ls
 			return
 		}
 		server.processLsdbAgeSelfOrigASExternalLsa(lsdbKey, lsaKey, lsa)
+	case NSSALSA:
+		lsa, ok := lsaEnt.(*ASExternalLsa)
+		if !ok {
+			server.logger.Err("Unable to assert lsa")
+			return
+		}
+		server.processLsdbAgeSelfOrigNssaLsa(lsdbKey, lsaKey, lsa)
 	}
 	return
 }
@@ -335,6 +364,14 @@ func (server *OSPFV2Server) processLsdbAgeNonSelfLsa(lsdbKey LsdbKey, lsaKey Lsa
 			return msg, false
 		}
 		return server.processLsdbAgeNonSelfASExternalLsa(lsdbKey, lsaKey, lsa)
+	case NSSALSA:
+		lsa, ok := lsaEnt.(*ASExternalLsa)
+		if !ok {
+			server.logger.Err("Unable to assert lsa")
+			return msg, false
+		}
+		// Type-7 LSAs share the AS External LSA format
+		return server.processLsdbAgeNonSelfASExternalLsa(lsdbKey, lsaKey, lsa)
 	}
 	return msg, false
 }
@@ -346,6 +383,7 @@ func (server *OSPFV2Server) processLsdbAgingTicker() {
 	var needSPFCalcSummary3 bool
 	var needSPFCalcSummary4 bool
 	var needSPFCalcASExternal bool
+	var needSPFCalcNssa bool
 	for lsdbKey, lsdbEnt := range server.LsdbData.AreaLsdb {
 		for lsaKey, lsaEnt := range lsdbEnt.RouterLsaMap {
 			selfOrigEnt, exist := server.LsdbData.AreaSelfOrigLsa[lsdbKey]
@@ -484,11 +522,16 @@ func (server *OSPFV2Server) processLsdbAgingTicker() {
 								NwAddr:  lsaKey.LSId,
 								Netmask: lsaEnt.Netmask,
 								Metric:  lsaEnt.Metric,
+								BitE:    lsaEnt.BitE,
+								Tag:     lsaEnt.ExtRouteTag,
+								FwdAddr: lsaEnt.FwdAddr,
 							}
 							_, exist = server.LsdbData.ExtRouteInfoMap[routeInfo]
 							if exist {
 								server.reGenerateASExternalLSAForGivenArea(routeInfo, lsdbKey.AreaId)
 								needSPFCalcASExternal = true
+							} else if _, exist = server.LsdbData.NssaTranslatedLsaMap[lsaKey]; exist {
+								server.refreshSelfOrigExternalLSA(lsdbKey, lsaKey)
 							}
 						}
 					}
@@ -497,13 +540,50 @@ func (server *OSPFV2Server) processLsdbAgingTicker() {
 				server.logger.Err("This should Not happen some serious problem")
 			}
 		}
+		for lsaKey, lsaEnt := range lsdbEnt.NssaLsaMap {
+			selfOrigEnt, exist := server.LsdbData.AreaSelfOrigLsa[lsdbKey]
+			if exist {
+				_, exist := selfOrigEnt[lsaKey]
+				if exist {
+					server.processLsdbAgeSelfOrigLsa(lsdbKey, lsaKey, &lsaEnt)
+				} else {
+					lsdbToFloodLSAMsg, flag := server.processLsdbAgeNonSelfLsa(lsdbKey, lsaKey, &lsaEnt)
+					if flag == true {
+						lsdbToFloodLSAMsgList = append(lsdbToFloodLSAMsgList, lsdbToFloodLSAMsg)
+						needSPFCalcNssa = true
+					}
+				}
+				if lsaEnt.LsaMd.LSAge == MAX_AGE {
+					delete(server.LsdbData.AreaLsdb[lsdbKey].NssaLsaMap, lsaKey)
+				} else {
+					server.LsdbData.AreaLsdb[lsdbKey].NssaLsaMap[lsaKey] = lsaEnt
+					if exist && lsaEnt.LsaMd.LSAge == LS_REFRESH_TIME {
+						server.refreshSelfOrigExternalLSA(lsdbKey, lsaKey)
+					}
+				}
+			} else {
+				server.logger.Err("This should Not happen some serious problem")
+			}
+		}
+	}
+	for lsdbKey, lsdbEnt := range server.LsdbData.AreaLsdb {
+		lsdbToFloodLSAMsgList = append(lsdbToFloodLSAMsgList, server.processLsdbAgingOpaqueLsa(lsdbKey, lsdbEnt)...)
 	}
 	server.SendMsgFromLsdbToFloodLsa(lsdbToFloodLSAMsgList)
-	if needSPFCalcRouter == true ||
-		needSPFCalcNetwork == true ||
-		needSPFCalcSummary3 == true ||
-		needSPFCalcSummary4 == true ||
-		needSPFCalcASExternal == true {
-		server.CalcSPFAndRoutingTbl()
+	if needSPFCalcRouter == true {
+		server.scheduleSPF(SPF_CALC_FULL, objects.SPF_TRIGGER_ROUTER_LSA)
+	}
+	if needSPFCalcNetwork == true {
+		server.scheduleSPF(SPF_CALC_FULL, objects.SPF_TRIGGER_NETWORK_LSA)
+	}
+	if needSPFCalcSummary3 == true ||
+		needSPFCalcSummary4 == true {
+		server.scheduleSPF(SPF_CALC_PARTIAL, objects.SPF_TRIGGER_SUMMARY_LSA)
+	}
+	if needSPFCalcASExternal == true {
+		server.scheduleSPF(SPF_CALC_PARTIAL, objects.SPF_TRIGGER_AS_EXTERNAL_LSA)
+	}
+	if needSPFCalcNssa == true {
+		server.scheduleSPF(SPF_CALC_PARTIAL, objects.SPF_TRIGGER_NSSA_LSA)
 	}
 }
@@ -56,6 +56,7 @@ type RouterLsa struct {
 	BitV        bool         /* V Bit */
 	BitE        bool         /* Bit E */
 	BitB        bool         /* Bit B */
+	BitNt       bool         /* Bit Nt, Rfc 3101 */
 	NumOfLinks  uint16       /* NumOfLinks */
 	LinkDetails []LinkDetail /* List of LinkDetails */
 }
@@ -121,6 +122,11 @@ func decodeRouterLsa(data []byte, lsa *RouterLsa, lsakey *LsaKey) {
 	} else {
 		lsa.BitB = false
 	}
+	if data[20]&0x10 != 0 {
+		lsa.BitNt = true
+	} else {
+		lsa.BitNt = false
+	}
 	lsa.NumOfLinks = binary.BigEndian.Uint16(data[22:24])
 	lsa.LinkDetails = make([]LinkDetail, lsa.NumOfLinks)
 	start := 24
@@ -187,6 +193,9 @@ func encodeRouterLsa(lsa RouterLsa, lsakey LsaKey) []byte {
 	if lsa.BitB == true {
 		val = val | 1
 	}
+	if lsa.BitNt == true {
+		val = val | 1<<4
+	}
 	rtrLsa[20] = val
 	binary.BigEndian.PutUint16(rtrLsa[22:24], lsa.NumOfLinks)
 
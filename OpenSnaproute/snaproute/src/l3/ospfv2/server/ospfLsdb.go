@@ -34,6 +34,7 @@ func (server *OSPFV2Server) InitLsdbData() {
 	server.LsdbData.AreaSelfOrigLsa = make(map[LsdbKey]SelfOrigLsa)
 	server.LsdbData.LsdbAgingTicker = nil
 	server.LsdbData.ExtRouteInfoMap = make(map[RouteInfo]bool)
+	server.LsdbData.NssaTranslatedLsaMap = make(map[LsaKey]ASExternalLsa)
 }
 
 func (server *OSPFV2Server) DeinitLsdb() {
@@ -47,13 +48,36 @@ func (server *OSPFV2Server) DeinitLsdb() {
 	server.LsdbData.AreaLsdb = nil
 	server.LsdbData.AreaSelfOrigLsa = nil
 	server.LsdbData.ExtRouteInfoMap = nil
+	server.LsdbData.NssaTranslatedLsaMap = nil
 }
 
+// Server routine is blocked till Lsdb init is done, hence the
+// redistribution data can be rebuilt from here
 func (server *OSPFV2Server) GetExtRouteInfo() {
-	routeInfoList := server.getBulkRoutesFromRibd()
-	for _, route := range routeInfoList {
-		server.LsdbData.ExtRouteInfoMap[*route] = true
-		server.generateASExternalLSA(*route)
+	ribRouteList := server.getBulkRoutesFromRibd()
+	server.initRedistData()
+	for _, ribRoute := range ribRouteList {
+		server.addRibRoute(ribRoute)
+	}
+	for extKey, _ := range server.RedistData.RibRouteMap {
+		routeInfo, ok := server.getRedistRouteInfo(extKey)
+		if !ok {
+			continue
+		}
+		server.RedistData.AdvRouteMap[extKey] = routeInfo
+		server.LsdbData.ExtRouteInfoMap[routeInfo] = true
+		server.generateASExternalLSA(routeInfo)
+		server.generateNssaLSA(routeInfo)
+	}
+}
+
+// Remove the route info of the prefix, there is at most one per prefix
+func (server *OSPFV2Server) delExtRouteInfo(nwAddr, netmask uint32) {
+	for routeInfo, _ := range server.LsdbData.ExtRouteInfoMap {
+		if routeInfo.NwAddr == nwAddr &&
+			routeInfo.Netmask == netmask {
+			delete(server.LsdbData.ExtRouteInfoMap, routeInfo)
+		}
 	}
 }
 
@@ -69,6 +93,10 @@ func (server *OSPFV2Server) InitAreaLsdb(areaId uint32) {
 		lsDbEnt.Summary3LsaMap = make(map[LsaKey]SummaryLsa)
 		lsDbEnt.Summary4LsaMap = make(map[LsaKey]SummaryLsa)
 		lsDbEnt.ASExternalLsaMap = make(map[LsaKey]ASExternalLsa)
+		lsDbEnt.NssaLsaMap = make(map[LsaKey]ASExternalLsa)
+		lsDbEnt.OpaqueLinkLsaMap = make(map[OpaqueLinkLsaKey]OpaqueLsa)
+		lsDbEnt.OpaqueAreaLsaMap = make(map[LsaKey]OpaqueLsa)
+		lsDbEnt.OpaqueASLsaMap = make(map[LsaKey]OpaqueLsa)
 		server.LsdbData.AreaLsdb[lsdbKey] = lsDbEnt
 	}
 	selfOrigLsaEnt, exist := server.LsdbData.AreaSelfOrigLsa[lsdbKey]
@@ -89,6 +117,10 @@ func (server *OSPFV2Server) DeinitAreaLsdb(areaId uint32) {
 		lsDbEnt.Summary3LsaMap = nil
 		lsDbEnt.Summary4LsaMap = nil
 		lsDbEnt.ASExternalLsaMap = nil
+		lsDbEnt.NssaLsaMap = nil
+		lsDbEnt.OpaqueLinkLsaMap = nil
+		lsDbEnt.OpaqueAreaLsaMap = nil
+		lsDbEnt.OpaqueASLsaMap = nil
 		delete(server.LsdbData.AreaLsdb, lsdbKey)
 	}
 	_, exist = server.LsdbData.AreaSelfOrigLsa[lsdbKey]
@@ -107,6 +139,7 @@ func (server *OSPFV2Server) StartLsdbRoutine() {
 	server.LsdbData.LsdbCtrlChData.LsdbGblCtrlReplyCh = make(chan bool)
 	server.LsdbData.LsdbCtrlChData.LsdbAreaCtrlCh = make(chan uint32)
 	server.LsdbData.LsdbCtrlChData.LsdbAreaCtrlReplyCh = make(chan uint32)
+	server.initStubRouterLsdbState()
 	initDoneCh := make(chan bool)
 	go server.ProcessLsdb(initDoneCh)
 	<-initDoneCh
@@ -147,6 +180,10 @@ func (server *OSPFV2Server) processRecvdLSA(msg RecvdLsaMsg) error {
 		server.processRecvdSummaryLSA(msg)
 	case ASExternalLSA:
 		server.processRecvdASExternalLSA(msg)
+	case NSSALSA:
+		server.processRecvdNssaLSA(msg)
+	case OpaqueLinkLSA, OpaqueAreaLSA, OpaqueASLSA:
+		server.processRecvdOpaqueLSA(msg)
 	default:
 		server.logger.Err("Invalid LsaType:", msg)
 	}
@@ -165,6 +202,10 @@ func (server *OSPFV2Server) processRecvdSelfLSA(msg RecvdSelfLsaMsg) error {
 		server.processRecvdSelfSummaryLSA(msg)
 	case ASExternalLSA:
 		server.processRecvdSelfASExternalLSA(msg)
+	case NSSALSA:
+		server.processRecvdSelfNssaLSA(msg)
+	case OpaqueLinkLSA, OpaqueAreaLSA, OpaqueASLSA:
+		server.processRecvdSelfOpaqueLSA(msg)
 	default:
 		server.logger.Err("Invalid LsaType:", msg)
 	}
@@ -174,13 +215,16 @@ func (server *OSPFV2Server) processRecvdSelfLSA(msg RecvdSelfLsaMsg) error {
 func (server *OSPFV2Server) ProcessRouteInfoData(msg RouteInfoDataUpdateMsg) {
 	if msg.MsgType == ROUTE_INFO_ADD {
 		for _, routeInfo := range msg.RouteInfoList {
+			server.delExtRouteInfo(routeInfo.NwAddr, routeInfo.Netmask)
 			server.LsdbData.ExtRouteInfoMap[routeInfo] = true
 			server.generateASExternalLSA(routeInfo)
+			server.generateNssaLSA(routeInfo)
 		}
 	} else if msg.MsgType == ROUTE_INFO_DEL {
 		for _, routeInfo := range msg.RouteInfoList {
 			delete(server.LsdbData.ExtRouteInfoMap, routeInfo)
 			server.flushASExternalLSA(routeInfo)
+			server.flushNssaLSA(routeInfo)
 		}
 	} else {
 		server.logger.Err("Invalid MsgType for RouteInfoDataUpdateMsg")
@@ -238,6 +282,14 @@ func (server *OSPFV2Server) processNbrDead(msg NbrDeadMsg) bool {
 			server.CreateAndSendMsgFromLsdbToFloodLsa(msg.AreaId, lsaKey, lsaEnt)
 		}
 	}
+	for lsaKey, lsaEnt := range lsdbEnt.NssaLsaMap {
+		if lsaKey.AdvRouter == msg.NbrRtrId {
+			flag = true
+			delete(lsdbEnt.NssaLsaMap, lsaKey)
+			lsaEnt.LsaMd.LSAge = MAX_AGE
+			server.CreateAndSendMsgFromLsdbToFloodLsa(msg.AreaId, lsaKey, lsaEnt)
+		}
+	}
 	if flag == true {
 		server.LsdbData.AreaLsdb[lsdbKey] = lsdbEnt
 		return true
@@ -254,19 +306,26 @@ func (server *OSPFV2Server) ProcessLsdb(initDoneCh chan bool) {
 	}
 	server.GetExtRouteInfo()
 	server.LsdbData.LsdbAgingTicker = time.NewTicker(LsaAgingTimeGranularity)
+	server.startSpfThrottle(SpfThrottleMsg{
+		InitialWait: server.globalData.SpfInitialWait,
+		HoldWait:    server.globalData.SpfHoldWait,
+		MaxWait:     server.globalData.SpfMaxWait,
+	})
 	initDoneCh <- true
 	for {
 		select {
 		case _ = <-server.LsdbData.LsdbCtrlChData.LsdbGblCtrlCh:
 			server.logger.Info("Stopping ProcessLsdb routine")
 			server.LsdbData.LsdbAgingTicker.Stop()
+			server.stopSpfThrottle()
 			server.DeinitLsdb()
 			server.LsdbData.LsdbCtrlChData.LsdbGblCtrlReplyCh <- true
 			return
 		case areaId := <-server.LsdbData.LsdbCtrlChData.LsdbAreaCtrlCh:
 			server.DeinitAreaLsdb(areaId)
 			server.RefreshLsdbSlice()
-			server.CalcSPFAndRoutingTbl()
+			server.deleteAreaSpfStats(areaId)
+			server.scheduleSPF(SPF_CALC_FULL, objects.SPF_TRIGGER_AREA_CHANGE)
 			server.LsdbData.LsdbCtrlChData.LsdbAreaCtrlReplyCh <- areaId
 		case areaId := <-server.MessagingChData.ServerToLsdbChData.InitAreaLsdbCh:
 			server.logger.Info("InitAreaLsdb...")
@@ -274,6 +333,7 @@ func (server *OSPFV2Server) ProcessLsdb(initDoneCh chan bool) {
 			server.logger.Info("InitAreaLsdb...")
 			server.SendMsgFromLsdbToServerForInitAreaLsdbDone()
 			server.GenerateAllASExternalLSA(areaId)
+			server.GenerateAllNssaLSA(areaId)
 		case msg := <-server.MessagingChData.IntfFSMToLsdbChData.GenerateRouterLSACh:
 			server.logger.Info("Generate self originated Router LSA", msg)
 			err := server.GenerateRouterLSA(msg)
@@ -281,34 +341,50 @@ func (server *OSPFV2Server) ProcessLsdb(initDoneCh chan bool) {
 				continue
 			}
 			server.logger.Info("Successfully Generated Router LSA")
-			server.CalcSPFAndRoutingTbl()
-			server.logger.Info("Successfully Calculated SPF")
+			server.scheduleSPF(SPF_CALC_FULL, objects.SPF_TRIGGER_ROUTER_LSA)
 		case msg := <-server.MessagingChData.NbrFSMToLsdbChData.UpdateSelfNetworkLSACh:
 			server.logger.Info("Update self originated Network LSA", msg)
 			err := server.processUpdateSelfNetworkLSA(msg)
 			if err != nil {
 				continue
 			}
-			server.CalcSPFAndRoutingTbl()
+			server.scheduleSPF(SPF_CALC_FULL, objects.SPF_TRIGGER_NETWORK_LSA)
 		case msg := <-server.MessagingChData.NbrFSMToLsdbChData.RecvdLsaMsgCh:
 			server.logger.Info("Update LSA", msg)
 			server.processRecvdLSA(msg)
-			server.CalcSPFAndRoutingTbl()
+			server.scheduleSPF(getSpfCalcTypeForLsa(msg.LsaKey.LSType))
 		case msg := <-server.MessagingChData.NbrFSMToLsdbChData.RecvdSelfLsaMsgCh:
 			server.logger.Info("Recvd Self LSA", msg)
 			server.processRecvdSelfLSA(msg)
-			server.CalcSPFAndRoutingTbl()
+			server.scheduleSPF(getSpfCalcTypeForLsa(msg.LsaKey.LSType))
 		case msg := <-server.MessagingChData.NbrFSMToLsdbChData.NbrDeadMsgCh:
 			server.logger.Info("Recvd Nbr Dead in Lsdb:", msg)
 			ret := server.processNbrDead(msg)
 			if ret == true {
-				server.CalcSPFAndRoutingTbl()
+				server.scheduleSPF(SPF_CALC_FULL, objects.SPF_TRIGGER_NBR_DOWN)
 			}
+		case msg := <-server.MessagingChData.ServerToLsdbChData.OpaqueLsaUpdateCh:
+			server.processOpaqueLsaMsg(msg)
 		case msg := <-server.MessagingChData.ServerToLsdbChData.RouteInfoDataUpdateCh:
 			//TODO: Handle AS External
 			server.ProcessRouteInfoData(msg)
 		case <-server.LsdbData.LsdbAgingTicker.C:
 			server.processLsdbAgingTicker()
+		case <-server.SpfThrottleData.SpfTimerCh:
+			server.processSpfTimerExpiry()
+		case msg := <-server.MessagingChData.ServerToLsdbChData.SpfThrottleUpdateCh:
+			server.processSpfThrottleUpdate(msg)
+		case msg := <-server.MessagingChData.ServerToLsdbChData.StubRouterUpdateCh:
+			server.processStubRouterUpdate(msg)
+		case <-server.MessagingChData.ServerToLsdbChData.AreaRangeUpdateCh:
+			server.scheduleSPF(SPF_CALC_FULL, objects.SPF_TRIGGER_AREA_RANGE_CHANGE)
+		case <-server.MessagingChData.ServerToLsdbChData.VirtualLinkUpdateCh:
+			server.scheduleSPF(SPF_CALC_FULL, objects.SPF_TRIGGER_VIRTUAL_LINK_CHANGE)
+		case <-server.MessagingChData.ServerToLsdbChData.MaximumPathsUpdateCh:
+			server.scheduleSPF(SPF_CALC_FULL, objects.SPF_TRIGGER_MAXIMUM_PATHS_CHANGE)
+		case <-server.MessagingChData.ServerToLsdbChData.GracefulRestartExitCh:
+			server.reoriginateSelfLsa()
+			server.scheduleSPF(SPF_CALC_FULL, objects.SPF_TRIGGER_GRACEFUL_RESTART_EXIT)
 		case <-server.MessagingChData.ServerToLsdbChData.RefreshLsdbSliceCh:
 			server.RefreshLsdbSlice()
 			server.SendMsgFromLsdbToServerForRefreshDone()
@@ -316,9 +392,9 @@ func (server *OSPFV2Server) ProcessLsdb(initDoneCh chan bool) {
 	}
 }
 
-func (server *OSPFV2Server) CalcSPFAndRoutingTbl() {
+func (server *OSPFV2Server) CalcSPFAndRoutingTbl(msg SpfCalcMsg) {
 	server.SummaryLsDb = nil
-	server.SendMsgToStartSpf()
+	server.SendMsgToStartSpf(msg)
 	spfState := <-server.MessagingChData.SPFToLsdbChData.DoneSPF
 	server.logger.Debug("SPF Calculation Return Status", spfState)
 	if server.globalData.AreaBdrRtrStatus == true {
@@ -330,6 +406,9 @@ func (server *OSPFV2Server) CalcSPFAndRoutingTbl() {
 		//Summary LSA
 		server.installSummaryLsa()
 	}
+	server.updateAreaRangeDiscardRoutes()
+	server.processNssaAbrLsa()
+	server.processVirtualLinkEndpoints()
 }
 
 func (server *OSPFV2Server) RefreshLsdbSlice() {
@@ -374,6 +453,34 @@ func (server *OSPFV2Server) RefreshLsdbSlice() {
 			}
 			server.GetBulkData.LsdbSlice = append(server.GetBulkData.LsdbSlice, lsdbSlice)
 		}
+		for lsaKey, _ := range lsDbEnt.NssaLsaMap {
+			lsdbSlice := LsdbSliceStruct{
+				LsdbKey: lsdbKey,
+				LsaKey:  lsaKey,
+			}
+			server.GetBulkData.LsdbSlice = append(server.GetBulkData.LsdbSlice, lsdbSlice)
+		}
+		for linkLsaKey, _ := range lsDbEnt.OpaqueLinkLsaMap {
+			lsdbSlice := LsdbSliceStruct{
+				LsdbKey: lsdbKey,
+				LsaKey:  linkLsaKey.LsaKey,
+			}
+			server.GetBulkData.LsdbSlice = append(server.GetBulkData.LsdbSlice, lsdbSlice)
+		}
+		for lsaKey, _ := range lsDbEnt.OpaqueAreaLsaMap {
+			lsdbSlice := LsdbSliceStruct{
+				LsdbKey: lsdbKey,
+				LsaKey:  lsaKey,
+			}
+			server.GetBulkData.LsdbSlice = append(server.GetBulkData.LsdbSlice, lsdbSlice)
+		}
+		for lsaKey, _ := range lsDbEnt.OpaqueASLsaMap {
+			lsdbSlice := LsdbSliceStruct{
+				LsdbKey: lsdbKey,
+				LsaKey:  lsaKey,
+			}
+			server.GetBulkData.LsdbSlice = append(server.GetBulkData.LsdbSlice, lsdbSlice)
+		}
 	}
 }
 
@@ -430,6 +537,20 @@ func (server *OSPFV2Server) getLsdbState(lsaType uint8, lsId, areaId, advRtrId u
 		}
 		lsaMd = lsaEnt.LsaMd
 		lsaEnc = encodeASExternalLsa(lsaEnt, lsaKey)
+	case NSSALSA:
+		lsaEnt, exist := lsdbEnt.NssaLsaMap[lsaKey]
+		if !exist {
+			return nil, errors.New("No such LSA exist")
+		}
+		lsaMd = lsaEnt.LsaMd
+		lsaEnc = encodeASExternalLsa(lsaEnt, lsaKey)
+	case OpaqueLinkLSA, OpaqueAreaLSA, OpaqueASLSA:
+		lsaEnt, exist := getOpaqueLsaByLsaKey(lsdbEnt, lsaKey)
+		if !exist {
+			return nil, errors.New("No such LSA exist")
+		}
+		lsaMd = lsaEnt.LsaMd
+		lsaEnc = encodeOpaqueLsa(lsaEnt, lsaKey)
 	default:
 		return nil, errors.New("Invalid LSType")
 	}
@@ -517,6 +638,22 @@ func (server *OSPFV2Server) getBulkLsdbState(fromIdx, cnt int) (*objects.Ospfv2L
 			}
 			lsaMd = lsaEnt.LsaMd
 			lsaEnc = encodeASExternalLsa(lsaEnt, lsdbSlice.LsaKey)
+		case NSSALSA:
+			lsaEnt, exist := lsdbEnt.NssaLsaMap[lsdbSlice.LsaKey]
+			if !exist {
+				idx++
+				continue
+			}
+			lsaMd = lsaEnt.LsaMd
+			lsaEnc = encodeASExternalLsa(lsaEnt, lsdbSlice.LsaKey)
+		case OpaqueLinkLSA, OpaqueAreaLSA, OpaqueASLSA:
+			lsaEnt, exist := getOpaqueLsaByLsaKey(lsdbEnt, lsdbSlice.LsaKey)
+			if !exist {
+				idx++
+				continue
+			}
+			lsaMd = lsaEnt.LsaMd
+			lsaEnc = encodeOpaqueLsa(lsaEnt, lsdbSlice.LsaKey)
 		default:
 			idx++
 			continue
@@ -37,6 +37,10 @@ const (
 	Summary3LSA   uint8 = 3
 	Summary4LSA   uint8 = 4
 	ASExternalLSA uint8 = 5
+	NSSALSA       uint8 = 7
+	OpaqueLinkLSA uint8 = 9
+	OpaqueAreaLSA uint8 = 10
+	OpaqueASLSA   uint8 = 11
 )
 
 type LsaKey struct {
@@ -55,6 +59,11 @@ type LSDatabase struct {
 	Summary3LsaMap   map[LsaKey]SummaryLsa
 	Summary4LsaMap   map[LsaKey]SummaryLsa
 	ASExternalLsaMap map[LsaKey]ASExternalLsa
+	NssaLsaMap       map[LsaKey]ASExternalLsa
+	// Rfc 5250 Opaque LSAs
+	OpaqueLinkLsaMap map[OpaqueLinkLsaKey]OpaqueLsa
+	OpaqueAreaLsaMap map[LsaKey]OpaqueLsa
+	OpaqueASLsaMap   map[LsaKey]OpaqueLsa
 }
 
 type SelfOrigLsa map[LsaKey]bool
@@ -70,6 +79,9 @@ type RouteInfo struct {
 	NwAddr  uint32
 	Netmask uint32
 	Metric  uint32
+	BitE    bool // Type 2 external metric
+	Tag     uint32
+	FwdAddr uint32
 }
 
 type LsdbStruct struct {
@@ -78,4 +90,6 @@ type LsdbStruct struct {
 	LsdbCtrlChData  LsdbCtrlChStruct
 	LsdbAgingTicker *time.Ticker
 	ExtRouteInfoMap map[RouteInfo]bool
+	// AS External LSAs translated from Type-7 LSAs
+	NssaTranslatedLsaMap map[LsaKey]ASExternalLsa
 }
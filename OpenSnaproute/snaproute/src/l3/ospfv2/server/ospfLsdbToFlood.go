@@ -33,6 +33,12 @@ func (server *OSPFV2Server) SendMsgFromLsdbToFloodLsa(msg []LsdbToFloodLSAMsg) {
 }
 
 func (server *OSPFV2Server) CreateAndSendMsgFromLsdbToFloodLsa(areaId uint32, lsaKey LsaKey, lsa interface{}) {
+	// Self originated LSAs are held back while restarting gracefully,
+	// they are reoriginated once the restart is over
+	if lsaKey.AdvRouter == server.globalData.RouterId &&
+		server.isGracefulRestartInProgress() {
+		return
+	}
 	msgData := LsdbToFloodLSAMsg{
 		AreaId:  areaId,
 		LsaKey:  lsaKey,
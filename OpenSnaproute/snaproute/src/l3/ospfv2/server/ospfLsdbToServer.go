@@ -34,3 +34,8 @@ func (server *OSPFV2Server) SendMsgFromLsdbToServerForInitAreaLsdbDone() {
 	server.logger.Info("Sending msg from Lsdb to Server for Init Area Lsdb Done:")
 	server.MessagingChData.LsdbToServerChData.InitAreaLsdbDoneCh <- true
 }
+
+func (server *OSPFV2Server) SendMsgFromLsdbToServerForVirtualLinkChange(msg VirtualLinkChangeMsg) {
+	server.logger.Info("Sending msg from Lsdb to Server for Virtual Link change:", msg)
+	server.MessagingChData.LsdbToServerChData.VirtualLinkChangeCh <- msg
+}
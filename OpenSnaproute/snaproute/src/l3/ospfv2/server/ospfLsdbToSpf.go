@@ -25,7 +25,7 @@ package server
 
 import ()
 
-func (server *OSPFV2Server) SendMsgToStartSpf() {
-	server.logger.Info("Sending msg from Lsdb To Spf:")
-	server.MessagingChData.LsdbToSPFChData.StartSPF <- true
+func (server *OSPFV2Server) SendMsgToStartSpf(msg SpfCalcMsg) {
+	server.logger.Info("Sending msg from Lsdb To Spf:", msg)
+	server.MessagingChData.LsdbToSPFChData.StartSPF <- msg
 }
@@ -83,9 +83,8 @@ func (server *OSPFV2Server) CalcInterAreaRoutes(areaId uint32) {
 		if rEnt.NumOfPaths == 0 {
 			continue
 		}
-		cost := rEnt.Cost + uint16(lsaEnt.Metric)
+		cost := rEnt.Cost + lsaEnt.Metric
 		nextHopMap := rEnt.NextHops
-		numOfNextHops := rEnt.NumOfPaths
 		rKey = RoutingTblEntryKey{
 			DestId:   lsaKey.LSId & lsaEnt.Netmask,
 			AddrMask: lsaEnt.Netmask,
@@ -108,23 +107,10 @@ func (server *OSPFV2Server) CalcInterAreaRoutes(areaId uint32) {
 				rEnt.Cost = cost
 				//rEnt.Type2Cost = 0
 				//rEnt.LSOrigin = lsaKey
-				rEnt.NumOfPaths = numOfNextHops
-				rEnt.NextHops = make(map[NextHop]bool)
-				for key, _ := range nextHopMap {
-					key.AdvRtr = lsaKey.AdvRouter
-					rEnt.NextHops[key] = true
-				}
+				setNextHops(&rEnt, nextHopMap, lsaKey.AdvRouter)
 			} else {
-				cnt := 0
-				for key, _ := range nextHopMap {
-					_, exist = rEnt.NextHops[key]
-					if !exist {
-						key.AdvRtr = lsaKey.AdvRouter
-						rEnt.NextHops[key] = true
-						cnt++
-					}
-				}
-				rEnt.NumOfPaths = numOfNextHops + cnt
+				// Equal cost path, add its next hops
+				mergeNextHops(&rEnt, nextHopMap, lsaKey.AdvRouter)
 			}
 		} else {
 			rEnt.OptCapabilities = 0 //TODO
@@ -132,12 +118,7 @@ func (server *OSPFV2Server) CalcInterAreaRoutes(areaId uint32) {
 			rEnt.Cost = cost
 			rEnt.Type2Cost = 0
 			//rEnt.LSOrigin = lsaKey
-			rEnt.NumOfPaths = numOfNextHops
-			rEnt.NextHops = make(map[NextHop]bool)
-			for key, _ := range nextHopMap {
-				key.AdvRtr = lsaKey.AdvRouter
-				rEnt.NextHops[key] = true
-			}
+			setNextHops(&rEnt, nextHopMap, lsaKey.AdvRouter)
 		}
 		tempAreaRoutingTbl.RoutingTblMap[rKey] = rEnt
 		server.RoutingTblData.TempAreaRoutingTbl[areaIdKey] = tempAreaRoutingTbl
@@ -163,6 +144,7 @@ func (server *OSPFV2Server) HandleTransitAreaSummaryLsa() {
 func (server *OSPFV2Server) GenerateSummaryLsa() {
 	server.logger.Info("Generating Summary LSA")
 	server.SummaryLsDb = make(map[LsdbKey]SummaryLsaMap)
+	activeRanges := server.getActiveAreaRanges()
 	for areaId, aEnt := range server.AreaConfMap {
 		if len(aEnt.IntfMap) == 0 {
 			continue
@@ -172,6 +154,8 @@ func (server *OSPFV2Server) GenerateSummaryLsa() {
 			AreaId: areaId,
 		}
 		isStub, _ := server.isStubArea(areaId)
+		isNssa := server.isNssaArea(areaId)
+		isTotallyStub := server.isTotallyStubArea(areaId)
 
 		sEnt, _ := server.SummaryLsDb[lsDbKey]
 		sEnt = make(map[LsaKey]SummaryLsa)
@@ -190,6 +174,11 @@ func (server *OSPFV2Server) GenerateSummaryLsa() {
 				// 6. TODO: Distance Vector Split Horizon Problem
 				continue
 			}
+			if isTotallyStub {
+				// Totally stub areas only receive the default
+				// summary LSA
+				continue
+			}
 
 			// TODO: AS External Routes
 			// If DestType == ASBdrRouter
@@ -224,7 +213,11 @@ func (server *OSPFV2Server) GenerateSummaryLsa() {
 				sEnt[lsaKey] = summaryLsa
 			} else if rKey.DestType == Network &&
 				rEnt.RoutingTblEnt.PathType == IntraArea {
-				//TODO: Address Range
+				// Networks falling in an address range of their
+				// area are advertised by the range (RFC 2328 12.4.3)
+				if _, found := server.getAreaRangeForRoute(rEnt.AreaId, rKey); found {
+					continue
+				}
 				// By default LSId = network's address
 				// Metric = Routing Table cost
 				server.logger.Debug("Summary : generated summary 3 lsa ", rKey)
@@ -233,11 +226,16 @@ func (server *OSPFV2Server) GenerateSummaryLsa() {
 			}
 		}
 
+		if !isTotallyStub {
+			server.generateAreaRangeSummaryLsa(areaId, activeRanges, sEnt)
+		}
 		server.SummaryLsDb[lsDbKey] = sEnt
-		if isStub {
+		// NSSA gets the Type-7 default instead (Rfc 3101 2.7)
+		if isStub && !isNssa {
 			lsaKey, defsummaryLsa := server.GenerateDefaultSummary3LSA(lsDbKey)
 			sEnt[lsaKey] = defsummaryLsa
 		}
+		server.applyStubRouterSummaryMetric(sEnt)
 	}
 }
 
@@ -256,17 +254,11 @@ func (server *OSPFV2Server) GenerateSummaryLsa() {
 func (server *OSPFV2Server) GenerateDefaultSummary3LSA(lsDbKey LsdbKey) (LsaKey, SummaryLsa) {
 	var summaryLsa SummaryLsa
 	seq_num := int(InitialSequenceNum)
-	metric := int32(20)
-	/* TODO
-	   areaConfKey := AreaConfKey{
-	           AreaId: config.AreaId(convertUint32ToIPv4(lsDbKey.AreaId)),
-	   }
-
-	   conf, exist := server.AreaConfMap[lsDbKey.AreaId]
-	   if exist {
-	           metric = conf.StubDefaultCost
-	   }
-	*/
+	metric := OSPF_DEFAULT_STUB_COST
+	conf, exist := server.AreaConfMap[lsDbKey.AreaId]
+	if exist {
+		metric = conf.StubDefaultCost
+	}
 	AdvRouter := server.globalData.RouterId
 	lsaKey := LsaKey{
 		LSType:    Summary3LSA,
@@ -287,7 +279,7 @@ func (server *OSPFV2Server) GenerateDefaultSummary3LSA(lsDbKey LsdbKey) (LsaKey,
 	summaryLsa.LsaMd.LSSequenceNum = seq_num
 	summaryLsa.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + 8)
 	summaryLsa.Netmask = 0
-	summaryLsa.Metric = uint32(metric)
+	summaryLsa.Metric = metric
 
 	return lsaKey, summaryLsa
 }
@@ -312,7 +304,7 @@ func (server *OSPFV2Server) GenerateType3SummaryLSA(rKey RoutingTblEntryKey, rEn
 		}
 	}
 
-	summaryLsa.LsaMd.Options = uint8(2) //Need to be re-visited
+	summaryLsa.LsaMd.Options = server.getAreaLsaOptions(lsDbKey.AreaId)
 	summaryLsa.LsaMd.LSAge = 0
 	summaryLsa.LsaMd.LSSequenceNum = seq_num
 	summaryLsa.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + 8)
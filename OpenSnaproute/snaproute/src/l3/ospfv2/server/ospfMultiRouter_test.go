@@ -0,0 +1,727 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"l3/ospfv2/objects"
+	"net"
+	"sync"
+	"testing"
+	"time"
+	"utils/logging"
+)
+
+const (
+	testHelloInterval   uint16 = 1
+	testRtrDeadInterval uint32 = 4
+	testIntfCost        uint32 = 10
+	testConvergeTimeout        = 30 * time.Second
+)
+
+/*
+testRouter is an OSPFV2Server without asicd, ribd and DB connections
+whose interfaces are attached to virtual wires
+*/
+type testRouter struct {
+	server   *OSPFV2Server
+	pktIO    *VirtualPktIO
+	ifIdx    int32
+	routines sync.WaitGroup
+}
+
+func newTestRouter(t *testing.T, rtrId string) *testRouter {
+	pktIO := NewVirtualPktIO()
+	server, err := NewOspfv2Server(InitParams{
+		Logger: new(logging.Writer),
+		PktIO:  pktIO,
+	})
+	if err != nil {
+		t.Fatal("Unable to create ospf server", err)
+	}
+	server.initMessagingChData()
+	server.initInfra()
+	server.initRibdComm()
+	server.initBfdComm()
+
+	rtr := &testRouter{
+		server: server,
+		pktIO:  pktIO,
+	}
+	rtr.routines.Add(2)
+	go func() {
+		server.StartDBClient()
+		rtr.routines.Done()
+	}()
+	go func() {
+		server.serverLoop()
+		rtr.routines.Done()
+	}()
+	routerId, _ := convertDotNotationToUint32(rtrId)
+	rtr.createConfig(t, CREATE_OSPFV2_GLOBAL, &CreateOspfv2GlobalInArgs{
+		Cfg: &objects.Ospfv2Global{
+			Vrf:                "default",
+			RouterId:           routerId,
+			AdminState:         objects.GLOBAL_ADMIN_STATE_UP,
+			ReferenceBandwidth: 100,
+			RestartSupport:     objects.RESTART_SUPPORT_NONE,
+			MaximumPaths:       8,
+			SpfInitialWait:     50,
+			SpfHoldWait:        200,
+			SpfMaxWait:         5000,
+		},
+	})
+	rtr.createConfig(t, CREATE_OSPFV2_AREA, &CreateOspfv2AreaInArgs{
+		Cfg: &objects.Ospfv2Area{
+			AreaId:         0,
+			AdminState:     objects.AREA_ADMIN_STATE_UP,
+			AuthType:       objects.AUTH_TYPE_NONE,
+			AreaType:       objects.AREA_TYPE_NORMAL,
+			ImportASExtern: true,
+		},
+	})
+	return rtr
+}
+
+/*
+close disables ospf on the router, which stops the protocol
+routines, and waits for the server routines to stop
+*/
+func (rtr *testRouter) close(t *testing.T) {
+	rtr.updateGlobal(t, &objects.Ospfv2Global{AdminState: objects.GLOBAL_ADMIN_STATE_DOWN}, 2)
+	rtr.server.stopServer()
+	done := make(chan bool)
+	go func() {
+		rtr.routines.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(testConvergeTimeout):
+		t.Fatal("Timed out waiting for the server routines to stop")
+	}
+}
+
+func (rtr *testRouter) request(op ServerOpId, data interface{}) interface{} {
+	rtr.server.ReqChan <- &ServerRequest{
+		Op:   op,
+		Data: data,
+	}
+	return <-rtr.server.ReplyChan
+}
+
+func (rtr *testRouter) createConfig(t *testing.T, op ServerOpId, data interface{}) {
+	ret := rtr.request(op, data)
+	retObj, ok := ret.(*CreateConfigOutArgs)
+	if !ok {
+		t.Fatal("Unexpected reply to config create", op, ret)
+	}
+	if !retObj.RetVal {
+		t.Fatal("Config create failed", op, retObj.Err)
+	}
+}
+
+// updateGlobal updates the global attribute at attrIdx in the model to its value in cfg
+func (rtr *testRouter) updateGlobal(t *testing.T, cfg *objects.Ospfv2Global, attrIdx int) {
+	attrSet := make([]bool, attrIdx+1)
+	attrSet[attrIdx] = true
+	ret := rtr.request(UPDATE_OSPFV2_GLOBAL, &UpdateOspfv2GlobalInArgs{
+		OldCfg:  &objects.Ospfv2Global{},
+		NewCfg:  cfg,
+		AttrSet: attrSet,
+	})
+	retObj, ok := ret.(*UpdateConfigOutArgs)
+	if !ok {
+		t.Fatal("Unexpected reply to global config update", ret)
+	}
+	if !retObj.RetVal {
+		t.Fatal("Global config update failed", retObj.Err)
+	}
+}
+
+// updateIntf updates the intf attribute at attrIdx in the model to its value in cfg
+func (rtr *testRouter) updateIntf(t *testing.T, cfg *objects.Ospfv2Intf, attrIdx int) {
+	attrSet := make([]bool, attrIdx+1)
+	attrSet[attrIdx] = true
+	ret := rtr.request(UPDATE_OSPFV2_INTF, &UpdateOspfv2IntfInArgs{
+		OldCfg:  &objects.Ospfv2Intf{},
+		NewCfg:  cfg,
+		AttrSet: attrSet,
+	})
+	retObj, ok := ret.(*UpdateConfigOutArgs)
+	if !ok {
+		t.Fatal("Unexpected reply to intf config update", ret)
+	}
+	if !retObj.RetVal {
+		t.Fatal("Intf config update failed", retObj.Err)
+	}
+}
+
+/*
+addIntf creates the L3 interface ifName, attaches it to the wire
+and enables ospf on it in the backbone area
+*/
+func (rtr *testRouter) addIntf(t *testing.T, ifName, ipAddr string, maskLen int, rtrPriority uint8, wire *VirtualWire) {
+	ip := rtr.addL3Intf(ifName, ipAddr, maskLen, wire)
+	rtr.createConfig(t, CREATE_OSPFV2_INTF, &CreateOspfv2IntfInArgs{
+		Cfg: newTestIntfCfg(ip, rtrPriority, objects.INTF_PASSIVE_DEFAULT),
+	})
+}
+
+// addAreaIntf is addIntf in the non backbone area areaId
+func (rtr *testRouter) addAreaIntf(t *testing.T, ifName, ipAddr string, maskLen int, areaId uint32, wire *VirtualWire) {
+	ip := rtr.addL3Intf(ifName, ipAddr, maskLen, wire)
+	cfg := newTestIntfCfg(ip, 1, objects.INTF_PASSIVE_DEFAULT)
+	cfg.AreaId = areaId
+	rtr.createConfig(t, CREATE_OSPFV2_INTF, &CreateOspfv2IntfInArgs{
+		Cfg: cfg,
+	})
+}
+
+func (rtr *testRouter) addArea(t *testing.T, areaId uint32) {
+	rtr.createConfig(t, CREATE_OSPFV2_AREA, &CreateOspfv2AreaInArgs{
+		Cfg: &objects.Ospfv2Area{
+			AreaId:         areaId,
+			AdminState:     objects.AREA_ADMIN_STATE_UP,
+			AuthType:       objects.AUTH_TYPE_NONE,
+			AreaType:       objects.AREA_TYPE_NORMAL,
+			ImportASExtern: true,
+		},
+	})
+}
+
+func (rtr *testRouter) addVirtualLink(t *testing.T, transitAreaId uint32, nbrRtrId string) {
+	rtrId, _ := convertDotNotationToUint32(nbrRtrId)
+	rtr.createConfig(t, CREATE_OSPFV2_VIRTUAL_LINK, &CreateOspfv2VirtualLinkInArgs{
+		Cfg: &objects.Ospfv2VirtualLink{
+			AreaId:          transitAreaId,
+			NbrRouterId:     rtrId,
+			AdminState:      true,
+			TransitDelay:    1,
+			RetransInterval: 2,
+			HelloInterval:   testHelloInterval,
+			RtrDeadInterval: testRtrDeadInterval,
+		},
+	})
+}
+
+func (rtr *testRouter) addPassiveIntf(t *testing.T, ifName, ipAddr string, maskLen int, passive uint8, wire *VirtualWire) {
+	ip := rtr.addL3Intf(ifName, ipAddr, maskLen, wire)
+	rtr.createConfig(t, CREATE_OSPFV2_INTF, &CreateOspfv2IntfInArgs{
+		Cfg: newTestIntfCfg(ip, 1, passive),
+	})
+}
+
+func (rtr *testRouter) addL3Intf(ifName, ipAddr string, maskLen int, wire *VirtualWire) uint32 {
+	rtr.pktIO.Connect(ifName, wire)
+	rtr.ifIdx++
+	ip, _ := convertDotNotationToUint32(ipAddr)
+	rtr.server.infraData.ipPropertyMap[rtr.ifIdx] = IpProperty{
+		IfId:    uint32(rtr.ifIdx),
+		IfName:  ifName,
+		IpAddr:  ip,
+		NetMask: uint32(0xffffffff) << uint(32-maskLen),
+		MacAddr: net.HardwareAddr{0x00, 0x00, byte(ip >> 24), byte(ip >> 16), byte(ip >> 8), byte(ip)},
+		Mtu:     1500,
+		State:   true,
+	}
+	rtr.server.infraData.ipToIfIdxMap[ip] = rtr.ifIdx
+	return ip
+}
+
+func newTestIntfCfg(ip uint32, rtrPriority, passive uint8) *objects.Ospfv2Intf {
+	return &objects.Ospfv2Intf{
+		IpAddress:       ip,
+		AdminState:      objects.INTF_ADMIN_STATE_UP,
+		AreaId:          0,
+		Type:            objects.INTF_TYPE_BROADCAST,
+		RtrPriority:     rtrPriority,
+		TransitDelay:    1,
+		RetransInterval: 2,
+		HelloInterval:   testHelloInterval,
+		RtrDeadInterval: testRtrDeadInterval,
+		MetricValue:     uint16(testIntfCost),
+		Passive:         passive,
+	}
+}
+
+func (rtr *testRouter) getNbrState(nbrIpAddr string) *objects.Ospfv2NbrState {
+	ip, _ := convertDotNotationToUint32(nbrIpAddr)
+	ret := rtr.request(GET_OSPFV2_NBR_STATE, &GetOspfv2NbrStateInArgs{
+		IpAddr: ip,
+	})
+	retObj, ok := ret.(*GetOspfv2NbrStateOutArgs)
+	if !ok || retObj.Err != nil {
+		return nil
+	}
+	return retObj.Obj
+}
+
+func (rtr *testRouter) getIntfState(ipAddr string) *objects.Ospfv2IntfState {
+	ip, _ := convertDotNotationToUint32(ipAddr)
+	ret := rtr.request(GET_OSPFV2_INTF_STATE, &GetOspfv2IntfStateInArgs{
+		IpAddr: ip,
+	})
+	retObj, ok := ret.(*GetOspfv2IntfStateOutArgs)
+	if !ok || retObj.Err != nil {
+		return nil
+	}
+	return retObj.Obj
+}
+
+func (rtr *testRouter) getVirtualLinkIntfState(transitAreaId uint32, nbrRtrId string) *objects.Ospfv2IntfState {
+	rtrId, _ := convertDotNotationToUint32(nbrRtrId)
+	ret := rtr.request(GET_OSPFV2_INTF_STATE, &GetOspfv2IntfStateInArgs{
+		IpAddr:           rtrId,
+		AddressLessIfIdx: transitAreaId,
+	})
+	retObj, ok := ret.(*GetOspfv2IntfStateOutArgs)
+	if !ok || retObj.Err != nil {
+		return nil
+	}
+	return retObj.Obj
+}
+
+func (rtr *testRouter) getGlobalState() *objects.Ospfv2GlobalState {
+	ret := rtr.request(GET_OSPFV2_GLOBAL_STATE, &GetOspfv2GlobalStateInArgs{
+		Vrf: "default",
+	})
+	retObj, ok := ret.(*GetOspfv2GlobalStateOutArgs)
+	if !ok || retObj.Err != nil {
+		return nil
+	}
+	return retObj.Obj
+}
+
+func (rtr *testRouter) getAreaState(areaId uint32) *objects.Ospfv2AreaState {
+	ret := rtr.request(GET_OSPFV2_AREA_STATE, &GetOspfv2AreaStateInArgs{
+		AreaId: areaId,
+	})
+	retObj, ok := ret.(*GetOspfv2AreaStateOutArgs)
+	if !ok || retObj.Err != nil {
+		return nil
+	}
+	return retObj.Obj
+}
+
+// getVirtualNbrState returns the nbr over the virtual link, it is known by the address it sends from
+func (rtr *testRouter) getVirtualNbrState(transitAreaId uint32, nbrRtrId string) *objects.Ospfv2NbrState {
+	rtrId, _ := convertDotNotationToUint32(nbrRtrId)
+	ret := rtr.request(GET_BULK_OSPFV2_NBR_STATE, &GetBulkInArgs{
+		FromIdx: 0,
+		Count:   100,
+	})
+	retObj, ok := ret.(*GetBulkOspfv2NbrStateOutArgs)
+	if !ok || retObj.Err != nil {
+		return nil
+	}
+	for _, nbr := range retObj.BulkInfo.List {
+		if nbr.RtrId == rtrId && nbr.AddressLessIfIdx == transitAreaId {
+			return nbr
+		}
+	}
+	return nil
+}
+
+func (rtr *testRouter) isNbrFull(nbrIpAddr string) bool {
+	nbr := rtr.getNbrState(nbrIpAddr)
+	return nbr != nil && nbr.State == uint8(NbrFull)
+}
+
+func (rtr *testRouter) isIntfState(ipAddr string, state uint8) bool {
+	intfState := rtr.getIntfState(ipAddr)
+	return intfState != nil && intfState.State == state
+}
+
+// getRoute returns the installed route to the network, the routing table is swapped as a whole after SPF
+func (rtr *testRouter) getRoute(network string, maskLen int) (GlobalRoutingTblEntry, bool) {
+	destId, _ := convertDotNotationToUint32(network)
+	rKey := RoutingTblEntryKey{
+		DestId:   destId,
+		AddrMask: uint32(0xffffffff) << uint(32-maskLen),
+		DestType: Network,
+	}
+	routingTbl := rtr.server.RoutingTblData.GlobalRoutingTbl
+	rEnt, exist := routingTbl[rKey]
+	return rEnt, exist
+}
+
+func waitFor(t *testing.T, desc string, cond func() bool) {
+	deadline := time.Now().Add(testConvergeTimeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for", desc)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func waitForAdjacency(t *testing.T, rtr1 *testRouter, ipAddr1 string, rtr2 *testRouter, ipAddr2 string) {
+	waitFor(t, "adjacency "+ipAddr1+"-"+ipAddr2, func() bool {
+		return rtr1.isNbrFull(ipAddr2) && rtr2.isNbrFull(ipAddr1)
+	})
+}
+
+/*
+testLine is a chain of routers, A and B share lan1 (10.0.1.0/24),
+B and C share lan2 (10.0.2.0/24) and C has a stub network 30.0.0.0/24
+*/
+type testLine struct {
+	lan1 *VirtualWire
+	lan2 *VirtualWire
+	rtrA *testRouter
+	rtrB *testRouter
+	rtrC *testRouter
+}
+
+/*
+newTestLine brings up the routers with B's lan2 interface
+configured with the passive setting, B is DR on both networks.
+C is adjacent to B unless the interface is passive.
+*/
+func newTestLine(t *testing.T, passive uint8) *testLine {
+	line := &testLine{
+		lan1: NewVirtualWire(),
+		lan2: NewVirtualWire(),
+	}
+	defer func() {
+		if t.Failed() {
+			line.close(t)
+		}
+	}()
+	line.rtrA = newTestRouter(t, "1.1.1.1")
+	line.rtrB = newTestRouter(t, "2.2.2.2")
+	line.rtrC = newTestRouter(t, "3.3.3.3")
+	line.rtrA.addIntf(t, "eth0", "10.0.1.1", 24, 1, line.lan1)
+	line.rtrB.addPassiveIntf(t, "eth1", "10.0.2.2", 24, passive, line.lan2)
+	waitFor(t, "A and B to become DR", func() bool {
+		return line.rtrA.isIntfState("10.0.1.1", objects.INTF_FSM_STATE_DR) &&
+			line.rtrB.isIntfState("10.0.2.2", objects.INTF_FSM_STATE_DR)
+	})
+	line.rtrB.addIntf(t, "eth0", "10.0.1.2", 24, 1, line.lan1)
+	waitForAdjacency(t, line.rtrA, "10.0.1.1", line.rtrB, "10.0.1.2")
+	line.rtrC.addIntf(t, "eth0", "10.0.2.3", 24, 1, line.lan2)
+	line.rtrC.addIntf(t, "eth1", "30.0.0.3", 24, 1, NewVirtualWire())
+	if passive != objects.INTF_PASSIVE_TRUE {
+		waitForAdjacency(t, line.rtrB, "10.0.2.2", line.rtrC, "10.0.2.3")
+	}
+	return line
+}
+
+func (line *testLine) close(t *testing.T) {
+	for _, rtr := range []*testRouter{line.rtrA, line.rtrB, line.rtrC} {
+		if rtr != nil {
+			rtr.close(t)
+		}
+	}
+	line.lan1.SetLinkUp(false)
+	line.lan2.SetLinkUp(false)
+}
+
+/*
+The tests bring up one adjacency at a time, the DR is the first router
+up on the network and is kept when routers with a higher router id join
+*/
+func TestTwoRouterAdjacency(t *testing.T) {
+	lan := NewVirtualWire()
+	defer lan.SetLinkUp(false)
+	rtrA := newTestRouter(t, "1.1.1.1")
+	defer rtrA.close(t)
+	rtrB := newTestRouter(t, "2.2.2.2")
+	defer rtrB.close(t)
+	rtrA.addIntf(t, "eth0", "10.0.0.1", 24, 1, lan)
+	rtrA.addIntf(t, "eth1", "20.0.0.1", 24, 1, NewVirtualWire())
+	waitFor(t, "A to become DR", func() bool {
+		return rtrA.isIntfState("10.0.0.1", objects.INTF_FSM_STATE_DR)
+	})
+	rtrB.addIntf(t, "eth0", "10.0.0.2", 24, 1, lan)
+	waitForAdjacency(t, rtrA, "10.0.0.1", rtrB, "10.0.0.2")
+	waitFor(t, "B to become BDR", func() bool {
+		return rtrB.isIntfState("10.0.0.2", objects.INTF_FSM_STATE_BDR)
+	})
+
+	drId, _ := convertDotNotationToUint32("1.1.1.1")
+	intfState := rtrB.getIntfState("10.0.0.2")
+	if intfState == nil || intfState.DesignatedRouterId != drId {
+		t.Error("Router 1.1.1.1 is not kept as DR", intfState)
+	}
+
+	waitFor(t, "route to 20.0.0.0/24 on B", func() bool {
+		_, exist := rtrB.getRoute("20.0.0.0", 24)
+		return exist
+	})
+	rEnt, _ := rtrB.getRoute("20.0.0.0", 24)
+	if rEnt.RoutingTblEnt.Cost != 2*testIntfCost {
+		t.Error("Wrong cost for 20.0.0.0/24:", rEnt.RoutingTblEnt.Cost)
+	}
+}
+
+func TestThreeRouterDrElection(t *testing.T) {
+	lan := NewVirtualWire()
+	defer lan.SetLinkUp(false)
+	rtrA := newTestRouter(t, "1.1.1.1")
+	defer rtrA.close(t)
+	rtrB := newTestRouter(t, "2.2.2.2")
+	defer rtrB.close(t)
+	rtrC := newTestRouter(t, "3.3.3.3")
+	defer rtrC.close(t)
+	rtrA.addIntf(t, "eth0", "10.0.0.1", 24, 1, lan)
+	waitFor(t, "A to become DR", func() bool {
+		return rtrA.isIntfState("10.0.0.1", objects.INTF_FSM_STATE_DR)
+	})
+	rtrB.addIntf(t, "eth0", "10.0.0.2", 24, 1, lan)
+	waitForAdjacency(t, rtrA, "10.0.0.1", rtrB, "10.0.0.2")
+	// Priority 0, never DR or BDR
+	rtrC.addIntf(t, "eth0", "10.0.0.3", 24, 0, lan)
+	waitForAdjacency(t, rtrA, "10.0.0.1", rtrC, "10.0.0.3")
+	waitForAdjacency(t, rtrB, "10.0.0.2", rtrC, "10.0.0.3")
+	waitFor(t, "B to become BDR and C DROther", func() bool {
+		return rtrB.isIntfState("10.0.0.2", objects.INTF_FSM_STATE_BDR) &&
+			rtrC.isIntfState("10.0.0.3", objects.INTF_FSM_STATE_OTHER_DR)
+	})
+
+	drId, _ := convertDotNotationToUint32("1.1.1.1")
+	bdrId, _ := convertDotNotationToUint32("2.2.2.2")
+	for ipAddr, rtr := range map[string]*testRouter{"10.0.0.1": rtrA, "10.0.0.2": rtrB, "10.0.0.3": rtrC} {
+		intfState := rtr.getIntfState(ipAddr)
+		if intfState == nil {
+			t.Error("No interface state for", ipAddr)
+			continue
+		}
+		if intfState.DesignatedRouterId != drId ||
+			intfState.BackupDesignatedRouterId != bdrId {
+			t.Error("Wrong DR/BDR on", ipAddr, intfState.DesignatedRouterId, intfState.BackupDesignatedRouterId)
+		}
+	}
+	if !rtrA.isIntfState("10.0.0.1", objects.INTF_FSM_STATE_DR) {
+		t.Error("Router 1.1.1.1 is not kept as DR")
+	}
+}
+
+func TestFloodingAndSpf(t *testing.T) {
+	line := newTestLine(t, objects.INTF_PASSIVE_DEFAULT)
+	defer line.close(t)
+
+	// C's stub network is flooded through B and reached over two transit networks
+	waitFor(t, "route to 30.0.0.0/24 on A", func() bool {
+		_, exist := line.rtrA.getRoute("30.0.0.0", 24)
+		return exist
+	})
+	rEnt, _ := line.rtrA.getRoute("30.0.0.0", 24)
+	if rEnt.RoutingTblEnt.Cost != 3*testIntfCost {
+		t.Error("Wrong cost for 30.0.0.0/24:", rEnt.RoutingTblEnt.Cost)
+	}
+
+	// Losing the B-C link withdraws the route once the adjacency times out
+	line.lan2.SetLinkUp(false)
+	waitFor(t, "route to 30.0.0.0/24 withdrawn on A", func() bool {
+		_, exist := line.rtrA.getRoute("30.0.0.0", 24)
+		return !exist
+	})
+}
+
+func TestPassiveIntf(t *testing.T) {
+	line := newTestLine(t, objects.INTF_PASSIVE_TRUE)
+	defer line.close(t)
+
+	// The passive subnet is advertised as a stub network
+	waitFor(t, "route to 10.0.2.0/24 on A", func() bool {
+		_, exist := line.rtrA.getRoute("10.0.2.0", 24)
+		return exist
+	})
+	rEnt, _ := line.rtrA.getRoute("10.0.2.0", 24)
+	if rEnt.RoutingTblEnt.Cost != 2*testIntfCost {
+		t.Error("Wrong cost for 10.0.2.0/24:", rEnt.RoutingTblEnt.Cost)
+	}
+
+	// No hellos are exchanged on the passive interface
+	waitFor(t, "hellos from C", func() bool {
+		intfState := line.rtrC.getIntfState("10.0.2.3")
+		return intfState != nil && intfState.NumOfHelloTx > 2
+	})
+	if intfState := line.rtrC.getIntfState("10.0.2.3"); intfState.NumOfHelloRx != 0 {
+		t.Error("Hellos received from passive interface", intfState.NumOfHelloRx)
+	}
+	if nbr := line.rtrC.getNbrState("10.0.2.2"); nbr != nil {
+		t.Error("Nbr discovered over passive interface", nbr)
+	}
+	if nbr := line.rtrB.getNbrState("10.0.2.3"); nbr != nil {
+		t.Error("Nbr discovered on passive interface", nbr)
+	}
+}
+
+func TestPassiveIntfDefault(t *testing.T) {
+	line := newTestLine(t, objects.INTF_PASSIVE_FALSE)
+	defer line.close(t)
+
+	// Only the interfaces following the default become passive
+	line.rtrB.updateGlobal(t, &objects.Ospfv2Global{PassiveIntfDefault: true}, 13)
+	waitFor(t, "adjacency 10.0.1.1-10.0.1.2 down", func() bool {
+		return line.rtrA.getNbrState("10.0.1.2") == nil &&
+			line.rtrB.getNbrState("10.0.1.1") == nil
+	})
+	if !line.rtrB.isNbrFull("10.0.2.3") {
+		t.Error("Adjacency over non passive interface is down")
+	}
+}
+
+func TestStubRouter(t *testing.T) {
+	line := newTestLine(t, objects.INTF_PASSIVE_DEFAULT)
+	defer line.close(t)
+	waitFor(t, "route to 30.0.0.0/24 on A", func() bool {
+		rEnt, exist := line.rtrA.getRoute("30.0.0.0", 24)
+		return exist && rEnt.RoutingTblEnt.Cost == 3*testIntfCost
+	})
+
+	// B stays reachable but its transit links carry the maximum metric
+	line.rtrB.updateGlobal(t, &objects.Ospfv2Global{StubRouterAdmin: true}, 14)
+	waitFor(t, "max metric route to 30.0.0.0/24 on A", func() bool {
+		rEnt, exist := line.rtrA.getRoute("30.0.0.0", 24)
+		return exist && rEnt.RoutingTblEnt.Cost == 2*testIntfCost+uint32(MaxLinkMetric)
+	})
+	state := line.rtrB.getGlobalState()
+	if state == nil || state.StubRouterStatus != objects.STUB_ROUTER_STATUS_ADMINISTRATIVE {
+		t.Error("Wrong stub router state", state)
+	}
+
+	line.rtrB.updateGlobal(t, &objects.Ospfv2Global{StubRouterAdmin: false}, 14)
+	waitFor(t, "route to 30.0.0.0/24 on A restored", func() bool {
+		rEnt, exist := line.rtrA.getRoute("30.0.0.0", 24)
+		return exist && rEnt.RoutingTblEnt.Cost == 3*testIntfCost
+	})
+	state = line.rtrB.getGlobalState()
+	if state == nil || state.StubRouterStatus != objects.STUB_ROUTER_STATUS_NONE {
+		t.Error("Wrong stub router state", state)
+	}
+}
+
+func TestPktStats(t *testing.T) {
+	line := newTestLine(t, objects.INTF_PASSIVE_DEFAULT)
+	defer line.close(t)
+	rtrA := line.rtrA
+
+	intfState := rtrA.getIntfState("10.0.1.1")
+	if intfState == nil || intfState.NumOfHelloRx == 0 || intfState.NumOfHelloTx == 0 ||
+		intfState.NumOfDbdRx == 0 || intfState.NumOfDbdTx == 0 {
+		t.Error("Wrong interface packet counters", intfState)
+	}
+	drIp, _ := convertDotNotationToUint32("10.0.1.1")
+	nbr := rtrA.getNbrState("10.0.1.2")
+	if nbr == nil || nbr.NumOfHelloRx == 0 || nbr.NumOfHelloTx == 0 ||
+		nbr.NumOfDbdRx == 0 || nbr.NumOfDbdTx == 0 {
+		t.Fatal("Wrong nbr packet counters", nbr)
+	}
+	if nbr.LastStateChangeReason != objects.NBR_EVENT_EXCHANGE_DONE &&
+		nbr.LastStateChangeReason != objects.NBR_EVENT_LOADING_DONE {
+		t.Error("Wrong nbr state change reason", nbr.LastStateChangeReason)
+	}
+	if nbr.DeadTimerRemaining > testRtrDeadInterval {
+		t.Error("Wrong nbr dead timer", nbr.DeadTimerRemaining)
+	}
+	// B advertises the DR once it leaves the waiting state
+	waitFor(t, "DR advertised by B", func() bool {
+		nbr := rtrA.getNbrState("10.0.1.2")
+		return nbr != nil && nbr.DesignatedRouter == drIp
+	})
+
+	// Hellos from C are dropped and C never becomes a nbr
+	ip := line.rtrC.addL3Intf("eth2", "10.0.1.3", 24, line.lan1)
+	cfg := newTestIntfCfg(ip, 1, objects.INTF_PASSIVE_DEFAULT)
+	cfg.HelloInterval = 2 * testHelloInterval
+	line.rtrC.createConfig(t, CREATE_OSPFV2_INTF, &CreateOspfv2IntfInArgs{
+		Cfg: cfg,
+	})
+	waitFor(t, "hello interval mismatch on A", func() bool {
+		intfState := rtrA.getIntfState("10.0.1.1")
+		return intfState != nil && intfState.NumOfHelloIntvlMismatch > 0
+	})
+	if nbr := rtrA.getNbrState("10.0.1.3"); nbr != nil {
+		t.Error("Nbr formed despite hello interval mismatch", nbr)
+	}
+}
+
+/*
+DBDs advertising an MTU larger than the interface MTU are
+rejected and the adjacency does not get past ExStart.
+*/
+func TestDbdMtuMismatch(t *testing.T) {
+	line := newTestLine(t, objects.INTF_PASSIVE_DEFAULT)
+	defer line.close(t)
+	rtrA := line.rtrA
+
+	ip := line.rtrC.addL3Intf("eth2", "10.0.1.3", 24, line.lan1)
+	ipEnt := line.rtrC.server.infraData.ipPropertyMap[line.rtrC.ifIdx]
+	ipEnt.Mtu = 9000
+	line.rtrC.server.infraData.ipPropertyMap[line.rtrC.ifIdx] = ipEnt
+	line.rtrC.createConfig(t, CREATE_OSPFV2_INTF, &CreateOspfv2IntfInArgs{
+		Cfg: newTestIntfCfg(ip, 1, objects.INTF_PASSIVE_DEFAULT),
+	})
+	waitFor(t, "MTU mismatch on A", func() bool {
+		nbr := rtrA.getNbrState("10.0.1.3")
+		return nbr != nil && nbr.NumOfMtuMismatch > 0
+	})
+	intfState := rtrA.getIntfState("10.0.1.1")
+	if intfState == nil || intfState.NumOfMtuMismatch == 0 {
+		t.Error("MTU mismatch not counted on interface", intfState)
+	}
+	nbr := rtrA.getNbrState("10.0.1.3")
+	if nbr == nil || nbr.State != uint8(NbrExchangeStart) {
+		t.Error("Nbr past ExStart despite MTU mismatch", nbr)
+	}
+	if line.rtrC.isNbrFull("10.0.1.1") {
+		t.Error("Nbr full on C despite MTU mismatch")
+	}
+	// The adjacency with a matching MTU is unaffected
+	if !rtrA.isNbrFull("10.0.1.2") {
+		t.Error("Adjacency 10.0.1.1-10.0.1.2 down")
+	}
+}
+
+func TestTestRouterClose(t *testing.T) {
+	lan := NewVirtualWire()
+	rtrA := newTestRouter(t, "1.1.1.1")
+	rtrB := newTestRouter(t, "2.2.2.2")
+	rtrA.addIntf(t, "eth0", "10.0.0.1", 24, 1, lan)
+	waitFor(t, "A to become DR", func() bool {
+		return rtrA.isIntfState("10.0.0.1", objects.INTF_FSM_STATE_DR)
+	})
+	rtrB.addIntf(t, "eth0", "10.0.0.2", 24, 1, lan)
+	waitForAdjacency(t, rtrA, "10.0.0.1", rtrB, "10.0.0.2")
+	pktIO := NewVirtualPktIO()
+	pktIO.Connect("eth0", lan)
+	_, rxHdl := openTestHandles(t, pktIO, "eth0", "10.0.0.3")
+	defer rxHdl.Close()
+	rtrA.close(t)
+	rtrB.close(t)
+
+	// Nothing is sent on the lan once the routers are closed
+	for {
+		if _, ok := readTestFrame(rxHdl); !ok {
+			break
+		}
+	}
+	time.Sleep(2 * time.Duration(testHelloInterval) * time.Second)
+	if _, ok := readTestFrame(rxHdl); ok {
+		t.Error("Packets sent after the routers were closed")
+	}
+}
@@ -200,6 +200,12 @@ func (server *OSPFV2Server) getNbrState(ipAddr, addressLessIfIdx uint32) (*objec
 	retObj.RtrId = nbr.NbrRtrId
 	retObj.State = uint8(nbr.State)
 	retObj.Options = int32(nbr.NbrOption)
+	retObj.RestartHelperStatus = nbr.GrHelperStatus
+	retObj.RestartHelperAge = server.getNbrGracefulRestartHelperAge(nbr)
+	retObj.RestartHelperExitReason = nbr.GrHelperExitReason
+	retObj.RestartResync = server.getNbrRestartResync(nbr.NbrRtrId)
+	retObj.BfdState = nbr.BfdState
+	server.fillNbrStats(&retObj, nbrKey, nbr)
 
 	return &retObj, nil
 }
@@ -229,6 +235,12 @@ func (server *OSPFV2Server) getBulkNbrState(fromIdx, cnt int) (*objects.Ospfv2Nb
 		obj.Options = int32(nbrEnt.NbrOption)
 		obj.RtrId = uint32(nbrEnt.NbrRtrId)
 		obj.State = uint8(nbrEnt.State)
+		obj.RestartHelperStatus = nbrEnt.GrHelperStatus
+		obj.RestartHelperAge = server.getNbrGracefulRestartHelperAge(nbrEnt)
+		obj.RestartHelperExitReason = nbrEnt.GrHelperExitReason
+		obj.RestartResync = server.getNbrRestartResync(nbrEnt.NbrRtrId)
+		obj.BfdState = nbrEnt.BfdState
+		server.fillNbrStats(&obj, nbrKey, nbrEnt)
 		retObj.List = append(retObj.List, &obj)
 		count++
 		idx++
@@ -54,6 +54,7 @@ func (server *OSPFV2Server) ProcessNbrFSM() {
 
 		case lsaAckData := <-server.NbrConfData.nbrLsaAckEventCh:
 			server.logger.Debug("Nbr: Received ack  ", lsaAckData)
+			server.DecodeLSAAck(lsaAckData)
 
 			//LSAReq received
 		case lsaReqData := <-server.NbrConfData.neighborLSAReqEventCh:
@@ -78,6 +79,21 @@ func (server *OSPFV2Server) ProcessNbrFSM() {
 				server.ProcessNbrDeadFromIntf(msg.IntfKey)
 			}
 
+		case nbrKey := <-server.NbrConfData.nbrGraceExpiryCh:
+			server.exitGracefulRestartHelper(nbrKey, objects.RESTART_EXIT_REASON_TIMED_OUT)
+
+		case msg := <-server.NbrConfData.nbrBfdStateCh:
+			server.ProcessNbrBfdState(msg)
+
+		case intfKey := <-server.NbrConfData.nbrBfdEnableCh:
+			server.ProcessIntfBfdEnable(intfKey)
+
+		case nbrKey := <-server.NbrConfData.nbrKillCh:
+			server.KillNbr(nbrKey)
+
+		case nbrKey := <-server.NbrConfData.nbrRetxCh:
+			server.retransmitToNbr(nbrKey)
+
 			//NbrFsmCtrlCh
 		case _ = <-server.NbrConfData.nbrFSMCtrlCh:
 			server.logger.Debug("Nbr : FSM stopping.. ")
@@ -137,12 +153,16 @@ func (server *OSPFV2Server) ProcessNbrHello(nbrData NbrHelloEventMsg) {
 		}
 	} else {
 		oldState = nbrConf.State
-		if nbrData.TwoWayStatus {
+		if nbrData.TwoWayStatus ||
+			nbrConf.GrHelperStatus == objects.RESTART_HELPER_STATUS_HELPING {
 			newState = NbrTwoWay
 		} else {
 			newState = NbrInit
 		}
 		//nbrConf.NbrDeadTimer.Reset(nbrConf.NbrDeadTimeDuration)
+		nbrConf.NbrDR = nbrData.NbrDRIpAddr
+		nbrConf.NbrBdr = nbrData.NbrBDRIpAddr
+		server.NbrConfMap[nbrKey] = nbrConf
 	}
 	server.logger.Debug("Nbr : oldstate", oldState, " newState ", newState, " state ", nbrConf.State)
 	if (oldState == NbrDown || oldState == NbrInit) &&
@@ -173,7 +193,7 @@ func (server *OSPFV2Server) ProcessNbrDbdMsg(dbdMsg NbrDbdMsg) {
 		server.ProcessNbrLoading(dbdMsg.nbrConfKey, nbrConf, dbdMsg.nbrDbdData)
 	case NbrFull:
 		server.logger.Err("Nbr: Received dbd packet when nbr is full . Restart FSM", dbdMsg.nbrConfKey)
-		nbrConf.State = NbrExchangeStart
+		setNbrState(&nbrConf, NbrExchangeStart, objects.NBR_EVENT_SEQ_NUMBER_MISMATCH)
 		server.ProcessNbrExstart(dbdMsg.nbrConfKey, nbrConf, dbdMsg.nbrDbdData)
 	case NbrDown:
 		server.logger.Warning("Nbr: Nbr is down state. Dont process dbd ", dbdMsg.nbrConfKey)
@@ -197,13 +217,18 @@ func (server *OSPFV2Server) CreateNewNbr(nbrData NbrHelloEventMsg) {
 	nbrConf.NbrRetxList = []*ospfLSAHeader{}
 	nbrConf.NbrDBSummaryList = []*ospfLSAHeader{}
 	nbrConf.NbrDeadTimeDuration = nbrData.NbrDeadTime
+	nbrConf.NbrUpTime = time.Now()
 	if nbrData.TwoWayStatus {
 		nbrConf.State = NbrTwoWay
+		nbrConf.NbrStateReason = objects.NBR_EVENT_TWO_WAY_RECEIVED
 		server.NbrConfMap[nbrKey] = nbrConf
 	} else {
 		nbrConf.State = NbrInit
+		nbrConf.NbrStateReason = objects.NBR_EVENT_HELLO_RECEIVED
 		server.NbrConfMap[nbrKey] = nbrConf
 	}
+	server.addNbrPktStats(nbrKey, nbrConf.IntfKey)
+	server.addNbrRetx(nbrKey, nbrConf.IntfKey, server.IntfConfMap[nbrConf.IntfKey].RetransInterval)
 	server.ProcessNbrDead(nbrKey)
 	//	server.ProcessNbrFsmStart(nbrKey, nbrConf)
 	server.logger.Debug("Nbr : Add to slice ", nbrKey)
@@ -216,7 +241,7 @@ func (server *OSPFV2Server) ProcessNbrFsmStart(nbrKey NbrConfKey) {
 	nbrConf, _ := server.NbrConfMap[nbrKey]
 	isAdjacent := server.AdjacencyCheck(nbrKey)
 	if isAdjacent {
-		nbrConf.State = NbrExchangeStart
+		setNbrState(&nbrConf, NbrExchangeStart, objects.NBR_EVENT_TWO_WAY_RECEIVED)
 		dbd_mdata.dd_sequence_number = uint32(time.Now().Nanosecond())
 		// send dbd packets
 		server.ConstructDbdMdata(nbrKey, true, true, true,
@@ -226,7 +251,7 @@ func (server *OSPFV2Server) ProcessNbrFsmStart(nbrKey NbrConfKey) {
 
 	} else { // no adjacency
 		server.logger.Debug("Nbr: Twoway  ", nbrKey)
-		nbrConf.State = NbrTwoWay
+		setNbrState(&nbrConf, NbrTwoWay, objects.NBR_EVENT_TWO_WAY_RECEIVED)
 	}
 
 	server.ProcessNbrUpdate(nbrKey, nbrConf)
@@ -244,6 +269,7 @@ func (server *OSPFV2Server) ProcessNbrInit(nbrKey NbrConfKey) {
 	nbrConf.NbrRetxList = nil
 	nbrConf.NbrDBSummaryList = nil
 	nbrConf.NbrLsaIndex = -1
+	server.clearNbrRetx(nbrKey)
 	server.ProcessNbrUpdate(nbrKey, nbrConf)
 }
 
@@ -279,7 +305,7 @@ func (server *OSPFV2Server) ProcessNbrExstart(nbrKey NbrConfKey, nbrConf NbrConf
 			nbrConf.isMaster = true
 			server.logger.Debug("NBREVENT: Negotiation done..")
 			negotiationDone = true
-			nbrConf.State = NbrExchange
+			setNbrState(&nbrConf, NbrExchange, objects.NBR_EVENT_NEGOTIATION_DONE)
 		}
 		/*
 			if nbrDbPkt.msbit && nbrConf.NbrRtrId > server.globalData.RouterId {
@@ -304,11 +330,11 @@ func (server *OSPFV2Server) ProcessNbrExstart(nbrKey NbrConfKey, nbrConf NbrConf
 			server.logger.Debug("DBD:(ExStart) SLAVE = ", nbrKey.NbrIdentity, "MASTER = SELF")
 			server.logger.Debug("NBREVENT: Negotiation done..")
 			negotiationDone = true
-			nbrConf.State = NbrExchange
+			setNbrState(&nbrConf, NbrExchange, objects.NBR_EVENT_NEGOTIATION_DONE)
 		}
 
 	} else {
-		nbrConf.State = NbrTwoWay
+		setNbrState(&nbrConf, NbrTwoWay, objects.NBR_EVENT_TWO_WAY_RECEIVED)
 	}
 
 	if negotiationDone {
@@ -332,7 +358,7 @@ func (server *OSPFV2Server) ProcessNbrExstart(nbrKey NbrConfKey, nbrConf NbrConf
 		nbrConf.NbrReqList = req_list
 	} else { // negotiation not done
 		server.logger.Debug("Nbr: Negotiation not done. ", nbrConf.NbrIP)
-		nbrConf.State = NbrExchangeStart
+		setNbrState(&nbrConf, NbrExchangeStart, objects.NBR_EVENT_TWO_WAY_RECEIVED)
 		if nbrConf.isMaster &&
 			nbrConf.NbrRtrId > server.globalData.RouterId {
 			dbd_mdata.dd_sequence_number = nbrDbPkt.dd_sequence_number
@@ -359,7 +385,7 @@ func (server *OSPFV2Server) ProcessNbrExchange(nbrKey NbrConfKey, nbrConf NbrCon
 		server.logger.Debug(fmt.Sprintln("NBRDBD: (Exchange)Discard packet. nbr", nbrConf.NbrIP,
 			" nbr state ", nbrConf.State))
 
-		nbrConf.State = NbrExchangeStart
+		setNbrState(&nbrConf, NbrExchangeStart, objects.NBR_EVENT_SEQ_NUMBER_MISMATCH)
 		server.ProcessNbrExstart(nbrKey, nbrConf, nbrDbPkt)
 
 		return
@@ -399,7 +425,7 @@ func (server *OSPFV2Server) ProcessNbrExchange(nbrKey NbrConfKey, nbrConf NbrCon
 			dbd_mdata.dd_sequence_number++
 		}
 		if !nbrDbPkt.mbit && last_exchange {
-			nbrConf.State = NbrLoading
+			setNbrState(&nbrConf, NbrLoading, objects.NBR_EVENT_EXCHANGE_DONE)
 			nbrConf.NbrReqListIndex = server.BuildAndSendLSAReq(nbrKey, nbrConf)
 			server.logger.Debug(fmt.Sprintln("DBD: Loading , nbr ", nbrKey.NbrIdentity))
 		}
@@ -421,7 +447,7 @@ func (server *OSPFV2Server) ProcessNbrLoading(nbrKey NbrConfKey, nbrConf NbrConf
 			" nbr state ", nbrConf.State))
 		//update neighbor to exchange start state and send dbd
 
-		nbrConf.State = NbrExchangeStart
+		setNbrState(&nbrConf, NbrExchangeStart, objects.NBR_EVENT_SEQ_NUMBER_MISMATCH)
 		nbrConf.isMaster = false
 		/*
 		dbd_mdata, _ = server.ConstructDbdMdata(nbrKey, true, true, true,
@@ -440,6 +466,9 @@ func (server *OSPFV2Server) ProcessNbrLoading(nbrKey NbrConfKey, nbrConf NbrConf
 			dbd_mdata, _ := server.ConstructDbdMdata(nbrKey, false, nbrDbPkt.mbit, false,
 				nbrDbPkt.options, nbrDbPkt.dd_sequence_number, false, false)
 			server.BuildAndSendDdBDPkt(nbrConf, dbd_mdata)
+			server.updatePktStats(nbrConf.IntfKey, nbrKey, func(stats *objects.Ospfv2PktStats) {
+				stats.NumOfRetransmits++
+			})
 			seq_num = dbd_mdata.dd_sequence_number + 1
 		}
 		seq_num = nbrConf.NbrLastDbd.dd_sequence_number
@@ -455,9 +484,16 @@ func (server *OSPFV2Server) ProcessNbrFull(nbrKey NbrConfKey) {
 	if !valid {
 		server.logger.Err("Nbr: Full event , nbr key does not exist ", nbrKey)
 	}
-	nbrConf.State = NbrFull
+	// The request list is empty at the end of the exchange or once loaded
+	if nbrConf.State == NbrExchange {
+		setNbrState(&nbrConf, NbrFull, objects.NBR_EVENT_EXCHANGE_DONE)
+	} else {
+		setNbrState(&nbrConf, NbrFull, objects.NBR_EVENT_LOADING_DONE)
+	}
 	server.UpdateIntfToNbrMap(nbrKey)
 	server.ProcessNbrUpdate(nbrKey, nbrConf)
+	server.setLsaReqRetxList(nbrKey, nil)
+	server.processGracefulRestartNbrFull(nbrConf.NbrRtrId)
 	server.logger.Debug("Nbr: Nbr full event ", nbrKey)
 	intf, valid := server.IntfConfMap[nbrConf.IntfKey]
 	if !valid {
@@ -495,9 +531,9 @@ func (server *OSPFV2Server) ProcessNetworkDRChangeMsg(msg NetworkDRChangeMsg) {
 	server.logger.Debug("Nbr: Received Network DR change message ")
 	var Op LsaOp
 	if msg.OldIntfFSMState == objects.INTF_FSM_STATE_DR &&
-		msg.NewIntfFSMState == objects.INTF_FSM_STATE_OTHER_DR {
+		msg.NewIntfFSMState != objects.INTF_FSM_STATE_DR {
 		Op = FLUSH
-	} else if msg.OldIntfFSMState == objects.INTF_FSM_STATE_OTHER_DR &&
+	} else if msg.OldIntfFSMState != objects.INTF_FSM_STATE_DR &&
 		msg.NewIntfFSMState == objects.INTF_FSM_STATE_DR {
 		Op = GENERATE
 	} else {
@@ -537,6 +573,7 @@ func (server *OSPFV2Server) ProcessNbrDeadFromIntf(key IntfConfKey) {
 		if !exist {
 			continue
 		}
+		server.deleteNbrBfdSession(nbrConf)
 		nbrConf.NbrDeadTimer.Stop()
 		nbrConf.NbrDeadTimer = nil
 		if len(nbrConf.NbrReqList) > 0 {
@@ -553,6 +590,8 @@ func (server *OSPFV2Server) ProcessNbrDeadFromIntf(key IntfConfKey) {
 		nbrConf.NbrRetxList = nil
 		nbrConf.NbrDBSummaryList = nil
 		delete(server.NbrConfMap, nbr)
+		server.delNbrPktStats(nbr)
+		server.delNbrRetx(nbr)
 		server.delNbrFromSlice(nbr)
 		server.logger.Info("Nbr: Deleted", nbr)
 	}
@@ -578,101 +617,129 @@ func (server *OSPFV2Server) ProcessNbrDead(nbrKey NbrConfKey) {
 	var nbr_entry_dead_func func()
 	nbr_entry_dead_func = func() {
 		nbrConf, _ := server.NbrConfMap[nbrKey]
+		// Adjacency with a restarting nbr is kept up for the grace period
+		if nbrConf.GrHelperStatus == objects.RESTART_HELPER_STATUS_HELPING &&
+			nbrConf.NbrDeadTimer != nil {
+			nbrConf.NbrDeadTimer.Reset(nbrConf.NbrDeadTimeDuration)
+			return
+		}
 
 		server.logger.Info(fmt.Sprintln("NBRSCAN: DEAD ", nbrKey))
-		server.logger.Info(fmt.Sprintln("DEAD: start processing nbr dead ", nbrKey))
-		server.ResetNbrData(nbrKey, nbrConf.IntfKey)
-
-		server.logger.Info(fmt.Sprintln("DEAD: end processing nbr dead ", nbrKey))
-
-		nbrConf, exists := server.NbrConfMap[nbrKey]
-		if exists {
-			//update interface to neighbor map
-			nbrList, valid := server.NbrConfData.IntfToNbrMap[nbrConf.IntfKey]
-			if valid {
-				for i, nbrKeyT := range nbrList {
-					if nbrKeyT.NbrIdentity == nbrKey.NbrIdentity {
-						nbrList = append(nbrList[:i], nbrList[i+1:]...)
-						break
-					}
+		server.KillNbr(nbrKey)
+	} // end of afterFunc callback
+	nbrConf, exists := server.NbrConfMap[nbrKey]
+	if exists {
+		nbrConf.NbrDeadTimer = time.AfterFunc(nbrConf.NbrDeadTimeDuration, nbr_entry_dead_func)
+		nbrConf.NbrDeadTimerStart = time.Now()
+		server.NbrConfMap[nbrKey] = nbrConf
+		server.logger.Debug("Nbr : nbr dead updated ")
+	}
+
+}
+
+/* KillNbr event. Tears down the adjacency on inactivity
+timer expiry or on BFD session down.
+*/
+func (server *OSPFV2Server) KillNbr(nbrKey NbrConfKey) {
+	nbrConf, _ := server.NbrConfMap[nbrKey]
+	server.logger.Info(fmt.Sprintln("DEAD: start processing nbr dead ", nbrKey))
+	server.ResetNbrData(nbrKey, nbrConf.IntfKey)
+
+	server.logger.Info(fmt.Sprintln("DEAD: end processing nbr dead ", nbrKey))
+
+	nbrConf, exists := server.NbrConfMap[nbrKey]
+	if exists {
+		server.deleteNbrBfdSession(nbrConf)
+		//update interface to neighbor map
+		nbrList, valid := server.NbrConfData.IntfToNbrMap[nbrConf.IntfKey]
+		if valid {
+			for i, nbrKeyT := range nbrList {
+				if nbrKeyT.NbrIdentity == nbrKey.NbrIdentity {
+					nbrList = append(nbrList[:i], nbrList[i+1:]...)
+					break
 				}
-				server.NbrConfData.IntfToNbrMap[nbrConf.IntfKey] = nbrList
-				server.logger.Debug("Nbr : Int to nbr list updated ", nbrList)
-			}
-			//send signal to intf fsm
-			nbrDownMsg := NbrDownMsg{
-				NbrKey: nbrKey,
 			}
-			server.MessagingChData.NbrToIntfFSMChData.NbrDownMsgChMap[nbrConf.IntfKey] <- nbrDownMsg
-			//Send Msg to Lsdb for Nbr Dead
-			intfConfEnt, exist := server.IntfConfMap[nbrConf.IntfKey]
-			if exist {
-				nbrDeadMsg := NbrDeadMsg{
-					AreaId:   intfConfEnt.AreaId,
-					NbrRtrId: nbrConf.NbrRtrId,
-				}
-				server.SendMsgToLsdbFromNbrFSMForNbrDead(nbrDeadMsg)
+			server.NbrConfData.IntfToNbrMap[nbrConf.IntfKey] = nbrList
+			server.logger.Debug("Nbr : Int to nbr list updated ", nbrList)
+		}
+		//send signal to intf fsm
+		nbrDownMsg := NbrDownMsg{
+			NbrKey: nbrKey,
+		}
+		server.MessagingChData.NbrToIntfFSMChData.NbrDownMsgChMap[nbrConf.IntfKey] <- nbrDownMsg
+		//Send Msg to Lsdb for Nbr Dead
+		intfConfEnt, exist := server.IntfConfMap[nbrConf.IntfKey]
+		if exist {
+			nbrDeadMsg := NbrDeadMsg{
+				AreaId:   intfConfEnt.AreaId,
+				NbrRtrId: nbrConf.NbrRtrId,
 			}
-			//send message to lsdb if I am DR.
-			intf, valid := server.IntfConfMap[nbrConf.IntfKey]
-			if !valid {
-				server.logger.Info("Nbr : intf does not exist . Dont send msg to lsdb", nbrConf.IntfKey)
-			} else {
-				if intf.DRtrId == server.globalData.RouterId {
-					nbrList := []uint32{}
-					for _, nbr := range server.NbrConfData.IntfToNbrMap[nbrConf.IntfKey] {
-						nbrC, exist := server.NbrConfMap[nbr]
-						if !exist {
-							server.logger.Info("Nbr : Generate nw lsa . nbr does not exist ", nbr)
-							continue
-						}
-						nbrList = append(nbrList, nbrC.NbrRtrId)
-					}
-
-					lsdbMsg := UpdateSelfNetworkLSAMsg{
-						Op:      GENERATE,
-						IntfKey: nbrConf.IntfKey,
-						NbrList: nbrList,
+			server.SendMsgToLsdbFromNbrFSMForNbrDead(nbrDeadMsg)
+		}
+		//send message to lsdb if I am DR.
+		intf, valid := server.IntfConfMap[nbrConf.IntfKey]
+		if !valid {
+			server.logger.Info("Nbr : intf does not exist . Dont send msg to lsdb", nbrConf.IntfKey)
+		} else {
+			if intf.DRtrId == server.globalData.RouterId {
+				nbrList := []uint32{}
+				for _, nbr := range server.NbrConfData.IntfToNbrMap[nbrConf.IntfKey] {
+					nbrC, exist := server.NbrConfMap[nbr]
+					if !exist {
+						server.logger.Info("Nbr : Generate nw lsa . nbr does not exist ", nbr)
+						continue
 					}
+					nbrList = append(nbrList, nbrC.NbrRtrId)
+				}
 
-					server.SendMsgFromNbrToLsdb(lsdbMsg)
+				lsdbMsg := UpdateSelfNetworkLSAMsg{
+					Op:      GENERATE,
+					IntfKey: nbrConf.IntfKey,
+					NbrList: nbrList,
 				}
 
-			}
-			if len(nbrConf.NbrReqList) > 0 {
-				nbrConf.NbrReqList = nbrConf.NbrReqList[:len(nbrConf.NbrReqList)-1]
-			}
-			if len(nbrConf.NbrRetxList) > 0 {
-				nbrConf.NbrRetxList = nbrConf.NbrRetxList[:len(nbrConf.NbrRetxList)-1]
-			}
-			if len(nbrConf.NbrDBSummaryList) > 0 {
-				nbrConf.NbrDBSummaryList = nbrConf.NbrDBSummaryList[:len(nbrConf.NbrDBSummaryList)-1]
+				server.SendMsgFromNbrToLsdb(lsdbMsg)
 			}
 
-			nbrConf.NbrReqList = nil
-			nbrConf.NbrRetxList = nil
-			nbrConf.NbrDBSummaryList = nil
-			//delete neighbor from map
-			delete(server.NbrConfMap, nbrKey)
-			server.logger.Info("Nbr: Deleted ", nbrKey)
 		}
-	} // end of afterFunc callback
-	nbrConf, exists := server.NbrConfMap[nbrKey]
-	if exists {
-		nbrConf.NbrDeadTimer = time.AfterFunc(nbrConf.NbrDeadTimeDuration, nbr_entry_dead_func)
-		server.NbrConfMap[nbrKey] = nbrConf
-		server.logger.Debug("Nbr : nbr dead updated ")
-	}
+		if len(nbrConf.NbrReqList) > 0 {
+			nbrConf.NbrReqList = nbrConf.NbrReqList[:len(nbrConf.NbrReqList)-1]
+		}
+		if len(nbrConf.NbrRetxList) > 0 {
+			nbrConf.NbrRetxList = nbrConf.NbrRetxList[:len(nbrConf.NbrRetxList)-1]
+		}
+		if len(nbrConf.NbrDBSummaryList) > 0 {
+			nbrConf.NbrDBSummaryList = nbrConf.NbrDBSummaryList[:len(nbrConf.NbrDBSummaryList)-1]
+		}
 
+		nbrConf.NbrReqList = nil
+		nbrConf.NbrRetxList = nil
+		nbrConf.NbrDBSummaryList = nil
+		//delete neighbor from map
+		delete(server.NbrConfMap, nbrKey)
+		server.delNbrPktStats(nbrKey)
+		server.delNbrRetx(nbrKey)
+		server.logger.Info("Nbr: Deleted ", nbrKey)
+	}
 }
 
 func (server *OSPFV2Server) ProcessNbrUpdate(nbrKey NbrConfKey, nbrConf NbrConf) {
 	server.logger.Debug("Nbr : ", nbrConf)
 	if nbrConf.NbrDeadTimer != nil {
 		nbrConf.NbrDeadTimer.Reset(nbrConf.NbrDeadTimeDuration)
+		nbrConf.NbrDeadTimerStart = time.Now()
 	}
+	oldConf, exist := server.NbrConfMap[nbrKey]
+	server.updateNbrBfdSession(&nbrConf)
 	server.NbrConfMap[nbrKey] = nbrConf
 	server.logger.Debug("Nbr: Nbr conf updated ", nbrKey)
+	if exist && (oldConf.State == NbrFull) != (nbrConf.State == NbrFull) {
+		// Point-to-multipoint links are only advertised to full nbrs
+		intfConf, valid := server.IntfConfMap[nbrConf.IntfKey]
+		if valid && intfConf.Type == objects.INTF_TYPE_POINT2MULTIPOINT {
+			server.SendMsgToGenerateRouterLSA(intfConf.AreaId)
+		}
+	}
 }
 
 /**** Utils APis *****/
@@ -711,6 +778,7 @@ func (server *OSPFV2Server) ResetNbrData(nbr NbrConfKey, intf IntfConfKey) {
 		nbrConf.NbrDBSummaryList = nil
 		nbrConf.NbrRetxList = nil
 	}
+	server.clearNbrRetx(nbr)
 
 	nbrList, exists := server.NbrConfData.IntfToNbrMap[intf]
 	if !exists {
@@ -26,6 +26,7 @@ package server
 import (
 	//"encoding/binary"
 	"fmt"
+	"l3/ospfv2/objects"
 	//"l3/ospf/config"
 	//"time"
 )
@@ -95,9 +96,23 @@ func (server *OSPFV2Server) generateDbSummaryList(nbrConfKey NbrConfKey) {
 		db_list = append(db_list, summary4_list...)
 	}
 
-	asExternal_list := server.generateDbasExternalList(areaId)
-	if asExternal_list != nil {
-		db_list = append(db_list, asExternal_list...)
+	if intf.Type != objects.INTF_TYPE_VIRTUAL {
+		asExternal_list := server.generateDbasExternalList(areaId)
+		if asExternal_list != nil {
+			db_list = append(db_list, asExternal_list...)
+		}
+	}
+
+	nssa_list := server.generateDbNssaList(areaId)
+	if nssa_list != nil {
+		db_list = append(db_list, nssa_list...)
+	}
+
+	if isNbrOpaqueCapable(nbrConf) {
+		opaque_list := server.generateDbOpaqueList(areaId, nbrConf.IntfKey, intf)
+		if opaque_list != nil {
+			db_list = append(db_list, opaque_list...)
+		}
 	}
 
 	for _, lsa := range db_list {
@@ -144,6 +159,80 @@ func (server *OSPFV2Server) generateDbasExternalList(self_areaId uint32) []*ospf
 	return db_list
 }
 
+/*@fn generateDbNssaList
+This function generates Type-7 LSA list for NSSA
+*/
+func (server *OSPFV2Server) generateDbNssaList(self_areaId uint32) []*ospfLSAHeader {
+	if !server.isNssaArea(self_areaId) {
+		return nil
+	}
+	db_list := []*ospfLSAHeader{}
+	lsdbKey := LsdbKey{
+		AreaId: self_areaId,
+	}
+
+	area_lsa, exist := server.LsdbData.AreaLsdb[lsdbKey]
+	if !exist {
+		server.logger.Err(fmt.Sprintln("negotiation: NSSA LSA doesnt exist"))
+		return nil
+	}
+	for lsaKey, dlsa := range area_lsa.NssaLsaMap {
+		db_nssa := getLsaHeaderFromLsa(dlsa.LsaMd.LSAge, dlsa.LsaMd.Options,
+			NSSALSA, lsaKey.LSId, lsaKey.AdvRouter,
+			uint32(dlsa.LsaMd.LSSequenceNum), dlsa.LsaMd.LSChecksum,
+			dlsa.LsaMd.LSLen)
+		/* add entry to the db summary list  */
+		db_list = append(db_list, db_nssa)
+	}
+	return db_list
+}
+
+/*@fn generateDbOpaqueList
+This function generates opaque LSA list as per the flooding
+scope of each opaque LS type, Rfc 5250
+*/
+func (server *OSPFV2Server) generateDbOpaqueList(self_areaId uint32, intfKey IntfConfKey, intf IntfConf) []*ospfLSAHeader {
+	db_list := []*ospfLSAHeader{}
+	lsdbKey := LsdbKey{
+		AreaId: self_areaId,
+	}
+
+	area_lsa, exist := server.LsdbData.AreaLsdb[lsdbKey]
+	if !exist {
+		server.logger.Err(fmt.Sprintln("negotiation: Opaque LSA doesnt exist"))
+		return nil
+	}
+	for linkLsaKey, dlsa := range area_lsa.OpaqueLinkLsaMap {
+		if linkLsaKey.IntfKey != intfKey {
+			continue
+		}
+		lsaKey := linkLsaKey.LsaKey
+		db_opaque := getLsaHeaderFromLsa(dlsa.LsaMd.LSAge, dlsa.LsaMd.Options,
+			OpaqueLinkLSA, lsaKey.LSId, lsaKey.AdvRouter,
+			uint32(dlsa.LsaMd.LSSequenceNum), dlsa.LsaMd.LSChecksum,
+			dlsa.LsaMd.LSLen)
+		db_list = append(db_list, db_opaque)
+	}
+	for lsaKey, dlsa := range area_lsa.OpaqueAreaLsaMap {
+		db_opaque := getLsaHeaderFromLsa(dlsa.LsaMd.LSAge, dlsa.LsaMd.Options,
+			OpaqueAreaLSA, lsaKey.LSId, lsaKey.AdvRouter,
+			uint32(dlsa.LsaMd.LSSequenceNum), dlsa.LsaMd.LSChecksum,
+			dlsa.LsaMd.LSLen)
+		db_list = append(db_list, db_opaque)
+	}
+	if intf.Type == objects.INTF_TYPE_VIRTUAL {
+		return db_list
+	}
+	for lsaKey, dlsa := range area_lsa.OpaqueASLsaMap {
+		db_opaque := getLsaHeaderFromLsa(dlsa.LsaMd.LSAge, dlsa.LsaMd.Options,
+			OpaqueASLSA, lsaKey.LSId, lsaKey.AdvRouter,
+			uint32(dlsa.LsaMd.LSSequenceNum), dlsa.LsaMd.LSChecksum,
+			dlsa.LsaMd.LSLen)
+		db_list = append(db_list, db_opaque)
+	}
+	return db_list
+}
+
 /* @fn generateDbsummaryLsaList
 This function will attach summary LSAs if the router is ABR
 */
@@ -283,6 +372,16 @@ func (server *OSPFV2Server) lsaAddCheck(lsaheader ospfLSAHeader,
 		dalsa, ret := server.getASExternalLsaFromLsdb(areaId, *lsa_key)
 		discard, _ = server.sanityCheckASExternalLsa(*alsa, dalsa, nbr, intf, ret, lsa_max_age)
 
+	case NSSALSA:
+		alsa := NewASExternalLsa()
+		dalsa, ret := server.getNssaLsaFromLsdb(areaId, *lsa_key)
+		discard, _ = server.sanityCheckNssaLsa(*alsa, dalsa, nbr, intf, ret, lsa_max_age)
+
+	case OpaqueLinkLSA, OpaqueAreaLSA, OpaqueASLSA:
+		olsa := NewOpaqueLsa()
+		dolsa, ret := server.getOpaqueLsaFromLsdb(areaId, nbr.IntfKey, *lsa_key)
+		discard, _ = server.sanityCheckOpaqueLsa(*olsa, dolsa, nbr, intf, ret, lsa_max_age)
+
 	}
 	if discard {
 		server.logger.Info(fmt.Sprintln("DBD: LSA is not added in the request list. Adv router ", adv_router,
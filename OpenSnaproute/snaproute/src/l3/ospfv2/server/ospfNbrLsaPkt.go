@@ -168,11 +168,9 @@ func (server *OSPFV2Server) EncodeLSAReqPkt(intfKey IntfConfKey, ent IntfConf,
 
 	ospf := append(ospfEncHdr, lsaDataEnc...)
 	server.logger.Debug("OSPF LSA REQ:", ospf)
-	csum := computeCheckSum(ospf)
-	binary.BigEndian.PutUint16(ospf[12:14], csum)
-	binary.BigEndian.PutUint64(ospf[16:24], ent.AuthKey)
+	ospf = server.encodeOspfAuth(ent, ospf)
 
-	ipPktlen := IP_HEADER_MIN_LEN + ospfHdr.Pktlen
+	ipPktlen := IP_HEADER_MIN_LEN + len(ospf)
 	var dstIp net.IP
 	if ent.FSMState == objects.INTF_FSM_STATE_P2P {
 		dstIp = net.ParseIP(config.AllSPFRouters)
@@ -280,6 +278,7 @@ func (server *OSPFV2Server) BuildAndSendLSAReq(nbrId NbrConfKey, nbrConf NbrConf
 		}
 		data := server.EncodeLSAReqPkt(nbrConf.IntfKey, intConf, nbrConf, msg.lsa_slice, nbrConf.NbrMac)
 		server.SendOspfPkt(nbrConf.IntfKey, data)
+		server.setLsaReqRetxList(nbrId, msg.lsa_slice)
 	}
 	return nbrConf.NbrReqListIndex
 }
@@ -334,11 +333,9 @@ func (server *OSPFV2Server) BuildLsaUpdPkt(intfKey IntfConfKey, ent IntfConf,
 
 	ospf := append(ospfEncHdr, lsaUpdEnc...)
 	//server.logger.Debug(fmt.Sprintln("OSPF LSA UPD:", ospf))
-	csum := computeCheckSum(ospf)
-	binary.BigEndian.PutUint16(ospf[12:14], csum)
-	binary.BigEndian.PutUint64(ospf[16:24], ent.AuthKey)
+	ospf = server.encodeOspfAuth(ent, ospf)
 	srcIp := net.ParseIP(convertUint32ToDotNotation(ent.IpAddr))
-	ipPktlen := IP_HEADER_MIN_LEN + ospfHdr.Pktlen
+	ipPktlen := IP_HEADER_MIN_LEN + len(ospf)
 	ipLayer := layers.IPv4{
 		Version:  uint8(4),
 		IHL:      uint8(IP_HEADER_MIN_LEN),
@@ -426,6 +423,10 @@ func (server *OSPFV2Server) ProcessLsaUpd(msg NbrLsaUpdMsg) {
 	end_index := 0
 	lsa_header_byte := make([]byte, OSPF_LSA_HEADER_SIZE)
 	for i := 0; i < int(no_lsa); i++ {
+		if index+OSPF_LSA_HEADER_SIZE > len(msg.data) {
+			server.logger.Err("LSAUPD: Discard. Truncated LSA header. nbr ", msg.nbrKey)
+			break
+		}
 		decodeLsaHeader(msg.data[index:index+OSPF_LSA_HEADER_SIZE], lsa_header)
 		copy(lsa_header_byte, msg.data[index:index+OSPF_LSA_HEADER_SIZE])
 		server.logger.Debug("LSAUPD: lsaheader decoded adv_rter ", lsa_header.Adv_router,
@@ -434,11 +435,16 @@ func (server *OSPFV2Server) ProcessLsaUpd(msg NbrLsaUpdMsg) {
 			" LSTYPE ", lsa_header.LSType,
 			" len ", lsa_header.length)
 		end_index = int(lsa_header.length) + index /* length includes data + header */
+		if int(lsa_header.length) < OSPF_LSA_HEADER_SIZE || end_index > len(msg.data) {
+			server.logger.Err("LSAUPD: Discard. Invalid LSA length ", lsa_header.length, " nbr ", msg.nbrKey)
+			break
+		}
 		currLsa := make([]byte, end_index-index)
 		copy(currLsa, msg.data[index:end_index])
 		if lsa_header.LSAge == LSA_MAX_AGE {
 			lsa_max_age = true
 		}
+		server.processRxLsaRetx(msg.nbrKey, decodeLSAHeader(currLsa))
 		/* send message to lsdb */
 		lsdb_msg := RecvdLsaMsg{}
 		lsdbKey := LsdbKey{
@@ -451,6 +457,13 @@ func (server *OSPFV2Server) ProcessLsaUpd(msg NbrLsaUpdMsg) {
 				server.NbrConfMap[msg.nbrKey])
 			//continue
 		}
+		if !server.isExternalLsaAllowed(msg.areaId, lsa_header.LSType) {
+			// Rfc 2328 13 (3) and Rfc 3101 3.2
+			server.logger.Info("LSAUPD: Discard lsa type ", lsa_header.LSType,
+				" not allowed in area ", msg.areaId)
+			index = end_index
+			continue
+		}
 		lsa_key := NewLsaKey()
 		selfGenLsaMsg := RecvdSelfLsaMsg{}
 		switch lsa_header.LSType {
@@ -488,6 +501,32 @@ func (server *OSPFV2Server) ProcessLsaUpd(msg NbrLsaUpdMsg) {
 			discard, _ = server.sanityCheckASExternalLsa(*alsa, dalsa, nbr, intf, ret, lsa_max_age)
 			lsdb_msg.LsaData = *alsa
 			selfGenLsaMsg.LsaData = *alsa
+
+		case NSSALSA:
+			alsa := NewASExternalLsa()
+			decodeASExternalLsa(currLsa, alsa, lsa_key)
+			dalsa, ret := server.getNssaLsaFromLsdb(msg.areaId, *lsa_key)
+			discard, _ = server.sanityCheckNssaLsa(*alsa, dalsa, nbr, intf, ret, lsa_max_age)
+			lsdb_msg.LsaData = *alsa
+			selfGenLsaMsg.LsaData = *alsa
+
+		case OpaqueLinkLSA, OpaqueAreaLSA, OpaqueASLSA:
+			olsa := NewOpaqueLsa()
+			err := decodeOpaqueLsa(currLsa, olsa, lsa_key)
+			if err != nil {
+				server.logger.Err("LSAUPD: Discard opaque lsa. nbr ", msg.nbrKey, err)
+				index = end_index
+				continue
+			}
+			olsa.IntfKey = nbr.IntfKey
+			if lsa_key.LSType == OpaqueLinkLSA {
+				// Grace LSAs drive the helper mode
+				server.processRxGraceLsa(msg.nbrKey, currLsa)
+			}
+			dolsa, ret := server.getOpaqueLsaFromLsdb(msg.areaId, nbr.IntfKey, *lsa_key)
+			discard, _ = server.sanityCheckOpaqueLsa(*olsa, dolsa, nbr, intf, ret, lsa_max_age)
+			lsdb_msg.LsaData = *olsa
+			selfGenLsaMsg.LsaData = *olsa
 		}
 
 		lsid := lsa_header.LinkId
@@ -509,6 +548,7 @@ func (server *OSPFV2Server) ProcessLsaUpd(msg NbrLsaUpdMsg) {
 
 		if !discard && !self_gen {
 			server.logger.Debug("LSAUPD: add to lsdb lsid ", lsid, " router_id ", router_id, " lstype ", lsa_header.LSType)
+			server.checkGracefulRestartHelperTopologyChange(msg.areaId, *lsa_key, currLsa)
 			lsdb_msg.MsgType = LSA_ADD
 			lsdb_msg.LsaKey = *lsa_key
 			server.MessagingChData.NbrFSMToLsdbChData.RecvdLsaMsgCh <- lsdb_msg
@@ -593,11 +633,9 @@ func (server *OSPFV2Server) BuildLSAAckPkt(intfKey IntfConfKey, ent IntfConf,
 
 	ospf := append(ospfEncHdr, lsaAckEnc...)
 	//server.logger.Debug(fmt.Sprintln("OSPF LSA ACK:", ospf))
-	csum := computeCheckSum(ospf)
-	binary.BigEndian.PutUint16(ospf[12:14], csum)
-	binary.BigEndian.PutUint64(ospf[16:24], ent.AuthKey)
+	ospf = server.encodeOspfAuth(ent, ospf)
 
-	ipPktlen := IP_HEADER_MIN_LEN + ospfHdr.Pktlen
+	ipPktlen := IP_HEADER_MIN_LEN + len(ospf)
 	if ent.FSMState == objects.INTF_FSM_STATE_P2P {
 		dstIp = net.ParseIP(config.AllSPFRouters)
 		dstMAC, _ = net.ParseMAC(config.McastMAC)
@@ -673,17 +711,9 @@ func (server *OSPFV2Server) DecodeLSAAck(msg NbrLsaAckMsg) {
 	if discard {
 		return
 	}
-	/* process each LSA and update request list */
-	for index := range msg.lsa_headers {
-		req_list := nbr.NbrReqList
-		for in := range req_list {
-			if req_list[in].link_state_id == msg.lsa_headers[index].link_state_id {
-				/* invalidate from request list */
-				/* TODO delete req list element */
-			}
-			/* update the reTxList */
-
-		}
+	/* process each LSA and update the retransmission list */
+	for _, lsa_header := range msg.lsa_headers {
+		server.ackLsaOnRetxList(msg.nbrKey, lsa_header)
 	}
 }
 
@@ -802,6 +832,25 @@ func (server *OSPFV2Server) generateLsaUpdUnicast(req ospfLSAReq, nbrKey NbrConf
 			server.logger.Debug("LSAREQ: AS external lsa not fount. lsaid ",
 				req.link_state_id, " lstype ", lsa_key.LSType, " adv_router ", lsa_key.AdvRouter, " areaid ", areaid)
 		}
+	case NSSALSA:
+		dalsa, ret := server.getNssaLsaFromLsdb(areaid, *lsa_key)
+		if ret == LsdbEntryFound {
+			lsa_pkt = encodeASExternalLsa(dalsa, *lsa_key)
+			flood = true
+		} else {
+			server.logger.Debug("LSAREQ: NSSA lsa not found. lsaid ",
+				req.link_state_id, " lstype ", lsa_key.LSType, " adv_router ", lsa_key.AdvRouter, " areaid ", areaid)
+		}
+	case OpaqueLinkLSA, OpaqueAreaLSA, OpaqueASLSA:
+		nbrConf := server.NbrConfMap[nbrKey]
+		dolsa, ret := server.getOpaqueLsaFromLsdb(areaid, nbrConf.IntfKey, *lsa_key)
+		if ret == LsdbEntryFound {
+			lsa_pkt = encodeOpaqueLsa(dolsa, *lsa_key)
+			flood = true
+		} else {
+			server.logger.Debug("LSAREQ: Opaque lsa not found. lsaid ",
+				req.link_state_id, " lstype ", lsa_key.LSType, " adv_router ", lsa_key.AdvRouter, " areaid ", areaid)
+		}
 	}
 	lsid := req.link_state_id
 	router_id := req.adv_router_id
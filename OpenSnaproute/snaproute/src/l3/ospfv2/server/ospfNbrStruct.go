@@ -54,6 +54,17 @@ type NbrConf struct {
 	NbrDBSummaryList []*ospfLSAHeader
 	NbrRetxList      []*ospfLSAHeader
 	NbrReqListIndex  int
+	//Graceful restart helper
+	GrHelperStatus     uint8
+	GrHelperExitReason uint8
+	GrHelperExpiry     time.Time
+	GrHelperTimer      *time.Timer
+	//BFD session with the nbr
+	BfdState uint8
+	//Adjacency statistics
+	NbrUpTime         time.Time
+	NbrStateReason    uint8
+	NbrDeadTimerStart time.Time
 }
 
 const (
@@ -158,6 +169,11 @@ type NbrStruct struct {
 	IntfToNbrMap          map[IntfConfKey][]NbrConfKey
 	nbrFSMCtrlCh          chan bool
 	nbrFSMCtrlReplyCh     chan bool
+	nbrGraceExpiryCh      chan NbrConfKey
+	nbrBfdStateCh         chan NbrBfdStateMsg
+	nbrBfdEnableCh        chan IntfConfKey
+	nbrKillCh             chan NbrConfKey
+	nbrRetxCh             chan NbrConfKey
 }
 
 func (server *OSPFV2Server) InitNbrStruct() {
@@ -169,18 +185,27 @@ func (server *OSPFV2Server) InitNbrStruct() {
 	server.NbrConfData.nbrLsaAckEventCh = make(chan NbrLsaAckMsg)
 	server.NbrConfData.nbrFSMCtrlCh = make(chan bool)
 	server.NbrConfData.nbrFSMCtrlReplyCh = make(chan bool)
+	server.NbrConfData.nbrGraceExpiryCh = make(chan NbrConfKey, 10)
+	server.NbrConfData.nbrBfdStateCh = make(chan NbrBfdStateMsg, 10)
+	server.NbrConfData.nbrBfdEnableCh = make(chan IntfConfKey, 10)
+	server.NbrConfData.nbrKillCh = make(chan NbrConfKey, 10)
+	server.NbrConfData.nbrRetxCh = make(chan NbrConfKey, NBR_RETX_QUEUE_LEN)
+	server.initNbrRetxData()
 	server.logger.Debug("Nbr: InitNbrStruct done ")
 }
 
 func (server *OSPFV2Server) DeinitNbrStruct() {
 
-	for _, nbr := range server.NbrConfMap {
+	for nbrKey, nbr := range server.NbrConfMap {
+		server.deleteNbrBfdSession(nbr)
+		server.delNbrPktStats(nbrKey)
 		nbr.NbrReqList = nil
 		nbr.NbrDBSummaryList = nil
 		nbr.NbrRetxList = nil
 		nbr.NbrDeadTimer = nil
 		nbr.NbrLsaRxTimer = nil
 	}
+	server.deinitNbrRetxData()
 	server.NbrConfMap = nil
 }
 
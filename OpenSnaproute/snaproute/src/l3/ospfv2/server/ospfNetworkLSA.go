@@ -34,8 +34,8 @@ func (server *OSPFV2Server) flushNetworkLSA(intfKey IntfConfKey) error {
 	if err != nil {
 		return err
 	}
-	if intfConfEnt.Type != objects.INTF_TYPE_BROADCAST {
-		return errors.New("Network LSA doesnot exist for Non Broadcast Network")
+	if !isMultiAccessIntf(intfConfEnt.Type) {
+		return errors.New("Network LSA doesnot exist for Non Multi-Access Network")
 	}
 	lsdbKey := LsdbKey{
 		AreaId: intfConfEnt.AreaId,
@@ -71,8 +71,8 @@ func (server *OSPFV2Server) generateNetworkLSA(intfKey IntfConfKey, nbrList []ui
 	if err != nil {
 		return err
 	}
-	if intfConfEnt.Type != objects.INTF_TYPE_BROADCAST {
-		return errors.New("Network LSA won't be generated for Non Broadcast Network")
+	if !isMultiAccessIntf(intfConfEnt.Type) {
+		return errors.New("Network LSA won't be generated for Non Multi-Access Network")
 	}
 	lsdbKey := LsdbKey{
 		AreaId: intfConfEnt.AreaId,
@@ -105,7 +105,7 @@ func (server *OSPFV2Server) generateNetworkLSA(intfKey IntfConfKey, nbrList []ui
 	} else {
 		lsaEnt.LsaMd.LSSequenceNum += 1
 	}
-	lsaEnt.LsaMd.Options = EOption
+	lsaEnt.LsaMd.Options = server.getAreaLsaOptions(lsdbKey.AreaId)
 	lsaEnc := encodeNetworkLsa(lsaEnt, lsaKey)
 	checksumOffset := uint16(14)
 	lsaEnt.LsaMd.LSChecksum = computeFletcherChecksum(lsaEnc[2:], checksumOffset)
@@ -223,7 +223,7 @@ func (server *OSPFV2Server) reGenerateNetworkLSA(lsaKey LsaKey, nLsa NetworkLsa,
 		if intfConfEnt.FSMState != objects.INTF_FSM_STATE_DR {
 			continue
 		}
-		if intfConfEnt.Type != objects.INTF_TYPE_BROADCAST {
+		if !isMultiAccessIntf(intfConfEnt.Type) {
 			continue
 		}
 		if nLsa.Netmask == intfConfEnt.Netmask &&
@@ -255,7 +255,7 @@ func (server *OSPFV2Server) reGenerateNetworkLSA(lsaKey LsaKey, nLsa NetworkLsa,
 	lsaEnt.LsaMd.LSChecksum = 0
 	lsaEnt.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + 4 + (4 * len(lsaEnt.AttachedRtr)))
 	lsaEnt.LsaMd.LSSequenceNum = lsaEnt.LsaMd.LSSequenceNum + 1
-	lsaEnt.LsaMd.Options = EOption
+	lsaEnt.LsaMd.Options = server.getAreaLsaOptions(lsdbKey.AreaId)
 	lsaEnc := encodeNetworkLsa(lsaEnt, lsaKey)
 	checksumOffset := uint16(14)
 	lsaEnt.LsaMd.LSChecksum = computeFletcherChecksum(lsaEnc[2:], checksumOffset)
@@ -0,0 +1,557 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	 Unless required by applicable law or agreed to in writing, software
+//	 distributed under the License is distributed on an "AS IS" BASIS,
+//	 WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	 See the License for the specific language governing permissions and
+//	 limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"l3/ospfv2/objects"
+)
+
+// Rfc 3101: Type-7 LSAs share the AS External LSA format, P-bit is
+// carried in the NP bit of the options field
+
+func (server *OSPFV2Server) isExternalLsaAllowed(areaId uint32, lsType uint8) bool {
+	switch lsType {
+	case ASExternalLSA, OpaqueASLSA:
+		isStub, err := server.isStubArea(areaId)
+		if err != nil || isStub {
+			return false
+		}
+	case NSSALSA:
+		return server.isNssaArea(areaId)
+	}
+	return true
+}
+
+func getExternalLsaMap(lsdbEnt LSDatabase, lsType uint8) map[LsaKey]ASExternalLsa {
+	if lsType == NSSALSA {
+		return lsdbEnt.NssaLsaMap
+	}
+	return lsdbEnt.ASExternalLsaMap
+}
+
+func (server *OSPFV2Server) processRecvdSelfNssaLSA(msg RecvdSelfLsaMsg) error {
+	lsa, ok := msg.LsaData.(ASExternalLsa)
+	if !ok {
+		server.logger.Err("Unable to assert given NSSA lsa")
+		return nil
+	}
+	lsdbEnt, exist := server.LsdbData.AreaLsdb[msg.LsdbKey]
+	if !exist {
+		server.logger.Err("No such Area exist", msg.LsdbKey)
+		return nil
+	}
+	lsaEnt, exist := lsdbEnt.NssaLsaMap[msg.LsaKey]
+	if !exist {
+		server.logger.Err("No such NSSA LSA exist", msg.LsaKey)
+		// Mark the recvd LSA as MAX_AGE and Flood
+		lsa.LsaMd.LSAge = MAX_AGE
+		server.CreateAndSendMsgFromLsdbToFloodLsa(msg.LsdbKey.AreaId, msg.LsaKey, lsa)
+		return nil
+	}
+	selfOrigLsaEnt, exist := server.LsdbData.AreaSelfOrigLsa[msg.LsdbKey]
+	if !exist {
+		server.logger.Err("No self originated LSA exist")
+		return nil
+	}
+	_, exist = selfOrigLsaEnt[msg.LsaKey]
+	if !exist {
+		server.logger.Err("No such self originated NSSA LSA exist", msg.LsaKey)
+		// Mark the recvd LSA as MAX_AGE and Flood
+		lsa.LsaMd.LSAge = MAX_AGE
+		server.CreateAndSendMsgFromLsdbToFloodLsa(msg.LsdbKey.AreaId, msg.LsaKey, lsa)
+		return nil
+	}
+	if lsaEnt.LsaMd.LSSequenceNum < lsa.LsaMd.LSSequenceNum {
+		lsaEnt.LsaMd.LSSequenceNum = lsa.LsaMd.LSSequenceNum + 1
+		lsaEnt.LsaMd.LSAge = 0
+		lsaEnt.LsaMd.LSChecksum = 0
+		lsaEnc := encodeASExternalLsa(lsaEnt, msg.LsaKey)
+		checksumOffset := uint16(14)
+		lsaEnt.LsaMd.LSChecksum = computeFletcherChecksum(lsaEnc[2:], checksumOffset)
+		lsdbEnt.NssaLsaMap[msg.LsaKey] = lsaEnt
+		server.LsdbData.AreaLsdb[msg.LsdbKey] = lsdbEnt
+	}
+	// Flood Self NSSA LSA
+	server.CreateAndSendMsgFromLsdbToFloodLsa(msg.LsdbKey.AreaId, msg.LsaKey, lsaEnt)
+	return nil
+}
+
+func (server *OSPFV2Server) processRecvdNssaLSA(msg RecvdLsaMsg) error {
+	lsdbEnt, exist := server.LsdbData.AreaLsdb[msg.LsdbKey]
+	if !exist {
+		server.logger.Err("No such Area exist", msg.LsdbKey)
+		return nil
+	}
+	if msg.MsgType == LSA_ADD {
+		lsa, ok := msg.LsaData.(ASExternalLsa)
+		if !ok {
+			server.logger.Err("Unable to assert given NSSA lsa")
+			return nil
+		}
+		_, exist = lsdbEnt.NssaLsaMap[msg.LsaKey]
+		lsdbEnt.NssaLsaMap[msg.LsaKey] = lsa
+		if !exist {
+			lsdbSlice := LsdbSliceStruct{
+				LsdbKey: msg.LsdbKey,
+				LsaKey:  msg.LsaKey,
+			}
+			server.GetBulkData.LsdbSlice = append(server.GetBulkData.LsdbSlice, lsdbSlice)
+		}
+	} else if msg.MsgType == LSA_DEL {
+		delete(lsdbEnt.NssaLsaMap, msg.LsaKey)
+	}
+	server.LsdbData.AreaLsdb[msg.LsdbKey] = lsdbEnt
+	return nil
+}
+
+// installSelfOrigExternalLsa installs a self originated AS External or
+// Type-7 LSA in the given area and floods it
+func (server *OSPFV2Server) installSelfOrigExternalLsa(lsdbKey LsdbKey, lsaKey LsaKey, lsa ASExternalLsa) {
+	lsdbEnt, exist := server.LsdbData.AreaLsdb[lsdbKey]
+	if !exist {
+		server.logger.Err("No Lsdb Exist for:", lsdbKey)
+		return
+	}
+	lsaMap := getExternalLsaMap(lsdbEnt, lsaKey.LSType)
+	oldEnt, exist := lsaMap[lsaKey]
+	if exist {
+		lsa.LsaMd.LSSequenceNum = oldEnt.LsaMd.LSSequenceNum + 1
+	} else {
+		lsa.LsaMd.LSSequenceNum = int(InitialSequenceNum)
+	}
+	lsa.LsaMd.LSAge = 0
+	lsa.LsaMd.LSChecksum = 0
+	checksumOffset := uint16(14)
+	lsaEnc := encodeASExternalLsa(lsa, lsaKey)
+	lsa.LsaMd.LSChecksum = computeFletcherChecksum(lsaEnc[2:], checksumOffset)
+	lsaMap[lsaKey] = lsa
+	server.LsdbData.AreaLsdb[lsdbKey] = lsdbEnt
+	selfOrigLsaEnt, _ := server.LsdbData.AreaSelfOrigLsa[lsdbKey]
+	selfOrigLsaEnt[lsaKey] = true
+	server.LsdbData.AreaSelfOrigLsa[lsdbKey] = selfOrigLsaEnt
+	server.CreateAndSendMsgFromLsdbToFloodLsa(lsdbKey.AreaId, lsaKey, lsa)
+	if !exist {
+		lsdbSlice := LsdbSliceStruct{
+			LsdbKey: lsdbKey,
+			LsaKey:  lsaKey,
+		}
+		server.GetBulkData.LsdbSlice = append(server.GetBulkData.LsdbSlice, lsdbSlice)
+	}
+}
+
+func (server *OSPFV2Server) flushSelfOrigExternalLsa(lsdbKey LsdbKey, lsaKey LsaKey) {
+	lsdbEnt, exist := server.LsdbData.AreaLsdb[lsdbKey]
+	if !exist {
+		return
+	}
+	lsaMap := getExternalLsaMap(lsdbEnt, lsaKey.LSType)
+	lsaEnt, exist := lsaMap[lsaKey]
+	if !exist {
+		return
+	}
+	selfOrigLsaEnt, _ := server.LsdbData.AreaSelfOrigLsa[lsdbKey]
+	lsaEnt.LsaMd.LSAge = MAX_AGE
+	server.CreateAndSendMsgFromLsdbToFloodLsa(lsdbKey.AreaId, lsaKey, lsaEnt)
+	delete(selfOrigLsaEnt, lsaKey)
+	delete(lsaMap, lsaKey)
+	server.LsdbData.AreaSelfOrigLsa[lsdbKey] = selfOrigLsaEnt
+	server.LsdbData.AreaLsdb[lsdbKey] = lsdbEnt
+}
+
+// refreshSelfOrigExternalLSA re-originates a self originated external
+// LSA which is not driven by ExtRouteInfoMap at LSRefreshTime
+func (server *OSPFV2Server) refreshSelfOrigExternalLSA(lsdbKey LsdbKey, lsaKey LsaKey) {
+	lsdbEnt, exist := server.LsdbData.AreaLsdb[lsdbKey]
+	if !exist {
+		return
+	}
+	lsaEnt, exist := getExternalLsaMap(lsdbEnt, lsaKey.LSType)[lsaKey]
+	if !exist {
+		return
+	}
+	server.installSelfOrigExternalLsa(lsdbKey, lsaKey, lsaEnt)
+}
+
+// getNssaFwdAddr returns the forwarding address to be used in Type-7
+// LSAs originated into the area, Rfc 3101 2.3
+func (server *OSPFV2Server) getNssaFwdAddr(areaId uint32) uint32 {
+	var fwdAddr uint32
+	areaEnt, exist := server.AreaConfMap[areaId]
+	if !exist {
+		return 0
+	}
+	for intfKey, _ := range areaEnt.IntfMap {
+		intfEnt, exist := server.IntfConfMap[intfKey]
+		if !exist || intfEnt.IpAddr == 0 {
+			continue
+		}
+		if fwdAddr == 0 || intfEnt.IpAddr < fwdAddr {
+			fwdAddr = intfEnt.IpAddr
+		}
+	}
+	return fwdAddr
+}
+
+func (server *OSPFV2Server) buildNssaLsa(routeInfo RouteInfo, areaId uint32) ASExternalLsa {
+	var lsa ASExternalLsa
+	lsa.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + 16)
+	lsa.BitE = routeInfo.BitE
+	lsa.ExtRouteTag = routeInfo.Tag
+	lsa.Metric = routeInfo.Metric
+	lsa.Netmask = routeInfo.Netmask
+	lsa.FwdAddr = routeInfo.FwdAddr
+	// Rfc 3101 2.4: P-bit is not set by NSSA border routers as
+	// they originate AS External LSAs themselves
+	if !server.globalData.AreaBdrRtrStatus {
+		lsa.LsaMd.Options = NPOption
+		if lsa.FwdAddr == 0 {
+			lsa.FwdAddr = server.getNssaFwdAddr(areaId)
+		}
+	}
+	return lsa
+}
+
+func (server *OSPFV2Server) generateNssaLSA(routeInfo RouteInfo) {
+	if server.globalData.ASBdrRtrStatus == false {
+		return
+	}
+	lsaKey := LsaKey{
+		LSType:    NSSALSA,
+		LSId:      routeInfo.NwAddr & routeInfo.Netmask,
+		AdvRouter: server.globalData.RouterId,
+	}
+	for areaId, areaEnt := range server.AreaConfMap {
+		if areaEnt.AdminState == false ||
+			areaEnt.AreaType != objects.AREA_TYPE_NSSA {
+			continue
+		}
+		lsdbKey := LsdbKey{
+			AreaId: areaId,
+		}
+		server.installSelfOrigExternalLsa(lsdbKey, lsaKey, server.buildNssaLsa(routeInfo, areaId))
+	}
+}
+
+func (server *OSPFV2Server) flushNssaLSA(routeInfo RouteInfo) {
+	if server.globalData.ASBdrRtrStatus == false {
+		return
+	}
+	lsaKey := LsaKey{
+		LSType:    NSSALSA,
+		LSId:      routeInfo.NwAddr & routeInfo.Netmask,
+		AdvRouter: server.globalData.RouterId,
+	}
+	for areaId, areaEnt := range server.AreaConfMap {
+		if areaEnt.AdminState == false ||
+			areaEnt.AreaType != objects.AREA_TYPE_NSSA {
+			continue
+		}
+		lsdbKey := LsdbKey{
+			AreaId: areaId,
+		}
+		server.flushSelfOrigExternalLsa(lsdbKey, lsaKey)
+	}
+}
+
+func (server *OSPFV2Server) GenerateAllNssaLSA(areaId uint32) {
+	if server.globalData.ASBdrRtrStatus == false {
+		return
+	}
+	areaEnt, err := server.GetAreaConfForGivenArea(areaId)
+	if err != nil {
+		server.logger.Err("No such area exist")
+		return
+	}
+	if areaEnt.AdminState == false ||
+		areaEnt.AreaType != objects.AREA_TYPE_NSSA {
+		return
+	}
+	lsdbKey := LsdbKey{
+		AreaId: areaId,
+	}
+	for route, _ := range server.LsdbData.ExtRouteInfoMap {
+		lsaKey := LsaKey{
+			LSType:    NSSALSA,
+			LSId:      route.NwAddr & route.Netmask,
+			AdvRouter: server.globalData.RouterId,
+		}
+		server.installSelfOrigExternalLsa(lsdbKey, lsaKey, server.buildNssaLsa(route, areaId))
+	}
+}
+
+func (server *OSPFV2Server) isLocalExtRoute(nwAddr, netmask uint32) bool {
+	if server.globalData.ASBdrRtrStatus == false {
+		return false
+	}
+	for route, _ := range server.LsdbData.ExtRouteInfoMap {
+		if route.Netmask == netmask &&
+			route.NwAddr&route.Netmask == nwAddr&netmask {
+			return true
+		}
+	}
+	return false
+}
+
+func sameExternalLsa(lsa1, lsa2 ASExternalLsa) bool {
+	return lsa1.LsaMd.Options == lsa2.LsaMd.Options &&
+		lsa1.Netmask == lsa2.Netmask &&
+		lsa1.BitE == lsa2.BitE &&
+		lsa1.Metric == lsa2.Metric &&
+		lsa1.FwdAddr == lsa2.FwdAddr &&
+		lsa1.ExtRouteTag == lsa2.ExtRouteTag
+}
+
+// generateNssaDefaultLSA originates a Type-7 default into the NSSA
+// from the NSSA border router, P-bit is clear so it never gets
+// translated, Rfc 3101 2.7
+func (server *OSPFV2Server) generateNssaDefaultLSA(areaId uint32) {
+	if server.isLocalExtRoute(0, 0) {
+		return
+	}
+	areaEnt, exist := server.AreaConfMap[areaId]
+	if !exist {
+		return
+	}
+	lsdbKey := LsdbKey{
+		AreaId: areaId,
+	}
+	lsaKey := LsaKey{
+		LSType:    NSSALSA,
+		LSId:      0,
+		AdvRouter: server.globalData.RouterId,
+	}
+	var lsa ASExternalLsa
+	lsa.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + 16)
+	lsa.BitE = true
+	lsa.Metric = areaEnt.StubDefaultCost
+	oldEnt, exist := server.getNssaLsaFromLsdb(areaId, lsaKey)
+	if exist && oldEnt.LsaMd.LSAge != MAX_AGE &&
+		sameExternalLsa(oldEnt, lsa) {
+		return
+	}
+	server.installSelfOrigExternalLsa(lsdbKey, lsaKey, lsa)
+}
+
+func (server *OSPFV2Server) flushNssaDefaultLSA(areaId uint32) {
+	if server.isLocalExtRoute(0, 0) {
+		return
+	}
+	lsdbKey := LsdbKey{
+		AreaId: areaId,
+	}
+	lsaKey := LsaKey{
+		LSType:    NSSALSA,
+		LSId:      0,
+		AdvRouter: server.globalData.RouterId,
+	}
+	server.flushSelfOrigExternalLsa(lsdbKey, lsaKey)
+}
+
+// isNssaTranslatorAlways tells if the router unconditionally translates
+// the Type-7 LSAs of the NSSA, in which case it sets the Nt bit of its
+// router-LSA in the area
+func (server *OSPFV2Server) isNssaTranslatorAlways(areaId uint32) bool {
+	areaEnt, exist := server.AreaConfMap[areaId]
+	if !exist {
+		return false
+	}
+	return areaEnt.AreaType == objects.AREA_TYPE_NSSA &&
+		areaEnt.NssaTranslatorRole == objects.NSSA_TRANSLATOR_ROLE_ALWAYS
+}
+
+// isAreaBdrRtrReachable tells if the intra-area routes of the last SPF
+// calculation of the area reach the given area border router
+func (server *OSPFV2Server) isAreaBdrRtrReachable(areaId, rtrId uint32) bool {
+	areaIdKey := AreaIdKey{
+		AreaId: areaId,
+	}
+	areaRoutingTbl, exist := server.RoutingTblData.IntraAreaRoutingTbl[areaIdKey]
+	if !exist {
+		return false
+	}
+	for _, destType := range []DestType{AreaBdrRouter, ASAreaBdrRouter} {
+		rKey := RoutingTblEntryKey{
+			DestId:   rtrId,
+			AddrMask: 0,
+			DestType: destType,
+		}
+		if _, exist := areaRoutingTbl.RoutingTblMap[rKey]; exist {
+			return true
+		}
+	}
+	return false
+}
+
+// isNssaTranslator elects the Type-7 to Type-5 translator of the NSSA,
+// Rfc 3101 3.1. A candidate translates unless one of the NSSA border
+// routers reachable through the area has the Nt bit set or has a
+// higher router id.
+func (server *OSPFV2Server) isNssaTranslator(areaId uint32) bool {
+	if server.isNssaTranslatorAlways(areaId) {
+		return true
+	}
+	lsdbKey := LsdbKey{
+		AreaId: areaId,
+	}
+	lsdbEnt, exist := server.LsdbData.AreaLsdb[lsdbKey]
+	if !exist {
+		return false
+	}
+	rtrId := server.globalData.RouterId
+	elected := true
+	for lsaKey, lsaEnt := range lsdbEnt.RouterLsaMap {
+		if lsaKey.AdvRouter == rtrId ||
+			lsaEnt.BitB == false ||
+			lsaEnt.LsaMd.LSAge == MAX_AGE ||
+			!server.isAreaBdrRtrReachable(areaId, lsaKey.AdvRouter) {
+			continue
+		}
+		if lsaEnt.BitNt {
+			return false
+		}
+		if lsaKey.AdvRouter > rtrId {
+			elected = false
+		}
+	}
+	return elected
+}
+
+// translateNssaLsa builds AS External LSAs for the Type-7 LSAs of the
+// given area which have P-bit set, Rfc 3101 3.2
+func (server *OSPFV2Server) translateNssaLsa(areaId uint32, translatedMap map[LsaKey]ASExternalLsa) {
+	lsdbKey := LsdbKey{
+		AreaId: areaId,
+	}
+	lsdbEnt, exist := server.LsdbData.AreaLsdb[lsdbKey]
+	if !exist {
+		return
+	}
+	rtrId := server.globalData.RouterId
+	for lsaKey, lsaEnt := range lsdbEnt.NssaLsaMap {
+		if lsaKey.AdvRouter == rtrId ||
+			lsaEnt.LsaMd.LSAge == MAX_AGE ||
+			lsaEnt.Metric == LSInfinity ||
+			lsaEnt.LsaMd.Options&NPOption == 0 ||
+			lsaEnt.FwdAddr == 0 {
+			continue
+		}
+		lsId := lsaKey.LSId & lsaEnt.Netmask
+		if server.isLocalExtRoute(lsId, lsaEnt.Netmask) {
+			// Locally redistributed route takes precedence
+			continue
+		}
+		key := LsaKey{
+			LSType:    ASExternalLSA,
+			LSId:      lsId,
+			AdvRouter: rtrId,
+		}
+		var lsa ASExternalLsa
+		lsa.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + 16)
+		lsa.LsaMd.Options = EOption
+		lsa.Netmask = lsaEnt.Netmask
+		lsa.BitE = lsaEnt.BitE
+		lsa.Metric = lsaEnt.Metric
+		lsa.FwdAddr = lsaEnt.FwdAddr
+		lsa.ExtRouteTag = lsaEnt.ExtRouteTag
+		oldEnt, exist := translatedMap[key]
+		if exist {
+			// Type 1 external metric is preferred over Type 2
+			// and then the lower metric
+			if oldEnt.BitE != lsa.BitE {
+				if lsa.BitE {
+					continue
+				}
+			} else if oldEnt.Metric <= lsa.Metric {
+				continue
+			}
+		}
+		translatedMap[key] = lsa
+	}
+}
+
+func (server *OSPFV2Server) installTranslatedLSA(lsaKey LsaKey, lsa ASExternalLsa) {
+	for areaId, areaEnt := range server.AreaConfMap {
+		if areaEnt.AdminState == false ||
+			areaEnt.ImportASExtern == false {
+			continue
+		}
+		oldEnt, exist := server.getASExternalLsaFromLsdb(areaId, lsaKey)
+		if exist && oldEnt.LsaMd.LSAge != MAX_AGE &&
+			sameExternalLsa(oldEnt, lsa) {
+			continue
+		}
+		lsdbKey := LsdbKey{
+			AreaId: areaId,
+		}
+		server.installSelfOrigExternalLsa(lsdbKey, lsaKey, lsa)
+	}
+}
+
+func (server *OSPFV2Server) flushTranslatedLSA(lsaKey LsaKey, netmask uint32) {
+	if server.isLocalExtRoute(lsaKey.LSId, netmask) {
+		// LSA is now owned by the local redistribution
+		return
+	}
+	for areaId, _ := range server.AreaConfMap {
+		lsdbKey := LsdbKey{
+			AreaId: areaId,
+		}
+		server.flushSelfOrigExternalLsa(lsdbKey, lsaKey)
+	}
+}
+
+// processNssaAbrLsa takes care of the NSSA border router duties, it
+// originates the Type-7 default into each NSSA configured to get one
+// and translates Type-7 LSAs into AS External LSAs when elected as
+// translator
+func (server *OSPFV2Server) processNssaAbrLsa() {
+	translatedMap := make(map[LsaKey]ASExternalLsa)
+	for areaId, areaEnt := range server.AreaConfMap {
+		if areaEnt.AdminState == false ||
+			areaEnt.AreaType != objects.AREA_TYPE_NSSA {
+			continue
+		}
+		if server.globalData.AreaBdrRtrStatus == false {
+			server.flushNssaDefaultLSA(areaId)
+			continue
+		}
+		if areaEnt.NssaDefaultOriginate {
+			server.generateNssaDefaultLSA(areaId)
+		} else {
+			server.flushNssaDefaultLSA(areaId)
+		}
+		if server.isNssaTranslator(areaId) {
+			server.translateNssaLsa(areaId, translatedMap)
+		}
+	}
+	for lsaKey, lsaEnt := range translatedMap {
+		server.installTranslatedLSA(lsaKey, lsaEnt)
+	}
+	for lsaKey, lsaEnt := range server.LsdbData.NssaTranslatedLsaMap {
+		if _, exist := translatedMap[lsaKey]; !exist {
+			server.flushTranslatedLSA(lsaKey, lsaEnt.Netmask)
+		}
+	}
+	server.LsdbData.NssaTranslatedLsaMap = translatedMap
+}
@@ -0,0 +1,173 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"l3/ospfv2/objects"
+	"testing"
+)
+
+const testNssaAreaId uint32 = 1
+
+// addTestNssaRtr adds the router-LSA of another router of the NSSA
+func addTestNssaRtr(server *OSPFV2Server, rtrId string, bitB, bitNt, reachable bool) {
+	advRtr, _ := convertDotNotationToUint32(rtrId)
+	lsaKey := LsaKey{
+		LSType:    RouterLSA,
+		LSId:      advRtr,
+		AdvRouter: advRtr,
+	}
+	server.LsdbData.AreaLsdb[LsdbKey{AreaId: testNssaAreaId}].RouterLsaMap[lsaKey] = RouterLsa{
+		BitB:  bitB,
+		BitNt: bitNt,
+	}
+	rKey := RoutingTblEntryKey{
+		DestId:   advRtr,
+		DestType: InternalRouter,
+	}
+	if bitB {
+		rKey.DestType = AreaBdrRouter
+	}
+	routingTblMap := server.RoutingTblData.IntraAreaRoutingTbl[AreaIdKey{AreaId: testNssaAreaId}].RoutingTblMap
+	if reachable {
+		routingTblMap[rKey] = RoutingTblEntry{}
+	} else {
+		delete(routingTblMap, rKey)
+	}
+}
+
+func getTestNssaDefaultLsa(server *OSPFV2Server) (ASExternalLsa, bool) {
+	lsaKey := LsaKey{
+		LSType:    NSSALSA,
+		LSId:      0,
+		AdvRouter: server.globalData.RouterId,
+	}
+	return server.getNssaLsaFromLsdb(testNssaAreaId, lsaKey)
+}
+
+// Rfc 3101 3.1
+func TestNssaTranslatorElection(t *testing.T) {
+	server := newTestServer("2.2.2.2")
+	server.globalData.AreaBdrRtrStatus = true
+	addTestArea(server, testNssaAreaId, AreaConf{
+		AreaType:        objects.AREA_TYPE_NSSA,
+		StubDefaultCost: 5,
+	})
+	if !server.isNssaTranslator(testNssaAreaId) {
+		t.Error("Only NSSA border router not elected")
+	}
+	addTestNssaRtr(server, "4.4.4.4", false, false, true)
+	if !server.isNssaTranslator(testNssaAreaId) {
+		t.Error("Internal router with higher router id takes part in the election")
+	}
+	addTestNssaRtr(server, "3.3.3.3", true, false, false)
+	if !server.isNssaTranslator(testNssaAreaId) {
+		t.Error("Unreachable border router with higher router id takes part in the election")
+	}
+	addTestNssaRtr(server, "3.3.3.3", true, false, true)
+	if server.isNssaTranslator(testNssaAreaId) {
+		t.Error("Elected although a reachable border router has a higher router id")
+	}
+
+	addTestNssaRtr(server, "3.3.3.3", true, false, false)
+	addTestNssaRtr(server, "1.1.1.1", true, true, true)
+	if server.isNssaTranslator(testNssaAreaId) {
+		t.Error("Elected although a reachable border router has the Nt bit set")
+	}
+	addTestNssaRtr(server, "1.1.1.1", true, true, false)
+	if !server.isNssaTranslator(testNssaAreaId) {
+		t.Error("Unreachable border router with the Nt bit set prevents the election")
+	}
+
+	addTestNssaRtr(server, "1.1.1.1", true, true, true)
+	areaEnt := server.AreaConfMap[testNssaAreaId]
+	areaEnt.NssaTranslatorRole = objects.NSSA_TRANSLATOR_ROLE_ALWAYS
+	server.AreaConfMap[testNssaAreaId] = areaEnt
+	if !server.isNssaTranslator(testNssaAreaId) {
+		t.Error("Router always translating is not translator")
+	}
+}
+
+func TestNssaDefaultOriginate(t *testing.T) {
+	server := newTestServer("2.2.2.2")
+	server.globalData.AreaBdrRtrStatus = true
+	addTestArea(server, testNssaAreaId, AreaConf{
+		AreaType:        objects.AREA_TYPE_NSSA,
+		StubDefaultCost: 5,
+	})
+	server.processNssaAbrLsa()
+	if _, exist := getTestNssaDefaultLsa(server); exist {
+		t.Error("Type-7 default originated without being configured")
+	}
+
+	areaEnt := server.AreaConfMap[testNssaAreaId]
+	areaEnt.NssaDefaultOriginate = true
+	server.AreaConfMap[testNssaAreaId] = areaEnt
+	server.processNssaAbrLsa()
+	lsa, exist := getTestNssaDefaultLsa(server)
+	if !exist {
+		t.Fatal("Type-7 default not originated")
+	}
+	if lsa.Metric != areaEnt.StubDefaultCost || lsa.LsaMd.Options&NPOption != 0 {
+		t.Error("Unexpected Type-7 default", lsa.Metric, lsa.LsaMd.Options)
+	}
+
+	server.globalData.AreaBdrRtrStatus = false
+	server.processNssaAbrLsa()
+	if _, exist := getTestNssaDefaultLsa(server); exist {
+		t.Error("Type-7 default not flushed when no longer border router")
+	}
+
+	server.globalData.AreaBdrRtrStatus = true
+	server.processNssaAbrLsa()
+	areaEnt.NssaDefaultOriginate = false
+	server.AreaConfMap[testNssaAreaId] = areaEnt
+	server.processNssaAbrLsa()
+	if _, exist := getTestNssaDefaultLsa(server); exist {
+		t.Error("Type-7 default not flushed when unconfigured")
+	}
+}
+
+func TestRouterLsaNtBit(t *testing.T) {
+	lsaKey := LsaKey{
+		LSType:    RouterLSA,
+		LSId:      1,
+		AdvRouter: 1,
+	}
+	lsa := RouterLsa{
+		BitB:  true,
+		BitNt: true,
+	}
+	lsa.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + 4)
+	lsaEnc := encodeRouterLsa(lsa, lsaKey)
+	if lsaEnc[20] != 0x11 {
+		t.Error("Unexpected router-LSA flags", lsaEnc[20])
+	}
+	var decLsa RouterLsa
+	var decKey LsaKey
+	decodeRouterLsa(lsaEnc, &decLsa, &decKey)
+	if !decLsa.BitNt || !decLsa.BitB || decLsa.BitE || decLsa.BitV {
+		t.Error("Unexpected decoded router-LSA flags", decLsa)
+	}
+}
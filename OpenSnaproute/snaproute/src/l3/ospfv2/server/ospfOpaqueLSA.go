@@ -0,0 +1,550 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"l3/ospfv2/objects"
+)
+
+/*
+Opaque LSA (Rfc 5250)
+
+	0                   1                   2                   3
+	0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1 2 3 4 5 6 7 8 9 0 1
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	|            LS age             |     Options   |  9, 10 or 11  |
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	|  Opaque Type  |               Opaque ID                       |
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	|                      Advertising Router                       |
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	|                      LS Sequence Number                       |
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	|         LS checksum           |           Length              |
+	+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+-+
+	|                                                               |
+	+                                                               +
+	|                      Opaque Information                       |
+	+                                                               +
+	|                              ...                              |
+*/
+
+const (
+	OPAQUE_ID_MASK    uint32 = 0x00ffffff
+	OPAQUE_TYPE_SHIFT        = 24
+)
+
+type OpaqueLsa struct {
+	LsaMd   LsaMetadata
+	IntfKey IntfConfKey // Only for link local scope
+	Data    []byte
+}
+
+// Link local opaque LSAs with the same key may be
+// received on different links of the same area
+type OpaqueLinkLsaKey struct {
+	IntfKey IntfConfKey
+	LsaKey  LsaKey
+}
+
+func NewOpaqueLsa() *OpaqueLsa {
+	return &OpaqueLsa{}
+}
+
+func isOpaqueLsa(lsType uint8) bool {
+	return lsType == OpaqueLinkLSA ||
+		lsType == OpaqueAreaLSA ||
+		lsType == OpaqueASLSA
+}
+
+func getOpaqueLSId(opaqueType uint8, opaqueId uint32) uint32 {
+	return uint32(opaqueType)<<OPAQUE_TYPE_SHIFT | (opaqueId & OPAQUE_ID_MASK)
+}
+
+func getOpaqueType(lsId uint32) uint8 {
+	return uint8(lsId >> OPAQUE_TYPE_SHIFT)
+}
+
+func getOpaqueId(lsId uint32) uint32 {
+	return lsId & OPAQUE_ID_MASK
+}
+
+func encodeOpaqueLsa(lsa OpaqueLsa, lsakey LsaKey) []byte {
+	oLsa := make([]byte, lsa.LsaMd.LSLen)
+	lsaHdr := encodeLsaHeader(lsa.LsaMd, lsakey)
+	copy(oLsa[0:OSPF_LSA_HEADER_SIZE], lsaHdr)
+	copy(oLsa[OSPF_LSA_HEADER_SIZE:], lsa.Data)
+	return oLsa
+}
+
+func decodeOpaqueLsa(data []byte, lsa *OpaqueLsa, lsakey *LsaKey) error {
+	if len(data) < OSPF_LSA_HEADER_SIZE {
+		return errors.New(fmt.Sprintln("Opaque LSA shorter than its header, len", len(data)))
+	}
+	lsa.LsaMd.LSAge = binary.BigEndian.Uint16(data[0:2])
+	lsa.LsaMd.Options = uint8(data[2])
+	lsakey.LSType = uint8(data[3])
+	lsakey.LSId = binary.BigEndian.Uint32(data[4:8])
+	lsakey.AdvRouter = binary.BigEndian.Uint32(data[8:12])
+	lsa.LsaMd.LSSequenceNum = int(binary.BigEndian.Uint32(data[12:16]))
+	lsa.LsaMd.LSChecksum = binary.BigEndian.Uint16(data[16:18])
+	lsa.LsaMd.LSLen = binary.BigEndian.Uint16(data[18:20])
+	end := int(lsa.LsaMd.LSLen)
+	if end < OSPF_LSA_HEADER_SIZE || end > len(data) {
+		return errors.New(fmt.Sprintln("Invalid opaque LSA length", end, "data len", len(data)))
+	}
+	lsa.Data = make([]byte, end-OSPF_LSA_HEADER_SIZE)
+	copy(lsa.Data, data[OSPF_LSA_HEADER_SIZE:end])
+	return nil
+}
+
+// Opaque LSAs are only sent to nbrs which set the O-bit in their DD packets
+func isNbrOpaqueCapable(nbrConf NbrConf) bool {
+	return (nbrConf.NbrOption & OOption) != 0
+}
+
+func (server *OSPFV2Server) isIntfOpaqueCapable(intfKey IntfConfKey) bool {
+	for _, nbrKey := range server.NbrConfData.IntfToNbrMap[intfKey] {
+		nbrConf, exist := server.NbrConfMap[nbrKey]
+		if exist && nbrConf.State >= NbrExchange &&
+			isNbrOpaqueCapable(nbrConf) {
+			return true
+		}
+	}
+	return false
+}
+
+// isOpaqueLsaFloodAllowed checks the flooding scope of the opaque LSA
+// against the interface it is going to be sent on
+func (server *OSPFV2Server) isOpaqueLsaFloodAllowed(lsType uint8, lsaIntfKey, intfKey IntfConfKey, intf IntfConf) bool {
+	switch lsType {
+	case OpaqueLinkLSA:
+		return lsaIntfKey == intfKey
+	case OpaqueASLSA:
+		// Same flooding scope as AS External LSAs
+		return intf.Type != objects.INTF_TYPE_VIRTUAL &&
+			server.isExternalLsaAllowed(intf.AreaId, OpaqueASLSA)
+	}
+	return true
+}
+
+func getOpaqueLsaMap(lsdbEnt LSDatabase, lsType uint8) map[LsaKey]OpaqueLsa {
+	if lsType == OpaqueASLSA {
+		return lsdbEnt.OpaqueASLsaMap
+	}
+	return lsdbEnt.OpaqueAreaLsaMap
+}
+
+func (server *OSPFV2Server) getOpaqueLsaFromLsdb(areaId uint32, intfKey IntfConfKey, lsaKey LsaKey) (lsa OpaqueLsa, retVal bool) {
+	lsdbKey := LsdbKey{
+		AreaId: areaId,
+	}
+	lsDbEnt, exist := server.LsdbData.AreaLsdb[lsdbKey]
+	if !exist {
+		return lsa, LsdbEntryNotFound
+	}
+	switch lsaKey.LSType {
+	case OpaqueLinkLSA:
+		linkLsaKey := OpaqueLinkLsaKey{
+			IntfKey: intfKey,
+			LsaKey:  lsaKey,
+		}
+		lsa, exist = lsDbEnt.OpaqueLinkLsaMap[linkLsaKey]
+	case OpaqueAreaLSA, OpaqueASLSA:
+		lsa, exist = getOpaqueLsaMap(lsDbEnt, lsaKey.LSType)[lsaKey]
+	default:
+		exist = false
+	}
+	if !exist {
+		return lsa, LsdbEntryNotFound
+	}
+	return lsa, LsdbEntryFound
+}
+
+// getOpaqueLsaByLsaKey looks up an opaque LSA when the link it
+// belongs to is not known, used for the Lsdb state objects
+func getOpaqueLsaByLsaKey(lsDbEnt LSDatabase, lsaKey LsaKey) (OpaqueLsa, bool) {
+	if lsaKey.LSType != OpaqueLinkLSA {
+		lsa, exist := getOpaqueLsaMap(lsDbEnt, lsaKey.LSType)[lsaKey]
+		return lsa, exist
+	}
+	for linkLsaKey, lsa := range lsDbEnt.OpaqueLinkLsaMap {
+		if linkLsaKey.LsaKey == lsaKey {
+			return lsa, true
+		}
+	}
+	return OpaqueLsa{}, false
+}
+
+func (server *OSPFV2Server) sanityCheckOpaqueLsa(olsa OpaqueLsa, dolsa OpaqueLsa, nbr NbrConf, intf IntfConf, exist bool, lsa_max_age bool) (discard bool, op uint8) {
+	send_ack := server.lsAgeCheck(nbr.IntfKey, lsa_max_age, exist)
+	if send_ack {
+		server.logger.Info(fmt.Sprintln("LSAUPD: Opaque LSA Discard.", " nbr ", nbr))
+		return true, LsdbNoAction
+	}
+	isNew := server.validateLsaIsNew(olsa.LsaMd, dolsa.LsaMd)
+	if !isNew {
+		return true, LsdbNoAction
+	}
+	return false, FloodLsa
+}
+
+func (server *OSPFV2Server) isSelfOrigOpaqueLsa(lsdbKey LsdbKey, lsaKey LsaKey) bool {
+	if lsaKey.LSType == OpaqueLinkLSA {
+		// Link local opaque LSAs are not part of AreaSelfOrigLsa
+		return lsaKey.AdvRouter == server.globalData.RouterId
+	}
+	selfOrigLsaEnt, exist := server.LsdbData.AreaSelfOrigLsa[lsdbKey]
+	if !exist {
+		return false
+	}
+	_, exist = selfOrigLsaEnt[lsaKey]
+	return exist
+}
+
+func (server *OSPFV2Server) processRecvdOpaqueLSA(msg RecvdLsaMsg) error {
+	lsdbEnt, exist := server.LsdbData.AreaLsdb[msg.LsdbKey]
+	if !exist {
+		server.logger.Err("No such Area exist", msg.LsdbKey)
+		return nil
+	}
+	lsa, ok := msg.LsaData.(OpaqueLsa)
+	if !ok {
+		server.logger.Err("Unable to assert given opaque lsa")
+		return nil
+	}
+	if msg.MsgType == LSA_ADD {
+		if msg.LsaKey.LSType == OpaqueLinkLSA {
+			linkLsaKey := OpaqueLinkLsaKey{
+				IntfKey: lsa.IntfKey,
+				LsaKey:  msg.LsaKey,
+			}
+			_, exist = lsdbEnt.OpaqueLinkLsaMap[linkLsaKey]
+			lsdbEnt.OpaqueLinkLsaMap[linkLsaKey] = lsa
+		} else {
+			lsaMap := getOpaqueLsaMap(lsdbEnt, msg.LsaKey.LSType)
+			_, exist = lsaMap[msg.LsaKey]
+			lsaMap[msg.LsaKey] = lsa
+		}
+		if !exist {
+			lsdbSlice := LsdbSliceStruct{
+				LsdbKey: msg.LsdbKey,
+				LsaKey:  msg.LsaKey,
+			}
+			server.GetBulkData.LsdbSlice = append(server.GetBulkData.LsdbSlice, lsdbSlice)
+		}
+	} else if msg.MsgType == LSA_DEL {
+		if msg.LsaKey.LSType == OpaqueLinkLSA {
+			linkLsaKey := OpaqueLinkLsaKey{
+				IntfKey: lsa.IntfKey,
+				LsaKey:  msg.LsaKey,
+			}
+			delete(lsdbEnt.OpaqueLinkLsaMap, linkLsaKey)
+		} else {
+			delete(getOpaqueLsaMap(lsdbEnt, msg.LsaKey.LSType), msg.LsaKey)
+		}
+	}
+	server.LsdbData.AreaLsdb[msg.LsdbKey] = lsdbEnt
+	return nil
+}
+
+func (server *OSPFV2Server) processRecvdSelfOpaqueLSA(msg RecvdSelfLsaMsg) error {
+	lsa, ok := msg.LsaData.(OpaqueLsa)
+	if !ok {
+		server.logger.Err("Unable to assert given opaque lsa")
+		return nil
+	}
+	lsaEnt, exist := server.getOpaqueLsaFromLsdb(msg.LsdbKey.AreaId, lsa.IntfKey, msg.LsaKey)
+	if !exist || !server.isSelfOrigOpaqueLsa(msg.LsdbKey, msg.LsaKey) {
+		server.logger.Err("No such self originated opaque LSA exist", msg.LsaKey)
+		// Mark the recvd LSA as MAX_AGE and Flood
+		lsa.LsaMd.LSAge = MAX_AGE
+		server.CreateAndSendMsgFromLsdbToFloodLsa(msg.LsdbKey.AreaId, msg.LsaKey, lsa)
+		return nil
+	}
+	if lsaEnt.LsaMd.LSSequenceNum < lsa.LsaMd.LSSequenceNum {
+		lsaEnt.LsaMd.LSSequenceNum = lsa.LsaMd.LSSequenceNum
+		server.installSelfOrigOpaqueLsa(msg.LsdbKey, msg.LsaKey, lsaEnt)
+		return nil
+	}
+	// Flood Self Opaque LSA
+	server.CreateAndSendMsgFromLsdbToFloodLsa(msg.LsdbKey.AreaId, msg.LsaKey, lsaEnt)
+	return nil
+}
+
+// installSelfOrigOpaqueLsa installs a self originated opaque LSA in the
+// given area and floods it, link local LSAs are keyed by lsa.IntfKey
+func (server *OSPFV2Server) installSelfOrigOpaqueLsa(lsdbKey LsdbKey, lsaKey LsaKey, lsa OpaqueLsa) {
+	lsdbEnt, exist := server.LsdbData.AreaLsdb[lsdbKey]
+	if !exist {
+		server.logger.Err("No Lsdb Exist for:", lsdbKey)
+		return
+	}
+	oldEnt, exist := server.getOpaqueLsaFromLsdb(lsdbKey.AreaId, lsa.IntfKey, lsaKey)
+	if exist {
+		lsa.LsaMd.LSSequenceNum = oldEnt.LsaMd.LSSequenceNum + 1
+	} else {
+		lsa.LsaMd.LSSequenceNum = int(InitialSequenceNum)
+	}
+	lsa.LsaMd.LSAge = 0
+	lsa.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + len(lsa.Data))
+	lsa.LsaMd.LSChecksum = 0
+	checksumOffset := uint16(14)
+	lsaEnc := encodeOpaqueLsa(lsa, lsaKey)
+	lsa.LsaMd.LSChecksum = computeFletcherChecksum(lsaEnc[2:], checksumOffset)
+	if lsaKey.LSType == OpaqueLinkLSA {
+		linkLsaKey := OpaqueLinkLsaKey{
+			IntfKey: lsa.IntfKey,
+			LsaKey:  lsaKey,
+		}
+		lsdbEnt.OpaqueLinkLsaMap[linkLsaKey] = lsa
+	} else {
+		getOpaqueLsaMap(lsdbEnt, lsaKey.LSType)[lsaKey] = lsa
+		selfOrigLsaEnt, _ := server.LsdbData.AreaSelfOrigLsa[lsdbKey]
+		selfOrigLsaEnt[lsaKey] = true
+		server.LsdbData.AreaSelfOrigLsa[lsdbKey] = selfOrigLsaEnt
+	}
+	server.LsdbData.AreaLsdb[lsdbKey] = lsdbEnt
+	server.CreateAndSendMsgFromLsdbToFloodLsa(lsdbKey.AreaId, lsaKey, lsa)
+	if !exist {
+		lsdbSlice := LsdbSliceStruct{
+			LsdbKey: lsdbKey,
+			LsaKey:  lsaKey,
+		}
+		server.GetBulkData.LsdbSlice = append(server.GetBulkData.LsdbSlice, lsdbSlice)
+	}
+}
+
+func (server *OSPFV2Server) flushSelfOrigOpaqueLsa(lsdbKey LsdbKey, intfKey IntfConfKey, lsaKey LsaKey) {
+	lsdbEnt, exist := server.LsdbData.AreaLsdb[lsdbKey]
+	if !exist {
+		return
+	}
+	lsaEnt, exist := server.getOpaqueLsaFromLsdb(lsdbKey.AreaId, intfKey, lsaKey)
+	if !exist {
+		return
+	}
+	lsaEnt.LsaMd.LSAge = MAX_AGE
+	server.CreateAndSendMsgFromLsdbToFloodLsa(lsdbKey.AreaId, lsaKey, lsaEnt)
+	if lsaKey.LSType == OpaqueLinkLSA {
+		linkLsaKey := OpaqueLinkLsaKey{
+			IntfKey: intfKey,
+			LsaKey:  lsaKey,
+		}
+		delete(lsdbEnt.OpaqueLinkLsaMap, linkLsaKey)
+	} else {
+		delete(getOpaqueLsaMap(lsdbEnt, lsaKey.LSType), lsaKey)
+		selfOrigLsaEnt, _ := server.LsdbData.AreaSelfOrigLsa[lsdbKey]
+		delete(selfOrigLsaEnt, lsaKey)
+		server.LsdbData.AreaSelfOrigLsa[lsdbKey] = selfOrigLsaEnt
+	}
+	server.LsdbData.AreaLsdb[lsdbKey] = lsdbEnt
+}
+
+// getOpaqueLsaAreaList returns the areas in which an opaque LSA of
+// given type has to be installed as per its flooding scope
+func (server *OSPFV2Server) getOpaqueLsaAreaList(msg OpaqueLsaMsg) []uint32 {
+	var areaList []uint32
+	switch msg.LSType {
+	case OpaqueLinkLSA:
+		intf, exist := server.IntfConfMap[msg.IntfKey]
+		if exist {
+			areaList = append(areaList, intf.AreaId)
+		}
+	case OpaqueAreaLSA:
+		areaList = append(areaList, msg.AreaId)
+	case OpaqueASLSA:
+		for lsdbKey, _ := range server.LsdbData.AreaLsdb {
+			if server.isExternalLsaAllowed(lsdbKey.AreaId, OpaqueASLSA) {
+				areaList = append(areaList, lsdbKey.AreaId)
+			}
+		}
+	}
+	return areaList
+}
+
+func (server *OSPFV2Server) processOpaqueLsaMsg(msg OpaqueLsaMsg) {
+	lsaKey := LsaKey{
+		LSType:    msg.LSType,
+		LSId:      getOpaqueLSId(msg.OpaqueType, msg.OpaqueId),
+		AdvRouter: server.globalData.RouterId,
+	}
+	for _, areaId := range server.getOpaqueLsaAreaList(msg) {
+		lsdbKey := LsdbKey{
+			AreaId: areaId,
+		}
+		if msg.Op == FLUSH {
+			server.flushSelfOrigOpaqueLsa(lsdbKey, msg.IntfKey, lsaKey)
+			continue
+		}
+		lsa := OpaqueLsa{
+			IntfKey: msg.IntfKey,
+			Data:    msg.Data,
+		}
+		lsa.LsaMd.Options = server.getAreaLsaOptions(areaId)
+		server.installSelfOrigOpaqueLsa(lsdbKey, lsaKey, lsa)
+	}
+}
+
+func (server *OSPFV2Server) validateOpaqueLsaMsg(msg OpaqueLsaMsg) error {
+	if server.globalData.AdminState == false {
+		return errors.New("Ospf is not enabled")
+	}
+	switch msg.LSType {
+	case OpaqueLinkLSA:
+		_, exist := server.IntfConfMap[msg.IntfKey]
+		if !exist {
+			return errors.New("No such interface exist")
+		}
+	case OpaqueAreaLSA:
+		_, exist := server.AreaConfMap[msg.AreaId]
+		if !exist {
+			return errors.New("No such area exist")
+		}
+	case OpaqueASLSA:
+	default:
+		return errors.New(fmt.Sprintln("Invalid opaque LS type:", msg.LSType))
+	}
+	if msg.OpaqueId > OPAQUE_ID_MASK {
+		return errors.New(fmt.Sprintln("Invalid opaque id:", msg.OpaqueId))
+	}
+	if len(msg.Data)%4 != 0 {
+		return errors.New("Opaque information is not 32 bit aligned")
+	}
+	return nil
+}
+
+// OriginateOpaqueLsa originates or updates a self originated opaque
+// LSA, intfKey is used for link local and areaId for area local scope.
+// It must not be called from the Lsdb routine.
+func (server *OSPFV2Server) OriginateOpaqueLsa(lsType uint8, areaId uint32, intfKey IntfConfKey, opaqueType uint8, opaqueId uint32, data []byte) error {
+	msg := OpaqueLsaMsg{
+		Op:         GENERATE,
+		LSType:     lsType,
+		AreaId:     areaId,
+		IntfKey:    intfKey,
+		OpaqueType: opaqueType,
+		OpaqueId:   opaqueId,
+		Data:       data,
+	}
+	err := server.validateOpaqueLsaMsg(msg)
+	if err != nil {
+		return err
+	}
+	server.MessagingChData.ServerToLsdbChData.OpaqueLsaUpdateCh <- msg
+	return nil
+}
+
+// WithdrawOpaqueLsa flushes an opaque LSA originated using
+// OriginateOpaqueLsa
+func (server *OSPFV2Server) WithdrawOpaqueLsa(lsType uint8, areaId uint32, intfKey IntfConfKey, opaqueType uint8, opaqueId uint32) error {
+	msg := OpaqueLsaMsg{
+		Op:         FLUSH,
+		LSType:     lsType,
+		AreaId:     areaId,
+		IntfKey:    intfKey,
+		OpaqueType: opaqueType,
+		OpaqueId:   opaqueId,
+	}
+	err := server.validateOpaqueLsaMsg(msg)
+	if err != nil {
+		return err
+	}
+	server.MessagingChData.ServerToLsdbChData.OpaqueLsaUpdateCh <- msg
+	return nil
+}
+
+func (server *OSPFV2Server) processLsdbAgeOpaqueLsa(lsdbKey LsdbKey, lsaKey LsaKey, lsa *OpaqueLsa) (LsdbToFloodLSAMsg, bool) {
+	var msg LsdbToFloodLSAMsg
+	//Increment LSA age
+	if lsa.LsaMd.LSAge < MAX_AGE {
+		lsa.LsaMd.LSAge++
+	}
+	//If Age = multiples of CheckAge compute checksum and verify if error raise an alarm
+	if (lsa.LsaMd.LSAge % CHECK_AGE) == 0 {
+		lsaEnc := encodeOpaqueLsa(*lsa, lsaKey)
+		cSum := computeFletcherChecksum(lsaEnc[2:], FLETCHER_CHECKSUM_VALIDATE)
+		if cSum != 0 {
+			server.logger.Err("Some serious problem, may be memory corruption")
+			return msg, false
+		}
+	}
+	if lsa.LsaMd.LSAge == MAX_AGE {
+		msg.AreaId = lsdbKey.AreaId
+		msg.LsaKey = lsaKey
+		msg.LsaData = *lsa
+		return msg, true
+	}
+	return msg, false
+}
+
+// processLsdbAgingOpaqueLsa ages the opaque LSAs of the area, self
+// originated ones are refreshed at LSRefreshTime and the others are
+// flushed once they reach MaxAge
+func (server *OSPFV2Server) processLsdbAgingOpaqueLsa(lsdbKey LsdbKey, lsdbEnt LSDatabase) []LsdbToFloodLSAMsg {
+	var lsdbToFloodLSAMsgList []LsdbToFloodLSAMsg
+	var refreshList []OpaqueLinkLsaKey
+	for linkLsaKey, lsaEnt := range lsdbEnt.OpaqueLinkLsaMap {
+		self := server.isSelfOrigOpaqueLsa(lsdbKey, linkLsaKey.LsaKey)
+		lsdbToFloodLSAMsg, flag := server.processLsdbAgeOpaqueLsa(lsdbKey, linkLsaKey.LsaKey, &lsaEnt)
+		if flag == true && !self {
+			lsdbToFloodLSAMsgList = append(lsdbToFloodLSAMsgList, lsdbToFloodLSAMsg)
+		}
+		if lsaEnt.LsaMd.LSAge == MAX_AGE {
+			delete(lsdbEnt.OpaqueLinkLsaMap, linkLsaKey)
+			continue
+		}
+		lsdbEnt.OpaqueLinkLsaMap[linkLsaKey] = lsaEnt
+		if self && lsaEnt.LsaMd.LSAge == LS_REFRESH_TIME {
+			refreshList = append(refreshList, linkLsaKey)
+		}
+	}
+	for _, lsaMap := range []map[LsaKey]OpaqueLsa{lsdbEnt.OpaqueAreaLsaMap, lsdbEnt.OpaqueASLsaMap} {
+		for lsaKey, lsaEnt := range lsaMap {
+			self := server.isSelfOrigOpaqueLsa(lsdbKey, lsaKey)
+			lsdbToFloodLSAMsg, flag := server.processLsdbAgeOpaqueLsa(lsdbKey, lsaKey, &lsaEnt)
+			if flag == true && !self {
+				lsdbToFloodLSAMsgList = append(lsdbToFloodLSAMsgList, lsdbToFloodLSAMsg)
+			}
+			if lsaEnt.LsaMd.LSAge == MAX_AGE {
+				delete(lsaMap, lsaKey)
+				continue
+			}
+			lsaMap[lsaKey] = lsaEnt
+			if self && lsaEnt.LsaMd.LSAge == LS_REFRESH_TIME {
+				refreshList = append(refreshList, OpaqueLinkLsaKey{LsaKey: lsaKey})
+			}
+		}
+	}
+	for _, linkLsaKey := range refreshList {
+		lsaEnt, exist := server.getOpaqueLsaFromLsdb(lsdbKey.AreaId, linkLsaKey.IntfKey, linkLsaKey.LsaKey)
+		if exist {
+			server.installSelfOrigOpaqueLsa(lsdbKey, linkLsaKey.LsaKey, lsaEnt)
+		}
+	}
+	return lsdbToFloodLSAMsgList
+}
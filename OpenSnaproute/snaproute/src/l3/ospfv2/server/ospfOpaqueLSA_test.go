@@ -0,0 +1,95 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"encoding/binary"
+	"l3/ospfv2/objects"
+	"testing"
+)
+
+func makeTestOpaqueLsa(lsLen uint16, dataLen int) []byte {
+	lsa := make([]byte, dataLen)
+	lsa[3] = OpaqueLinkLSA
+	binary.BigEndian.PutUint32(lsa[4:8], getOpaqueLSId(GRACE_LSA_OPAQUE_TYPE, 1))
+	binary.BigEndian.PutUint32(lsa[8:12], testIp("2.2.2.2"))
+	binary.BigEndian.PutUint16(lsa[18:20], lsLen)
+	return lsa
+}
+
+func TestDecodeOpaqueLsaMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"short data", make([]byte, OSPF_LSA_HEADER_SIZE-1)},
+		{"length below header", makeTestOpaqueLsa(OSPF_LSA_HEADER_SIZE-4, OSPF_LSA_HEADER_SIZE)},
+		{"length beyond data", makeTestOpaqueLsa(OSPF_LSA_HEADER_SIZE+8, OSPF_LSA_HEADER_SIZE+4)},
+	}
+	for _, test := range tests {
+		err := decodeOpaqueLsa(test.data, NewOpaqueLsa(), NewLsaKey())
+		if err == nil {
+			t.Error(test.name, ": malformed opaque LSA decoded without error")
+		}
+	}
+
+	lsa := makeTestOpaqueLsa(OSPF_LSA_HEADER_SIZE+4, OSPF_LSA_HEADER_SIZE+8)
+	olsa := NewOpaqueLsa()
+	err := decodeOpaqueLsa(lsa, olsa, NewLsaKey())
+	if err != nil || len(olsa.Data) != 4 {
+		t.Error("Failed to decode opaque LSA, err ", err, " data ", olsa.Data)
+	}
+}
+
+func TestLsaUpdMalformedOpaqueLsa(t *testing.T) {
+	server := newTestServer("1.1.1.1")
+	addTestArea(server, 0, AreaConf{})
+	intfKey := addTestIntf(server, 0, "10.0.0.1", objects.INTF_TYPE_POINT2POINT, objects.INTF_FSM_STATE_P2P)
+	nbrKey := addTestP2MPNbr(server, intfKey, "10.0.0.2", "2.2.2.2", NbrFull)
+	server.MessagingChData.NbrFSMToLsdbChData.RecvdLsaMsgCh = make(chan RecvdLsaMsg, testChanLen)
+	server.MessagingChData.NbrFSMToFloodChData.LsaFloodCh = make(chan NbrToFloodMsg, testChanLen)
+
+	lsuPkts := [][]byte{
+		// LSA count without any LSA
+		{0, 0, 0, 1},
+		// LSA length shorter than the LSA header
+		append([]byte{0, 0, 0, 1}, makeTestOpaqueLsa(4, OSPF_LSA_HEADER_SIZE+4)...),
+		// LSA length beyond the end of the pkt
+		append([]byte{0, 0, 0, 1}, makeTestOpaqueLsa(OSPF_LSA_HEADER_SIZE+64, OSPF_LSA_HEADER_SIZE+4)...),
+		// No LSA count
+		{0, 0},
+	}
+	for _, data := range lsuPkts {
+		server.ProcessLsaUpd(NbrLsaUpdMsg{
+			nbrKey: nbrKey,
+			data:   data,
+		})
+	}
+	if len(server.MessagingChData.NbrFSMToLsdbChData.RecvdLsaMsgCh) != 0 {
+		t.Error("Malformed opaque LSA sent to the LSDB")
+	}
+	if len(server.MessagingChData.NbrFSMToFloodChData.LsaFloodCh) != 0 {
+		t.Error("Malformed opaque LSA flooded")
+	}
+}
@@ -0,0 +1,103 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"l3/ospfv2/objects"
+	"time"
+	"utils/commonDefs"
+)
+
+func (server *OSPFV2Server) isIntfPassive(ent IntfConf) bool {
+	if ent.Type == objects.INTF_TYPE_VIRTUAL ||
+		ent.IfType == commonDefs.IfTypeLoopback {
+		return false
+	}
+	switch ent.Passive {
+	case objects.INTF_PASSIVE_TRUE:
+		return true
+	case objects.INTF_PASSIVE_FALSE:
+		return false
+	}
+	return server.globalData.PassiveIntfDefault
+}
+
+/*
+Passive interfaces have no Hello, Rx/Tx or nbr machinery.
+On multi-access networks the router is the DR of a network
+without other routers, otherwise the interface is point-to-point.
+*/
+func (server *OSPFV2Server) StartPassiveIntf(key IntfConfKey) {
+	ent, _ := server.IntfConfMap[key]
+	if isMultiAccessIntf(ent.Type) {
+		ent.FSMState = objects.INTF_FSM_STATE_DR
+		ent.DRIpAddr = ent.IpAddr
+		ent.DRtrId = server.globalData.RouterId
+	} else {
+		ent.FSMState = objects.INTF_FSM_STATE_P2P
+	}
+	ent.NumOfStateChange++
+	ent.TimeOfStateChange = time.Now().String()
+	server.IntfConfMap[key] = ent
+	server.logger.Info("Passive interface up", key)
+	server.SendMsgToGenerateRouterLSA(ent.AreaId)
+}
+
+func (server *OSPFV2Server) StopPassiveIntf(key IntfConfKey) {
+	ent, _ := server.IntfConfMap[key]
+	ent.FSMState = objects.INTF_FSM_STATE_DOWN
+	ent.DRIpAddr = 0
+	ent.DRtrId = 0
+	ent.NumOfStateChange++
+	ent.TimeOfStateChange = time.Now().String()
+	server.IntfConfMap[key] = ent
+	server.logger.Info("Passive interface down", key)
+	server.SendMsgToGenerateRouterLSA(ent.AreaId)
+}
+
+/*
+Only the interfaces following the global default are restarted,
+the running adjacencies on the other interfaces are kept.
+*/
+func (server *OSPFV2Server) updateGlobalPassiveIntfDefault(passiveIntfDefault bool) {
+	if server.globalData.PassiveIntfDefault == passiveIntfDefault {
+		return
+	}
+	var intfList []IntfConfKey
+	for intfKey, intfEnt := range server.IntfConfMap {
+		if intfEnt.Passive == objects.INTF_PASSIVE_DEFAULT &&
+			intfEnt.Type != objects.INTF_TYPE_VIRTUAL &&
+			intfEnt.IfType != commonDefs.IfTypeLoopback {
+			intfList = append(intfList, intfKey)
+		}
+	}
+	for _, intfKey := range intfList {
+		server.StopIntfFSM(intfKey)
+	}
+	server.globalData.PassiveIntfDefault = passiveIntfDefault
+	for _, intfKey := range intfList {
+		server.StartIntfFSM(intfKey)
+	}
+	server.logger.Info("Passive interface default updated to", passiveIntfDefault)
+}
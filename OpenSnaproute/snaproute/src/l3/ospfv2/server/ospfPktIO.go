@@ -0,0 +1,243 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"fmt"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/pcap"
+	"io"
+	"l3/ospfv2/objects"
+	"net"
+	"sync"
+)
+
+/*
+PktIOParams describes the link an ospf interface sends
+and receives its packets on
+*/
+type PktIOParams struct {
+	IfName        string
+	IpAddr        uint32
+	IfMacAddr     net.HardwareAddr
+	IntfType      uint8
+	AreaId        uint32
+	VirtNbrIpAddr uint32 // Virtual link only
+}
+
+// PktTxHandle sends complete ethernet frames on a link
+type PktTxHandle interface {
+	WritePacketData(data []byte) error
+	Close()
+}
+
+/*
+PktRxHandle returns the ospf ethernet frames received on a link,
+frames sent by the interface itself are not returned. Interfaces
+with static nbrs also get the ARP frames of their address. Once closed
+ReadPacketData returns io.EOF.
+*/
+type PktRxHandle interface {
+	gopacket.PacketDataSource
+	Close()
+}
+
+/*
+PktIO opens the per link transmit and receive handles of the ospf
+interfaces. The daemon uses pcap, tests may attach the interfaces
+to an in-memory network instead.
+*/
+type PktIO interface {
+	OpenTx(params PktIOParams) (PktTxHandle, error)
+	OpenRx(params PktIOParams) (PktRxHandle, error)
+}
+
+type PcapPktIO struct{}
+
+type pcapTxHandle struct {
+	sendMutex sync.Mutex
+	handle    *pcap.Handle
+}
+
+func NewPcapPktIO() *PcapPktIO {
+	return &PcapPktIO{}
+}
+
+func (pktIO *PcapPktIO) OpenTx(params PktIOParams) (PktTxHandle, error) {
+	handle, err := pcap.OpenLive(params.IfName, snapshotLen, promiscuous, pcapTimeout)
+	if err != nil {
+		return nil, err
+	}
+	return &pcapTxHandle{
+		handle: handle,
+	}, nil
+}
+
+func (pktIO *PcapPktIO) OpenRx(params PktIOParams) (PktRxHandle, error) {
+	handle, err := pcap.OpenLive(params.IfName, snapshotLen, promiscuous, pcapTimeout)
+	if err != nil {
+		return nil, err
+	}
+	err = handle.SetBPFFilter(getPcapRxFilter(params))
+	if err != nil {
+		handle.Close()
+		return nil, err
+	}
+	return handle, nil
+}
+
+// Area id of the ospf header, the ip header may carry options
+const pcapOspfAreaId = "ip[((ip[0] & 0xf) << 2) + 8 : 4]"
+
+/*
+Virtual link packets are backbone packets unicast to the address
+of a transit area interface. They are received on the virtual
+interface only, never on the physical one.
+*/
+func getPcapRxFilter(params PktIOParams) string {
+	ip := convertUint32ToDotNotation(params.IpAddr)
+	if params.IntfType == objects.INTF_TYPE_VIRTUAL {
+		return fmt.Sprintf("proto ospf and dst host %s and src host %s and %s = 0",
+			ip, convertUint32ToDotNotation(params.VirtNbrIpAddr), pcapOspfAreaId)
+	}
+	filter := fmt.Sprintf("proto ospf and not src host %s", ip)
+	if params.AreaId != 0 {
+		filter += fmt.Sprintf(" and not (dst host %s and %s = 0)", ip, pcapOspfAreaId)
+	}
+	if isStaticNbrIntf(params.IntfType) {
+		// Replies to the nbr MAC resolution
+		filter = fmt.Sprintf("(%s) or arp dst host %s", filter, ip)
+	}
+	return filter
+}
+
+func (txHdl *pcapTxHandle) WritePacketData(data []byte) error {
+	txHdl.sendMutex.Lock()
+	err := txHdl.handle.WritePacketData(data)
+	txHdl.sendMutex.Unlock()
+	return err
+}
+
+func (txHdl *pcapTxHandle) Close() {
+	txHdl.handle.Close()
+}
+
+func getPktIOParams(intfEnt IntfConf) PktIOParams {
+	return PktIOParams{
+		IfName:        intfEnt.IfName,
+		IpAddr:        intfEnt.IpAddr,
+		IfMacAddr:     intfEnt.IfMacAddr,
+		IntfType:      intfEnt.Type,
+		AreaId:        intfEnt.AreaId,
+		VirtNbrIpAddr: intfEnt.VirtNbrIpAddr,
+	}
+}
+
+type mergedRxFrame struct {
+	data []byte
+	ci   gopacket.CaptureInfo
+}
+
+/*
+mergedRxHandle returns the frames received on several links, a
+virtual link listens on every transit area interface through which
+the virtual nbr is reachable.
+*/
+type mergedRxHandle struct {
+	rxHdls    []PktRxHandle
+	pktCh     chan mergedRxFrame
+	closeCh   chan bool
+	closeOnce sync.Once
+}
+
+/*
+@fn openMergedRxHandle
+Opens a receive handle per link, a single link is returned as is.
+*/
+func openMergedRxHandle(pktIO PktIO, paramsList []PktIOParams) (PktRxHandle, error) {
+	rxHdls := make([]PktRxHandle, 0, len(paramsList))
+	for _, params := range paramsList {
+		rxHdl, err := pktIO.OpenRx(params)
+		if err != nil {
+			for _, hdl := range rxHdls {
+				hdl.Close()
+			}
+			return nil, err
+		}
+		rxHdls = append(rxHdls, rxHdl)
+	}
+	if len(rxHdls) == 1 {
+		return rxHdls[0], nil
+	}
+	mergedHdl := &mergedRxHandle{
+		rxHdls:  rxHdls,
+		pktCh:   make(chan mergedRxFrame),
+		closeCh: make(chan bool),
+	}
+	for _, rxHdl := range rxHdls {
+		go mergedHdl.readFrom(rxHdl)
+	}
+	return mergedHdl, nil
+}
+
+func (mergedHdl *mergedRxHandle) readFrom(rxHdl PktRxHandle) {
+	for {
+		data, ci, err := rxHdl.ReadPacketData()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			// Read timeout, check whether the handle was closed
+			select {
+			case <-mergedHdl.closeCh:
+				return
+			default:
+				continue
+			}
+		}
+		select {
+		case mergedHdl.pktCh <- mergedRxFrame{data, ci}:
+		case <-mergedHdl.closeCh:
+			return
+		}
+	}
+}
+
+func (mergedHdl *mergedRxHandle) ReadPacketData() ([]byte, gopacket.CaptureInfo, error) {
+	select {
+	case frame := <-mergedHdl.pktCh:
+		return frame.data, frame.ci, nil
+	case <-mergedHdl.closeCh:
+		return nil, gopacket.CaptureInfo{}, io.EOF
+	}
+}
+
+func (mergedHdl *mergedRxHandle) Close() {
+	mergedHdl.closeOnce.Do(func() {
+		close(mergedHdl.closeCh)
+		for _, rxHdl := range mergedHdl.rxHdls {
+			rxHdl.Close()
+		}
+	})
+}
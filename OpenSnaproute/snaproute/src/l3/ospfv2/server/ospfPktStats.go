@@ -0,0 +1,245 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//	 Unless required by applicable law or agreed to in writing, software
+//	 distributed under the License is distributed on an "AS IS" BASIS,
+//	 WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//	 See the License for the specific language governing permissions and
+//	 limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"encoding/binary"
+	"l3/ospfv2/objects"
+	"sync"
+	"time"
+)
+
+const ETH_HEADER_LEN = 14
+
+type NbrPktStats struct {
+	IntfKey      IntfConfKey
+	AuthFailures uint32
+	objects.Ospfv2PktStats
+}
+
+/*
+Packet counters are updated from the per interface rx and tx
+routines as well as from the nbr FSM, hence they are kept
+outside of IntfConfMap and NbrConfMap behind a lock.
+*/
+type PktStatsStruct struct {
+	sync.Mutex
+	IntfStats map[IntfConfKey]*objects.Ospfv2PktStats
+	NbrStats  map[NbrConfKey]*NbrPktStats
+}
+
+func (server *OSPFV2Server) initPktStatsData() {
+	server.PktStatsData.IntfStats = make(map[IntfConfKey]*objects.Ospfv2PktStats)
+	server.PktStatsData.NbrStats = make(map[NbrConfKey]*NbrPktStats)
+}
+
+func countPkt(stats *objects.Ospfv2PktStats, pktType uint8, isTx bool) {
+	switch pktType {
+	case HelloType:
+		if isTx {
+			stats.NumOfHelloTx++
+		} else {
+			stats.NumOfHelloRx++
+		}
+	case DBDescriptionType:
+		if isTx {
+			stats.NumOfDbdTx++
+		} else {
+			stats.NumOfDbdRx++
+		}
+	case LSRequestType:
+		if isTx {
+			stats.NumOfLsReqTx++
+		} else {
+			stats.NumOfLsReqRx++
+		}
+	case LSUpdateType:
+		if isTx {
+			stats.NumOfLsUpdTx++
+		} else {
+			stats.NumOfLsUpdRx++
+		}
+	case LSAckType:
+		if isTx {
+			stats.NumOfLsAckTx++
+		} else {
+			stats.NumOfLsAckRx++
+		}
+	}
+}
+
+/*
+Applies update to the counters of the interface and, if it is
+known, of the nbr the packet was exchanged with.
+*/
+func (server *OSPFV2Server) updatePktStats(key IntfConfKey, nbrKey NbrConfKey, update func(*objects.Ospfv2PktStats)) {
+	server.PktStatsData.Lock()
+	defer server.PktStatsData.Unlock()
+	intfStats, exist := server.PktStatsData.IntfStats[key]
+	if !exist {
+		intfStats = new(objects.Ospfv2PktStats)
+		server.PktStatsData.IntfStats[key] = intfStats
+	}
+	update(intfStats)
+	nbrStats, exist := server.PktStatsData.NbrStats[nbrKey]
+	if exist && nbrStats.IntfKey == key {
+		update(&nbrStats.Ospfv2PktStats)
+	}
+}
+
+func (server *OSPFV2Server) countRxPkt(key IntfConfKey, pkt *OspfPktStruct) {
+	nbrKey := NbrConfKey{
+		NbrIdentity:         pkt.IpHdrMd.SrcIP,
+		NbrAddressLessIfIdx: key.IntfIdx,
+	}
+	server.updatePktStats(key, nbrKey, func(stats *objects.Ospfv2PktStats) {
+		countPkt(stats, pkt.OspfHdrMd.PktType, false)
+	})
+}
+
+/*
+Tx packets are fully encoded ethernet frames. Packets sent to
+AllSPFRouters or AllDRouters are counted against every nbr on
+the interface.
+*/
+func (server *OSPFV2Server) countTxPkt(key IntfConfKey, ospfPkt []byte) {
+	if len(ospfPkt) < ETH_HEADER_LEN+IP_HEADER_MIN_LEN+OSPF_HEADER_SIZE {
+		return
+	}
+	ipHdr := ospfPkt[ETH_HEADER_LEN:]
+	ipHdrLen := int(ipHdr[0]&0x0f) * 4
+	if ipHdrLen < IP_HEADER_MIN_LEN || len(ipHdr) < ipHdrLen+OSPF_HEADER_SIZE {
+		return
+	}
+	dstIp := binary.BigEndian.Uint32(ipHdr[16:20])
+	pktType := ipHdr[ipHdrLen+1]
+
+	server.PktStatsData.Lock()
+	defer server.PktStatsData.Unlock()
+	intfStats, exist := server.PktStatsData.IntfStats[key]
+	if !exist {
+		intfStats = new(objects.Ospfv2PktStats)
+		server.PktStatsData.IntfStats[key] = intfStats
+	}
+	countPkt(intfStats, pktType, true)
+	if dstIp == ALLSPFROUTER || dstIp == ALLDROUTER {
+		for _, nbrStats := range server.PktStatsData.NbrStats {
+			if nbrStats.IntfKey == key {
+				countPkt(&nbrStats.Ospfv2PktStats, pktType, true)
+			}
+		}
+		return
+	}
+	nbrKey := NbrConfKey{
+		NbrIdentity:         dstIp,
+		NbrAddressLessIfIdx: key.IntfIdx,
+	}
+	nbrStats, exist := server.PktStatsData.NbrStats[nbrKey]
+	if exist && nbrStats.IntfKey == key {
+		countPkt(&nbrStats.Ospfv2PktStats, pktType, true)
+	}
+}
+
+func (server *OSPFV2Server) countNbrAuthFailure(nbrKey NbrConfKey) {
+	server.PktStatsData.Lock()
+	nbrStats, exist := server.PktStatsData.NbrStats[nbrKey]
+	if exist {
+		nbrStats.AuthFailures++
+	}
+	server.PktStatsData.Unlock()
+}
+
+func (server *OSPFV2Server) addNbrPktStats(nbrKey NbrConfKey, intfKey IntfConfKey) {
+	server.PktStatsData.Lock()
+	server.PktStatsData.NbrStats[nbrKey] = &NbrPktStats{
+		IntfKey: intfKey,
+	}
+	server.PktStatsData.Unlock()
+}
+
+func (server *OSPFV2Server) delNbrPktStats(nbrKey NbrConfKey) {
+	server.PktStatsData.Lock()
+	delete(server.PktStatsData.NbrStats, nbrKey)
+	server.PktStatsData.Unlock()
+}
+
+func (server *OSPFV2Server) delIntfPktStats(key IntfConfKey) {
+	server.PktStatsData.Lock()
+	delete(server.PktStatsData.IntfStats, key)
+	server.PktStatsData.Unlock()
+}
+
+func (server *OSPFV2Server) getIntfPktStats(key IntfConfKey) objects.Ospfv2PktStats {
+	server.PktStatsData.Lock()
+	defer server.PktStatsData.Unlock()
+	intfStats, exist := server.PktStatsData.IntfStats[key]
+	if !exist {
+		return objects.Ospfv2PktStats{}
+	}
+	return *intfStats
+}
+
+func (server *OSPFV2Server) getNbrPktStats(nbrKey NbrConfKey) NbrPktStats {
+	server.PktStatsData.Lock()
+	defer server.PktStatsData.Unlock()
+	nbrStats, exist := server.PktStatsData.NbrStats[nbrKey]
+	if !exist {
+		return NbrPktStats{}
+	}
+	return *nbrStats
+}
+
+/*
+Moves the nbr to state, event is the nbr event
+(RFC 2328, section 10.3) which caused the transition.
+*/
+func setNbrState(nbrConf *NbrConf, state NbrState, event uint8) {
+	if nbrConf.State != state {
+		nbrConf.NbrStateReason = event
+	}
+	nbrConf.State = state
+}
+
+func getNbrDeadTimerRemaining(nbr NbrConf) uint32 {
+	if nbr.NbrDeadTimer == nil {
+		return 0
+	}
+	remaining := nbr.NbrDeadTimeDuration - time.Since(nbr.NbrDeadTimerStart)
+	if remaining < 0 {
+		return 0
+	}
+	return uint32(remaining.Seconds())
+}
+
+func (server *OSPFV2Server) fillNbrStats(obj *objects.Ospfv2NbrState, nbrKey NbrConfKey, nbr NbrConf) {
+	obj.Uptime = uint32(time.Since(nbr.NbrUpTime).Seconds())
+	obj.LastStateChangeReason = nbr.NbrStateReason
+	obj.DesignatedRouter = nbr.NbrDR
+	obj.BackupDesignatedRouter = nbr.NbrBdr
+	obj.RetransmitListLen = uint32(server.getNbrRetxListLen(nbrKey))
+	obj.DeadTimerRemaining = getNbrDeadTimerRemaining(nbr)
+	nbrStats := server.getNbrPktStats(nbrKey)
+	obj.NumOfAuthFailures = nbrStats.AuthFailures
+	obj.Ospfv2PktStats = nbrStats.Ospfv2PktStats
+}
@@ -0,0 +1,526 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"errors"
+	"l3/ospfv2/objects"
+	"sort"
+)
+
+type RedistConf struct {
+	Metric     uint32 // 0: metric of the route
+	MetricType uint8
+	Tag        uint32
+	RouteMap   string
+}
+
+type RouteMapKey struct {
+	Name     string
+	Sequence uint32
+}
+
+type RouteMapConf struct {
+	Permit              bool
+	MatchPrefix         bool
+	MatchIpPrefix       uint32
+	MatchNetmask        uint32
+	MatchLongerPrefixes bool
+	MatchTag            uint32
+	SetMetric           uint32
+	SetMetricType       uint8
+	SetTag              uint32
+	SetFwdAddr          uint32
+}
+
+type ExtRouteKey struct {
+	NwAddr  uint32
+	Netmask uint32
+}
+
+type RibRoute struct {
+	ExtRouteKey
+	Protocol string
+	Metric   uint32
+	Tag      uint32
+}
+
+type RibRouteAttr struct {
+	Metric uint32
+	Tag    uint32 // Route tag in ribd, matched by the route maps
+}
+
+type RedistStruct struct {
+	RibRouteMap map[ExtRouteKey]map[string]RibRouteAttr // Routes published by ribd, protocol to attributes
+	AdvRouteMap map[ExtRouteKey]RouteInfo               // Routes redistributed after applying the policy
+}
+
+// Order in which the sources are picked when the same prefix is
+// published by more than one of them
+var redistProtocolPrecedence []string = []string{
+	objects.REDIST_PROTOCOL_CONNECTED,
+	objects.REDIST_PROTOCOL_STATIC,
+	objects.REDIST_PROTOCOL_BGP,
+}
+
+type RouteMapKeySlice []RouteMapKey
+
+func (r RouteMapKeySlice) Len() int {
+	return len(r)
+}
+
+func (r RouteMapKeySlice) Less(i, j int) bool {
+	return r[i].Sequence < r[j].Sequence
+}
+
+func (r RouteMapKeySlice) Swap(i, j int) {
+	r[i], r[j] = r[j], r[i]
+}
+
+func (server *OSPFV2Server) initRedistData() {
+	server.RedistData.RibRouteMap = make(map[ExtRouteKey]map[string]RibRouteAttr)
+	server.RedistData.AdvRouteMap = make(map[ExtRouteKey]RouteInfo)
+}
+
+func genOspfv2RedistributionUpdateMask(attrset []bool) uint32 {
+	var mask uint32 = 0
+
+	if attrset == nil {
+		mask = objects.OSPFV2_REDISTRIBUTION_UPDATE_METRIC |
+			objects.OSPFV2_REDISTRIBUTION_UPDATE_METRIC_TYPE |
+			objects.OSPFV2_REDISTRIBUTION_UPDATE_TAG |
+			objects.OSPFV2_REDISTRIBUTION_UPDATE_ROUTE_MAP
+	} else {
+		for idx, val := range attrset {
+			if val == true {
+				switch idx {
+				case 0:
+					//Protocol
+				case 1:
+					mask |= objects.OSPFV2_REDISTRIBUTION_UPDATE_METRIC
+				case 2:
+					mask |= objects.OSPFV2_REDISTRIBUTION_UPDATE_METRIC_TYPE
+				case 3:
+					mask |= objects.OSPFV2_REDISTRIBUTION_UPDATE_TAG
+				case 4:
+					mask |= objects.OSPFV2_REDISTRIBUTION_UPDATE_ROUTE_MAP
+				}
+			}
+		}
+	}
+	return mask
+}
+
+func (server *OSPFV2Server) createRedistribution(cfg *objects.Ospfv2Redistribution) (bool, error) {
+	server.logger.Info("Redistribution configuration create")
+	_, exist := server.RedistConfMap[cfg.Protocol]
+	if exist {
+		server.logger.Err("Unable to create redistribution already exist")
+		return false, errors.New("Unable to create redistribution already exist")
+	}
+	server.RedistConfMap[cfg.Protocol] = RedistConf{
+		Metric:     cfg.Metric,
+		MetricType: cfg.MetricType,
+		Tag:        cfg.Tag,
+		RouteMap:   cfg.RouteMap,
+	}
+	server.refreshRedistribution()
+	server.logger.Info("Successfully created ospfv2Redistribution config")
+	return true, nil
+}
+
+func (server *OSPFV2Server) updateRedistribution(newCfg, oldCfg *objects.Ospfv2Redistribution, attrset []bool) (bool, error) {
+	server.logger.Info("Redistribution configuration update")
+	redistEnt, exist := server.RedistConfMap[newCfg.Protocol]
+	if !exist {
+		server.logger.Err("Cannot update, redistribution doesnot exist")
+		return false, errors.New("Cannot update, redistribution doesnot exist")
+	}
+	mask := genOspfv2RedistributionUpdateMask(attrset)
+	if mask&objects.OSPFV2_REDISTRIBUTION_UPDATE_METRIC == objects.OSPFV2_REDISTRIBUTION_UPDATE_METRIC {
+		redistEnt.Metric = newCfg.Metric
+	}
+	if mask&objects.OSPFV2_REDISTRIBUTION_UPDATE_METRIC_TYPE == objects.OSPFV2_REDISTRIBUTION_UPDATE_METRIC_TYPE {
+		redistEnt.MetricType = newCfg.MetricType
+	}
+	if mask&objects.OSPFV2_REDISTRIBUTION_UPDATE_TAG == objects.OSPFV2_REDISTRIBUTION_UPDATE_TAG {
+		redistEnt.Tag = newCfg.Tag
+	}
+	if mask&objects.OSPFV2_REDISTRIBUTION_UPDATE_ROUTE_MAP == objects.OSPFV2_REDISTRIBUTION_UPDATE_ROUTE_MAP {
+		redistEnt.RouteMap = newCfg.RouteMap
+	}
+	server.RedistConfMap[newCfg.Protocol] = redistEnt
+	server.refreshRedistribution()
+	return true, nil
+}
+
+func (server *OSPFV2Server) deleteRedistribution(cfg *objects.Ospfv2Redistribution) (bool, error) {
+	server.logger.Info("Redistribution configuration delete")
+	_, exist := server.RedistConfMap[cfg.Protocol]
+	if !exist {
+		server.logger.Err("Unable to delete redistribution doesnot exist")
+		return false, errors.New("Unable to delete redistribution doesnot exist")
+	}
+	delete(server.RedistConfMap, cfg.Protocol)
+	server.refreshRedistribution()
+	return true, nil
+}
+
+func genOspfv2RouteMapUpdateMask(attrset []bool) uint32 {
+	var mask uint32 = 0
+
+	if attrset == nil {
+		mask = objects.OSPFV2_ROUTE_MAP_UPDATE_ACTION |
+			objects.OSPFV2_ROUTE_MAP_UPDATE_MATCH_IP_PREFIX |
+			objects.OSPFV2_ROUTE_MAP_UPDATE_MATCH_NETMASK |
+			objects.OSPFV2_ROUTE_MAP_UPDATE_MATCH_LONGER_PREFIXES |
+			objects.OSPFV2_ROUTE_MAP_UPDATE_MATCH_TAG |
+			objects.OSPFV2_ROUTE_MAP_UPDATE_SET_METRIC |
+			objects.OSPFV2_ROUTE_MAP_UPDATE_SET_METRIC_TYPE |
+			objects.OSPFV2_ROUTE_MAP_UPDATE_SET_TAG |
+			objects.OSPFV2_ROUTE_MAP_UPDATE_SET_FWD_ADDR
+	} else {
+		for idx, val := range attrset {
+			if val == true {
+				switch idx {
+				case 0:
+					//Name
+				case 1:
+					//Sequence
+				case 2:
+					mask |= objects.OSPFV2_ROUTE_MAP_UPDATE_ACTION
+				case 3:
+					mask |= objects.OSPFV2_ROUTE_MAP_UPDATE_MATCH_IP_PREFIX
+				case 4:
+					mask |= objects.OSPFV2_ROUTE_MAP_UPDATE_MATCH_NETMASK
+				case 5:
+					mask |= objects.OSPFV2_ROUTE_MAP_UPDATE_MATCH_LONGER_PREFIXES
+				case 6:
+					mask |= objects.OSPFV2_ROUTE_MAP_UPDATE_MATCH_TAG
+				case 7:
+					mask |= objects.OSPFV2_ROUTE_MAP_UPDATE_SET_METRIC
+				case 8:
+					mask |= objects.OSPFV2_ROUTE_MAP_UPDATE_SET_METRIC_TYPE
+				case 9:
+					mask |= objects.OSPFV2_ROUTE_MAP_UPDATE_SET_TAG
+				case 10:
+					mask |= objects.OSPFV2_ROUTE_MAP_UPDATE_SET_FWD_ADDR
+				}
+			}
+		}
+	}
+	return mask
+}
+
+func (server *OSPFV2Server) createRouteMap(cfg *objects.Ospfv2RouteMap) (bool, error) {
+	server.logger.Info("Route map configuration create")
+	routeMapKey := RouteMapKey{
+		Name:     cfg.Name,
+		Sequence: cfg.Sequence,
+	}
+	_, exist := server.RouteMapConfMap[routeMapKey]
+	if exist {
+		server.logger.Err("Unable to create route map already exist")
+		return false, errors.New("Unable to create route map already exist")
+	}
+	server.RouteMapConfMap[routeMapKey] = RouteMapConf{
+		Permit:              cfg.Permit,
+		MatchPrefix:         cfg.MatchPrefix,
+		MatchIpPrefix:       cfg.MatchIpPrefix,
+		MatchNetmask:        cfg.MatchNetmask,
+		MatchLongerPrefixes: cfg.MatchLongerPrefixes,
+		MatchTag:            cfg.MatchTag,
+		SetMetric:           cfg.SetMetric,
+		SetMetricType:       cfg.SetMetricType,
+		SetTag:              cfg.SetTag,
+		SetFwdAddr:          cfg.SetFwdAddr,
+	}
+	server.refreshRedistribution()
+	server.logger.Info("Successfully created ospfv2RouteMap config")
+	return true, nil
+}
+
+func (server *OSPFV2Server) updateRouteMap(newCfg, oldCfg *objects.Ospfv2RouteMap, attrset []bool) (bool, error) {
+	server.logger.Info("Route map configuration update")
+	routeMapKey := RouteMapKey{
+		Name:     newCfg.Name,
+		Sequence: newCfg.Sequence,
+	}
+	routeMapEnt, exist := server.RouteMapConfMap[routeMapKey]
+	if !exist {
+		server.logger.Err("Cannot update, route map doesnot exist")
+		return false, errors.New("Cannot update, route map doesnot exist")
+	}
+	mask := genOspfv2RouteMapUpdateMask(attrset)
+	if mask&objects.OSPFV2_ROUTE_MAP_UPDATE_ACTION == objects.OSPFV2_ROUTE_MAP_UPDATE_ACTION {
+		routeMapEnt.Permit = newCfg.Permit
+	}
+	if mask&objects.OSPFV2_ROUTE_MAP_UPDATE_MATCH_IP_PREFIX == objects.OSPFV2_ROUTE_MAP_UPDATE_MATCH_IP_PREFIX {
+		routeMapEnt.MatchPrefix = newCfg.MatchPrefix
+		routeMapEnt.MatchIpPrefix = newCfg.MatchIpPrefix
+	}
+	if mask&objects.OSPFV2_ROUTE_MAP_UPDATE_MATCH_NETMASK == objects.OSPFV2_ROUTE_MAP_UPDATE_MATCH_NETMASK {
+		routeMapEnt.MatchNetmask = newCfg.MatchNetmask
+	}
+	if mask&objects.OSPFV2_ROUTE_MAP_UPDATE_MATCH_LONGER_PREFIXES == objects.OSPFV2_ROUTE_MAP_UPDATE_MATCH_LONGER_PREFIXES {
+		routeMapEnt.MatchLongerPrefixes = newCfg.MatchLongerPrefixes
+	}
+	if mask&objects.OSPFV2_ROUTE_MAP_UPDATE_MATCH_TAG == objects.OSPFV2_ROUTE_MAP_UPDATE_MATCH_TAG {
+		routeMapEnt.MatchTag = newCfg.MatchTag
+	}
+	if mask&objects.OSPFV2_ROUTE_MAP_UPDATE_SET_METRIC == objects.OSPFV2_ROUTE_MAP_UPDATE_SET_METRIC {
+		routeMapEnt.SetMetric = newCfg.SetMetric
+	}
+	if mask&objects.OSPFV2_ROUTE_MAP_UPDATE_SET_METRIC_TYPE == objects.OSPFV2_ROUTE_MAP_UPDATE_SET_METRIC_TYPE {
+		routeMapEnt.SetMetricType = newCfg.SetMetricType
+	}
+	if mask&objects.OSPFV2_ROUTE_MAP_UPDATE_SET_TAG == objects.OSPFV2_ROUTE_MAP_UPDATE_SET_TAG {
+		routeMapEnt.SetTag = newCfg.SetTag
+	}
+	if mask&objects.OSPFV2_ROUTE_MAP_UPDATE_SET_FWD_ADDR == objects.OSPFV2_ROUTE_MAP_UPDATE_SET_FWD_ADDR {
+		routeMapEnt.SetFwdAddr = newCfg.SetFwdAddr
+	}
+	server.RouteMapConfMap[routeMapKey] = routeMapEnt
+	server.refreshRedistribution()
+	return true, nil
+}
+
+func (server *OSPFV2Server) deleteRouteMap(cfg *objects.Ospfv2RouteMap) (bool, error) {
+	server.logger.Info("Route map configuration delete")
+	routeMapKey := RouteMapKey{
+		Name:     cfg.Name,
+		Sequence: cfg.Sequence,
+	}
+	_, exist := server.RouteMapConfMap[routeMapKey]
+	if !exist {
+		server.logger.Err("Unable to delete route map doesnot exist")
+		return false, errors.New("Unable to delete route map doesnot exist")
+	}
+	delete(server.RouteMapConfMap, routeMapKey)
+	server.refreshRedistribution()
+	return true, nil
+}
+
+func (rm RouteMapConf) match(routeInfo RouteInfo, ribTag uint32) bool {
+	if rm.MatchPrefix {
+		if rm.MatchLongerPrefixes {
+			if routeInfo.Netmask&rm.MatchNetmask != rm.MatchNetmask ||
+				routeInfo.NwAddr&rm.MatchNetmask != rm.MatchIpPrefix {
+				return false
+			}
+		} else if routeInfo.Netmask != rm.MatchNetmask ||
+			routeInfo.NwAddr != rm.MatchIpPrefix {
+			return false
+		}
+	}
+	if rm.MatchTag != 0 && ribTag != rm.MatchTag {
+		return false
+	}
+	return true
+}
+
+/*
+Evaluate the entries of the route map in sequence order, the first
+matching entry decides. Routes matching no entry, or referring to a
+route map which is not configured, are denied. The tag is matched
+against the tag of the route in ribd.
+*/
+func (server *OSPFV2Server) applyRouteMap(name string, ribTag uint32, routeInfo *RouteInfo) bool {
+	var keys RouteMapKeySlice
+	for key, _ := range server.RouteMapConfMap {
+		if key.Name == name {
+			keys = append(keys, key)
+		}
+	}
+	sort.Sort(keys)
+	for _, key := range keys {
+		rm := server.RouteMapConfMap[key]
+		if !rm.match(*routeInfo, ribTag) {
+			continue
+		}
+		if !rm.Permit {
+			return false
+		}
+		if rm.SetMetric != 0 {
+			routeInfo.Metric = rm.SetMetric
+		}
+		if rm.SetMetricType != objects.METRIC_TYPE_NONE {
+			routeInfo.BitE = rm.SetMetricType == objects.METRIC_TYPE_E2
+		}
+		if rm.SetTag != 0 {
+			routeInfo.Tag = rm.SetTag
+		}
+		if rm.SetFwdAddr != 0 {
+			routeInfo.FwdAddr = rm.SetFwdAddr
+		}
+		return true
+	}
+	return false
+}
+
+/*
+Apply the redistribution policy of the source protocol to a ribd
+route. When no redistribution is configured every route published
+by ribd is redistributed as E2 with its own metric and tag.
+*/
+func (server *OSPFV2Server) applyRedistPolicy(extKey ExtRouteKey, protocol string, attr RibRouteAttr) (RouteInfo, bool) {
+	routeInfo := RouteInfo{
+		NwAddr:  extKey.NwAddr,
+		Netmask: extKey.Netmask,
+		Metric:  attr.Metric,
+		BitE:    true,
+		Tag:     attr.Tag,
+	}
+	if len(server.RedistConfMap) == 0 {
+		return routeInfo, true
+	}
+	redistEnt, exist := server.RedistConfMap[protocol]
+	if !exist {
+		return routeInfo, false
+	}
+	if redistEnt.Metric != 0 {
+		routeInfo.Metric = redistEnt.Metric
+	}
+	routeInfo.BitE = redistEnt.MetricType != objects.METRIC_TYPE_E1
+	if redistEnt.Tag != 0 {
+		routeInfo.Tag = redistEnt.Tag
+	}
+	if redistEnt.RouteMap == "" {
+		return routeInfo, true
+	}
+	ok := server.applyRouteMap(redistEnt.RouteMap, attr.Tag, &routeInfo)
+	return routeInfo, ok
+}
+
+func isRedistProtocol(protocol string) bool {
+	for _, proto := range redistProtocolPrecedence {
+		if proto == protocol {
+			return true
+		}
+	}
+	return false
+}
+
+// Pick the route to be redistributed for the prefix amongst the sources publishing it
+func (server *OSPFV2Server) getRedistRouteInfo(extKey ExtRouteKey) (RouteInfo, bool) {
+	protoMap := server.RedistData.RibRouteMap[extKey]
+	var protocols []string
+	for _, protocol := range redistProtocolPrecedence {
+		if _, exist := protoMap[protocol]; exist {
+			protocols = append(protocols, protocol)
+		}
+	}
+	var others []string
+	for protocol, _ := range protoMap {
+		if !isRedistProtocol(protocol) {
+			others = append(others, protocol)
+		}
+	}
+	sort.Strings(others)
+	protocols = append(protocols, others...)
+	for _, protocol := range protocols {
+		routeInfo, ok := server.applyRedistPolicy(extKey, protocol, protoMap[protocol])
+		if ok {
+			server.applyStubRouterExtMetric(&routeInfo)
+			return routeInfo, true
+		}
+	}
+	return RouteInfo{}, false
+}
+
+func (server *OSPFV2Server) addRibRoute(route RibRoute) {
+	protoMap, exist := server.RedistData.RibRouteMap[route.ExtRouteKey]
+	if !exist {
+		protoMap = make(map[string]RibRouteAttr)
+		server.RedistData.RibRouteMap[route.ExtRouteKey] = protoMap
+	}
+	protoMap[route.Protocol] = RibRouteAttr{
+		Metric: route.Metric,
+		Tag:    route.Tag,
+	}
+}
+
+func (server *OSPFV2Server) delRibRoute(route RibRoute) {
+	protoMap, exist := server.RedistData.RibRouteMap[route.ExtRouteKey]
+	if !exist {
+		return
+	}
+	delete(protoMap, route.Protocol)
+	if len(protoMap) == 0 {
+		delete(server.RedistData.RibRouteMap, route.ExtRouteKey)
+	}
+}
+
+/*
+Re-evaluate the policy for the prefix and collect the routes to be
+withdrawn or (re)originated. A route whose attributes changed is only
+(re)originated, Lsdb replaces the LSA in place.
+*/
+func (server *OSPFV2Server) updateRedistRoute(extKey ExtRouteKey, addList, delList *[]RouteInfo) {
+	oldRouteInfo, advertised := server.RedistData.AdvRouteMap[extKey]
+	routeInfo, ok := server.getRedistRouteInfo(extKey)
+	if ok {
+		if advertised && oldRouteInfo == routeInfo {
+			return
+		}
+		server.RedistData.AdvRouteMap[extKey] = routeInfo
+		*addList = append(*addList, routeInfo)
+	} else if advertised {
+		delete(server.RedistData.AdvRouteMap, extKey)
+		*delList = append(*delList, oldRouteInfo)
+	}
+}
+
+func (server *OSPFV2Server) sendRedistRouteUpdate(addList, delList []RouteInfo) {
+	if len(delList) > 0 {
+		server.SendMsgToLsdbToUpdateRouteInfo(RouteInfoDataUpdateMsg{
+			MsgType:       ROUTE_INFO_DEL,
+			RouteInfoList: delList,
+		})
+	}
+	if len(addList) > 0 {
+		server.SendMsgToLsdbToUpdateRouteInfo(RouteInfoDataUpdateMsg{
+			MsgType:       ROUTE_INFO_ADD,
+			RouteInfoList: addList,
+		})
+	}
+}
+
+// Re-evaluate all the ribd routes after a policy change
+func (server *OSPFV2Server) refreshRedistribution() {
+	if server.globalData.AdminState == false {
+		// Evaluated from scratch when Lsdb starts
+		return
+	}
+	var addList, delList []RouteInfo
+	for extKey, _ := range server.RedistData.RibRouteMap {
+		server.updateRedistRoute(extKey, &addList, &delList)
+	}
+	for extKey, _ := range server.RedistData.AdvRouteMap {
+		if _, exist := server.RedistData.RibRouteMap[extKey]; !exist {
+			server.updateRedistRoute(extKey, &addList, &delList)
+		}
+	}
+	server.sendRedistRouteUpdate(addList, delList)
+}
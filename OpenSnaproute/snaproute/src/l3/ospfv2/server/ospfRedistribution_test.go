@@ -0,0 +1,158 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"l3/ospfv2/objects"
+	"l3/rib/ribdCommonDefs"
+	"ribdInt"
+	"testing"
+)
+
+func TestConvertRibdRouteTag(t *testing.T) {
+	routeList := ribdCommonDefs.RoutelistInfo{
+		RouteInfo: ribdInt.Routes{
+			Ipaddr:          "20.1.1.1",
+			Mask:            "255.255.255.0",
+			RoutingProtocol: objects.REDIST_PROTOCOL_STATIC,
+			Metric:          5,
+			Tag:             100,
+		},
+	}
+	ribRoute := convertRibdRoute(&routeList)
+	if ribRoute.NwAddr != testIp("20.1.1.0") ||
+		ribRoute.Netmask != testIp("255.255.255.0") ||
+		ribRoute.Protocol != objects.REDIST_PROTOCOL_STATIC ||
+		ribRoute.Metric != 5 || ribRoute.Tag != 100 {
+		t.Error("Ribd route mismatch", ribRoute)
+	}
+}
+
+func TestRedistTagPropagation(t *testing.T) {
+	server := newTestServer("1.1.1.1")
+	extKey := ExtRouteKey{
+		NwAddr:  testIp("20.1.1.0"),
+		Netmask: testIp("255.255.255.0"),
+	}
+	server.addRibRoute(RibRoute{
+		ExtRouteKey: extKey,
+		Protocol:    objects.REDIST_PROTOCOL_STATIC,
+		Metric:      5,
+		Tag:         100,
+	})
+	routeInfo, ok := server.getRedistRouteInfo(extKey)
+	if !ok || routeInfo.Tag != 100 || routeInfo.Metric != 5 {
+		t.Error("Ribd route tag not redistributed", routeInfo, ok)
+	}
+
+	server.RedistConfMap[objects.REDIST_PROTOCOL_STATIC] = RedistConf{
+		MetricType: objects.METRIC_TYPE_E2,
+	}
+	routeInfo, ok = server.getRedistRouteInfo(extKey)
+	if !ok || routeInfo.Tag != 100 {
+		t.Error("Ribd route tag not kept without a configured tag", routeInfo, ok)
+	}
+
+	server.RedistConfMap[objects.REDIST_PROTOCOL_STATIC] = RedistConf{
+		MetricType: objects.METRIC_TYPE_E2,
+		Tag:        7,
+	}
+	routeInfo, ok = server.getRedistRouteInfo(extKey)
+	if !ok || routeInfo.Tag != 7 {
+		t.Error("Configured tag not set", routeInfo, ok)
+	}
+}
+
+func TestRouteMapMatchTag(t *testing.T) {
+	server := newTestServer("1.1.1.1")
+	server.RedistConfMap[objects.REDIST_PROTOCOL_STATIC] = RedistConf{
+		MetricType: objects.METRIC_TYPE_E2,
+		Tag:        7,
+		RouteMap:   "static",
+	}
+	server.RouteMapConfMap[RouteMapKey{Name: "static", Sequence: 10}] = RouteMapConf{
+		Permit:    true,
+		MatchTag:  100,
+		SetMetric: 50,
+	}
+	tagged := ExtRouteKey{
+		NwAddr:  testIp("20.1.1.0"),
+		Netmask: testIp("255.255.255.0"),
+	}
+	untagged := ExtRouteKey{
+		NwAddr:  testIp("20.1.2.0"),
+		Netmask: testIp("255.255.255.0"),
+	}
+	server.addRibRoute(RibRoute{
+		ExtRouteKey: tagged,
+		Protocol:    objects.REDIST_PROTOCOL_STATIC,
+		Metric:      5,
+		Tag:         100,
+	})
+	server.addRibRoute(RibRoute{
+		ExtRouteKey: untagged,
+		Protocol:    objects.REDIST_PROTOCOL_STATIC,
+		Metric:      5,
+	})
+
+	// Matched against the ribd tag, not the configured one
+	routeInfo, ok := server.getRedistRouteInfo(tagged)
+	if !ok || routeInfo.Metric != 50 || routeInfo.Tag != 7 {
+		t.Error("Route with matching ribd tag not redistributed", routeInfo, ok)
+	}
+	if _, ok = server.getRedistRouteInfo(untagged); ok {
+		t.Error("Route without matching ribd tag redistributed")
+	}
+
+	server.RouteMapConfMap[RouteMapKey{Name: "static", Sequence: 10}] = RouteMapConf{
+		Permit:   true,
+		MatchTag: 7,
+	}
+	if _, ok = server.getRedistRouteInfo(tagged); ok {
+		t.Error("Route matched on the configured tag")
+	}
+
+	// The tag of the same prefix from another source is not used
+	server.RedistConfMap[objects.REDIST_PROTOCOL_CONNECTED] = RedistConf{
+		MetricType: objects.METRIC_TYPE_E2,
+		RouteMap:   "static",
+	}
+	server.addRibRoute(RibRoute{
+		ExtRouteKey: untagged,
+		Protocol:    objects.REDIST_PROTOCOL_CONNECTED,
+		Metric:      1,
+		Tag:         7,
+	})
+	routeInfo, ok = server.getRedistRouteInfo(untagged)
+	if !ok || routeInfo.Tag != 7 || routeInfo.Metric != 1 {
+		t.Error("Connected route with matching ribd tag not redistributed", routeInfo, ok)
+	}
+	server.delRibRoute(RibRoute{
+		ExtRouteKey: untagged,
+		Protocol:    objects.REDIST_PROTOCOL_CONNECTED,
+	})
+	if _, ok = server.getRedistRouteInfo(untagged); ok {
+		t.Error("Static route matched on the tag of the deleted connected route")
+	}
+}
@@ -0,0 +1,312 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"encoding/binary"
+	"l3/ospfv2/objects"
+	"net"
+	"sync"
+	"time"
+)
+
+const NBR_RETX_QUEUE_LEN = 10
+
+/*
+LSAs flooded to a nbr and LSAs requested from it which are
+neither acknowledged nor received yet (RFC 2328, section 10.4
+and 13.6).
+*/
+type NbrRetxEnt struct {
+	IntfKey    IntfConfKey
+	Interval   time.Duration
+	Lsas       map[LsaKey][]byte
+	LsaReqs    []ospfLSAReq
+	timer      *time.Timer
+	timerArmed bool
+}
+
+/*
+The lists are filled from the flooding routine and emptied from
+the nbr FSM, hence they are kept outside of NbrConfMap behind a
+lock.
+*/
+type NbrRetxStruct struct {
+	sync.Mutex
+	NbrRetx map[NbrConfKey]*NbrRetxEnt
+}
+
+func (server *OSPFV2Server) initNbrRetxData() {
+	server.NbrRetxData.Lock()
+	server.NbrRetxData.NbrRetx = make(map[NbrConfKey]*NbrRetxEnt)
+	server.NbrRetxData.Unlock()
+}
+
+func (server *OSPFV2Server) deinitNbrRetxData() {
+	server.NbrRetxData.Lock()
+	for _, retx := range server.NbrRetxData.NbrRetx {
+		if retx.timer != nil {
+			retx.timer.Stop()
+		}
+	}
+	server.NbrRetxData.NbrRetx = nil
+	server.NbrRetxData.Unlock()
+}
+
+func (server *OSPFV2Server) addNbrRetx(nbrKey NbrConfKey, intfKey IntfConfKey, retxInterval uint16) {
+	if retxInterval == 0 {
+		retxInterval = 1
+	}
+	server.NbrRetxData.Lock()
+	if server.NbrRetxData.NbrRetx != nil {
+		server.NbrRetxData.NbrRetx[nbrKey] = &NbrRetxEnt{
+			IntfKey:  intfKey,
+			Interval: time.Duration(retxInterval) * time.Second,
+			Lsas:     make(map[LsaKey][]byte),
+		}
+	}
+	server.NbrRetxData.Unlock()
+}
+
+func (server *OSPFV2Server) delNbrRetx(nbrKey NbrConfKey) {
+	server.NbrRetxData.Lock()
+	retx, exist := server.NbrRetxData.NbrRetx[nbrKey]
+	if exist {
+		if retx.timer != nil {
+			retx.timer.Stop()
+		}
+		delete(server.NbrRetxData.NbrRetx, nbrKey)
+	}
+	server.NbrRetxData.Unlock()
+}
+
+/* Empties both lists, the nbr fell back below Exchange */
+func (server *OSPFV2Server) clearNbrRetx(nbrKey NbrConfKey) {
+	server.NbrRetxData.Lock()
+	retx, exist := server.NbrRetxData.NbrRetx[nbrKey]
+	if exist {
+		retx.Lsas = make(map[LsaKey][]byte)
+		retx.LsaReqs = nil
+	}
+	server.NbrRetxData.Unlock()
+}
+
+func (server *OSPFV2Server) getNbrRetxListLen(nbrKey NbrConfKey) int {
+	server.NbrRetxData.Lock()
+	defer server.NbrRetxData.Unlock()
+	retx, exist := server.NbrRetxData.NbrRetx[nbrKey]
+	if !exist {
+		return 0
+	}
+	return len(retx.Lsas)
+}
+
+/* Called with NbrRetxData locked */
+func (server *OSPFV2Server) armNbrRetxTimer(nbrKey NbrConfKey, retx *NbrRetxEnt) {
+	if retx.timerArmed {
+		return
+	}
+	retx.timerArmed = true
+	if retx.timer != nil {
+		retx.timer.Reset(retx.Interval)
+		return
+	}
+	retx.timer = time.AfterFunc(retx.Interval, func() {
+		select {
+		case server.NbrConfData.nbrRetxCh <- nbrKey:
+		default:
+			// FSM is busy, try again after the next interval
+			server.NbrRetxData.Lock()
+			if retx.timerArmed {
+				retx.timer.Reset(retx.Interval)
+			}
+			server.NbrRetxData.Unlock()
+		}
+	})
+}
+
+func getLsaKeyFromLsaHeader(hdr ospfLSAHeader) LsaKey {
+	return LsaKey{
+		LSType:    hdr.ls_type,
+		LSId:      hdr.link_state_id,
+		AdvRouter: hdr.adv_router_id,
+	}
+}
+
+/*
+Adds an LSA sent to the nbr to its retransmission list. A more
+recent instance of the LSA replaces the older one.
+*/
+func (server *OSPFV2Server) addLsaToRetxList(nbrKey NbrConfKey, lsa []byte) {
+	if len(lsa) < OSPF_LSA_HEADER_SIZE {
+		return
+	}
+	lsaKey := getLsaKeyFromLsaHeader(decodeLSAHeader(lsa))
+	server.NbrRetxData.Lock()
+	retx, exist := server.NbrRetxData.NbrRetx[nbrKey]
+	if exist {
+		lsaCopy := make([]byte, len(lsa))
+		copy(lsaCopy, lsa)
+		retx.Lsas[lsaKey] = lsaCopy
+		server.armNbrRetxTimer(nbrKey, retx)
+	}
+	server.NbrRetxData.Unlock()
+}
+
+/* Adds an LSA flooded on the intf to the lists of its adjacent nbrs */
+func (server *OSPFV2Server) addLsaToIntfRetxLists(intfKey IntfConfKey, lsa []byte) {
+	nbrList, exist := server.NbrConfData.IntfToNbrMap[intfKey]
+	if !exist {
+		return
+	}
+	for _, nbrKey := range nbrList {
+		nbrConf, exist := server.NbrConfMap[nbrKey]
+		if !exist || nbrConf.State < NbrExchange {
+			continue
+		}
+		server.addLsaToRetxList(nbrKey, lsa)
+	}
+}
+
+/*
+Removes the LSA from the retransmission list if hdr is for the
+same instance (RFC 2328, section 13.7).
+*/
+func (server *OSPFV2Server) ackLsaOnRetxList(nbrKey NbrConfKey, hdr ospfLSAHeader) {
+	lsaKey := getLsaKeyFromLsaHeader(hdr)
+	server.NbrRetxData.Lock()
+	retx, exist := server.NbrRetxData.NbrRetx[nbrKey]
+	if exist {
+		lsa, exist := retx.Lsas[lsaKey]
+		if exist {
+			sent := decodeLSAHeader(lsa)
+			if sent.ls_sequence_num == hdr.ls_sequence_num &&
+				sent.ls_checksum == hdr.ls_checksum {
+				delete(retx.Lsas, lsaKey)
+			}
+		}
+	}
+	server.NbrRetxData.Unlock()
+}
+
+/* Records the LSAs requested from the nbr in the last LSR */
+func (server *OSPFV2Server) setLsaReqRetxList(nbrKey NbrConfKey, reqs []ospfLSAReq) {
+	server.NbrRetxData.Lock()
+	retx, exist := server.NbrRetxData.NbrRetx[nbrKey]
+	if exist {
+		retx.LsaReqs = reqs
+		if len(reqs) > 0 {
+			server.armNbrRetxTimer(nbrKey, retx)
+		}
+	}
+	server.NbrRetxData.Unlock()
+}
+
+/*
+An LSA received from the nbr is an implied ack for the same
+instance on its retransmission list, and satisfies a pending
+request for it.
+*/
+func (server *OSPFV2Server) processRxLsaRetx(nbrKey NbrConfKey, hdr ospfLSAHeader) {
+	server.ackLsaOnRetxList(nbrKey, hdr)
+	server.NbrRetxData.Lock()
+	retx, exist := server.NbrRetxData.NbrRetx[nbrKey]
+	if exist {
+		for i, req := range retx.LsaReqs {
+			if uint8(req.ls_type) == hdr.ls_type &&
+				req.link_state_id == hdr.link_state_id &&
+				req.adv_router_id == hdr.adv_router_id {
+				retx.LsaReqs = append(retx.LsaReqs[:i:i], retx.LsaReqs[i+1:]...)
+				break
+			}
+		}
+	}
+	server.NbrRetxData.Unlock()
+}
+
+/*
+Retransmission timer expiry. Unacknowledged LSAs and unanswered
+requests are resent directly to the nbr.
+*/
+func (server *OSPFV2Server) retransmitToNbr(nbrKey NbrConfKey) {
+	nbrConf, exist := server.NbrConfMap[nbrKey]
+	if !exist {
+		server.delNbrRetx(nbrKey)
+		return
+	}
+	server.NbrRetxData.Lock()
+	retx, exist := server.NbrRetxData.NbrRetx[nbrKey]
+	if !exist {
+		server.NbrRetxData.Unlock()
+		return
+	}
+	retx.timerArmed = false
+	if nbrConf.State < NbrExchange {
+		retx.Lsas = make(map[LsaKey][]byte)
+		retx.LsaReqs = nil
+	}
+	var lsas [][]byte
+	for _, lsa := range retx.Lsas {
+		lsas = append(lsas, lsa)
+	}
+	reqs := retx.LsaReqs
+	if nbrConf.State != NbrExchange && nbrConf.State != NbrLoading {
+		reqs = nil
+	}
+	if len(lsas) > 0 || len(reqs) > 0 {
+		server.armNbrRetxTimer(nbrKey, retx)
+	}
+	server.NbrRetxData.Unlock()
+
+	intf, exist := server.IntfConfMap[nbrConf.IntfKey]
+	if !exist {
+		return
+	}
+	dstIp := net.ParseIP(convertUint32ToDotNotation(nbrConf.NbrIP))
+	if intf.Type == objects.INTF_TYPE_VIRTUAL {
+		dstIp = net.ParseIP(convertUint32ToDotNotation(intf.VirtNbrIpAddr))
+	}
+	for _, lsa := range lsas {
+		lsaEncPkt := make([]byte, 4)
+		binary.BigEndian.PutUint32(lsaEncPkt, 1)
+		lsaEncPkt = append(lsaEncPkt, lsa...)
+		pkt := server.BuildLsaUpdPkt(nbrConf.IntfKey, intf,
+			nbrConf.NbrMac, dstIp, len(lsaEncPkt), lsaEncPkt)
+		server.logger.Debug("LSAUPD: Retransmit lsa to nbr ", nbrKey)
+		server.SendOspfPkt(nbrConf.IntfKey, pkt)
+		server.countRetransmit(nbrConf.IntfKey, nbrKey)
+	}
+	if len(reqs) > 0 {
+		data := server.EncodeLSAReqPkt(nbrConf.IntfKey, intf, nbrConf, reqs, nbrConf.NbrMac)
+		server.logger.Debug("LSAREQ: Retransmit lsa req to nbr ", nbrKey)
+		server.SendOspfPkt(nbrConf.IntfKey, data)
+		server.countRetransmit(nbrConf.IntfKey, nbrKey)
+	}
+}
+
+func (server *OSPFV2Server) countRetransmit(intfKey IntfConfKey, nbrKey NbrConfKey) {
+	server.updatePktStats(intfKey, nbrKey, func(stats *objects.Ospfv2PktStats) {
+		stats.NumOfRetransmits++
+	})
+}
@@ -0,0 +1,178 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"encoding/binary"
+	"github.com/google/gopacket/layers"
+	"l3/ospfv2/objects"
+	"net"
+	"testing"
+	"time"
+)
+
+func makeTestRouterLsaHeader(lsId string, seqNum uint32) []byte {
+	lsa := make([]byte, OSPF_LSA_HEADER_SIZE)
+	lsa[3] = RouterLSA
+	binary.BigEndian.PutUint32(lsa[4:8], testIp(lsId))
+	binary.BigEndian.PutUint32(lsa[8:12], testIp(lsId))
+	binary.BigEndian.PutUint32(lsa[12:16], seqNum)
+	binary.BigEndian.PutUint16(lsa[18:20], OSPF_LSA_HEADER_SIZE)
+	return lsa
+}
+
+/*
+Adds a P2MP nbr with retransmission and packet stats state and
+returns the nbr end of the wire
+*/
+func addTestRetxNbr(t *testing.T, server *OSPFV2Server, state NbrState) (NbrConfKey, PktRxHandle) {
+	addTestArea(server, 1, AreaConf{ImportASExtern: true})
+	intfKey := addTestIntf(server, 1, "10.0.0.1", objects.INTF_TYPE_POINT2MULTIPOINT, objects.INTF_FSM_STATE_P2P)
+	nbrMac := net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x02}
+	_, nbrRxHdl := openTestNbrWire(t, server, intfKey, PktIOParams{
+		IpAddr:    testIp("10.0.0.2"),
+		IfMacAddr: nbrMac,
+		IntfType:  objects.INTF_TYPE_POINT2MULTIPOINT,
+		AreaId:    1,
+	})
+	nbrKey := addTestP2MPNbr(server, intfKey, "10.0.0.2", "2.2.2.2", state)
+	nbrConf := server.NbrConfMap[nbrKey]
+	nbrConf.NbrMac = nbrMac
+	server.NbrConfMap[nbrKey] = nbrConf
+	server.NbrConfData.IntfToNbrMap[intfKey] = []NbrConfKey{nbrKey}
+	server.addNbrPktStats(nbrKey, intfKey)
+	server.addNbrRetx(nbrKey, intfKey, 1)
+	return nbrKey, nbrRxHdl
+}
+
+/*
+Returns the OSPF packet type and destination of the next packet
+sent to the nbr
+*/
+func readTestOspfPkt(t *testing.T, rxHdl PktRxHandle) (uint8, string) {
+	pkt, ok := readTestPacket(t, rxHdl)
+	if !ok {
+		return 0, ""
+	}
+	ipLayer := pkt.Layer(layers.LayerTypeIPv4)
+	if ipLayer == nil || len(ipLayer.LayerPayload()) < OSPF_HEADER_SIZE {
+		t.Fatal("Expected an OSPF packet, got", pkt)
+	}
+	return ipLayer.LayerPayload()[1], ipLayer.(*layers.IPv4).DstIP.String()
+}
+
+func TestLsaRetransmit(t *testing.T) {
+	server := newTestServer("1.1.1.1")
+	nbrKey, nbrRxHdl := addTestRetxNbr(t, server, NbrFull)
+	defer nbrRxHdl.Close()
+	defer server.delNbrRetx(nbrKey)
+
+	lsa := makeTestRouterLsaHeader("1.1.1.1", 0x80000002)
+	server.addLsaToIntfRetxLists(server.NbrConfMap[nbrKey].IntfKey, lsa)
+	if server.getNbrRetxListLen(nbrKey) != 1 {
+		t.Fatal("Flooded LSA not added to the retransmission list")
+	}
+
+	select {
+	case key := <-server.NbrConfData.nbrRetxCh:
+		if key != nbrKey {
+			t.Fatal("Retransmission timer fired for", key)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("Retransmission timer did not fire")
+	}
+	server.retransmitToNbr(nbrKey)
+	pktType, dstIp := readTestOspfPkt(t, nbrRxHdl)
+	if pktType != LSUpdateType {
+		t.Fatal("Unacknowledged LSA not retransmitted, got packet type", pktType)
+	}
+	if dstIp != "10.0.0.2" {
+		t.Error("LSA retransmission not unicast to the nbr", dstIp)
+	}
+	if server.getNbrPktStats(nbrKey).NumOfRetransmits != 1 {
+		t.Error("LSU retransmission not counted", server.getNbrPktStats(nbrKey))
+	}
+
+	// Ack for another instance leaves the LSA on the list
+	server.ackLsaOnRetxList(nbrKey, decodeLSAHeader(makeTestRouterLsaHeader("1.1.1.1", 0x80000001)))
+	if server.getNbrRetxListLen(nbrKey) != 1 {
+		t.Fatal("LSA removed by the ack for an older instance")
+	}
+	server.DecodeLSAAck(NbrLsaAckMsg{
+		nbrKey:      nbrKey,
+		lsa_headers: []ospfLSAHeader{decodeLSAHeader(lsa)},
+	})
+	if server.getNbrRetxListLen(nbrKey) != 0 {
+		t.Fatal("Acknowledged LSA still on the retransmission list")
+	}
+	server.retransmitToNbr(nbrKey)
+	if _, ok := readTestFrame(nbrRxHdl); ok {
+		t.Error("Acknowledged LSA retransmitted")
+	}
+	if server.getNbrPktStats(nbrKey).NumOfRetransmits != 1 {
+		t.Error("Retransmission counted without a packet", server.getNbrPktStats(nbrKey))
+	}
+}
+
+func TestLsaReqRetransmit(t *testing.T) {
+	server := newTestServer("1.1.1.1")
+	nbrKey, nbrRxHdl := addTestRetxNbr(t, server, NbrLoading)
+	defer nbrRxHdl.Close()
+	defer server.delNbrRetx(nbrKey)
+
+	reqs := []ospfLSAReq{
+		{ls_type: uint32(RouterLSA), link_state_id: testIp("2.2.2.2"), adv_router_id: testIp("2.2.2.2")},
+		{ls_type: uint32(RouterLSA), link_state_id: testIp("3.3.3.3"), adv_router_id: testIp("3.3.3.3")},
+	}
+	server.setLsaReqRetxList(nbrKey, reqs)
+	server.retransmitToNbr(nbrKey)
+	if pktType, _ := readTestOspfPkt(t, nbrRxHdl); pktType != LSRequestType {
+		t.Fatal("Unanswered LSR not retransmitted, got packet type", pktType)
+	}
+	if server.getNbrPktStats(nbrKey).NumOfRetransmits != 1 {
+		t.Error("LSR retransmission not counted", server.getNbrPktStats(nbrKey))
+	}
+
+	// Received LSA satisfies its request
+	server.processRxLsaRetx(nbrKey, decodeLSAHeader(makeTestRouterLsaHeader("2.2.2.2", 0x80000001)))
+	server.NbrRetxData.Lock()
+	pending := server.NbrRetxData.NbrRetx[nbrKey].LsaReqs
+	server.NbrRetxData.Unlock()
+	if len(pending) != 1 || pending[0].link_state_id != testIp("3.3.3.3") {
+		t.Fatal("Received LSA not removed from the pending requests", pending)
+	}
+
+	// Nothing is retransmitted once the adjacency is torn down
+	nbrConf := server.NbrConfMap[nbrKey]
+	nbrConf.State = NbrExchangeStart
+	server.NbrConfMap[nbrKey] = nbrConf
+	server.addLsaToRetxList(nbrKey, makeTestRouterLsaHeader("1.1.1.1", 0x80000001))
+	server.retransmitToNbr(nbrKey)
+	if _, ok := readTestFrame(nbrRxHdl); ok {
+		t.Error("Retransmitted to a nbr below Exchange")
+	}
+	if server.getNbrRetxListLen(nbrKey) != 0 {
+		t.Error("Retransmission list not flushed below Exchange")
+	}
+}
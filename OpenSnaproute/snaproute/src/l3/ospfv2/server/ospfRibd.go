@@ -124,9 +124,9 @@ func (server *OSPFV2Server) createRibdSubscriber() {
 	}
 }
 
-func (server *OSPFV2Server) getBulkRoutesFromRibd() []*RouteInfo {
+func (server *OSPFV2Server) getBulkRoutesFromRibd() []RibRoute {
 	curMark := ribdInt.Int(0)
-	var routeInfoList []*RouteInfo
+	var ribRouteList []RibRoute
 
 	if server.ribdComm.ribdClient.IsConnected {
 		server.logger.Info("Calling Ribd To get Routes for Ospfd")
@@ -140,23 +140,15 @@ func (server *OSPFV2Server) getBulkRoutesFromRibd() []*RouteInfo {
 			more := bool(bulkInfo.More)
 			curMark = bulkInfo.EndIdx
 			for idx := 0; idx < objCount; idx++ {
-				route := bulkInfo.RouteList[idx]
-				nwAddr, _ := convertDotNotationToUint32(route.Ipaddr)
-				netmask, _ := convertDotNotationToUint32(route.Mask)
-				metric := uint32(route.Metric)
-				routeInfo := RouteInfo{
-					NwAddr:  nwAddr,
-					Netmask: netmask,
-					Metric:  metric,
-				}
-				routeInfoList = append(routeInfoList, &routeInfo)
+				ribRoute := convertRibdRouteInfo(bulkInfo.RouteList[idx])
+				ribRouteList = append(ribRouteList, ribRoute)
 			}
 			if more == false {
 				break
 			}
 		}
 	}
-	return routeInfoList
+	return ribRouteList
 }
 
 func (server *OSPFV2Server) processRibdNotification(ribdRxBuf []byte) {
@@ -180,40 +172,38 @@ func (server *OSPFV2Server) processRibdNotification(ribdRxBuf []byte) {
 	}
 }
 
-func (server *OSPFV2Server) processRibdRouteAddMsg(routeList *ribdCommonDefs.RoutelistInfo) {
-	var routeInfoList []RouteInfo
-	route := routeList.RouteInfo
+func convertRibdRouteInfo(route *ribdInt.Routes) RibRoute {
 	nwAddr, _ := convertDotNotationToUint32(route.Ipaddr)
 	netmask, _ := convertDotNotationToUint32(route.Mask)
-	metric := uint32(route.Metric)
-	routeInfo := RouteInfo{
-		NwAddr:  nwAddr,
-		Netmask: netmask,
-		Metric:  metric,
-	}
-	routeInfoList = append(routeInfoList, routeInfo)
-	msg := RouteInfoDataUpdateMsg{
-		MsgType:       ROUTE_INFO_ADD,
-		RouteInfoList: routeInfoList,
+	return RibRoute{
+		ExtRouteKey: ExtRouteKey{
+			NwAddr:  nwAddr & netmask,
+			Netmask: netmask,
+		},
+		Protocol: route.RoutingProtocol,
+		Metric:   uint32(route.Metric),
+		Tag:      uint32(route.Tag),
 	}
-	server.SendMsgToLsdbToUpdateRouteInfo(msg)
+}
+
+func convertRibdRoute(routeList *ribdCommonDefs.RoutelistInfo) RibRoute {
+	return convertRibdRouteInfo(&routeList.RouteInfo)
+}
+
+func (server *OSPFV2Server) processRibdRouteAddMsg(routeList *ribdCommonDefs.RoutelistInfo) {
+	var addList, delList []RouteInfo
+	ribRoute := convertRibdRoute(routeList)
+	server.addRibRoute(ribRoute)
+	server.processStubRouterRibRoute(ribRoute)
+	server.updateRedistRoute(ribRoute.ExtRouteKey, &addList, &delList)
+	server.sendRedistRouteUpdate(addList, delList)
 }
 
 func (server *OSPFV2Server) processRibdRouteDelMsg(routeList *ribdCommonDefs.RoutelistInfo) {
-	var routeInfoList []RouteInfo
-	route := routeList.RouteInfo
-	nwAddr, _ := convertDotNotationToUint32(route.Ipaddr)
-	netmask, _ := convertDotNotationToUint32(route.Mask)
-	metric := uint32(route.Metric)
-	routeInfo := RouteInfo{
-		NwAddr:  nwAddr,
-		Netmask: netmask,
-		Metric:  metric,
-	}
-	routeInfoList = append(routeInfoList, routeInfo)
-	msg := RouteInfoDataUpdateMsg{
-		MsgType:       ROUTE_INFO_DEL,
-		RouteInfoList: routeInfoList,
-	}
-	server.SendMsgToLsdbToUpdateRouteInfo(msg)
+	var addList, delList []RouteInfo
+	ribRoute := convertRibdRoute(routeList)
+	server.delRibRoute(ribRoute)
+	server.processStubRouterRibRoute(ribRoute)
+	server.updateRedistRoute(ribRoute.ExtRouteKey, &addList, &delList)
+	server.sendRedistRouteUpdate(addList, delList)
 }
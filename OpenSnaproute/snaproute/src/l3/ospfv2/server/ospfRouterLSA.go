@@ -80,21 +80,30 @@ func (server *OSPFV2Server) GetLinkDetails(areaId uint32, areaEnt AreaConf) []Li
 				linkDetail.LinkMetric = uint16(0)
 				linkDetail.NumOfTOS = 0
 			}
+		} else if server.isIntfPassive(intfConf) {
+			// No adjacencies, the subnet is a stub network
+			server.logger.Debug("Passive Interface")
+			linkDetail.LinkType = STUB_LINK
+			linkDetail.LinkData = intfConf.Netmask
+			linkDetail.LinkId = intfConf.IpAddr & intfConf.Netmask
+			linkDetail.LinkMetric = uint16(intfConf.Cost)
+			linkDetail.NumOfTOS = 0
 		} else {
 			switch intfConf.Type {
-			case objects.INTF_TYPE_BROADCAST:
+			case objects.INTF_TYPE_BROADCAST, objects.INTF_TYPE_NBMA:
 				if len(intfConf.NbrMap) == 0 { //Stub Network
 					server.logger.Debug("Stub Network")
 					linkDetail.LinkType = STUB_LINK
 					linkDetail.LinkData = intfConf.Netmask
 					linkDetail.LinkId = intfConf.IpAddr & intfConf.Netmask
+					linkDetail.LinkMetric = uint16(intfConf.Cost)
 				} else { //Transit Link
 					server.logger.Debug("Transit Network")
 					linkDetail.LinkType = TRANSIT_LINK
 					linkDetail.LinkData = intfConf.IpAddr
 					linkDetail.LinkId = intfConf.DRIpAddr
+					linkDetail.LinkMetric = server.getTransitLinkMetric(intfConf.Cost)
 				}
-				linkDetail.LinkMetric = uint16(intfConf.Cost)
 				linkDetail.NumOfTOS = 0
 			case objects.INTF_TYPE_POINT2POINT:
 				server.logger.Debug("P2P Network")
@@ -113,7 +122,44 @@ func (server *OSPFV2Server) GetLinkDetails(areaId uint32, areaEnt AreaConf) []Li
 					linkDetail.LinkData = intfKey.IpAddr
 				}
 				linkDetail.NumOfTOS = 0
-				linkDetail.LinkMetric = uint16(intfConf.Cost)
+				linkDetail.LinkMetric = server.getTransitLinkMetric(intfConf.Cost)
+			case objects.INTF_TYPE_POINT2MULTIPOINT:
+				// RFC 2328 12.4.1.4: host route to self plus
+				// one p2p link per fully adjacent nbr, no network LSA
+				server.logger.Debug("Point-to-Multipoint Network")
+				linkDetails = append(linkDetails, LinkDetail{
+					LinkId:     intfConf.IpAddr,
+					LinkData:   0xffffffff,
+					LinkType:   STUB_LINK,
+					NumOfTOS:   0,
+					LinkMetric: 0,
+				})
+				for nbrKey, nbr := range intfConf.NbrMap {
+					nbrConf, exist := server.NbrConfMap[nbrKey]
+					if !exist || nbrConf.State != NbrFull {
+						continue
+					}
+					linkDetails = append(linkDetails, LinkDetail{
+						LinkId:     nbr.RtrId,
+						LinkData:   intfConf.IpAddr,
+						LinkType:   P2P_LINK,
+						NumOfTOS:   0,
+						LinkMetric: server.getTransitLinkMetric(intfConf.Cost),
+					})
+				}
+				continue
+			case objects.INTF_TYPE_VIRTUAL:
+				if len(intfConf.NbrMap) == 0 {
+					continue
+				}
+				server.logger.Debug("Virtual Link")
+				linkDetail.LinkType = VIRTUAL_LINK
+				for _, nbr := range intfConf.NbrMap {
+					linkDetail.LinkId = nbr.RtrId
+				}
+				linkDetail.LinkData = intfConf.IpAddr
+				linkDetail.NumOfTOS = 0
+				linkDetail.LinkMetric = server.getTransitLinkMetric(intfConf.Cost)
 			}
 		}
 		linkDetails = append(linkDetails, linkDetail)
@@ -163,7 +209,7 @@ func (server *OSPFV2Server) GenerateRouterLSA(msg GenerateRouterLSAMsg) error {
 	lsaEnt.LsaMd.LSAge = 0
 	lsaEnt.LsaMd.LSChecksum = 0
 	lsaEnt.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + 4 + (12 * numOfLinks))
-	lsaEnt.LsaMd.Options = EOption
+	lsaEnt.LsaMd.Options = server.getAreaLsaOptions(lsdbKey.AreaId)
 	if !exist {
 		lsaEnt.LsaMd.LSSequenceNum = int(InitialSequenceNum)
 	} else {
@@ -171,7 +217,8 @@ func (server *OSPFV2Server) GenerateRouterLSA(msg GenerateRouterLSAMsg) error {
 	}
 	lsaEnt.BitB = BitB
 	lsaEnt.BitE = BitE
-	lsaEnt.BitV = false
+	lsaEnt.BitV = server.isVirtualLinkTransitArea(msg.AreaId)
+	lsaEnt.BitNt = BitB && server.isNssaTranslatorAlways(msg.AreaId)
 	lsaEnt.NumOfLinks = uint16(numOfLinks)
 	lsaEnt.LinkDetails = nil
 	lsaEnt.LinkDetails = append(lsaEnt.LinkDetails, linkDetails...)
@@ -235,11 +282,12 @@ func (server *OSPFV2Server) reGenerateRouterLSA(msg GenerateRouterLSAMsg) {
 	lsaEnt.LsaMd.LSAge = 0
 	lsaEnt.LsaMd.LSChecksum = 0
 	lsaEnt.LsaMd.LSLen = uint16(OSPF_LSA_HEADER_SIZE + 4 + (12 * numOfLinks))
-	lsaEnt.LsaMd.Options = EOption
+	lsaEnt.LsaMd.Options = server.getAreaLsaOptions(lsdbKey.AreaId)
 	lsaEnt.LsaMd.LSSequenceNum = lsaEnt.LsaMd.LSSequenceNum + 1
 	lsaEnt.BitB = BitB
 	lsaEnt.BitE = BitE
-	lsaEnt.BitV = false
+	lsaEnt.BitV = server.isVirtualLinkTransitArea(msg.AreaId)
+	lsaEnt.BitNt = BitB && server.isNssaTranslatorAlways(msg.AreaId)
 	lsaEnt.NumOfLinks = uint16(numOfLinks)
 	lsaEnt.LinkDetails = nil
 	lsaEnt.LinkDetails = append(lsaEnt.LinkDetails, linkDetails...)
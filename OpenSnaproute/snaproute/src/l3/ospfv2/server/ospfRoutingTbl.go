@@ -28,7 +28,6 @@ import (
 	"errors"
 	"fmt"
 	"ribd"
-	"strconv"
 )
 
 type RoutingTblStruct struct {
@@ -36,6 +35,12 @@ type RoutingTblStruct struct {
 	GlobalRoutingTbl     map[RoutingTblEntryKey]GlobalRoutingTblEntry
 	OldGlobalRoutingTbl  map[RoutingTblEntryKey]GlobalRoutingTblEntry
 	TempGlobalRoutingTbl map[RoutingTblEntryKey]GlobalRoutingTblEntry
+	DiscardRouteMap      map[AreaRangeKey]bool                        // Installed area range discard routes
+	PreservedRoutingTbl  map[RoutingTblEntryKey]GlobalRoutingTblEntry // Routes kept across graceful restart
+	IntraAreaRoutingTbl  map[AreaIdKey]AreaRoutingTbl                 // Intra-area routes of the last full SPF
+
+	VirtualLinkEndpointMap     map[VirtualLinkKey]VirtualLinkEndpoint
+	TempVirtualLinkEndpointMap map[VirtualLinkKey]VirtualLinkEndpoint
 }
 
 type DestType uint8
@@ -89,8 +94,8 @@ type AreaRoutingTbl struct {
 type RoutingTblEntry struct {
 	OptCapabilities uint8    // Optional Capabilities
 	PathType        PathType // Path Type
-	Cost            uint16
-	Type2Cost       uint16
+	Cost            uint32
+	Type2Cost       uint32
 	LSOrigin        LsaKey
 	NumOfPaths      int
 	NextHops        map[NextHop]bool // Next Hop
@@ -289,7 +294,8 @@ func (server *OSPFV2Server) findP2PNextHopIP(vFirst VertexKey, vSecond VertexKey
 	}
 	for _, link := range secondLsa.LinkDetails {
 		if link.LinkId == vFirst.AdvRtr &&
-			link.LinkType == P2P_LINK {
+			(link.LinkType == P2P_LINK ||
+				link.LinkType == VIRTUAL_LINK) {
 			secondLink = link
 			flag = true
 			break
@@ -302,6 +308,14 @@ func (server *OSPFV2Server) findP2PNextHopIP(vFirst VertexKey, vSecond VertexKey
 	}
 	ifIPAddr = firstLink.LinkData
 	nextHopIP = secondLink.LinkData
+	if firstLink.LinkType == VIRTUAL_LINK {
+		// Virtual nbr is reached through the transit area
+		nextHopIP, flag = server.getVirtualLinkNextHop(vSecond.AdvRtr)
+		if flag == false {
+			err = errors.New("Virtual link to second vertex is down")
+			return 0, 0, err
+		}
+	}
 	return ifIPAddr, nextHopIP, nil
 
 }
@@ -428,6 +442,7 @@ func (server *OSPFV2Server) UpdateRoutingTblForRouter(areaIdKey AreaIdKey, vKey
 		}
 		rEnt.NextHops[nextHop] = true
 	}
+	rEnt.NumOfPaths = len(rEnt.NextHops)
 	//rEnt.AdvRtr = vKey.AdvRtr
 	tempAreaRoutingTbl.RoutingTblMap[rKey] = rEnt
 	server.RoutingTblData.TempAreaRoutingTbl[areaIdKey] = tempAreaRoutingTbl
@@ -502,19 +517,24 @@ func (server *OSPFV2Server) UpdateRoutingTblWithStub(areaId uint32, vKey VertexK
 	}
 	rEnt, exist := tempAreaRoutingTbl.RoutingTblMap[rKey]
 	if exist {
-		server.logger.Info("Routing Tbl entry for Stub already exist for:", rKey)
-		return
+		if rEnt.Cost < tVertex.Distance {
+			server.logger.Info("Routing Tbl entry for Stub already exist with lesser cost for:", rKey)
+			return
+		}
+		if rEnt.Cost == tVertex.Distance {
+			// Stub advertised by multiple routers at equal cost
+			mergeNextHops(&rEnt, pREnt.NextHops, 0)
+			tempAreaRoutingTbl.RoutingTblMap[rKey] = rEnt
+			server.RoutingTblData.TempAreaRoutingTbl[areaIdKey] = tempAreaRoutingTbl
+			return
+		}
 	}
 	rEnt.OptCapabilities = pREnt.OptCapabilities //TODO
 	rEnt.PathType = IntraArea                    //TODO
 	rEnt.Cost = tVertex.Distance
 	rEnt.Type2Cost = 0 //TODO
 	rEnt.LSOrigin = sEnt.LsaKey
-	rEnt.NumOfPaths = tVertex.NumOfPaths
-	rEnt.NextHops = make(map[NextHop]bool, tVertex.NumOfPaths)
-	for key, _ := range pREnt.NextHops {
-		rEnt.NextHops[key] = true
-	}
+	setNextHops(&rEnt, pREnt.NextHops, 0)
 	//rEnt.AdvRtr = vKey.AdvRtr
 	tempAreaRoutingTbl.RoutingTblMap[rKey] = rEnt
 	server.RoutingTblData.TempAreaRoutingTbl[areaIdKey] = tempAreaRoutingTbl
@@ -585,6 +605,7 @@ func (server *OSPFV2Server) UpdateRoutingTblForSNetwork(areaIdKey AreaIdKey, vKe
 		}
 		rEnt.NextHops[nextHop] = true
 	}
+	rEnt.NumOfPaths = len(rEnt.NextHops)
 	//rEnt.AdvRtr = vKey.AdvRtr
 	tempAreaRoutingTbl.RoutingTblMap[rKey] = rEnt
 	server.RoutingTblData.TempAreaRoutingTbl[areaIdKey] = tempAreaRoutingTbl
@@ -687,6 +708,7 @@ func (server *OSPFV2Server) UpdateRoutingTblForTNetwork(areaIdKey AreaIdKey, vKe
 		}
 		rEnt.NextHops[nextHop] = true
 	}
+	rEnt.NumOfPaths = len(rEnt.NextHops)
 	//rEnt.AdvRtr = vKey.AdvRtr
 	tempAreaRoutingTbl.RoutingTblMap[rKey] = rEnt
 	server.RoutingTblData.TempAreaRoutingTbl[areaIdKey] = tempAreaRoutingTbl
@@ -730,31 +752,23 @@ func (server *OSPFV2Server) DeleteRoute(rKey RoutingTblEntryKey) {
 	destNetIp := convertUint32ToDotNotation(rKey.DestId)
 	networkMask := convertUint32ToDotNotation(rKey.AddrMask)
 	routeType := "OSPF"
-	for key, _ := range oldEnt.RoutingTblEnt.NextHops {
-		nextHopIp := convertUint32ToDotNotation(key.NextHopIP)
-		server.logger.Info("Deleting Route: destNetIp:", destNetIp, "networkMask:", networkMask, "nextHopIp:", nextHopIp, "routeType:", routeType)
-		cfg := ribd.IPv4Route{
-			DestinationNw: destNetIp,
-			Protocol:      routeType,
-			Cost:          0,
-			NetworkMask:   networkMask,
-		}
-		nextHopInfo := ribd.NextHopInfo{
-			NextHopIp: nextHopIp,
-		}
-		cfg.NextHop = make([]*ribd.NextHopInfo, 0)
-		cfg.NextHop = append(cfg.NextHop, &nextHopInfo)
-		if server.ribdComm.ribdClient.ClientHdl == nil {
-			server.logger.Err("Nil ribd handle. Can not delete route. ")
-			return
-		}
-
-		ret, err := server.ribdComm.ribdClient.ClientHdl.DeleteIPv4Route(&cfg)
-		if err != nil {
-			server.logger.Err("Error Deleting Route:", err)
-		}
-		server.logger.Info("Return Value for RIB DeleteV4Route call: ", ret)
+	cfg := ribd.IPv4Route{
+		DestinationNw: destNetIp,
+		Protocol:      routeType,
+		Cost:          0,
+		NetworkMask:   networkMask,
+		NextHop:       server.getRibdNextHopList(oldEnt.RoutingTblEnt, false),
+	}
+	server.logger.Info("Deleting Route: destNetIp:", destNetIp, "networkMask:", networkMask, "numOfNextHops:", len(cfg.NextHop), "routeType:", routeType)
+	if server.ribdComm.ribdClient.ClientHdl == nil {
+		server.logger.Err("Nil ribd handle. Can not delete route. ")
+		return
+	}
+	ret, err := server.ribdComm.ribdClient.ClientHdl.DeleteIPv4Route(&cfg)
+	if err != nil {
+		server.logger.Err("Error Deleting Route:", err)
 	}
+	server.logger.Info("Return Value for RIB DeleteV4Route call: ", ret)
 	msg := RouteDelMsg{
 		RTblKey: rKey,
 	}
@@ -772,33 +786,27 @@ func (server *OSPFV2Server) InstallRoute(rKey RoutingTblEntryKey) {
 	networkMask := convertUint32ToDotNotation(rKey.AddrMask)
 	metric := ribd.Int(newEnt.RoutingTblEnt.Cost)
 	routeType := "OSPF"
-	for key, _ := range newEnt.RoutingTblEnt.NextHops {
-		nextHopIp := convertUint32ToDotNotation(key.NextHopIP)
-		ifIdx, exist := server.infraData.ipToIfIdxMap[key.IfIPAddr]
-		if !exist {
-			server.logger.Err("Unable to find entry for ip:", key.IfIPAddr, "in ipToIfIdxMap")
-			continue
-		}
-		//nextHopIfIndex := asicdCommonDefs.GetIfIndexFromIntfIdAndIntfType(int(ipProp.IfId), int(ipProp.IfType))
-		nextHopIfIndex := ifIdx
-		server.logger.Info("Installing Route: destNetIp:", destNetIp, "networkMask:", networkMask, "metric:", metric, "nextHopIp:", nextHopIp, "nextHopIfIndex:", nextHopIfIndex, "routeType:", routeType)
-		cfg := ribd.IPv4Route{
-			DestinationNw: destNetIp,
-			Protocol:      routeType,
-			Cost:          int32(metric),
-			NetworkMask:   networkMask,
-		}
-		nextHopInfo := ribd.NextHopInfo{
-			NextHopIp:     nextHopIp,
-			NextHopIntRef: strconv.Itoa(int(nextHopIfIndex)),
-		}
-		cfg.NextHop = make([]*ribd.NextHopInfo, 0)
-		cfg.NextHop = append(cfg.NextHop, &nextHopInfo)
-		ret, err := server.ribdComm.ribdClient.ClientHdl.CreateIPv4Route(&cfg)
-		if err != nil {
-			server.logger.Err("Error Installing Route:", err, ret)
-			continue
-		}
+	cfg := ribd.IPv4Route{
+		DestinationNw: destNetIp,
+		Protocol:      routeType,
+		Cost:          int32(metric),
+		NetworkMask:   networkMask,
+		NextHop:       server.getRibdNextHopList(newEnt.RoutingTblEnt, true),
+	}
+	if len(cfg.NextHop) == 0 {
+		server.logger.Err("No valid next hop for rKey:", rKey, "hence not installing it")
+		return
+	}
+	for _, nextHopInfo := range cfg.NextHop {
+		server.logger.Info("Installing Route: destNetIp:", destNetIp, "networkMask:", networkMask, "metric:", metric, "nextHopIp:", nextHopInfo.NextHopIp, "nextHopIfIndex:", nextHopInfo.NextHopIntRef, "routeType:", routeType)
+	}
+	if server.ribdComm.ribdClient.ClientHdl == nil {
+		server.logger.Err("Nil ribd handle. Can not install route. ")
+		return
+	}
+	ret, err := server.ribdComm.ribdClient.ClientHdl.CreateIPv4Route(&cfg)
+	if err != nil {
+		server.logger.Err("Error Installing Route:", err, ret)
 	}
 	msg := RouteAddMsg{
 		RTblKey:   rKey,
@@ -818,6 +826,7 @@ func (server *OSPFV2Server) ConsolidatingRoutingTbl() {
 		}
 
 		for rKey, rEnt := range tempAreaRoutingTbl.RoutingTblMap {
+			server.limitNextHops(&rEnt)
 			ent, exist := server.RoutingTblData.TempGlobalRoutingTbl[rKey]
 			if exist {
 
@@ -841,6 +850,15 @@ func (server *OSPFV2Server) UpdateRoute(rKey RoutingTblEntryKey) {
 func (server *OSPFV2Server) InstallRoutingTbl() {
 	server.logger.Info("Routing Table Consolidation:")
 	server.ConsolidatingRoutingTbl()
+	// Forwarding state is kept as is until the graceful restart is over
+	if server.isGracefulRestartInProgress() {
+		server.logger.Info("Graceful restart in progress, not installing Routing Table")
+		return
+	}
+	if server.RoutingTblData.PreservedRoutingTbl != nil {
+		server.RoutingTblData.OldGlobalRoutingTbl = server.RoutingTblData.PreservedRoutingTbl
+		server.RoutingTblData.PreservedRoutingTbl = nil
+	}
 	server.logger.Info("Installing Routing Table ")
 
 	OldRoutingTblKeys := make(map[RoutingTblEntryKey]bool)
@@ -899,39 +917,25 @@ func (server *OSPFV2Server) FlushRoutingTbl() {
 		destNetIp := convertUint32ToDotNotation(rKey.DestId)
 		networkMask := convertUint32ToDotNotation(rKey.AddrMask)
 		routeType := "OSPF"
-		for key, _ := range rEnt.RoutingTblEnt.NextHops {
-			nextHopIp := convertUint32ToDotNotation(key.NextHopIP)
+		if len(rEnt.RoutingTblEnt.NextHops) > 0 &&
+			server.ribdComm.ribdClient.ClientHdl != nil {
 			cfg := ribd.IPv4Route{
 				DestinationNw: destNetIp,
 				Protocol:      routeType,
 				Cost:          0,
 				NetworkMask:   networkMask,
+				NextHop:       server.getRibdNextHopList(rEnt.RoutingTblEnt, false),
 			}
-			nextHopInfo := ribd.NextHopInfo{
-				NextHopIp: nextHopIp,
-			}
-			cfg.NextHop = make([]*ribd.NextHopInfo, 0)
-			cfg.NextHop = append(cfg.NextHop, &nextHopInfo)
-			if server.ribdComm.ribdClient.ClientHdl == nil {
-				server.logger.Err("Nil ribd handle. Can not delete route. ")
-				continue
-			}
-
 			ret, err := server.ribdComm.ribdClient.ClientHdl.DeleteIPv4Route(&cfg)
 			if err != nil {
 				server.logger.Err("Error Deleting Route:", err)
 			}
 			server.logger.Info("Return Value for RIB DeleteV4Route call: ", ret)
-			/*
-			   err = server.DelIPv4RoutesState(rKey)
-			   if err != nil {
-			           server.logger.Info(fmt.Sprintln("DB: Failed to delete route from db. route , err ", rKey, err))
-			   }
-			*/
 		}
 		msg := RouteDelMsg{
 			RTblKey: rKey,
 		}
 		server.SendRouteDelMsgToDBClnt(msg)
 	}
+	server.flushAreaRangeDiscardRoutes()
 }
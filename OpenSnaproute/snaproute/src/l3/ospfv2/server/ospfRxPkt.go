@@ -29,7 +29,6 @@ import (
 	"fmt"
 	"github.com/google/gopacket"
 	"github.com/google/gopacket/layers"
-	"github.com/google/gopacket/pcap"
 	"l3/ospfv2/objects"
 	"time"
 )
@@ -68,7 +67,7 @@ func (server *OSPFV2Server) processOspfData(recvPktData *OspfPktStruct, helloPkt
 	return err
 }
 
-func (server *OSPFV2Server) processIPv4Layer(ipLayer gopacket.Layer, IpAddr uint32, ipHdrMd *IpHdrMetadata) error {
+func (server *OSPFV2Server) processIPv4Layer(ipLayer gopacket.Layer, IpAddrs []uint32, ipHdrMd *IpHdrMetadata) error {
 	ipLayerContents := ipLayer.LayerContents()
 	ipChkSum := binary.BigEndian.Uint16(ipLayerContents[10:12])
 	binary.BigEndian.PutUint16(ipLayerContents[10:], 0)
@@ -82,12 +81,12 @@ func (server *OSPFV2Server) processIPv4Layer(ipLayer gopacket.Layer, IpAddr uint
 	ipPkt := ipLayer.(*layers.IPv4)
 	ipHdrMd.SrcIP, _ = convertDotNotationToUint32(ipPkt.SrcIP.To4().String())
 	ipHdrMd.DstIP, _ = convertDotNotationToUint32(ipPkt.DstIP.To4().String())
-	if IpAddr == ipHdrMd.SrcIP {
+	if isIpAddrInList(ipHdrMd.SrcIP, IpAddrs) {
 		err := errors.New(fmt.Sprintln("locally generated pkt", ipPkt.SrcIP, "hence dicarding the packet"))
 		return err
 	}
 
-	if IpAddr != ipHdrMd.DstIP &&
+	if !isIpAddrInList(ipHdrMd.DstIP, IpAddrs) &&
 		ALLDROUTER != ipHdrMd.DstIP &&
 		ALLSPFROUTER != ipHdrMd.DstIP {
 		err := errors.New(fmt.Sprintln("Incorrect DstIP", ipPkt.DstIP, "hence dicarding the packet"))
@@ -123,14 +122,25 @@ func (server *OSPFV2Server) processOspfHeader(ospfPkt []byte, key IntfConfKey, m
 	ospfHdr := NewOSPFHeader()
 
 	decodeOspfHdr(ospfPkt, ospfHdr)
+	nbrKey := NbrConfKey{
+		NbrIdentity:         ipHdrMd.SrcIP,
+		NbrAddressLessIfIdx: key.IntfIdx,
+	}
 
 	if OSPF_VERSION_2 != ospfHdr.Ver {
 		err := errors.New("Dropped because of Ospf Version not matching")
 		return err
 	}
 
+	if int(ospfHdr.Pktlen) < OSPF_HEADER_SIZE ||
+		int(ospfHdr.Pktlen) > len(ospfPkt) {
+		err := errors.New("Dropped because of invalid Ospf packet length")
+		return err
+	}
+
 	if ent.AreaId == ospfHdr.AreaId {
-		if ent.Type != objects.INTF_TYPE_POINT2POINT {
+		if ent.Type != objects.INTF_TYPE_POINT2POINT &&
+			ent.Type != objects.INTF_TYPE_VIRTUAL {
 			if (ent.IpAddr & ent.Netmask) != (ipHdrMd.SrcIP & ent.Netmask) {
 				err := errors.New("Dropped because of Src IP is not in subnet and Area ID is matching")
 				return err
@@ -138,8 +148,11 @@ func (server *OSPFV2Server) processOspfHeader(ospfPkt []byte, key IntfConfKey, m
 			}
 		}
 	} else {
-		// We don't support Virtual Link
-		err := errors.New("Dropped because Area ID is not matching and we dont support Virtual links, so this should not happend")
+		// Backbone pkts for a virtual link are received on the virtual interface
+		server.updatePktStats(key, nbrKey, func(stats *objects.Ospfv2PktStats) {
+			stats.NumOfAreaMismatch++
+		})
+		err := errors.New("Dropped because Area ID is not matching")
 		return err
 
 	}
@@ -152,33 +165,25 @@ func (server *OSPFV2Server) processOspfHeader(ospfPkt []byte, key IntfConfKey, m
 		}
 	}
 
-	//OSPF Auth Type
-	if ent.AuthType != ospfHdr.AuthType {
-		err := errors.New("Dropped because of Router Id not matching")
+	//OSPF Authentication
+	err := server.verifyOspfAuth(ent, ospfPkt, ospfHdr, ipHdrMd.SrcIP)
+	if err != nil {
+		server.countNbrAuthFailure(nbrKey)
 		return err
 	}
 
-	//TODO: We don't support Authentication
-
 	if ospfHdr.PktType != HelloType {
-		if ent.Type == objects.INTF_TYPE_BROADCAST {
-			nbrKey := NbrConfKey{
-				NbrIdentity:         ipHdrMd.SrcIP,
-				NbrAddressLessIfIdx: key.IntfIdx,
-			}
+		if isMultiAccessIntf(ent.Type) {
 			_, exist := ent.NbrMap[nbrKey]
 			if !exist {
 				err := errors.New("Adjacency not established with this nbr")
 				return err
 			}
-		} else if ent.Type == objects.INTF_TYPE_POINT2POINT {
+		} else if ent.Type == objects.INTF_TYPE_POINT2POINT ||
+			ent.Type == objects.INTF_TYPE_POINT2MULTIPOINT ||
+			ent.Type == objects.INTF_TYPE_VIRTUAL {
 			/* For future - For unnumbered P2P the identity will be
 			   router id. */
-
-			nbrKey := NbrConfKey{
-				NbrIdentity:         ipHdrMd.SrcIP,
-				NbrAddressLessIfIdx: key.IntfIdx,
-			}
 			_, exist := ent.NbrMap[nbrKey]
 			if !exist {
 				err := errors.New("Adjacency not established with this nbr")
@@ -187,13 +192,18 @@ func (server *OSPFV2Server) processOspfHeader(ospfPkt []byte, key IntfConfKey, m
 		}
 	}
 
-	//OSPF Header CheckSum
-	binary.BigEndian.PutUint16(ospfPkt[12:14], 0)
-	copy(ospfPkt[16:OSPF_HEADER_SIZE], []byte{0, 0, 0, 0, 0, 0, 0, 0})
-	csum := computeCheckSum(ospfPkt)
-	if csum != ospfHdr.Chksum {
-		err := errors.New("Dropped because of invalid checksum")
-		return err
+	//OSPF Header CheckSum, not used with Cryptographic authentication
+	if uint8(ospfHdr.AuthType) != objects.AUTH_TYPE_MD5 {
+		binary.BigEndian.PutUint16(ospfPkt[12:14], 0)
+		copy(ospfPkt[16:OSPF_HEADER_SIZE], []byte{0, 0, 0, 0, 0, 0, 0, 0})
+		csum := computeCheckSum(ospfPkt[:ospfHdr.Pktlen])
+		if csum != ospfHdr.Chksum {
+			server.updatePktStats(key, nbrKey, func(stats *objects.Ospfv2PktStats) {
+				stats.NumOfBadChecksum++
+			})
+			err := errors.New("Dropped because of invalid checksum")
+			return err
+		}
 	}
 
 	md.PktType = ospfHdr.PktType
@@ -239,7 +249,7 @@ func (server *OSPFV2Server) processOspfPkt(pkt gopacket.Packet, key IntfConfKey,
 	}
 
 	ipHdrMd := NewIpHdrMetadata()
-	err := server.processIPv4Layer(ipLayer, ent.IpAddr, ipHdrMd)
+	err := server.processIPv4Layer(ipLayer, getIntfRxIpAddrs(ent), ipHdrMd)
 	if err != nil {
 		return errors.New(fmt.Sprintln("Dropped because of IPv4 layer processing", err))
 	}
@@ -253,7 +263,8 @@ func (server *OSPFV2Server) processOspfPkt(pkt gopacket.Packet, key IntfConfKey,
 	}
 	ospfPktData.OspfHdrMd = ospfHdrMd
 
-	ospfPktData.Data = ospfPkt[OSPF_HEADER_SIZE:]
+	//Strip the Message Digest (if any) trailing the ospf packet
+	ospfPktData.Data = ospfPkt[OSPF_HEADER_SIZE:ospfHdrMd.Pktlen]
 	return nil
 }
 
@@ -268,6 +279,7 @@ func (server *OSPFV2Server) ProcessOspfRecvHelloPkt(recvPktData OspfHelloPktRecv
 		case _ = <-recvPktData.OspfRecvHelloCtrlCh:
 			server.logger.Info("Stopping ProcessOspfRecvHelloPkt routine")
 			recvPktData.OspfRecvHelloCtrlReplyCh <- true
+			return
 		}
 	}
 
@@ -300,7 +312,7 @@ func (server *OSPFV2Server) ProcessOspfRecvLsaAndDbdPkt(recvPktData OspfLsaAndDb
 
 			switch msg.OspfHdrMd.PktType {
 			case DBDescriptionType:
-				err := server.ProcessRxDbdPkt(msg.Data, msg.OspfHdrMd, msg.IpHdrMd, nbrKey)
+				err := server.ProcessRxDbdPkt(msg.Data, msg.OspfHdrMd, msg.IpHdrMd, nbrKey, recvPktData.IntfConfKey)
 				if err != nil {
 					server.logger.Err("Failed to process rx dbd pkt ", nbrKey)
 				}
@@ -325,6 +337,7 @@ func (server *OSPFV2Server) ProcessOspfRecvLsaAndDbdPkt(recvPktData OspfLsaAndDb
 		case _ = <-recvPktData.OspfRecvLsaAndDbdCtrlCh:
 			server.logger.Info("Stopping ProcessOspfRecvLsaAndDbdPkt routine")
 			recvPktData.OspfRecvLsaAndDbdCtrlReplyCh <- true
+			return
 		}
 	}
 }
@@ -359,13 +372,14 @@ func (server *OSPFV2Server) ProcessOspfRecvPkt(recvPkt OspfPktRecvStruct) {
 				server.logger.Err("Error processing Ospf Pkt:", err)
 				continue
 			}
+			server.countRxPkt(recvPkt.IntfConfKey, ospfPktData)
 			server.processOspfData(ospfPktData, recvHelloPkt.OspfRecvHelloPktCh, recvLsaAndDbdPkt.OspfRecvLsaAndDbdPktCh)
 		case _ = <-recvPkt.OspfRecvCtrlCh:
 			server.logger.Info("Stopping ProcessOspfRecvPkt")
 			recvHelloPkt.OspfRecvHelloCtrlCh <- true
 			_ = <-recvHelloPkt.OspfRecvHelloCtrlReplyCh
 			recvLsaAndDbdPkt.OspfRecvLsaAndDbdCtrlCh <- true
-			_ = recvLsaAndDbdPkt.OspfRecvLsaAndDbdCtrlReplyCh
+			_ = <-recvLsaAndDbdPkt.OspfRecvLsaAndDbdCtrlReplyCh
 			recvPkt.OspfRecvCtrlReplyCh <- true
 			return
 		}
@@ -384,13 +398,18 @@ func (server *OSPFV2Server) StartOspfRecvPkts(key IntfConfKey) {
 	}
 	ent, _ := server.IntfConfMap[key]
 	go server.ProcessOspfRecvPkt(recvPkt)
-	handle := ent.rxHdl.RecvPcapHdl
+	handle := ent.rxHdl.RecvHdl
 	recv := gopacket.NewPacketSource(handle, layers.LayerTypeEthernet)
 	in := recv.Packets()
 	for {
 		select {
 		case packet, ok := <-in:
 			if ok {
+				arpLayer := packet.Layer(layers.LayerTypeARP)
+				if arpLayer != nil {
+					server.processRxArpPkt(key, arpLayer.(*layers.ARP))
+					continue
+				}
 				ipLayer := packet.Layer(layers.LayerTypeIPv4)
 				if ipLayer == nil {
 					server.logger.Err("Not an IP packet")
@@ -401,6 +420,9 @@ func (server *OSPFV2Server) StartOspfRecvPkts(key IntfConfKey) {
 				if ipPkt.Protocol == layers.IPProtocol(OSPF_PROTO_ID) {
 					recvPkt.OspfRecvPktCh <- packet
 				}
+			} else {
+				// Packet source is closed, wait for the stop
+				in = nil
 			}
 		case _ = <-ent.rxHdl.PktRecvCtrlCh:
 			server.logger.Info("Stopping the Recv Ospf packet thread")
@@ -434,21 +456,16 @@ func (server *OSPFV2Server) StopOspfRecvPkts(key IntfConfKey) {
 func (server *OSPFV2Server) InitRxPkt(intfKey IntfConfKey) error {
 	intfEnt, _ := server.IntfConfMap[intfKey]
 	ifName := intfEnt.IfName
-	ipAddr := intfEnt.IpAddr
-	recvHdl, err := pcap.OpenLive(ifName, snapshotLen, promiscuous, pcapTimeout)
-	if err != nil {
-		server.logger.Err("Error opening recv pcap handler", ifName)
-		return err
+	paramsList := []PktIOParams{getPktIOParams(intfEnt)}
+	if intfEnt.Type == objects.INTF_TYPE_VIRTUAL {
+		paramsList = server.getVirtualLinkRxParams(intfEnt)
 	}
-	ip := convertUint32ToDotNotation(ipAddr)
-	filter := fmt.Sprintf("proto ospf and not src host %s", ip)
-	server.logger.Info("Filter:", filter)
-	err = recvHdl.SetBPFFilter(filter)
+	recvHdl, err := openMergedRxHandle(server.pktIO, paramsList)
 	if err != nil {
-		server.logger.Err("Unable to set filter on", ifName)
+		server.logger.Err("Error opening rx handle on", ifName, err)
 		return err
 	}
-	intfEnt.rxHdl.RecvPcapHdl = recvHdl
+	intfEnt.rxHdl.RecvHdl = recvHdl
 	intfEnt.rxHdl.PktRecvCtrlCh = make(chan bool)
 	intfEnt.rxHdl.PktRecvCtrlReplyCh = make(chan bool)
 	server.IntfConfMap[intfKey] = intfEnt
@@ -457,8 +474,8 @@ func (server *OSPFV2Server) InitRxPkt(intfKey IntfConfKey) error {
 
 func (server *OSPFV2Server) DeinitRxPkt(intfKey IntfConfKey) {
 	intfEnt, _ := server.IntfConfMap[intfKey]
-	intfEnt.rxHdl.RecvPcapHdl.Close()
-	intfEnt.rxHdl.RecvPcapHdl = nil
+	intfEnt.rxHdl.RecvHdl.Close()
+	intfEnt.rxHdl.RecvHdl = nil
 	intfEnt.rxHdl.PktRecvCtrlCh = nil
 	intfEnt.rxHdl.PktRecvCtrlReplyCh = nil
 	server.IntfConfMap[intfKey] = intfEnt
@@ -28,6 +28,10 @@ func (server *OSPFV2Server) StopIntfRxTxPkt(intfKey IntfConfKey) {
 	//Nothing to stop for Tx
 	server.DeinitRxPkt(intfKey)
 	server.DeinitTxPkt(intfKey)
+	intfEnt, _ := server.IntfConfMap[intfKey]
+	if isStaticNbrIntf(intfEnt.Type) {
+		server.flushArpCache(intfKey)
+	}
 	server.logger.Info("StopIntfRxTxPkt() successfully")
 }
 
@@ -49,13 +49,13 @@ type Path []VertexKey
 
 type TreeVertex struct {
 	Paths      []Path
-	Distance   uint16
+	Distance   uint32
 	NumOfPaths int
 }
 
 type StubVertex struct {
 	NbrVertexKey  VertexKey
-	NbrVertexCost uint16
+	NbrVertexCost uint32
 	LinkData      uint32
 	LsaKey        LsaKey
 	AreaId        uint32
@@ -64,7 +64,7 @@ type StubVertex struct {
 
 type Vertex struct {
 	NbrVertexKey  []VertexKey
-	NbrVertexCost []uint16
+	NbrVertexCost []uint32
 	LinkData      map[VertexKey]uint32
 	LsaKey        LsaKey
 	AreaId        uint32
@@ -81,7 +81,7 @@ const (
 
 type VertexData struct {
 	vKey     VertexKey
-	distance uint16
+	distance uint32
 }
 
 var check bool = true
@@ -175,7 +175,7 @@ func (server *OSPFV2Server) UpdateAreaGraphNetworkLsa(lsaEnt NetworkLsa, lsaKey
 	network := lsaKey.LSId & netmask
 	server.logger.Info("netmask:", netmask, "network:", network)
 	ent.NbrVertexKey = make([]VertexKey, 0)
-	ent.NbrVertexCost = make([]uint16, 0)
+	ent.NbrVertexCost = make([]uint32, 0)
 	ent.LinkData = make(map[VertexKey]uint32)
 	for i := 0; i < len(lsaEnt.AttachedRtr); i++ {
 		Rtr := lsaEnt.AttachedRtr[i]
@@ -186,7 +186,7 @@ func (server *OSPFV2Server) UpdateAreaGraphNetworkLsa(lsaEnt NetworkLsa, lsaKey
 		}
 		server.logger.Info("Attached Router at index:", i, "is:", Rtr)
 		var vKey VertexKey
-		var cost uint16
+		var cost uint32
 		vKey = VertexKey{
 			Type:   RouterVertex,
 			ID:     Rtr,
@@ -323,13 +323,13 @@ func (server *OSPFV2Server) UpdateAreaGraphRouterLsa(lsaEnt RouterLsa, lsaKey Ls
 		}
 	*/
 	ent.NbrVertexKey = make([]VertexKey, 0)
-	ent.NbrVertexCost = make([]uint16, 0)
+	ent.NbrVertexCost = make([]uint32, 0)
 	ent.LinkData = make(map[VertexKey]uint32)
 	for i := 0; i < int(lsaEnt.NumOfLinks); i++ {
 		server.logger.Info("Link Detail at index", i, "is:", lsaEnt.LinkDetails[i])
 		linkDetail := lsaEnt.LinkDetails[i]
 		var vKey VertexKey
-		var cost uint16
+		var cost uint32
 		var lData uint32
 		if linkDetail.LinkType == TRANSIT_LINK {
 			server.logger.Info("===It is TransitLink===")
@@ -348,7 +348,7 @@ func (server *OSPFV2Server) UpdateAreaGraphRouterLsa(lsaEnt RouterLsa, lsaKey Ls
 				//continue
 			}
 			vKey.AdvRtr = nLsaKey.AdvRouter
-			cost = linkDetail.LinkMetric
+			cost = uint32(linkDetail.LinkMetric)
 			lData = linkDetail.LinkData
 			ent.NbrVertexKey = append(ent.NbrVertexKey, vKey)
 			ent.NbrVertexCost = append(ent.NbrVertexCost, cost)
@@ -360,7 +360,7 @@ func (server *OSPFV2Server) UpdateAreaGraphRouterLsa(lsaEnt RouterLsa, lsaKey Ls
 				ID:     linkDetail.LinkId,
 				AdvRtr: lsaKey.AdvRouter,
 			}
-			cost = linkDetail.LinkMetric
+			cost = uint32(linkDetail.LinkMetric)
 			lData = linkDetail.LinkData
 			sentry, _ := server.SPFData.AreaStubs[vKey]
 			sentry.NbrVertexKey = vertexKey
@@ -370,7 +370,8 @@ func (server *OSPFV2Server) UpdateAreaGraphRouterLsa(lsaEnt RouterLsa, lsaKey Ls
 			sentry.LsaKey = lsaKey
 			sentry.LinkStateId = lsaKey.LSId
 			server.SPFData.AreaStubs[vKey] = sentry
-		} else if linkDetail.LinkType == P2P_LINK {
+		} else if linkDetail.LinkType == P2P_LINK ||
+			linkDetail.LinkType == VIRTUAL_LINK {
 			server.logger.Info("===It is P2PLink===")
 			vKey = VertexKey{
 				Type:   RouterVertex,
@@ -382,7 +383,7 @@ func (server *OSPFV2Server) UpdateAreaGraphRouterLsa(lsaEnt RouterLsa, lsaKey Ls
 				server.logger.Info("Err:", err, vKey.ID)
 				return err
 			}
-			cost = linkDetail.LinkMetric
+			cost = uint32(linkDetail.LinkMetric)
 			lData = linkDetail.LinkData
 			ent.NbrVertexKey = append(ent.NbrVertexKey, vKey)
 			ent.NbrVertexCost = append(ent.NbrVertexCost, cost)
@@ -587,7 +588,7 @@ func (server *OSPFV2Server) ExecuteDijkstra(vKey VertexKey, areaId uint32) error
 				var path Path
 				path = make(Path, 0)
 				tEnt.Paths[0] = path
-				tEnt.Distance = 0xff00 // LSInfinity
+				tEnt.Distance = LSInfinity
 				tEnt.NumOfPaths = 1
 			}
 			tEntry, exist := server.SPFData.SPFTree[treeVSlice[j].vKey]
@@ -617,6 +618,13 @@ func (server *OSPFV2Server) ExecuteDijkstra(vKey VertexKey, areaId uint32) error
 					tEnt.Paths[l] = path
 				}
 				tEnt.NumOfPaths = tEntry.NumOfPaths
+				// Keep the candidate list ordered on the lowered distance
+				for l := j + 1; l < len(treeVSlice); l++ {
+					if treeVSlice[l].vKey == verKey {
+						treeVSlice[l].distance = tEnt.Distance
+						break
+					}
+				}
 			} else if tEnt.Distance == tEntry.Distance+cost {
 				server.logger.Debug("We have equal cost path via:", tEntry)
 				server.logger.Debug("tEnt:", tEnt, "tEntry:", tEntry)
@@ -805,7 +813,7 @@ func (server *OSPFV2Server) UpdateRoutingTbl(vKey VertexKey, areaId uint32) {
 	}
 }
 
-func (server *OSPFV2Server) SPFCalculation() {
+func (server *OSPFV2Server) SPFCalculation(trigger uint8) {
 	server.logger.Info("Area LS Database:", server.LsdbData.AreaLsdb)
 	// Create New Routing table
 	// Invalidate Old Routing table
@@ -817,6 +825,8 @@ func (server *OSPFV2Server) SPFCalculation() {
 	server.RoutingTblData.OldGlobalRoutingTbl = server.RoutingTblData.GlobalRoutingTbl
 	server.RoutingTblData.TempAreaRoutingTbl = nil
 	server.RoutingTblData.TempAreaRoutingTbl = make(map[AreaIdKey]AreaRoutingTbl)
+	server.RoutingTblData.TempVirtualLinkEndpointMap = make(map[VirtualLinkKey]VirtualLinkEndpoint)
+	server.RoutingTblData.IntraAreaRoutingTbl = make(map[AreaIdKey]AreaRoutingTbl)
 	for areaId, aEnt := range server.AreaConfMap {
 
 		server.logger.Info("Area Id : ", areaId, "Area Bdr Status:", server.globalData.AreaBdrRtrStatus)
@@ -824,6 +834,7 @@ func (server *OSPFV2Server) SPFCalculation() {
 			continue
 		}
 		//aEnt.TransitCapability = false
+		startTime := time.Now()
 		server.InitSPFStructs()
 		areaIdKey := AreaIdKey{
 			AreaId: areaId,
@@ -856,34 +867,20 @@ func (server *OSPFV2Server) SPFCalculation() {
 			//server.dumpSPFTree()
 		server.logger.Info("End after Dijkstra")
 		server.UpdateRoutingTbl(vKey, areaId)
+		if areaId != 0 {
+			server.updateVirtualLinkEndpoints(areaId)
+		}
 		server.logger.Info("Handling Stub links...")
 		server.HandleStubs(vKey, areaId)
+		server.saveIntraAreaRoutingTbl(areaIdKey)
 		server.HandleSummaryLsa(areaId)
+		server.updateAreaSpfStats(areaId, SPF_CALC_FULL, trigger, startTime)
 		server.SPFData.AreaGraph = nil
 		server.SPFData.AreaStubs = nil
 		server.SPFData.SPFTree = nil
 	}
 	 //server.dumpRoutingTbl()
-	server.RoutingTblData.TempGlobalRoutingTbl = nil
-	server.RoutingTblData.TempGlobalRoutingTbl = make(map[RoutingTblEntryKey]GlobalRoutingTblEntry)
-	/* Summarize and Install/Delete Routes In Routing Table */
-	server.InstallRoutingTbl()
-	// Copy the Summarize Routing Table in Global Routing Table
-	server.RoutingTblData.GlobalRoutingTbl = nil
-	server.RoutingTblData.GlobalRoutingTbl = make(map[RoutingTblEntryKey]GlobalRoutingTblEntry)
-	server.RoutingTblData.GlobalRoutingTbl = server.RoutingTblData.TempGlobalRoutingTbl
-	server.dumpGlobalRoutingTbl()
-	for areaId, _ := range server.AreaConfMap {
-		areaIdKey := AreaIdKey{
-			AreaId: areaId,
-		}
-		tempAreaRoutingTbl := server.RoutingTblData.TempAreaRoutingTbl[areaIdKey]
-		tempAreaRoutingTbl.RoutingTblMap = nil
-		server.RoutingTblData.TempAreaRoutingTbl[areaIdKey] = tempAreaRoutingTbl
-	}
-	server.RoutingTblData.TempAreaRoutingTbl = nil
-	server.RoutingTblData.OldGlobalRoutingTbl = nil
-	server.RoutingTblData.TempGlobalRoutingTbl = nil
+	server.installSPFRoutingTbl()
 	//server.dumpGlobalRoutingTbl()
 	/*
 		if server.globalData.AreaBdrRtrStatus == true {
@@ -929,6 +926,7 @@ func (server *OSPFV2Server) InitRoutingTbl() {
 
 func (server *OSPFV2Server) DeinitRoutingTbl() {
 	server.RoutingTblData.GlobalRoutingTbl = nil
+	server.RoutingTblData.IntraAreaRoutingTbl = nil
 }
 
 func (server *OSPFV2Server) InitSPFStructs() {
@@ -950,7 +948,11 @@ func (server *OSPFV2Server) SPFRoutine() {
 		select {
 		case msg := <-server.MessagingChData.LsdbToSPFChData.StartSPF:
 			server.logger.Info("Recevd SPF Calculation Notification for:", msg)
-			server.SPFCalculation()
+			if msg.CalcType == SPF_CALC_PARTIAL && server.canRunPartialSPF() {
+				server.PartialSPFCalculation(msg.Trigger)
+			} else {
+				server.SPFCalculation(msg.Trigger)
+			}
 			server.SendMsgForSpfDone()
 		case <-server.SPFData.SPFGblCtrlCh:
 			server.FlushRoutingTbl()
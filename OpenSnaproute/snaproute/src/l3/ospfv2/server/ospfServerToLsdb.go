@@ -35,6 +35,46 @@ func (server *OSPFV2Server) SendMsgToLsdbToInitAreaLsdb(areaId uint32) {
 	server.MessagingChData.ServerToLsdbChData.InitAreaLsdbCh <- areaId
 }
 
+func (server *OSPFV2Server) SendMsgToLsdbForAreaRangeUpdate() {
+	if server.globalData.AdminState == false {
+		return
+	}
+	server.logger.Info("Sending msg to Lsdb for Area Range update")
+	server.MessagingChData.ServerToLsdbChData.AreaRangeUpdateCh <- true
+}
+
+func (server *OSPFV2Server) SendMsgToLsdbForVirtualLinkUpdate() {
+	if server.globalData.AdminState == false {
+		return
+	}
+	server.logger.Info("Sending msg to Lsdb for Virtual Link update")
+	server.MessagingChData.ServerToLsdbChData.VirtualLinkUpdateCh <- true
+}
+
+func (server *OSPFV2Server) SendMsgToLsdbForMaximumPathsUpdate() {
+	if server.globalData.AdminState == false {
+		return
+	}
+	server.logger.Info("Sending msg to Lsdb for Maximum Paths update")
+	server.MessagingChData.ServerToLsdbChData.MaximumPathsUpdateCh <- true
+}
+
+func (server *OSPFV2Server) SendMsgToLsdbForSpfThrottleUpdate(msg SpfThrottleMsg) {
+	if server.globalData.AdminState == false {
+		return
+	}
+	server.logger.Info("Sending msg to Lsdb for SPF throttle update:", msg)
+	server.MessagingChData.ServerToLsdbChData.SpfThrottleUpdateCh <- msg
+}
+
+func (server *OSPFV2Server) SendMsgToLsdbForStubRouterUpdate(msg StubRouterMsg) {
+	if server.globalData.AdminState == false {
+		return
+	}
+	server.logger.Info("Sending msg to Lsdb for Stub Router update:", msg)
+	server.MessagingChData.ServerToLsdbChData.StubRouterUpdateCh <- msg
+}
+
 func (server *OSPFV2Server) SendMsgToLsdbToUpdateRouteInfo(msg RouteInfoDataUpdateMsg) {
 	server.logger.Info("Sending msg to Lsdb for Updating RouteInfo:", msg)
 	server.MessagingChData.ServerToLsdbChData.RouteInfoDataUpdateCh <- msg
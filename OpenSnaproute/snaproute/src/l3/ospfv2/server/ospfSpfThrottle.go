@@ -0,0 +1,326 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"l3/ospfv2/objects"
+	"sync"
+	"time"
+)
+
+type SpfCalcType uint8
+
+const (
+	SPF_CALC_NONE    SpfCalcType = 0
+	SPF_CALC_PARTIAL SpfCalcType = 1 // Inter-area and external routes only
+	SPF_CALC_FULL    SpfCalcType = 2
+)
+
+const ospfv2GlobalSpfThrottleMask = objects.OSPFV2_GLOBAL_UPDATE_SPF_INITIAL_WAIT |
+	objects.OSPFV2_GLOBAL_UPDATE_SPF_HOLD_WAIT |
+	objects.OSPFV2_GLOBAL_UPDATE_SPF_MAX_WAIT
+
+type SpfCalcMsg struct {
+	CalcType SpfCalcType
+	Trigger  uint8
+}
+
+type SpfThrottleMsg struct {
+	InitialWait uint32
+	HoldWait    uint32
+	MaxWait     uint32
+}
+
+type SpfStats struct {
+	NumSpfRuns        uint32
+	NumPartialSpfRuns uint32
+	LastSpfDuration   uint32
+	LastSpfTrigger    uint8
+}
+
+type SpfThrottleStruct struct {
+	InitialWait time.Duration
+	HoldWait    time.Duration
+	MaxWait     time.Duration
+	NextWait    time.Duration
+	LastSpfTime time.Time
+	SpfTimer    *time.Timer
+	SpfTimerCh  <-chan time.Time // nil while no SPF is scheduled
+	PendingSpf  SpfCalcMsg
+
+	// Written by SPF routine, read by server routine
+	StatsMutex sync.RWMutex
+	AreaStats  map[uint32]SpfStats
+}
+
+func (server *OSPFV2Server) initSpfThrottleData() {
+	server.SpfThrottleData.AreaStats = make(map[uint32]SpfStats)
+}
+
+func (server *OSPFV2Server) updateGlobalSpfThrottle(newCfg *objects.Ospfv2Global, mask uint32) {
+	if mask&objects.OSPFV2_GLOBAL_UPDATE_SPF_INITIAL_WAIT == objects.OSPFV2_GLOBAL_UPDATE_SPF_INITIAL_WAIT {
+		server.globalData.SpfInitialWait = newCfg.SpfInitialWait
+	}
+	if mask&objects.OSPFV2_GLOBAL_UPDATE_SPF_HOLD_WAIT == objects.OSPFV2_GLOBAL_UPDATE_SPF_HOLD_WAIT {
+		server.globalData.SpfHoldWait = newCfg.SpfHoldWait
+	}
+	if mask&objects.OSPFV2_GLOBAL_UPDATE_SPF_MAX_WAIT == objects.OSPFV2_GLOBAL_UPDATE_SPF_MAX_WAIT {
+		server.globalData.SpfMaxWait = newCfg.SpfMaxWait
+	}
+	server.SendMsgToLsdbForSpfThrottleUpdate(SpfThrottleMsg{
+		InitialWait: server.globalData.SpfInitialWait,
+		HoldWait:    server.globalData.SpfHoldWait,
+		MaxWait:     server.globalData.SpfMaxWait,
+	})
+}
+
+/*
+@fn startSpfThrottle
+Called by Lsdb routine on start, no SPF is scheduled yet
+*/
+func (server *OSPFV2Server) startSpfThrottle(msg SpfThrottleMsg) {
+	throttle := &server.SpfThrottleData
+	throttle.LastSpfTime = time.Time{}
+	throttle.SpfTimer = nil
+	throttle.SpfTimerCh = nil
+	throttle.PendingSpf = SpfCalcMsg{}
+	server.processSpfThrottleUpdate(msg)
+}
+
+func (server *OSPFV2Server) stopSpfThrottle() {
+	throttle := &server.SpfThrottleData
+	if throttle.SpfTimer != nil {
+		throttle.SpfTimer.Stop()
+	}
+	throttle.SpfTimer = nil
+	throttle.SpfTimerCh = nil
+	throttle.PendingSpf = SpfCalcMsg{}
+}
+
+func (server *OSPFV2Server) processSpfThrottleUpdate(msg SpfThrottleMsg) {
+	throttle := &server.SpfThrottleData
+	throttle.InitialWait = time.Duration(msg.InitialWait) * time.Millisecond
+	throttle.HoldWait = time.Duration(msg.HoldWait) * time.Millisecond
+	throttle.MaxWait = time.Duration(msg.MaxWait) * time.Millisecond
+	if throttle.NextWait < throttle.HoldWait {
+		throttle.NextWait = throttle.HoldWait
+	}
+	if throttle.NextWait > throttle.MaxWait {
+		throttle.NextWait = throttle.MaxWait
+	}
+}
+
+/*
+@fn scheduleSPF
+Requests a route calculation. Requests received while a calculation
+is already scheduled are merged into it, a full calculation takes
+precedence over a partial one.
+*/
+func (server *OSPFV2Server) scheduleSPF(calcType SpfCalcType, trigger uint8) {
+	if calcType == SPF_CALC_NONE {
+		return
+	}
+	throttle := &server.SpfThrottleData
+	if calcType > throttle.PendingSpf.CalcType {
+		throttle.PendingSpf.CalcType = calcType
+		throttle.PendingSpf.Trigger = trigger
+	}
+	if throttle.SpfTimerCh != nil {
+		return
+	}
+	wait := server.getSpfWait()
+	server.logger.Debug("Scheduling SPF in", wait, "trigger:", trigger)
+	throttle.SpfTimer = time.NewTimer(wait)
+	throttle.SpfTimerCh = throttle.SpfTimer.C
+}
+
+/*
+@fn getSpfWait
+Exponential back-off: the first change after a quiet period of twice
+MaxWait is handled after InitialWait. Following calculations are kept
+HoldWait apart, HoldWait being doubled on every run up to MaxWait.
+*/
+func (server *OSPFV2Server) getSpfWait() time.Duration {
+	throttle := &server.SpfThrottleData
+	now := time.Now()
+	if throttle.LastSpfTime.IsZero() ||
+		now.Sub(throttle.LastSpfTime) > 2*throttle.MaxWait {
+		throttle.NextWait = throttle.HoldWait
+		return throttle.InitialWait
+	}
+	wait := throttle.NextWait - now.Sub(throttle.LastSpfTime)
+	throttle.NextWait = 2 * throttle.NextWait
+	if throttle.NextWait > throttle.MaxWait {
+		throttle.NextWait = throttle.MaxWait
+	}
+	if wait < throttle.InitialWait {
+		wait = throttle.InitialWait
+	}
+	return wait
+}
+
+func (server *OSPFV2Server) processSpfTimerExpiry() {
+	throttle := &server.SpfThrottleData
+	msg := throttle.PendingSpf
+	throttle.SpfTimer = nil
+	throttle.SpfTimerCh = nil
+	throttle.PendingSpf = SpfCalcMsg{}
+	server.CalcSPFAndRoutingTbl(msg)
+	throttle.LastSpfTime = time.Now()
+}
+
+func getSpfCalcTypeForLsa(lsType uint8) (SpfCalcType, uint8) {
+	switch lsType {
+	case RouterLSA:
+		return SPF_CALC_FULL, objects.SPF_TRIGGER_ROUTER_LSA
+	case NetworkLSA:
+		return SPF_CALC_FULL, objects.SPF_TRIGGER_NETWORK_LSA
+	case Summary3LSA, Summary4LSA:
+		return SPF_CALC_PARTIAL, objects.SPF_TRIGGER_SUMMARY_LSA
+	case ASExternalLSA:
+		return SPF_CALC_PARTIAL, objects.SPF_TRIGGER_AS_EXTERNAL_LSA
+	case NSSALSA:
+		return SPF_CALC_PARTIAL, objects.SPF_TRIGGER_NSSA_LSA
+	}
+	// Opaque LSAs do not contribute to the routing table
+	return SPF_CALC_NONE, objects.SPF_TRIGGER_NONE
+}
+
+/*
+@fn canRunPartialSPF
+Partial calculation reuses the intra-area routes of the last full
+calculation, which requires the same set of areas to be active.
+*/
+func (server *OSPFV2Server) canRunPartialSPF() bool {
+	if server.RoutingTblData.IntraAreaRoutingTbl == nil {
+		return false
+	}
+	numOfAreas := 0
+	for areaId, aEnt := range server.AreaConfMap {
+		if len(aEnt.IntfMap) == 0 || aEnt.AdminState == false {
+			continue
+		}
+		areaIdKey := AreaIdKey{
+			AreaId: areaId,
+		}
+		if _, exist := server.RoutingTblData.IntraAreaRoutingTbl[areaIdKey]; !exist {
+			return false
+		}
+		numOfAreas++
+	}
+	return numOfAreas == len(server.RoutingTblData.IntraAreaRoutingTbl)
+}
+
+func copyAreaRoutingTbl(areaRoutingTbl AreaRoutingTbl) AreaRoutingTbl {
+	var newTbl AreaRoutingTbl
+	newTbl.RoutingTblMap = make(map[RoutingTblEntryKey]RoutingTblEntry)
+	for rKey, rEnt := range areaRoutingTbl.RoutingTblMap {
+		nextHops := make(map[NextHop]bool)
+		for nextHop, val := range rEnt.NextHops {
+			nextHops[nextHop] = val
+		}
+		rEnt.NextHops = nextHops
+		newTbl.RoutingTblMap[rKey] = rEnt
+	}
+	return newTbl
+}
+
+/*
+@fn saveIntraAreaRoutingTbl
+Keeps the intra-area routes of the area (RFC 2328 16.1) for the
+following partial calculations
+*/
+func (server *OSPFV2Server) saveIntraAreaRoutingTbl(areaIdKey AreaIdKey) {
+	tempAreaRoutingTbl := server.RoutingTblData.TempAreaRoutingTbl[areaIdKey]
+	server.RoutingTblData.IntraAreaRoutingTbl[areaIdKey] = copyAreaRoutingTbl(tempAreaRoutingTbl)
+}
+
+/*
+@fn PartialSPFCalculation
+Only summary or AS external LSAs have changed, inter-area and
+AS external routes are recalculated (RFC 2328 16.5, 16.6) on top
+of the intra-area routes of the last full calculation.
+*/
+func (server *OSPFV2Server) PartialSPFCalculation(trigger uint8) {
+	server.RoutingTblData.OldGlobalRoutingTbl = server.RoutingTblData.GlobalRoutingTbl
+	server.RoutingTblData.TempAreaRoutingTbl = make(map[AreaIdKey]AreaRoutingTbl)
+	for areaIdKey, areaRoutingTbl := range server.RoutingTblData.IntraAreaRoutingTbl {
+		server.RoutingTblData.TempAreaRoutingTbl[areaIdKey] = copyAreaRoutingTbl(areaRoutingTbl)
+	}
+	// Virtual link endpoints only depend on intra-area routes
+	server.RoutingTblData.TempVirtualLinkEndpointMap = make(map[VirtualLinkKey]VirtualLinkEndpoint)
+	for vlKey, endpoint := range server.RoutingTblData.VirtualLinkEndpointMap {
+		server.RoutingTblData.TempVirtualLinkEndpointMap[vlKey] = endpoint
+	}
+	for areaIdKey, _ := range server.RoutingTblData.IntraAreaRoutingTbl {
+		startTime := time.Now()
+		server.HandleSummaryLsa(areaIdKey.AreaId)
+		server.updateAreaSpfStats(areaIdKey.AreaId, SPF_CALC_PARTIAL, trigger, startTime)
+	}
+	server.installSPFRoutingTbl()
+}
+
+/*
+@fn installSPFRoutingTbl
+Installs the routing table built by the calculation and releases
+the per area routing tables
+*/
+func (server *OSPFV2Server) installSPFRoutingTbl() {
+	server.RoutingTblData.TempGlobalRoutingTbl = make(map[RoutingTblEntryKey]GlobalRoutingTblEntry)
+	/* Summarize and Install/Delete Routes In Routing Table */
+	server.InstallRoutingTbl()
+	// Copy the Summarize Routing Table in Global Routing Table
+	server.RoutingTblData.GlobalRoutingTbl = server.RoutingTblData.TempGlobalRoutingTbl
+	server.dumpGlobalRoutingTbl()
+	server.RoutingTblData.TempAreaRoutingTbl = nil
+	server.RoutingTblData.OldGlobalRoutingTbl = nil
+	server.RoutingTblData.TempGlobalRoutingTbl = nil
+}
+
+func (server *OSPFV2Server) updateAreaSpfStats(areaId uint32, calcType SpfCalcType, trigger uint8, startTime time.Time) {
+	server.SpfThrottleData.StatsMutex.Lock()
+	stats := server.SpfThrottleData.AreaStats[areaId]
+	if calcType == SPF_CALC_FULL {
+		stats.NumSpfRuns++
+	} else {
+		stats.NumPartialSpfRuns++
+	}
+	stats.LastSpfDuration = uint32(time.Since(startTime) / time.Microsecond)
+	stats.LastSpfTrigger = trigger
+	server.SpfThrottleData.AreaStats[areaId] = stats
+	server.SpfThrottleData.StatsMutex.Unlock()
+}
+
+func (server *OSPFV2Server) deleteAreaSpfStats(areaId uint32) {
+	server.SpfThrottleData.StatsMutex.Lock()
+	delete(server.SpfThrottleData.AreaStats, areaId)
+	server.SpfThrottleData.StatsMutex.Unlock()
+}
+
+func (server *OSPFV2Server) getAreaSpfStats(areaId uint32) SpfStats {
+	server.SpfThrottleData.StatsMutex.RLock()
+	stats := server.SpfThrottleData.AreaStats[areaId]
+	server.SpfThrottleData.StatsMutex.RUnlock()
+	return stats
+}
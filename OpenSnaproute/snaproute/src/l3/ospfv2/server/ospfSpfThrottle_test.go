@@ -0,0 +1,255 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"encoding/json"
+	"l3/ospfv2/objects"
+	"l3/rib/ribdCommonDefs"
+	"ribdInt"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestSpfThrottleWait(t *testing.T) {
+	server := newTestServer("1.1.1.1")
+	server.startSpfThrottle(SpfThrottleMsg{
+		InitialWait: 50,
+		HoldWait:    200,
+		MaxWait:     1000,
+	})
+	throttle := &server.SpfThrottleData
+
+	// First change after a quiet period
+	if wait := server.getSpfWait(); wait != 50*time.Millisecond {
+		t.Error("Wrong initial wait", wait)
+	}
+	if throttle.NextWait != 200*time.Millisecond {
+		t.Error("Hold wait not armed", throttle.NextWait)
+	}
+
+	// Kept HoldWait apart from the last run, doubled on every run
+	throttle.LastSpfTime = time.Now()
+	wait := server.getSpfWait()
+	if wait <= 50*time.Millisecond || wait > 200*time.Millisecond {
+		t.Error("Wrong hold wait", wait)
+	}
+	expected := []time.Duration{400, 800, 1000, 1000}
+	for _, next := range expected {
+		if throttle.NextWait != next*time.Millisecond {
+			t.Fatal("Wrong back-off, expected", next, "got", throttle.NextWait)
+		}
+		throttle.LastSpfTime = time.Now()
+		server.getSpfWait()
+	}
+
+	// Never less than InitialWait
+	throttle.LastSpfTime = time.Now().Add(-1500 * time.Millisecond)
+	if wait := server.getSpfWait(); wait != 50*time.Millisecond {
+		t.Error("Wait below the initial wait", wait)
+	}
+	if throttle.NextWait != 1000*time.Millisecond {
+		t.Error("Back-off reset before a quiet period", throttle.NextWait)
+	}
+
+	// Back-off is reset after twice MaxWait without a change
+	throttle.LastSpfTime = time.Now().Add(-2500 * time.Millisecond)
+	if wait := server.getSpfWait(); wait != 50*time.Millisecond {
+		t.Error("Wrong wait after a quiet period", wait)
+	}
+	if throttle.NextWait != 200*time.Millisecond {
+		t.Error("Back-off not reset after a quiet period", throttle.NextWait)
+	}
+
+	// Lowering MaxWait clamps the current back-off
+	throttle.NextWait = 1000 * time.Millisecond
+	server.processSpfThrottleUpdate(SpfThrottleMsg{
+		InitialWait: 50,
+		HoldWait:    200,
+		MaxWait:     500,
+	})
+	if throttle.NextWait != 500*time.Millisecond {
+		t.Error("Back-off not clamped to the max wait", throttle.NextWait)
+	}
+}
+
+func TestScheduleSpfMerge(t *testing.T) {
+	server := newTestServer("1.1.1.1")
+	server.startSpfThrottle(SpfThrottleMsg{
+		InitialWait: 50,
+		HoldWait:    200,
+		MaxWait:     1000,
+	})
+	throttle := &server.SpfThrottleData
+	defer server.stopSpfThrottle()
+
+	server.scheduleSPF(SPF_CALC_NONE, objects.SPF_TRIGGER_NONE)
+	if throttle.SpfTimerCh != nil {
+		t.Fatal("SPF scheduled for an LSA not used in the calculation")
+	}
+	server.scheduleSPF(SPF_CALC_PARTIAL, objects.SPF_TRIGGER_AS_EXTERNAL_LSA)
+	timerCh := throttle.SpfTimerCh
+	if timerCh == nil || throttle.PendingSpf.CalcType != SPF_CALC_PARTIAL {
+		t.Fatal("Partial SPF not scheduled", throttle.PendingSpf)
+	}
+	server.scheduleSPF(SPF_CALC_FULL, objects.SPF_TRIGGER_ROUTER_LSA)
+	server.scheduleSPF(SPF_CALC_PARTIAL, objects.SPF_TRIGGER_SUMMARY_LSA)
+	if throttle.SpfTimerCh != timerCh {
+		t.Error("SPF rescheduled while pending")
+	}
+	if throttle.PendingSpf.CalcType != SPF_CALC_FULL ||
+		throttle.PendingSpf.Trigger != objects.SPF_TRIGGER_ROUTER_LSA {
+		t.Error("Full SPF not merged into the pending one", throttle.PendingSpf)
+	}
+
+	server.stopSpfThrottle()
+	if throttle.SpfTimerCh != nil || throttle.PendingSpf.CalcType != SPF_CALC_NONE {
+		t.Error("SPF still pending after stop", throttle.PendingSpf)
+	}
+}
+
+func TestSpfCalcTypeForLsa(t *testing.T) {
+	tests := []struct {
+		lsType   uint8
+		calcType SpfCalcType
+	}{
+		{RouterLSA, SPF_CALC_FULL},
+		{NetworkLSA, SPF_CALC_FULL},
+		{Summary3LSA, SPF_CALC_PARTIAL},
+		{Summary4LSA, SPF_CALC_PARTIAL},
+		{ASExternalLSA, SPF_CALC_PARTIAL},
+		{NSSALSA, SPF_CALC_PARTIAL},
+		{OpaqueLinkLSA, SPF_CALC_NONE},
+	}
+	for _, test := range tests {
+		if calcType, _ := getSpfCalcTypeForLsa(test.lsType); calcType != test.calcType {
+			t.Error("Wrong calculation for LSA type", test.lsType, calcType)
+		}
+	}
+}
+
+// addRibRoute publishes a route on the router as ribd would
+func (rtr *testRouter) addRibRoute(t *testing.T, network string, maskLen int, protocol string) {
+	mask := convertUint32ToDotNotation(uint32(0xffffffff) << uint(32-maskLen))
+	msgBuf, err := json.Marshal(ribdCommonDefs.RoutelistInfo{
+		RouteInfo: ribdInt.Routes{
+			Ipaddr:          network,
+			Mask:            mask,
+			RoutingProtocol: protocol,
+			Metric:          1,
+		},
+	})
+	if err != nil {
+		t.Fatal("Unable to marshal ribd route", err)
+	}
+	buf, err := json.Marshal(ribdCommonDefs.RibdNotifyMsg{
+		MsgType: ribdCommonDefs.NOTIFY_ROUTE_CREATED,
+		MsgBuf:  msgBuf,
+	})
+	if err != nil {
+		t.Fatal("Unable to marshal ribd notification", err)
+	}
+	rtr.server.ribdComm.ribdSubSocketCh <- buf
+}
+
+/*
+External routes of an ASBR only trigger partial calculations on
+the other routers. Changes arriving while a calculation is
+scheduled are handled by that calculation.
+*/
+func TestPartialSpf(t *testing.T) {
+	lan := NewVirtualWire()
+	defer lan.SetLinkUp(false)
+	rtrA := newTestRouter(t, "1.1.1.1")
+	defer rtrA.close(t)
+	rtrB := newTestRouter(t, "2.2.2.2")
+	defer rtrB.close(t)
+	rtrB.updateGlobal(t, &objects.Ospfv2Global{ASBdrRtrStatus: true}, 3)
+	rtrA.addIntf(t, "eth0", "10.0.0.1", 24, 1, lan)
+	waitFor(t, "A to become DR", func() bool {
+		return rtrA.isIntfState("10.0.0.1", objects.INTF_FSM_STATE_DR)
+	})
+	rtrB.addIntf(t, "eth0", "10.0.0.2", 24, 1, lan)
+	rtrB.addIntf(t, "eth1", "20.0.0.2", 24, 1, NewVirtualWire())
+	waitForAdjacency(t, rtrA, "10.0.0.1", rtrB, "10.0.0.2")
+	// B's router LSA changes once more when it leaves the waiting state
+	waitFor(t, "B to become BDR", func() bool {
+		return rtrB.isIntfState("10.0.0.2", objects.INTF_FSM_STATE_BDR)
+	})
+	waitFor(t, "route to 20.0.0.0/24 on A", func() bool {
+		_, exist := rtrA.getRoute("20.0.0.0", 24)
+		return exist
+	})
+
+	rtrA.updateGlobal(t, &objects.Ospfv2Global{SpfInitialWait: 1000}, 10)
+	rtrA.updateGlobal(t, &objects.Ospfv2Global{SpfHoldWait: 1000}, 11)
+	// A router LSA refresh in the window merges the external LSAs
+	// into a full calculation, try again with new routes
+	for attempt := 1; attempt <= 3; attempt++ {
+		before := rtrA.getAreaState(0)
+		if before == nil || before.NumSpfRuns == 0 {
+			t.Fatal("No full SPF run on A", before)
+		}
+		startTime := time.Now()
+		var networks []string
+		for idx := 1; idx <= 3; idx++ {
+			network := "40." + strconv.Itoa(attempt) + "." + strconv.Itoa(idx) + ".0"
+			networks = append(networks, network)
+			rtrB.addRibRoute(t, network, 24, objects.REDIST_PROTOCOL_STATIC)
+		}
+		waitFor(t, "external routes on A", func() bool {
+			for _, network := range networks {
+				if _, exist := rtrA.getRoute(network, 24); !exist {
+					return false
+				}
+			}
+			return true
+		})
+		if elapsed := time.Since(startTime); elapsed < time.Second {
+			t.Error("SPF ran before the initial wait", elapsed)
+		}
+		rEnt, _ := rtrA.getRoute(networks[0], 24)
+		if rEnt.RoutingTblEnt.PathType != Type2Ext {
+			t.Error("Wrong path type for", networks[0], rEnt.RoutingTblEnt.PathType)
+		}
+
+		after := rtrA.getAreaState(0)
+		if after == nil {
+			t.Fatal("No area state on A")
+		}
+		if after.NumSpfRuns != before.NumSpfRuns {
+			continue
+		}
+		if after.NumPartialSpfRuns != before.NumPartialSpfRuns+1 {
+			t.Error("External LSAs not handled by a single partial SPF",
+				before.NumPartialSpfRuns, after.NumPartialSpfRuns)
+		}
+		if after.LastSpfTrigger != objects.SPF_TRIGGER_AS_EXTERNAL_LSA {
+			t.Error("Wrong SPF trigger", after.LastSpfTrigger)
+		}
+		return
+	}
+	t.Error("Full SPF run for external LSAs")
+}
@@ -0,0 +1,188 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"errors"
+	"l3/ospfv2/objects"
+	"net"
+	"time"
+)
+
+type StaticNbrConf struct {
+	RtrPriority uint8
+}
+
+func genOspfv2NbrUpdateMask(attrset []bool) uint32 {
+	var mask uint32 = 0
+
+	if attrset == nil {
+		mask = objects.OSPFV2_NBR_UPDATE_RTR_PRIORITY
+	} else {
+		for idx, val := range attrset {
+			if val == true {
+				switch idx {
+				case 0:
+					//IpAddr
+				case 1:
+					//AddressLessIfIdx
+				case 2:
+					mask |= objects.OSPFV2_NBR_UPDATE_RTR_PRIORITY
+				}
+			}
+		}
+	}
+	return mask
+}
+
+func (server *OSPFV2Server) createStaticNbr(cfg *objects.Ospfv2Nbr) (bool, error) {
+	server.logger.Info("Static nbr configuration create")
+	nbrKey := NbrConfKey{
+		NbrIdentity:         cfg.IpAddr,
+		NbrAddressLessIfIdx: cfg.AddressLessIfIdx,
+	}
+	_, exist := server.StaticNbrConfMap[nbrKey]
+	if exist {
+		server.logger.Err("Unable to create static nbr already exist")
+		return false, errors.New("Unable to create static nbr already exist")
+	}
+	server.StaticNbrConfMap[nbrKey] = StaticNbrConf{
+		RtrPriority: cfg.RtrPriority,
+	}
+	server.logger.Info("Successfully created ospfv2Nbr config")
+	return true, nil
+}
+
+func (server *OSPFV2Server) updateStaticNbr(newCfg, oldCfg *objects.Ospfv2Nbr, attrset []bool) (bool, error) {
+	server.logger.Info("Static nbr configuration update")
+	nbrKey := NbrConfKey{
+		NbrIdentity:         newCfg.IpAddr,
+		NbrAddressLessIfIdx: newCfg.AddressLessIfIdx,
+	}
+	nbrEnt, exist := server.StaticNbrConfMap[nbrKey]
+	if !exist {
+		server.logger.Err("Cannot update, static nbr doesnot exist")
+		return false, errors.New("Cannot update, static nbr doesnot exist")
+	}
+	mask := genOspfv2NbrUpdateMask(attrset)
+	if mask&objects.OSPFV2_NBR_UPDATE_RTR_PRIORITY == objects.OSPFV2_NBR_UPDATE_RTR_PRIORITY {
+		nbrEnt.RtrPriority = newCfg.RtrPriority
+	}
+	server.StaticNbrConfMap[nbrKey] = nbrEnt
+	return true, nil
+}
+
+func (server *OSPFV2Server) deleteStaticNbr(cfg *objects.Ospfv2Nbr) (bool, error) {
+	server.logger.Info("Static nbr configuration delete")
+	nbrKey := NbrConfKey{
+		NbrIdentity:         cfg.IpAddr,
+		NbrAddressLessIfIdx: cfg.AddressLessIfIdx,
+	}
+	_, exist := server.StaticNbrConfMap[nbrKey]
+	if !exist {
+		server.logger.Err("Unable to delete static nbr doesnot exist")
+		return false, errors.New("Unable to delete static nbr doesnot exist")
+	}
+	// Adjacency (if any) goes down once the nbr stops hearing our hellos
+	delete(server.StaticNbrConfMap, nbrKey)
+	server.delArpCacheEntry(nbrKey.NbrIdentity)
+	return true, nil
+}
+
+/*
+@fn isIntfStaticNbr
+Static nbrs of a numbered interface are the ones in its
+subnet, those of an unnumbered one share its ifIndex.
+*/
+func isIntfStaticNbr(key IntfConfKey, ent IntfConf, nbrKey NbrConfKey) bool {
+	if nbrKey.NbrAddressLessIfIdx != key.IntfIdx {
+		return false
+	}
+	return key.IntfIdx != 0 ||
+		(nbrKey.NbrIdentity&ent.Netmask) == (ent.IpAddr&ent.Netmask)
+}
+
+func (server *OSPFV2Server) getIntfStaticNbrs(key IntfConfKey, ent IntfConf) map[NbrConfKey]StaticNbrConf {
+	nbrs := make(map[NbrConfKey]StaticNbrConf)
+	for nbrKey, nbrEnt := range server.StaticNbrConfMap {
+		if isIntfStaticNbr(key, ent, nbrKey) {
+			nbrs[nbrKey] = nbrEnt
+		}
+	}
+	return nbrs
+}
+
+/*
+@fn sendStaticNbrHelloPkts
+NBMA and point-to-multipoint networks have no multicast, hellos
+are unicast to each configured nbr. On NBMA (RFC 2328 9.5.1),
+a router eligible to become DR only talks to the other eligible
+nbrs and to the DR/BDR, a non eligible one only to the DR/BDR,
+and nbrs which are down are polled every PollInterval. Nothing
+is sent to a nbr until its MAC address is resolved.
+*/
+func (server *OSPFV2Server) sendStaticNbrHelloPkts(key IntfConfKey) {
+	ent, exist := server.IntfConfMap[key]
+	if !exist {
+		server.logger.Err("Interface doesnot exist", key)
+		return
+	}
+	rtrId := server.globalData.RouterId
+	isDROrBDR := ent.DRtrId == rtrId || ent.BDRtrId == rtrId
+	pollInterval := time.Duration(ent.PollInterval) * time.Second
+	for nbrKey, staticNbr := range server.getIntfStaticNbrs(key, ent) {
+		nbrIp := nbrKey.NbrIdentity
+		if ent.Type == objects.INTF_TYPE_NBMA && !isDROrBDR &&
+			nbrIp != ent.DRIpAddr && nbrIp != ent.BDRIpAddr &&
+			(ent.RtrPriority == 0 || staticNbr.RtrPriority == 0) {
+			continue
+		}
+		nbrConf, exist := server.NbrConfMap[nbrKey]
+		polling := ent.Type == objects.INTF_TYPE_NBMA &&
+			(!exist || nbrConf.State <= NbrDown)
+		if polling {
+			lastPoll, polled := ent.NbrPollTime[nbrIp]
+			if polled && time.Since(lastPoll) < pollInterval {
+				continue
+			}
+		}
+		dstMac, resolved := server.resolveNbrMac(key, nbrKey)
+		if !resolved {
+			continue
+		}
+		if polling {
+			ent.NbrPollTime[nbrIp] = time.Now()
+		}
+		dstIp := net.ParseIP(convertUint32ToDotNotation(nbrIp))
+		ospfHelloPkt := server.BuildHelloPkt(key, dstIp, dstMac)
+		if ospfHelloPkt == nil {
+			server.logger.Err("Unable to send the ospf Hello pkt to", dstIp)
+			continue
+		}
+		err := server.SendOspfPkt(key, ospfHelloPkt)
+		if err != nil {
+			server.logger.Err("Unable to send the ospf Hello pkt to", dstIp)
+		}
+	}
+}
@@ -0,0 +1,303 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"l3/ospfv2/objects"
+	"net"
+	"testing"
+)
+
+func addTestP2MPNbr(server *OSPFV2Server, intfKey IntfConfKey, nbrIp, rtrId string, state NbrState) NbrConfKey {
+	nbrKey := NbrConfKey{
+		NbrIdentity: testIp(nbrIp),
+	}
+	intfEnt := server.IntfConfMap[intfKey]
+	intfEnt.NbrMap[nbrKey] = NbrData{
+		TwoWayStatus: true,
+		NbrIpAddr:    testIp(nbrIp),
+		RtrId:        testIp(rtrId),
+	}
+	server.IntfConfMap[intfKey] = intfEnt
+	server.NbrConfMap[nbrKey] = NbrConf{
+		IntfKey:  intfKey,
+		State:    state,
+		NbrIP:    testIp(nbrIp),
+		NbrRtrId: testIp(rtrId),
+	}
+	return nbrKey
+}
+
+/*
+Connects the interface to a virtual wire and returns the
+nbr end of it, opened with nbrParams
+*/
+func openTestNbrWire(t *testing.T, server *OSPFV2Server, intfKey IntfConfKey, nbrParams PktIOParams) (*VirtualPktIO, PktRxHandle) {
+	wire := NewVirtualWire()
+	pktIO := NewVirtualPktIO()
+	pktIO.Connect("eth0", wire)
+	intfEnt := server.IntfConfMap[intfKey]
+	intfEnt.IfName = "eth0"
+	txHdl, err := pktIO.OpenTx(getPktIOParams(intfEnt))
+	if err != nil {
+		t.Fatal("Unable to open tx handle", err)
+	}
+	intfEnt.txHdl.SendHdl = txHdl
+	server.IntfConfMap[intfKey] = intfEnt
+	nbrParams.IfName = "eth0"
+	nbrRxHdl, err := pktIO.OpenRx(nbrParams)
+	if err != nil {
+		t.Fatal("Unable to open nbr rx handle", err)
+	}
+	return pktIO, nbrRxHdl
+}
+
+func TestP2MPRouterLsaFullNbrs(t *testing.T) {
+	server := newTestServer("1.1.1.1")
+	addTestArea(server, 1, AreaConf{ImportASExtern: true})
+	intfKey := addTestIntf(server, 1, "10.0.0.1", objects.INTF_TYPE_POINT2MULTIPOINT, objects.INTF_FSM_STATE_P2P)
+	addTestP2MPNbr(server, intfKey, "10.0.0.2", "2.2.2.2", NbrFull)
+	addTestP2MPNbr(server, intfKey, "10.0.0.3", "3.3.3.3", NbrExchange)
+
+	linkDetails := server.GetLinkDetails(1, server.AreaConfMap[1])
+	var p2pLinks []LinkDetail
+	hostRoute := false
+	for _, link := range linkDetails {
+		switch link.LinkType {
+		case P2P_LINK:
+			p2pLinks = append(p2pLinks, link)
+		case STUB_LINK:
+			hostRoute = link.LinkId == testIp("10.0.0.1") &&
+				link.LinkData == 0xffffffff
+		}
+	}
+	if !hostRoute {
+		t.Error("Point-to-multipoint host route missing", linkDetails)
+	}
+	if len(p2pLinks) != 1 ||
+		p2pLinks[0].LinkId != testIp("2.2.2.2") ||
+		p2pLinks[0].LinkData != testIp("10.0.0.1") {
+		t.Error("Only the full nbr should be advertised", p2pLinks)
+	}
+}
+
+func TestP2MPRouterLsaOnNbrFull(t *testing.T) {
+	server := newTestServer("1.1.1.1")
+	addTestArea(server, 1, AreaConf{ImportASExtern: true})
+	intfKey := addTestIntf(server, 1, "10.0.0.1", objects.INTF_TYPE_POINT2MULTIPOINT, objects.INTF_FSM_STATE_P2P)
+	nbrKey := addTestP2MPNbr(server, intfKey, "10.0.0.2", "2.2.2.2", NbrLoading)
+	genCh := server.MessagingChData.IntfFSMToLsdbChData.GenerateRouterLSACh
+
+	nbrConf := server.NbrConfMap[nbrKey]
+	nbrConf.State = NbrFull
+	server.ProcessNbrUpdate(nbrKey, nbrConf)
+	if len(genCh) != 1 {
+		t.Fatal("Router LSA not regenerated when the nbr became full")
+	}
+	msg := <-genCh
+	if msg.AreaId != 1 {
+		t.Error("Router LSA regenerated for the wrong area", msg)
+	}
+	server.ProcessNbrUpdate(nbrKey, nbrConf)
+	if len(genCh) != 0 {
+		t.Error("Router LSA regenerated without a state change")
+	}
+	nbrConf.State = NbrExchange
+	server.ProcessNbrUpdate(nbrKey, nbrConf)
+	if len(genCh) != 1 {
+		t.Error("Router LSA not regenerated when the nbr left full")
+	}
+}
+
+func readTestPacket(t *testing.T, rxHdl PktRxHandle) (gopacket.Packet, bool) {
+	data, ok := readTestFrame(rxHdl)
+	if !ok {
+		return nil, false
+	}
+	return gopacket.NewPacket(data, layers.LayerTypeEthernet, gopacket.Default), true
+}
+
+func TestNbmaHelloMacResolution(t *testing.T) {
+	server := newTestServer("1.1.1.1")
+	addTestArea(server, 1, AreaConf{ImportASExtern: true})
+	intfKey := addTestIntf(server, 1, "10.0.0.1", objects.INTF_TYPE_NBMA, objects.INTF_FSM_STATE_P2P)
+	nbrMac := net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x02}
+	pktIO, nbrRxHdl := openTestNbrWire(t, server, intfKey, PktIOParams{
+		IpAddr:    testIp("10.0.0.2"),
+		IfMacAddr: nbrMac,
+		IntfType:  objects.INTF_TYPE_NBMA,
+		AreaId:    1,
+	})
+	defer nbrRxHdl.Close()
+	rxHdl, err := pktIO.OpenRx(getPktIOParams(server.IntfConfMap[intfKey]))
+	if err != nil {
+		t.Fatal("Unable to open rx handle", err)
+	}
+	defer rxHdl.Close()
+	server.StaticNbrConfMap[NbrConfKey{NbrIdentity: testIp("10.0.0.2")}] = StaticNbrConf{
+		RtrPriority: 1,
+	}
+
+	// Unknown MAC, the nbr is resolved first
+	server.sendStaticNbrHelloPkts(intfKey)
+	pkt, ok := readTestPacket(t, nbrRxHdl)
+	if !ok {
+		t.Fatal("Nothing sent to the nbr")
+	}
+	arpLayer := pkt.Layer(layers.LayerTypeARP)
+	if arpLayer == nil || arpLayer.(*layers.ARP).Operation != layers.ARPRequest {
+		t.Fatal("Expected an arp request, got", pkt)
+	}
+	if _, ok = readTestFrame(nbrRxHdl); ok {
+		t.Error("Hello sent before the nbr MAC is resolved")
+	}
+
+	pkt, ok = readTestPacket(t, rxHdl)
+	if !ok {
+		t.Fatal("No arp reply")
+	}
+	arpLayer = pkt.Layer(layers.LayerTypeARP)
+	if arpLayer == nil {
+		t.Fatal("Expected an arp reply, got", pkt)
+	}
+	server.processRxArpPkt(intfKey, arpLayer.(*layers.ARP))
+	mac, exist := server.getArpCacheMac(intfKey, testIp("10.0.0.2"))
+	if !exist || mac.String() != nbrMac.String() {
+		t.Fatal("Nbr MAC not learnt from the arp reply", mac)
+	}
+
+	server.sendStaticNbrHelloPkts(intfKey)
+	pkt, ok = readTestPacket(t, nbrRxHdl)
+	if !ok {
+		t.Fatal("Hello not sent once the nbr MAC is resolved")
+	}
+	ethLayer := pkt.Layer(layers.LayerTypeEthernet)
+	ipLayer := pkt.Layer(layers.LayerTypeIPv4)
+	if ethLayer == nil || ipLayer == nil {
+		t.Fatal("Expected a hello, got", pkt)
+	}
+	if ethLayer.(*layers.Ethernet).DstMAC.String() != nbrMac.String() {
+		t.Error("Hello not sent to the nbr MAC", ethLayer.(*layers.Ethernet).DstMAC)
+	}
+	if ipLayer.(*layers.IPv4).DstIP.String() != "10.0.0.2" {
+		t.Error("Hello not unicast to the nbr", ipLayer.(*layers.IPv4).DstIP)
+	}
+
+	// Cache is flushed with the interface
+	server.flushArpCache(intfKey)
+	if _, exist = server.getArpCacheMac(intfKey, testIp("10.0.0.2")); exist {
+		t.Error("Arp cache not flushed")
+	}
+}
+
+func makeTestArpReply(srcIp, dstIp string, srcMac net.HardwareAddr) *layers.ARP {
+	return &layers.ARP{
+		Operation:         layers.ARPReply,
+		SourceHwAddress:   []byte(srcMac),
+		SourceProtAddress: net.ParseIP(srcIp).To4(),
+		DstHwAddress:      make([]byte, ARP_HW_ADDR_LEN),
+		DstProtAddress:    net.ParseIP(dstIp).To4(),
+	}
+}
+
+func TestArpReplyValidation(t *testing.T) {
+	server := newTestServer("1.1.1.1")
+	addTestArea(server, 1, AreaConf{ImportASExtern: true})
+	intfKey := addTestIntf(server, 1, "10.0.0.1", objects.INTF_TYPE_NBMA, objects.INTF_FSM_STATE_P2P)
+	otherIntfKey := addTestIntf(server, 1, "20.0.0.1", objects.INTF_TYPE_NBMA, objects.INTF_FSM_STATE_P2P)
+	nbrMac := net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x02}
+	_, nbrRxHdl := openTestNbrWire(t, server, intfKey, PktIOParams{
+		IpAddr:    testIp("10.0.0.2"),
+		IfMacAddr: nbrMac,
+		IntfType:  objects.INTF_TYPE_NBMA,
+		AreaId:    1,
+	})
+	defer nbrRxHdl.Close()
+	nbrKey := NbrConfKey{NbrIdentity: testIp("10.0.0.2")}
+	server.StaticNbrConfMap[nbrKey] = StaticNbrConf{RtrPriority: 1}
+	reply := makeTestArpReply("10.0.0.2", "10.0.0.1", nbrMac)
+
+	// Unsolicited replies are not learnt
+	server.processRxArpPkt(intfKey, reply)
+	server.processRxArpPkt(intfKey, makeTestArpReply("10.0.0.3", "10.0.0.1", nbrMac))
+	if _, exist := server.getArpCacheMac(intfKey, testIp("10.0.0.2")); exist {
+		t.Fatal("Unsolicited arp reply learnt")
+	}
+	if _, exist := server.getArpCacheMac(intfKey, testIp("10.0.0.3")); exist {
+		t.Fatal("Arp reply from an unknown nbr learnt")
+	}
+
+	// Nothing is requested for addresses which are not static nbrs
+	server.resolveNbrMac(intfKey, NbrConfKey{NbrIdentity: testIp("10.0.0.4")})
+	server.resolveNbrMac(otherIntfKey, nbrKey)
+	if _, ok := readTestFrame(nbrRxHdl); ok {
+		t.Fatal("Arp request sent for an address which is not a static nbr of the intf")
+	}
+
+	if _, resolved := server.resolveNbrMac(intfKey, nbrKey); resolved {
+		t.Fatal("Nbr resolved without an arp reply")
+	}
+	if _, ok := readTestFrame(nbrRxHdl); !ok {
+		t.Fatal("Arp request not sent to the static nbr")
+	}
+	server.processRxArpPkt(otherIntfKey, reply)
+	server.processRxArpPkt(intfKey, makeTestArpReply("10.0.0.2", "10.0.0.9", nbrMac))
+	if _, exist := server.getArpCacheMac(intfKey, testIp("10.0.0.2")); exist {
+		t.Fatal("Arp reply learnt on another intf or for another address")
+	}
+	server.processRxArpPkt(intfKey, reply)
+	mac, resolved := server.resolveNbrMac(intfKey, nbrKey)
+	if !resolved || mac.String() != nbrMac.String() {
+		t.Fatal("Solicited arp reply not learnt", mac)
+	}
+	if _, exist := server.getArpCacheMac(otherIntfKey, testIp("10.0.0.2")); exist {
+		t.Error("Arp entry used on another intf")
+	}
+
+	// Entries age out and the nbr is resolved again
+	server.ArpCacheData.Lock()
+	arpEnt := server.ArpCacheData.MacMap[testIp("10.0.0.2")]
+	arpEnt.LearnTime = arpEnt.LearnTime.Add(-ARP_CACHE_TIMEOUT)
+	server.ArpCacheData.MacMap[testIp("10.0.0.2")] = arpEnt
+	server.ArpCacheData.Unlock()
+	if _, resolved = server.resolveNbrMac(intfKey, nbrKey); resolved {
+		t.Fatal("Aged arp entry used")
+	}
+	if _, ok := readTestFrame(nbrRxHdl); !ok {
+		t.Fatal("Arp request not sent once the entry aged out")
+	}
+
+	// Late replies are not learnt
+	server.ArpCacheData.Lock()
+	reqEnt := server.ArpCacheData.ReqMap[testIp("10.0.0.2")]
+	reqEnt.ReqTime = reqEnt.ReqTime.Add(-ARP_REQ_TIMEOUT)
+	server.ArpCacheData.ReqMap[testIp("10.0.0.2")] = reqEnt
+	server.ArpCacheData.Unlock()
+	server.processRxArpPkt(intfKey, reply)
+	if _, exist := server.getArpCacheMac(intfKey, testIp("10.0.0.2")); exist {
+		t.Error("Arp reply to a timed out request learnt")
+	}
+}
@@ -0,0 +1,285 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"l3/ospfv2/objects"
+	"time"
+)
+
+const (
+	// RFC 6987, largest router LSA link metric
+	MaxLinkMetric uint16 = 0xffff
+	// Summary and external LSAs with the maximum metric are still
+	// usable, LSInfinity would withdraw the routes
+	MaxLsaMetric uint32 = 0xff0000
+)
+
+const (
+	STUB_ROUTER_BGP_QUIET_PERIOD time.Duration = 10 * time.Second
+	STUB_ROUTER_BGP_MAX_WAIT     uint32        = 600
+)
+
+const ospfv2GlobalStubRouterMask = objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_ADMIN |
+	objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_STARTUP |
+	objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_BGP |
+	objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_SUMMARY |
+	objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_EXT
+
+type StubRouterMsg struct {
+	RouterLsaMaxMetric   bool
+	SummaryLsaMaxMetric  bool
+	ExternalLsaMaxMetric bool
+}
+
+type StubRouterStruct struct {
+	OnStartup     bool
+	StartupExpiry time.Time
+	StartupTimer  *time.Timer
+	StartupTimeCh chan bool
+	LastBgpUpdate time.Time
+	// Metrics currently advertised
+	AdvState StubRouterMsg
+	// Copy of AdvState owned by Lsdb routine
+	LsdbState StubRouterMsg
+}
+
+func (server *OSPFV2Server) initStubRouterData() {
+	server.StubRouterData.StartupTimeCh = make(chan bool, 1)
+}
+
+func (server *OSPFV2Server) updateGlobalStubRouter(cfg *objects.Ospfv2Global, mask uint32) {
+	if mask&objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_ADMIN == objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_ADMIN {
+		server.globalData.StubRouterAdmin = cfg.StubRouterAdmin
+	}
+	// On startup parameters take effect the next time OSPF is enabled
+	if mask&objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_STARTUP == objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_STARTUP {
+		server.globalData.StubRouterOnStartup = cfg.StubRouterOnStartup
+	}
+	if mask&objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_BGP == objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_BGP {
+		server.globalData.StubRouterWaitForBgp = cfg.StubRouterWaitForBgp
+	}
+	if mask&objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_SUMMARY == objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_SUMMARY {
+		server.globalData.StubRouterSummaryLsa = cfg.StubRouterSummaryLsa
+	}
+	if mask&objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_EXT == objects.OSPFV2_GLOBAL_UPDATE_STUB_ROUTER_EXT {
+		server.globalData.StubRouterExternalLsa = cfg.StubRouterExternalLsa
+	}
+}
+
+func (server *OSPFV2Server) isStubRouter() bool {
+	return server.globalData.StubRouterAdmin || server.StubRouterData.OnStartup
+}
+
+func (server *OSPFV2Server) getStubRouterMsg() StubRouterMsg {
+	stubRouter := server.isStubRouter()
+	return StubRouterMsg{
+		RouterLsaMaxMetric:   stubRouter,
+		SummaryLsaMaxMetric:  stubRouter && server.globalData.StubRouterSummaryLsa,
+		ExternalLsaMaxMetric: stubRouter && server.globalData.StubRouterExternalLsa,
+	}
+}
+
+func (server *OSPFV2Server) getStubRouterState() (uint8, uint32) {
+	if server.globalData.StubRouterAdmin {
+		return objects.STUB_ROUTER_STATUS_ADMINISTRATIVE, 0
+	}
+	if !server.StubRouterData.OnStartup {
+		return objects.STUB_ROUTER_STATUS_NONE, 0
+	}
+	var timeLeft uint32
+	remaining := server.StubRouterData.StartupExpiry.Sub(time.Now())
+	if remaining > 0 {
+		timeLeft = uint32(remaining / time.Second)
+	}
+	return objects.STUB_ROUTER_STATUS_ON_STARTUP, timeLeft
+}
+
+/*
+Called before the Lsdb routine is started when OSPF gets enabled,
+the LSAs are originated with the maximum metric right away
+*/
+func (server *OSPFV2Server) startStubRouterOnStartup() {
+	duration := server.globalData.StubRouterOnStartup
+	if duration == 0 && server.globalData.StubRouterWaitForBgp {
+		duration = STUB_ROUTER_BGP_MAX_WAIT
+	}
+	if duration == 0 {
+		return
+	}
+	stubRouter := &server.StubRouterData
+	now := time.Now()
+	stubRouter.OnStartup = true
+	stubRouter.StartupExpiry = now.Add(time.Duration(duration) * time.Second)
+	stubRouter.LastBgpUpdate = now
+	server.armStubRouterStartupTimer(now)
+	server.logger.Info("Stub router on startup for", duration, "seconds")
+}
+
+func (server *OSPFV2Server) stopStubRouterOnStartup() {
+	stubRouter := &server.StubRouterData
+	if stubRouter.StartupTimer != nil {
+		stubRouter.StartupTimer.Stop()
+	}
+	stubRouter.OnStartup = false
+}
+
+/*
+A single timer is used for the life time of the server so that a
+stale expiry can never start a second one. While waiting for BGP it
+fires every quiet period to check whether BGP has converged.
+*/
+func (server *OSPFV2Server) armStubRouterStartupTimer(now time.Time) {
+	stubRouter := &server.StubRouterData
+	wait := stubRouter.StartupExpiry.Sub(now)
+	if server.globalData.StubRouterWaitForBgp && wait > STUB_ROUTER_BGP_QUIET_PERIOD {
+		wait = STUB_ROUTER_BGP_QUIET_PERIOD
+	}
+	if stubRouter.StartupTimer == nil {
+		stubRouter.StartupTimer = time.AfterFunc(wait, func() {
+			select {
+			case stubRouter.StartupTimeCh <- true:
+			default:
+			}
+		})
+		return
+	}
+	stubRouter.StartupTimer.Reset(wait)
+}
+
+/*
+BGP is considered converged once it has routes in ribd and none of
+them changed during the quiet period
+*/
+func (server *OSPFV2Server) isBgpConverged(now time.Time) bool {
+	if now.Sub(server.StubRouterData.LastBgpUpdate) < STUB_ROUTER_BGP_QUIET_PERIOD {
+		return false
+	}
+	for _, protoMap := range server.RedistData.RibRouteMap {
+		if _, exist := protoMap[objects.REDIST_PROTOCOL_BGP]; exist {
+			return true
+		}
+	}
+	return false
+}
+
+func (server *OSPFV2Server) processStubRouterRibRoute(route RibRoute) {
+	if server.StubRouterData.OnStartup &&
+		route.Protocol == objects.REDIST_PROTOCOL_BGP {
+		server.StubRouterData.LastBgpUpdate = time.Now()
+	}
+}
+
+func (server *OSPFV2Server) processStubRouterStartupTimer() {
+	stubRouter := &server.StubRouterData
+	if !stubRouter.OnStartup {
+		return
+	}
+	now := time.Now()
+	if now.Before(stubRouter.StartupExpiry) {
+		if !server.globalData.StubRouterWaitForBgp ||
+			!server.isBgpConverged(now) {
+			server.armStubRouterStartupTimer(now)
+			return
+		}
+		server.logger.Info("BGP converged, exiting stub router on startup")
+	} else {
+		server.logger.Info("Stub router on startup timed out")
+	}
+	stubRouter.OnStartup = false
+	server.updateStubRouterState()
+}
+
+/*
+Re-originate the LSAs whose metric depends on the stub router state.
+External routes are re-evaluated here, Lsdb takes care of the router
+and summary LSAs.
+*/
+func (server *OSPFV2Server) updateStubRouterState() {
+	msg := server.getStubRouterMsg()
+	if msg == server.StubRouterData.AdvState {
+		return
+	}
+	server.StubRouterData.AdvState = msg
+	if server.globalData.AdminState == false {
+		return
+	}
+	server.logger.Info("Stub router state changed:", msg)
+	server.refreshRedistribution()
+	server.SendMsgToLsdbForStubRouterUpdate(msg)
+}
+
+func (server *OSPFV2Server) applyStubRouterExtMetric(routeInfo *RouteInfo) {
+	if server.StubRouterData.AdvState.ExternalLsaMaxMetric {
+		routeInfo.Metric = MaxLsaMetric
+	}
+}
+
+// Called before the Lsdb routine is started
+func (server *OSPFV2Server) initStubRouterLsdbState() {
+	server.StubRouterData.AdvState = server.getStubRouterMsg()
+	server.StubRouterData.LsdbState = server.StubRouterData.AdvState
+}
+
+/*
+Following functions are called by Lsdb routine
+*/
+func (server *OSPFV2Server) getTransitLinkMetric(cost uint32) uint16 {
+	// Virtual link cost is the path cost through the transit area
+	if server.StubRouterData.LsdbState.RouterLsaMaxMetric ||
+		cost > uint32(MaxLinkMetric) {
+		return MaxLinkMetric
+	}
+	return uint16(cost)
+}
+
+func (server *OSPFV2Server) applyStubRouterSummaryMetric(sEnt SummaryLsaMap) {
+	if !server.StubRouterData.LsdbState.SummaryLsaMaxMetric {
+		return
+	}
+	for lsaKey, summaryLsa := range sEnt {
+		summaryLsa.Metric = MaxLsaMetric
+		sEnt[lsaKey] = summaryLsa
+	}
+}
+
+func (server *OSPFV2Server) processStubRouterUpdate(msg StubRouterMsg) {
+	server.StubRouterData.LsdbState = msg
+	for areaId, _ := range server.AreaConfMap {
+		lsdbKey := LsdbKey{
+			AreaId: areaId,
+		}
+		if _, exist := server.LsdbData.AreaLsdb[lsdbKey]; !exist {
+			continue
+		}
+		err := server.GenerateRouterLSA(GenerateRouterLSAMsg{
+			AreaId: areaId,
+		})
+		if err != nil {
+			server.logger.Err("Unable to regenerate router LSA for area", areaId, err)
+		}
+	}
+	// Summary LSAs are regenerated after the route calculation
+	server.scheduleSPF(SPF_CALC_FULL, objects.SPF_TRIGGER_ROUTER_LSA)
+}
@@ -206,7 +206,7 @@ func (server *OSPFV2Server) updateSummaryLsa(lsdbKey LsdbKey, lsaKey LsaKey, lsa
 	sLsa.LsaMd.LSChecksum = 0
 	sLsa.LsaMd.LSLen = lsaEnt.LsaMd.LSLen
 	sLsa.LsaMd.LSSequenceNum = sLsa.LsaMd.LSSequenceNum + 1
-	sLsa.LsaMd.Options = EOption
+	sLsa.LsaMd.Options = server.getAreaLsaOptions(lsdbKey.AreaId)
 	sLsaEnc := encodeSummaryLsa(sLsa, lsaKey)
 	checksumOffset := uint16(14)
 	sLsa.LsaMd.LSChecksum = computeFletcherChecksum(sLsaEnc[2:], checksumOffset)
@@ -235,7 +235,7 @@ func (server *OSPFV2Server) insertNewSummaryLsa(lsdbKey LsdbKey, lsaKey LsaKey,
 	sLsa.LsaMd.LSChecksum = 0
 	sLsa.LsaMd.LSLen = lsaEnt.LsaMd.LSLen
 	sLsa.LsaMd.LSSequenceNum = int(InitialSequenceNum)
-	sLsa.LsaMd.Options = EOption
+	sLsa.LsaMd.Options = server.getAreaLsaOptions(lsdbKey.AreaId)
 	sLsaEnc := encodeSummaryLsa(sLsa, lsaKey)
 	checksumOffset := uint16(14)
 	sLsa.LsaMd.LSChecksum = computeFletcherChecksum(sLsaEnc[2:], checksumOffset)
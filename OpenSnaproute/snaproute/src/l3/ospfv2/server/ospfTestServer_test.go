@@ -0,0 +1,99 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"net"
+	"time"
+	"utils/logging"
+)
+
+// Messages sent by the code under test are read back from the channels
+const testChanLen = 16
+
+func testIp(ip string) uint32 {
+	ipAddr, _ := convertDotNotationToUint32(ip)
+	return ipAddr
+}
+
+/*
+newTestServer returns a server initialized the way ospfd and its
+routines initialize it, without starting any of the routines. The
+channels to the LSDB and flooding routines are buffered.
+*/
+func newTestServer(rtrId string) *OSPFV2Server {
+	server, _ := NewOspfv2Server(InitParams{
+		Logger: new(logging.Writer),
+		PktIO:  NewVirtualPktIO(),
+	})
+	server.globalData.RouterId = testIp(rtrId)
+	server.initMessagingChData()
+	server.initInfra()
+	server.InitNbrStruct()
+	server.InitLsdbData()
+	server.InitRoutingTbl()
+	server.InitSPFStructs()
+	server.RoutingTblData.IntraAreaRoutingTbl = make(map[AreaIdKey]AreaRoutingTbl)
+	server.RoutingTblData.TempAreaRoutingTbl = make(map[AreaIdKey]AreaRoutingTbl)
+	server.RoutingTblData.TempVirtualLinkEndpointMap = make(map[VirtualLinkKey]VirtualLinkEndpoint)
+	server.MessagingChData.IntfFSMToLsdbChData.GenerateRouterLSACh = make(chan GenerateRouterLSAMsg, testChanLen)
+	server.MessagingChData.LsdbToFloodChData.LsdbToFloodLSACh = make(chan []LsdbToFloodLSAMsg, testChanLen)
+	return server
+}
+
+// addTestArea adds an administratively up area with its LSDB
+func addTestArea(server *OSPFV2Server, areaId uint32, areaEnt AreaConf) {
+	areaEnt.AdminState = true
+	if areaEnt.IntfMap == nil {
+		areaEnt.IntfMap = make(map[IntfConfKey]bool)
+	}
+	server.AreaConfMap[areaId] = areaEnt
+	server.InitAreaLsdb(areaId)
+	server.RoutingTblData.IntraAreaRoutingTbl[AreaIdKey{AreaId: areaId}] = AreaRoutingTbl{
+		RoutingTblMap: make(map[RoutingTblEntryKey]RoutingTblEntry),
+	}
+}
+
+// addTestIntf adds an interface in the area which is up in the given state
+func addTestIntf(server *OSPFV2Server, areaId uint32, ipAddr string, intfType, fsmState uint8) IntfConfKey {
+	ip := testIp(ipAddr)
+	intfKey := IntfConfKey{
+		IpAddr: ip,
+	}
+	server.IntfConfMap[intfKey] = IntfConf{
+		AreaId:      areaId,
+		Type:        intfType,
+		IpAddr:      ip,
+		Netmask:     testIp("255.255.255.0"),
+		IfMacAddr:   net.HardwareAddr{0x00, 0x11, byte(ip >> 24), byte(ip >> 16), byte(ip >> 8), byte(ip)},
+		Cost:        10,
+		RtrPriority: 1,
+		FSMState:    fsmState,
+		NbrMap:      make(map[NbrConfKey]NbrData),
+		NbrPollTime: make(map[uint32]time.Time),
+		authData:    NewIntfAuthStruct(),
+	}
+	server.AreaConfMap[areaId].IntfMap[intfKey] = true
+	return intfKey
+}
@@ -25,39 +25,48 @@ package server
 
 import (
 	"errors"
-	"github.com/google/gopacket/pcap"
+	"l3/ospfv2/objects"
 )
 
 func (server *OSPFV2Server) SendOspfPkt(key IntfConfKey, ospfPkt []byte) error {
 	entry, _ := server.IntfConfMap[key]
-	handle := entry.txHdl.SendPcapHdl
+	handle := entry.txHdl.SendHdl
 	if handle == nil {
-		server.logger.Err("Invalid pcap handle")
-		err := errors.New("Invalid pcap handle")
+		server.logger.Err("Invalid tx handle")
+		err := errors.New("Invalid tx handle")
 		return err
 	}
-	entry.txHdl.SendMutex.Lock()
+	if entry.Type == objects.INTF_TYPE_VIRTUAL {
+		var err error
+		ospfPkt, err = server.rewriteVirtualLinkPkt(entry, ospfPkt)
+		if err != nil {
+			server.logger.Err("Unable to send pkt on virtual link", key, err)
+			return err
+		}
+	}
 	err := handle.WritePacketData(ospfPkt)
-	entry.txHdl.SendMutex.Unlock()
+	if err == nil {
+		server.countTxPkt(key, ospfPkt)
+	}
 	return err
 }
 
 func (server *OSPFV2Server) InitTxPkt(intfKey IntfConfKey) error {
 	intfEnt, _ := server.IntfConfMap[intfKey]
 	ifName := intfEnt.IfName
-	sendHdl, err := pcap.OpenLive(ifName, snapshotLen, promiscuous, pcapTimeout)
+	sendHdl, err := server.pktIO.OpenTx(getPktIOParams(intfEnt))
 	if err != nil {
-		server.logger.Err("Error opening pcap handler on ", ifName)
+		server.logger.Err("Error opening tx handle on ", ifName)
 		return err
 	}
-	intfEnt.txHdl.SendPcapHdl = sendHdl
+	intfEnt.txHdl.SendHdl = sendHdl
 	server.IntfConfMap[intfKey] = intfEnt
 	return nil
 }
 
 func (server *OSPFV2Server) DeinitTxPkt(intfKey IntfConfKey) {
 	intfEnt, _ := server.IntfConfMap[intfKey]
-	intfEnt.txHdl.SendPcapHdl.Close()
-	intfEnt.txHdl.SendPcapHdl = nil
+	intfEnt.txHdl.SendHdl.Close()
+	intfEnt.txHdl.SendHdl = nil
 	server.IntfConfMap[intfKey] = intfEnt
 }
@@ -0,0 +1,596 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"errors"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"l3/ospfv2/objects"
+	"net"
+	"sort"
+)
+
+type VirtualLinkKey struct {
+	TransitAreaId uint32
+	NbrRtrId      uint32
+}
+
+type VirtualLinkConf struct {
+	IntfKey IntfConfKey // Virtual interface, always part of the backbone
+}
+
+/*
+Virtual link endpoint as computed by the SPF of the
+transit area (RFC 2328 15 and 16.1).
+*/
+type VirtualLinkEndpoint struct {
+	Cost        uint32
+	LocalIpAddr uint32 // Our interface towards the virtual nbr
+	NbrIpAddr   uint32 // Virtual nbr interface address
+	NextHopIp   uint32
+	RxIpAddrs   []uint32 // Every interface towards the virtual nbr (ECMP)
+}
+
+type ipAddrSlice []uint32
+
+func (s ipAddrSlice) Len() int {
+	return len(s)
+}
+
+func (s ipAddrSlice) Less(i, j int) bool {
+	return s[i] < s[j]
+}
+
+func (s ipAddrSlice) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+func isSameIpAddrList(list1, list2 []uint32) bool {
+	if len(list1) != len(list2) {
+		return false
+	}
+	for idx, ipAddr := range list1 {
+		if list2[idx] != ipAddr {
+			return false
+		}
+	}
+	return true
+}
+
+func isIpAddrInList(ipAddr uint32, list []uint32) bool {
+	for _, listIpAddr := range list {
+		if listIpAddr == ipAddr {
+			return true
+		}
+	}
+	return false
+}
+
+func isSameVirtualLinkEndpoint(endpoint1, endpoint2 VirtualLinkEndpoint) bool {
+	return endpoint1.Cost == endpoint2.Cost &&
+		endpoint1.LocalIpAddr == endpoint2.LocalIpAddr &&
+		endpoint1.NbrIpAddr == endpoint2.NbrIpAddr &&
+		endpoint1.NextHopIp == endpoint2.NextHopIp &&
+		isSameIpAddrList(endpoint1.RxIpAddrs, endpoint2.RxIpAddrs)
+}
+
+type VirtualLinkChangeMsg struct {
+	Key       VirtualLinkKey
+	Reachable bool
+	Endpoint  VirtualLinkEndpoint
+}
+
+func genOspfv2VirtualLinkUpdateMask(attrset []bool) uint32 {
+	var mask uint32 = 0
+
+	if attrset == nil {
+		mask = objects.OSPFV2_VIRTUAL_LINK_UPDATE_ADMIN_STATE |
+			objects.OSPFV2_VIRTUAL_LINK_UPDATE_TRANSIT_DELAY |
+			objects.OSPFV2_VIRTUAL_LINK_UPDATE_RETRANS_INTERVAL |
+			objects.OSPFV2_VIRTUAL_LINK_UPDATE_HELLO_INTERVAL |
+			objects.OSPFV2_VIRTUAL_LINK_UPDATE_RTR_DEAD_INTERVAL |
+			objects.OSPFV2_VIRTUAL_LINK_UPDATE_AUTH_KEY |
+			objects.OSPFV2_VIRTUAL_LINK_UPDATE_AUTH_KEY_ID
+	} else {
+		for idx, val := range attrset {
+			if val == true {
+				switch idx {
+				case 0:
+					//AreaId
+				case 1:
+					//NbrRouterId
+				case 2:
+					mask |= objects.OSPFV2_VIRTUAL_LINK_UPDATE_ADMIN_STATE
+				case 3:
+					mask |= objects.OSPFV2_VIRTUAL_LINK_UPDATE_TRANSIT_DELAY
+				case 4:
+					mask |= objects.OSPFV2_VIRTUAL_LINK_UPDATE_RETRANS_INTERVAL
+				case 5:
+					mask |= objects.OSPFV2_VIRTUAL_LINK_UPDATE_HELLO_INTERVAL
+				case 6:
+					mask |= objects.OSPFV2_VIRTUAL_LINK_UPDATE_RTR_DEAD_INTERVAL
+				case 7:
+					mask |= objects.OSPFV2_VIRTUAL_LINK_UPDATE_AUTH_KEY
+				case 8:
+					mask |= objects.OSPFV2_VIRTUAL_LINK_UPDATE_AUTH_KEY_ID
+				}
+			}
+		}
+	}
+	return mask
+}
+
+/*
+The virtual interface is keyed by the virtual nbr router id
+and the transit area, which can never collide with a numbered
+(IntfIdx 0) or unnumbered (IpAddr 0) interface.
+*/
+func getVirtualLinkIntfKey(vlKey VirtualLinkKey) IntfConfKey {
+	return IntfConfKey{
+		IpAddr:  vlKey.NbrRtrId,
+		IntfIdx: vlKey.TransitAreaId,
+	}
+}
+
+func (server *OSPFV2Server) createVirtualLink(cfg *objects.Ospfv2VirtualLink) (bool, error) {
+	server.logger.Info("Virtual link configuration create")
+	vlKey := VirtualLinkKey{
+		TransitAreaId: cfg.AreaId,
+		NbrRtrId:      cfg.NbrRouterId,
+	}
+	_, exist := server.VirtualLinkConfMap[vlKey]
+	if exist {
+		server.logger.Err("Unable to create virtual link already exist")
+		return false, errors.New("Unable to create virtual link already exist")
+	}
+	transitAreaEnt, exist := server.AreaConfMap[cfg.AreaId]
+	if !exist {
+		server.logger.Err("Unable to create virtual link, transit area doesnot exist")
+		return false, errors.New("Unable to create virtual link, transit area doesnot exist")
+	}
+	if transitAreaEnt.AreaType != objects.AREA_TYPE_NORMAL {
+		server.logger.Err("Unable to create virtual link, stub and NSSA areas cannot be transit area")
+		return false, errors.New("Unable to create virtual link, stub and NSSA areas cannot be transit area")
+	}
+	backboneEnt, exist := server.AreaConfMap[0]
+	if !exist {
+		server.logger.Err("Unable to create virtual link, backbone area doesnot exist")
+		return false, errors.New("Unable to create virtual link, backbone area doesnot exist")
+	}
+	err := validateIntfAuthKey(backboneEnt.AuthType, cfg.AuthKey)
+	if err != nil {
+		server.logger.Err("Virtual link configuration create:", err)
+		return false, err
+	}
+	intfConfKey := getVirtualLinkIntfKey(vlKey)
+	var intfConfEnt IntfConf
+	intfConfEnt.AdminState = cfg.AdminState
+	intfConfEnt.AreaId = 0
+	intfConfEnt.Type = objects.INTF_TYPE_VIRTUAL
+	intfConfEnt.RtrPriority = 0
+	intfConfEnt.TransitDelay = cfg.TransitDelay
+	intfConfEnt.RetransInterval = cfg.RetransInterval
+	intfConfEnt.HelloInterval = cfg.HelloInterval
+	intfConfEnt.RtrDeadInterval = cfg.RtrDeadInterval
+	intfConfEnt.Cost = LSInfinity
+	intfConfEnt.AuthType = uint16(backboneEnt.AuthType)
+	intfConfEnt.AuthKey = encodeIntfAuthKey(cfg.AuthKey)
+	intfConfEnt.AuthKeyId = cfg.AuthKeyId
+	intfConfEnt.authData = NewIntfAuthStruct()
+	intfConfEnt.FSMState = objects.INTF_FSM_STATE_DOWN
+	// Comes up once the nbr is reachable through the transit area
+	intfConfEnt.OperState = false
+	server.IntfConfMap[intfConfKey] = intfConfEnt
+
+	backboneEnt.IntfMap[intfConfKey] = true
+	server.AreaConfMap[0] = backboneEnt
+	server.MessagingChData.NbrToIntfFSMChData.NbrDownMsgChMap[intfConfKey] = make(chan NbrDownMsg, NBR_DOWN_MSG_QUEUE_LEN)
+	server.VirtualLinkConfMap[vlKey] = VirtualLinkConf{
+		IntfKey: intfConfKey,
+	}
+	server.SendMsgToLsdbForVirtualLinkUpdate()
+	server.logger.Info("Successfully created ospfv2VirtualLink config")
+	return true, nil
+}
+
+func (server *OSPFV2Server) updateVirtualLink(newCfg, oldCfg *objects.Ospfv2VirtualLink, attrset []bool) (bool, error) {
+	server.logger.Info("Virtual link configuration update")
+	vlKey := VirtualLinkKey{
+		TransitAreaId: newCfg.AreaId,
+		NbrRtrId:      newCfg.NbrRouterId,
+	}
+	vlEnt, exist := server.VirtualLinkConfMap[vlKey]
+	if !exist {
+		server.logger.Err("Cannot update, virtual link doesnot exist")
+		return false, errors.New("Cannot update, virtual link doesnot exist")
+	}
+	mask := genOspfv2VirtualLinkUpdateMask(attrset)
+	if mask&objects.OSPFV2_VIRTUAL_LINK_UPDATE_AUTH_KEY == objects.OSPFV2_VIRTUAL_LINK_UPDATE_AUTH_KEY {
+		backboneEnt, _ := server.AreaConfMap[0]
+		err := validateIntfAuthKey(backboneEnt.AuthType, newCfg.AuthKey)
+		if err != nil {
+			server.logger.Err("Virtual link configuration update:", err)
+			return false, err
+		}
+	}
+	server.StopIntfFSM(vlEnt.IntfKey)
+	intfConfEnt, _ := server.IntfConfMap[vlEnt.IntfKey]
+	if mask&objects.OSPFV2_VIRTUAL_LINK_UPDATE_ADMIN_STATE == objects.OSPFV2_VIRTUAL_LINK_UPDATE_ADMIN_STATE {
+		intfConfEnt.AdminState = newCfg.AdminState
+	}
+	if mask&objects.OSPFV2_VIRTUAL_LINK_UPDATE_TRANSIT_DELAY == objects.OSPFV2_VIRTUAL_LINK_UPDATE_TRANSIT_DELAY {
+		intfConfEnt.TransitDelay = newCfg.TransitDelay
+	}
+	if mask&objects.OSPFV2_VIRTUAL_LINK_UPDATE_RETRANS_INTERVAL == objects.OSPFV2_VIRTUAL_LINK_UPDATE_RETRANS_INTERVAL {
+		intfConfEnt.RetransInterval = newCfg.RetransInterval
+	}
+	if mask&objects.OSPFV2_VIRTUAL_LINK_UPDATE_HELLO_INTERVAL == objects.OSPFV2_VIRTUAL_LINK_UPDATE_HELLO_INTERVAL {
+		intfConfEnt.HelloInterval = newCfg.HelloInterval
+	}
+	if mask&objects.OSPFV2_VIRTUAL_LINK_UPDATE_RTR_DEAD_INTERVAL == objects.OSPFV2_VIRTUAL_LINK_UPDATE_RTR_DEAD_INTERVAL {
+		intfConfEnt.RtrDeadInterval = newCfg.RtrDeadInterval
+	}
+	if mask&objects.OSPFV2_VIRTUAL_LINK_UPDATE_AUTH_KEY == objects.OSPFV2_VIRTUAL_LINK_UPDATE_AUTH_KEY {
+		intfConfEnt.AuthKey = encodeIntfAuthKey(newCfg.AuthKey)
+	}
+	if mask&objects.OSPFV2_VIRTUAL_LINK_UPDATE_AUTH_KEY_ID == objects.OSPFV2_VIRTUAL_LINK_UPDATE_AUTH_KEY_ID {
+		intfConfEnt.AuthKeyId = newCfg.AuthKeyId
+	}
+	server.IntfConfMap[vlEnt.IntfKey] = intfConfEnt
+	server.StartIntfFSM(vlEnt.IntfKey)
+	return true, nil
+}
+
+func (server *OSPFV2Server) deleteVirtualLink(cfg *objects.Ospfv2VirtualLink) (bool, error) {
+	server.logger.Info("Virtual link configuration delete")
+	vlKey := VirtualLinkKey{
+		TransitAreaId: cfg.AreaId,
+		NbrRtrId:      cfg.NbrRouterId,
+	}
+	vlEnt, exist := server.VirtualLinkConfMap[vlKey]
+	if !exist {
+		server.logger.Err("Unable to delete virtual link doesnot exist")
+		return false, errors.New("Unable to delete virtual link doesnot exist")
+	}
+	server.StopIntfFSM(vlEnt.IntfKey)
+	backboneEnt, _ := server.AreaConfMap[0]
+	delete(backboneEnt.IntfMap, vlEnt.IntfKey)
+	server.AreaConfMap[0] = backboneEnt
+	delete(server.MessagingChData.NbrToIntfFSMChData.NbrDownMsgChMap, vlEnt.IntfKey)
+	delete(server.IntfConfMap, vlEnt.IntfKey)
+	server.delIntfPktStats(vlEnt.IntfKey)
+	delete(server.VirtualLinkConfMap, vlKey)
+	server.SendMsgToLsdbForVirtualLinkUpdate()
+	return true, nil
+}
+
+func (server *OSPFV2Server) areaHasVirtualLink(areaId uint32) bool {
+	for vlKey, _ := range server.VirtualLinkConfMap {
+		if vlKey.TransitAreaId == areaId {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+@fn isVirtualLinkTransitArea
+Bit V of the router LSA of areaId is set when at least one
+virtual link using areaId as transit area is up.
+*/
+func (server *OSPFV2Server) isVirtualLinkTransitArea(areaId uint32) bool {
+	for vlKey, vlEnt := range server.VirtualLinkConfMap {
+		if vlKey.TransitAreaId != areaId {
+			continue
+		}
+		intfEnt, exist := server.IntfConfMap[vlEnt.IntfKey]
+		if exist && intfEnt.FSMState == objects.INTF_FSM_STATE_P2P &&
+			len(intfEnt.NbrMap) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+/*
+@fn updateVirtualLinkEndpoints
+Called after the intra area routes of a transit area are
+computed. A virtual link endpoint is reachable when the
+virtual nbr is an area border router in the routing table
+of the transit area (RFC 2328 16.1 step 4).
+Packets are sent through the lowest next hop, they are received
+on every interface of the equal cost paths.
+*/
+func (server *OSPFV2Server) updateVirtualLinkEndpoints(areaId uint32) {
+	areaIdKey := AreaIdKey{
+		AreaId: areaId,
+	}
+	tempAreaRoutingTbl, exist := server.RoutingTblData.TempAreaRoutingTbl[areaIdKey]
+	if !exist {
+		return
+	}
+	for vlKey, _ := range server.VirtualLinkConfMap {
+		if vlKey.TransitAreaId != areaId {
+			continue
+		}
+		var rEnt RoutingTblEntry
+		found := false
+		for _, destType := range []DestType{AreaBdrRouter, ASAreaBdrRouter} {
+			rKey := RoutingTblEntryKey{
+				DestType: destType,
+				AddrMask: 0,
+				DestId:   vlKey.NbrRtrId,
+			}
+			rEnt, found = tempAreaRoutingTbl.RoutingTblMap[rKey]
+			if found {
+				break
+			}
+		}
+		if !found || len(rEnt.NextHops) == 0 {
+			continue
+		}
+		nextHops := make(NextHopSlice, 0, len(rEnt.NextHops))
+		for nextHop, _ := range rEnt.NextHops {
+			nextHops = append(nextHops, nextHop)
+		}
+		sort.Sort(nextHops)
+		var endpoint VirtualLinkEndpoint
+		endpoint.Cost = rEnt.Cost
+		endpoint.LocalIpAddr = nextHops[0].IfIPAddr
+		endpoint.NextHopIp = nextHops[0].NextHopIP
+		rxIpAddrs := make(ipAddrSlice, 0, len(nextHops))
+		rxIpAddrMap := make(map[uint32]bool)
+		for _, nextHop := range nextHops {
+			if nextHop.IfIPAddr == 0 || rxIpAddrMap[nextHop.IfIPAddr] {
+				continue
+			}
+			rxIpAddrMap[nextHop.IfIPAddr] = true
+			rxIpAddrs = append(rxIpAddrs, nextHop.IfIPAddr)
+		}
+		sort.Sort(rxIpAddrs)
+		endpoint.RxIpAddrs = rxIpAddrs
+		endpoint.NbrIpAddr = server.getVirtualNbrIpAddr(vlKey.NbrRtrId)
+		if endpoint.LocalIpAddr == 0 || endpoint.NbrIpAddr == 0 {
+			continue
+		}
+		server.RoutingTblData.TempVirtualLinkEndpointMap[vlKey] = endpoint
+	}
+}
+
+/*
+@fn getVirtualNbrIpAddr
+The virtual nbr address is the address of its interface towards
+the calculating router (RFC 2328 15), the link data of the
+virtual nbr vertex towards its parent in the shortest path tree.
+The lowest address is used when the nbr has several parents.
+*/
+func (server *OSPFV2Server) getVirtualNbrIpAddr(nbrRtrId uint32) uint32 {
+	vKey := VertexKey{
+		Type:   RouterVertex,
+		ID:     nbrRtrId,
+		AdvRtr: nbrRtrId,
+	}
+	tVertex, exist := server.SPFData.SPFTree[vKey]
+	if !exist {
+		return 0
+	}
+	gEnt, exist := server.SPFData.AreaGraph[vKey]
+	if !exist {
+		return 0
+	}
+	var nbrIpAddr uint32
+	for _, path := range tVertex.Paths {
+		if len(path) == 0 {
+			continue
+		}
+		linkData, exist := gEnt.LinkData[path[len(path)-1]]
+		if !exist || linkData == 0 {
+			continue
+		}
+		if nbrIpAddr == 0 || linkData < nbrIpAddr {
+			nbrIpAddr = linkData
+		}
+	}
+	return nbrIpAddr
+}
+
+/*
+@fn processVirtualLinkEndpoints
+Compares the endpoints computed by the last SPF run with the
+previous ones and notifies the server about every change.
+*/
+func (server *OSPFV2Server) processVirtualLinkEndpoints() {
+	newMap := server.RoutingTblData.TempVirtualLinkEndpointMap
+	if newMap == nil {
+		newMap = make(map[VirtualLinkKey]VirtualLinkEndpoint)
+	}
+	for vlKey, _ := range server.RoutingTblData.VirtualLinkEndpointMap {
+		if _, exist := newMap[vlKey]; !exist {
+			server.SendMsgFromLsdbToServerForVirtualLinkChange(VirtualLinkChangeMsg{
+				Key:       vlKey,
+				Reachable: false,
+			})
+		}
+	}
+	for vlKey, endpoint := range newMap {
+		oldEndpoint, exist := server.RoutingTblData.VirtualLinkEndpointMap[vlKey]
+		if exist && isSameVirtualLinkEndpoint(oldEndpoint, endpoint) {
+			continue
+		}
+		server.SendMsgFromLsdbToServerForVirtualLinkChange(VirtualLinkChangeMsg{
+			Key:       vlKey,
+			Reachable: true,
+			Endpoint:  endpoint,
+		})
+	}
+	server.RoutingTblData.VirtualLinkEndpointMap = newMap
+	server.RoutingTblData.TempVirtualLinkEndpointMap = nil
+}
+
+/*
+@fn processVirtualLinkChange
+Brings the virtual interface up or down following the
+reachability of the virtual nbr. The virtual interface
+borrows the address of the transit area interface used
+to reach the nbr.
+*/
+func (server *OSPFV2Server) processVirtualLinkChange(msg VirtualLinkChangeMsg) {
+	vlEnt, exist := server.VirtualLinkConfMap[msg.Key]
+	if !exist {
+		return
+	}
+	server.logger.Info("Virtual link change:", msg)
+	intfConfEnt, _ := server.IntfConfMap[vlEnt.IntfKey]
+	if msg.Reachable && intfConfEnt.OperState == true &&
+		intfConfEnt.IpAddr == msg.Endpoint.LocalIpAddr &&
+		intfConfEnt.VirtNbrIpAddr == msg.Endpoint.NbrIpAddr &&
+		isSameIpAddrList(intfConfEnt.VirtRxIpAddrs, msg.Endpoint.RxIpAddrs) {
+		// Only the cost or the next hop changed, keep the adjacency
+		intfConfEnt.Cost = msg.Endpoint.Cost
+		intfConfEnt.VirtNextHopIp = msg.Endpoint.NextHopIp
+		server.IntfConfMap[vlEnt.IntfKey] = intfConfEnt
+		server.SendMsgToGenerateRouterLSA(intfConfEnt.AreaId)
+		return
+	}
+	server.StopIntfFSM(vlEnt.IntfKey)
+	intfConfEnt, _ = server.IntfConfMap[vlEnt.IntfKey]
+	intfConfEnt.OperState = false
+	if msg.Reachable {
+		l3IfIdx, exist := server.infraData.ipToIfIdxMap[msg.Endpoint.LocalIpAddr]
+		if exist {
+			ipEnt, _ := server.infraData.ipPropertyMap[l3IfIdx]
+			intfConfEnt.OperState = ipEnt.State
+			intfConfEnt.Mtu = uint32(ipEnt.Mtu)
+			intfConfEnt.IfName = ipEnt.IfName
+			intfConfEnt.IfMacAddr = ipEnt.MacAddr
+			intfConfEnt.IpAddr = ipEnt.IpAddr
+			intfConfEnt.IfType = ipEnt.IfType
+			intfConfEnt.Netmask = 0
+			intfConfEnt.Cost = msg.Endpoint.Cost
+			intfConfEnt.VirtNbrIpAddr = msg.Endpoint.NbrIpAddr
+			intfConfEnt.VirtNextHopIp = msg.Endpoint.NextHopIp
+			intfConfEnt.VirtRxIpAddrs = msg.Endpoint.RxIpAddrs
+		} else {
+			server.logger.Err("Virtual link: unknown L3 interface", msg.Endpoint.LocalIpAddr)
+		}
+	}
+	server.IntfConfMap[vlEnt.IntfKey] = intfConfEnt
+	server.StartIntfFSM(vlEnt.IntfKey)
+	server.SendMsgToGenerateRouterLSA(msg.Key.TransitAreaId)
+}
+
+/*
+@fn getVirtualLinkRxParams
+The virtual nbr may send through any of the equal cost paths,
+a receive handle is opened on each of them.
+*/
+func (server *OSPFV2Server) getVirtualLinkRxParams(ent IntfConf) []PktIOParams {
+	paramsList := make([]PktIOParams, 0, len(ent.VirtRxIpAddrs))
+	for _, ipAddr := range ent.VirtRxIpAddrs {
+		l3IfIdx, exist := server.infraData.ipToIfIdxMap[ipAddr]
+		if !exist {
+			continue
+		}
+		ipEnt, _ := server.infraData.ipPropertyMap[l3IfIdx]
+		params := getPktIOParams(ent)
+		params.IfName = ipEnt.IfName
+		params.IpAddr = ipAddr
+		paramsList = append(paramsList, params)
+	}
+	if len(paramsList) == 0 {
+		paramsList = append(paramsList, getPktIOParams(ent))
+	}
+	return paramsList
+}
+
+/*
+@fn getIntfRxIpAddrs
+Local addresses the pkts received on the interface are sent to.
+The virtual nbr may send to any of the equal cost path interfaces.
+*/
+func getIntfRxIpAddrs(ent IntfConf) []uint32 {
+	ipAddrs := []uint32{ent.IpAddr}
+	if ent.Type == objects.INTF_TYPE_VIRTUAL {
+		ipAddrs = append(ipAddrs, ent.VirtRxIpAddrs...)
+	}
+	return ipAddrs
+}
+
+func (server *OSPFV2Server) getVirtualLinkNextHop(nbrRtrId uint32) (uint32, bool) {
+	for vlKey, vlEnt := range server.VirtualLinkConfMap {
+		if vlKey.NbrRtrId != nbrRtrId {
+			continue
+		}
+		intfEnt, exist := server.IntfConfMap[vlEnt.IntfKey]
+		if exist && intfEnt.OperState == true {
+			return intfEnt.VirtNextHopIp, true
+		}
+	}
+	return 0, false
+}
+
+func (server *OSPFV2Server) getVirtualLinkNextHopMac(ent IntfConf) (net.HardwareAddr, error) {
+	for _, nbrConf := range server.NbrConfMap {
+		if nbrConf.NbrIP == ent.VirtNextHopIp &&
+			nbrConf.NbrMac != nil {
+			return nbrConf.NbrMac, nil
+		}
+	}
+	return nil, errors.New("Virtual link next hop is not a nbr")
+}
+
+/*
+@fn rewriteVirtualLinkPkt
+Packets sent over a virtual link are unicast to the virtual
+nbr and routed hop by hop through the transit area.
+*/
+func (server *OSPFV2Server) rewriteVirtualLinkPkt(ent IntfConf, ospfPkt []byte) ([]byte, error) {
+	dstMac, err := server.getVirtualLinkNextHopMac(ent)
+	if err != nil {
+		return nil, err
+	}
+	pkt := gopacket.NewPacket(ospfPkt, layers.LayerTypeEthernet, gopacket.Default)
+	ethLayer := pkt.Layer(layers.LayerTypeEthernet)
+	ipLayer := pkt.Layer(layers.LayerTypeIPv4)
+	if ethLayer == nil || ipLayer == nil {
+		return nil, errors.New("Invalid Ospf packet")
+	}
+	eth := ethLayer.(*layers.Ethernet)
+	ip := ipLayer.(*layers.IPv4)
+	eth.DstMAC = dstMac
+	ip.DstIP = net.ParseIP(convertUint32ToDotNotation(ent.VirtNbrIpAddr))
+	ip.TTL = VIRTUAL_LINK_TTL
+
+	buffer := gopacket.NewSerializeBuffer()
+	options := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+	err = gopacket.SerializeLayers(buffer, options, eth, ip, gopacket.Payload(ip.LayerPayload()))
+	if err != nil {
+		return nil, err
+	}
+	return buffer.Bytes(), nil
+}
@@ -0,0 +1,259 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"l3/ospfv2/objects"
+	"testing"
+)
+
+func TestVirtualLinkEndpoint(t *testing.T) {
+	vlKey := VirtualLinkKey{
+		TransitAreaId: 1,
+		NbrRtrId:      testIp("3.3.3.3"),
+	}
+	server := newTestServer("1.1.1.1")
+	server.VirtualLinkConfMap[vlKey] = VirtualLinkConf{
+		IntfKey: getVirtualLinkIntfKey(vlKey),
+	}
+
+	// Two equal cost paths towards the virtual nbr
+	rKey := RoutingTblEntryKey{
+		DestType: AreaBdrRouter,
+		DestId:   vlKey.NbrRtrId,
+	}
+	server.RoutingTblData.TempAreaRoutingTbl[AreaIdKey{AreaId: 1}] = AreaRoutingTbl{
+		RoutingTblMap: map[RoutingTblEntryKey]RoutingTblEntry{
+			rKey: RoutingTblEntry{
+				Cost: 20,
+				NextHops: map[NextHop]bool{
+					NextHop{IfIPAddr: testIp("10.0.1.1"), NextHopIP: testIp("10.0.1.2")}: true,
+					NextHop{IfIPAddr: testIp("10.0.0.1"), NextHopIP: testIp("10.0.0.2")}: true,
+				},
+			},
+		},
+	}
+	rootVKey := VertexKey{Type: RouterVertex, ID: testIp("1.1.1.1"), AdvRtr: testIp("1.1.1.1")}
+	nwVKey := VertexKey{Type: TNetworkVertex, ID: testIp("10.0.2.2"), AdvRtr: testIp("2.2.2.2")}
+	p2pVKey := VertexKey{Type: RouterVertex, ID: testIp("4.4.4.4"), AdvRtr: testIp("4.4.4.4")}
+	farVKey := VertexKey{Type: TNetworkVertex, ID: testIp("10.0.9.5"), AdvRtr: testIp("5.5.5.5")}
+	nbrVKey := VertexKey{Type: RouterVertex, ID: vlKey.NbrRtrId, AdvRtr: vlKey.NbrRtrId}
+	server.SPFData.AreaGraph[nbrVKey] = Vertex{
+		LinkData: map[VertexKey]uint32{
+			nwVKey:  testIp("10.0.2.3"),
+			p2pVKey: testIp("10.0.3.3"),
+			// Not on a shortest path back to the calculating router
+			farVKey: testIp("10.0.0.9"),
+		},
+	}
+	server.SPFData.SPFTree[nbrVKey] = TreeVertex{
+		Paths: []Path{
+			Path{rootVKey, nwVKey},
+			Path{rootVKey, p2pVKey},
+		},
+		NumOfPaths: 2,
+	}
+
+	server.updateVirtualLinkEndpoints(1)
+	endpoint, exist := server.RoutingTblData.TempVirtualLinkEndpointMap[vlKey]
+	if !exist {
+		t.Fatal("Virtual link endpoint not computed")
+	}
+	if endpoint.Cost != 20 {
+		t.Error("Unexpected virtual link cost", endpoint.Cost)
+	}
+	if endpoint.LocalIpAddr != testIp("10.0.0.1") ||
+		endpoint.NextHopIp != testIp("10.0.0.2") {
+		t.Error("Virtual link not sent through the lowest next hop",
+			convertUint32ToDotNotation(endpoint.LocalIpAddr),
+			convertUint32ToDotNotation(endpoint.NextHopIp))
+	}
+	if !isSameIpAddrList(endpoint.RxIpAddrs, []uint32{testIp("10.0.0.1"), testIp("10.0.1.1")}) {
+		t.Error("Virtual link not received on every equal cost path", endpoint.RxIpAddrs)
+	}
+	if endpoint.NbrIpAddr != testIp("10.0.2.3") {
+		t.Error("Virtual nbr address is not its interface towards us",
+			convertUint32ToDotNotation(endpoint.NbrIpAddr))
+	}
+
+	// Unreachable virtual nbr
+	delete(server.SPFData.SPFTree, nbrVKey)
+	server.RoutingTblData.TempVirtualLinkEndpointMap = make(map[VirtualLinkKey]VirtualLinkEndpoint)
+	server.updateVirtualLinkEndpoints(1)
+	if _, exist := server.RoutingTblData.TempVirtualLinkEndpointMap[vlKey]; exist {
+		t.Error("Virtual link endpoint computed without a nbr address")
+	}
+}
+
+func TestProcessVirtualLinkEndpoints(t *testing.T) {
+	vlKey := VirtualLinkKey{
+		TransitAreaId: 1,
+		NbrRtrId:      testIp("3.3.3.3"),
+	}
+	server := newTestServer("1.1.1.1")
+	server.VirtualLinkConfMap[vlKey] = VirtualLinkConf{
+		IntfKey: getVirtualLinkIntfKey(vlKey),
+	}
+	changeCh := server.MessagingChData.LsdbToServerChData.VirtualLinkChangeCh
+	endpoint := VirtualLinkEndpoint{
+		Cost:        20,
+		LocalIpAddr: testIp("10.0.0.1"),
+		NbrIpAddr:   testIp("10.0.2.3"),
+		NextHopIp:   testIp("10.0.0.2"),
+		RxIpAddrs:   []uint32{testIp("10.0.0.1")},
+	}
+	server.RoutingTblData.TempVirtualLinkEndpointMap[vlKey] = endpoint
+	server.processVirtualLinkEndpoints()
+	if len(changeCh) != 1 {
+		t.Fatal("New virtual link endpoint not notified")
+	}
+	<-changeCh
+
+	sameEndpoint := endpoint
+	sameEndpoint.RxIpAddrs = []uint32{testIp("10.0.0.1")}
+	server.RoutingTblData.TempVirtualLinkEndpointMap = map[VirtualLinkKey]VirtualLinkEndpoint{
+		vlKey: sameEndpoint,
+	}
+	server.processVirtualLinkEndpoints()
+	if len(changeCh) != 0 {
+		t.Error("Unchanged virtual link endpoint notified")
+	}
+
+	ecmpEndpoint := endpoint
+	ecmpEndpoint.RxIpAddrs = []uint32{testIp("10.0.0.1"), testIp("10.0.1.1")}
+	server.RoutingTblData.TempVirtualLinkEndpointMap = map[VirtualLinkKey]VirtualLinkEndpoint{
+		vlKey: ecmpEndpoint,
+	}
+	server.processVirtualLinkEndpoints()
+	if len(changeCh) != 1 {
+		t.Fatal("New equal cost path not notified")
+	}
+	msg := <-changeCh
+	if !msg.Reachable || len(msg.Endpoint.RxIpAddrs) != 2 {
+		t.Error("Unexpected virtual link change", msg)
+	}
+
+	server.processVirtualLinkEndpoints()
+	if len(changeCh) != 1 {
+		t.Fatal("Unreachable virtual nbr not notified")
+	}
+	msg = <-changeCh
+	if msg.Reachable {
+		t.Error("Virtual nbr still reachable", msg)
+	}
+}
+
+// The virtual nbr may send to the address of any of the equal cost paths
+func TestVirtualLinkRxIpAddrs(t *testing.T) {
+	server := newTestServer("1.1.1.1")
+	ent := IntfConf{
+		Type:          objects.INTF_TYPE_VIRTUAL,
+		IpAddr:        testIp("10.0.0.1"),
+		VirtRxIpAddrs: []uint32{testIp("10.0.0.1"), testIp("10.0.1.1")},
+	}
+	tests := []struct {
+		srcIp string
+		dstIp string
+		valid bool
+	}{
+		{"10.0.2.3", "10.0.0.1", true},
+		{"10.0.2.3", "10.0.1.1", true},
+		{"10.0.2.3", "10.0.2.1", false},
+		// Sent by self on the other path
+		{"10.0.1.1", "10.0.0.1", false},
+	}
+	for _, test := range tests {
+		pkt := gopacket.NewPacket(buildTestOspfFrame(test.srcIp, test.dstIp), layers.LayerTypeEthernet, gopacket.Default)
+		err := server.processIPv4Layer(pkt.Layer(layers.LayerTypeIPv4), getIntfRxIpAddrs(ent), NewIpHdrMetadata())
+		if (err == nil) != test.valid {
+			t.Error("Wrong rx check for pkt from", test.srcIp, "to", test.dstIp, err)
+		}
+	}
+}
+
+/*
+A and C are ABRs joined by two equal cost links in transit area 1.
+Their backbone stub networks are only connected over the virtual
+link, whose pkts may arrive on either of the equal cost links.
+*/
+func TestVirtualLinkBetweenAbrs(t *testing.T) {
+	lan1 := NewVirtualWire()
+	defer lan1.SetLinkUp(false)
+	lan2 := NewVirtualWire()
+	defer lan2.SetLinkUp(false)
+	rtrA := newTestRouter(t, "1.1.1.1")
+	defer rtrA.close(t)
+	rtrC := newTestRouter(t, "3.3.3.3")
+	defer rtrC.close(t)
+	rtrA.addArea(t, 1)
+	rtrC.addArea(t, 1)
+	rtrA.addIntf(t, "eth0", "20.0.0.1", 24, 1, NewVirtualWire())
+	rtrC.addIntf(t, "eth0", "40.0.0.3", 24, 1, NewVirtualWire())
+	rtrA.addAreaIntf(t, "eth1", "10.0.1.1", 24, 1, lan1)
+	rtrA.addAreaIntf(t, "eth2", "10.0.2.1", 24, 1, lan2)
+	waitFor(t, "A to become DR", func() bool {
+		return rtrA.isIntfState("10.0.1.1", objects.INTF_FSM_STATE_DR) &&
+			rtrA.isIntfState("10.0.2.1", objects.INTF_FSM_STATE_DR)
+	})
+	rtrC.addAreaIntf(t, "eth1", "10.0.1.3", 24, 1, lan1)
+	rtrC.addAreaIntf(t, "eth2", "10.0.2.3", 24, 1, lan2)
+	waitForAdjacency(t, rtrA, "10.0.1.1", rtrC, "10.0.1.3")
+	waitForAdjacency(t, rtrA, "10.0.2.1", rtrC, "10.0.2.3")
+
+	rtrA.addVirtualLink(t, 1, "3.3.3.3")
+	rtrC.addVirtualLink(t, 1, "1.1.1.1")
+	waitFor(t, "adjacency over the virtual link", func() bool {
+		nbrC := rtrA.getVirtualNbrState(1, "3.3.3.3")
+		nbrA := rtrC.getVirtualNbrState(1, "1.1.1.1")
+		return nbrC != nil && nbrC.State == uint8(NbrFull) &&
+			nbrA != nil && nbrA.State == uint8(NbrFull)
+	})
+	vlIntfState := rtrA.getVirtualLinkIntfState(1, "3.3.3.3")
+	if vlIntfState == nil || vlIntfState.State != objects.INTF_FSM_STATE_P2P {
+		t.Error("Virtual interface not up", vlIntfState)
+	}
+
+	// The backbone networks are intra area routes over the virtual link
+	for _, route := range []struct {
+		rtr     *testRouter
+		network string
+	}{
+		{rtrA, "40.0.0.0"},
+		{rtrC, "20.0.0.0"},
+	} {
+		rtr := route.rtr
+		network := route.network
+		waitFor(t, "backbone route to "+network+"/24", func() bool {
+			rEnt, exist := rtr.getRoute(network, 24)
+			return exist && rEnt.AreaId == 0 &&
+				rEnt.RoutingTblEnt.PathType == IntraArea
+		})
+		rEnt, _ := rtr.getRoute(network, 24)
+		if rEnt.RoutingTblEnt.Cost != 2*testIntfCost {
+			t.Error("Wrong cost for", network, rEnt.RoutingTblEnt.Cost)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"encoding/binary"
+	"errors"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"io"
+	"l3/ospfv2/objects"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	virtualWireRxQueueLen = 1000
+	// Real links have some latency, the nbr FSM relies on it
+	virtualWireDefaultDelay = 10 * time.Millisecond
+)
+
+/*
+VirtualWire is an in-memory broadcast segment, every frame sent on
+the wire is delivered to all the receive handles attached to it.
+Several OSPFV2Server instances attached to the same wires form
+adjacencies without any network interface.
+*/
+type VirtualWire struct {
+	mutex  sync.Mutex
+	linkUp bool
+	delay  time.Duration
+	rxHdls map[*virtualRxHandle]bool
+}
+
+/*
+VirtualPktIO is the PktIO of a router attached to virtual wires,
+interfaces are attached to the wire connected to their IfName.
+*/
+type VirtualPktIO struct {
+	mutex   sync.Mutex
+	wireMap map[string]*VirtualWire
+}
+
+type virtualTxHandle struct {
+	wire *VirtualWire
+}
+
+type virtualWireFrame struct {
+	data      []byte
+	deliverAt time.Time
+}
+
+type virtualRxHandle struct {
+	wire      *VirtualWire
+	params    PktIOParams
+	pktCh     chan virtualWireFrame
+	closeCh   chan bool
+	closeOnce sync.Once
+}
+
+func NewVirtualWire() *VirtualWire {
+	return &VirtualWire{
+		linkUp: true,
+		delay:  virtualWireDefaultDelay,
+		rxHdls: make(map[*virtualRxHandle]bool),
+	}
+}
+
+// SetDelay sets the time a frame takes to reach the other end of the wire
+func (wire *VirtualWire) SetDelay(delay time.Duration) {
+	wire.mutex.Lock()
+	wire.delay = delay
+	wire.mutex.Unlock()
+}
+
+// SetLinkUp connects or disconnects the wire, frames are dropped while down
+func (wire *VirtualWire) SetLinkUp(linkUp bool) {
+	wire.mutex.Lock()
+	wire.linkUp = linkUp
+	wire.mutex.Unlock()
+}
+
+func (wire *VirtualWire) attach(rxHdl *virtualRxHandle) {
+	wire.mutex.Lock()
+	wire.rxHdls[rxHdl] = true
+	wire.mutex.Unlock()
+}
+
+func (wire *VirtualWire) detach(rxHdl *virtualRxHandle) {
+	wire.mutex.Lock()
+	delete(wire.rxHdls, rxHdl)
+	wire.mutex.Unlock()
+}
+
+func (wire *VirtualWire) send(data []byte) error {
+	pkt := gopacket.NewPacket(data, layers.LayerTypeEthernet, gopacket.Default)
+	arpLayer := pkt.Layer(layers.LayerTypeARP)
+	if arpLayer != nil {
+		return wire.sendArp(data, arpLayer.(*layers.ARP))
+	}
+	ipLayer := pkt.Layer(layers.LayerTypeIPv4)
+	if ipLayer == nil {
+		return errors.New("Not an IP packet")
+	}
+	ipPkt := ipLayer.(*layers.IPv4)
+	if ipPkt.Protocol != layers.IPProtocol(OSPF_PROTO_ID) {
+		return nil
+	}
+	srcIp, _ := convertDotNotationToUint32(ipPkt.SrcIP.To4().String())
+	dstIp, _ := convertDotNotationToUint32(ipPkt.DstIP.To4().String())
+	var areaId uint32
+	ospfPkt := ipPkt.LayerPayload()
+	if len(ospfPkt) >= OSPF_HEADER_SIZE {
+		areaId = binary.BigEndian.Uint32(ospfPkt[8:12])
+	}
+	wire.mutex.Lock()
+	defer wire.mutex.Unlock()
+	if !wire.linkUp {
+		return nil
+	}
+	wire.deliver(data, func(rxHdl *virtualRxHandle) bool {
+		return rxHdl.accept(srcIp, dstIp, areaId)
+	})
+	return nil
+}
+
+/*
+The host stack of every router attached to the wire answers the
+ARP requests for its interface addresses.
+*/
+func (wire *VirtualWire) sendArp(data []byte, arp *layers.ARP) error {
+	if len(arp.SourceProtAddress) != net.IPv4len ||
+		len(arp.DstProtAddress) != net.IPv4len {
+		return errors.New("Invalid arp packet")
+	}
+	srcIp := binary.BigEndian.Uint32(arp.SourceProtAddress)
+	dstIp := binary.BigEndian.Uint32(arp.DstProtAddress)
+	wire.mutex.Lock()
+	defer wire.mutex.Unlock()
+	if !wire.linkUp {
+		return nil
+	}
+	wire.deliver(data, func(rxHdl *virtualRxHandle) bool {
+		return rxHdl.acceptArp(dstIp)
+	})
+	if arp.Operation != layers.ARPRequest {
+		return nil
+	}
+	for rxHdl, _ := range wire.rxHdls {
+		if rxHdl.params.IpAddr != dstIp ||
+			rxHdl.params.IfMacAddr == nil ||
+			rxHdl.params.IntfType == objects.INTF_TYPE_VIRTUAL {
+			continue
+		}
+		reply := buildVirtualWireArpReply(arp, rxHdl.params.IfMacAddr)
+		wire.deliver(reply, func(rxHdl *virtualRxHandle) bool {
+			return rxHdl.acceptArp(srcIp)
+		})
+		break
+	}
+	return nil
+}
+
+// Called with the wire lock held
+func (wire *VirtualWire) deliver(data []byte, accept func(*virtualRxHandle) bool) {
+	deliverAt := time.Now().Add(wire.delay)
+	for rxHdl, _ := range wire.rxHdls {
+		if !accept(rxHdl) {
+			continue
+		}
+		// Every receiver owns its copy, the rx path rewrites the headers
+		pktData := make([]byte, len(data))
+		copy(pktData, data)
+		select {
+		case rxHdl.pktCh <- virtualWireFrame{pktData, deliverAt}:
+		default:
+			// Receive queue full, the frame is lost as on a real link
+		}
+	}
+}
+
+func buildVirtualWireArpReply(req *layers.ARP, mac net.HardwareAddr) []byte {
+	ethLayer := layers.Ethernet{
+		SrcMAC:       mac,
+		DstMAC:       net.HardwareAddr(req.SourceHwAddress),
+		EthernetType: layers.EthernetTypeARP,
+	}
+	arpLayer := layers.ARP{
+		AddrType:          layers.LinkTypeEthernet,
+		Protocol:          layers.EthernetTypeIPv4,
+		HwAddressSize:     ARP_HW_ADDR_LEN,
+		ProtAddressSize:   uint8(net.IPv4len),
+		Operation:         layers.ARPReply,
+		SourceHwAddress:   []byte(mac),
+		SourceProtAddress: req.DstProtAddress,
+		DstHwAddress:      req.SourceHwAddress,
+		DstProtAddress:    req.SourceProtAddress,
+	}
+	buffer := gopacket.NewSerializeBuffer()
+	gopacket.SerializeLayers(buffer, gopacket.SerializeOptions{FixLengths: true},
+		&ethLayer, &arpLayer)
+	return buffer.Bytes()
+}
+
+func NewVirtualPktIO() *VirtualPktIO {
+	return &VirtualPktIO{
+		wireMap: make(map[string]*VirtualWire),
+	}
+}
+
+// Connect attaches the interface named ifName to the wire
+func (pktIO *VirtualPktIO) Connect(ifName string, wire *VirtualWire) {
+	pktIO.mutex.Lock()
+	pktIO.wireMap[ifName] = wire
+	pktIO.mutex.Unlock()
+}
+
+func (pktIO *VirtualPktIO) getWire(ifName string) (*VirtualWire, error) {
+	pktIO.mutex.Lock()
+	defer pktIO.mutex.Unlock()
+	wire, exist := pktIO.wireMap[ifName]
+	if !exist {
+		return nil, errors.New("No virtual wire connected to " + ifName)
+	}
+	return wire, nil
+}
+
+func (pktIO *VirtualPktIO) OpenTx(params PktIOParams) (PktTxHandle, error) {
+	wire, err := pktIO.getWire(params.IfName)
+	if err != nil {
+		return nil, err
+	}
+	return &virtualTxHandle{
+		wire: wire,
+	}, nil
+}
+
+func (pktIO *VirtualPktIO) OpenRx(params PktIOParams) (PktRxHandle, error) {
+	wire, err := pktIO.getWire(params.IfName)
+	if err != nil {
+		return nil, err
+	}
+	rxHdl := &virtualRxHandle{
+		wire:    wire,
+		params:  params,
+		pktCh:   make(chan virtualWireFrame, virtualWireRxQueueLen),
+		closeCh: make(chan bool),
+	}
+	wire.attach(rxHdl)
+	return rxHdl, nil
+}
+
+func (txHdl *virtualTxHandle) WritePacketData(data []byte) error {
+	return txHdl.wire.send(data)
+}
+
+func (txHdl *virtualTxHandle) Close() {
+}
+
+// Same filtering as the pcap receive handle
+func (rxHdl *virtualRxHandle) accept(srcIp, dstIp, areaId uint32) bool {
+	if rxHdl.params.IntfType == objects.INTF_TYPE_VIRTUAL {
+		return dstIp == rxHdl.params.IpAddr &&
+			srcIp == rxHdl.params.VirtNbrIpAddr &&
+			areaId == 0
+	}
+	if rxHdl.params.AreaId != 0 &&
+		dstIp == rxHdl.params.IpAddr && areaId == 0 {
+		return false
+	}
+	return srcIp != rxHdl.params.IpAddr
+}
+
+func (rxHdl *virtualRxHandle) acceptArp(dstIp uint32) bool {
+	return isStaticNbrIntf(rxHdl.params.IntfType) &&
+		dstIp == rxHdl.params.IpAddr
+}
+
+func (rxHdl *virtualRxHandle) ReadPacketData() ([]byte, gopacket.CaptureInfo, error) {
+	var frame virtualWireFrame
+	select {
+	case frame = <-rxHdl.pktCh:
+	case <-rxHdl.closeCh:
+		return nil, gopacket.CaptureInfo{}, io.EOF
+	}
+	select {
+	case <-time.After(frame.deliverAt.Sub(time.Now())):
+	case <-rxHdl.closeCh:
+		return nil, gopacket.CaptureInfo{}, io.EOF
+	}
+	ci := gopacket.CaptureInfo{
+		Timestamp:     frame.deliverAt,
+		CaptureLength: len(frame.data),
+		Length:        len(frame.data),
+	}
+	return frame.data, ci, nil
+}
+
+func (rxHdl *virtualRxHandle) Close() {
+	rxHdl.closeOnce.Do(func() {
+		rxHdl.wire.detach(rxHdl)
+		close(rxHdl.closeCh)
+	})
+}
@@ -0,0 +1,373 @@
+//
+//Copyright [2016] [SnapRoute Inc]
+//
+//Licensed under the Apache License, Version 2.0 (the "License");
+//you may not use this file except in compliance with the License.
+//You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+//       Unless required by applicable law or agreed to in writing, software
+//       distributed under the License is distributed on an "AS IS" BASIS,
+//       WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+//       See the License for the specific language governing permissions and
+//       limitations under the License.
+//
+// _______  __       __________   ___      _______.____    __    ____  __  .___________.  ______  __    __
+// |   ____||  |     |   ____\  \ /  /     /       |\   \  /  \  /   / |  | |           | /      ||  |  |  |
+// |  |__   |  |     |  |__   \  V  /     |   (----` \   \/    \/   /  |  | `---|  |----`|  ,----'|  |__|  |
+// |   __|  |  |     |   __|   >   <       \   \      \            /   |  |     |  |     |  |     |   __   |
+// |  |     |  `----.|  |____ /  .  \  .----)   |      \    /\    /    |  |     |  |     |  `----.|  |  |  |
+// |__|     |_______||_______/__/ \__\ |_______/        \__/  \__/     |__|     |__|      \______||__|  |__|
+//
+
+package server
+
+import (
+	"encoding/binary"
+	"github.com/google/gopacket"
+	"github.com/google/gopacket/layers"
+	"io"
+	"l3/ospfv2/objects"
+	"net"
+	"testing"
+	"time"
+)
+
+func buildTestOspfFrame(srcIp, dstIp string) []byte {
+	return buildTestOspfAreaFrame(srcIp, dstIp, 0)
+}
+
+func buildTestOspfAreaFrame(srcIp, dstIp string, areaId uint32) []byte {
+	eth := &layers.Ethernet{
+		SrcMAC:       net.HardwareAddr{0x00, 0x11, 0x22, 0x33, 0x44, 0x55},
+		DstMAC:       net.HardwareAddr{0x01, 0x00, 0x5e, 0x00, 0x00, 0x05},
+		EthernetType: layers.EthernetTypeIPv4,
+	}
+	ip := &layers.IPv4{
+		Version:  4,
+		TTL:      1,
+		Protocol: layers.IPProtocol(OSPF_PROTO_ID),
+		SrcIP:    net.ParseIP(srcIp).To4(),
+		DstIP:    net.ParseIP(dstIp).To4(),
+	}
+	buf := gopacket.NewSerializeBuffer()
+	opts := gopacket.SerializeOptions{
+		FixLengths:       true,
+		ComputeChecksums: true,
+	}
+	ospfHdr := make([]byte, OSPF_HEADER_SIZE)
+	ospfHdr[0] = OSPF_VERSION_2
+	ospfHdr[1] = HelloType
+	binary.BigEndian.PutUint16(ospfHdr[2:4], OSPF_HEADER_SIZE)
+	binary.BigEndian.PutUint32(ospfHdr[8:12], areaId)
+	gopacket.SerializeLayers(buf, opts, eth, ip, gopacket.Payload(ospfHdr))
+	return buf.Bytes()
+}
+
+func openTestHandles(t *testing.T, pktIO *VirtualPktIO, ifName, ipAddr string) (PktTxHandle, PktRxHandle) {
+	ip, _ := convertDotNotationToUint32(ipAddr)
+	params := PktIOParams{
+		IfName:   ifName,
+		IpAddr:   ip,
+		IntfType: objects.INTF_TYPE_BROADCAST,
+	}
+	txHdl, err := pktIO.OpenTx(params)
+	if err != nil {
+		t.Fatal("Unable to open tx handle on", ifName, err)
+	}
+	rxHdl, err := pktIO.OpenRx(params)
+	if err != nil {
+		t.Fatal("Unable to open rx handle on", ifName, err)
+	}
+	return txHdl, rxHdl
+}
+
+func readTestFrame(rxHdl PktRxHandle) ([]byte, bool) {
+	select {
+	case frame := <-rxHdl.(*virtualRxHandle).pktCh:
+		return frame.data, true
+	case <-time.After(100 * time.Millisecond):
+		return nil, false
+	}
+}
+
+func TestVirtualWireDelivery(t *testing.T) {
+	wire := NewVirtualWire()
+	pktIO1 := NewVirtualPktIO()
+	pktIO1.Connect("eth0", wire)
+	pktIO2 := NewVirtualPktIO()
+	pktIO2.Connect("eth0", wire)
+	txHdl1, rxHdl1 := openTestHandles(t, pktIO1, "eth0", "10.0.0.1")
+	_, rxHdl2 := openTestHandles(t, pktIO2, "eth0", "10.0.0.2")
+	defer rxHdl1.Close()
+	defer rxHdl2.Close()
+
+	frame := buildTestOspfFrame("10.0.0.1", AllSPFRouters)
+	err := txHdl1.WritePacketData(frame)
+	if err != nil {
+		t.Fatal("Unable to send frame", err)
+	}
+	data, ok := readTestFrame(rxHdl2)
+	if !ok {
+		t.Fatal("Frame not delivered to the other router")
+	}
+	if string(data) != string(frame) {
+		t.Error("Frame modified on the wire")
+	}
+	// The sender does not receive its own frames
+	_, ok = readTestFrame(rxHdl1)
+	if ok {
+		t.Error("Frame delivered back to the sender")
+	}
+}
+
+func TestVirtualWireLinkDown(t *testing.T) {
+	wire := NewVirtualWire()
+	pktIO1 := NewVirtualPktIO()
+	pktIO1.Connect("eth0", wire)
+	pktIO2 := NewVirtualPktIO()
+	pktIO2.Connect("eth0", wire)
+	txHdl1, rxHdl1 := openTestHandles(t, pktIO1, "eth0", "10.0.0.1")
+	_, rxHdl2 := openTestHandles(t, pktIO2, "eth0", "10.0.0.2")
+	defer rxHdl1.Close()
+	defer rxHdl2.Close()
+
+	wire.SetLinkUp(false)
+	txHdl1.WritePacketData(buildTestOspfFrame("10.0.0.1", AllSPFRouters))
+	_, ok := readTestFrame(rxHdl2)
+	if ok {
+		t.Error("Frame delivered while the link is down")
+	}
+	wire.SetLinkUp(true)
+	txHdl1.WritePacketData(buildTestOspfFrame("10.0.0.1", AllSPFRouters))
+	_, ok = readTestFrame(rxHdl2)
+	if !ok {
+		t.Error("Frame not delivered once the link is up")
+	}
+}
+
+func TestVirtualWireDelay(t *testing.T) {
+	wire := NewVirtualWire()
+	wire.SetDelay(50 * time.Millisecond)
+	pktIO1 := NewVirtualPktIO()
+	pktIO1.Connect("eth0", wire)
+	pktIO2 := NewVirtualPktIO()
+	pktIO2.Connect("eth0", wire)
+	txHdl1, rxHdl1 := openTestHandles(t, pktIO1, "eth0", "10.0.0.1")
+	_, rxHdl2 := openTestHandles(t, pktIO2, "eth0", "10.0.0.2")
+	defer rxHdl1.Close()
+	defer rxHdl2.Close()
+
+	sendTime := time.Now()
+	txHdl1.WritePacketData(buildTestOspfFrame("10.0.0.1", AllSPFRouters))
+	_, _, err := rxHdl2.ReadPacketData()
+	if err != nil {
+		t.Fatal("Unable to read frame", err)
+	}
+	if time.Since(sendTime) < 50*time.Millisecond {
+		t.Error("Frame delivered before the wire delay")
+	}
+}
+
+func TestVirtualWireVirtualLinkFilter(t *testing.T) {
+	wire := NewVirtualWire()
+	pktIO := NewVirtualPktIO()
+	pktIO.Connect("eth0", wire)
+	txHdl, rxHdl := openTestHandles(t, pktIO, "eth0", "10.0.0.1")
+	defer rxHdl.Close()
+
+	ip, _ := convertDotNotationToUint32("10.0.0.2")
+	nbrIp, _ := convertDotNotationToUint32("10.0.0.3")
+	vlRxHdl, err := pktIO.OpenRx(PktIOParams{
+		IfName:        "eth0",
+		IpAddr:        ip,
+		IntfType:      objects.INTF_TYPE_VIRTUAL,
+		VirtNbrIpAddr: nbrIp,
+	})
+	if err != nil {
+		t.Fatal("Unable to open virtual link rx handle", err)
+	}
+	defer vlRxHdl.Close()
+
+	txHdl.WritePacketData(buildTestOspfFrame("10.0.0.1", "10.0.0.2"))
+	_, ok := readTestFrame(vlRxHdl)
+	if ok {
+		t.Error("Frame from a non virtual neighbor delivered on the virtual link")
+	}
+	txHdl.WritePacketData(buildTestOspfFrame("10.0.0.3", "10.0.0.2"))
+	_, ok = readTestFrame(vlRxHdl)
+	if !ok {
+		t.Error("Frame from the virtual neighbor not delivered")
+	}
+	txHdl.WritePacketData(buildTestOspfAreaFrame("10.0.0.3", "10.0.0.2", 1))
+	_, ok = readTestFrame(vlRxHdl)
+	if ok {
+		t.Error("Transit area frame delivered on the virtual link")
+	}
+}
+
+func TestVirtualWirePhysicalFilter(t *testing.T) {
+	wire := NewVirtualWire()
+	pktIO := NewVirtualPktIO()
+	pktIO.Connect("eth0", wire)
+	txHdl, _ := openTestHandles(t, pktIO, "eth0", "10.0.0.3")
+
+	ip, _ := convertDotNotationToUint32("10.0.0.2")
+	rxHdl, err := pktIO.OpenRx(PktIOParams{
+		IfName:   "eth0",
+		IpAddr:   ip,
+		IntfType: objects.INTF_TYPE_BROADCAST,
+		AreaId:   1,
+	})
+	if err != nil {
+		t.Fatal("Unable to open rx handle", err)
+	}
+	defer rxHdl.Close()
+
+	// Virtual link packet unicast to the transit area interface
+	txHdl.WritePacketData(buildTestOspfAreaFrame("10.0.0.3", "10.0.0.2", 0))
+	_, ok := readTestFrame(rxHdl)
+	if ok {
+		t.Error("Virtual link frame delivered on the transit area interface")
+	}
+	txHdl.WritePacketData(buildTestOspfAreaFrame("10.0.0.3", "10.0.0.2", 1))
+	_, ok = readTestFrame(rxHdl)
+	if !ok {
+		t.Error("Unicast transit area frame not delivered")
+	}
+	txHdl.WritePacketData(buildTestOspfAreaFrame("10.0.0.3", AllSPFRouters, 1))
+	_, ok = readTestFrame(rxHdl)
+	if !ok {
+		t.Error("Multicast transit area frame not delivered")
+	}
+}
+
+func TestPcapRxFilter(t *testing.T) {
+	ip, _ := convertDotNotationToUint32("10.0.0.2")
+	nbrIp, _ := convertDotNotationToUint32("10.0.0.3")
+	filter := getPcapRxFilter(PktIOParams{
+		IpAddr:   ip,
+		IntfType: objects.INTF_TYPE_BROADCAST,
+	})
+	if filter != "proto ospf and not src host 10.0.0.2" {
+		t.Error("Unexpected backbone interface filter", filter)
+	}
+	filter = getPcapRxFilter(PktIOParams{
+		IpAddr:   ip,
+		IntfType: objects.INTF_TYPE_BROADCAST,
+		AreaId:   1,
+	})
+	if filter != "proto ospf and not src host 10.0.0.2 and "+
+		"not (dst host 10.0.0.2 and "+pcapOspfAreaId+" = 0)" {
+		t.Error("Unexpected transit area interface filter", filter)
+	}
+	filter = getPcapRxFilter(PktIOParams{
+		IpAddr:   ip,
+		IntfType: objects.INTF_TYPE_NBMA,
+	})
+	if filter != "(proto ospf and not src host 10.0.0.2) or arp dst host 10.0.0.2" {
+		t.Error("Unexpected NBMA interface filter", filter)
+	}
+	filter = getPcapRxFilter(PktIOParams{
+		IpAddr:        ip,
+		IntfType:      objects.INTF_TYPE_VIRTUAL,
+		VirtNbrIpAddr: nbrIp,
+	})
+	if filter != "proto ospf and dst host 10.0.0.2 and src host 10.0.0.3 and "+
+		pcapOspfAreaId+" = 0" {
+		t.Error("Unexpected virtual link filter", filter)
+	}
+}
+
+func TestMergedRxHandle(t *testing.T) {
+	wire1 := NewVirtualWire()
+	wire2 := NewVirtualWire()
+	pktIO := NewVirtualPktIO()
+	pktIO.Connect("eth0", wire1)
+	pktIO.Connect("eth1", wire2)
+	nbrPktIO := NewVirtualPktIO()
+	nbrPktIO.Connect("eth0", wire1)
+	nbrPktIO.Connect("eth1", wire2)
+	txHdl1, _ := openTestHandles(t, nbrPktIO, "eth0", "10.0.0.3")
+	txHdl2, _ := openTestHandles(t, nbrPktIO, "eth1", "10.0.1.3")
+
+	ip1, _ := convertDotNotationToUint32("10.0.0.2")
+	ip2, _ := convertDotNotationToUint32("10.0.1.2")
+	nbrIp, _ := convertDotNotationToUint32("10.0.0.3")
+	rxHdl, err := openMergedRxHandle(pktIO, []PktIOParams{
+		{IfName: "eth0", IpAddr: ip1, IntfType: objects.INTF_TYPE_VIRTUAL, VirtNbrIpAddr: nbrIp},
+		{IfName: "eth1", IpAddr: ip2, IntfType: objects.INTF_TYPE_VIRTUAL, VirtNbrIpAddr: nbrIp},
+	})
+	if err != nil {
+		t.Fatal("Unable to open merged rx handle", err)
+	}
+
+	readCh := make(chan error, 1)
+	read := func() {
+		_, _, err := rxHdl.ReadPacketData()
+		readCh <- err
+	}
+	// The virtual nbr sends over either of the equal cost paths
+	for _, txHdl := range []PktTxHandle{txHdl1, txHdl2} {
+		dstIp := "10.0.0.2"
+		if txHdl == txHdl2 {
+			dstIp = "10.0.1.2"
+		}
+		txHdl.WritePacketData(buildTestOspfFrame("10.0.0.3", dstIp))
+		go read()
+		select {
+		case err := <-readCh:
+			if err != nil {
+				t.Error("Unexpected read error", err)
+			}
+		case <-time.After(time.Second):
+			t.Error("Frame not delivered to", dstIp)
+		}
+	}
+	rxHdl.Close()
+	go read()
+	select {
+	case err := <-readCh:
+		if err != io.EOF {
+			t.Error("Expected io.EOF on a closed merged handle, got", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Read not released by close")
+	}
+}
+
+func TestVirtualPktIOUnconnected(t *testing.T) {
+	pktIO := NewVirtualPktIO()
+	_, err := pktIO.OpenTx(PktIOParams{IfName: "eth0"})
+	if err == nil {
+		t.Error("Tx handle opened on an unconnected interface")
+	}
+	_, err = pktIO.OpenRx(PktIOParams{IfName: "eth0"})
+	if err == nil {
+		t.Error("Rx handle opened on an unconnected interface")
+	}
+}
+
+func TestVirtualRxHandleClose(t *testing.T) {
+	wire := NewVirtualWire()
+	pktIO := NewVirtualPktIO()
+	pktIO.Connect("eth0", wire)
+	_, rxHdl := openTestHandles(t, pktIO, "eth0", "10.0.0.1")
+
+	errCh := make(chan error, 1)
+	go func() {
+		_, _, err := rxHdl.ReadPacketData()
+		errCh <- err
+	}()
+	rxHdl.Close()
+	rxHdl.Close()
+	select {
+	case err := <-errCh:
+		if err != io.EOF {
+			t.Error("Expected io.EOF on a closed rx handle, got", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("Read not unblocked by Close")
+	}
+}
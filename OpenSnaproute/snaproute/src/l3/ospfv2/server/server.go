@@ -54,6 +54,7 @@ type InitParams struct {
 	DbHdl     dbutils.DBIntf
 	ParamsDir string
 	DmnName   string
+	PktIO     PktIO // Defaults to pcap
 }
 
 type OSPFV2Server struct {
@@ -61,27 +62,44 @@ type OSPFV2Server struct {
 	dmnName        string
 	logger         logging.LoggerIntf
 	dbHdl          dbutils.DBIntf
+	pktIO          PktIO
 	ReqChan        chan *ServerRequest
 	ReplyChan      chan interface{}
 	InitCompleteCh chan bool
+	stopCh         chan bool
 
 	ribdComm  RibdCommStruct
 	asicdComm AsicdCommStruct
+	bfdComm   BfdCommStruct
 
 	infraData InfraStruct
 
-	globalData      GlobalStruct
-	IntfConfMap     map[IntfConfKey]IntfConf
-	NbrConfMap      map[NbrConfKey]NbrConf
-	AreaConfMap     map[uint32]AreaConf //Key AreaId
-	MessagingChData MessagingChStruct
+	globalData         GlobalStruct
+	IntfConfMap        map[IntfConfKey]IntfConf
+	NbrConfMap         map[NbrConfKey]NbrConf
+	AreaConfMap        map[uint32]AreaConf //Key AreaId
+	AreaRangeConfMap   map[AreaRangeKey]AreaRangeConf
+	AreaRangeMaskMap   map[uint32][]uint32 //Netmasks of the ranges of each area, longest first
+	VirtualLinkConfMap map[VirtualLinkKey]VirtualLinkConf
+	StaticNbrConfMap   map[NbrConfKey]StaticNbrConf
+	RedistConfMap      map[string]RedistConf
+	RouteMapConfMap    map[RouteMapKey]RouteMapConf
+	MessagingChData    MessagingChStruct
 
 	NbrConfData    NbrStruct
 	LsdbData       LsdbStruct
 	FloodData      FloodStruct
 	SPFData        SPFStruct
 	RoutingTblData RoutingTblStruct
+	RedistData     RedistStruct
 	SummaryLsDb    map[LsdbKey]SummaryLsaMap
+	GrData         GracefulRestartStruct
+
+	SpfThrottleData SpfThrottleStruct
+	StubRouterData  StubRouterStruct
+	PktStatsData    PktStatsStruct
+	NbrRetxData     NbrRetxStruct
+	ArpCacheData    ArpCacheStruct
 
 	GetBulkData GetBulkStruct
 }
@@ -93,11 +111,28 @@ func NewOspfv2Server(initParams InitParams) (*OSPFV2Server, error) {
 	server.dbHdl = initParams.DbHdl
 	server.dmnName = initParams.DmnName
 	server.paramsDir = initParams.ParamsDir
+	server.pktIO = initParams.PktIO
+	if server.pktIO == nil {
+		server.pktIO = NewPcapPktIO()
+	}
 	server.ReqChan = make(chan *ServerRequest)
 	server.ReplyChan = make(chan interface{})
 	server.InitCompleteCh = make(chan bool)
+	server.stopCh = make(chan bool)
 	server.IntfConfMap = make(map[IntfConfKey]IntfConf)
 	server.AreaConfMap = make(map[uint32]AreaConf)
+	server.AreaRangeConfMap = make(map[AreaRangeKey]AreaRangeConf)
+	server.AreaRangeMaskMap = make(map[uint32][]uint32)
+	server.VirtualLinkConfMap = make(map[VirtualLinkKey]VirtualLinkConf)
+	server.StaticNbrConfMap = make(map[NbrConfKey]StaticNbrConf)
+	server.RedistConfMap = make(map[string]RedistConf)
+	server.RouteMapConfMap = make(map[RouteMapKey]RouteMapConf)
+	server.initRedistData()
+	server.initGracefulRestartData()
+	server.initSpfThrottleData()
+	server.initStubRouterData()
+	server.initPktStatsData()
+	server.initArpCacheData()
 	return &server, nil
 }
 
@@ -122,6 +157,8 @@ func (server *OSPFV2Server) ConnectToServers() {
 			server.ConnectToRibdServer(client.Port)
 		} else if client.Name == "asicd" {
 			server.ConnectToAsicdServer(client.Port)
+		} else if client.Name == "bfdd" {
+			server.StartBfdClient(client.Port)
 		}
 	}
 }
@@ -129,6 +166,7 @@ func (server *OSPFV2Server) ConnectToServers() {
 func (server *OSPFV2Server) StartSubscribers() {
 	server.StartAsicdSubscriber()
 	server.StartRibdSubscriber()
+	server.StartBfdSubscriber()
 }
 
 func (server *OSPFV2Server) initMessagingChData() {
@@ -143,13 +181,21 @@ func (server *OSPFV2Server) initMessagingChData() {
 	server.MessagingChData.NbrFSMToLsdbChData.NbrDeadMsgCh = make(chan NbrDeadMsg)
 	server.MessagingChData.LsdbToFloodChData.LsdbToFloodLSACh = make(chan []LsdbToFloodLSAMsg)
 	server.MessagingChData.NbrFSMToFloodChData.LsaFloodCh = make(chan NbrToFloodMsg)
-	server.MessagingChData.LsdbToSPFChData.StartSPF = make(chan bool)
+	server.MessagingChData.LsdbToSPFChData.StartSPF = make(chan SpfCalcMsg)
 	server.MessagingChData.SPFToLsdbChData.DoneSPF = make(chan bool)
 	server.MessagingChData.ServerToLsdbChData.RefreshLsdbSliceCh = make(chan bool)
 	server.MessagingChData.ServerToLsdbChData.RouteInfoDataUpdateCh = make(chan RouteInfoDataUpdateMsg)
 	server.MessagingChData.ServerToLsdbChData.InitAreaLsdbCh = make(chan uint32)
+	server.MessagingChData.ServerToLsdbChData.AreaRangeUpdateCh = make(chan bool)
+	server.MessagingChData.ServerToLsdbChData.VirtualLinkUpdateCh = make(chan bool)
+	server.MessagingChData.ServerToLsdbChData.GracefulRestartExitCh = make(chan bool, 1)
+	server.MessagingChData.ServerToLsdbChData.OpaqueLsaUpdateCh = make(chan OpaqueLsaMsg)
+	server.MessagingChData.ServerToLsdbChData.MaximumPathsUpdateCh = make(chan bool)
+	server.MessagingChData.ServerToLsdbChData.SpfThrottleUpdateCh = make(chan SpfThrottleMsg)
+	server.MessagingChData.ServerToLsdbChData.StubRouterUpdateCh = make(chan StubRouterMsg)
 	server.MessagingChData.LsdbToServerChData.InitAreaLsdbDoneCh = make(chan bool)
 	server.MessagingChData.LsdbToServerChData.RefreshLsdbSliceDoneCh = make(chan bool)
+	server.MessagingChData.LsdbToServerChData.VirtualLinkChangeCh = make(chan VirtualLinkChangeMsg, 10)
 	server.MessagingChData.RouteTblToDBClntChData.RouteAddMsgCh = make(chan RouteAddMsg, 100)
 	server.MessagingChData.RouteTblToDBClntChData.RouteDelMsgCh = make(chan RouteDelMsg, 100)
 	server.MessagingChData.ServerToDBClntChData.FlushRouteFromDBCh = make(chan bool)
@@ -162,8 +208,12 @@ func (server *OSPFV2Server) SigHandler(sigChan <-chan os.Signal) {
 	switch signal {
 	case syscall.SIGHUP:
 		server.logger.Debug("Received SIGHUP signal")
-		server.SendFlushRouteMsgToDBClnt()
-		<-server.MessagingChData.DBClntToServerChData.FlushRouteFromDBDoneCh
+		// Keep the routes in place if the restart can be graceful
+		server.GrData.PrepareRestartCh <- true
+		if !<-server.GrData.PrepareRestartDoneCh {
+			server.SendFlushRouteMsgToDBClnt()
+			<-server.MessagingChData.DBClntToServerChData.FlushRouteFromDBDoneCh
+		}
 		debug.PrintStack()
 		var memStat runtime.MemStats
 		runtime.ReadMemStats(&memStat)
@@ -182,6 +232,7 @@ func (server *OSPFV2Server) initServer() error {
 	server.initMessagingChData()
 	server.initAsicdComm()
 	server.initRibdComm()
+	server.initBfdComm()
 	server.ConnectToServers()
 	server.StartSubscribers()
 	server.initInfra()
@@ -193,6 +244,7 @@ func (server *OSPFV2Server) initServer() error {
 		server.logger.Err("DB Handle is nil")
 		return errors.New("DB Handle is nil")
 	}
+	server.initGracefulRestart()
 	go server.StartDBClient()
 	return nil
 }
@@ -230,6 +282,114 @@ func (server *OSPFV2Server) handleRPCRequest(req *ServerRequest) {
 			retObj.BulkInfo, retObj.Err = server.getBulkAreaState(val.FromIdx, val.Count)
 		}
 		server.ReplyChan <- interface{}(&retObj)
+	case CREATE_OSPFV2_AREA_RANGE:
+		var retObj CreateConfigOutArgs
+		if val, ok := req.Data.(*CreateOspfv2AreaRangeInArgs); ok {
+			retObj.RetVal, retObj.Err = server.createAreaRange(val.Cfg)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case UPDATE_OSPFV2_AREA_RANGE:
+		var retObj UpdateConfigOutArgs
+		if val, ok := req.Data.(*UpdateOspfv2AreaRangeInArgs); ok {
+			retObj.RetVal, retObj.Err = server.updateAreaRange(val.NewCfg, val.OldCfg, val.AttrSet)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case DELETE_OSPFV2_AREA_RANGE:
+		var retObj DeleteConfigOutArgs
+		if val, ok := req.Data.(*DeleteOspfv2AreaRangeInArgs); ok {
+			retObj.RetVal, retObj.Err = server.deleteAreaRange(val.Cfg)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case CREATE_OSPFV2_VIRTUAL_LINK:
+		var retObj CreateConfigOutArgs
+		if val, ok := req.Data.(*CreateOspfv2VirtualLinkInArgs); ok {
+			retObj.RetVal, retObj.Err = server.createVirtualLink(val.Cfg)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case UPDATE_OSPFV2_VIRTUAL_LINK:
+		var retObj UpdateConfigOutArgs
+		if val, ok := req.Data.(*UpdateOspfv2VirtualLinkInArgs); ok {
+			retObj.RetVal, retObj.Err = server.updateVirtualLink(val.NewCfg, val.OldCfg, val.AttrSet)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case DELETE_OSPFV2_VIRTUAL_LINK:
+		var retObj DeleteConfigOutArgs
+		if val, ok := req.Data.(*DeleteOspfv2VirtualLinkInArgs); ok {
+			retObj.RetVal, retObj.Err = server.deleteVirtualLink(val.Cfg)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case CREATE_OSPFV2_INTF_AUTH_KEY:
+		var retObj CreateConfigOutArgs
+		if val, ok := req.Data.(*CreateOspfv2IntfAuthKeyInArgs); ok {
+			retObj.RetVal, retObj.Err = server.createIntfAuthKey(val.Cfg)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case UPDATE_OSPFV2_INTF_AUTH_KEY:
+		var retObj UpdateConfigOutArgs
+		if val, ok := req.Data.(*UpdateOspfv2IntfAuthKeyInArgs); ok {
+			retObj.RetVal, retObj.Err = server.updateIntfAuthKey(val.NewCfg, val.OldCfg, val.AttrSet)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case DELETE_OSPFV2_INTF_AUTH_KEY:
+		var retObj DeleteConfigOutArgs
+		if val, ok := req.Data.(*DeleteOspfv2IntfAuthKeyInArgs); ok {
+			retObj.RetVal, retObj.Err = server.deleteIntfAuthKey(val.Cfg)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case CREATE_OSPFV2_NBR:
+		var retObj CreateConfigOutArgs
+		if val, ok := req.Data.(*CreateOspfv2NbrInArgs); ok {
+			retObj.RetVal, retObj.Err = server.createStaticNbr(val.Cfg)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case UPDATE_OSPFV2_NBR:
+		var retObj UpdateConfigOutArgs
+		if val, ok := req.Data.(*UpdateOspfv2NbrInArgs); ok {
+			retObj.RetVal, retObj.Err = server.updateStaticNbr(val.NewCfg, val.OldCfg, val.AttrSet)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case DELETE_OSPFV2_NBR:
+		var retObj DeleteConfigOutArgs
+		if val, ok := req.Data.(*DeleteOspfv2NbrInArgs); ok {
+			retObj.RetVal, retObj.Err = server.deleteStaticNbr(val.Cfg)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case CREATE_OSPFV2_REDISTRIBUTION:
+		var retObj CreateConfigOutArgs
+		if val, ok := req.Data.(*CreateOspfv2RedistributionInArgs); ok {
+			retObj.RetVal, retObj.Err = server.createRedistribution(val.Cfg)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case UPDATE_OSPFV2_REDISTRIBUTION:
+		var retObj UpdateConfigOutArgs
+		if val, ok := req.Data.(*UpdateOspfv2RedistributionInArgs); ok {
+			retObj.RetVal, retObj.Err = server.updateRedistribution(val.NewCfg, val.OldCfg, val.AttrSet)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case DELETE_OSPFV2_REDISTRIBUTION:
+		var retObj DeleteConfigOutArgs
+		if val, ok := req.Data.(*DeleteOspfv2RedistributionInArgs); ok {
+			retObj.RetVal, retObj.Err = server.deleteRedistribution(val.Cfg)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case CREATE_OSPFV2_ROUTE_MAP:
+		var retObj CreateConfigOutArgs
+		if val, ok := req.Data.(*CreateOspfv2RouteMapInArgs); ok {
+			retObj.RetVal, retObj.Err = server.createRouteMap(val.Cfg)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case UPDATE_OSPFV2_ROUTE_MAP:
+		var retObj UpdateConfigOutArgs
+		if val, ok := req.Data.(*UpdateOspfv2RouteMapInArgs); ok {
+			retObj.RetVal, retObj.Err = server.updateRouteMap(val.NewCfg, val.OldCfg, val.AttrSet)
+		}
+		server.ReplyChan <- interface{}(&retObj)
+	case DELETE_OSPFV2_ROUTE_MAP:
+		var retObj DeleteConfigOutArgs
+		if val, ok := req.Data.(*DeleteOspfv2RouteMapInArgs); ok {
+			retObj.RetVal, retObj.Err = server.deleteRouteMap(val.Cfg)
+		}
+		server.ReplyChan <- interface{}(&retObj)
 	case CREATE_OSPFV2_GLOBAL:
 		var retObj CreateConfigOutArgs
 		if val, ok := req.Data.(*CreateOspfv2GlobalInArgs); ok {
@@ -325,9 +485,22 @@ func (server *OSPFV2Server) StartOspfv2Server() {
 		panic(err)
 	}
 	server.InitCompleteCh <- true
+	server.serverLoop()
+}
+
+/*
+Stops the server and DB client routines, ospf has to be
+administratively disabled first
+*/
+func (server *OSPFV2Server) stopServer() {
+	close(server.stopCh)
+}
 
+func (server *OSPFV2Server) serverLoop() {
 	for {
 		select {
+		case <-server.stopCh:
+			return
 		case req := <-server.ReqChan:
 			server.logger.Debug("Handling RPC Req", req)
 			server.handleRPCRequest(req)
@@ -344,6 +517,24 @@ func (server *OSPFV2Server) StartOspfv2Server() {
 			server.logger.Debug("Done Process Rib Rx Buf", ribRxBuf)
 		case <-server.ribdComm.ribdSubSocketErrCh:
 			server.logger.Err("Invalid Message from Ribd")
+		case bfdRxBuf := <-server.bfdComm.bfdSubSocketCh:
+			server.logger.Debug("Process Bfd Rx Buf", bfdRxBuf)
+			server.processBfdNotification(bfdRxBuf)
+			server.logger.Debug("Done Process Bfd Rx Buf", bfdRxBuf)
+		case <-server.bfdComm.bfdSubSocketErrCh:
+			server.logger.Err("Invalid Message from Bfdd")
+		case msg := <-server.MessagingChData.LsdbToServerChData.VirtualLinkChangeCh:
+			server.processVirtualLinkChange(msg)
+		case <-server.GrData.PrepareRestartCh:
+			if !server.prepareGracefulRestart() {
+				server.GrData.PrepareRestartDoneCh <- false
+			}
+		case <-server.GrData.GraceLsaTxCh:
+			server.processGraceLsaTxTimer()
+		case reason := <-server.GrData.RestartExitCh:
+			server.exitGracefulRestart(reason)
+		case <-server.StubRouterData.StartupTimeCh:
+			server.processStubRouterStartupTimer()
 		case <-server.GetBulkData.SliceRefreshCh:
 			server.logger.Debug("Refresh IntfConf Slice")
 			server.RefreshIntfConfSlice()
@@ -49,6 +49,24 @@ const (
 	GET_BULK_OSPFV2_NBR_STATE
 	GET_OSPFV2_LSDB_STATE
 	GET_BULK_OSPFV2_LSDB_STATE
+	CREATE_OSPFV2_AREA_RANGE
+	UPDATE_OSPFV2_AREA_RANGE
+	DELETE_OSPFV2_AREA_RANGE
+	CREATE_OSPFV2_VIRTUAL_LINK
+	UPDATE_OSPFV2_VIRTUAL_LINK
+	DELETE_OSPFV2_VIRTUAL_LINK
+	CREATE_OSPFV2_NBR
+	UPDATE_OSPFV2_NBR
+	DELETE_OSPFV2_NBR
+	CREATE_OSPFV2_REDISTRIBUTION
+	UPDATE_OSPFV2_REDISTRIBUTION
+	DELETE_OSPFV2_REDISTRIBUTION
+	CREATE_OSPFV2_ROUTE_MAP
+	UPDATE_OSPFV2_ROUTE_MAP
+	DELETE_OSPFV2_ROUTE_MAP
+	CREATE_OSPFV2_INTF_AUTH_KEY
+	UPDATE_OSPFV2_INTF_AUTH_KEY
+	DELETE_OSPFV2_INTF_AUTH_KEY
 )
 
 type ServerRequest struct {
@@ -104,6 +122,20 @@ type GetBulkOspfv2AreaStateOutArgs struct {
 	Err      error
 }
 
+type CreateOspfv2AreaRangeInArgs struct {
+	Cfg *objects.Ospfv2AreaRange
+}
+
+type UpdateOspfv2AreaRangeInArgs struct {
+	OldCfg  *objects.Ospfv2AreaRange
+	NewCfg  *objects.Ospfv2AreaRange
+	AttrSet []bool
+}
+
+type DeleteOspfv2AreaRangeInArgs struct {
+	Cfg *objects.Ospfv2AreaRange
+}
+
 type CreateOspfv2GlobalInArgs struct {
 	Cfg *objects.Ospfv2Global
 }
@@ -146,6 +178,76 @@ type DeleteOspfv2IntfInArgs struct {
 	Cfg *objects.Ospfv2Intf
 }
 
+type CreateOspfv2VirtualLinkInArgs struct {
+	Cfg *objects.Ospfv2VirtualLink
+}
+
+type UpdateOspfv2VirtualLinkInArgs struct {
+	OldCfg  *objects.Ospfv2VirtualLink
+	NewCfg  *objects.Ospfv2VirtualLink
+	AttrSet []bool
+}
+
+type DeleteOspfv2VirtualLinkInArgs struct {
+	Cfg *objects.Ospfv2VirtualLink
+}
+
+type CreateOspfv2IntfAuthKeyInArgs struct {
+	Cfg *objects.Ospfv2IntfAuthKey
+}
+
+type UpdateOspfv2IntfAuthKeyInArgs struct {
+	OldCfg  *objects.Ospfv2IntfAuthKey
+	NewCfg  *objects.Ospfv2IntfAuthKey
+	AttrSet []bool
+}
+
+type DeleteOspfv2IntfAuthKeyInArgs struct {
+	Cfg *objects.Ospfv2IntfAuthKey
+}
+
+type CreateOspfv2NbrInArgs struct {
+	Cfg *objects.Ospfv2Nbr
+}
+
+type UpdateOspfv2NbrInArgs struct {
+	OldCfg  *objects.Ospfv2Nbr
+	NewCfg  *objects.Ospfv2Nbr
+	AttrSet []bool
+}
+
+type DeleteOspfv2NbrInArgs struct {
+	Cfg *objects.Ospfv2Nbr
+}
+
+type CreateOspfv2RedistributionInArgs struct {
+	Cfg *objects.Ospfv2Redistribution
+}
+
+type UpdateOspfv2RedistributionInArgs struct {
+	OldCfg  *objects.Ospfv2Redistribution
+	NewCfg  *objects.Ospfv2Redistribution
+	AttrSet []bool
+}
+
+type DeleteOspfv2RedistributionInArgs struct {
+	Cfg *objects.Ospfv2Redistribution
+}
+
+type CreateOspfv2RouteMapInArgs struct {
+	Cfg *objects.Ospfv2RouteMap
+}
+
+type UpdateOspfv2RouteMapInArgs struct {
+	OldCfg  *objects.Ospfv2RouteMap
+	NewCfg  *objects.Ospfv2RouteMap
+	AttrSet []bool
+}
+
+type DeleteOspfv2RouteMapInArgs struct {
+	Cfg *objects.Ospfv2RouteMap
+}
+
 type GetOspfv2IntfStateInArgs struct {
 	IpAddr           uint32
 	AddressLessIfIdx uint32
@@ -27,16 +27,28 @@ import ()
 
 type DHCPRelayGlobal struct {
 	baseObj
-	Vrf           string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"w", MULTIPLICITY:"1", AUTOCREATE: "true", DESCRIPTION: "VRF id for DHCPv4 Relay Agent global config", DEFAULT:"default"`
+	Vrf           string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"w", MULTIPLICITY:"*", DESCRIPTION: "VRF id for DHCPv4 Relay Agent global config", DEFAULT:"default"`
 	Enable        bool   `DESCRIPTION: "Global level config for enabling/disabling the Relay Agent", DEFAULT:"false"`
 	HopCountLimit int32  `DESCRIPTION: "Hop Count Limit", DEFAULT:"32"`
+	Snooping      bool   `DESCRIPTION: "Enable DHCP snooping, server messages received on interfaces configured as untrusted are dropped", DEFAULT:"false"`
+	ServerVrf     string `DESCRIPTION: "VRF in which the DHCP Servers are reachable, Servers are in the relay VRF when not set. In another VRF the relay address is taken from the Server VRF and the Link Selection and Server Identifier Override sub-options carry the client link", DEFAULT:""`
 }
 
 type DHCPRelayIntf struct {
 	baseObj
-	IntfRef  string   `SNAPROUTE: "KEY", CATEGORY:"L3",  ACCESS:"w", MULTIPLICITY:"*", DESCRIPTION:"DHCP Client facing interface reference for which Relay Agent needs to be configured"`
-	Enable   bool     `DESCRIPTION: "Interface level config for enabling/disabling the relay agent"`
-	ServerIp []string `DESCRIPTION: "DHCP Server(s) where relay agent can relay client dhcp requests"`
+	IntfRef               string   `SNAPROUTE: "KEY", CATEGORY:"L3",  ACCESS:"w", MULTIPLICITY:"*", DESCRIPTION:"DHCP Client facing interface reference for which Relay Agent needs to be configured"`
+	Enable                bool     `DESCRIPTION: "Interface level config for enabling/disabling the relay agent"`
+	ServerIp              []string `DESCRIPTION: "DHCP Server(s) where relay agent can relay client dhcp requests"`
+	RelayAgentInfo        bool     `DESCRIPTION: "Insert Relay Agent Information (Option 82) in client requests relayed to the server", DEFAULT:"false"`
+	CircuitIdType         string   `DESCRIPTION: "Value carried in the Circuit-ID sub-option", SELECTION: IntfName/Vlan, DEFAULT:"IntfName"`
+	RemoteId              string   `DESCRIPTION: "Value carried in the Remote-ID sub-option, switch mac address is used when not set", DEFAULT:""`
+	RelayAgentInfoPolicy  string   `DESCRIPTION: "Action for client requests which already carry Relay Agent Information", SELECTION: Keep/Replace/Drop, DEFAULT:"Replace"`
+	ServerSelection       string   `DESCRIPTION: "Policy for choosing the DHCP Server(s) a client discover is relayed to", SELECTION: BroadcastAll/PrimaryBackup/RoundRobin/HashByMac, DEFAULT:"BroadcastAll"`
+	ServerTimeout         int32    `DESCRIPTION: "Time in seconds without a response after which a DHCP Server is considered down", MIN:"1", MAX:"300", DEFAULT:"5"`
+	Vrf                   string   `DESCRIPTION: "VRF of the interface, the interface is relayed by the Relay Agent of this VRF", DEFAULT:"default"`
+	RateLimit             int32    `DESCRIPTION: "Maximum client packets per second relayed from the interface, 0 disables the limit", MIN:"0", DEFAULT:"0"`
+	ClientRateLimit       int32    `DESCRIPTION: "Maximum packets per second relayed for a client mac address on the interface, 0 disables the limit", MIN:"0", DEFAULT:"0"`
+	RelayAgentInfoTrusted bool     `DESCRIPTION: "Apply Relay Agent Information config on requests already relayed by a downstream relay agent (giaddr set), such requests are forwarded unmodified when not trusted", DEFAULT:"false"`
 }
 
 type DHCPRelayClientState struct {
@@ -55,39 +67,92 @@ type DHCPRelayClientState struct {
 	ServerAck       string `DESCRIPTION: "Most recent time stamp of server ack message"`
 	ServerRequests  int32  `DESCRIPTION: "Total Number of requests relayed to server"`
 	ServerResponses int32  `DESCRIPTION: "Total Number of responses received from server"`
+	Vrf             string `DESCRIPTION: "VRF of the interface on which the client was learnt"`
 }
 
 type DHCPRelayIntfState struct {
 	baseObj
-	IntfRef           string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"r", MULTIPLICITY:"*", DESCRIPTION: "Interface for which state is required to be collected"`
-	TotalDrops        int32  `DESCRIPTION: "Total number of DHCP Packets dropped by relay agent"`
-	TotalDhcpClientRx int32  `DESCRIPTION: "Total number of client requests that came to relay agent"`
-	TotalDhcpClientTx int32  `DESCRIPTION: "Total number of client responses send out by relay agent"`
-	TotalDhcpServerRx int32  `DESCRIPTION: "Total number of server requests made by relay agent"`
-	TotalDhcpServerTx int32  `DESCRIPTION: "Total number of server responses received by relay agent"`
+	IntfRef                string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"r", MULTIPLICITY:"*", DESCRIPTION: "Interface for which state is required to be collected"`
+	TotalDrops             int32  `DESCRIPTION: "Total number of DHCP Packets dropped by relay agent"`
+	TotalDhcpClientRx      int32  `DESCRIPTION: "Total number of client requests that came to relay agent"`
+	TotalDhcpClientTx      int32  `DESCRIPTION: "Total number of client responses send out by relay agent"`
+	TotalDhcpServerRx      int32  `DESCRIPTION: "Total number of server requests made by relay agent"`
+	TotalDhcpServerTx      int32  `DESCRIPTION: "Total number of server responses received by relay agent"`
+	RelayAgentInfoInserted int32  `DESCRIPTION: "Total number of client requests in which Relay Agent Information was inserted"`
+	RelayAgentInfoKept     int32  `DESCRIPTION: "Total number of client requests relayed with existing Relay Agent Information"`
+	RelayAgentInfoReplaced int32  `DESCRIPTION: "Total number of client requests in which existing Relay Agent Information was replaced"`
+	RelayAgentInfoDropped  int32  `DESCRIPTION: "Total number of client requests dropped as they carried Relay Agent Information"`
+	RelayAgentInfoStripped int32  `DESCRIPTION: "Total number of server responses from which Relay Agent Information was stripped"`
+	HopCountDrops          int32  `DESCRIPTION: "Total number of client requests dropped as they exceeded the hop count limit"`
+	SnoopingDrops          int32  `DESCRIPTION: "Total number of server messages dropped as they were received on an untrusted interface"`
+	ServerSelection        string `DESCRIPTION: "DHCP Server selection policy in use on the interface"`
+	Vrf                    string `DESCRIPTION: "VRF of the interface"`
+	RateLimitDrops         int32  `DESCRIPTION: "Total number of client requests dropped as they exceeded the interface rate limit"`
+	ClientRateLimitDrops   int32  `DESCRIPTION: "Total number of client requests dropped as they exceeded the client rate limit"`
+	LoopDrops              int32  `DESCRIPTION: "Total number of client requests dropped as their GIAddr was the address of the interface"`
 }
 
 type DHCPRelayIntfServerState struct {
 	baseObj
-	IntfRef   string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"r", MULTIPLICITY:"1", DESCRIPTION: "Interface Index for which state is required to be collected"`
-	ServerIp  string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"r", MULTIPLICITY:"1", DESCRIPTION: "Server IP on the interface for which state is required to be collected"`
-	Request   int32  `DESCRIPTION: "Total number of requests to Server"`
-	Responses int32  `DESCRIPTION: "Total number of responses from Server"`
+	IntfRef      string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"r", MULTIPLICITY:"1", DESCRIPTION: "Interface Index for which state is required to be collected"`
+	ServerIp     string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"r", MULTIPLICITY:"1", DESCRIPTION: "Server IP on the interface for which state is required to be collected"`
+	Request      int32  `DESCRIPTION: "Total number of requests to Server"`
+	Responses    int32  `DESCRIPTION: "Total number of responses from Server"`
+	Health       string `DESCRIPTION: "Liveness of the Server derived from its responses, Up or Down"`
+	LastResponse string `DESCRIPTION: "Time at which the last response was received from the Server"`
+	Vrf          string `DESCRIPTION: "VRF in which the Server is reached"`
+}
+
+type DHCPRelayBindingState struct {
+	baseObj
+	MacAddr     string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"r", MULTIPLICITY:"*", DESCRIPTION: "Host Hardware/Mac Address"`
+	XId         string `SNAPROUTE: "KEY", CATEGORY:"L3", DESCRIPTION: "Transaction id of the DHCP exchange"`
+	IntfRef     string `DESCRIPTION: "Client facing interface on which the host was learnt"`
+	Vlan        int32  `DESCRIPTION: "Vlan id of the client facing interface, 0 if the interface is not a vlan"`
+	OfferedIp   string `DESCRIPTION: "Ip Address offered by DHCP Server"`
+	AcceptedIp  string `DESCRIPTION: "Ip Address acknowledged by DHCP Server"`
+	LeaseTime   int32  `DESCRIPTION: "Lease time in seconds granted by DHCP Server"`
+	LeaseExpiry string `DESCRIPTION: "Time at which the binding expires, in seconds since the Unix epoch"`
+	State       string `DESCRIPTION: "State of the binding"`
+	Vrf         string `DESCRIPTION: "VRF of the client facing interface"`
+}
+
+type DHCPRelaySnoopingIntf struct {
+	baseObj
+	IntfRef string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"w", MULTIPLICITY:"*", DESCRIPTION: "Interface reference for which DHCP snooping trust needs to be configured"`
+	Trusted bool   `DESCRIPTION: "DHCP server messages received on the interface are accepted when snooping is enabled", DEFAULT:"false"`
+}
+
+type DHCPRelaySnoopingBindingState struct {
+	baseObj
+	MacAddr     string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"r", MULTIPLICITY:"*", DESCRIPTION: "Host Hardware/Mac Address"`
+	IpAddr      string `DESCRIPTION: "Ip Address acknowledged by DHCP Server"`
+	Vlan        int32  `DESCRIPTION: "Vlan id of the client facing interface, 0 if the interface is not a vlan"`
+	IntfRef     string `DESCRIPTION: "Client facing interface, the snooping interface the client request was received on if any"`
+	LeaseTime   int32  `DESCRIPTION: "Lease time in seconds granted by DHCP Server"`
+	LeaseExpiry string `DESCRIPTION: "Time at which the binding expires, in seconds since the Unix epoch"`
 }
 
 type DHCPv6RelayGlobal struct {
 	baseObj
-	Vrf           string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"w", MULTIPLICITY:"1", AUTOCREATE: "true", DESCRIPTION: "VRF id for DHCPv6 Relay Agent global config", DEFAULT:"default"`
+	Vrf           string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"w", MULTIPLICITY:"*", DESCRIPTION: "VRF id for DHCPv6 Relay Agent global config", DEFAULT:"default"`
 	Enable        bool   `DESCRIPTION: "Global level config for enabling/disabling the Relay Agent", DEFAULT:"false"`
 	HopCountLimit int32  `DESCRIPTION: "Hop Count Limit", DEFAULT:"32"`
+	ServerVrf     string `DESCRIPTION: "VRF in which the DHCP Servers are reachable, Servers are in the relay VRF when not set", DEFAULT:""`
 }
 
 type DHCPv6RelayIntf struct {
 	baseObj
-	IntfRef       string   `SNAPROUTE: "KEY", CATEGORY:"L3",  ACCESS:"w", MULTIPLICITY:"*", DESCRIPTION:"DHCP Client facing interface reference for which Relay Agent needs to be configured""`
-	Enable        bool     `DESCRIPTION: "Interface level config for enabling/disabling the relay agent"`
-	ServerIp      []string `DESCRIPTION: "DHCP Server(s) where relay agent can relay client dhcp requests"`
-	UpstreamIntfs []string `DESCRIPTION: "DHCP Server facing interfaces where Relay Forward messages are multicasted"`
+	IntfRef               string   `SNAPROUTE: "KEY", CATEGORY:"L3",  ACCESS:"w", MULTIPLICITY:"*", DESCRIPTION:"DHCP Client facing interface reference for which Relay Agent needs to be configured""`
+	Enable                bool     `DESCRIPTION: "Interface level config for enabling/disabling the relay agent"`
+	ServerIp              []string `DESCRIPTION: "DHCP Server(s) where relay agent can relay client dhcp requests"`
+	UpstreamIntfs         []string `DESCRIPTION: "DHCP Server facing interfaces where Relay Forward messages are multicasted"`
+	InterfaceId           bool     `DESCRIPTION: "Insert Interface-ID (option 18) carrying the interface name in Relay Forward messages", DEFAULT:"false"`
+	RemoteId              string   `DESCRIPTION: "Value carried in the Remote-ID (option 37) of Relay Forward messages, option is not inserted when not set", DEFAULT:""`
+	RemoteIdEnterpriseNum int32    `DESCRIPTION: "Vendor enterprise number carried in the Remote-ID option", DEFAULT:"0"`
+	Vrf                   string   `DESCRIPTION: "VRF of the interface, the interface is relayed by the Relay Agent of this VRF", DEFAULT:"default"`
+	RateLimit             int32    `DESCRIPTION: "Maximum client packets per second relayed from the interface, 0 disables the limit", MIN:"0", DEFAULT:"0"`
+	ClientRateLimit       int32    `DESCRIPTION: "Maximum packets per second relayed for a client mac address on the interface, 0 disables the limit", MIN:"0", DEFAULT:"0"`
 }
 
 type DHCPv6RelayClientState struct {
@@ -113,16 +178,24 @@ type DHCPv6RelayClientState struct {
 	ServerReconfigure string `DESCRIPTION: "Most recent time stamp of server reconfigure message"`
 	ServerRequests    int32  `DESCRIPTION: "Total Number of requests relayed to server"`
 	ServerResponses   int32  `DESCRIPTION: "Total Number of responses received from server"`
+	Vrf               string `DESCRIPTION: "VRF of the interface on which the client was learnt"`
 }
 
 type DHCPv6RelayIntfState struct {
 	baseObj
-	IntfRef           string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"r", MULTIPLICITY:"*", DESCRIPTION: "Interface for which state is required to be collected"`
-	TotalDrops        int32  `DESCRIPTION: "Total number of DHCP Packets dropped by relay agent"`
-	TotalDhcpClientRx int32  `DESCRIPTION: "Total number of client requests that came to relay agent"`
-	TotalDhcpClientTx int32  `DESCRIPTION: "Total number of client responses send out by relay agent"`
-	TotalDhcpServerRx int32  `DESCRIPTION: "Total number of server requests made by relay agent"`
-	TotalDhcpServerTx int32  `DESCRIPTION: "Total number of server responses received by relay agent"`
+	IntfRef               string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"r", MULTIPLICITY:"*", DESCRIPTION: "Interface for which state is required to be collected"`
+	TotalDrops            int32  `DESCRIPTION: "Total number of DHCP Packets dropped by relay agent"`
+	TotalDhcpClientRx     int32  `DESCRIPTION: "Total number of client requests that came to relay agent"`
+	TotalDhcpClientTx     int32  `DESCRIPTION: "Total number of client responses send out by relay agent"`
+	TotalDhcpServerRx     int32  `DESCRIPTION: "Total number of server requests made by relay agent"`
+	TotalDhcpServerTx     int32  `DESCRIPTION: "Total number of server responses received by relay agent"`
+	HopCountDrops         int32  `DESCRIPTION: "Total number of relay forward messages dropped as they exceeded the hop count limit"`
+	InterfaceIdInserted   int32  `DESCRIPTION: "Total number of relay forward messages in which Interface-ID was inserted"`
+	RemoteIdInserted      int32  `DESCRIPTION: "Total number of relay forward messages in which Remote-ID was inserted"`
+	InterfaceIdMismatches int32  `DESCRIPTION: "Total number of relay reply messages whose Interface-ID did not match the interface learnt for the peer"`
+	Vrf                   string `DESCRIPTION: "VRF of the interface"`
+	RateLimitDrops        int32  `DESCRIPTION: "Total number of client messages dropped as they exceeded the interface rate limit"`
+	ClientRateLimitDrops  int32  `DESCRIPTION: "Total number of client messages dropped as they exceeded the client rate limit"`
 }
 
 type DHCPv6RelayIntfServerState struct {
@@ -131,4 +204,18 @@ type DHCPv6RelayIntfServerState struct {
 	ServerIp  string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"r", MULTIPLICITY:"1", DESCRIPTION: "Server IP on the interface for which state is required to be collected"`
 	Request   int32  `DESCRIPTION: "Total number of requests to Server"`
 	Responses int32  `DESCRIPTION: "Total number of responses from Server"`
+	Vrf       string `DESCRIPTION: "VRF in which the Server is reached"`
+}
+
+type DHCPv6RelayPDState struct {
+	baseObj
+	Prefix            string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"r", MULTIPLICITY:"*", DESCRIPTION: "Prefix delegated to the requesting router"`
+	Vrf               string `SNAPROUTE: "KEY", CATEGORY:"L3", DESCRIPTION: "VRF of the interface on which the requesting router is reachable, the route is installed in this VRF"`
+	IntfRef           string `DESCRIPTION: "Interface on which the requesting router is reachable"`
+	NextHopIp         string `DESCRIPTION: "Address of the requesting router used as next hop of the delegated prefix"`
+	ClientMacAddr     string `DESCRIPTION: "Mac address of the requesting router"`
+	PreferredLifetime int32  `DESCRIPTION: "Preferred lifetime of the delegated prefix in seconds"`
+	ValidLifetime     int32  `DESCRIPTION: "Valid lifetime of the delegated prefix in seconds"`
+	LeaseExpiry       string `DESCRIPTION: "Time at which the delegated prefix expires, in seconds since the Unix epoch"`
+	RouteInstalled    bool   `DESCRIPTION: "Route for the delegated prefix is installed in RIB"`
 }
@@ -25,11 +25,25 @@ package objects
 
 type Ospfv2Global struct {
 	ConfigObj
-	Vrf                string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"w", MULTIPLICITY:"1", AUTOCREATE: "true", DESCRIPTION: "VRF id for OSPF global config", DEFAULT:"default"`
-	RouterId           string `DESCRIPTION: A 32-bit integer uniquely identifying the router in the Autonomous System. By convention, to ensure uniqueness, this should default to the value of one of the router's IP interface addresses.  This object is persistent and when written the entity SHOULD save the change to non-volatile storage., DEFAULT:"0.0.0.0"`
-	AdminState         string `DESCRIPTION: Indicates if OSPF is enabled globally., DEFAULT:"DOWN"`
-	ASBdrRtrStatus     bool   `DESCRIPTION: A flag to note whether this router is configured as an Autonomous System Border Router.  This object is persistent and when written the entity SHOULD save the change to non-volatile storage., DEFAULT:false`
-	ReferenceBandwidth uint32 `DESCRIPTION: "Reference bandwidth in kilobits/second for calculating default interface metrics. Unit: Mbps", MIN: 100, MAX: 2147483647, DEFAULT: 100`
+	Vrf                      string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"w", MULTIPLICITY:"1", AUTOCREATE: "true", DESCRIPTION: "VRF id for OSPF global config", DEFAULT:"default"`
+	RouterId                 string `DESCRIPTION: A 32-bit integer uniquely identifying the router in the Autonomous System. By convention, to ensure uniqueness, this should default to the value of one of the router's IP interface addresses.  This object is persistent and when written the entity SHOULD save the change to non-volatile storage., DEFAULT:"0.0.0.0"`
+	AdminState               string `DESCRIPTION: Indicates if OSPF is enabled globally., DEFAULT:"DOWN"`
+	ASBdrRtrStatus           bool   `DESCRIPTION: A flag to note whether this router is configured as an Autonomous System Border Router.  This object is persistent and when written the entity SHOULD save the change to non-volatile storage., DEFAULT:false`
+	ReferenceBandwidth       uint32 `DESCRIPTION: "Reference bandwidth in kilobits/second for calculating default interface metrics. Unit: Mbps", MIN: 100, MAX: 2147483647, DEFAULT: 100`
+	RestartSupport           string `DESCRIPTION: The router's support for OSPF graceful restart. When plannedOnly, the router originates Grace-LSAs and preserves its installed routes across a planned restart of the daemon., SELECTION: none/plannedOnly, DEFAULT:"none"`
+	RestartInterval          uint32 `DESCRIPTION: Configured OSPF graceful restart timeout interval (grace period) in seconds advertised in Grace-LSAs., MIN: 1, MAX: 1800, DEFAULT: 120`
+	RestartHelperSupport     bool   `DESCRIPTION: Indicates if this router acts as a graceful restart helper for neighbors that advertise a Grace-LSA., DEFAULT:true`
+	RestartStrictLsaChecking bool   `DESCRIPTION: Indicates if strict LSA checking is enabled for graceful restart. When enabled, helper mode is terminated on a change to the link state database that would be flooded to the restarting router., DEFAULT:true`
+	MaximumPaths             uint8  `DESCRIPTION: Maximum number of equal cost paths installed in the routing table for a destination., MIN: 1, MAX: 32, DEFAULT: 8`
+	SpfInitialWait           uint32 `DESCRIPTION: Delay in milliseconds between the first topology change after a quiet period and the SPF calculation., MIN: 0, MAX: 60000, DEFAULT: 50`
+	SpfHoldWait              uint32 `DESCRIPTION: Minimum delay in milliseconds between two consecutive SPF calculations. The delay is doubled on every calculation up to SpfMaxWait while the topology keeps changing., MIN: 0, MAX: 60000, DEFAULT: 200`
+	SpfMaxWait               uint32 `DESCRIPTION: Maximum delay in milliseconds between two consecutive SPF calculations., MIN: 0, MAX: 60000, DEFAULT: 5000`
+	PassiveIntfDefault       bool   `DESCRIPTION: Indicates if OSPF interfaces are passive unless configured otherwise on the interface., DEFAULT:false`
+	StubRouterAdmin          bool   `DESCRIPTION: Indicates if the router advertises itself as a stub router (RFC 6987). All non-stub links of the router LSAs are advertised with the maximum metric so that the router is not used for transit traffic., DEFAULT:false`
+	StubRouterOnStartup      uint32 `DESCRIPTION: Time in seconds for which the router advertises itself as a stub router after OSPF is enabled. 0 disables stub router advertisement on startup unless StubRouterWaitForBgp is set., MIN: 0, MAX: 86400, DEFAULT: 0`
+	StubRouterWaitForBgp     bool   `DESCRIPTION: Indicates if the stub router advertisement on startup ends once BGP has converged. BGP is considered converged once its routes have not changed for 10 seconds. The advertisement ends after StubRouterOnStartup seconds (600 seconds if not set) at the latest., DEFAULT:false`
+	StubRouterSummaryLsa     bool   `DESCRIPTION: Indicates if summary LSAs are also advertised with the maximum metric while the router is a stub router., DEFAULT:false`
+	StubRouterExternalLsa    bool   `DESCRIPTION: Indicates if AS External and NSSA LSAs are also advertised with the maximum metric while the router is a stub router., DEFAULT:false`
 }
 
 type Ospfv2GlobalState struct {
@@ -46,20 +60,38 @@ type Ospfv2GlobalState struct {
 	NumOfSummary4LSA   uint32 `DESCRIPTION: Number of Summary 4 LSAs.`
 	NumOfASExternalLSA uint32 `DESCRIPTION: Number of ASExternal LSAs.`
 	NumOfRoutes        uint32 `DESCRIPTION: Number of Routes (Unsupported).`
+	RestartStatus      string `DESCRIPTION: Current status of OSPF graceful restart., SELECTION: notRestarting/plannedRestart`
+	RestartAge         uint32 `DESCRIPTION: Remaining time in seconds of the current OSPF graceful restart interval.`
+	RestartExitReason  string `DESCRIPTION: Describes the outcome of the last attempt at a graceful restart., SELECTION: none/inProgress/completed/timedOut/topologyChanged`
+	StubRouterStatus   string `DESCRIPTION: Indicates if the router currently advertises itself as a stub router and why., SELECTION: none/administrative/onStartup`
+	StubRouterTimeLeft uint32 `DESCRIPTION: Remaining time in seconds of the stub router advertisement on startup.`
 }
 
 type Ospfv2Area struct {
 	ConfigObj
-	AreaId         string `SNAPROUTE: "KEY", CATEGORY:"L3",  ACCESS:"w", MULTIPLICITY:"*", DESCRIPTION: A 32-bit integer uniquely identifying an area. Area ID 0.0.0.0 is used for the OSPF backbone.`
-	AdminState     string `DESCRIPTION: Indicates if OSPF is enabled on this area, DEFAULT:"DOWN"`
-	AuthType       string `DESCRIPTION: The authentication type specified for an area., SELECTION: none(0)/simplePassword(1)/md5(2), DEFAULT:"None"`
-	ImportASExtern bool   `DESCRIPTION: ExternalRoutingCapability if false AS External LSA will not be flooded into this area, DEFAULT: true`
+	AreaId               string `SNAPROUTE: "KEY", CATEGORY:"L3",  ACCESS:"w", MULTIPLICITY:"*", DESCRIPTION: A 32-bit integer uniquely identifying an area. Area ID 0.0.0.0 is used for the OSPF backbone.`
+	AdminState           string `DESCRIPTION: Indicates if OSPF is enabled on this area, DEFAULT:"DOWN"`
+	AuthType             string `DESCRIPTION: The authentication type specified for an area., SELECTION: none(0)/simplePassword(1)/md5(2), DEFAULT:"None"`
+	ImportASExtern       bool   `DESCRIPTION: ExternalRoutingCapability if false AS External LSA will not be flooded into this area, DEFAULT: true`
+	AreaType             string `DESCRIPTION: Type of the area. Stub and totally stub areas do not carry AS External LSAs, totally stub areas additionally do not carry Type-3 summaries other than the default. NSSA areas carry Type-7 LSAs instead of AS External LSAs, SELECTION: normal/stub/totallystub/nssa, DEFAULT:"normal"`
+	StubDefaultCost      int32  `DESCRIPTION: Metric of the default route advertised by an area border router into a stub, totally stub or NSSA area, MIN:"1", MAX:"16777215", DEFAULT:"1"`
+	NssaTranslatorRole   string `DESCRIPTION: Type-7 to AS External LSA translation role of an NSSA border router. Always translates unconditionally and sets the Nt bit in the router-LSA, candidate translates only when elected among the reachable NSSA border routers, SELECTION: candidate/always, DEFAULT:"candidate"`
+	NssaDefaultOriginate bool   `DESCRIPTION: If true an NSSA border router originates a Type-7 default into the NSSA, DEFAULT: false`
+}
+
+type Ospfv2AreaRange struct {
+	ConfigObj
+	AreaId    string `SNAPROUTE: "KEY", CATEGORY:"L3",  ACCESS:"w", MULTIPLICITY:"*", DESCRIPTION: The area the address range is configured on.`
+	IpPrefix  string `SNAPROUTE: "KEY", CATEGORY:"L3",  DESCRIPTION: The IP prefix of the address range.`
+	Netmask   string `SNAPROUTE: "KEY", CATEGORY:"L3",  DESCRIPTION: The network mask of the address range.`
+	Advertise bool   `DESCRIPTION: If true a single summary LSA is advertised for the range into other areas otherwise the intra-area routes falling in the range are not advertised at all, DEFAULT: true`
+	Cost      int32  `DESCRIPTION: Metric of the summary LSA advertised for the range. If 0 the highest cost of the component routes is used, MIN:"0", MAX:"16777215", DEFAULT:"0"`
 }
 
 type Ospfv2AreaState struct {
 	ConfigObj
-	AreaId string `SNAPROUTE: "KEY", CATEGORY:"L3",  ACCESS:"r", MULTIPLICITY:"*", DESCRIPTION: A 32-bit integer uniquely identifying an area. Area ID 0.0.0.0 is used for the OSPF backbone.`
-	//NumSpfRuns       uint32 `DESCRIPTION: The number of times that the intra-area route table has been calculated using this area's link state database.  This is typically done using Dijkstra's algorithm.  Discontinuities in the value of this counter can occur at re-initialization of the management system, and at other times as indicated by the value of ospfDiscontinuityTime.`
+	AreaId     string `SNAPROUTE: "KEY", CATEGORY:"L3",  ACCESS:"r", MULTIPLICITY:"*", DESCRIPTION: A 32-bit integer uniquely identifying an area. Area ID 0.0.0.0 is used for the OSPF backbone.`
+	NumSpfRuns uint32 `DESCRIPTION: The number of times that the intra-area route table has been calculated using this area's link state database.  This is typically done using Dijkstra's algorithm.  Discontinuities in the value of this counter can occur at re-initialization of the management system, and at other times as indicated by the value of ospfDiscontinuityTime.`
 	//NumBdrRtr        uint32 `DESCRIPTION: The total number of Area Border Routers reachable within this area.  This is initially zero and is calculated in each Shortest Path First (SPF) pass.`
 	//NumAsBdrRtr      uint32 `DESCRIPTION: The total number of Autonomous System Border Routers reachable within this area.  This is initially zero and is calculated in each SPF pass.`
 	NumOfRouterLSA     uint32 `DESCRIPTION: Number of Router LSA in a given Area`
@@ -71,6 +103,9 @@ type Ospfv2AreaState struct {
 	NumOfLSA           uint32 `DESCRIPTION: Number of LSAs in a given Area.`
 	NumOfNbrs          uint32 `DESCRIPTION: Number of Neighbors in a given Area`
 	NumOfRoutes        uint32 `DESCRIPTION: Number of Routes in a given Area (Unsupported).`
+	NumPartialSpfRuns  uint32 `DESCRIPTION: The number of times that the inter-area and external routes have been recalculated for this area without running Dijkstra's algorithm, following a change of summary or external LSAs only.`
+	LastSpfDuration    uint32 `DESCRIPTION: Duration in microseconds of the last route calculation for this area.`
+	LastSpfTrigger     string `DESCRIPTION: Reason of the last route calculation for this area., SELECTION: none/routerLsa/networkLsa/summaryLsa/asExternalLsa/nssaLsa/nbrDown/areaChange/areaRangeChange/virtualLinkChange/maximumPathsChange/gracefulRestartExit`
 }
 type Ospfv2Intf struct {
 	ConfigObj
@@ -78,13 +113,71 @@ type Ospfv2Intf struct {
 	AddressLessIfIdx uint32 `SNAPROUTE: "KEY", CATEGORY:"L3",  DESCRIPTION: For the purpose of easing the instancing of addressed and addressless interfaces; this variable takes the value 0 on interfaces with IP addresses and the corresponding value of ifIndex for interfaces having no IP address., MIN: 0, MAX: 2147483647`
 	AdminState       string `DESCRIPTION: Indiacates if OSPF is enabled on this interface, DEFAULT:"DOWN"`
 	AreaId           string `DESCRIPTION: A 32-bit integer uniquely identifying the area to which the interface connects.  Area ID 0.0.0.0 is used for the OSPF backbone., DEFAULT:"0.0.0.0"`
-	Type             string `DESCRIPTION: The OSPF interface type. By way of a default, this field may be intuited from the corresponding value of ifType. Broadcast LANs, such as Ethernet and IEEE 802.5, take the value 'broadcast', X.25 and similar technologies take the value 'nbma', and links that are definitively point to point take the value 'pointToPoint'., SELECTION: Broadcast/PointToPoint, DEFAULT:"Broadcast"`
-	RtrPriority      uint8  `DESCRIPTION: The priority of this interface.  Used in multi-access networks, this field is used in the designated router election algorithm.  The value 0 signifies that the router is not eligible to become the designated router on this particular network.  In the event of a tie in this value, routers will use their Router ID as a tie breaker., MIN: 0, MAX: 255, DEFAULT:1`
-	TransitDelay     uint16 `DESCRIPTION: The estimated number of seconds it takes to transmit a link state update packet over this interface.  Note that the minimal value SHOULD be 1 second., MIN: 0, MAX: 3600, DEFAULT:1`
+	Type             string `DESCRIPTION: The OSPF interface type. By way of a default, this field may be intuited from the corresponding value of ifType. Broadcast LANs, such as Ethernet and IEEE 802.5, take the value 'broadcast', X.25 and similar technologies take the value 'nbma', and links that are definitively point to point take the value 'pointToPoint'., SELECTION: Broadcast/PointToPoint/NBMA/PointToMultipoint, DEFAULT:"Broadcast"`
+	RtrPriority      uint8  `DESCRIPTION: The priority of this interface.  Used in multi-access networks, this field is used in the designated router election algorithm.  The value 0 signifies that the router is not eligible to become the designated router on this particular network.  In the event of a tie in this value, routers will use their Router ID as a tie breaker., MIN: 0, MAX: 255, DEFAULT:"1"`
+	TransitDelay     uint16 `DESCRIPTION: The estimated number of seconds it takes to transmit a link state update packet over this interface.  Note that the minimal value SHOULD be 1 second., MIN: 0, MAX: 3600, DEFAULT:"1"`
 	RetransInterval  uint16 `DESCRIPTION: The number of seconds between link state advertisement retransmissions, for adjacencies belonging to this interface.  This value is also used when retransmitting  database description and Link State request packets. Note that minimal value SHOULD be 1 second., MIN: 0, MAX:3600, DEFAULT:5`
 	HelloInterval    uint16 `DESCRIPTION: The length of time, in seconds, between the Hello packets that the router sends on the interface.  This value must be the same for all routers attached to a common network., MIN: 1, MAX: 65535, DEFAULT:10`
 	RtrDeadInterval  uint32 `DESCRIPTION: The number of seconds that a router's Hello packets have not been seen before its neighbors declare the router down. This should be some multiple of the Hello interval.  This value must be the same for all routers attached to a common network., MIN: 0, MAX: 2147483647, DEFAULT:40`
 	MetricValue      uint16 `DESCRIPTION: The metric of using this Type of Service on this interface.  The default value of the TOS 0 metric is 10^8 / ifSpeed., MIN: 0, MAX: 65535, DEFAULT:10`
+	AuthKey          string `DESCRIPTION: The authentication key used on this interface. For simplePassword areas this is the password (up to 8 characters), for md5 areas the secret key (up to 16 characters)., DEFAULT:""`
+	AuthKeyId        uint8  `DESCRIPTION: The Key ID identifying the secret key used to generate the message digest on md5 areas., MIN: 0, MAX: 255, DEFAULT:"1"`
+	PollInterval     uint32 `DESCRIPTION: The larger time interval, in seconds, between the Hello packets sent to an inactive non-broadcast multi-access neighbor., MIN: 1, MAX: 2147483647, DEFAULT:120`
+	BfdEnable        bool   `DESCRIPTION: Indicates if BFD sessions are established with the neighbors on this interface. A neighbor is declared down as soon as its BFD session goes down instead of waiting for RtrDeadInterval to expire., DEFAULT:false`
+	Passive          string `DESCRIPTION: A passive interface is advertised as a stub link in the router-LSA but does not send or receive Hello packets and never forms adjacencies. Default follows PassiveIntfDefault of the global OSPF config., SELECTION: Default/True/False, DEFAULT:"Default"`
+}
+
+type Ospfv2IntfAuthKey struct {
+	ConfigObj
+	IpAddress        string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"w", MULTIPLICITY:"*", DESCRIPTION: The IP address of the OSPF interface the key belongs to.`
+	AddressLessIfIdx uint32 `SNAPROUTE: "KEY", CATEGORY:"L3", DESCRIPTION: On an interface having an IP address, zero. On addressless interfaces, the corresponding value of ifIndex in the Internet Standard MIB., MIN: 0, MAX: 2147483647`
+	KeyId            uint8  `SNAPROUTE: "KEY", CATEGORY:"L3", DESCRIPTION: The Key ID identifying the secret key in the message digest of md5 areas., MIN: 0, MAX: 255`
+	Key              string `DESCRIPTION: The md5 secret key (up to 16 characters). Received packets using this Key ID are accepted as soon as the key is configured.`
+	GenerateDelay    uint32 `DESCRIPTION: The number of seconds after configuration before the key is used to generate message digests. The most recently activated key is used for sending, which allows a key rollover without dropping adjacencies., MIN: 0, MAX: 2147483647, DEFAULT:0`
+}
+
+type Ospfv2Nbr struct {
+	ConfigObj
+	IpAddr           string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"w", MULTIPLICITY:"*", DESCRIPTION: The IP address of the neighbor on an NBMA or point-to-multipoint interface. Hello packets are unicast to the configured neighbors of those interfaces.`
+	AddressLessIfIdx uint32 `SNAPROUTE: "KEY", CATEGORY:"L3", DESCRIPTION: On an interface having an IP address, zero. On addressless interfaces, the corresponding value of ifIndex in the Internet Standard MIB., MIN: 0, MAX: 2147483647`
+	RtrPriority      uint8  `DESCRIPTION: The priority of this neighbor in the designated router election algorithm. The value 0 signifies that the neighbor is not eligible to become the designated router on this particular network., MIN: 0, MAX: 255, DEFAULT:"1"`
+}
+
+type Ospfv2VirtualLink struct {
+	ConfigObj
+	AreaId          string `SNAPROUTE: "KEY", CATEGORY:"L3",  ACCESS:"w", MULTIPLICITY:"*", DESCRIPTION: The transit area the virtual link traverses. The backbone and stub areas cannot be used as transit area.`
+	NbrRouterId     string `SNAPROUTE: "KEY", CATEGORY:"L3",  DESCRIPTION: The Router ID of the virtual neighbor.`
+	AdminState      string `DESCRIPTION: Indiacates if the virtual link is enabled, DEFAULT:"DOWN"`
+	TransitDelay    uint16 `DESCRIPTION: The estimated number of seconds it takes to transmit a link state update packet over the virtual link., MIN: 0, MAX: 3600, DEFAULT:"1"`
+	RetransInterval uint16 `DESCRIPTION: The number of seconds between link state advertisement retransmissions for the adjacency over the virtual link. This should be well over the expected round trip delay between the two endpoints., MIN: 0, MAX:3600, DEFAULT:5`
+	HelloInterval   uint16 `DESCRIPTION: The length of time, in seconds, between the Hello packets that the router sends on the virtual link. This value must be the same on both endpoints., MIN: 1, MAX: 65535, DEFAULT:10`
+	RtrDeadInterval uint32 `DESCRIPTION: The number of seconds that the virtual neighbor's Hello packets have not been seen before it is declared down. This value must be the same on both endpoints., MIN: 0, MAX: 2147483647, DEFAULT:60`
+	AuthKey         string `DESCRIPTION: The authentication key used on the virtual link. The authentication type is the one of the backbone area., DEFAULT:""`
+	AuthKeyId       uint8  `DESCRIPTION: The Key ID identifying the secret key used to generate the message digest on md5 backbone., MIN: 0, MAX: 255, DEFAULT:"1"`
+}
+
+type Ospfv2Redistribution struct {
+	ConfigObj
+	Protocol   string `SNAPROUTE: "KEY", CATEGORY:"L3",  ACCESS:"w", MULTIPLICITY:"*", DESCRIPTION: The source of the routes redistributed into OSPF as AS External LSAs. If no source is configured every route published by ribd is redistributed., SELECTION: CONNECTED/STATIC/BGP`
+	Metric     int32  `DESCRIPTION: Metric of the AS External LSAs originated for the routes of this source. If 0 the metric of the route is used, MIN:"0", MAX:"16777215", DEFAULT:"0"`
+	MetricType string `DESCRIPTION: Type of the external metric advertised for the routes of this source, SELECTION: E1/E2, DEFAULT:"E2"`
+	Tag        int32  `DESCRIPTION: External route tag of the AS External LSAs originated for the routes of this source, MIN:"0", MAX:"2147483647", DEFAULT:"0"`
+	RouteMap   string `DESCRIPTION: Name of the route map filtering the routes of this source. If empty all the routes of this source are redistributed, DEFAULT:""`
+}
+
+type Ospfv2RouteMap struct {
+	ConfigObj
+	Name                string `SNAPROUTE: "KEY", CATEGORY:"L3",  ACCESS:"w", MULTIPLICITY:"*", DESCRIPTION: Name of the route map.`
+	Sequence            int32  `SNAPROUTE: "KEY", CATEGORY:"L3",  DESCRIPTION: Sequence number of the entry, entries are evaluated in increasing sequence order and the first matching entry is applied. Routes matching no entry are not redistributed., MIN:"1", MAX:"65535"`
+	Action              string `DESCRIPTION: Whether the routes matching this entry are redistributed, SELECTION: Permit/Deny, DEFAULT:"Permit"`
+	MatchIpPrefix       string `DESCRIPTION: IP prefix the route has to match. If empty the prefix is not matched, DEFAULT:""`
+	MatchNetmask        string `DESCRIPTION: Network mask of the prefix the route has to match, DEFAULT:"0.0.0.0"`
+	MatchLongerPrefixes bool   `DESCRIPTION: If true the routes more specific than the prefix also match otherwise only the exact prefix matches, DEFAULT:false`
+	MatchTag            int32  `DESCRIPTION: Tag the route has to match, routes carry the tag of their redistribution source. If 0 the tag is not matched, MIN:"0", MAX:"2147483647", DEFAULT:"0"`
+	SetMetric           int32  `DESCRIPTION: Metric advertised for the matching routes. If 0 the metric is not changed, MIN:"0", MAX:"16777215", DEFAULT:"0"`
+	SetMetricType       string `DESCRIPTION: Type of the external metric advertised for the matching routes. If empty the metric type is not changed, SELECTION: E1/E2, DEFAULT:""`
+	SetTag              int32  `DESCRIPTION: External route tag advertised for the matching routes. If 0 the tag is not changed, MIN:"0", MAX:"2147483647", DEFAULT:"0"`
+	SetFwdAddr          string `DESCRIPTION: Forwarding address advertised for the matching routes. If empty the forwarding address is not changed, DEFAULT:""`
 }
 
 type Ospfv2IntfState struct {
@@ -108,15 +201,62 @@ type Ospfv2IntfState struct {
 	Cost                     uint32 `DESCRIPTION: Cost for a given Interface.`
 	NumOfStateChange         uint32 `DESCRIPTION: Number of FSM State Change.`
 	TimeOfStateChange        string `DESCRIPTION: Last time stamp Intf FSM State Change.`
+	NumOfAuthTypeMismatch    uint32 `DESCRIPTION: Number of packets dropped because of authentication type mismatch.`
+	NumOfAuthFailures        uint32 `DESCRIPTION: Number of packets dropped because of invalid password or message digest.`
+	NumOfAuthReplayDrops     uint32 `DESCRIPTION: Number of packets dropped because of decreasing cryptographic sequence number.`
+	NumOfHelloRx             uint32 `DESCRIPTION: Number of Hello packets received.`
+	NumOfHelloTx             uint32 `DESCRIPTION: Number of Hello packets sent.`
+	NumOfDbdRx               uint32 `DESCRIPTION: Number of Database Description packets received.`
+	NumOfDbdTx               uint32 `DESCRIPTION: Number of Database Description packets sent.`
+	NumOfLsReqRx             uint32 `DESCRIPTION: Number of Link State Request packets received.`
+	NumOfLsReqTx             uint32 `DESCRIPTION: Number of Link State Request packets sent.`
+	NumOfLsUpdRx             uint32 `DESCRIPTION: Number of Link State Update packets received.`
+	NumOfLsUpdTx             uint32 `DESCRIPTION: Number of Link State Update packets sent.`
+	NumOfLsAckRx             uint32 `DESCRIPTION: Number of Link State Acknowledgment packets received.`
+	NumOfLsAckTx             uint32 `DESCRIPTION: Number of Link State Acknowledgment packets sent.`
+	NumOfRetransmits         uint32 `DESCRIPTION: Number of retransmitted Database Description packets.`
+	NumOfMtuMismatch         uint32 `DESCRIPTION: Number of Database Description packets dropped because of interface MTU mismatch.`
+	NumOfAreaMismatch        uint32 `DESCRIPTION: Number of packets dropped because of Area ID mismatch.`
+	NumOfHelloIntvlMismatch  uint32 `DESCRIPTION: Number of Hello packets dropped because of Hello Interval mismatch.`
+	NumOfDeadIntvlMismatch   uint32 `DESCRIPTION: Number of Hello packets dropped because of Router Dead Interval mismatch.`
+	NumOfBadChecksum         uint32 `DESCRIPTION: Number of packets dropped because of invalid Ospf checksum.`
 }
 
 type Ospfv2NbrState struct {
 	ConfigObj
-	IpAddr           string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"r", MULTIPLICITY:"*",  DESCRIPTION: The IP address this neighbor is using in its IP source address.  Note that, on addressless links, this will not be 0.0.0.0 but the  address of another of the neighbor's interfaces.`
-	AddressLessIfIdx uint32 `SNAPROUTE: "KEY", CATEGORY:"L3",  DESCRIPTION: On an interface having an IP address, zero. On addressless interfaces, the corresponding value of ifIndex in the Internet Standard MIB. On row creation, this can be derived from the instance., MIN:0, MAX: 2147483647`
-	RtrId            string `DESCRIPTION: A 32-bit integer (represented as a type IpAddress) uniquely identifying the neighboring router in the Autonomous System.`
-	Options          int32  `DESCRIPTION: A bit mask corresponding to the neighbor's options field.  Bit 0, if set, indicates that the system will operate on Type of Service metrics other than TOS 0.  If zero, the neighbor will ignore all metrics except the TOS 0 metric.  Bit 1, if set, indicates that the associated area accepts and operates on external information; if zero, it is a stub area.  Bit 2, if set, indicates that the system is capable of routing IP multicast datagrams, that is that it implements the multicast extensions to OSPF.  Bit 3, if set, indicates that the associated area is an NSSA.  These areas are capable of carrying type-7 external advertisements, which are translated into type-5 external advertisements at NSSA borders.`
-	State            string `DESCRIPTION: The state of the relationship with this neighbor., SELECTION: exchangeStart(5)/loading(7)/attempt(2)/exchange(6)/down(1)/init(3)/full(8)/twoWay(4)`
+	IpAddr                  string `SNAPROUTE: "KEY", CATEGORY:"L3", ACCESS:"r", MULTIPLICITY:"*",  DESCRIPTION: The IP address this neighbor is using in its IP source address.  Note that, on addressless links, this will not be 0.0.0.0 but the  address of another of the neighbor's interfaces.`
+	AddressLessIfIdx        uint32 `SNAPROUTE: "KEY", CATEGORY:"L3",  DESCRIPTION: On an interface having an IP address, zero. On addressless interfaces, the corresponding value of ifIndex in the Internet Standard MIB. On row creation, this can be derived from the instance., MIN:0, MAX: 2147483647`
+	RtrId                   string `DESCRIPTION: A 32-bit integer (represented as a type IpAddress) uniquely identifying the neighboring router in the Autonomous System.`
+	Options                 int32  `DESCRIPTION: A bit mask corresponding to the neighbor's options field.  Bit 0, if set, indicates that the system will operate on Type of Service metrics other than TOS 0.  If zero, the neighbor will ignore all metrics except the TOS 0 metric.  Bit 1, if set, indicates that the associated area accepts and operates on external information; if zero, it is a stub area.  Bit 2, if set, indicates that the system is capable of routing IP multicast datagrams, that is that it implements the multicast extensions to OSPF.  Bit 3, if set, indicates that the associated area is an NSSA.  These areas are capable of carrying type-7 external advertisements, which are translated into type-5 external advertisements at NSSA borders.`
+	State                   string `DESCRIPTION: The state of the relationship with this neighbor., SELECTION: exchangeStart(5)/loading(7)/attempt(2)/exchange(6)/down(1)/init(3)/full(8)/twoWay(4)`
+	RestartHelperStatus     string `DESCRIPTION: Indicates whether the router is acting as a graceful restart helper for the neighbor., SELECTION: notHelping/helping`
+	RestartHelperAge        uint32 `DESCRIPTION: Remaining time in seconds of the current graceful restart interval, if the router is acting as a restart helper for the neighbor.`
+	RestartHelperExitReason string `DESCRIPTION: Describes the outcome of the last attempt at acting as a graceful restart helper for the neighbor., SELECTION: none/inProgress/completed/timedOut/topologyChanged`
+	RestartResync           string `DESCRIPTION: While this router is gracefully restarting, indicates whether the adjacency with the neighbor has been re-synchronized., SELECTION: none/inProgress/completed`
+	BfdState                string `DESCRIPTION: The state of the BFD session with the neighbor. none indicates that no BFD session is registered for the neighbor., SELECTION: none/init/up/down`
+	Uptime                  uint32 `DESCRIPTION: Time in seconds since the neighbor was discovered.`
+	LastStateChangeReason   string `DESCRIPTION: The neighbor event which caused the last state change., SELECTION: none/helloReceived/twoWayReceived/oneWayReceived/adjOk/negotiationDone/exchangeDone/loadingDone/seqNumberMismatch`
+	DesignatedRouter        string `DESCRIPTION: The IP address of the designated router advertised by the neighbor.`
+	BackupDesignatedRouter  string `DESCRIPTION: The IP address of the backup designated router advertised by the neighbor.`
+	RetransmitListLen       uint32 `DESCRIPTION: Number of LSAs in the link state retransmission list of the neighbor.`
+	DeadTimerRemaining      uint32 `DESCRIPTION: Remaining time in seconds before the neighbor is declared down.`
+	NumOfAuthFailures       uint32 `DESCRIPTION: Number of packets from the neighbor dropped because of authentication failure.`
+	NumOfHelloRx            uint32 `DESCRIPTION: Number of Hello packets received.`
+	NumOfHelloTx            uint32 `DESCRIPTION: Number of Hello packets sent.`
+	NumOfDbdRx              uint32 `DESCRIPTION: Number of Database Description packets received.`
+	NumOfDbdTx              uint32 `DESCRIPTION: Number of Database Description packets sent.`
+	NumOfLsReqRx            uint32 `DESCRIPTION: Number of Link State Request packets received.`
+	NumOfLsReqTx            uint32 `DESCRIPTION: Number of Link State Request packets sent.`
+	NumOfLsUpdRx            uint32 `DESCRIPTION: Number of Link State Update packets received.`
+	NumOfLsUpdTx            uint32 `DESCRIPTION: Number of Link State Update packets sent.`
+	NumOfLsAckRx            uint32 `DESCRIPTION: Number of Link State Acknowledgment packets received.`
+	NumOfLsAckTx            uint32 `DESCRIPTION: Number of Link State Acknowledgment packets sent.`
+	NumOfRetransmits        uint32 `DESCRIPTION: Number of retransmitted Database Description packets.`
+	NumOfMtuMismatch        uint32 `DESCRIPTION: Number of Database Description packets dropped because of interface MTU mismatch.`
+	NumOfAreaMismatch       uint32 `DESCRIPTION: Number of packets dropped because of Area ID mismatch.`
+	NumOfHelloIntvlMismatch uint32 `DESCRIPTION: Number of Hello packets dropped because of Hello Interval mismatch.`
+	NumOfDeadIntvlMismatch  uint32 `DESCRIPTION: Number of Hello packets dropped because of Router Dead Interval mismatch.`
+	NumOfBadChecksum        uint32 `DESCRIPTION: Number of packets dropped because of invalid Ospf checksum.`
 }
 
 type Ospfv2LsdbState struct {